@@ -7,6 +7,7 @@ import (
 	"syscall"
 
 	"github.com/law-makers/crawl/internal/cli"
+	"github.com/law-makers/crawl/internal/engine/dynamic"
 	"github.com/rs/zerolog/log"
 )
 
@@ -18,6 +19,8 @@ func main() {
 	go func() {
 		<-sigCh
 		log.Warn().Msg("Interrupt received, shutting down gracefully...")
+		// Deferred browser cleanup doesn't run on os.Exit
+		dynamic.KillOwned()
 		os.Exit(0)
 	}()
 
@@ -4,19 +4,27 @@ package app
 import (
 	"context"
 	"fmt"
-	"io"
+	"net"
 	"net/http"
-	"os"
+	"net/url"
 	"sync"
 	"time"
 
+	"github.com/law-makers/crawl/internal/browser"
 	"github.com/law-makers/crawl/internal/cache"
 	"github.com/law-makers/crawl/internal/config"
 	"github.com/law-makers/crawl/internal/engine"
 	"github.com/law-makers/crawl/internal/engine/dynamic"
 	"github.com/law-makers/crawl/internal/engine/hybrid"
 	"github.com/law-makers/crawl/internal/engine/static"
+	"github.com/law-makers/crawl/internal/httpcache"
+	"github.com/law-makers/crawl/internal/memguard"
+	"github.com/law-makers/crawl/internal/paths"
+	"github.com/law-makers/crawl/internal/policy"
+	"github.com/law-makers/crawl/internal/proxy"
 	"github.com/law-makers/crawl/internal/ratelimit"
+	"github.com/law-makers/crawl/internal/replay"
+	"github.com/law-makers/crawl/internal/reqlog"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 )
@@ -32,10 +40,17 @@ type Application struct {
 	BrowserPool    *dynamic.BrowserPool
 	poolMu         sync.Mutex
 	RateLimiter    ratelimit.RateLimiter
+	Concurrency    *ratelimit.DomainConcurrency
+	RequestLog     *reqlog.Logger
+	Transport      http.RoundTripper    // shared by every HTTP client the app hands out
+	HTTPCache      *httpcache.Transport // static engine only; nil unless --cache-policy is http
 	HTTPClient     *http.Client
 	StaticScraper  *static.Scraper
 	DynamicScraper *dynamic.Scraper
 	Scraper        engine.Scraper
+	MemoryGuard    *memguard.Guard // nil unless a memory limit is configured
+	Policy         *policy.Policy  // nil unless a system policy file exists
+	stopGuard      context.CancelFunc
 	startTime      time.Time
 }
 
@@ -55,41 +70,54 @@ func New(ctx context.Context, cfg *config.Config) (*Application, error) {
 		return nil, fmt.Errorf("config is required")
 	}
 
-	// Initialize logger based on config
-	logLevel := zerolog.ErrorLevel // default: suppress non-verbose info logs
-	switch cfg.LogLevel {
-	case "debug":
-		logLevel = zerolog.DebugLevel
-	case "warn":
-		logLevel = zerolog.WarnLevel
-	case "error":
-		logLevel = zerolog.ErrorLevel
-	// Treat "info" as non-verbose (don't display info logs unless -v is used)
-	default:
-		logLevel = zerolog.ErrorLevel
-	}
-	zerolog.SetGlobalLevel(logLevel)
+	// The global logger's level, format and output are set up by the
+	// caller (see logging.Setup)
+	logger := log.Logger
 
-	var logWriter io.Writer
-	if cfg.JSONLog {
-		// JSON logs to stderr
-		logWriter = os.Stderr
-	} else {
-		// Human-friendly console output otherwise
-		logWriter = zerolog.NewConsoleWriter()
+	// Load the system policy first; nothing below may get around it. It is
+	// looked up even when the config failed to load so a broken config
+	// file can't be used to skip it.
+	policyFile := cfg.PolicyFile
+	if policyFile == "" {
+		policyFile = paths.PolicyFile()
+	}
+	pol, err := policy.Load(policyFile)
+	if err != nil {
+		return nil, err
+	}
+	if pol != nil {
+		if cfg.Proxy != "" {
+			if err := pol.Forbid(policy.FeatureProxy, "a proxy"); err != nil {
+				return nil, err
+			}
+		}
+		// Domain overrides are applied after the policy sees a request
+		for domain, override := range cfg.Domains {
+			if override.Proxy != "" {
+				if err := pol.Forbid(policy.FeatureProxy, "the proxy for "+domain+" in the config file"); err != nil {
+					return nil, err
+				}
+			}
+		}
+		if cfg.BrowserRemoteURL != "" {
+			if err := pol.Forbid(policy.FeatureRemoteBrowser, "a remote browser"); err != nil {
+				return nil, err
+			}
+		}
+		logger.Debug().
+			Str("file", policyFile).
+			Int("deny_domains", len(pol.DenyDomains)).
+			Int("allow_domains", len(pol.AllowDomains)).
+			Strs("disabled_features", pol.DisabledFeatures).
+			Msg("System policy loaded")
 	}
-
-	logger := log.Output(logWriter).With().Timestamp().Logger()
-
-	logger.Debug().
-		Str("level", cfg.LogLevel).
-		Bool("json", cfg.JSONLog).
-		Msg("Logger initialized")
 
 	// Create cache
 	memCache := cache.NewMemoryCache(cfg.CacheMaxSizeBytes)
+	memCache.SetMaxEntries(cfg.CacheMaxEntries)
 	logger.Debug().
 		Int64("max_size_bytes", cfg.CacheMaxSizeBytes).
+		Int("max_entries", cfg.CacheMaxEntries).
 		Msg("Memory cache initialized")
 
 	// Browser pool initialization is now lazy (only created when SPA/dynamic scraping is requested).
@@ -103,19 +131,124 @@ func New(ctx context.Context, cfg *config.Config) (*Application, error) {
 		Int("static_burst", cfg.StaticRateLimitBurst).
 		Msg("Rate limiter initialized")
 
-	// Create HTTP client
-	httpClient := &http.Client{
-		Timeout: cfg.HTTPTimeout,
-		Transport: &http.Transport{
-			MaxIdleConns:        100,
-			MaxIdleConnsPerHost: 10,
-			IdleConnTimeout:     90 * time.Second,
-			DisableKeepAlives:   false,
-		},
+	// Create per-domain concurrency limiter
+	concurrency := ratelimit.NewDomainConcurrency(cfg.MaxConcurrentPerDomain)
+	for domain, max := range cfg.DomainConcurrency {
+		concurrency.SetMax(domain, max)
+	}
+
+	// Apply per-domain rate and concurrency overrides from the config file.
+	// --domain-concurrency flags win over the file.
+	for domain, override := range cfg.Domains {
+		if override.RPS > 0 {
+			burst := override.Burst
+			if burst <= 0 {
+				burst = cfg.StaticRateLimitBurst
+			}
+			rateLimiter.SetLimit(domain, override.RPS, burst)
+		}
+		if _, fromFlag := cfg.DomainConcurrency[domain]; override.MaxConcurrent > 0 && !fromFlag {
+			concurrency.SetMax(domain, override.MaxConcurrent)
+		}
+	}
+	// The policy's politeness limits cap everything above
+	if pol != nil {
+		rateLimiter.SetCeiling(pol.MaxRPS, pol.MaxBurst)
+		concurrency.SetCeiling(pol.MaxPerDomain)
+	}
+	logger.Debug().
+		Int("max_per_domain", cfg.MaxConcurrentPerDomain).
+		Int("overrides", len(cfg.DomainConcurrency)).
+		Msg("Concurrency limiter initialized")
+
+	// Create the shared HTTP transport. Every subsystem gets its client from
+	// this transport so connection pools, proxy and TLS settings are consistent.
+	baseTransport := &http.Transport{
+		DialContext: (&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          100,
+		MaxIdleConnsPerHost:   cfg.MaxIdleConnsPerHost,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   cfg.TLSHandshakeTimeout,
+		ExpectContinueTimeout: cfg.ExpectContinueTimeout,
+		DisableKeepAlives:     cfg.DisableKeepAlives,
+	}
+	var defaultProxy func(*http.Request) (*url.URL, error)
+	if cfg.Proxy != "" {
+		proxyURL, err := url.Parse(cfg.Proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy URL: %w", err)
+		}
+		defaultProxy = http.ProxyURL(proxyURL)
+	}
+	// A proxy chosen per request (domain overrides, escalation, compare
+	// variants) travels in the request context, past any wrappers above
+	baseTransport.Proxy = proxy.Func(defaultProxy)
+	var base http.RoundTripper = baseTransport
+	if cfg.RawOutput != "" {
+		// Directly on the transport, so it sees bodies before decompression
+		base = replay.NewRawDump(cfg.RawOutput, baseTransport)
+		logger.Debug().Str("file", cfg.RawOutput).Msg("Raw response output enabled")
+	}
+	// Checked per request so redirects to denied domains are stopped too
+	var transport http.RoundTripper = pol.Transport(base)
+	if cfg.Contact != "" {
+		transport = &contactTransport{from: cfg.FromAddress(), userAgent: cfg.UserAgent, next: transport}
+		if cfg.FromAddress() == "" {
+			// The From header must be an email address (RFC 9110)
+			logger.Debug().Str("contact", cfg.Contact).Msg("Contact is a URL: added to the user agent, From header not sent")
+		}
 	}
 	logger.Debug().
 		Dur("timeout", cfg.HTTPTimeout).
-		Msg("HTTP client initialized")
+		Int("max_idle_per_host", cfg.MaxIdleConnsPerHost).
+		Dur("tls_handshake_timeout", cfg.TLSHandshakeTimeout).
+		Bool("keepalive", !cfg.DisableKeepAlives).
+		Msg("HTTP transport initialized")
+
+	// Record or replay raw responses if requested
+	switch {
+	case cfg.ReplayDir != "":
+		transport = replay.NewReplayer(cfg.ReplayDir)
+		logger.Debug().Str("dir", cfg.ReplayDir).Msg("Replay mode enabled")
+	case cfg.RecordDir != "":
+		transport = replay.NewRecorder(cfg.RecordDir, transport)
+		logger.Debug().Str("dir", cfg.RecordDir).Msg("Record mode enabled")
+	}
+	// The HTTP cache only serves the static engine's page fetches; media
+	// downloads, probes and robots checks stream past it
+	staticTransport := transport
+	var httpCache *httpcache.Transport
+	if cfg.CachePolicy == "http" && cfg.ReplayDir == "" {
+		// Above the recorder, so cache hits aren't recorded as fetches
+		httpCache = httpcache.New(transport, cfg.CacheMaxSizeBytes)
+		staticTransport = httpCache
+		logger.Debug().Int64("max_size_bytes", cfg.CacheMaxSizeBytes).Msg("HTTP cache enabled")
+	}
+
+	// Log every outbound request if requested
+	var requestLog *reqlog.Logger
+	if cfg.RequestLog != "" {
+		l, err := reqlog.Open(cfg.RequestLog)
+		if err != nil {
+			return nil, err
+		}
+		requestLog = l
+		logger.Debug().Str("file", cfg.RequestLog).Msg("Request log enabled")
+	}
+
+	app := &Application{
+		Config:     cfg,
+		Logger:     &logger,
+		RequestLog: requestLog,
+		Transport:  transport,
+		HTTPCache:  httpCache,
+		Policy:     pol,
+	}
+	httpClient := app.newHTTPClient(staticTransport, cfg.HTTPTimeout, "static")
 
 	// Create scrapers
 	staticScraper := static.New(
@@ -136,26 +269,102 @@ func New(ctx context.Context, cfg *config.Config) (*Application, error) {
 		cfg.UserAgent,
 	)
 
-	hybridScraper := hybrid.New(staticScraper, dynamicScraper)
+	staticScraper.SetConcurrency(concurrency)
+	dynamicScraper.SetConcurrency(concurrency)
+	dynamicScraper.SetRequestLog(requestLog)
+	dynamicScraper.SetRemoteURL(cfg.BrowserRemoteURL)
+	// The browser follows redirects and loads subresources itself, so the
+	// policy's domain rules are enforced inside it too
+	var blockURL func(string) error
+	if pol.RestrictsDomains() {
+		blockURL = pol.CheckURL
+		dynamicScraper.SetURLFilter(blockURL)
+	}
+	if cfg.BrowserDriver != "" && cfg.BrowserDriver != dynamic.DriverChromedp {
+		driver, err := dynamic.NewDriver(cfg.BrowserDriver, dynamic.DriverOptions{
+			UserAgent: cfg.UserAgent,
+			Proxy:     cfg.Proxy,
+			Headless:  cfg.BrowserHeadless,
+			RemoteURL: cfg.BrowserRemoteURL,
+			BlockURL:  blockURL,
+		})
+		if err != nil {
+			return nil, err
+		}
+		dynamicScraper.SetDriver(driver)
+		logger.Debug().Str("driver", driver.Name()).Msg("Browser driver selected")
+	}
+
+	var scraper engine.Scraper = hybrid.New(staticScraper, dynamicScraper)
+	if len(cfg.Domains) > 0 {
+		scraper = &domainScraper{cfg: cfg, auto: scraper, static: staticScraper, dynamic: dynamicScraper}
+		logger.Debug().Int("domains", len(cfg.Domains)).Msg("Domain overrides enabled")
+	}
+	scraper = app.Guard(scraper)
 	logger.Debug().Msg("Scrapers initialized")
 
-	app := &Application{
-		Config:         cfg,
-		Logger:         &logger,
-		Cache:          memCache,
-		BrowserPool:    browserPool,
-		RateLimiter:    rateLimiter,
-		HTTPClient:     httpClient,
-		StaticScraper:  staticScraper,
-		DynamicScraper: dynamicScraper,
-		Scraper:        hybridScraper,
-		startTime:      time.Now(),
+	app.Cache = memCache
+	app.BrowserPool = browserPool
+	app.RateLimiter = rateLimiter
+	app.Concurrency = concurrency
+	app.HTTPClient = httpClient
+	app.StaticScraper = staticScraper
+	app.DynamicScraper = dynamicScraper
+	app.Scraper = scraper
+	app.startTime = time.Now()
+
+	if cfg.MemoryLimit > 0 {
+		app.startMemoryGuard(uint64(cfg.MemoryLimit))
 	}
 
 	logger.Info().Msg("Application initialized successfully")
 	return app, nil
 }
 
+// startMemoryGuard watches memory use against limit. Under pressure the
+// cache is emptied and idle browser tabs are closed; commands pause new
+// requests through MemoryGuard.Wait.
+func (a *Application) startMemoryGuard(limit uint64) {
+	guard := memguard.New(limit)
+	guard.OnPressure(func() {
+		if err := a.Cache.Clear(); err != nil {
+			a.Logger.Warn().Err(err).Msg("Failed to clear cache under memory pressure")
+		}
+		if a.HTTPCache != nil {
+			a.HTTPCache.Clear()
+		}
+		a.poolMu.Lock()
+		pool := a.BrowserPool
+		a.poolMu.Unlock()
+		if pool != nil {
+			if n := pool.Shrink(1); n > 0 {
+				a.Logger.Info().Int("closed", n).Int("pool_size", pool.Size()).Msg("Closed idle browser contexts under memory pressure")
+			}
+		}
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	guard.Start(ctx)
+	a.MemoryGuard = guard
+	a.stopGuard = cancel
+	a.Logger.Debug().Uint64("limit_bytes", limit).Msg("Memory guard started")
+}
+
+// NewHTTPClient returns a client that shares the application transport.
+// Requests are recorded in the request log, if enabled, under the given engine name.
+func (a *Application) NewHTTPClient(timeout time.Duration, engine string) *http.Client {
+	return a.newHTTPClient(a.Transport, timeout, engine)
+}
+
+// newHTTPClient returns a client on next, logged under the given engine name
+func (a *Application) newHTTPClient(next http.RoundTripper, timeout time.Duration, engine string) *http.Client {
+	transport := reqlog.Wrap(next, a.RequestLog, engine)
+	if logged, ok := transport.(*reqlog.Transport); ok && a.Config.ReplayDir != "" {
+		logged.Cache = reqlog.CacheReplay
+	}
+	return &http.Client{Timeout: timeout, Transport: transport}
+}
+
 // EnsureBrowserPool lazily creates the browser pool if it has not already been
 // initialized. Callers should provide a context with an appropriate timeout.
 func (a *Application) EnsureBrowserPool(ctx context.Context) error {
@@ -169,14 +378,34 @@ func (a *Application) EnsureBrowserPool(ctx context.Context) error {
 	if a.BrowserPool != nil {
 		return nil
 	}
+	// The browser pool belongs to the chromedp driver; other drivers manage their own browsers
+	if a.DynamicScraper != nil && a.DynamicScraper.Driver().Name() != dynamic.DriverChromedp {
+		return nil
+	}
 
 	logger := a.Logger
+
+	// Download a managed browser first if none is available and the user opted in
+	if a.Config.BrowserAutoInstall && a.Config.BrowserRemoteURL == "" && dynamic.FindChrome() == "" {
+		logger.Info().Str("version", browser.PinnedVersion).Msg("No Chrome found; installing chrome-headless-shell")
+		// A download can outlast the caller's pool start-up deadline
+		installCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Minute)
+		defer cancel()
+		if _, err := browser.Install(installCtx, browser.InstallOptions{}); err != nil {
+			return fmt.Errorf("failed to install browser: %w", err)
+		}
+	}
+
 	logger.Debug().Msg("Initializing browser pool on demand")
 	pool, err := dynamic.NewBrowserPool(dynamic.BrowserPoolOptions{
-		Size:      a.Config.BrowserPoolSize,
-		Headless:  a.Config.BrowserHeadless,
-		UserAgent: a.Config.UserAgent,
-		Proxy:     a.Config.Proxy,
+		Size:        a.Config.BrowserPoolSize,
+		MinSize:     a.Config.BrowserPoolMin,
+		IdleTimeout: a.Config.BrowserIdleTimeout,
+		Headless:    a.Config.BrowserHeadless,
+		UserAgent:   a.Config.UserAgent,
+		Proxy:       a.Config.Proxy,
+		RemoteURL:   a.Config.BrowserRemoteURL,
+		DevTools:    a.Config.BrowserDevTools,
 	})
 	if err != nil {
 		logger.Warn().Err(err).Msg("Failed to create browser pool on demand")
@@ -207,6 +436,13 @@ func (a *Application) EnsureBrowserPool(ctx context.Context) error {
 func (a *Application) Close(ctx context.Context) error {
 	a.Logger.Info().Msg("Shutting down application")
 
+	// Stop the memory guard first so it can't act on resources being closed
+	if a.stopGuard != nil {
+		a.stopGuard()
+		s := a.MemoryGuard.Stats()
+		a.Logger.Debug().Uint64("peak_bytes", s.Peak).Int("pauses", s.Pauses).Msg("Memory guard stopped")
+	}
+
 	// Close browser pool (will interrupt any running operations)
 	if a.BrowserPool != nil {
 		if err := a.BrowserPool.Close(); err != nil {
@@ -224,6 +460,11 @@ func (a *Application) Close(ctx context.Context) error {
 		a.HTTPClient.CloseIdleConnections()
 	}
 
+	// Flush the request log
+	if err := a.RequestLog.Close(); err != nil {
+		a.Logger.Warn().Err(err).Msg("Error closing request log")
+	}
+
 	uptime := time.Since(a.startTime)
 	a.Logger.Info().Dur("uptime", uptime).Msg("Application shutdown complete")
 	return nil
@@ -233,3 +474,30 @@ func (a *Application) Close(ctx context.Context) error {
 func (a *Application) Uptime() time.Duration {
 	return time.Since(a.startTime)
 }
+
+// contactTransport sets the From header (when the contact is an email
+// address) and the user agent carrying the contact, so site operators can
+// reach whoever runs a large crawl. Requests that set their own keep them;
+// those that set none, such as oEmbed lookups, would otherwise go out as
+// Go-http-client.
+type contactTransport struct {
+	from      string
+	userAgent string
+	next      http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *contactTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	setFrom := t.from != "" && req.Header.Get("From") == ""
+	setUA := t.userAgent != "" && req.Header.Get("User-Agent") == ""
+	if setFrom || setUA {
+		req = req.Clone(req.Context())
+		if setFrom {
+			req.Header.Set("From", t.from)
+		}
+		if setUA {
+			req.Header.Set("User-Agent", t.userAgent)
+		}
+	}
+	return t.next.RoundTrip(req)
+}
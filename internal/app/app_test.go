@@ -0,0 +1,77 @@
+package app
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/law-makers/crawl/internal/config"
+	"github.com/law-makers/crawl/pkg/models"
+	"github.com/spf13/cobra"
+)
+
+// TestNew_StaticFetchSendsContact checks what a static get --from puts on
+// the wire, not just the configured user agent string
+func TestNew_StaticFetchSendsContact(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("XDG_CACHE_HOME", dir)
+
+	var mu sync.Mutex
+	var ua, from string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		ua, from = r.Header.Get("User-Agent"), r.Header.Get("From")
+		mu.Unlock()
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte("<html><head><title>Hi</title></head><body><p>Hello</p></body></html>"))
+	}))
+	defer srv.Close()
+
+	cmd := &cobra.Command{Use: "get"}
+	config.RegisterFlags(cmd)
+	if err := cmd.ParseFlags([]string{"--from", "ops@example.com"}); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := config.Load(cmd)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	cfg.PolicyFile = filepath.Join(dir, "no-policy.yaml")
+
+	a, err := New(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer a.Close(context.Background())
+
+	if _, err := a.StaticScraper.Fetch(models.RequestOptions{URL: srv.URL, Selector: "body"}); err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	mu.Lock()
+	gotUA, gotFrom := ua, from
+	mu.Unlock()
+	if want := config.DefaultUserAgent + " (+mailto:ops@example.com)"; gotUA != want {
+		t.Errorf("Expected User-Agent %q, got %q", want, gotUA)
+	}
+	if gotFrom != "ops@example.com" {
+		t.Errorf("Expected From ops@example.com, got %q", gotFrom)
+	}
+
+	// Clients handed out for other work carry the contact too
+	resp, err := a.NewHTTPClient(0, "test").Get(srv.URL)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	resp.Body.Close()
+	mu.Lock()
+	gotUA = ua
+	mu.Unlock()
+	if want := config.DefaultUserAgent + " (+mailto:ops@example.com)"; gotUA != want {
+		t.Errorf("Expected User-Agent %q from an app client, got %q", want, gotUA)
+	}
+}
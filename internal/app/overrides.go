@@ -0,0 +1,69 @@
+package app
+
+import (
+	"strings"
+
+	"github.com/law-makers/crawl/internal/config"
+	"github.com/law-makers/crawl/internal/engine"
+	"github.com/law-makers/crawl/pkg/models"
+	"github.com/rs/zerolog/log"
+)
+
+// domainScraper applies per-host overrides from the config file before
+// dispatching to the engine selected by the override's mode.
+type domainScraper struct {
+	cfg     *config.Config
+	auto    engine.Scraper
+	static  engine.Scraper
+	dynamic engine.Scraper
+}
+
+// Name returns the name of the default engine
+func (d *domainScraper) Name() string {
+	return d.auto.Name()
+}
+
+// Fetch merges the matching override into opts and fetches with the right engine
+func (d *domainScraper) Fetch(opts models.RequestOptions) (*models.PageData, error) {
+	override, ok := d.cfg.DomainFor(opts.URL)
+	if !ok {
+		return d.auto.Fetch(opts)
+	}
+
+	opts = applyOverride(opts, override)
+
+	scraper := d.auto
+	switch opts.Mode {
+	case models.ModeStatic:
+		scraper = d.static
+	case models.ModeSPA:
+		scraper = d.dynamic
+	}
+	log.Debug().Str("url", opts.URL).Str("scraper", scraper.Name()).Msg("Applied domain override")
+	return scraper.Fetch(opts)
+}
+
+// applyOverride fills unset request options from a domain override. Values
+// given explicitly on the request (mode, headers, proxy, wait) take precedence.
+func applyOverride(opts models.RequestOptions, override config.DomainOverride) models.RequestOptions {
+	if override.Mode != "" && (opts.Mode == "" || opts.Mode == models.ModeAuto) {
+		opts.Mode = models.ScraperMode(strings.ToLower(override.Mode))
+	}
+	if len(override.Headers) > 0 {
+		merged := make(map[string]string, len(override.Headers)+len(opts.Headers))
+		for key, value := range override.Headers {
+			merged[key] = value
+		}
+		for key, value := range opts.Headers {
+			merged[key] = value
+		}
+		opts.Headers = merged
+	}
+	if override.Proxy != "" && opts.Proxy == "" {
+		opts.Proxy = override.Proxy
+	}
+	if override.WaitSeconds > 0 && opts.WaitSeconds == 0 {
+		opts.WaitSeconds = override.WaitSeconds
+	}
+	return opts
+}
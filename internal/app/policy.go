@@ -0,0 +1,42 @@
+package app
+
+import (
+	"github.com/law-makers/crawl/internal/engine"
+	"github.com/law-makers/crawl/internal/policy"
+	"github.com/law-makers/crawl/pkg/models"
+)
+
+// policyScraper refuses requests the system policy forbids before they
+// reach the engine. It only sees the URL a page starts at; redirects and
+// subresources are checked by the policy's HTTP transport and, in the
+// browser, by the dynamic scraper's URL filter.
+type policyScraper struct {
+	policy *policy.Policy
+	next   engine.Scraper
+}
+
+// Name returns the name of the wrapped engine
+func (p *policyScraper) Name() string {
+	return p.next.Name()
+}
+
+// Fetch checks opts against the policy and fetches if it allows them
+func (p *policyScraper) Fetch(opts models.RequestOptions) (*models.PageData, error) {
+	if err := p.policy.CheckRequest(opts); err != nil {
+		return nil, err
+	}
+	return p.next.Fetch(opts)
+}
+
+// Guard wraps scraper so every request it is given, including ones changed
+// by escalation, is checked against the system policy. Without a policy
+// scraper is returned as is.
+func (a *Application) Guard(scraper engine.Scraper) engine.Scraper {
+	if a.Policy == nil {
+		return scraper
+	}
+	if _, ok := scraper.(*policyScraper); ok {
+		return scraper
+	}
+	return &policyScraper{policy: a.Policy, next: scraper}
+}
@@ -0,0 +1,330 @@
+// internal/attest/attest.go
+//
+// Package attest signs output files so a capture can later be shown to be
+// untampered. The signature is an in-toto statement in a DSSE envelope: the
+// statement names each output file by its SHA-256 and records, for every
+// page in it, when and from where it was fetched, the response headers and
+// a hash of the content.
+package attest
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/law-makers/crawl/pkg/models"
+)
+
+const (
+	// StatementType is the in-toto statement version written
+	StatementType = "https://in-toto.io/Statement/v1"
+	// PredicateType identifies crawl's capture predicate
+	PredicateType = "https://github.com/law-makers/crawl/attestation/capture/v1"
+	// PayloadType is the DSSE payload type of an in-toto statement
+	PayloadType = "application/vnd.in-toto+json"
+	// Ext is appended to the output path to name its attestation
+	Ext = ".intoto.json"
+)
+
+// Envelope is a signed DSSE envelope
+type Envelope struct {
+	PayloadType string      `json:"payloadType"`
+	Payload     string      `json:"payload"` // base64 of the statement
+	Signatures  []Signature `json:"signatures"`
+}
+
+// Signature is one signature over an envelope's payload
+type Signature struct {
+	KeyID string `json:"keyid"`
+	Sig   string `json:"sig"` // base64
+}
+
+// Statement is the signed in-toto statement
+type Statement struct {
+	Type          string    `json:"_type"`
+	Subject       []Subject `json:"subject"`
+	PredicateType string    `json:"predicateType"`
+	Predicate     Capture   `json:"predicate"`
+}
+
+// Subject is an output file, named relative to its attestation
+type Subject struct {
+	Name   string            `json:"name"`
+	Digest map[string]string `json:"digest"`
+}
+
+// Capture describes the run that produced the files
+type Capture struct {
+	Tool     string    `json:"tool"`
+	JobID    string    `json:"job_id,omitempty"`
+	SignedAt time.Time `json:"signed_at"`
+	Pages    []Page    `json:"pages"`
+}
+
+// Page is the provenance of one page in the output
+type Page struct {
+	URL           string            `json:"url"`
+	StatusCode    int               `json:"status_code"`
+	FetchedAt     time.Time         `json:"fetched_at"`
+	Headers       map[string]string `json:"headers,omitempty"`
+	ContentSHA256 string            `json:"content_sha256"`
+	HTMLSHA256    string            `json:"html_sha256,omitempty"`
+	RequestID     string            `json:"request_id,omitempty"`
+}
+
+// PageOf records the provenance of a fetched page
+func PageOf(p *models.PageData) Page {
+	page := Page{
+		URL:           p.URL,
+		StatusCode:    p.StatusCode,
+		FetchedAt:     p.FetchedAt,
+		Headers:       p.Headers,
+		ContentSHA256: hashString(p.Content),
+		RequestID:     p.RequestID,
+	}
+	if p.HTML != "" {
+		page.HTMLSHA256 = hashString(p.HTML)
+	}
+	return page
+}
+
+func hashString(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+// Signer signs statements with a private key
+type Signer struct {
+	key   crypto.Signer
+	keyID string
+}
+
+// LoadSigner reads a PEM private key: Ed25519, ECDSA or RSA, in PKCS #8,
+// SEC 1 or PKCS #1 form
+func LoadSigner(path string) (*Signer, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+	var key interface{}
+	switch block.Type {
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	default:
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key %s: %w", path, err)
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported private key type %T in %s", key, path)
+	}
+	keyID, err := KeyID(signer.Public())
+	if err != nil {
+		return nil, err
+	}
+	return &Signer{key: signer, keyID: keyID}, nil
+}
+
+// LoadPublicKey reads a PEM public key (PKIX "PUBLIC KEY")
+func LoadPublicKey(path string) (crypto.PublicKey, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+	pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse public key %s: %w", path, err)
+	}
+	return pub, nil
+}
+
+func readPEM(path string) (*pem.Block, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key: %w", err)
+	}
+	block, _ := pem.Decode(raw)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM block in %s", path)
+	}
+	return block, nil
+}
+
+// KeyID identifies a public key: the SHA-256 of its PKIX encoding
+func KeyID(pub crypto.PublicKey) (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return "", fmt.Errorf("unsupported public key: %w", err)
+	}
+	return hashString(string(der)), nil
+}
+
+// Sign attests files, which hold pages, written by the run jobID. Subjects
+// are named relative to dir, the directory the attestation is written to.
+func (s *Signer) Sign(dir string, files []string, pages []Page, tool, jobID string) (*Envelope, error) {
+	st := Statement{
+		Type:          StatementType,
+		PredicateType: PredicateType,
+		Predicate: Capture{
+			Tool:     tool,
+			JobID:    jobID,
+			SignedAt: time.Now().UTC(),
+			Pages:    pages,
+		},
+	}
+	for _, f := range files {
+		sum, err := fileSHA256(f)
+		if err != nil {
+			return nil, err
+		}
+		name, err := filepath.Rel(dir, f)
+		if err != nil {
+			name = f
+		}
+		st.Subject = append(st.Subject, Subject{Name: filepath.ToSlash(name), Digest: map[string]string{"sha256": sum}})
+	}
+
+	payload, err := json.Marshal(st)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode statement: %w", err)
+	}
+	sig, err := s.sign(pae(PayloadType, payload))
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign: %w", err)
+	}
+	return &Envelope{
+		PayloadType: PayloadType,
+		Payload:     base64.StdEncoding.EncodeToString(payload),
+		Signatures:  []Signature{{KeyID: s.keyID, Sig: base64.StdEncoding.EncodeToString(sig)}},
+	}, nil
+}
+
+func (s *Signer) sign(msg []byte) ([]byte, error) {
+	if _, ok := s.key.(ed25519.PrivateKey); ok {
+		return s.key.Sign(rand.Reader, msg, crypto.Hash(0))
+	}
+	digest := sha256.Sum256(msg)
+	return s.key.Sign(rand.Reader, digest[:], crypto.SHA256)
+}
+
+// pae is DSSE's pre-authentication encoding of a payload
+func pae(payloadType string, payload []byte) []byte {
+	return []byte(fmt.Sprintf("DSSEv1 %d %s %d %s", len(payloadType), payloadType, len(payload), payload))
+}
+
+// Write saves the envelope as indented JSON
+func (e *Envelope) Write(path string) error {
+	raw, err := json.MarshalIndent(e, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, append(raw, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write attestation: %w", err)
+	}
+	return nil
+}
+
+// ReadEnvelope loads an attestation written by Write
+func ReadEnvelope(path string) (*Envelope, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read attestation: %w", err)
+	}
+	var env Envelope
+	if err := json.Unmarshal(raw, &env); err != nil {
+		return nil, fmt.Errorf("invalid attestation %s: %w", path, err)
+	}
+	return &env, nil
+}
+
+// Verify checks that the envelope was signed by pub and returns its statement
+func Verify(env *Envelope, pub crypto.PublicKey) (*Statement, error) {
+	if env.PayloadType != PayloadType {
+		return nil, fmt.Errorf("unexpected payload type %q", env.PayloadType)
+	}
+	payload, err := base64.StdEncoding.DecodeString(env.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("invalid payload: %w", err)
+	}
+	msg := pae(env.PayloadType, payload)
+	verified := false
+	for _, s := range env.Signatures {
+		sig, err := base64.StdEncoding.DecodeString(s.Sig)
+		if err == nil && verifySig(pub, msg, sig) {
+			verified = true
+			break
+		}
+	}
+	if !verified {
+		return nil, errors.New("signature does not match the key")
+	}
+
+	var st Statement
+	if err := json.Unmarshal(payload, &st); err != nil {
+		return nil, fmt.Errorf("invalid statement: %w", err)
+	}
+	if st.Type != StatementType || st.PredicateType != PredicateType {
+		return nil, fmt.Errorf("unexpected statement type %q / %q", st.Type, st.PredicateType)
+	}
+	return &st, nil
+}
+
+func verifySig(pub crypto.PublicKey, msg, sig []byte) bool {
+	switch k := pub.(type) {
+	case ed25519.PublicKey:
+		return ed25519.Verify(k, msg, sig)
+	case *ecdsa.PublicKey:
+		digest := sha256.Sum256(msg)
+		return ecdsa.VerifyASN1(k, digest[:], sig)
+	case *rsa.PublicKey:
+		digest := sha256.Sum256(msg)
+		return rsa.VerifyPKCS1v15(k, crypto.SHA256, digest[:], sig) == nil
+	}
+	return false
+}
+
+// CheckSubjects compares each subject with the file of that name in dir
+func CheckSubjects(st *Statement, dir string) error {
+	for _, s := range st.Subject {
+		path := filepath.Join(dir, filepath.FromSlash(s.Name))
+		sum, err := fileSHA256(path)
+		if err != nil {
+			return err
+		}
+		if sum != s.Digest["sha256"] {
+			return fmt.Errorf("%s has been modified (sha256 %s, signed %s)", s.Name, sum, s.Digest["sha256"])
+		}
+	}
+	return nil
+}
+
+func fileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash %s: %w", path, err)
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("failed to hash %s: %w", path, err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
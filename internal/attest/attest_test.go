@@ -0,0 +1,137 @@
+package attest
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/law-makers/crawl/pkg/models"
+)
+
+// writeKeys saves key as a PKCS #8 private key and its PKIX public key
+func writeKeys(t *testing.T, dir string, key interface{}, pub interface{}) (string, string) {
+	t.Helper()
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("MarshalPKCS8PrivateKey failed: %v", err)
+	}
+	pubDER, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		t.Fatalf("MarshalPKIXPublicKey failed: %v", err)
+	}
+	keyPath := filepath.Join(dir, "key.pem")
+	pubPath := filepath.Join(dir, "key.pub")
+	os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0600)
+	os.WriteFile(pubPath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER}), 0644)
+	return keyPath, pubPath
+}
+
+func signedCapture(t *testing.T, dir, keyPath string) string {
+	t.Helper()
+	out := filepath.Join(dir, "pages.json")
+	os.WriteFile(out, []byte(`{"url":"https://example.com"}`), 0644)
+
+	signer, err := LoadSigner(keyPath)
+	if err != nil {
+		t.Fatalf("LoadSigner failed: %v", err)
+	}
+	page := PageOf(&models.PageData{
+		URL:        "https://example.com",
+		StatusCode: 200,
+		FetchedAt:  time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		Headers:    map[string]string{"Content-Type": "text/html"},
+		Content:    "Hello",
+	})
+	env, err := signer.Sign(dir, []string{out}, []Page{page}, "crawl test", "job-1")
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+	path := out + Ext
+	if err := env.Write(path); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	return path
+}
+
+func TestSignVerify_RoundTrip(t *testing.T) {
+	for name, gen := range map[string]func() (interface{}, interface{}){
+		"ed25519": func() (interface{}, interface{}) {
+			pub, key, _ := ed25519.GenerateKey(rand.Reader)
+			return key, pub
+		},
+		"ecdsa": func() (interface{}, interface{}) {
+			key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+			return key, &key.PublicKey
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			key, pub := gen()
+			keyPath, pubPath := writeKeys(t, dir, key, pub)
+			path := signedCapture(t, dir, keyPath)
+
+			env, err := ReadEnvelope(path)
+			if err != nil {
+				t.Fatalf("ReadEnvelope failed: %v", err)
+			}
+			pubKey, err := LoadPublicKey(pubPath)
+			if err != nil {
+				t.Fatalf("LoadPublicKey failed: %v", err)
+			}
+			st, err := Verify(env, pubKey)
+			if err != nil {
+				t.Fatalf("Verify failed: %v", err)
+			}
+			if len(st.Subject) != 1 || st.Subject[0].Name != "pages.json" {
+				t.Errorf("Unexpected subjects: %+v", st.Subject)
+			}
+			if p := st.Predicate.Pages[0]; p.URL != "https://example.com" || p.ContentSHA256 != hashString("Hello") || p.Headers["Content-Type"] != "text/html" {
+				t.Errorf("Unexpected page: %+v", p)
+			}
+			if st.Predicate.JobID != "job-1" {
+				t.Errorf("Expected job-1, got %q", st.Predicate.JobID)
+			}
+			if err := CheckSubjects(st, dir); err != nil {
+				t.Errorf("CheckSubjects failed on untouched output: %v", err)
+			}
+		})
+	}
+}
+
+func TestCheckSubjects_DetectsModifiedFile(t *testing.T) {
+	dir := t.TempDir()
+	pub, key, _ := ed25519.GenerateKey(rand.Reader)
+	keyPath, _ := writeKeys(t, dir, key, pub)
+	path := signedCapture(t, dir, keyPath)
+
+	os.WriteFile(filepath.Join(dir, "pages.json"), []byte(`{"url":"https://evil.example"}`), 0644)
+	env, _ := ReadEnvelope(path)
+	st, err := Verify(env, pub)
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if err := CheckSubjects(st, dir); err == nil || !strings.Contains(err.Error(), "modified") {
+		t.Errorf("Expected a modified file error, got %v", err)
+	}
+}
+
+func TestVerify_WrongKey(t *testing.T) {
+	dir := t.TempDir()
+	pub, key, _ := ed25519.GenerateKey(rand.Reader)
+	keyPath, _ := writeKeys(t, dir, key, pub)
+	path := signedCapture(t, dir, keyPath)
+
+	other, _, _ := ed25519.GenerateKey(rand.Reader)
+	env, _ := ReadEnvelope(path)
+	if _, err := Verify(env, other); err == nil {
+		t.Error("Expected verification with another key to fail")
+	}
+}
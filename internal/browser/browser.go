@@ -0,0 +1,189 @@
+// internal/browser/browser.go
+//
+// Package browser manages Chrome builds downloaded by crawl itself, for
+// machines without a system Chrome. Builds are chrome-headless-shell
+// releases from Chrome for Testing, stored in the user cache directory.
+package browser
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/law-makers/crawl/internal/paths"
+)
+
+// PinnedVersion is the chrome-headless-shell release installed by default
+const PinnedVersion = "131.0.6778.85"
+
+// pinnedChecksums maps "<version>/<platform>" to the SHA-256 of the release
+// archive. Releases listed here are verified on install. Others need
+// --sha256, or an explicit AllowUnverified, before Install downloads them;
+// their hash is recorded either way. No checksums are listed yet, so until
+// they are, auto-install refuses to run.
+var pinnedChecksums = map[string]string{}
+
+// downloadBaseURL is the Chrome for Testing download host (overridden in tests)
+var downloadBaseURL = "https://storage.googleapis.com/chrome-for-testing-public"
+
+// manifestName is the file written next to each installed build
+const manifestName = "install.json"
+
+// Installed describes a managed browser build on disk
+type Installed struct {
+	Version     string    `json:"version"`
+	Platform    string    `json:"platform"`
+	Path        string    `json:"path"`
+	SHA256      string    `json:"sha256"`
+	Verified    bool      `json:"verified"`
+	InstalledAt time.Time `json:"installed_at"`
+}
+
+// Dir returns the directory managed builds are installed into:
+// CRAWL_BROWSERS_DIR, else ~/.crawl/browsers if an earlier version installed
+// builds there, else "browsers" in the user cache directory.
+func Dir() (string, error) {
+	if dir := os.Getenv("CRAWL_BROWSERS_DIR"); dir != "" {
+		return dir, nil
+	}
+	if legacy, err := paths.LegacyDir(); err == nil {
+		if info, err := os.Stat(filepath.Join(legacy, "browsers")); err == nil && info.IsDir() {
+			return filepath.Join(legacy, "browsers"), nil
+		}
+	}
+	cache, err := paths.CacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(cache, "browsers"), nil
+}
+
+// Platform returns the Chrome for Testing platform name for this machine
+func Platform() (string, error) {
+	switch runtime.GOOS + "/" + runtime.GOARCH {
+	case "linux/amd64":
+		return "linux64", nil
+	case "darwin/arm64":
+		return "mac-arm64", nil
+	case "darwin/amd64":
+		return "mac-x64", nil
+	case "windows/amd64":
+		return "win64", nil
+	case "windows/386":
+		return "win32", nil
+	}
+	return "", fmt.Errorf("chrome-headless-shell is not published for %s/%s", runtime.GOOS, runtime.GOARCH)
+}
+
+// executableName returns the path of the binary inside an extracted archive
+func executableName(platform string) string {
+	name := "chrome-headless-shell"
+	if strings.HasPrefix(platform, "win") {
+		name += ".exe"
+	}
+	return filepath.Join("chrome-headless-shell-"+platform, name)
+}
+
+// List returns the installed builds, newest version first
+func List() ([]Installed, error) {
+	dir, err := Dir()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", dir, err)
+	}
+
+	var builds []Installed
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		raw, err := os.ReadFile(filepath.Join(dir, entry.Name(), manifestName))
+		if err != nil {
+			// Incomplete or foreign directory
+			continue
+		}
+		var inst Installed
+		if err := json.Unmarshal(raw, &inst); err != nil {
+			continue
+		}
+		builds = append(builds, inst)
+	}
+	sort.Slice(builds, func(i, j int) bool {
+		return compareVersions(builds[i].Version, builds[j].Version) > 0
+	})
+	return builds, nil
+}
+
+// Find returns the executable of the newest installed build for this
+// platform, or "" when none is installed
+func Find() string {
+	platform, err := Platform()
+	if err != nil {
+		return ""
+	}
+	builds, err := List()
+	if err != nil {
+		return ""
+	}
+	for _, b := range builds {
+		if b.Platform != platform {
+			continue
+		}
+		if info, err := os.Stat(b.Path); err == nil && !info.IsDir() {
+			return b.Path
+		}
+	}
+	return ""
+}
+
+// Remove deletes an installed build
+func Remove(version string) error {
+	dir, err := Dir()
+	if err != nil {
+		return err
+	}
+	if version == "" || strings.ContainsAny(version, `/\`) || version == "." || version == ".." {
+		return fmt.Errorf("invalid version %q", version)
+	}
+	target := filepath.Join(dir, version)
+	if _, err := os.Stat(filepath.Join(target, manifestName)); err != nil {
+		return fmt.Errorf("version %s is not installed", version)
+	}
+	if err := os.RemoveAll(target); err != nil {
+		return fmt.Errorf("failed to remove %s: %w", target, err)
+	}
+	return nil
+}
+
+// compareVersions compares dotted numeric versions such as 131.0.6778.85
+func compareVersions(a, b string) int {
+	pa, pb := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(pa) || i < len(pb); i++ {
+		var x, y int
+		if i < len(pa) {
+			x, _ = strconv.Atoi(pa[i])
+		}
+		if i < len(pb) {
+			y, _ = strconv.Atoi(pb[i])
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
@@ -0,0 +1,193 @@
+package browser
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fakeRelease serves a zip with the given entries and returns its checksum
+func fakeRelease(t *testing.T, entries map[string]string) (*httptest.Server, string) {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, body := range entries {
+		hdr := &zip.FileHeader{Name: name, Method: zip.Deflate}
+		hdr.SetMode(0755)
+		w, err := zw.CreateHeader(hdr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(body))
+	}
+	zw.Close()
+	archive := buf.Bytes()
+	sum := sha256.Sum256(archive)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, ".zip") {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(archive)
+	}))
+	t.Cleanup(server.Close)
+
+	prev := downloadBaseURL
+	downloadBaseURL = server.URL
+	t.Cleanup(func() { downloadBaseURL = prev })
+	t.Setenv("CRAWL_BROWSERS_DIR", t.TempDir())
+	return server, hex.EncodeToString(sum[:])
+}
+
+func platformOrSkip(t *testing.T) string {
+	platform, err := Platform()
+	if err != nil {
+		t.Skip(err)
+	}
+	return platform
+}
+
+func TestInstall_ListFindRemove(t *testing.T) {
+	platform := platformOrSkip(t)
+	_, sum := fakeRelease(t, map[string]string{executableName(platform): "#!/bin/sh\n"})
+
+	inst, err := Install(context.Background(), InstallOptions{Version: "120.0.1", SHA256: sum})
+	if err != nil {
+		t.Fatalf("Install failed: %v", err)
+	}
+	if !inst.Verified || inst.SHA256 != sum {
+		t.Errorf("Expected verified install with checksum %s, got %+v", sum, inst)
+	}
+	info, err := os.Stat(inst.Path)
+	if err != nil {
+		t.Fatalf("Executable missing: %v", err)
+	}
+	if info.Mode().Perm()&0100 == 0 {
+		t.Errorf("Expected executable bit to be preserved, got %v", info.Mode())
+	}
+
+	if _, err := Install(context.Background(), InstallOptions{Version: "121.0.2", AllowUnverified: true}); err != nil {
+		t.Fatalf("Second install failed: %v", err)
+	}
+	builds, err := List()
+	if err != nil || len(builds) != 2 || builds[0].Version != "121.0.2" {
+		t.Fatalf("Expected two builds, newest first, got %+v (%v)", builds, err)
+	}
+	if builds[0].Verified {
+		t.Error("Expected install without a known checksum to be unverified")
+	}
+	if got := Find(); got != builds[0].Path {
+		t.Errorf("Expected Find to return newest build %s, got %s", builds[0].Path, got)
+	}
+
+	if err := Remove("121.0.2"); err != nil {
+		t.Fatalf("Remove failed: %v", err)
+	}
+	if got := Find(); got != inst.Path {
+		t.Errorf("Expected Find to fall back to %s, got %s", inst.Path, got)
+	}
+	if err := Remove("121.0.2"); err == nil {
+		t.Error("Expected error removing a version that is not installed")
+	}
+	if err := Remove(".."); err == nil {
+		t.Error("Expected error for invalid version")
+	}
+}
+
+func TestInstall_ChecksumMismatch(t *testing.T) {
+	platform := platformOrSkip(t)
+	fakeRelease(t, map[string]string{executableName(platform): "binary"})
+
+	_, err := Install(context.Background(), InstallOptions{Version: "120.0.1", SHA256: strings.Repeat("0", 64)})
+	if err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Fatalf("Expected checksum mismatch, got %v", err)
+	}
+	if Find() != "" {
+		t.Error("Expected nothing to be installed after a failed verification")
+	}
+	dir, _ := Dir()
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 0 {
+		t.Errorf("Expected temporary files to be cleaned up, found %d entries", len(entries))
+	}
+}
+
+func TestInstall_RefusesWithoutChecksum(t *testing.T) {
+	platform := platformOrSkip(t)
+	server, _ := fakeRelease(t, map[string]string{executableName(platform): "binary"})
+	var downloads int
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { downloads++ })
+
+	_, err := Install(context.Background(), InstallOptions{Version: "120.0.1"})
+	if err == nil || !strings.Contains(err.Error(), "no checksum is known") {
+		t.Fatalf("Expected install without a checksum to be refused, got %v", err)
+	}
+	if downloads != 0 {
+		t.Errorf("Expected nothing to be downloaded, got %d requests", downloads)
+	}
+}
+
+func TestInstall_RejectsPathTraversal(t *testing.T) {
+	platformOrSkip(t)
+	fakeRelease(t, map[string]string{"../escape": "x"})
+
+	_, err := Install(context.Background(), InstallOptions{Version: "120.0.1", AllowUnverified: true})
+	if err == nil || !strings.Contains(err.Error(), "escapes") {
+		t.Fatalf("Expected path traversal to be rejected, got %v", err)
+	}
+	dir, _ := Dir()
+	if _, err := os.Stat(filepath.Join(filepath.Dir(dir), "escape")); err == nil {
+		t.Error("Archive entry was written outside the install directory")
+	}
+}
+
+func TestDir(t *testing.T) {
+	home, cache := t.TempDir(), t.TempDir()
+	t.Setenv("CRAWL_BROWSERS_DIR", "")
+	t.Setenv("CRAWL_HOME", cache)
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+
+	dir, err := Dir()
+	if err != nil {
+		t.Fatalf("Dir failed: %v", err)
+	}
+	if want := filepath.Join(cache, "browsers"); dir != want {
+		t.Errorf("Dir() = %q, want %q", dir, want)
+	}
+
+	// Builds installed by earlier versions keep being used
+	legacy := filepath.Join(home, ".crawl", "browsers")
+	if err := os.MkdirAll(legacy, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if dir, _ := Dir(); dir != legacy {
+		t.Errorf("Dir() = %q, want the existing %q", dir, legacy)
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	cases := []struct {
+		a, b string
+		want int
+	}{
+		{"131.0.6778.85", "131.0.6778.85", 0},
+		{"131.0.6778.85", "131.0.6778.204", -1},
+		{"132.0.1", "131.9.9", 1},
+		{"120", "120.0.1", -1},
+	}
+	for _, c := range cases {
+		if got := compareVersions(c.a, c.b); got != c.want {
+			t.Errorf("compareVersions(%q, %q) = %d, want %d", c.a, c.b, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,229 @@
+// internal/browser/install.go
+package browser
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// InstallOptions configures Install
+type InstallOptions struct {
+	Version         string    // Release to install (default PinnedVersion)
+	SHA256          string    // Expected archive checksum; overrides the pinned one
+	AllowUnverified bool      // Install even when no checksum is pinned or given
+	Force           bool      // Reinstall even if the version is already present
+	Progress        io.Writer // Receives download progress lines (optional)
+}
+
+// Install downloads a chrome-headless-shell release, verifies its checksum
+// and unpacks it into Dir(). The build is only moved into place once it has
+// been fully extracted, so an interrupted install never leaves a broken
+// browser behind.
+func Install(ctx context.Context, opts InstallOptions) (*Installed, error) {
+	if opts.Version == "" {
+		opts.Version = PinnedVersion
+	}
+	platform, err := Platform()
+	if err != nil {
+		return nil, err
+	}
+	dir, err := Dir()
+	if err != nil {
+		return nil, err
+	}
+
+	target := filepath.Join(dir, opts.Version)
+	if _, err := os.Stat(filepath.Join(target, manifestName)); err == nil && !opts.Force {
+		return readManifest(target)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+
+	expected := strings.ToLower(opts.SHA256)
+	if expected == "" {
+		expected = pinnedChecksums[opts.Version+"/"+platform]
+	}
+	if expected == "" && !opts.AllowUnverified {
+		return nil, fmt.Errorf("no checksum is known for chrome-headless-shell %s on %s: run 'crawl browser install --sha256 <hex>', or add --allow-unverified to install it without verification", opts.Version, platform)
+	}
+
+	// Download to a temporary file, hashing as we go
+	archive, err := os.CreateTemp(dir, "download-*.zip")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer os.Remove(archive.Name())
+	defer archive.Close()
+
+	url := fmt.Sprintf("%s/%s/%s/chrome-headless-shell-%s.zip", downloadBaseURL, opts.Version, platform, platform)
+	sum, err := download(ctx, url, archive, opts.Progress)
+	if err != nil {
+		return nil, err
+	}
+	if expected != "" && sum != expected {
+		return nil, fmt.Errorf("checksum mismatch for %s: expected %s, got %s", url, expected, sum)
+	}
+	if expected == "" {
+		log.Warn().Str("version", opts.Version).Str("sha256", sum).Msg("Installing unverified release; recorded the downloaded archive's hash")
+	}
+
+	// Extract beside the final location, then swap it in
+	staging, err := os.MkdirTemp(dir, "staging-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create staging directory: %w", err)
+	}
+	defer os.RemoveAll(staging)
+	if err := unzip(archive.Name(), staging); err != nil {
+		return nil, err
+	}
+
+	exe := filepath.Join(staging, executableName(platform))
+	if _, err := os.Stat(exe); err != nil {
+		return nil, fmt.Errorf("archive does not contain %s", executableName(platform))
+	}
+
+	inst := &Installed{
+		Version:     opts.Version,
+		Platform:    platform,
+		Path:        filepath.Join(target, executableName(platform)),
+		SHA256:      sum,
+		Verified:    expected != "",
+		InstalledAt: time.Now().UTC(),
+	}
+	raw, _ := json.MarshalIndent(inst, "", "  ")
+	if err := os.WriteFile(filepath.Join(staging, manifestName), raw, 0644); err != nil {
+		return nil, fmt.Errorf("failed to write manifest: %w", err)
+	}
+
+	if err := os.RemoveAll(target); err != nil {
+		return nil, fmt.Errorf("failed to replace %s: %w", target, err)
+	}
+	if err := os.Rename(staging, target); err != nil {
+		return nil, fmt.Errorf("failed to install to %s: %w", target, err)
+	}
+
+	log.Info().Str("version", inst.Version).Str("path", inst.Path).Msg("Browser installed")
+	return inst, nil
+}
+
+// download writes url to w and returns the hex SHA-256 of the body
+func download(ctx context.Context, url string, w io.Writer, progress io.Writer) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("download failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("download failed: %s returned %s", url, resp.Status)
+	}
+
+	hash := sha256.New()
+	var body io.Reader = resp.Body
+	if progress != nil {
+		body = &progressReader{r: resp.Body, total: resp.ContentLength, w: progress}
+	}
+	if _, err := io.Copy(io.MultiWriter(w, hash), body); err != nil {
+		return "", fmt.Errorf("download failed: %w", err)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// unzip extracts archive into dest, rejecting entries that would escape it
+func unzip(archive, dest string) error {
+	zr, err := zip.OpenReader(archive)
+	if err != nil {
+		return fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer zr.Close()
+
+	root := filepath.Clean(dest) + string(os.PathSeparator)
+	for _, f := range zr.File {
+		path := filepath.Join(dest, f.Name)
+		if !strings.HasPrefix(path, root) {
+			return fmt.Errorf("archive entry %q escapes the install directory", f.Name)
+		}
+		if f.FileInfo().IsDir() {
+			if err := os.MkdirAll(path, 0755); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := extractFile(f, path); err != nil {
+			return fmt.Errorf("failed to extract %s: %w", f.Name, err)
+		}
+	}
+	return nil
+}
+
+func extractFile(f *zip.File, path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	src, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	// Keep the executable bits from the archive
+	mode := f.Mode().Perm() | 0600
+	dst, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		dst.Close()
+		return err
+	}
+	return dst.Close()
+}
+
+func readManifest(dir string) (*Installed, error) {
+	raw, err := os.ReadFile(filepath.Join(dir, manifestName))
+	if err != nil {
+		return nil, err
+	}
+	var inst Installed
+	if err := json.Unmarshal(raw, &inst); err != nil {
+		return nil, fmt.Errorf("corrupt manifest in %s: %w", dir, err)
+	}
+	return &inst, nil
+}
+
+// progressReader prints download progress in 10% steps
+type progressReader struct {
+	r     io.Reader
+	w     io.Writer
+	total int64
+	read  int64
+	last  int64
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	p.read += int64(n)
+	if p.total > 0 {
+		if pct := p.read * 100 / p.total; pct >= p.last+10 || (err == io.EOF && pct != p.last) {
+			p.last = pct
+			fmt.Fprintf(p.w, "  %3d%% (%.1f MB)\n", pct, float64(p.read)/(1024*1024))
+		}
+	}
+	return n, err
+}
@@ -0,0 +1,95 @@
+// internal/budget/budget.go
+package budget
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrExceeded is returned (wrapped) for work that was skipped because the
+// run's time or request budget was used up. Test with errors.Is.
+var ErrExceeded = errors.New("run budget exceeded")
+
+// Budget limits how long a run may take and how many requests it may make.
+// Zero values mean unlimited.
+type Budget struct {
+	MaxDuration time.Duration
+	MaxRequests int
+}
+
+// Unlimited reports whether no limits are set
+func (b Budget) Unlimited() bool {
+	return b.MaxDuration <= 0 && b.MaxRequests <= 0
+}
+
+// Tracker enforces a Budget for a single run. It is safe for concurrent use;
+// a nil Tracker never refuses.
+type Tracker struct {
+	budget   Budget
+	deadline time.Time
+	mu       sync.Mutex
+	used     int
+	reason   string
+	stopped  error
+}
+
+// Start begins tracking a run against b
+func Start(b Budget) *Tracker {
+	t := &Tracker{budget: b}
+	if b.MaxDuration > 0 {
+		t.deadline = time.Now().Add(b.MaxDuration)
+	}
+	return t
+}
+
+// Take claims one request from the budget. Once either limit is hit every
+// subsequent call fails, so in-flight work finishes but nothing new starts.
+func (t *Tracker) Take() error {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.stopped != nil {
+		return t.stopped
+	}
+	if t.reason == "" {
+		switch {
+		case !t.deadline.IsZero() && time.Now().After(t.deadline):
+			t.reason = fmt.Sprintf("max duration %s reached", t.budget.MaxDuration)
+		case t.budget.MaxRequests > 0 && t.used >= t.budget.MaxRequests:
+			t.reason = fmt.Sprintf("max requests %d reached", t.budget.MaxRequests)
+		}
+	}
+	if t.reason != "" {
+		return fmt.Errorf("%w: %s", ErrExceeded, t.reason)
+	}
+	t.used++
+	return nil
+}
+
+// Exceeded returns the reason the budget was exhausted, or "" if it was not
+func (t *Tracker) Exceeded() string {
+	if t == nil {
+		return ""
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.reason
+}
+
+// Stop makes every later Take fail with err, for runs that end early for
+// reasons other than the budget (e.g. fail-fast)
+func (t *Tracker) Stop(err error) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.stopped == nil {
+		t.stopped = err
+	}
+}
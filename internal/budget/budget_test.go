@@ -0,0 +1,59 @@
+package budget
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTracker_MaxRequests(t *testing.T) {
+	tr := Start(Budget{MaxRequests: 2})
+	for i := 0; i < 2; i++ {
+		if err := tr.Take(); err != nil {
+			t.Fatalf("Take %d failed: %v", i, err)
+		}
+	}
+	if err := tr.Take(); !errors.Is(err, ErrExceeded) {
+		t.Errorf("Expected ErrExceeded, got %v", err)
+	}
+	if tr.Exceeded() == "" {
+		t.Error("Expected exceeded reason")
+	}
+}
+
+func TestTracker_MaxDuration(t *testing.T) {
+	tr := Start(Budget{MaxDuration: 10 * time.Millisecond})
+	if err := tr.Take(); err != nil {
+		t.Fatalf("Take failed: %v", err)
+	}
+	time.Sleep(20 * time.Millisecond)
+	if err := tr.Take(); !errors.Is(err, ErrExceeded) {
+		t.Errorf("Expected ErrExceeded after deadline, got %v", err)
+	}
+}
+
+func TestTracker_Unlimited(t *testing.T) {
+	var nilTracker *Tracker
+	if err := nilTracker.Take(); err != nil {
+		t.Errorf("nil tracker should never refuse: %v", err)
+	}
+	tr := Start(Budget{})
+	for i := 0; i < 1000; i++ {
+		if err := tr.Take(); err != nil {
+			t.Fatalf("unlimited budget refused: %v", err)
+		}
+	}
+}
+
+func TestTracker_Stop(t *testing.T) {
+	tr := Start(Budget{})
+	if err := tr.Take(); err != nil {
+		t.Fatalf("Take failed: %v", err)
+	}
+	stop := errors.New("stopped")
+	tr.Stop(stop)
+	tr.Stop(errors.New("ignored"))
+	if err := tr.Take(); err != stop {
+		t.Errorf("Expected the first stop error, got %v", err)
+	}
+}
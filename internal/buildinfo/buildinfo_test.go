@@ -0,0 +1,41 @@
+package buildinfo
+
+import (
+	"runtime"
+	"runtime/debug"
+	"testing"
+)
+
+func TestGet(t *testing.T) {
+	info := Get()
+	if info.Version != Version || info.GoVersion != runtime.Version() {
+		t.Errorf("Unexpected info: %+v", info)
+	}
+	if info.OS != runtime.GOOS || info.Arch != runtime.GOARCH {
+		t.Errorf("Expected %s/%s, got %s/%s", runtime.GOOS, runtime.GOARCH, info.OS, info.Arch)
+	}
+}
+
+func TestApplyVCS(t *testing.T) {
+	settings := []debug.BuildSetting{
+		{Key: "vcs.revision", Value: "0123456789abcdef0123"},
+		{Key: "vcs.time", Value: "2026-01-02T03:04:05Z"},
+		{Key: "vcs.modified", Value: "true"},
+	}
+
+	var info Info
+	applyVCS(&info, settings)
+	if info.Commit != "0123456789abcdef0123" || info.Date != "2026-01-02T03:04:05Z" || !info.Modified {
+		t.Errorf("Expected the VCS settings to be applied, got %+v", info)
+	}
+	if info.ShortCommit() != "0123456789ab" {
+		t.Errorf("Expected a 12 character commit, got %q", info.ShortCommit())
+	}
+
+	// Values set with -ldflags win
+	stamped := Info{Commit: "release", Date: "today"}
+	applyVCS(&stamped, settings)
+	if stamped.Commit != "release" || stamped.Date != "today" {
+		t.Errorf("Expected ldflags values to be kept, got %+v", stamped)
+	}
+}
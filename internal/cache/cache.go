@@ -44,6 +44,41 @@ type cacheEntry struct {
 	Data      *models.PageData
 	ExpiresAt time.Time
 	Key       string // For LRU tracking
+	Size      int64  // Size charged when stored, so removal subtracts exactly the same amount
+}
+
+// entryOverhead approximates the fixed cost of an entry: the PageData struct,
+// the list element, the map slot and slice/map headers
+const entryOverhead = 512
+
+// entrySize estimates the memory held by a cached page, counting every
+// string it retains
+func entrySize(key string, data *models.PageData) int64 {
+	size := int64(entryOverhead + len(key))
+	if data == nil {
+		return size
+	}
+	size += int64(len(data.URL) + len(data.Title) + len(data.Content) + len(data.HTML))
+	for _, item := range data.Data {
+		size += int64(len(item.Selector) + len(item.Text) + len(item.HTML))
+	}
+	for _, row := range data.Structured {
+		for k, v := range row {
+			size += int64(len(k) + len(v))
+		}
+	}
+	for k, v := range data.Headers {
+		size += int64(len(k) + len(v))
+	}
+	for k, v := range data.Metadata {
+		size += int64(len(k) + len(v))
+	}
+	for _, list := range [][]string{data.Links, data.Images, data.Scripts} {
+		for _, s := range list {
+			size += int64(len(s))
+		}
+	}
+	return size
 }
 
 // Point 7: LRU cache implementation for smart eviction
@@ -54,6 +89,7 @@ type MemoryCache struct {
 	mu      sync.RWMutex
 	maxSize int64 // Maximum cache size in bytes
 	size    int64 // Current size in bytes
+	maxLen  int   // Maximum number of entries (0 = unlimited)
 	ctx     context.Context
 	cancel  context.CancelFunc
 	hits    uint64 // Cache hit counter
@@ -83,6 +119,20 @@ func NewMemoryCache(maxSizeBytes int64) *MemoryCache {
 	return cache
 }
 
+// SetMaxEntries caps the number of cached entries in addition to the byte
+// limit. Zero or a negative value removes the cap.
+func (mc *MemoryCache) SetMaxEntries(n int) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	if n < 0 {
+		n = 0
+	}
+	mc.maxLen = n
+	for mc.overCapacity(0, 0) {
+		mc.evictLRU()
+	}
+}
+
 // Get retrieves a cached response
 // Point 8B: Manual unlock instead of defer for hot path optimization
 // Point 7: LRU - moves accessed item to front of list
@@ -100,9 +150,9 @@ func (mc *MemoryCache) Get(key string) (*models.PageData, bool) {
 	// Check if expired
 	if time.Now().After(entry.ExpiresAt) {
 		mc.misses++
+		// Expired, delete it while the lock is held so a concurrent Set isn't lost
+		mc.removeElement(element)
 		mc.mu.Unlock()
-		// Expired, delete it
-		go mc.Delete(key)
 		return nil, false
 	}
 
@@ -125,26 +175,29 @@ func (mc *MemoryCache) Set(key string, data *models.PageData, ttl time.Duration)
 	mc.mu.Lock()
 	defer mc.mu.Unlock()
 
-	// Estimate size (rough approximation)
-	size := int64(len(data.HTML) + len(data.Content) + len(data.Title))
-	size += 1024 // Add ~1KB overhead for struct, pointers, maps, slices
+	size := entrySize(key, data)
 
 	// Check if key already exists - update it
 	if element, exists := mc.store[key]; exists {
 		oldEntry := element.Value.(*cacheEntry)
-		oldSize := int64(len(oldEntry.Data.HTML) + len(oldEntry.Data.Content))
-		mc.size -= oldSize
+		mc.size -= oldEntry.Size
 
 		// Update entry
 		entry := &cacheEntry{
 			Data:      data,
 			ExpiresAt: time.Now().Add(ttl),
 			Key:       key,
+			Size:      size,
 		}
 		element.Value = entry
 		mc.lruList.MoveToFront(element)
 		mc.size += size
 
+		// A larger value may push the cache over its limit; evict others first
+		for mc.overCapacity(0, 0) && mc.lruList.Back() != element {
+			mc.evictLRU()
+		}
+
 		log.Debug().
 			Str("key", key).
 			Dur("ttl", ttl).
@@ -155,7 +208,7 @@ func (mc *MemoryCache) Set(key string, data *models.PageData, ttl time.Duration)
 	}
 
 	// Check if we need to evict entries (LRU eviction)
-	for mc.size+size > mc.maxSize && mc.lruList.Len() > 0 {
+	for mc.overCapacity(size, 1) && mc.lruList.Len() > 0 {
 		mc.evictLRU()
 	}
 
@@ -163,6 +216,7 @@ func (mc *MemoryCache) Set(key string, data *models.PageData, ttl time.Duration)
 		Data:      data,
 		ExpiresAt: time.Now().Add(ttl),
 		Key:       key,
+		Size:      size,
 	}
 
 	// Add to front of list (most recently used)
@@ -185,11 +239,7 @@ func (mc *MemoryCache) Delete(key string) error {
 	defer mc.mu.Unlock()
 
 	if element, exists := mc.store[key]; exists {
-		entry := element.Value.(*cacheEntry)
-		size := int64(len(entry.Data.HTML) + len(entry.Data.Content))
-		mc.lruList.Remove(element)
-		delete(mc.store, key)
-		mc.size -= size
+		mc.removeElement(element)
 		log.Debug().Str("key", key).Msg("Deleted from cache")
 	}
 
@@ -225,14 +275,26 @@ func (mc *MemoryCache) evictLRU() {
 		return
 	}
 
-	entry := element.Value.(*cacheEntry)
-	size := int64(len(entry.Data.HTML) + len(entry.Data.Content))
+	entry := mc.removeElement(element)
+	log.Debug().Str("key", entry.Key).Msg("Evicted from cache (LRU)")
+}
+
+// overCapacity reports whether adding extraBytes and extraEntries would exceed
+// the size or entry limits (must be called with lock held)
+func (mc *MemoryCache) overCapacity(extraBytes int64, extraEntries int) bool {
+	if mc.size+extraBytes > mc.maxSize {
+		return true
+	}
+	return mc.maxLen > 0 && mc.lruList.Len()+extraEntries > mc.maxLen
+}
 
+// removeElement unlinks an entry and releases its recorded size (must be called with lock held)
+func (mc *MemoryCache) removeElement(element *list.Element) *cacheEntry {
+	entry := element.Value.(*cacheEntry)
 	mc.lruList.Remove(element)
 	delete(mc.store, entry.Key)
-	mc.size -= size
-
-	log.Debug().Str("key", entry.Key).Msg("Evicted from cache (LRU)")
+	mc.size -= entry.Size
+	return entry
 }
 
 // cleanupExpired periodically removes expired entries
@@ -253,10 +315,7 @@ func (mc *MemoryCache) cleanupExpired() {
 				entry := element.Value.(*cacheEntry)
 
 				if now.After(entry.ExpiresAt) {
-					size := int64(len(entry.Data.HTML) + len(entry.Data.Content))
-					mc.lruList.Remove(element)
-					delete(mc.store, entry.Key)
-					mc.size -= size
+					mc.removeElement(element)
 				}
 			}
 			mc.mu.Unlock()
@@ -282,6 +341,7 @@ func (mc *MemoryCache) Stats() map[string]interface{} {
 		"entries":     mc.lruList.Len(),
 		"size_bytes":  mc.size,
 		"max_size":    mc.maxSize,
+		"max_entries": mc.maxLen,
 		"utilization": float64(mc.size) / float64(mc.maxSize) * 100,
 		"hits":        mc.hits,
 		"misses":      mc.misses,
@@ -289,6 +349,13 @@ func (mc *MemoryCache) Stats() map[string]interface{} {
 	}
 }
 
+// Counters returns the cache hit and miss counts
+func (mc *MemoryCache) Counters() (hits, misses uint64) {
+	mc.mu.RLock()
+	defer mc.mu.RUnlock()
+	return mc.hits, mc.misses
+}
+
 // CacheKeyFromURL generates a cache key from a URL and selector
 func CacheKeyFromURL(url, selector string) string {
 	if selector != "" && selector != "body" {
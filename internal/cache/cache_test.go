@@ -0,0 +1,150 @@
+package cache
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/law-makers/crawl/pkg/models"
+)
+
+// checkInvariants verifies the tracked size equals the sum of entry sizes
+// and that the map and LRU list agree
+func checkInvariants(t *testing.T, mc *MemoryCache) {
+	t.Helper()
+	mc.mu.RLock()
+	defer mc.mu.RUnlock()
+
+	var total int64
+	for element := mc.lruList.Front(); element != nil; element = element.Next() {
+		entry := element.Value.(*cacheEntry)
+		if mc.store[entry.Key] != element {
+			t.Errorf("List entry %q is not indexed in the store", entry.Key)
+		}
+		total += entry.Size
+	}
+	if total != mc.size {
+		t.Errorf("Size drift: tracked %d, entries sum to %d", mc.size, total)
+	}
+	if len(mc.store) != mc.lruList.Len() {
+		t.Errorf("Store has %d keys but list has %d entries", len(mc.store), mc.lruList.Len())
+	}
+	if mc.size < 0 || mc.size > mc.maxSize {
+		t.Errorf("Size %d outside [0, %d]", mc.size, mc.maxSize)
+	}
+}
+
+func page(n int) *models.PageData {
+	return &models.PageData{
+		URL:     fmt.Sprintf("https://example.com/%d", n),
+		Title:   "Example",
+		Content: strings.Repeat("c", n),
+		HTML:    strings.Repeat("h", n),
+		Headers: map[string]string{"Content-Type": "text/html"},
+		Links:   []string{"https://example.com/a", "https://example.com/b"},
+	}
+}
+
+func TestMemoryCache_SizeAccountingNoDrift(t *testing.T) {
+	mc := NewMemoryCache(1024 * 1024)
+	defer mc.Close()
+
+	for i := 0; i < 50; i++ {
+		mc.Set(fmt.Sprintf("k%d", i%10), page(i*100), time.Minute)
+		checkInvariants(t, mc)
+	}
+	for i := 0; i < 10; i += 2 {
+		mc.Delete(fmt.Sprintf("k%d", i))
+		checkInvariants(t, mc)
+	}
+	for i := 1; i < 10; i += 2 {
+		mc.Delete(fmt.Sprintf("k%d", i))
+	}
+	checkInvariants(t, mc)
+	if mc.size != 0 {
+		t.Errorf("Expected size 0 after deleting everything, got %d", mc.size)
+	}
+}
+
+func TestMemoryCache_EntrySizeCountsAllFields(t *testing.T) {
+	bare := &models.PageData{URL: "https://example.com"}
+	full := &models.PageData{
+		URL:      "https://example.com",
+		Headers:  map[string]string{"X-Header": strings.Repeat("v", 100)},
+		Metadata: map[string]string{"description": strings.Repeat("d", 100)},
+		Links:    []string{strings.Repeat("l", 100)},
+		Images:   []string{strings.Repeat("i", 100)},
+		Scripts:  []string{strings.Repeat("s", 100)},
+	}
+	if diff := entrySize("k", full) - entrySize("k", bare); diff < 500 {
+		t.Errorf("Expected headers, metadata and links to be counted, size grew by only %d", diff)
+	}
+}
+
+func TestMemoryCache_EvictsToStayWithinLimit(t *testing.T) {
+	limit := 3 * entrySize("k00", page(1000))
+	mc := NewMemoryCache(limit)
+	defer mc.Close()
+
+	for i := 10; i < 30; i++ {
+		mc.Set(fmt.Sprintf("k%d", i), page(1000), time.Minute)
+		checkInvariants(t, mc)
+	}
+	if n := mc.lruList.Len(); n != 3 {
+		t.Errorf("Expected 3 entries to fit, got %d", n)
+	}
+	if _, ok := mc.Get("k29"); !ok {
+		t.Error("Expected most recent entry to be retained")
+	}
+	if _, ok := mc.Get("k10"); ok {
+		t.Error("Expected oldest entry to be evicted")
+	}
+}
+
+func TestMemoryCache_UpdateGrowingEntryEvicts(t *testing.T) {
+	limit := 3 * entrySize("k0", page(1000))
+	mc := NewMemoryCache(limit)
+	defer mc.Close()
+
+	for i := 0; i < 3; i++ {
+		mc.Set(fmt.Sprintf("k%d", i), page(1000), time.Minute)
+	}
+	mc.Set("k2", page(2000), time.Minute)
+	checkInvariants(t, mc)
+	if _, ok := mc.Get("k2"); !ok {
+		t.Error("Expected updated entry to be retained")
+	}
+}
+
+func TestMemoryCache_MaxEntries(t *testing.T) {
+	mc := NewMemoryCache(1024 * 1024)
+	defer mc.Close()
+	mc.SetMaxEntries(2)
+
+	for i := 0; i < 5; i++ {
+		mc.Set(fmt.Sprintf("k%d", i), page(10), time.Minute)
+		checkInvariants(t, mc)
+	}
+	if n := mc.lruList.Len(); n != 2 {
+		t.Errorf("Expected 2 entries, got %d", n)
+	}
+	if _, ok := mc.Get("k4"); !ok {
+		t.Error("Expected most recent entry to be retained")
+	}
+}
+
+func TestMemoryCache_ExpiredGetReleasesSize(t *testing.T) {
+	mc := NewMemoryCache(1024 * 1024)
+	defer mc.Close()
+
+	mc.Set("k", page(100), time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+	if _, ok := mc.Get("k"); ok {
+		t.Error("Expected expired entry to miss")
+	}
+	checkInvariants(t, mc)
+	if mc.size != 0 {
+		t.Errorf("Expected size 0 after expiry, got %d", mc.size)
+	}
+}
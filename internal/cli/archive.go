@@ -0,0 +1,91 @@
+package cli
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/law-makers/crawl/internal/attest"
+	"github.com/law-makers/crawl/internal/sink"
+	"github.com/law-makers/crawl/internal/ui"
+	"github.com/law-makers/crawl/internal/utils/archive"
+	"github.com/spf13/cobra"
+)
+
+var archivePath string
+
+// addArchiveFlags registers --archive on cmd
+func addArchiveFlags(cmd *cobra.Command, usage string) {
+	cmd.Flags().StringVar(&archivePath, "archive", "", usage)
+}
+
+// checkArchive rejects an --archive path this run can't write, before any
+// work is done. outputs are the destinations the files to pack are written
+// to, when the command writes to --output rather than a directory.
+func checkArchive(outputs ...string) error {
+	if archivePath == "" {
+		return nil
+	}
+	if !archive.Supported(archivePath) {
+		return fmt.Errorf("invalid archive: %s (must end in .zip, .tar.gz or .tgz)", archivePath)
+	}
+	for _, out := range outputs {
+		if out == "" || sink.IsURL(out) {
+			return fmt.Errorf("--archive packs the files written to --output and needs an --output file")
+		}
+	}
+	return nil
+}
+
+// writeArchive packs entries, with an index.json manifest, into --archive.
+// Names are relative to baseDir, or when it is empty to the deepest directory
+// holding every entry, so links between them (a Markdown file and its
+// images) still resolve once unpacked.
+func writeArchive(baseDir string, entries []archive.Entry) error {
+	if archivePath == "" || len(entries) == 0 {
+		return nil
+	}
+	if signKey != "" && output != "" {
+		// The attestation --sign wrote next to the output
+		entries = append(entries, archive.Entry{Path: output + attest.Ext, ContentType: "application/json"})
+	}
+	if baseDir == "" {
+		baseDir = commonDir(entries)
+	}
+	if err := archive.Create(archivePath, baseDir, entries); err != nil {
+		return fmt.Errorf("failed to create archive: %w", err)
+	}
+	link := terminalHyperlink(filepath.Base(archivePath), archivePath)
+	ui.Printf("\n%s %s\n", ui.Success(ui.Mark(ui.IconSuccess, ui.T("archive.written"))), ui.Bold(link))
+	return nil
+}
+
+// commonDir returns the deepest directory that contains every entry
+func commonDir(entries []archive.Entry) string {
+	var dir string
+	for i, e := range entries {
+		abs, err := filepath.Abs(e.Path)
+		if err != nil {
+			continue
+		}
+		d := filepath.Dir(abs)
+		if i == 0 || dir == "" {
+			dir = d
+			continue
+		}
+		for dir != filepath.Dir(dir) && d != dir && !strings.HasPrefix(d, dir+string(filepath.Separator)) {
+			dir = filepath.Dir(dir)
+		}
+	}
+	return dir
+}
+
+// fileEntries lists files for an archive; url is their source, if they hold
+// a single page
+func fileEntries(files []string, url string) []archive.Entry {
+	entries := make([]archive.Entry, len(files))
+	for i, f := range files {
+		entries[i] = archive.Entry{Path: f, URL: url}
+	}
+	return entries
+}
@@ -0,0 +1,142 @@
+// internal/cli/assert.go
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/law-makers/crawl/internal/expr"
+	"github.com/law-makers/crawl/internal/ui"
+	"github.com/law-makers/crawl/pkg/models"
+	"github.com/spf13/cobra"
+)
+
+// DefaultAssertExitCode is used when page assertions fail, distinct from
+// errors (1) and failed requests (2)
+const DefaultAssertExitCode = 3
+
+var (
+	assertPresent  []string
+	assertAbsent   []string
+	assertMinCount []string
+	assertExprs    []string
+	assertExitCode int
+
+	// Compiled --assert-expr expressions, checked against each page as
+	// fetched, before --redact and any --plugin
+	exprAssertions []*expr.Program
+)
+
+// addAssertFlags registers the page assertion flags
+func addAssertFlags(cmd *cobra.Command) {
+	cmd.Flags().StringArrayVar(&assertPresent, "assert-selector", nil, "Fail unless the page has an element matching this selector (repeatable)")
+	cmd.Flags().StringArrayVar(&assertAbsent, "assert-absent", nil, "Fail if the page has an element matching this selector, e.g. .captcha (repeatable)")
+	cmd.Flags().StringArrayVar(&assertMinCount, "assert-min-count", nil, "Fail unless the selector matches at least N elements: \".result:10\" (repeatable)")
+	cmd.Flags().StringArrayVar(&assertExprs, "assert-expr", nil, "Fail unless this CEL expression over the page is true, e.g. 'status == 200 && size(items) >= 10' (repeatable)")
+	cmd.Flags().IntVar(&assertExitCode, "assert-exit-code", DefaultAssertExitCode, "Exit code when an assertion fails")
+}
+
+// parseAssertions builds the assertions from the assertion flags
+func parseAssertions() ([]models.Assertion, error) {
+	if assertExitCode < 1 || assertExitCode > 125 {
+		return nil, fmt.Errorf("invalid --assert-exit-code %d (must be 1-125)", assertExitCode)
+	}
+
+	var assertions []models.Assertion
+	for _, sel := range assertPresent {
+		assertions = append(assertions, models.Assertion{Selector: sel, Min: 1, Max: -1})
+	}
+	for _, sel := range assertAbsent {
+		assertions = append(assertions, models.Assertion{Selector: sel, Min: 0, Max: 0})
+	}
+	for _, spec := range assertMinCount {
+		// The count follows the last colon; selectors may contain colons themselves
+		i := strings.LastIndex(spec, ":")
+		n, err := strconv.Atoi(strings.TrimSpace(spec[i+1:]))
+		if i <= 0 || err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid --assert-min-count %q (use selector:count, e.g. \".result:10\")", spec)
+		}
+		assertions = append(assertions, models.Assertion{Selector: strings.TrimSpace(spec[:i]), Min: n, Max: -1})
+	}
+	exprAssertions = nil
+	for _, src := range assertExprs {
+		p, err := expr.Compile(src)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --assert-expr: %w", err)
+		}
+		exprAssertions = append(exprAssertions, p)
+	}
+	return assertions, nil
+}
+
+// evalExprAssertions adds the --assert-expr results to the page's
+// assertions. An expression that fails to evaluate, such as one reading a
+// missing header, counts as failed.
+func evalExprAssertions(page *models.PageData) {
+	for _, p := range exprAssertions {
+		r := models.AssertionResult{Assertion: models.Assertion{Expr: p.Source}}
+		ok, err := p.Eval(page)
+		if err != nil {
+			r.Error = err.Error()
+		}
+		r.Passed = ok && err == nil
+		page.Assertions = append(page.Assertions, r)
+	}
+}
+
+// AssertionError is returned when a fetched page fails its assertions
+type AssertionError struct {
+	Failed int // Failed assertions across all pages
+	Pages  int // Pages with at least one failed assertion
+	Code   int
+}
+
+// ExitCode returns the process exit code for the failed checks
+func (e *AssertionError) ExitCode() int {
+	return e.Code
+}
+
+// Error implements the error interface
+func (e *AssertionError) Error() string {
+	if e.Pages > 1 {
+		return fmt.Sprintf("%d assertion(s) failed on %d pages", e.Failed, e.Pages)
+	}
+	return fmt.Sprintf("%d assertion(s) failed", e.Failed)
+}
+
+// checkAssertions reports failed assertions on stderr and returns an
+// *AssertionError when any page failed one
+func checkAssertions(pages ...*models.PageData) error {
+	failed, failedPages := 0, 0
+	for _, p := range pages {
+		if n := reportAssertions(p); n > 0 {
+			failed += n
+			failedPages++
+		}
+	}
+	return assertionError(failed, failedPages)
+}
+
+// reportAssertions prints the page's failed assertions on stderr and
+// returns how many there were
+func reportAssertions(p *models.PageData) int {
+	failed := 0
+	for _, r := range p.Assertions {
+		if r.Passed {
+			continue
+		}
+		failed++
+		fmt.Fprintf(os.Stderr, "%s %s: %s\n", ui.Error(ui.Mark(ui.IconFailure, ui.T("assert.failed"))), p.URL, r)
+	}
+	return failed
+}
+
+// assertionError returns an *AssertionError for failed assertions, or nil
+func assertionError(failed, failedPages int) error {
+	if failed == 0 {
+		return nil
+	}
+	return &AssertionError{Failed: failed, Pages: failedPages, Code: assertExitCode}
+}
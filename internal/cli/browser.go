@@ -0,0 +1,152 @@
+// internal/cli/browser.go
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/law-makers/crawl/internal/browser"
+	"github.com/law-makers/crawl/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	browserVersion    string
+	browserSHA256     string
+	browserForce      bool
+	browserAll        bool
+	browserUnverified bool
+)
+
+// browserCmd groups the managed browser subcommands
+var browserCmd = &cobra.Command{
+	Use:   "browser",
+	Short: "Manage the headless browser used for SPA mode",
+	Long: `Installs and manages chrome-headless-shell builds for SPA mode.
+
+Crawl uses a system Chrome/Chromium when one is found. On machines without
+one, 'crawl browser install' downloads a pinned chrome-headless-shell
+release into the user cache directory (~/.cache/crawl/browsers on Linux,
+%LocalAppData%\crawl\browsers on Windows, or $CRAWL_BROWSERS_DIR), and SPA
+mode uses it automatically. Set browser_auto_install: true in the config file (or
+CRAWL_BROWSER_AUTO_INSTALL=1) to install on first use.
+
+Each archive is checked against its SHA-256 before it is unpacked. Releases
+without a checksum built into crawl need --sha256, or --allow-unverified to
+install them unchecked; auto-install only installs releases it can verify.`,
+	Example: `  # Install the pinned release, verifying the archive checksum
+  crawl browser install --sha256 <hex>
+
+  # Install a specific release and verify its archive checksum
+  crawl browser install --version 131.0.6778.85 --sha256 <hex>
+
+  # Show installed builds
+  crawl browser list
+
+  # Remove a build
+  crawl browser remove 131.0.6778.85`,
+}
+
+var browserInstallCmd = &cobra.Command{
+	Use:   "install",
+	Short: "Download chrome-headless-shell",
+	Args:  cobra.NoArgs,
+	RunE:  runBrowserInstall,
+}
+
+var browserListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List installed browsers",
+	Args:  cobra.NoArgs,
+	RunE:  runBrowserList,
+}
+
+var browserRemoveCmd = &cobra.Command{
+	Use:   "remove [version]",
+	Short: "Remove an installed browser",
+	Args:  cobra.MaximumNArgs(1),
+	RunE:  runBrowserRemove,
+}
+
+func init() {
+	rootCmd.AddCommand(browserCmd)
+	browserCmd.AddCommand(browserInstallCmd, browserListCmd, browserRemoveCmd)
+
+	browserInstallCmd.Flags().StringVar(&browserVersion, "version", browser.PinnedVersion, "Chrome for Testing release to install")
+	browserInstallCmd.Flags().StringVar(&browserSHA256, "sha256", "", "Expected SHA-256 of the release archive")
+	browserInstallCmd.Flags().BoolVar(&browserUnverified, "allow-unverified", false, "Install a release with no known checksum without verifying it")
+	browserInstallCmd.Flags().BoolVar(&browserForce, "force", false, "Reinstall even if the version is already installed")
+	browserRemoveCmd.Flags().BoolVar(&browserAll, "all", false, "Remove every installed browser")
+}
+
+func runBrowserInstall(cmd *cobra.Command, args []string) error {
+	ui.Printf("%s %s\n", ui.Info(ui.T("browser.install")), browserVersion)
+	inst, err := browser.Install(context.Background(), browser.InstallOptions{
+		Version:         browserVersion,
+		SHA256:          browserSHA256,
+		AllowUnverified: browserUnverified,
+		Force:           browserForce,
+		Progress:        os.Stdout,
+	})
+	if err != nil {
+		return err
+	}
+
+	ui.Printf("%s %s\n", ui.Success(ui.Mark(ui.IconSuccess, ui.T("browser.installed"))), inst.Path)
+	if !inst.Verified {
+		fmt.Println(ui.Warning("  " + ui.T("browser.checksum", inst.SHA256)))
+	}
+	return nil
+}
+
+func runBrowserList(cmd *cobra.Command, args []string) error {
+	builds, err := browser.List()
+	if err != nil {
+		return err
+	}
+	if len(builds) == 0 {
+		fmt.Println(ui.Info(ui.T("browser.none")))
+		return nil
+	}
+
+	active := browser.Find()
+	for _, b := range builds {
+		marker := " "
+		if b.Path == active {
+			marker = "*"
+		}
+		verified := "unverified"
+		if b.Verified {
+			verified = "verified"
+		}
+		fmt.Printf("%s %s  %s  %s  %s\n", marker, ui.Bold(b.Version), b.Platform, verified, b.Path)
+	}
+	return nil
+}
+
+func runBrowserRemove(cmd *cobra.Command, args []string) error {
+	var versions []string
+	switch {
+	case browserAll:
+		builds, err := browser.List()
+		if err != nil {
+			return err
+		}
+		for _, b := range builds {
+			versions = append(versions, b.Version)
+		}
+	case len(args) == 1:
+		versions = args
+	default:
+		return fmt.Errorf("specify a version to remove, or --all")
+	}
+
+	for _, v := range versions {
+		if err := browser.Remove(v); err != nil {
+			return err
+		}
+		ui.Printf("%s %s\n", ui.Success(ui.Mark(ui.IconSuccess, ui.T("browser.removed"))), v)
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+// internal/cli/budget.go
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/law-makers/crawl/internal/budget"
+	"github.com/law-makers/crawl/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// addBudgetFlags registers --max-duration and --max-requests on a command
+// that fetches many URLs. crawl media has its own, worded for downloads.
+func addBudgetFlags(cmd *cobra.Command) {
+	cmd.Flags().DurationVar(&maxDuration, "max-duration", 0, "Stop starting new requests after this long, e.g. 30m (0 for no limit)")
+	cmd.Flags().IntVar(&maxRequests, "max-requests", 0, "Fetch at most this many URLs; the rest are skipped (0 for no limit)")
+}
+
+// runBudget returns the budget set by the budget flags
+func runBudget() budget.Budget {
+	return budget.Budget{MaxDuration: maxDuration, MaxRequests: maxRequests}
+}
+
+// reportOverBudget lists the URLs the run budget left unfetched, on stderr
+func reportOverBudget(urls []string, reason error) {
+	if len(urls) == 0 {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "%s %s\n", ui.Warning(ui.Mark(ui.IconWarning, ui.T("batch.stopped_early"))), ui.T("batch.over_budget", reason, len(urls)))
+	for _, u := range urls {
+		fmt.Fprintf(os.Stderr, "  %s %s\n", ui.Dim("-"), u)
+	}
+}
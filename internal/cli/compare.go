@@ -0,0 +1,221 @@
+// internal/cli/compare.go
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/law-makers/crawl/internal/compare"
+	"github.com/law-makers/crawl/internal/policy"
+	"github.com/law-makers/crawl/internal/ui"
+	headersutil "github.com/law-makers/crawl/internal/utils/headers"
+	urlutil "github.com/law-makers/crawl/internal/utils/url"
+	"github.com/law-makers/crawl/pkg/models"
+	"github.com/spf13/cobra"
+)
+
+var (
+	compareVariants string
+	compareAll      bool
+)
+
+// compareWidth is the most characters of a value shown in the table
+const compareWidth = 40
+
+// compareCmd represents the compare command
+var compareCmd = &cobra.Command{
+	Use:   "compare <url>",
+	Short: "Fetch a URL as different devices, regions or languages and compare the results",
+	Long: `Fetches the same URL once per variant, all at once, and reports the fields
+that came back different: status, final URL, title, the selected content,
+--fields values, metadata and a few telling response headers.
+
+A variant is one or more of these, joined with "+":
+
+  ua:<device>        desktop, mac, iphone, ipad or android user agent
+  proxy:<name|url>   a proxy from the config file's proxies block, or a URL
+  lang:<language>    the Accept-Language header, e.g. lang:de-DE
+  header:<Name>=<v>  any other request header
+
+Only differing fields are shown unless --all is given; --json prints the
+full report.`,
+	Example: `  # Compare what a phone and a desktop see
+  crawl compare https://shop.example.com/p/1 --variants "ua:iphone,ua:desktop"
+
+  # Compare prices across regions, using proxies named in the config file
+  crawl compare https://shop.example.com/p/1 --variants "proxy:us,proxy:de" --selector ".price"
+
+  # Device and region together, with structured fields, as JSON
+  crawl compare https://shop.example.com/p/1 --variants "ua:iphone+proxy:us,ua:desktop+proxy:de" --fields "price=.price,stock=.stock" --json`,
+	Args: cobra.ExactArgs(1),
+	RunE: runCompare,
+}
+
+func init() {
+	rootCmd.AddCommand(compareCmd)
+
+	compareCmd.Flags().StringVar(&compareVariants, "variants", "", "Comma-separated variants to fetch, e.g. \"ua:iphone,ua:desktop,proxy:us,proxy:de\" (required)")
+	compareCmd.Flags().StringVarP(&mode, "mode", "m", "auto", "Scraper mode: auto, static, or spa")
+	compareCmd.Flags().StringVarP(&selector, "selector", "s", "body", "CSS selector whose content is compared (e.g., .price)")
+	compareCmd.Flags().StringVar(&fields, "fields", "", "Structured fields to compare (e.g., price=.price,stock=.stock)")
+	compareCmd.Flags().StringArrayVarP(&headers, "header", "H", []string{}, "Headers sent with every variant (e.g., -H \"Cookie: consent=1\")")
+	compareCmd.Flags().BoolVar(&compareAll, "all", false, "Show every compared field, not just those that differ")
+	compareCmd.MarkFlagRequired("variants")
+}
+
+func runCompare(cmd *cobra.Command, args []string) error {
+	url := args[0]
+	if err := urlutil.ValidateURL(url); err != nil {
+		return err
+	}
+	scraperMode, err := parseMode(mode)
+	if err != nil {
+		return err
+	}
+
+	appCtx := GetAppFromCmd(cmd)
+	if appCtx == nil {
+		return fmt.Errorf("application not initialized")
+	}
+	variants, err := compare.ParseVariants(compareVariants, appCtx.Config.Proxies)
+	if err != nil {
+		return err
+	}
+	for _, v := range variants {
+		if v.Proxy != "" {
+			if err := appCtx.Policy.Forbid(policy.FeatureProxy, "variant "+v.Name); err != nil {
+				return err
+			}
+		}
+	}
+	scraper, err := scraperForMode(appCtx, scraperMode)
+	if err != nil {
+		return err
+	}
+	if scraper, err = wrapScraper(appCtx, scraper, scraperMode); err != nil {
+		return err
+	}
+	cmd.SilenceUsage = true
+
+	common := headersutil.ParseHeaders(headers)
+	if userAgent != "" && common["User-Agent"] == "" {
+		common["User-Agent"] = userAgent
+	}
+
+	// Every variant at once, so time-sensitive content (prices, stock) is
+	// compared as of the same moment
+	results := make([]compare.Result, len(variants))
+	var wg sync.WaitGroup
+	for i, v := range variants {
+		h := make(map[string]string, len(common)+len(v.Headers))
+		for k, val := range common {
+			h[k] = val
+		}
+		for k, val := range v.Headers {
+			h[k] = val
+		}
+		p := proxy
+		if v.Proxy != "" {
+			p = v.Proxy
+		}
+		opts := models.RequestOptions{
+			URL:      url,
+			Mode:     scraperMode,
+			Selector: selector,
+			Fields:   parseFields(fields),
+			Headers:  h,
+			Timeout:  30 * time.Second,
+			Proxy:    p,
+			NoHTML:   true,
+		}
+		wg.Add(1)
+		go func(i int, v compare.Variant) {
+			defer wg.Done()
+			page, err := scraper.Fetch(opts)
+			results[i] = compare.Result{Variant: v, Page: page, Err: err}
+		}(i, v)
+	}
+	wg.Wait()
+
+	report := compare.Compare(url, results)
+	failed := 0
+	for _, v := range report.Variants {
+		if v.Error != "" {
+			failed++
+		}
+	}
+
+	if jsonOutput {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			return err
+		}
+	} else {
+		printComparison(report)
+	}
+	if failed == len(variants) {
+		return fmt.Errorf("every variant failed")
+	}
+	return nil
+}
+
+// printComparison prints one row per field and one column per variant,
+// then any variants that failed
+func printComparison(r compare.Report) {
+	shown := r.Differing()
+	if compareAll {
+		shown = r.Fields
+	}
+
+	if len(shown) > 0 {
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		names := make([]string, len(r.Variants))
+		for i, v := range r.Variants {
+			names[i] = v.Name
+		}
+		fmt.Fprintf(tw, "FIELD\t%s\n", strings.Join(names, "\t"))
+		for _, f := range shown {
+			cells := make([]string, len(f.Values))
+			for i, val := range f.Values {
+				if r.Variants[i].Error != "" {
+					cells[i] = "-"
+					continue
+				}
+				cells[i] = orDash(clip(val, compareWidth))
+			}
+			name := f.Name
+			if compareAll && f.Differs {
+				name = "* " + name
+			}
+			fmt.Fprintf(tw, "%s\t%s\n", name, strings.Join(cells, "\t"))
+		}
+		tw.Flush()
+		fmt.Println()
+	}
+
+	for _, v := range r.Variants {
+		if v.Error != "" {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", ui.Error(ui.Mark(ui.IconFailure, v.Name)), v.Error)
+		}
+	}
+	if differing := len(r.Differing()); differing == 0 {
+		ui.Printf("%s\n", ui.Success(ui.T("compare.same", len(r.Variants))))
+	} else {
+		ui.Printf("%s\n", ui.Info(ui.T("compare.summary", differing, len(r.Fields), len(r.Variants))))
+	}
+}
+
+// clip shortens s to n characters on one line
+func clip(s string, n int) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if r := []rune(s); len(r) > n {
+		return string(r[:n-3]) + "..."
+	}
+	return s
+}
@@ -0,0 +1,61 @@
+// internal/cli/console.go
+package cli
+
+import (
+	"fmt"
+
+	"github.com/law-makers/crawl/internal/engine"
+	"github.com/law-makers/crawl/pkg/models"
+	"github.com/spf13/cobra"
+)
+
+var failOnJSError bool
+
+// addConsoleFlags registers --fail-on-js-error on a command
+func addConsoleFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&failOnJSError, "fail-on-js-error", false, "Fail pages that throw an uncaught JavaScript error while rendering. Browser console output is kept in \"console_logs\" either way")
+}
+
+// jsErrorCheck fails pages whose scripts threw while the browser rendered them
+type jsErrorCheck struct {
+	next engine.Scraper
+}
+
+// Name returns the name of the wrapped engine
+func (j *jsErrorCheck) Name() string {
+	return j.next.Name()
+}
+
+// Fetch fetches opts and returns an error naming the first uncaught exception
+func (j *jsErrorCheck) Fetch(opts models.RequestOptions) (*models.PageData, error) {
+	data, err := j.next.Fetch(opts)
+	if err != nil {
+		return data, err
+	}
+	var thrown []models.ConsoleMessage
+	for _, m := range data.ConsoleLogs {
+		if m.Exception {
+			thrown = append(thrown, m)
+		}
+	}
+	if len(thrown) == 0 {
+		return data, nil
+	}
+	first := thrown[0].Text
+	if thrown[0].URL != "" {
+		first = fmt.Sprintf("%s (%s:%d)", first, thrown[0].URL, thrown[0].Line)
+	}
+	return nil, fmt.Errorf("page threw %d JavaScript error(s): %s", len(thrown), first)
+}
+
+// withJSErrorCheck wraps scraper for --fail-on-js-error, or returns it
+// unchanged. Only the browser runs scripts, so static mode is refused.
+func withJSErrorCheck(scraper engine.Scraper, scraperMode models.ScraperMode) (engine.Scraper, error) {
+	if !failOnJSError {
+		return scraper, nil
+	}
+	if scraperMode == models.ModeStatic {
+		return nil, fmt.Errorf("--fail-on-js-error requires --mode=spa or auto")
+	}
+	return &jsErrorCheck{next: scraper}, nil
+}
@@ -0,0 +1,150 @@
+// internal/cli/cookies.go
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/law-makers/crawl/internal/cookies"
+	"github.com/law-makers/crawl/internal/ui"
+	headersutil "github.com/law-makers/crawl/internal/utils/headers"
+	urlutil "github.com/law-makers/crawl/internal/utils/url"
+	"github.com/law-makers/crawl/pkg/models"
+	"github.com/spf13/cobra"
+)
+
+var cookiesMode string
+
+// cookiesCmd represents the cookies command
+var cookiesCmd = &cobra.Command{
+	Use:   "cookies <url>",
+	Short: "List the cookies a page sets and their attributes",
+	Long: `Loads a page and reports every cookie set along the way, redirects
+included: domain, path, expiry, Secure, HttpOnly, SameSite and size, and
+whether it is third-party (set for a site other than the page's).
+
+Static mode sees the cookies set by the page and its redirects. SPA mode
+loads the page in the browser, so cookies set by scripts, images and frames
+from other sites are reported too.`,
+	Example: `  # Cookies set by a page and its redirects
+  crawl cookies https://example.com
+
+  # Include third-party cookies set by the page's subresources
+  crawl cookies https://example.com --mode spa
+
+  # Full details, values included, as JSON
+  crawl cookies https://example.com --mode spa --json`,
+	Args: cobra.ExactArgs(1),
+	RunE: runCookies,
+}
+
+func init() {
+	rootCmd.AddCommand(cookiesCmd)
+
+	cookiesCmd.Flags().StringVarP(&cookiesMode, "mode", "m", "static", "Scraper mode: static, or spa for cookies set by subresources too")
+	cookiesCmd.Flags().StringArrayVarP(&headers, "header", "H", []string{}, "Custom headers (e.g., -H \"Cookie: consent=1\")")
+}
+
+// cookieReport is the --json output of crawl cookies
+type cookieReport struct {
+	URL             string          `json:"url"`
+	StatusCode      int             `json:"status_code"`
+	Cookies         []models.Cookie `json:"cookies"`
+	ThirdParty      int             `json:"third_party"`
+	ThirdPartySites int             `json:"third_party_sites"`
+}
+
+func runCookies(cmd *cobra.Command, args []string) error {
+	url := args[0]
+	if err := urlutil.ValidateURL(url); err != nil {
+		return err
+	}
+	scraperMode, err := parseMode(cookiesMode)
+	if err != nil {
+		return err
+	}
+	if scraperMode == models.ModeAuto {
+		return fmt.Errorf("--mode must be static or spa")
+	}
+
+	appCtx := GetAppFromCmd(cmd)
+	if appCtx == nil {
+		return fmt.Errorf("application not initialized")
+	}
+	scraper, err := scraperForMode(appCtx, scraperMode)
+	if err != nil {
+		return err
+	}
+	if scraper, err = wrapScraper(appCtx, scraper, scraperMode); err != nil {
+		return err
+	}
+
+	page, err := scraper.Fetch(models.RequestOptions{
+		URL:            url,
+		Mode:           scraperMode,
+		Headers:        headersutil.ParseHeaders(headers),
+		Timeout:        30 * time.Second,
+		NoHTML:         true,
+		CaptureCookies: true,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to fetch URL: %w", err)
+	}
+	cmd.SilenceUsage = true
+
+	cookies.Sort(page.Cookies)
+	report := cookieReport{URL: url, StatusCode: page.StatusCode, Cookies: page.Cookies}
+	report.ThirdParty, report.ThirdPartySites = cookies.ThirdParty(page.Cookies)
+	if report.Cookies == nil {
+		report.Cookies = []models.Cookie{}
+	}
+
+	if jsonOutput {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	}
+	printCookies(report)
+	return nil
+}
+
+// printCookies prints one row per cookie and a third-party summary
+func printCookies(r cookieReport) {
+	if len(r.Cookies) == 0 {
+		ui.Printf("%s\n", ui.Info(ui.T("cookies.none")))
+		return
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tDOMAIN\tPATH\tEXPIRES\tSECURE\tHTTPONLY\tSAMESITE\tSIZE\tPARTY")
+	for _, c := range r.Cookies {
+		party := "first"
+		if c.ThirdParty {
+			party = "third"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%d\t%s\n",
+			c.Name, c.Domain, orDash(c.Path), cookieExpiry(c), yesNo(c.Secure), yesNo(c.HTTPOnly), orDash(c.SameSite), c.Size, party)
+	}
+	tw.Flush()
+	ui.Printf("\n%s\n", ui.Info(ui.T("cookies.summary", len(r.Cookies), r.ThirdParty, r.ThirdPartySites)))
+}
+
+// cookieExpiry describes when a cookie expires
+func cookieExpiry(c models.Cookie) string {
+	switch {
+	case c.Expires == nil:
+		return "session"
+	case !c.Expires.After(time.Unix(0, 0)):
+		return "deleted"
+	}
+	return c.Expires.Local().Format("2006-01-02 15:04")
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
@@ -0,0 +1,37 @@
+// internal/cli/csv.go
+package cli
+
+import (
+	outpututil "github.com/law-makers/crawl/internal/utils/output"
+	"github.com/spf13/cobra"
+)
+
+var (
+	csvDelimiter string
+	csvNoHeader  bool
+	csvBOM       bool
+	csvCRLF      bool
+)
+
+// addCSVFlags registers the flags that set the dialect of .csv output
+func addCSVFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&csvDelimiter, "csv-delimiter", ",", "CSV field delimiter, e.g. ';' or tab")
+	cmd.Flags().BoolVar(&csvNoHeader, "csv-no-header", false, "Leave the header row out of CSV files")
+	cmd.Flags().BoolVar(&csvBOM, "csv-bom", false, "Start CSV files with a UTF-8 byte order mark (for Excel)")
+	cmd.Flags().BoolVar(&csvCRLF, "csv-crlf", false, "End CSV lines with CRLF instead of LF")
+}
+
+// applyCSVDialect validates the CSV flags and installs them for the CSV writers
+func applyCSVDialect() error {
+	comma, err := outpututil.ParseDelimiter(csvDelimiter)
+	if err != nil {
+		return err
+	}
+	outpututil.SetCSVDialect(outpututil.CSVDialect{
+		Comma:    comma,
+		NoHeader: csvNoHeader,
+		BOM:      csvBOM,
+		CRLF:     csvCRLF,
+	})
+	return nil
+}
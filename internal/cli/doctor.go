@@ -0,0 +1,98 @@
+// internal/cli/doctor.go
+package cli
+
+import (
+	"fmt"
+
+	"github.com/law-makers/crawl/internal/engine/dynamic"
+	"github.com/law-makers/crawl/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var doctorKillZombies bool
+
+// doctorCmd checks the local browser setup for problems
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check the browser setup and clean up orphaned Chrome processes",
+	Long: `Reports the Chrome that SPA mode would use and looks for Chrome processes
+left behind by crawl runs that were killed (e.g. with SIGKILL or by the OOM
+killer) before they could shut their browser down.
+
+Crawl tags each browser it launches through its profile directory, which is
+named after the crawl process, so orphans are recognised safely: browsers
+you started yourself are never touched. --kill-zombies stops the orphans and
+removes their profile directories. Crawl also does this on its own each time
+it launches a browser.`,
+	Example: `  # Look for orphaned browsers
+  crawl doctor
+
+  # Stop them and remove their profiles
+  crawl doctor --kill-zombies`,
+	Args: cobra.NoArgs,
+	RunE: runDoctor,
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+	doctorCmd.Flags().BoolVar(&doctorKillZombies, "kill-zombies", false, "Stop orphaned Chrome processes and remove their profiles")
+}
+
+// doctorReport is the --json output
+type doctorReport struct {
+	Chrome  *chromeReport        `json:"chrome"`
+	Zombies []dynamic.Zombie     `json:"zombies"`
+	Sweep   *dynamic.SweepResult `json:"sweep,omitempty"`
+}
+
+func runDoctor(cmd *cobra.Command, args []string) error {
+	var chromePath, remote string
+	if appCtx := GetAppFromCmd(cmd); appCtx != nil {
+		chromePath = appCtx.Config.ChromePath
+		remote = appCtx.Config.BrowserRemoteURL
+	}
+	report := doctorReport{Chrome: detectChrome(chromePath, remote), Zombies: []dynamic.Zombie{}}
+
+	if doctorKillZombies {
+		result, err := dynamic.Sweep()
+		if err != nil {
+			return fmt.Errorf("failed to list processes: %w", err)
+		}
+		report.Sweep = &result
+	} else {
+		zombies, err := dynamic.FindZombies()
+		if err != nil {
+			return fmt.Errorf("failed to list processes: %w", err)
+		}
+		if zombies != nil {
+			report.Zombies = zombies
+		}
+	}
+
+	if jsonOutput {
+		return writeVersionJSON(report)
+	}
+
+	fmt.Printf("%s  %s\n", ui.Bold(ui.T("version.chrome")), ui.Value(chromeDetail(report.Chrome)))
+	if report.Sweep != nil {
+		fmt.Println(ui.Success(ui.Mark(ui.IconSuccess, ui.T("doctor.killed", len(report.Sweep.Killed)))))
+		for _, z := range report.Sweep.Killed {
+			fmt.Printf("  %s\n", ui.Dim(ui.T("doctor.zombie", z.PID, z.Owner, z.Profile)))
+		}
+		if report.Sweep.Profiles > 0 {
+			fmt.Println(ui.Success(ui.Mark(ui.IconSuccess, ui.T("doctor.profiles", report.Sweep.Profiles))))
+		}
+		return nil
+	}
+
+	if len(report.Zombies) == 0 {
+		fmt.Println(ui.Success(ui.Mark(ui.IconSuccess, ui.T("doctor.clean"))))
+		return nil
+	}
+	fmt.Println(ui.Warning(ui.Mark(ui.IconWarning, ui.T("doctor.zombies", len(report.Zombies)))))
+	for _, z := range report.Zombies {
+		fmt.Printf("  %s\n", ui.T("doctor.zombie", z.PID, z.Owner, z.Profile))
+	}
+	fmt.Println(ui.Info(ui.Mark(ui.IconTip, ui.T("doctor.hint"))))
+	return nil
+}
@@ -0,0 +1,86 @@
+// internal/cli/epub.go
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/law-makers/crawl/internal/app"
+	outpututil "github.com/law-makers/crawl/internal/utils/output"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+// maxEPUBImageBytes caps each image embedded in a book
+const maxEPUBImageBytes = 20 << 20
+
+var (
+	epubTitle  string
+	epubAuthor string
+)
+
+// addEPUBFlags registers the flags for .epub output
+func addEPUBFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&epubTitle, "epub-title", "", "EPUB: book title (default: the first page's title)")
+	cmd.Flags().StringVar(&epubAuthor, "epub-author", "", "EPUB: book author")
+}
+
+// isEPUBPath reports whether path is saved as an EPUB book
+func isEPUBPath(path string) bool {
+	return strings.HasSuffix(strings.ToLower(path), ".epub")
+}
+
+// epubOptions builds the book options, downloading images for embedding
+// through the application's HTTP client
+func epubOptions(ctx context.Context, appCtx *app.Application) outpututil.EPUBOptions {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	client := http.DefaultClient
+	if appCtx != nil {
+		client = appCtx.NewHTTPClient(60*time.Second, "epub")
+	}
+
+	return outpututil.EPUBOptions{
+		Title:  epubTitle,
+		Author: epubAuthor,
+		FetchImage: func(url string) ([]byte, string, error) {
+			data, mediaType, err := fetchImage(ctx, client, url)
+			if err != nil {
+				log.Warn().Str("url", url).Err(err).Msg("Failed to embed image; using its alt text")
+			}
+			return data, mediaType, err
+		},
+	}
+}
+
+func fetchImage(ctx context.Context, client *http.Client, url string) ([]byte, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	if userAgent != "" {
+		req.Header.Set("User-Agent", userAgent)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxEPUBImageBytes+1))
+	if err != nil {
+		return nil, "", err
+	}
+	if len(data) > maxEPUBImageBytes {
+		return nil, "", fmt.Errorf("image larger than %d MB", maxEPUBImageBytes>>20)
+	}
+	return data, resp.Header.Get("Content-Type"), nil
+}
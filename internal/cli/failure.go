@@ -0,0 +1,26 @@
+// internal/cli/failure.go
+package cli
+
+import (
+	"github.com/law-makers/crawl/internal/failpolicy"
+	"github.com/spf13/cobra"
+)
+
+var (
+	failThreshold string
+	failFast      bool
+	failExitCode  int
+)
+
+// addFailureFlags registers the flags that decide when failed requests fail
+// a multi-URL run and which exit code is used
+func addFailureFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&failThreshold, "fail-threshold", "0", "Failures tolerated before the run fails: a percentage (5%) or a count (3)")
+	cmd.Flags().BoolVar(&failFast, "fail-fast", false, "Stop starting new requests after the first failure")
+	cmd.Flags().IntVar(&failExitCode, "fail-exit-code", failpolicy.DefaultExitCode, "Exit code when failures exceed --fail-threshold or --fail-fast stops the run")
+}
+
+// failurePolicy builds the policy from the failure flags
+func failurePolicy() (failpolicy.Policy, error) {
+	return failpolicy.Parse(failThreshold, failFast, failExitCode)
+}
@@ -0,0 +1,246 @@
+// internal/cli/forms.go
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/law-makers/crawl/internal/forms"
+	"github.com/law-makers/crawl/internal/ui"
+	headersutil "github.com/law-makers/crawl/internal/utils/headers"
+	urlutil "github.com/law-makers/crawl/internal/utils/url"
+	"github.com/law-makers/crawl/pkg/models"
+	"github.com/spf13/cobra"
+)
+
+var (
+	formsMode  string
+	formRef    string
+	submitForm string
+)
+
+// formsCmd represents the forms command
+var formsCmd = &cobra.Command{
+	Use:   "forms <url>",
+	Short: "List the forms on a page, or fill one in and fetch the result",
+	Long: `Lists each form on a page: where and how it submits, its fields with their
+current values, hidden inputs, and which of those look like CSRF tokens.
+
+With --submit-form the chosen form is filled in and submitted, and the page
+it leads to is output as crawl get would. Fields not given keep the values
+the page set, so hidden inputs and CSRF tokens are sent along, and cookies
+the form page set are carried over to the submission.
+
+In static mode the form is encoded and sent directly (GET or POST). In SPA
+mode it is filled in and submitted in the browser, so forms that depend on
+scripts work too.`,
+	Example: `  # List the forms on a page
+  crawl forms https://example.com/search
+
+  # Search, and print the results' headings
+  crawl forms https://example.com/search --submit-form "q=climate bill" --selector "h2"
+
+  # Pick a form by name, id or index and save the result
+  crawl forms https://example.com --form login --submit-form "user=me,pass=secret" -o result.json
+
+  # Submit in the browser, for forms driven by scripts
+  crawl forms https://example.com/search --mode spa --submit-form "q=crawl"`,
+	Args: cobra.ExactArgs(1),
+	RunE: runForms,
+}
+
+func init() {
+	rootCmd.AddCommand(formsCmd)
+
+	formsCmd.Flags().StringVarP(&formsMode, "mode", "m", "static", "Scraper mode: static, or spa to fill the form in a browser")
+	formsCmd.Flags().StringVar(&formRef, "form", "", "Form to list or submit, by name, id or index (needed when the page has several)")
+	formsCmd.Flags().StringVar(&submitForm, "submit-form", "", "Fill in and submit the form with these values (e.g. \"q=term,category=books\")")
+	formsCmd.Flags().StringVarP(&selector, "selector", "s", "body", "CSS selector to extract from the resulting page")
+	formsCmd.Flags().StringVarP(&output, "output", "o", "", "File path to save the resulting page (same formats as get)")
+	formsCmd.Flags().StringArrayVarP(&headers, "header", "H", []string{}, "Custom headers (e.g., -H \"Cookie: consent=1\")")
+}
+
+func runForms(cmd *cobra.Command, args []string) error {
+	url := args[0]
+	if err := urlutil.ValidateURL(url); err != nil {
+		return err
+	}
+	scraperMode, err := parseMode(formsMode)
+	if err != nil {
+		return err
+	}
+	if scraperMode == models.ModeAuto {
+		return fmt.Errorf("--mode must be static or spa")
+	}
+	submitting := cmd.Flags().Changed("submit-form")
+	values, err := forms.ParseValues(submitForm)
+	if err != nil {
+		return err
+	}
+
+	appCtx := GetAppFromCmd(cmd)
+	if appCtx == nil {
+		return fmt.Errorf("application not initialized")
+	}
+	scraper, err := scraperForMode(appCtx, scraperMode)
+	if err != nil {
+		return err
+	}
+	if scraper, err = wrapScraper(appCtx, scraper, scraperMode); err != nil {
+		return err
+	}
+
+	headerMap := headersutil.ParseHeaders(headers)
+	if userAgent != "" && headerMap["User-Agent"] == "" {
+		headerMap["User-Agent"] = userAgent
+	}
+	page, err := scraper.Fetch(models.RequestOptions{
+		URL:            url,
+		Mode:           scraperMode,
+		Headers:        headerMap,
+		Timeout:        30 * time.Second,
+		Proxy:          proxy,
+		CaptureCookies: true,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to fetch URL: %w", err)
+	}
+	cmd.SilenceUsage = true
+	found, err := forms.Parse(page.HTML, page.URL)
+	if err != nil {
+		return err
+	}
+
+	if !submitting {
+		if formRef != "" {
+			form, err := forms.Find(found, formRef)
+			if err != nil {
+				return err
+			}
+			found = []models.Form{*form}
+		}
+		if jsonOutput {
+			if found == nil {
+				found = []models.Form{}
+			}
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(found)
+		}
+		printForms(found)
+		return nil
+	}
+
+	form, err := forms.Find(found, formRef)
+	if err != nil {
+		return err
+	}
+	opts := models.RequestOptions{
+		Mode:     scraperMode,
+		Selector: selector,
+		Headers:  headerMap,
+		Timeout:  30 * time.Second,
+		Proxy:    proxy,
+	}
+	if scraperMode == models.ModeSPA {
+		opts.URL = url
+		opts.Submit = forms.Submission(form, values)
+	} else {
+		method, target, body, contentType, err := forms.Request(form, forms.Values(form, values))
+		if err != nil {
+			return err
+		}
+		opts.URL, opts.Method, opts.Body = target, method, body
+		opts.Headers = formHeaders(headerMap, page, contentType)
+	}
+	result, err := scraper.Fetch(opts)
+	if err != nil {
+		return fmt.Errorf("failed to submit form: %w", err)
+	}
+	_, err = writeGetOutput(cmd.Context(), appCtx, result)
+	return err
+}
+
+// formHeaders returns the headers for a static form submission: the
+// request's own, the body's Content-Type, the form page as Referer, and
+// the cookies the form page set (sessions that CSRF tokens are tied to)
+func formHeaders(base map[string]string, page *models.PageData, contentType string) map[string]string {
+	h := make(map[string]string, len(base)+3)
+	for k, v := range base {
+		h[k] = v
+	}
+	if contentType != "" {
+		h["Content-Type"] = contentType
+	}
+	if h["Referer"] == "" {
+		h["Referer"] = page.URL
+	}
+	var pairs []string
+	for _, c := range page.Cookies {
+		if c.ThirdParty || (c.Expires != nil && c.Expires.Before(time.Now())) {
+			continue
+		}
+		pairs = append(pairs, c.Name+"="+c.Value)
+	}
+	if len(pairs) > 0 {
+		if h["Cookie"] != "" {
+			pairs = append([]string{h["Cookie"]}, pairs...)
+		}
+		h["Cookie"] = strings.Join(pairs, "; ")
+	}
+	return h
+}
+
+// printForms prints each form's target and a table of its fields
+func printForms(found []models.Form) {
+	if len(found) == 0 {
+		ui.Printf("%s\n", ui.Info(ui.T("forms.none")))
+		return
+	}
+	for i, f := range found {
+		if i > 0 {
+			fmt.Println()
+		}
+		label := fmt.Sprintf("#%d", f.Index)
+		if name := firstNonEmpty(f.Name, f.ID); name != "" {
+			label += " " + name
+		}
+		fmt.Printf("%s  %s %s\n", ui.Bold(label), f.Method, f.Action)
+		if len(f.Fields) == 0 {
+			continue
+		}
+		csrf := map[string]bool{}
+		for _, name := range f.CSRF {
+			csrf[name] = true
+		}
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "  NAME\tTYPE\tVALUE\tNOTES")
+		for _, field := range f.Fields {
+			var notes []string
+			if field.Required {
+				notes = append(notes, "required")
+			}
+			if csrf[field.Name] {
+				notes = append(notes, ui.T("forms.csrf"))
+			}
+			if len(field.Options) > 1 {
+				notes = append(notes, "options: "+clip(strings.Join(field.Options, ", "), compareWidth))
+			}
+			fmt.Fprintf(tw, "  %s\t%s\t%s\t%s\n", field.Name, field.Type, orDash(clip(field.Value, compareWidth)), strings.Join(notes, "; "))
+		}
+		tw.Flush()
+	}
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
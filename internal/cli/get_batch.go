@@ -0,0 +1,274 @@
+// internal/cli/get_batch.go
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/law-makers/crawl/internal/app"
+	"github.com/law-makers/crawl/internal/attest"
+	"github.com/law-makers/crawl/internal/budget"
+	"github.com/law-makers/crawl/internal/engine"
+	"github.com/law-makers/crawl/internal/engine/batch"
+	"github.com/law-makers/crawl/internal/failpolicy"
+	"github.com/law-makers/crawl/internal/notify"
+	"github.com/law-makers/crawl/internal/sink"
+	"github.com/law-makers/crawl/internal/trace"
+	"github.com/law-makers/crawl/internal/ui"
+	outpututil "github.com/law-makers/crawl/internal/utils/output"
+	"github.com/law-makers/crawl/pkg/models"
+	"github.com/rs/zerolog/log"
+)
+
+// checkBatchOutput rejects output formats that hold a single page, and
+// split flags for outputs that can't be rotated
+func checkBatchOutput(path string) error {
+	if splitting() {
+		if !rotatable(path) {
+			return fmt.Errorf("--output-split and --output-partition need a .jsonl, .ndjson or .csv --output file")
+		}
+		_, err := splitOptions()
+		return err
+	}
+	if path == "" || sink.IsURL(path) || rotatable(path) {
+		return nil
+	}
+	lower := strings.ToLower(path)
+	if strings.HasSuffix(lower, ".json") || strings.HasSuffix(lower, ".xlsx") || isEPUBPath(lower) {
+		return nil
+	}
+	return fmt.Errorf("multiple URLs can only be saved as .json (array), .jsonl, .csv, .xlsx or .epub, or sent to a sink URL")
+}
+
+// rotatable reports whether path is a line-based file RotatingWriter can split
+func rotatable(path string) bool {
+	lower := strings.ToLower(path)
+	for _, ext := range []string{".jsonl", ".ndjson", ".csv"} {
+		if strings.HasSuffix(lower, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// runGetBatch fetches urls through the shared scraper and writes the pages in
+// the order the URLs were given. Pages that fail are reported and left out;
+// policy decides whether they fail the run. notifier hears how it went.
+func runGetBatch(ctx context.Context, appCtx *app.Application, scraper engine.Scraper, base models.RequestOptions, urls []string, policy failpolicy.Policy, notifier *notify.Dispatcher) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	started := time.Now()
+
+	requests := make([]models.RequestOptions, len(urls))
+	for i, u := range urls {
+		requests[i] = base
+		requests[i].URL = u
+		if traceGet {
+			requests[i].Trace = models.NewTrace()
+		}
+	}
+
+	// Results arrive in completion order; index them so output follows the input
+	b := batch.New(scraper, getConcurrency)
+	b.SetFailFast(policy.FailFast)
+	b.SetMemoryGuard(appCtx.MemoryGuard)
+	b.SetBudget(runBudget())
+	// A URL given twice has a result per listing, each with its own page
+	byURL := make(map[string][]models.ScrapeResult, len(urls))
+	for res := range b.ScrapeBatch(ctx, requests) {
+		byURL[res.URL] = append(byURL[res.URL], res)
+		if res.Error != nil && !errors.Is(res.Error, failpolicy.ErrAborted) && !errors.Is(res.Error, budget.ErrExceeded) {
+			reportFailure(res.URL, res.Error)
+		}
+	}
+
+	pages := make([]*models.PageData, 0, len(urls))
+	failed, aborted := 0, 0
+	var overBudget []string
+	var budgetErr error
+	for _, r := range requests {
+		var res models.ScrapeResult
+		if queued := byURL[r.URL]; len(queued) > 0 {
+			res, byURL[r.URL] = queued[0], queued[1:]
+		}
+		if errors.Is(res.Error, failpolicy.ErrAborted) {
+			aborted++
+			continue
+		}
+		if errors.Is(res.Error, budget.ErrExceeded) {
+			overBudget = append(overBudget, r.URL)
+			budgetErr = res.Error
+			continue
+		}
+		if traceGet {
+			trace.Render(os.Stderr, fmt.Sprintf("%s (%s)", r.URL, scraper.Name()), r.Trace)
+		}
+		if res.Error != nil || res.Data == nil {
+			failed++
+			continue
+		}
+		applyHeaderChecks(res.Data)
+		evalExprAssertions(res.Data)
+		redactPage(res.Data)
+		page, pluginOutput, err := applyPlugin(ctx, res.Data)
+		if err == nil && pluginOutput != nil {
+			err = fmt.Errorf("plugin %s returned output instead of a page, which only works with a single URL", extractPlugin)
+		}
+		if err != nil {
+			reportFailure(r.URL, err)
+			failed++
+			continue
+		}
+		pages = append(pages, page)
+	}
+
+	if publishURL != "" {
+		if err := publishToSink(ctx, publishURL, pages...); err != nil {
+			return err
+		}
+	}
+	files, err := writePages(ctx, appCtx, pages, output)
+	if err != nil {
+		return err
+	}
+	signed := make([]attest.Page, len(pages))
+	for i, p := range pages {
+		signed[i] = attest.PageOf(p)
+	}
+	if err := signOutput(appCtx.Config.JobID, files, signed); err != nil {
+		return err
+	}
+	// A file holds several pages, so only a lone page is recorded as its source
+	var source string
+	if len(pages) == 1 {
+		source = pages[0].URL
+	}
+	if err := writeArchive("", fileEntries(files, source)); err != nil {
+		return err
+	}
+
+	log.Info().Int("urls", len(urls)).Int("failed", failed).Int("aborted", aborted).Int("over_budget", len(overBudget)).Msg("Fetched URLs")
+	target := inputFile
+	if target == "" {
+		target = fmt.Sprintf("%d URLs", len(urls))
+	}
+	sendNotification(ctx, notifier, notify.Event{
+		Command:  "get",
+		JobID:    appCtx.Config.JobID,
+		Target:   target,
+		Total:    len(urls),
+		Success:  len(pages),
+		Failed:   failed,
+		Skipped:  aborted + len(overBudget),
+		Duration: time.Since(started).Round(time.Millisecond),
+	})
+	if aborted > 0 {
+		fmt.Fprintf(os.Stderr, "%s %s\n", ui.Warning(ui.Mark(ui.IconWarning, ui.T("batch.stopped_early"))), ui.T("batch.not_fetched", aborted))
+	}
+	reportOverBudget(overBudget, budgetErr)
+	if err := policy.Check(failed, len(urls)-aborted-len(overBudget), aborted > 0); err != nil {
+		return err
+	}
+	return checkAssertions(pages...)
+}
+
+// writePages sends pages to a sink, a .json array, an .xlsx workbook, an
+// .epub book, .jsonl or .csv files (rotated by the split flags), or stdout
+// as JSON Lines, and returns the files written
+func writePages(ctx context.Context, appCtx *app.Application, pages []*models.PageData, path string) ([]string, error) {
+	if path != "" && sink.IsURL(path) {
+		return nil, publishToSink(ctx, path, pages...)
+	}
+	if path == "" {
+		return nil, encodePages(os.Stdout, pages, false)
+	}
+	lower := strings.ToLower(path)
+	switch {
+	case strings.HasSuffix(lower, ".csv") || splitting():
+		return writeRotated(pages, path)
+	case strings.HasSuffix(lower, ".xlsx"):
+		if err := outpututil.SaveXLSX(pages, path, tableColumns()); err != nil {
+			return nil, fmt.Errorf("failed to save Excel workbook: %w", err)
+		}
+	case isEPUBPath(lower):
+		if err := outpututil.SaveEPUB(pages, path, epubOptions(ctx, appCtx)); err != nil {
+			return nil, fmt.Errorf("failed to save EPUB: %w", err)
+		}
+	default:
+		f, err := os.Create(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create %s: %w", path, err)
+		}
+		if err := encodePages(f, pages, strings.HasSuffix(lower, ".json")); err != nil {
+			f.Close()
+			return nil, fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		if err := f.Close(); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", path, err)
+		}
+	}
+
+	link := terminalHyperlink(path, path)
+	ui.Printf("%s\n", ui.Success(ui.Mark(ui.IconSuccess, ui.T("batch.saved_pages", len(pages), ui.Bold(link)))))
+	return []string{path}, nil
+}
+
+// writeRotated writes pages through a RotatingWriter and lists the files created
+func writeRotated(pages []*models.PageData, path string) ([]string, error) {
+	split, err := splitOptions()
+	if err != nil {
+		return nil, err
+	}
+	rw, err := outpututil.NewRotatingWriter(path, split)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range pages {
+		if err := rw.Write(p); err != nil {
+			rw.Close()
+			return nil, err
+		}
+	}
+	if err := rw.Close(); err != nil {
+		return nil, err
+	}
+
+	files := rw.Files()
+	ui.Printf("%s\n", ui.Success(ui.Mark(ui.IconSuccess, ui.T("batch.saved_files", len(pages), len(files)))))
+	for _, f := range files {
+		ui.Printf("  %s\n", terminalHyperlink(f, f))
+	}
+	return files, nil
+}
+
+// encodePages writes the export form of each page, either as one indented
+// JSON array or as one JSON object per line
+func encodePages(w io.Writer, pages []*models.PageData, asArray bool) error {
+	if asArray {
+		exports := make([]json.Marshaler, len(pages))
+		for i, p := range pages {
+			exports[i] = outpututil.ExportJSON(p)
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(exports)
+	}
+
+	for _, p := range pages {
+		line, err := outpututil.MarshalJSON(p)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "%s\n", line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,168 @@
+// internal/cli/head.go
+package cli
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/law-makers/crawl/internal/probe"
+	"github.com/law-makers/crawl/internal/ui"
+	headersutil "github.com/law-makers/crawl/internal/utils/headers"
+	urlutil "github.com/law-makers/crawl/internal/utils/url"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+var (
+	headFile        string
+	headConcurrency int
+)
+
+// headCmd represents the head command
+var headCmd = &cobra.Command{
+	Use:   "head <url>...",
+	Short: "Show status, type, size and cache headers without downloading pages",
+	Long: `Sends a HEAD request to each URL and reports the status, final URL after
+redirects, content type, size, last-modified and cache headers. Servers that
+reject HEAD are retried with GET, discarding the body.
+
+Use it to triage a list of URLs before deciding which ones to crawl fully.`,
+	Example: `  # Check a single URL
+  crawl head https://example.com
+
+  # Triage a list of URLs, 20 at a time
+  crawl head --file urls.txt --concurrency 20
+
+  # Emit one JSON object per URL (with the global --json flag)
+  crawl head --file urls.txt --json > triage.jsonl`,
+	RunE: runHead,
+}
+
+func init() {
+	rootCmd.AddCommand(headCmd)
+
+	headCmd.Flags().StringVarP(&headFile, "file", "f", "", "Read URLs from a file, one per line (# starts a comment)")
+	headCmd.Flags().IntVarP(&headConcurrency, "concurrency", "c", 10, "Number of URLs to check at once")
+	headCmd.Flags().StringArrayVarP(&headers, "header", "H", []string{}, "Custom headers (e.g., -H \"Authorization: Bearer token\")")
+}
+
+func runHead(cmd *cobra.Command, args []string) error {
+	urls := append([]string{}, args...)
+	if headFile != "" {
+		listed, err := readURLFile(headFile)
+		if err != nil {
+			return err
+		}
+		urls = append(urls, listed...)
+	}
+	if len(urls) == 0 {
+		return fmt.Errorf("no URLs given (pass them as arguments or with --file)")
+	}
+	for _, u := range urls {
+		if err := urlutil.ValidateURL(u); err != nil {
+			return fmt.Errorf("%s: %w", u, err)
+		}
+	}
+
+	appCtx := GetAppFromCmd(cmd)
+	if appCtx == nil {
+		return fmt.Errorf("application not initialized")
+	}
+
+	requestTimeout := 15 * time.Second
+	if timeout != "" {
+		if d, err := time.ParseDuration(timeout); err == nil {
+			requestTimeout = d
+		} else {
+			log.Warn().Str("timeout", timeout).Msg("Invalid timeout format, using default 15s")
+		}
+	}
+
+	prober := probe.New(appCtx.NewHTTPClient(requestTimeout, "head"), appCtx.RateLimiter, appCtx.Concurrency)
+	prober.SetUserAgent(appCtx.Config.UserAgent)
+	prober.SetHeaders(headersutil.ParseHeaders(headers))
+	results := prober.ProbeAll(cmd.Context(), urls, headConcurrency)
+
+	if jsonOutput {
+		enc := json.NewEncoder(os.Stdout)
+		for _, r := range results {
+			if err := enc.Encode(r); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	printHeadResults(results)
+	return nil
+}
+
+// printHeadResults prints one row per URL
+func printHeadResults(results []probe.Result) {
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "STATUS\tTYPE\tSIZE\tLAST-MODIFIED\tCACHE\tURL")
+	failed := 0
+	for _, r := range results {
+		if r.Error != "" {
+			failed++
+			fmt.Fprintf(tw, "ERR\t-\t-\t-\t-\t%s  %s\n", r.URL, ui.Error(r.Error))
+			continue
+		}
+
+		size := "-"
+		if r.Size >= 0 {
+			size = formatBytes(r.Size)
+		}
+		contentType := r.ContentType
+		if i := strings.Index(contentType, ";"); i >= 0 {
+			contentType = contentType[:i]
+		}
+		target := r.URL
+		if r.FinalURL != "" && r.FinalURL != r.URL {
+			target += " → " + r.FinalURL
+		}
+		if r.Method != "HEAD" {
+			target += " (via " + r.Method + ")"
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%s\n", r.Status, orDash(contentType), size, orDash(r.LastModified), orDash(r.CacheControl), target)
+	}
+	tw.Flush()
+
+	if len(results) > 1 {
+		ui.Printf("\n%s\n", ui.Info(ui.T("head.summary", len(results), failed)))
+	}
+}
+
+// readURLFile reads one URL per line, skipping blank lines and # comments
+func readURLFile(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open URL list: %w", err)
+	}
+	defer f.Close()
+
+	var urls []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		urls = append(urls, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read URL list: %w", err)
+	}
+	return urls, nil
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,101 @@
+// internal/cli/headercheck.go
+package cli
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/law-makers/crawl/pkg/models"
+	"github.com/spf13/cobra"
+)
+
+var (
+	headerExtract string
+	assertHeaders []string
+
+	// Parsed --header-extract and --assert-header
+	headerFields     []headerField
+	headerAssertions []models.Assertion
+)
+
+// headerField is one name=Header pair of --header-extract
+type headerField struct {
+	name   string
+	header string
+}
+
+// addHeaderCheckFlags registers --header-extract and --assert-header on a command
+func addHeaderCheckFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&headerExtract, "header-extract", "", "Copy response headers into the structured output: \"server=Server,cache=CF-Cache-Status\" (a bare header name is used as its own field name)")
+	cmd.Flags().StringArrayVar(&assertHeaders, "assert-header", nil, "Fail unless the response has this header, containing the value if given: \"Content-Type: text/html\" (repeatable)")
+}
+
+// parseHeaderChecks validates --header-extract and --assert-header
+func parseHeaderChecks() error {
+	headerFields = nil
+	for _, part := range strings.Split(headerExtract, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, header, ok := strings.Cut(part, "=")
+		if !ok {
+			header = name
+		}
+		name, header = strings.TrimSpace(name), strings.TrimSpace(header)
+		if name == "" || header == "" {
+			return fmt.Errorf("invalid --header-extract %q (use name=Header, e.g. \"server=Server\")", part)
+		}
+		headerFields = append(headerFields, headerField{name: name, header: header})
+	}
+
+	headerAssertions = nil
+	for _, spec := range assertHeaders {
+		header, value, _ := strings.Cut(spec, ":")
+		header = strings.TrimSpace(header)
+		if header == "" {
+			return fmt.Errorf("invalid --assert-header %q (use Header or \"Header: value\")", spec)
+		}
+		headerAssertions = append(headerAssertions, models.Assertion{Header: header, Value: strings.TrimSpace(value), Min: 1, Max: -1})
+	}
+	return nil
+}
+
+// applyHeaderChecks copies the --header-extract headers into every
+// structured row of page (adding a row if it has none) and adds the
+// --assert-header results to its assertions
+func applyHeaderChecks(page *models.PageData) {
+	if len(headerFields) > 0 {
+		if len(page.Structured) == 0 {
+			page.Structured = []map[string]string{{}}
+		}
+		for _, f := range headerFields {
+			value, _ := responseHeader(page, f.header)
+			for _, row := range page.Structured {
+				row[f.name] = value
+			}
+		}
+	}
+	for _, a := range headerAssertions {
+		r := models.AssertionResult{Assertion: a}
+		if value, ok := responseHeader(page, a.Header); ok {
+			r.Count, r.Got = 1, value
+			r.Passed = strings.Contains(strings.ToLower(value), strings.ToLower(a.Value))
+		}
+		page.Assertions = append(page.Assertions, r)
+	}
+}
+
+// responseHeader looks a header up in page's response headers regardless of case
+func responseHeader(page *models.PageData, name string) (string, bool) {
+	if value, ok := page.Headers[http.CanonicalHeaderKey(name)]; ok {
+		return value, true
+	}
+	for key, value := range page.Headers {
+		if strings.EqualFold(key, name) {
+			return value, true
+		}
+	}
+	return "", false
+}
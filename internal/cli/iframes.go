@@ -0,0 +1,37 @@
+// internal/cli/iframes.go
+package cli
+
+import (
+	"fmt"
+
+	"github.com/law-makers/crawl/pkg/models"
+	"github.com/spf13/cobra"
+)
+
+var (
+	includeIframes     bool
+	iframesCrossOrigin bool
+	maxIframes         int
+)
+
+// addFrameFlags registers --include-iframes and its options on a command
+func addFrameFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&includeIframes, "include-iframes", false, "Also extract from the page's iframes (3 levels deep), merging their content, fields and matches into the page's; each frame is listed in \"frames\" and rows from one carry its URL in _frame")
+	cmd.Flags().BoolVar(&iframesCrossOrigin, "iframes-cross-origin", false, "With --include-iframes, also read iframes from other origins")
+	cmd.Flags().IntVar(&maxIframes, "max-iframes", 20, "With --include-iframes, most iframes to read per page (0 for no limit)")
+}
+
+// frameOptions returns the iframe settings for --include-iframes, or nil
+// without it
+func frameOptions() (*models.FrameOptions, error) {
+	if !includeIframes {
+		if iframesCrossOrigin {
+			return nil, fmt.Errorf("--iframes-cross-origin requires --include-iframes")
+		}
+		return nil, nil
+	}
+	if maxIframes < 0 {
+		return nil, fmt.Errorf("--max-iframes must be >= 0")
+	}
+	return &models.FrameOptions{CrossOrigin: iframesCrossOrigin, Max: maxIframes}, nil
+}
@@ -0,0 +1,43 @@
+// internal/cli/lock.go
+package cli
+
+import (
+	"errors"
+
+	"github.com/law-makers/crawl/internal/lockfile"
+	"github.com/law-makers/crawl/internal/sink"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+var forceLock bool
+
+// addLockFlags registers --force for commands that lock their output
+func addLockFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&forceLock, "force", false, "Write to the output even if another crawl run appears to be using it")
+}
+
+// lockOutput takes the advisory lock on an output directory or file so two
+// runs can't interleave their writes. Stdout and sinks aren't locked. The
+// returned func releases the lock.
+func lockOutput(cmd *cobra.Command, target string, isDir bool) (func(), error) {
+	if target == "" || sink.IsURL(target) {
+		return func() {}, nil
+	}
+	lock, err := lockfile.Acquire(target, cmd.CommandPath(), isDir, forceLock)
+	if err != nil {
+		var held *lockfile.HeldError
+		if errors.As(err, &held) {
+			cmd.SilenceUsage = true
+		}
+		return nil, err
+	}
+	if forceLock {
+		log.Debug().Str("target", target).Msg("Locked output (forced)")
+	}
+	return func() {
+		if err := lock.Release(); err != nil {
+			log.Warn().Err(err).Str("target", target).Msg("Failed to release output lock")
+		}
+	}, nil
+}
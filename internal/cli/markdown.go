@@ -0,0 +1,132 @@
+// internal/cli/markdown.go
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/law-makers/crawl/internal/app"
+	"github.com/law-makers/crawl/internal/downloader"
+	"github.com/law-makers/crawl/internal/ui"
+	outpututil "github.com/law-makers/crawl/internal/utils/output"
+	urlutil "github.com/law-makers/crawl/internal/utils/url"
+	"github.com/law-makers/crawl/pkg/models"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+var (
+	mdFrontmatter       bool
+	mdTags              string
+	mdDownloadImages    string
+	mdNormalizeHeadings bool
+)
+
+// addMarkdownFlags registers the flags for .md output
+func addMarkdownFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&mdFrontmatter, "frontmatter", false, "Markdown: start with YAML frontmatter (url, title, date, tags)")
+	cmd.Flags().StringVar(&mdTags, "tags", "", "Markdown: comma-separated frontmatter tags (implies --frontmatter)")
+	cmd.Flags().StringVar(&mdDownloadImages, "download-images", "", "Markdown: download images into this directory and link to the local copies")
+	cmd.Flags().BoolVar(&mdNormalizeHeadings, "normalize-headings", false, "Markdown: renumber headings so the page's top heading becomes #")
+}
+
+// isMarkdownPath reports whether path is saved as Markdown
+func isMarkdownPath(path string) bool {
+	lower := strings.ToLower(path)
+	return strings.HasSuffix(lower, ".md") || strings.HasSuffix(lower, ".markdown")
+}
+
+// markdownOptions builds the options for saving data to mdPath, downloading
+// its images first when --download-images is set
+func markdownOptions(ctx context.Context, appCtx *app.Application, data *models.PageData, mdPath string) (outpututil.MarkdownOptions, error) {
+	opts := outpututil.MarkdownOptions{
+		Columns:           tableColumns(),
+		Frontmatter:       mdFrontmatter || mdTags != "",
+		NormalizeHeadings: mdNormalizeHeadings,
+	}
+	for _, tag := range strings.Split(mdTags, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			opts.Tags = append(opts.Tags, tag)
+		}
+	}
+
+	if mdDownloadImages == "" || !isMarkdownPath(mdPath) {
+		return opts, nil
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	images, err := downloadMarkdownImages(ctx, appCtx, data, mdPath)
+	if err != nil {
+		return opts, err
+	}
+	opts.Images = images
+	return opts, nil
+}
+
+// downloadMarkdownImages fetches the images in the page content into
+// --download-images and maps each image URL to its path relative to mdPath.
+// Images that fail to download keep their remote URL.
+func downloadMarkdownImages(ctx context.Context, appCtx *app.Application, data *models.PageData, mdPath string) (map[string]string, error) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(data.HTML))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse page HTML: %w", err)
+	}
+	seen := make(map[string]bool)
+	var urls []string
+	doc.Find("img[src]").Each(func(i int, s *goquery.Selection) {
+		src, _ := s.Attr("src")
+		u := urlutil.ResolveURL(data.URL, src)
+		if src != "" && !strings.HasPrefix(src, "data:") && !seen[u] {
+			seen[u] = true
+			urls = append(urls, u)
+		}
+	})
+	if len(urls) == 0 {
+		return nil, nil
+	}
+
+	assetDir, err := filepath.Abs(mdDownloadImages)
+	if err != nil {
+		return nil, fmt.Errorf("invalid image directory: %w", err)
+	}
+	if err := os.MkdirAll(assetDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create image directory: %w", err)
+	}
+	mdDir, err := filepath.Abs(filepath.Dir(mdPath))
+	if err != nil {
+		return nil, fmt.Errorf("invalid output path: %w", err)
+	}
+
+	pool := downloader.NewWorkerPool(4, 60*time.Second, GetUserAgent())
+	pool.SetProgress(!quiet, ui.ColorsEnabled())
+	if appCtx != nil {
+		pool.SetRateLimiter(appCtx.RateLimiter)
+		pool.SetConcurrency(appCtx.Concurrency)
+		pool.SetClient(appCtx.NewHTTPClient(60*time.Second, "downloader"))
+		pool.SetMemoryGuard(appCtx.MemoryGuard)
+	}
+	results := pool.DownloadBatch(ctx, urls, downloader.DownloadOptions{
+		OutputDir: assetDir,
+		Headers:   map[string]string{"Referer": data.URL},
+	})
+
+	images := make(map[string]string, len(results))
+	for _, r := range results {
+		if !r.Success {
+			log.Warn().Str("url", r.URL).Err(r.Error).Msg("Failed to download image; keeping the remote URL")
+			continue
+		}
+		rel, err := filepath.Rel(mdDir, r.FilePath)
+		if err != nil {
+			rel = r.FilePath
+		}
+		images[r.URL] = filepath.ToSlash(rel)
+	}
+	return images, nil
+}
@@ -3,14 +3,23 @@ package cli
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"path/filepath"
 	"strings"
 	"time"
 
+	"github.com/law-makers/crawl/internal/budget"
 	"github.com/law-makers/crawl/internal/downloader"
+	"github.com/law-makers/crawl/internal/downloader/imageproc"
 	"github.com/law-makers/crawl/internal/engine"
+	"github.com/law-makers/crawl/internal/failpolicy"
+	"github.com/law-makers/crawl/internal/notify"
+	"github.com/law-makers/crawl/internal/ratelimit"
+	"github.com/law-makers/crawl/internal/reqctx"
+	"github.com/law-makers/crawl/internal/stats"
 	"github.com/law-makers/crawl/internal/ui"
+	"github.com/law-makers/crawl/internal/utils/archive"
 	headersutil "github.com/law-makers/crawl/internal/utils/headers"
 	"github.com/law-makers/crawl/pkg/models"
 	"github.com/rs/zerolog"
@@ -23,6 +32,13 @@ var (
 	concurrency int
 	outputDir   string
 	waitSeconds int
+	retries     int
+	rateLimit   float64
+	rateBurst   int
+	incremental bool
+	maxDuration time.Duration
+	maxRequests int
+	statsJSON   string
 )
 
 // mediaCmd represents the media command
@@ -48,7 +64,44 @@ Perfect for downloading galleries, video collections, or scraping media-heavy si
   crawl media https://example.com --type=all --output=./downloads
 
   # Download from a SPA that requires JavaScript
-  crawl media https://spa-site.com --mode=spa --type=video`,
+  crawl media https://spa-site.com --mode=spa --type=video
+
+  # Download videos from embedded Vimeo and JW Player players, saving their metadata
+  crawl media https://example.com/post --type=video --embeds --embeds-json=videos.json
+
+  # Download images and package them into a single archive
+  crawl media https://example.com --type=image --archive=images.zip
+
+  # Build a dataset: JPEG images at most 1600px wide, without camera metadata
+  # (--convert webp writes lossless WebP, usually larger than JPEG)
+  crawl media https://example.com/gallery --type=image --convert jpeg --max-width 1600 --strip-exif
+
+  # Keep each file's source page, alt text, caption and hash in a .json beside it
+  crawl media https://example.com/gallery --type=image --sidecar
+
+  # Re-run periodically, only fetching files that changed
+  crawl media https://example.com --output=./mirror --incremental
+
+  # Be gentle with a small host: one file per second
+  crawl media https://example.com --rate-limit=1 --burst=1
+
+  # Stop starting new downloads after 10 minutes or 500 files, keeping what finished
+  crawl media https://example.com --max-duration=10m --max-requests=500
+
+  # Download every page of a paginated gallery (up to 50 pages)
+  crawl media "https://example.com/gallery?page=1" --type=image --follow-next --max-pages=50
+
+  # Skip the size prompt for a large download in a script
+  crawl media https://example.com/videos --type=video --yes
+
+  # Export run statistics for a dashboard
+  crawl media https://example.com --stats-json=stats.json
+
+  # Fail a CI job only if more than 10% of files fail to download
+  crawl media https://example.com --fail-threshold=10%
+
+  # Post to Slack when the batch finishes, flagging runs where 20% or more fail
+  crawl media https://example.com --notify slack://hooks.slack.com/services/T000/B000/XXXX --notify-failure-threshold=20`,
 	Args: cobra.ExactArgs(1),
 	RunE: runMedia,
 }
@@ -62,7 +115,22 @@ func init() {
 	mediaCmd.Flags().StringVarP(&mode, "mode", "m", "auto", "Scraper mode: auto, static, or spa")
 	mediaCmd.Flags().IntVar(&waitSeconds, "wait", 0, "Seconds to wait after page loads before scraping (static and SPA)")
 	mediaCmd.Flags().StringArrayVarP(&headers, "header", "H", []string{}, "Custom headers")
-
+	mediaCmd.Flags().Float64Var(&rateLimit, "rate-limit", 5, "Maximum requests per second per host, replacing the configured rate (0 for no limit; a policy ceiling still applies)")
+	mediaCmd.Flags().IntVar(&rateBurst, "burst", 10, "Number of downloads per host allowed to start at once before --rate-limit applies")
+	addArchiveFlags(mediaCmd, "Also package downloaded files, their sidecars and an index.json manifest (source URL, content type and SHA-256 of each file) into an archive (.zip, .tar.gz or .tgz)")
+	mediaCmd.Flags().BoolVar(&incremental, "incremental", false, "Skip files unchanged since the last run (tracked in the output directory)")
+	mediaCmd.Flags().IntVar(&retries, "retries", 2, "Number of retries per file on network errors, 429 and 5xx responses")
+	mediaCmd.Flags().DurationVar(&maxDuration, "max-duration", 0, "Stop starting new downloads after this long, e.g. 30m (0 for no limit)")
+	mediaCmd.Flags().IntVar(&maxRequests, "max-requests", 0, "Download at most this many files; the rest are skipped (0 for no limit)")
+	mediaCmd.Flags().StringVar(&statsJSON, "stats-json", "", "Write run statistics (status codes, bytes, retries, throttling) to this JSON file")
+	addFailureFlags(mediaCmd)
+	addNotifyFlags(mediaCmd)
+	addMediaPreviewFlags(mediaCmd)
+	addMediaPageFlags(mediaCmd)
+	addMediaEmbedFlags(mediaCmd)
+	addMediaImageFlags(mediaCmd)
+	addMediaSidecarFlags(mediaCmd)
+	addLockFlags(mediaCmd)
 }
 
 func runMedia(cmd *cobra.Command, args []string) error {
@@ -88,6 +156,32 @@ func runMedia(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("invalid media type: %s (must be image, video, audio, or all)", mediaType)
 	}
 
+	// Validate archive format before doing any work
+	if err := checkArchive(); err != nil {
+		return err
+	}
+
+	policy, err := failurePolicy()
+	if err != nil {
+		return err
+	}
+	limit, err := confirmLimit()
+	if err != nil {
+		return err
+	}
+	imgOpts, err := imageOptions()
+	if err != nil {
+		return err
+	}
+
+	// Open notifiers up front so a bad URL or template fails fast
+	notifier, err := openNotifier()
+	if err != nil {
+		return err
+	}
+	started := time.Now()
+	collector := stats.New("media", pageURL)
+
 	// Validate concurrency
 	if concurrency < 1 {
 		concurrency = 1
@@ -100,6 +194,7 @@ func runMedia(cmd *cobra.Command, args []string) error {
 		Str("url", pageURL).
 		Str("type", string(mediaTypeEnum)).
 		Int("concurrency", concurrency).
+		Float64("rate_limit", rateLimit).
 		Str("output", outputDir).
 		Msg("Starting media extraction")
 
@@ -127,7 +222,7 @@ func runMedia(cmd *cobra.Command, args []string) error {
 	}
 
 	// Use the scraper from the app
-	scraper = appCtx.Scraper
+	scraper = reqctx.Stamp(appCtx.Scraper, appCtx.Config.JobID)
 
 	// Fetch the page
 	opts := models.RequestOptions{
@@ -147,32 +242,81 @@ func runMedia(cmd *cobra.Command, args []string) error {
 		Int("status", pageData.StatusCode).
 		Int64("response_time_ms", pageData.ResponseTime).
 		Msg("Page fetched successfully")
+	collector.Record(scraper.Name(), stats.Request{
+		Status:   pageData.StatusCode,
+		Bytes:    int64(len(pageData.HTML)),
+		Duration: time.Duration(pageData.ResponseTime) * time.Millisecond,
+		Waited:   pageWaited(pageData),
+	})
+
+	// Extract media URLs from the HTML, and from embedded players if asked
+	var embeds *embedResolver
+	if resolveEmbeds || embedsJSON != "" {
+		embeds = &embedResolver{client: appCtx.NewHTTPClient(30*time.Second, "embed")}
+	}
+	extract := func(html, pageURL string) ([]string, error) {
+		urls, err := downloader.ExtractMedia(html, pageURL, mediaTypeEnum)
+		if err != nil || embeds == nil || mediaTypeEnum == downloader.MediaTypeImage || mediaTypeEnum == downloader.MediaTypeAudio {
+			return urls, err
+		}
+		unique := downloader.NewManifest()
+		unique.Add(urls)
+		unique.Add(embeds.Streams(cmd.Context(), html, pageURL))
+		return unique.URLs(), nil
+	}
+	sources := mediaSources{}
+	if writeSidecars {
+		extract = sources.wrap(extract)
+	}
 
-	// Extract media URLs from the HTML
 	log.Debug().Msg("Extracting media URLs")
-	mediaURLs, err := downloader.ExtractMedia(pageData.HTML, pageURL, mediaTypeEnum)
+	mediaURLs, err := extract(pageData.HTML, pageURL)
 	if err != nil {
 		return fmt.Errorf("failed to extract media: %w", err)
 	}
 
+	// Collect the rest of a paginated gallery, skipping files seen on earlier pages
+	if followNext {
+		manifest := downloader.NewManifest()
+		ui.Printf("\n%s %s\n", ui.Info(ui.T("media.page", 1)), ui.Value(ui.T("media.page_new", manifest.Add(mediaURLs))))
+		followGallery(scraper, opts, pageData, extract, manifest, collector)
+		mediaURLs = manifest.URLs()
+	}
+
+	if embeds != nil && embedsJSON != "" {
+		if err := embeds.WriteJSON(embedsJSON); err != nil {
+			log.Warn().Err(err).Str("file", embedsJSON).Msg("Failed to write embeds")
+		}
+	}
+
 	if len(mediaURLs) == 0 {
 		log.Debug().Msg("No media files found on this page")
-		fmt.Println("\n" + ui.Info("❌ No media files found."))
-		fmt.Println("\n" + ui.Info("💡 TIP: Try using --mode=spa for JavaScript-heavy sites"))
+		ui.Println("\n" + ui.Info(ui.Mark(ui.IconNone, ui.T("media.none_found"))))
+		ui.Println("\n" + ui.Info(ui.Mark(ui.IconTip, ui.T("media.tip_spa"))))
 		return nil
 	}
 
 	log.Debug().Int("count", len(mediaURLs)).Msg("Media URLs extracted")
 	// Only show detailed file preview when verbose or JSON logging is enabled.
 	if verbose || jsonOutput {
-		fmt.Printf("\n%s %s\n", ui.Bold("Found"), ui.ColorWhite+fmt.Sprintf("%d media file(s):", len(mediaURLs))+ui.ColorReset)
+		ui.Printf("\n%s %s\n", ui.Bold(ui.T("media.found")), ui.Value(ui.T("media.found_list", len(mediaURLs))))
 		for i, url := range mediaURLs {
-			fmt.Printf("  %s %d. %s\n", ui.ColorDim, i+1, ui.ColorWhite+url+ui.ColorReset)
+			ui.Printf("   %s %s\n", ui.Dim(fmt.Sprintf("%d.", i+1)), ui.Value(url))
 		}
-		fmt.Println()
+		ui.Println()
 	} else {
 		// Minimal output: only show the count so the progress bar remains the primary output.
-		fmt.Printf("\n%s %s\n\n", ui.Bold("Found"), ui.ColorWhite+fmt.Sprintf("%d media file(s).", len(mediaURLs))+ui.ColorReset)
+		ui.Printf("\n%s %s\n\n", ui.Bold(ui.T("media.found")), ui.Value(ui.T("media.found_count", len(mediaURLs))))
+	}
+
+	// Show what will be fetched, and make the user confirm very large downloads
+	if !noEstimate {
+		plan := planMedia(cmd.Context(), appCtx, mediaURLs, headerMap)
+		printMediaPlan(plan)
+		if err := confirmDownload(plan, limit); err != nil {
+			cmd.SilenceUsage = true
+			return err
+		}
 	}
 
 	// Create output directory
@@ -180,17 +324,47 @@ func runMedia(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return fmt.Errorf("invalid output directory: %w", err)
 	}
+	unlock, err := lockOutput(cmd, absOutputDir, true)
+	if err != nil {
+		return err
+	}
+	defer unlock()
 
 	// Create worker pool
-	pool := downloader.NewWorkerPool(concurrency, 60*time.Second, "Crawl/1.0")
+	pool := downloader.NewWorkerPool(concurrency, 60*time.Second, GetUserAgent())
+	retryCfg := downloader.DefaultRetryConfig()
+	retryCfg.MaxAttempts = retries + 1
+	pool.SetRetryConfig(retryCfg)
+	applyRateOverride(cmd, appCtx.RateLimiter)
+	pool.SetRateLimiter(appCtx.RateLimiter)
+	pool.SetConcurrency(appCtx.Concurrency)
+	pool.SetClient(appCtx.NewHTTPClient(60*time.Second, "downloader"))
+	pool.SetBudget(budget.Budget{MaxDuration: maxDuration, MaxRequests: maxRequests})
+	pool.SetMemoryGuard(appCtx.MemoryGuard)
+	pool.SetProgress(!quiet, ui.ColorsEnabled())
 
 	// Start downloads
-	fmt.Printf("%s %s\n\n", ui.Info("Starting download with"), ui.ColorWhite+fmt.Sprintf("%d workers...", concurrency)+ui.ColorReset)
+	ui.Printf("%s %s\n\n", ui.Info(ui.T("media.starting")), ui.Value(ui.T("media.workers", concurrency)))
 	ctx := context.Background()
 
 	downloadOpts := downloader.DownloadOptions{
 		OutputDir: absOutputDir,
 		Headers:   headerMap,
+		FailFast:  policy.FailFast,
+	}
+	if imgOpts.Enabled() {
+		downloadOpts.PostProcess = func(path string) (string, error) {
+			return imageproc.Process(path, imgOpts)
+		}
+	}
+
+	// Load ETag/Last-Modified/hash state from previous runs
+	if incremental {
+		state, err := downloader.LoadState(filepath.Join(absOutputDir, downloader.StateFileName))
+		if err != nil {
+			return fmt.Errorf("failed to load incremental state: %w", err)
+		}
+		downloadOpts.State = state
 	}
 
 	// Reduce console logging during the download phase so the progress bar remains the primary output.
@@ -202,31 +376,58 @@ func runMedia(cmd *cobra.Command, args []string) error {
 	// Restore previous log level
 	zerolog.SetGlobalLevel(prevLevel)
 
+	if downloadOpts.State != nil {
+		if err := downloadOpts.State.Save(); err != nil {
+			log.Warn().Err(err).Msg("Failed to save incremental state")
+		}
+	}
+
 	// Print results
 	successCount := 0
 	failCount := 0
+	unchangedCount := 0
+	var skipped []*downloader.DownloadResult
 	totalSize := int64(0)
 	totalDuration := time.Duration(0)
 
 	// Only show detailed results header if verbose or JSON output is enabled.
 	if verbose || jsonOutput {
-		fmt.Println("\n" + ui.Bold("Download Results:"))
+		ui.Println("\n" + ui.Bold(ui.T("media.results")))
 	}
 
 	for i, result := range results {
+		retried := result.Attempts - 1
+		if retried < 0 {
+			retried = 0
+		}
+		collector.Record("downloader", stats.Request{
+			Status:   result.StatusCode,
+			Bytes:    result.Size,
+			Duration: result.Duration,
+			Retries:  retried,
+			Waited:   result.Waited,
+			Err:      result.Error,
+			Skipped:  result.Skipped,
+		})
+
 		if result.Success {
 			successCount++
+			if result.Unchanged {
+				unchangedCount++
+			}
 			totalSize += result.Size
 			totalDuration += result.Duration
 			if verbose || jsonOutput {
-				fmt.Printf("%s [%d/%d] %s\n", ui.Success("✓"), i+1, len(results), ui.ColorWhite+filepath.Base(result.FilePath)+ui.ColorReset)
-				fmt.Printf("  %s %s  %s %v\n", ui.ColorDim+"Size:", ui.ColorWhite+formatBytes(result.Size)+ui.ColorReset, ui.ColorDim+"Duration:", result.Duration.Round(time.Millisecond))
+				ui.Printf("%s %s\n", ui.Success(ui.Mark(ui.IconSuccess, fmt.Sprintf("[%d/%d]", i+1, len(results)))), ui.Value(filepath.Base(result.FilePath)))
+				ui.Printf("  %s %s  %s %s\n", ui.Dim(ui.T("media.size")), ui.Value(formatBytes(result.Size)), ui.Dim(ui.T("media.duration")), ui.Dim(result.Duration.Round(time.Millisecond).String()))
 			}
+		} else if result.Skipped {
+			skipped = append(skipped, result)
 		} else {
 			failCount++
 			if verbose || jsonOutput {
-				fmt.Printf("%s [%d/%d] %s\n", ui.Error("✗"), i+1, len(results), ui.ColorWhite+result.URL+ui.ColorReset)
-				fmt.Printf("  %s %s\n", ui.ColorDim+"Error:", ui.Error(fmt.Sprintf("%v", result.Error)))
+				fmt.Printf("%s %s\n", ui.Error(ui.Mark(ui.IconFailure, fmt.Sprintf("[%d/%d]", i+1, len(results)))), ui.Value(result.URL))
+				fmt.Printf("  %s %s\n", ui.Dim(ui.T("media.error")), ui.Error(fmt.Sprintf("%v", result.Error)))
 			}
 		}
 	}
@@ -236,32 +437,90 @@ func runMedia(cmd *cobra.Command, args []string) error {
 	if successCount > 0 {
 		avgDuration = totalDuration / time.Duration(successCount)
 	}
-	printSummary(verbose || jsonOutput, len(results), successCount, failCount, totalSize, avgDuration, absOutputDir)
+	printSummary(verbose || jsonOutput, len(results), successCount, failCount, unchangedCount, len(skipped), totalSize, avgDuration, absOutputDir)
+
+	// Print the detailed breakdown and optionally export it
+	if counters, ok := appCtx.Cache.(cacheCounters); ok {
+		collector.SetCache(counters.Counters())
+	}
+	printStats(collector.Summary())
+	if statsJSON != "" {
+		if err := collector.WriteJSON(statsJSON); err != nil {
+			log.Warn().Err(err).Str("file", statsJSON).Msg("Failed to write stats")
+		}
+	}
 
-	if failCount > 0 {
-		// Avoid printing usage/help when downloads had partial failures; the summary already provides details.
-		cmd.SilenceUsage = true
-		return fmt.Errorf("%d download(s) failed", failCount)
+	// Report what the run budget cut off
+	if len(skipped) > 0 {
+		fmt.Printf("\n%s %s\n", ui.Warning(ui.Mark(ui.IconWarning, ui.T("media.stopped_early"))), ui.Value(ui.T("media.not_fetched", skipped[0].Error, len(skipped))))
+		if verbose || jsonOutput {
+			for _, result := range skipped {
+				fmt.Printf("  %s %s\n", ui.Dim("-"), ui.Value(result.URL))
+			}
+		}
+	}
+
+	var sidecars []string
+	if writeSidecars {
+		sidecars = writeMediaSidecars(results, sources)
+	}
+
+	// Package successful downloads, and their sidecars, if requested
+	if archivePath != "" && successCount > 0 {
+		var entries []archive.Entry
+		sidecarURLs := make(map[string]string)
+		for _, result := range results {
+			if result.Success {
+				entries = append(entries, archive.Entry{Path: result.FilePath, URL: result.URL, ContentType: result.ContentType})
+				sidecarURLs[result.FilePath+downloader.SidecarSuffix] = result.URL
+			}
+		}
+		for _, path := range sidecars {
+			entries = append(entries, archive.Entry{Path: path, URL: sidecarURLs[path], ContentType: "application/json"})
+		}
+		if err := writeArchive(absOutputDir, entries); err != nil {
+			return err
+		}
 	}
 
-	return nil
+	sendNotification(ctx, notifier, notify.Event{
+		Command:  "media",
+		JobID:    appCtx.Config.JobID,
+		Target:   pageURL,
+		Total:    len(results),
+		Success:  successCount,
+		Failed:   failCount,
+		Skipped:  len(skipped),
+		Duration: time.Since(started).Round(time.Millisecond),
+	})
+
+	// Avoid printing usage/help when downloads had partial failures; the summary already provides details.
+	cmd.SilenceUsage = true
+	aborted := len(skipped) > 0 && errors.Is(skipped[0].Error, failpolicy.ErrAborted)
+	return policy.Check(failCount, len(results)-len(skipped), aborted)
 }
 
 // printSummary prints a concise or detailed summary depending on the 'detailed' flag.
-func printSummary(detailed bool, total, success, failed int, totalSize int64, avg time.Duration, outDir string) {
+func printSummary(detailed bool, total, success, failed, unchanged, skipped int, totalSize int64, avg time.Duration, outDir string) {
 	// For non-detailed output ensure a leading blank line so it doesn't attach to the progress bar
 	if !detailed {
-		fmt.Println()
+		ui.Println()
+	}
+	ui.Printf("\n%s\n", ui.Bold(ui.T("summary.title")))
+	ui.Printf("  %s %s\n", ui.Bold(ui.T("summary.total")), ui.Value(ui.T("summary.files", total)))
+	ui.Printf("  %s %s\n", ui.Bold(ui.T("summary.success")), ui.Success(fmt.Sprintf("%d", success)))
+	ui.Printf("  %s %s\n", ui.Bold(ui.T("summary.failed")), ui.Error(fmt.Sprintf("%d", failed)))
+	if unchanged > 0 {
+		ui.Printf("  %s %s\n", ui.Bold(ui.T("summary.unchanged")), ui.Value(fmt.Sprintf("%d", unchanged)))
+	}
+	if skipped > 0 {
+		ui.Printf("  %s %s\n", ui.Bold(ui.T("summary.skipped")), ui.Warning(fmt.Sprintf("%d", skipped)))
 	}
-	fmt.Printf("\n%s\n", ui.Bold("Summary:"))
-	fmt.Printf("  %s %s\n", ui.ColorBold+"Total:"+ui.ColorReset, ui.ColorWhite+fmt.Sprintf("%d files", total)+ui.ColorReset)
-	fmt.Printf("  %s %s\n", ui.ColorBold+"Success:"+ui.ColorReset, ui.Success(fmt.Sprintf("%d", success)))
-	fmt.Printf("  %s %s\n", ui.ColorBold+"Failed:"+ui.ColorReset, ui.Error(fmt.Sprintf("%d", failed)))
-	fmt.Printf("  %s %s\n", ui.ColorBold+"Total Size:"+ui.ColorReset, ui.ColorWhite+formatBytes(totalSize)+ui.ColorReset)
+	ui.Printf("  %s %s\n", ui.Bold(ui.T("summary.total_size")), ui.Value(formatBytes(totalSize)))
 	if success > 0 {
-		fmt.Printf("  %s %s\n", ui.ColorBold+"Average Time:"+ui.ColorReset, ui.ColorWhite+avg.Round(time.Millisecond).String()+ui.ColorReset)
+		ui.Printf("  %s %s\n", ui.Bold(ui.T("summary.avg_time")), ui.Value(avg.Round(time.Millisecond).String()))
 	}
-	fmt.Printf("  %s %s\n", ui.ColorBold+"Output Directory:"+ui.ColorReset, ui.ColorWhite+outDir+ui.ColorReset)
+	ui.Printf("  %s %s\n", ui.Bold(ui.T("summary.output_dir")), ui.Value(outDir))
 }
 
 // formatBytes formats byte count as human-readable string
@@ -277,3 +536,25 @@ func formatBytes(bytes int64) string {
 	}
 	return fmt.Sprintf("%.1f %cB", float64(bytes)/float64(div), "KMGTPE"[exp])
 }
+
+// applyRateOverride applies --rate-limit and --burst, when given, to the
+// application's limiter, which downloads share with the scrapers. Domains
+// with their own rate in the config file keep it.
+func applyRateOverride(cmd *cobra.Command, rl ratelimit.RateLimiter) {
+	flags := cmd.Flags()
+	if !flags.Changed("rate-limit") && !flags.Changed("burst") {
+		return
+	}
+	limiter, ok := rl.(*ratelimit.DomainLimiter)
+	if !ok {
+		return
+	}
+	rps, burst := limiter.Default()
+	if flags.Changed("rate-limit") {
+		rps = rateLimit
+	}
+	if flags.Changed("burst") {
+		burst = rateBurst
+	}
+	limiter.SetDefault(rps, burst)
+}
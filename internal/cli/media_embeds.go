@@ -0,0 +1,86 @@
+// internal/cli/media_embeds.go
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/law-makers/crawl/internal/downloader/embed"
+	"github.com/law-makers/crawl/internal/ui"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+var (
+	resolveEmbeds bool
+	embedsJSON    string
+)
+
+// addMediaEmbedFlags registers the flags for resolving embedded players
+func addMediaEmbedFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&resolveEmbeds, "embeds", false, "Resolve embedded players ("+strings.Join(embed.Providers(), ", ")+") into downloadable streams")
+	cmd.Flags().StringVar(&embedsJSON, "embeds-json", "", "Write the metadata of resolved embedded videos to this JSON file (implies --embeds)")
+}
+
+// embedResolver finds the embedded players on each page and keeps the
+// videos it resolves, so their metadata can be written once at the end
+type embedResolver struct {
+	client *http.Client
+	videos []*embed.Video
+}
+
+// Streams returns the best stream of each player on the page. Players that
+// only give metadata, such as YouTube, are reported but not downloaded.
+func (r *embedResolver) Streams(ctx context.Context, html, pageURL string) []string {
+	embeds, err := embed.Find(html, pageURL)
+	if err != nil {
+		log.Warn().Err(err).Str("url", pageURL).Msg("Failed to look for embedded players")
+		return nil
+	}
+
+	var urls []string
+	for _, e := range embeds {
+		v, err := embed.Resolve(ctx, r.client, e)
+		if err != nil {
+			log.Warn().Err(err).Str("provider", e.Provider).Str("embed", e.URL).Msg("Failed to resolve embedded player")
+		}
+		if v == nil {
+			continue
+		}
+		r.videos = append(r.videos, v)
+		if best, ok := v.Best(); ok {
+			urls = append(urls, best.URL)
+			continue
+		}
+		fmt.Printf("%s %s %s\n", ui.Warning(ui.Mark(ui.IconWarning, ui.T("media.no_stream"))), ui.Value(embedLabel(v)), ui.Dim(v.PageURL))
+	}
+	return urls
+}
+
+// WriteJSON writes the resolved videos to path
+func (r *embedResolver) WriteJSON(path string) error {
+	videos := r.videos
+	if videos == nil {
+		videos = []*embed.Video{}
+	}
+	data, err := json.MarshalIndent(videos, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode embeds: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write embeds: %w", err)
+	}
+	return nil
+}
+
+// embedLabel names a video for messages
+func embedLabel(v *embed.Video) string {
+	if v.Title != "" {
+		return fmt.Sprintf("%s video %q", v.Provider, v.Title)
+	}
+	return fmt.Sprintf("%s video %s", v.Provider, v.ID)
+}
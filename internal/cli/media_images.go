@@ -0,0 +1,40 @@
+// internal/cli/media_images.go
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/law-makers/crawl/internal/downloader/imageproc"
+	"github.com/spf13/cobra"
+)
+
+var (
+	convertFormat string
+	maxWidth      int
+	stripEXIF     bool
+	jpegQuality   int
+)
+
+// addMediaImageFlags registers the flags for processing downloaded images
+func addMediaImageFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&convertFormat, "convert", "", "Convert downloaded images to this format: "+strings.Join(imageproc.Formats, ", ")+" (webp output is lossless only, so usually larger than JPEG; use it for PNG-like images)")
+	cmd.Flags().IntVar(&maxWidth, "max-width", 0, "Scale downloaded images wider than this many pixels down to it (0 keeps the size)")
+	cmd.Flags().BoolVar(&stripEXIF, "strip-exif", false, "Remove EXIF, XMP and text metadata from downloaded images")
+	cmd.Flags().IntVar(&jpegQuality, "jpeg-quality", 90, "Quality (1-100) of images re-encoded as JPEG by --convert or --max-width")
+}
+
+// imageOptions validates the image processing flags
+func imageOptions() (imageproc.Options, error) {
+	format, err := imageproc.ParseFormat(convertFormat)
+	if err != nil {
+		return imageproc.Options{}, fmt.Errorf("invalid --convert: %w", err)
+	}
+	if maxWidth < 0 {
+		return imageproc.Options{}, fmt.Errorf("invalid --max-width: must not be negative")
+	}
+	if jpegQuality < 1 || jpegQuality > 100 {
+		return imageproc.Options{}, fmt.Errorf("invalid --jpeg-quality: must be between 1 and 100")
+	}
+	return imageproc.Options{Format: format, MaxWidth: maxWidth, StripEXIF: stripEXIF, Quality: jpegQuality}, nil
+}
@@ -0,0 +1,70 @@
+// internal/cli/media_pages.go
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/law-makers/crawl/internal/downloader"
+	"github.com/law-makers/crawl/internal/engine"
+	"github.com/law-makers/crawl/internal/stats"
+	"github.com/law-makers/crawl/internal/ui"
+	"github.com/law-makers/crawl/pkg/models"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+var (
+	followNext bool
+	maxPages   int
+)
+
+// addMediaPageFlags registers the flags for following paginated galleries
+func addMediaPageFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&followNext, "follow-next", false, "Follow \"next page\" links and collect media from every page of a gallery before downloading")
+	cmd.Flags().IntVar(&maxPages, "max-pages", 10, "Maximum number of gallery pages to visit with --follow-next (0 for no limit)")
+}
+
+// followGallery fetches the pages after first until there is no next page
+// or --max-pages is reached, adding each page's media to the manifest. A
+// page that fails to load ends the traversal but keeps what was collected.
+func followGallery(scraper engine.Scraper, opts models.RequestOptions, first *models.PageData, extract func(html, pageURL string) ([]string, error), manifest *downloader.Manifest, collector *stats.Collector) {
+	visited := map[string]bool{opts.URL: true}
+	page, pageURL := first, opts.URL
+	for n := 2; maxPages <= 0 || n <= maxPages; n++ {
+		next, err := downloader.NextPage(page.HTML, pageURL)
+		if err != nil {
+			log.Warn().Err(err).Str("url", pageURL).Msg("Failed to look for the next page")
+			return
+		}
+		if next == "" || visited[next] {
+			return
+		}
+		visited[next] = true
+
+		opts.URL = next
+		log.Debug().Str("url", next).Int("page", n).Msg("Fetching next gallery page")
+		page, err = scraper.Fetch(opts)
+		if err != nil {
+			fmt.Printf("%s %s\n", ui.Warning(ui.Mark(ui.IconWarning, ui.T("media.page_failed", n))), ui.Value(err.Error()))
+			return
+		}
+		pageURL = next
+		collector.Record(scraper.Name(), stats.Request{
+			Status:   page.StatusCode,
+			Bytes:    int64(len(page.HTML)),
+			Duration: time.Duration(page.ResponseTime) * time.Millisecond,
+			Waited:   pageWaited(page),
+		})
+
+		urls, err := extract(page.HTML, next)
+		if err != nil {
+			log.Warn().Err(err).Str("url", next).Msg("Failed to extract media")
+			continue
+		}
+		added := manifest.Add(urls)
+		ui.Printf("%s %s %s\n", ui.Info(ui.T("media.page", n)),
+			ui.Value(ui.T("media.page_counts", added, len(urls)-added)), ui.Dim(next))
+	}
+	log.Info().Int("max_pages", maxPages).Msg("Stopped following pages at --max-pages")
+}
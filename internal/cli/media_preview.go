@@ -0,0 +1,133 @@
+// internal/cli/media_preview.go
+package cli
+
+import (
+	"context"
+	"fmt"
+	"text/tabwriter"
+	"time"
+
+	"github.com/law-makers/crawl/internal/app"
+	"github.com/law-makers/crawl/internal/downloader"
+	"github.com/law-makers/crawl/internal/probe"
+	"github.com/law-makers/crawl/internal/ui"
+	outpututil "github.com/law-makers/crawl/internal/utils/output"
+	"github.com/spf13/cobra"
+)
+
+// maxPlanRows bounds each breakdown table; the rest are summed into one row
+const maxPlanRows = 8
+
+var (
+	confirmAbove string
+	noEstimate   bool
+)
+
+// addMediaPreviewFlags registers the flags for the pre-download size check
+func addMediaPreviewFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&confirmAbove, "confirm-above", "1GB", "Ask before downloading when the estimated total is larger than this, e.g. 500MB or 20GB")
+	cmd.Flags().BoolVar(&noEstimate, "no-estimate", false, "Skip the HEAD requests that estimate the download size")
+}
+
+// confirmLimit parses --confirm-above
+func confirmLimit() (int64, error) {
+	limit, err := outpututil.ParseSize(confirmAbove)
+	if err != nil {
+		return 0, fmt.Errorf("invalid --confirm-above: %w", err)
+	}
+	return limit, nil
+}
+
+// planMedia sends a HEAD request for each media URL and groups the results
+func planMedia(ctx context.Context, appCtx *app.Application, urls []string, headerMap map[string]string) downloader.Plan {
+	prober := probe.New(appCtx.NewHTTPClient(15*time.Second, "media"), appCtx.RateLimiter, appCtx.Concurrency)
+	prober.SetUserAgent(appCtx.Config.UserAgent)
+	prober.SetHeaders(headerMap)
+	// A server that rejects HEAD must not make the estimate download the file
+	prober.SetHeadOnly(true)
+
+	files := make([]downloader.PlannedFile, len(urls))
+	for i, r := range prober.ProbeAll(ctx, urls, concurrency) {
+		files[i] = downloader.PlannedFile{URL: r.URL, ContentType: r.ContentType, Size: r.Size}
+		if r.Error != "" || r.Status < 200 || r.Status > 299 {
+			files[i].Size = -1
+		}
+	}
+	return downloader.NewPlan(files)
+}
+
+// printMediaPlan prints the type, extension and host breakdowns and the
+// estimated total
+func printMediaPlan(p downloader.Plan) {
+	ui.Printf("%s\n", ui.Bold(ui.T("plan.title")))
+	tw := tabwriter.NewWriter(ui.Status(), 0, 0, 2, ' ', 0)
+	for _, section := range []struct {
+		title  string
+		groups []downloader.Group
+	}{{"TYPE", p.ByType}, {"EXTENSION", p.ByExtension}, {"HOST", p.ByHost}} {
+		if section.title != "TYPE" {
+			fmt.Fprintln(tw, "\t\t")
+		}
+		fmt.Fprintf(tw, "  %s\tFILES\tSIZE\n", section.title)
+		for _, g := range topGroups(section.groups) {
+			fmt.Fprintf(tw, "  %s\t%d\t%s\n", g.Key, g.Files, planSize(g.Bytes, g.Unknown))
+		}
+	}
+	tw.Flush()
+	ui.Println()
+
+	estimate := formatBytes(p.Estimate())
+	if p.Unknown > 0 {
+		known := p.Files - p.Unknown
+		if known == 0 {
+			estimate = ui.T("plan.unknown")
+		} else {
+			estimate = "~" + estimate
+		}
+		ui.Printf("%s %s %s\n\n", ui.Info(ui.T("plan.estimate")), ui.Value(estimate),
+			ui.Dim(ui.T("plan.unsized", p.Unknown, p.Files)))
+		return
+	}
+	ui.Printf("%s %s\n\n", ui.Info(ui.T("plan.estimate")), ui.Value(estimate))
+}
+
+// topGroups keeps the first maxPlanRows-1 groups and sums the rest into one
+func topGroups(groups []downloader.Group) []downloader.Group {
+	if len(groups) <= maxPlanRows {
+		return groups
+	}
+	top := append([]downloader.Group(nil), groups[:maxPlanRows-1]...)
+	rest := downloader.Group{Key: fmt.Sprintf("(%d more)", len(groups)-len(top))}
+	for _, g := range groups[len(top):] {
+		rest.Files += g.Files
+		rest.Bytes += g.Bytes
+		rest.Unknown += g.Unknown
+	}
+	return append(top, rest)
+}
+
+// planSize formats a group's known size, noting files of unknown size
+func planSize(bytes int64, unknown int) string {
+	size := formatBytes(bytes)
+	if unknown > 0 {
+		size += fmt.Sprintf(" + %d unknown", unknown)
+	}
+	return size
+}
+
+// confirmDownload returns nil when the plan is within --confirm-above, --yes
+// was given, or the user agrees at a prompt
+func confirmDownload(p downloader.Plan, limit int64) error {
+	estimate := p.Estimate()
+	if estimate <= limit {
+		return nil
+	}
+	refusal := fmt.Errorf("estimated download of %s exceeds --confirm-above %s; pass --yes to download anyway", formatBytes(estimate), confirmAbove)
+	if err := confirm(ui.T("plan.confirm", p.Files, formatBytes(estimate)), refusal); err != nil {
+		if err == refusal {
+			return err
+		}
+		return fmt.Errorf("download cancelled")
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+// internal/cli/media_sidecar.go
+package cli
+
+import (
+	"os"
+
+	"github.com/law-makers/crawl/internal/downloader"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+var writeSidecars bool
+
+// addMediaSidecarFlags registers --sidecar on the media command
+func addMediaSidecarFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&writeSidecars, "sidecar", false, "Write <file>.json next to each downloaded file: the page and URL it came from, its alt text and caption, content type, dimensions or duration, and SHA-256")
+}
+
+// mediaSources remembers the page each media URL was found on and what
+// the page said about it, for --sidecar
+type mediaSources map[string]downloader.MediaSource
+
+// wrap returns extract, also recording the source of each URL it returns.
+// URLs the DOM doesn't describe, such as streams resolved from embedded
+// players, get just the page.
+func (m mediaSources) wrap(extract func(html, pageURL string) ([]string, error)) func(html, pageURL string) ([]string, error) {
+	return func(html, pageURL string) ([]string, error) {
+		urls, err := extract(html, pageURL)
+		if err != nil {
+			return urls, err
+		}
+		described, err := downloader.DescribeMedia(html, pageURL)
+		if err != nil {
+			log.Debug().Err(err).Str("url", pageURL).Msg("Failed to describe media")
+		}
+		for _, u := range urls {
+			if _, seen := m[u]; seen {
+				continue
+			}
+			src, ok := described[u]
+			if !ok {
+				src = downloader.MediaSource{Page: pageURL}
+			}
+			m[u] = src
+		}
+		return urls, nil
+	}
+}
+
+// writeMediaSidecars writes the sidecar of each downloaded file and returns
+// their paths. A file left unchanged by --incremental keeps the sidecar
+// written when it was downloaded.
+func writeMediaSidecars(results []*downloader.DownloadResult, sources mediaSources) []string {
+	var paths []string
+	for _, result := range results {
+		if !result.Success {
+			continue
+		}
+		if result.Unchanged {
+			if _, err := os.Stat(result.FilePath + downloader.SidecarSuffix); err == nil {
+				paths = append(paths, result.FilePath+downloader.SidecarSuffix)
+				continue
+			}
+		}
+		path, err := downloader.WriteSidecar(result, sources[result.URL])
+		if err != nil {
+			log.Warn().Err(err).Str("file", result.FilePath).Msg("Failed to write sidecar")
+			continue
+		}
+		paths = append(paths, path)
+	}
+	return paths
+}
@@ -0,0 +1,31 @@
+// internal/cli/noai.go
+package cli
+
+import (
+	"time"
+
+	"github.com/law-makers/crawl/internal/app"
+	"github.com/law-makers/crawl/internal/engine"
+	"github.com/law-makers/crawl/internal/engine/tdm"
+	"github.com/spf13/cobra"
+)
+
+var (
+	checkNoAI   bool
+	respectNoAI bool
+)
+
+// addNoAIFlags registers --check-noai and --respect-noai on a command
+func addNoAIFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&checkNoAI, "check-noai", false, "Also read each site's robots.txt for AI crawler blocks and Content-Usage preferences, adding them to the page's noai list (headers and meta tags are always checked)")
+	cmd.Flags().BoolVar(&respectNoAI, "respect-noai", false, "Skip pages that reserve text and data mining: noai/noimageai in X-Robots-Tag or meta robots, tdm-reservation, or robots.txt AI directives (implies --check-noai)")
+}
+
+// withNoAI wraps scraper with the robots.txt check asked for by
+// --check-noai or --respect-noai, or returns it unchanged
+func withNoAI(appCtx *app.Application, scraper engine.Scraper) engine.Scraper {
+	if !checkNoAI && !respectNoAI {
+		return scraper
+	}
+	return tdm.NewChecker(scraper, appCtx.NewHTTPClient(15*time.Second, "robots"), respectNoAI)
+}
@@ -0,0 +1,38 @@
+// internal/cli/notify.go
+package cli
+
+import (
+	"context"
+
+	"github.com/law-makers/crawl/internal/notify"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+var (
+	notifyURLs      []string
+	notifyTemplate  string
+	notifyThreshold float64
+)
+
+// addNotifyFlags registers the flags that send a notification when a
+// multi-item run finishes
+func addNotifyFlags(cmd *cobra.Command) {
+	cmd.Flags().StringArrayVar(&notifyURLs, "notify", []string{}, "Notify when the run finishes (slack://, discord://, webhook://, https://, smtp://); repeatable")
+	cmd.Flags().StringVar(&notifyTemplate, "notify-template", "", "Go template for notification messages (fields: .Kind .Command .JobID .Target .Total .Success .Failed .Skipped .Duration)")
+	cmd.Flags().Float64Var(&notifyThreshold, "notify-failure-threshold", 0, "Report a failure-threshold breach when at least this percentage of items fail (0 disables)")
+}
+
+// openNotifier opens the --notify targets up front, so a bad URL or
+// template fails before a long run starts
+func openNotifier() (*notify.Dispatcher, error) {
+	return notify.NewDispatcher(notifyURLs, notifyTemplate, notifyThreshold)
+}
+
+// sendNotification delivers event to the --notify targets. Notifications
+// are best-effort; a failed webhook should not fail the run.
+func sendNotification(ctx context.Context, notifier *notify.Dispatcher, event notify.Event) {
+	if err := notifier.Finish(ctx, event); err != nil {
+		log.Warn().Err(err).Msg("Failed to send notification")
+	}
+}
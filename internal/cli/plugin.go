@@ -0,0 +1,184 @@
+// internal/cli/plugin.go
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/law-makers/crawl/internal/plugin"
+	"github.com/law-makers/crawl/internal/ui"
+	"github.com/law-makers/crawl/pkg/models"
+	"github.com/spf13/cobra"
+)
+
+var (
+	extractPlugin string
+	pluginArgs    []string
+	pluginTimeout time.Duration
+	pluginMemory  uint32
+)
+
+// The --plugin extractor is opened once per run, so a WASM module is only
+// compiled once for a batch
+var (
+	extractorOnce sync.Once
+	extractor     plugin.Extractor
+	extractorErr  error
+)
+
+// pluginCmd groups the plugin subcommands
+var pluginCmd = &cobra.Command{
+	Use:   "plugin",
+	Short: "List and use crawl-<name> plugins",
+	Long: `Plugins are executables named crawl-<name> on PATH, so teams can ship
+their own commands and extractors without forking crawl.
+
+Commands: "crawl <name> args..." runs crawl-<name> args... whenever <name> is
+not a built-in command. CRAWL_BIN points back at crawl, and
+CRAWL_PLUGIN_PROTOCOL names the JSON contract below.
+
+Extractors: "crawl get URL --plugin <name>" runs "crawl-<name> extract" and
+writes one JSON object to its stdin:
+
+  {"protocol": "crawl.plugin/v1", "kind": "extract",
+   "args": [--plugin-arg values], "page": {page data, including "html"}}
+
+The plugin answers on stdout with one of:
+
+  {"page": {...}}     the transformed page, which --output, --publish and
+                      assertions then use as if crawl had produced it
+  {"output": ...}     any JSON, printed or written to --output as-is
+  {"error": "..."}    the extraction failed
+
+A non-zero exit also fails it; the plugin's stderr is shown as-is.
+
+WASM extractors: "--plugin prices.wasm" runs a WebAssembly module built for
+WASI preview 1 (e.g. GOOS=wasip1 GOARCH=wasm) with the same contract, in a
+sandbox: no files, network or host environment, a fresh instance per page,
+and limits set by --plugin-timeout and --plugin-memory. Use it for
+transforms shared by others that shouldn't run with your privileges.`,
+	Example: `  # Show installed plugins
+  crawl plugin list
+
+  # Run the crawl-sitemap plugin as a command
+  crawl sitemap https://example.com
+
+  # Post-process a page with crawl-prices
+  crawl get https://shop.example.com --plugin prices --plugin-arg=--currency=EUR
+
+  # Same, sandboxed as WebAssembly
+  crawl get https://shop.example.com --plugin ./prices.wasm --plugin-timeout 5s`,
+}
+
+var pluginListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List plugins found on PATH",
+	Args:  cobra.NoArgs,
+	RunE:  runPluginList,
+}
+
+func init() {
+	rootCmd.AddCommand(pluginCmd)
+	pluginCmd.AddCommand(pluginListCmd)
+	addPluginFlags(getCmd)
+}
+
+// addPluginFlags registers --plugin and --plugin-arg on a command
+func addPluginFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&extractPlugin, "plugin", "", "Pass each page through the crawl-<name> extractor plugin (see 'crawl plugin --help')")
+	cmd.Flags().StringArrayVar(&pluginArgs, "plugin-arg", []string{}, "Argument for the --plugin extractor (repeatable)")
+	cmd.Flags().DurationVar(&pluginTimeout, "plugin-timeout", plugin.DefaultWASMTimeout, "Time limit per page for a .wasm --plugin")
+	cmd.Flags().Uint32Var(&pluginMemory, "plugin-memory", plugin.DefaultWASMMemoryMB, "Memory limit in MB for a .wasm --plugin")
+}
+
+func runPluginList(cmd *cobra.Command, args []string) error {
+	plugins := plugin.Discover()
+	if jsonOutput {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(plugins)
+	}
+	if len(plugins) == 0 {
+		fmt.Println(ui.Info(ui.T("plugin.none", plugin.Prefix)))
+		return nil
+	}
+	for _, p := range plugins {
+		fmt.Printf("%s  %s\n", ui.Bold(p.Name), ui.Dim(p.Path))
+		for _, path := range p.Shadowed {
+			fmt.Printf("  %s %s\n", ui.Warning(ui.Mark(ui.IconWarning, ui.T("plugin.shadowed"))), path)
+		}
+	}
+	return nil
+}
+
+// runPluginCommand runs "crawl <name> args..." as the crawl-<name> plugin
+// when <name> is not a built-in command. It reports whether it handled the
+// arguments and the exit code to leave with.
+func runPluginCommand(args []string) (bool, int) {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return false, 0
+	}
+	if c, _, err := rootCmd.Find(args); err == nil && c != rootCmd {
+		return false, 0
+	}
+	p, err := plugin.Find(args[0])
+	if err != nil {
+		return false, 0
+	}
+
+	if err := p.Command(context.Background(), args[1:]).Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return true, exitErr.ExitCode()
+		}
+		fmt.Fprintf(os.Stderr, "Error: plugin %s: %v\n", p.Name, err)
+		return true, 1
+	}
+	return true, 0
+}
+
+// applyPlugin runs the --plugin extractor on a page. It returns the page to
+// carry on with, or the plugin's own output to print or save instead.
+func applyPlugin(ctx context.Context, page *models.PageData) (*models.PageData, json.RawMessage, error) {
+	if extractPlugin == "" {
+		return page, nil, nil
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	extractorOnce.Do(func() {
+		extractor, extractorErr = plugin.Open(ctx, extractPlugin, plugin.WASMOptions{Timeout: pluginTimeout, MemoryMB: pluginMemory})
+	})
+	if extractorErr != nil {
+		return nil, nil, extractorErr
+	}
+	resp, err := extractor.Extract(ctx, page, pluginArgs)
+	if err != nil {
+		return nil, nil, err
+	}
+	if resp.Page != nil {
+		return resp.Page, nil, nil
+	}
+	return nil, resp.Output, nil
+}
+
+// writePluginOutput prints a plugin's output, or writes it to --output
+func writePluginOutput(raw json.RawMessage) error {
+	if output == "" {
+		_, err := fmt.Printf("%s\n", raw)
+		return err
+	}
+	if err := os.WriteFile(output, append(raw, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write plugin output: %w", err)
+	}
+	link := terminalHyperlink(output, output)
+	ui.Printf("%s %s\n", ui.Success(ui.Mark(ui.IconSuccess, ui.T("get.saved_to"))), ui.Bold(link))
+	return nil
+}
@@ -0,0 +1,45 @@
+// internal/cli/policy.go
+package cli
+
+import (
+	"strings"
+
+	"github.com/law-makers/crawl/internal/policy"
+	"github.com/spf13/cobra"
+)
+
+// policyFlags maps the flags that turn on each feature a policy can disable.
+// --proxy and --cdp are checked when the app is created as they can also
+// come from the environment or config file.
+var policyFlags = []struct {
+	feature string
+	flags   []string
+}{
+	{policy.FeatureProxy, []string{"retry-proxies"}},
+	{policy.FeaturePlugins, []string{"plugin"}},
+	{policy.FeatureEscalate, []string{"escalate", "retry-suspected"}},
+	{policy.FeatureHeadful, []string{"headful", "devtools", "slowmo"}},
+}
+
+// checkPolicy rejects flags for features the system policy disables, before
+// anything is fetched
+func checkPolicy(cmd *cobra.Command, pol *policy.Policy) error {
+	if pol == nil {
+		return nil
+	}
+	for _, pf := range policyFlags {
+		for _, name := range pf.flags {
+			if f := cmd.Flags().Lookup(name); f != nil && f.Changed {
+				if err := pol.Forbid(pf.feature, "--"+name); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	for _, rung := range escalate {
+		if strings.EqualFold(strings.TrimSpace(rung), "stealth") {
+			return pol.Forbid(policy.FeatureStealth, "--escalate stealth")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+// internal/cli/prefetch.go
+package cli
+
+import (
+	"fmt"
+
+	"github.com/law-makers/crawl/internal/app"
+	"github.com/law-makers/crawl/internal/engine"
+	"github.com/law-makers/crawl/internal/engine/prefetch"
+	"github.com/spf13/cobra"
+)
+
+var prefetchLinks string
+
+// addPrefetchFlags registers --prefetch-links on a command
+func addPrefetchFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&prefetchLinks, "prefetch-links", "", "With several URLs or --input: after each page, fetch its first N same-domain links in the background (same-domain:N, default 10), so a later URL in the run is answered from the cache")
+}
+
+// wrapPrefetch wraps scraper in a prefetcher when --prefetch-links is set.
+// The returned stop function drops prefetches that have not started.
+func wrapPrefetch(appCtx *app.Application, scraper engine.Scraper) (engine.Scraper, func(), error) {
+	if prefetchLinks == "" {
+		return scraper, func() {}, nil
+	}
+	spec, err := prefetch.ParseSpec(prefetchLinks)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid --prefetch-links: %w", err)
+	}
+	p := prefetch.New(scraper, appCtx.Cache, spec, appCtx.Config.CacheTTL)
+	return p, p.Close, nil
+}
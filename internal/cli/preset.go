@@ -0,0 +1,95 @@
+// internal/cli/preset.go
+package cli
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/law-makers/crawl/internal/app"
+	"github.com/law-makers/crawl/internal/preset"
+	"github.com/law-makers/crawl/internal/ratelimit"
+	"github.com/law-makers/crawl/pkg/models"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+var presetName string
+
+// activePreset is the --preset in effect, nil without one
+var activePreset *preset.Preset
+
+// addPresetFlags registers --preset on a command
+func addPresetFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&presetName, "preset", "", fmt.Sprintf("Defaults for a kind of site: %s. Sets selectors to try, --fields and politeness; flags you pass win", strings.Join(preset.Names(), ", ")))
+}
+
+// applyPreset loads --preset and takes over the flags the user left unset
+func applyPreset(cmd *cobra.Command) error {
+	activePreset = nil
+	if presetName == "" {
+		return nil
+	}
+	p, err := preset.Get(presetName)
+	if err != nil {
+		return fmt.Errorf("invalid --preset: %w", err)
+	}
+	activePreset = &p
+	if !cmd.Flags().Changed("concurrency") {
+		getConcurrency = p.Concurrency
+	}
+	return nil
+}
+
+// presetSelectors returns the selectors to try when --selector was not given
+func presetSelectors(cmd *cobra.Command) []string {
+	if activePreset == nil || cmd.Flags().Changed("selector") {
+		return nil
+	}
+	return activePreset.Selectors
+}
+
+// requestFields returns --fields, or the preset's fields when it is unset
+func requestFields() []models.Field {
+	if fields == "" && activePreset != nil {
+		return activePreset.Fields
+	}
+	return parseFields(fields)
+}
+
+// applyPresetPoliteness slows requests to the hosts in urls down to the
+// preset's rate and concurrency. Hosts with their own settings in the config
+// file, and an explicit --max-per-domain, are left alone.
+func applyPresetPoliteness(cmd *cobra.Command, appCtx *app.Application, urls []string) {
+	if activePreset == nil {
+		return
+	}
+	p := activePreset
+	limiter, _ := appCtx.RateLimiter.(*ratelimit.DomainLimiter)
+	seen := make(map[string]bool)
+	for _, u := range urls {
+		host := hostOf(u)
+		if host == "" || seen[host] {
+			continue
+		}
+		seen[host] = true
+		override, _ := appCtx.Config.DomainFor(u)
+		if limiter != nil && override.RPS == 0 {
+			limiter.SetLimit(host, p.RPS, p.Burst)
+		}
+		_, fromFlag := appCtx.Config.DomainConcurrency[host]
+		if appCtx.Concurrency != nil && override.MaxConcurrent == 0 && !fromFlag && !cmd.Flags().Changed("max-per-domain") {
+			appCtx.Concurrency.SetMax(host, p.MaxPerDomain)
+		}
+	}
+	log.Debug().Str("preset", p.Name).Float64("rps", p.RPS).Int("max_per_domain", p.MaxPerDomain).Msg("Applied preset politeness")
+}
+
+// hostOf returns the host (with any port) that the limiters key a URL by
+func hostOf(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return u.Host
+}
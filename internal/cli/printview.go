@@ -0,0 +1,60 @@
+// internal/cli/printview.go
+package cli
+
+import (
+	"github.com/law-makers/crawl/internal/engine"
+	"github.com/law-makers/crawl/internal/logging"
+	"github.com/law-makers/crawl/pkg/models"
+	"github.com/spf13/cobra"
+)
+
+var preferPrint bool
+
+// addPrintFlags registers --prefer-print on a command
+func addPrintFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&preferPrint, "prefer-print", false, "Read pages from their print or reader version when they link to one (rel=alternate media=print, ?print=1, /print). The page's URL is kept; the version read is in \"fetched_from\"")
+}
+
+// printPreferrer fetches the print version of pages that have one in place
+// of the page
+type printPreferrer struct {
+	next engine.Scraper
+}
+
+// Name returns the name of the wrapped engine
+func (p *printPreferrer) Name() string {
+	return p.next.Name()
+}
+
+// Fetch fetches opts, then the print version it links to if any. The page
+// itself is returned when the print version fails or comes back empty.
+func (p *printPreferrer) Fetch(opts models.RequestOptions) (*models.PageData, error) {
+	data, err := p.next.Fetch(opts)
+	if err != nil || data.PrintURL == "" {
+		return data, err
+	}
+	logger := logging.ForRequest(opts, "")
+	printOpts := opts
+	printOpts.URL = data.PrintURL
+	printOpts.DumpRaw = false // --raw-output keeps the page as requested
+	printed, err := p.next.Fetch(printOpts)
+	if err != nil {
+		logger.Warn().Err(err).Str("print_url", data.PrintURL).Msg("Print version failed, using the page")
+		return data, nil
+	}
+	if printed.StatusCode < 200 || printed.StatusCode > 299 || printed.Content == "" {
+		logger.Warn().Int("status", printed.StatusCode).Str("print_url", data.PrintURL).Msg("Print version is empty or an error, using the page")
+		return data, nil
+	}
+	logger.Debug().Str("print_url", data.PrintURL).Msg("Using print version")
+	printed.URL, printed.PrintURL, printed.FetchedFrom = opts.URL, data.PrintURL, data.PrintURL
+	return printed, nil
+}
+
+// withPrintPreference wraps scraper for --prefer-print, or returns it unchanged
+func withPrintPreference(scraper engine.Scraper) engine.Scraper {
+	if !preferPrint {
+		return scraper
+	}
+	return &printPreferrer{next: scraper}
+}
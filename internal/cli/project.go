@@ -0,0 +1,29 @@
+// internal/cli/project.go
+package cli
+
+import (
+	outpututil "github.com/law-makers/crawl/internal/utils/output"
+	"github.com/spf13/cobra"
+)
+
+var (
+	onlyFields string
+	omitFields string
+)
+
+// addProjectionFlags registers the flags that choose which page fields are
+// written to JSON and CSV output
+func addProjectionFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&onlyFields, "only", "", "Write only these fields, e.g. url,title,structured (html is written only when listed here)")
+	cmd.Flags().StringVar(&omitFields, "omit", "", "Leave these fields out of the output, e.g. headers,scripts,links")
+}
+
+// applyProjection validates the projection flags and installs them for the exporters
+func applyProjection() error {
+	p, err := outpututil.ParseProjection(onlyFields, omitFields)
+	if err != nil {
+		return err
+	}
+	outpututil.SetProjection(p)
+	return nil
+}
@@ -0,0 +1,48 @@
+// internal/cli/prompt.go
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/law-makers/crawl/internal/ui"
+	"golang.org/x/term"
+)
+
+// assumeYes is set by --yes/--non-interactive (or CRAWL_NON_INTERACTIVE)
+var assumeYes bool
+
+// interactive reports whether crawl may stop and wait for the user
+func interactive() bool {
+	return !assumeYes && term.IsTerminal(int(os.Stdin.Fd()))
+}
+
+// confirm asks a yes/no question on stderr. --yes answers it; without a
+// terminal to ask on it returns refusal, so scripts must opt in with --yes
+// instead of hanging on stdin.
+func confirm(question string, refusal error) error {
+	if assumeYes {
+		return nil
+	}
+	if !interactive() {
+		return refusal
+	}
+	fmt.Fprintf(os.Stderr, "%s [y/N] ", ui.Warning(ui.Mark(ui.IconWarning, question)))
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return nil
+	}
+	return fmt.Errorf("cancelled")
+}
+
+// pause waits for Enter, and returns at once when crawl may not wait
+func pause(message string) {
+	if !interactive() {
+		return
+	}
+	fmt.Fprint(os.Stderr, message)
+	bufio.NewReader(os.Stdin).ReadString('\n')
+}
@@ -0,0 +1,27 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/law-makers/crawl/internal/sink"
+	"github.com/spf13/cobra"
+)
+
+var publishURL string
+
+// addPublishFlags registers --publish on cmd
+func addPublishFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&publishURL, "publish", "", "Also publish each page as a message: nats://host:4222/subject or kafka://broker:9092/topic, keyed by URL (?key=sha256 for its hash) with job ID, request ID, status and content type headers")
+}
+
+// checkPublish validates --publish, and keeps message buses out of
+// --output so each page reaches them one way only
+func checkPublish() error {
+	if sink.IsMessageBus(output) {
+		return fmt.Errorf("--output %s: publish to NATS or Kafka with --publish", output)
+	}
+	if publishURL != "" && !sink.IsMessageBus(publishURL) {
+		return fmt.Errorf("invalid --publish: %s (must be a nats:// or kafka:// URL; send pages to a database or search index with --output)", publishURL)
+	}
+	return nil
+}
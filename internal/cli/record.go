@@ -0,0 +1,108 @@
+// internal/cli/record.go
+package cli
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/law-makers/crawl/internal/ui"
+	headersutil "github.com/law-makers/crawl/internal/utils/headers"
+	urlutil "github.com/law-makers/crawl/internal/utils/url"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+var recordOutput string
+
+// recordCmd represents the record command
+var recordCmd = &cobra.Command{
+	Use:   "record <url>",
+	Short: "Capture the raw HTML of a URL as a test fixture",
+	Long: `Fetches a URL once and saves the raw, unmodified response body to disk.
+
+Recorded fixtures can be checked into a repository and used to write
+regression tests for scraping logic, so selector changes can be verified
+against a known page even after the live site changes.`,
+	Example: `  # Record a fixture next to your tests
+  crawl record https://example.com --output testdata/example.html
+
+  # Record with custom headers
+  crawl record https://example.com -H "Accept-Language: de-DE"`,
+	Args: cobra.ExactArgs(1),
+	RunE: runRecord,
+}
+
+func init() {
+	rootCmd.AddCommand(recordCmd)
+
+	recordCmd.Flags().StringVarP(&recordOutput, "output", "o", "", "File path for the fixture (default: <host>.html)")
+	recordCmd.Flags().StringArrayVarP(&headers, "header", "H", []string{}, "Custom headers")
+}
+
+func runRecord(cmd *cobra.Command, args []string) error {
+	pageURL := args[0]
+
+	// Validate URL
+	if err := urlutil.ValidateURL(pageURL); err != nil {
+		return err
+	}
+
+	appCtx := GetAppFromCmd(cmd)
+	if appCtx == nil {
+		return fmt.Errorf("application not initialized")
+	}
+
+	// Derive a default fixture name from the host
+	outPath := recordOutput
+	if outPath == "" {
+		u, _ := url.Parse(pageURL)
+		outPath = strings.ReplaceAll(u.Host, ":", "_") + ".html"
+	}
+
+	req, err := http.NewRequestWithContext(cmd.Context(), "GET", pageURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", GetUserAgent())
+	req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
+	for key, value := range headersutil.ParseHeaders(headers) {
+		req.Header.Set(key, value)
+	}
+
+	log.Debug().Str("url", pageURL).Str("output", outPath).Msg("Recording fixture")
+	resp, err := appCtx.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to fetch URL: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		log.Warn().Int("status", resp.StatusCode).Msg("Recording an error response")
+	}
+
+	if dir := filepath.Dir(outPath); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create fixture directory: %w", err)
+		}
+	}
+
+	file, err := os.Create(outPath)
+	if err != nil {
+		return fmt.Errorf("failed to create fixture: %w", err)
+	}
+	defer file.Close()
+
+	n, err := io.Copy(file, resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to write fixture: %w", err)
+	}
+
+	link := terminalHyperlink(filepath.Base(outPath), outPath)
+	ui.Printf("%s %s %s\n", ui.Success(ui.Mark(ui.IconSuccess, ui.T("record.saved"))), ui.Bold(link), ui.Dim(ui.T("record.detail", formatBytes(n), resp.StatusCode)))
+	return nil
+}
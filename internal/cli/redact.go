@@ -0,0 +1,41 @@
+// internal/cli/redact.go
+package cli
+
+import (
+	"fmt"
+
+	"github.com/law-makers/crawl/internal/redact"
+	"github.com/law-makers/crawl/pkg/models"
+	"github.com/spf13/cobra"
+)
+
+var (
+	redactSpec string
+	redactor   *redact.Redactor
+)
+
+// addRedactFlags registers --redact on a command
+func addRedactFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&redactSpec, "redact", "", "Mask personal data in the title, content, HTML and fields before anything is written or sent: pii, or some of email, phone, id (SSN/NI numbers), card")
+}
+
+// applyRedaction validates --redact and sets up the redactor it asks for
+func applyRedaction() error {
+	redactor = nil
+	if redactSpec == "" {
+		return nil
+	}
+	r, err := redact.Parse(redactSpec)
+	if err != nil {
+		return fmt.Errorf("invalid --redact: %w", err)
+	}
+	redactor = r
+	return nil
+}
+
+// redactPage masks personal data in a page when --redact is set
+func redactPage(page *models.PageData) {
+	if redactor != nil {
+		redactor.Page(page)
+	}
+}
@@ -3,16 +3,18 @@ package cli
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"strings"
 
-	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
 
 	"github.com/law-makers/crawl/internal/app"
+	"github.com/law-makers/crawl/internal/buildinfo"
 	"github.com/law-makers/crawl/internal/config"
+	"github.com/law-makers/crawl/internal/logging"
 	"github.com/law-makers/crawl/internal/ui"
 )
 
@@ -30,15 +32,26 @@ var rootCmd = &cobra.Command{
 	Use:     "crawl",
 	Short:   "A fast and cross-platform CLI for scraping websites",
 	Long:    `Crawl is a unified data extraction tool designed to scrape static and SPA sites.`,
-	Version: "0.1.0",
+	Version: buildinfo.Version,
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 // It initializes the application and passes it to all commands.
 func Execute() {
+	// crawl <name> runs the crawl-<name> plugin when <name> isn't built in
+	if handled, code := runPluginCommand(os.Args[1:]); handled {
+		os.Exit(code)
+	}
+
 	// Execute CLI (application is initialized lazily in PersistentPreRunE)
 	err := rootCmd.Execute()
+	closeLog()
+	// Failed requests and failed assertions choose their own exit code
+	var exitErr interface{ ExitCode() int }
+	if errors.As(err, &exitErr) {
+		os.Exit(exitErr.ExitCode())
+	}
 	if err != nil {
 		os.Exit(1)
 	}
@@ -56,6 +69,13 @@ func init() {
 			log.Warn().Err(err).Msg("failed to load configuration, using defaults")
 			cfg = &config.Config{}
 		}
+		if cfg.JobID == "" {
+			cfg.JobID = jobID
+		}
+		// The raw dump describes one page fetch; other commands make many requests
+		if cfg.RawOutput != "" && cmd != getCmd {
+			return fmt.Errorf("--raw-output only works with crawl get")
+		}
 
 		ctx, cancel := context.WithTimeout(context.Background(), cfg.HTTPTimeout*10)
 		defer cancel()
@@ -64,6 +84,10 @@ func init() {
 		if err != nil {
 			return err
 		}
+		if err := checkPolicy(cmd, appCtx.Policy); err != nil {
+			_ = appCtx.Close(ctx)
+			return err
+		}
 
 		// Store app in the current command's context for commands to access
 		SetApp(cmd, appCtx)
@@ -106,26 +130,37 @@ func initConfig() {
 		log.Warn().Err(err).Msg("failed to load configuration, using defaults")
 		cfg = &config.Config{}
 	}
-	switch strings.ToLower(cfg.LogLevel) {
-	case "debug":
-		zerolog.SetGlobalLevel(zerolog.DebugLevel)
-		verbose = true
-	case "error":
-		zerolog.SetGlobalLevel(zerolog.ErrorLevel)
-		quiet = true
-	default:
-		// Default to suppressing info logs unless verbose is explicitly requested
-		zerolog.SetGlobalLevel(zerolog.ErrorLevel)
+	verbose = strings.ToLower(cfg.LogLevel) == "debug"
+
+	if cfg.Theme != "" {
+		if theme, err := ui.ThemeByName(cfg.Theme); err != nil {
+			log.Warn().Err(err).Msg("Ignoring theme")
+		} else {
+			ui.SetTheme(theme)
+		}
+	}
+	if cfg.Language != "" {
+		if err := ui.SetLanguage(cfg.Language); err != nil {
+			log.Warn().Err(err).Msg("Ignoring language")
+		}
+	} else if lang := os.Getenv("LANG"); lang != "" {
+		// Best effort: most locales have no catalog and stay in English
+		_ = ui.SetLanguage(lang)
+	}
+	// Legacy Windows consoles print escape codes literally unless told not to
+	if cfg.NoColor || !ui.EnableVirtualTerminal() {
+		ui.DisableColors()
 	}
+	quiet = cfg.Quiet
+	ui.SetQuiet(quiet)
 
-	if cfg.JSONLog {
-		log.Logger = zerolog.New(os.Stderr).With().Timestamp().Logger()
-		jsonOutput = true
-	} else {
-		log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stderr})
+	jsonOutput = cfg.JSONLog
+	if err := setupLogging(cfg); err != nil {
+		log.Warn().Err(err).Msg("Logging to stderr instead")
 	}
 
 	// Populate legacy globals so existing commands work
+	assumeYes = cfg.NonInteractive
 	userAgent = cfg.UserAgent
 	proxy = cfg.Proxy
 	timeout = cfg.HTTPTimeout.String()
@@ -133,12 +168,48 @@ func initConfig() {
 	log.Debug().Str("user_agent", cfg.UserAgent).Msg("Configuration loaded")
 }
 
+// closeLog closes the --log-file, if one is open
+var closeLog = func() error { return nil }
+
+// jobID identifies this run; it is picked when logging is set up so the
+// logs and the application's config agree on it
+var jobID string
+
+// setupLogging points the global logger at the configured format and file,
+// tagging every event with the run's job ID, which it picks if unset
+func setupLogging(cfg *config.Config) error {
+	format := cfg.LogFormat
+	if format == "" && cfg.JSONLog {
+		format = logging.FormatJSON
+	}
+	if cfg.JobID == "" {
+		cfg.JobID = logging.NewJobID()
+	}
+	jobID = cfg.JobID
+	opts := logging.Options{
+		Level:      cfg.LogLevel,
+		Format:     format,
+		File:       cfg.LogFile,
+		MaxSize:    cfg.LogMaxSize,
+		MaxBackups: cfg.LogMaxBackups,
+		NoColor:    !ui.ColorsEnabled(),
+		JobID:      cfg.JobID,
+	}
+	closeFn, err := logging.Setup(opts)
+	if err != nil {
+		opts.File = ""
+		closeFn, _ = logging.Setup(opts)
+	}
+	closeLog = closeFn
+	return err
+}
+
 // GetUserAgent returns the configured user agent string
 func GetUserAgent() string {
 	if userAgent != "" {
 		return userAgent
 	}
-	return "Crawl/1.0 (https://github.com/law-makers/crawl)"
+	return config.DefaultUserAgent
 }
 
 func init() {
@@ -153,7 +224,7 @@ func init() {
 // customHelpFunc provides a colorized help output
 func customHelpFunc(cmd *cobra.Command, args []string) {
 	// Header with command name
-	fmt.Fprintf(os.Stdout, "\n%s%s%s\n", ui.ColorBold+ui.ColorCyan, strings.ToUpper(cmd.Name()), ui.ColorReset)
+	fmt.Fprintf(os.Stdout, "\n%s\n", ui.Bold(ui.Accent(strings.ToUpper(cmd.Name()))))
 
 	// Short description
 	if cmd.Short != "" {
@@ -166,20 +237,18 @@ func customHelpFunc(cmd *cobra.Command, args []string) {
 	}
 
 	// Usage section
-	fmt.Fprintf(os.Stdout, "\n%sUsage%s\n", ui.ColorBold+ui.ColorWhite, ui.ColorReset)
+	fmt.Fprintf(os.Stdout, "\n%s\n", ui.Bold(ui.Value(ui.T("help.usage"))))
 	if cmd.Runnable() {
-		fmt.Fprintf(os.Stdout, "  %s%s%s\n", ui.ColorCyan, cmd.UseLine(), ui.ColorReset)
+		fmt.Fprintf(os.Stdout, "  %s\n", ui.Accent(cmd.UseLine()))
 	}
 	if cmd.HasAvailableSubCommands() {
-		fmt.Fprintf(os.Stdout, "  %s%s%s %s<command>%s %s[flags]%s\n",
-			ui.ColorCyan, cmd.CommandPath(), ui.ColorReset,
-			ui.ColorYellow, ui.ColorReset,
-			ui.ColorDim, ui.ColorReset)
+		fmt.Fprintf(os.Stdout, "  %s %s %s\n",
+			ui.Accent(cmd.CommandPath()), ui.Placeholder("<command>"), ui.Dim("[flags]"))
 	}
 
 	// Examples section
 	if cmd.HasExample() {
-		fmt.Fprintf(os.Stdout, "\n%sExamples%s\n", ui.ColorBold+ui.ColorWhite, ui.ColorReset)
+		fmt.Fprintf(os.Stdout, "\n%s\n", ui.Bold(ui.Value(ui.T("help.examples"))))
 		examples := strings.Split(cmd.Example, "\n")
 		lastWasCommand := false
 		for _, example := range examples {
@@ -193,18 +262,18 @@ func customHelpFunc(cmd *cobra.Command, args []string) {
 					fmt.Fprintln(os.Stdout)
 				}
 				// Comment line
-				fmt.Fprintf(os.Stdout, "  %s%s%s\n", ui.ColorDim, trimmed, ui.ColorReset)
+				fmt.Fprintf(os.Stdout, "  %s\n", ui.Dim(trimmed))
 				lastWasCommand = false
 			} else {
 				// Command line
-				fmt.Fprintf(os.Stdout, "  %s$ %s%s\n", ui.ColorGreen, trimmed, ui.ColorReset)
+				fmt.Fprintf(os.Stdout, "  %s\n", ui.Example("$ "+trimmed))
 			}
 		}
 	}
 
 	// Available commands section
 	if cmd.HasAvailableSubCommands() {
-		fmt.Fprintf(os.Stdout, "\n%sCommands%s\n", ui.ColorBold+ui.ColorWhite, ui.ColorReset)
+		fmt.Fprintf(os.Stdout, "\n%s\n", ui.Bold(ui.Value(ui.T("help.commands"))))
 
 		maxLen := 0
 		availableCommands := []*cobra.Command{}
@@ -219,10 +288,8 @@ func customHelpFunc(cmd *cobra.Command, args []string) {
 
 		for _, c := range availableCommands {
 			padding := strings.Repeat(" ", maxLen-len(c.Name())+2)
-			fmt.Fprintf(os.Stdout, "  %s%s%s%s%s%s%s\n",
-				ui.ColorCyan, c.Name(), ui.ColorReset,
-				padding,
-				ui.ColorDim, c.Short, ui.ColorReset)
+			fmt.Fprintf(os.Stdout, "  %s%s%s\n",
+				ui.Accent(c.Name()), padding, ui.Dim(c.Short))
 		}
 	}
 
@@ -231,42 +298,36 @@ func customHelpFunc(cmd *cobra.Command, args []string) {
 	hasInheritedFlags := cmd.HasAvailableInheritedFlags()
 
 	if hasLocalFlags {
-		fmt.Fprintf(os.Stdout, "\n%sFlags%s\n", ui.ColorBold+ui.ColorWhite, ui.ColorReset)
+		fmt.Fprintf(os.Stdout, "\n%s\n", ui.Bold(ui.Value(ui.T("help.flags"))))
 		printFlags(cmd.LocalFlags().FlagUsages())
 	}
 
 	if hasInheritedFlags {
-		fmt.Fprintf(os.Stdout, "\n%sGlobal Flags%s\n", ui.ColorBold+ui.ColorWhite, ui.ColorReset)
+		fmt.Fprintf(os.Stdout, "\n%s\n", ui.Bold(ui.Value(ui.T("help.global_flags"))))
 		printFlags(cmd.InheritedFlags().FlagUsages())
 	}
 
 	// Footer
 	if cmd.HasAvailableSubCommands() {
-		fmt.Fprintf(os.Stdout, "\n%sUse \"%s%s%s %s<command>%s %s--help%s\" for more information about a command.%s\n",
-			ui.ColorDim,
-			ui.ColorCyan, cmd.CommandPath(), ui.ColorReset+ui.ColorDim,
-			ui.ColorYellow, ui.ColorReset+ui.ColorDim,
-			ui.ColorGreen, ui.ColorReset+ui.ColorDim,
-			ui.ColorReset)
+		fmt.Fprintf(os.Stdout, "\n%s\n", ui.Dim(ui.T("help.footer",
+			ui.Accent(cmd.CommandPath()), ui.Placeholder("<command>"), ui.Example("--help"))))
 	}
 	fmt.Fprintln(os.Stdout)
 }
 
 // customUsageFunc provides a colorized usage output
 func customUsageFunc(cmd *cobra.Command) error {
-	fmt.Fprintf(os.Stderr, "\n%sUsage%s\n", ui.ColorBold+ui.ColorWhite, ui.ColorReset)
+	fmt.Fprintf(os.Stderr, "\n%s\n", ui.Bold(ui.Value(ui.T("help.usage"))))
 	if cmd.Runnable() {
-		fmt.Fprintf(os.Stderr, "  %s%s%s\n", ui.ColorCyan, cmd.UseLine(), ui.ColorReset)
+		fmt.Fprintf(os.Stderr, "  %s\n", ui.Accent(cmd.UseLine()))
 	}
 	if cmd.HasAvailableSubCommands() {
-		fmt.Fprintf(os.Stderr, "  %s%s%s %s<command>%s %s[flags]%s\n",
-			ui.ColorCyan, cmd.CommandPath(), ui.ColorReset,
-			ui.ColorYellow, ui.ColorReset,
-			ui.ColorDim, ui.ColorReset)
+		fmt.Fprintf(os.Stderr, "  %s %s %s\n",
+			ui.Accent(cmd.CommandPath()), ui.Placeholder("<command>"), ui.Dim("[flags]"))
 	}
 
 	if cmd.HasAvailableSubCommands() {
-		fmt.Fprintf(os.Stderr, "\n%sCommands%s\n", ui.ColorBold+ui.ColorWhite, ui.ColorReset)
+		fmt.Fprintf(os.Stderr, "\n%s\n", ui.Bold(ui.Value(ui.T("help.commands"))))
 
 		maxLen := 0
 		availableCommands := []*cobra.Command{}
@@ -281,23 +342,18 @@ func customUsageFunc(cmd *cobra.Command) error {
 
 		for _, c := range availableCommands {
 			padding := strings.Repeat(" ", maxLen-len(c.Name())+2)
-			fmt.Fprintf(os.Stderr, "  %s%s%s%s%s%s%s\n",
-				ui.ColorCyan, c.Name(), ui.ColorReset,
-				padding,
-				ui.ColorDim, c.Short, ui.ColorReset)
+			fmt.Fprintf(os.Stderr, "  %s%s%s\n",
+				ui.Accent(c.Name()), padding, ui.Dim(c.Short))
 		}
 	}
 
 	if cmd.HasAvailableLocalFlags() {
-		fmt.Fprintf(os.Stderr, "\n%sFlags%s\n", ui.ColorBold+ui.ColorWhite, ui.ColorReset)
+		fmt.Fprintf(os.Stderr, "\n%s\n", ui.Bold(ui.Value(ui.T("help.flags"))))
 		printFlagsToStderr(cmd.LocalFlags().FlagUsages())
 	}
 
-	fmt.Fprintf(os.Stderr, "\n%sUse \"%s%s%s %s--help%s\" for more information.%s\n",
-		ui.ColorDim,
-		ui.ColorCyan, cmd.CommandPath(), ui.ColorReset+ui.ColorDim,
-		ui.ColorGreen, ui.ColorReset+ui.ColorDim,
-		ui.ColorReset)
+	fmt.Fprintf(os.Stderr, "\n%s\n", ui.Dim(ui.T("usage.footer",
+		ui.Accent(cmd.CommandPath()), ui.Example("--help"))))
 
 	return nil
 }
@@ -352,19 +408,16 @@ func printFlagsTo(writer *os.File, flagUsages string) {
 
 				padding := strings.Repeat(" ", maxFlagLen-len(flagPart)+2)
 
-				fmt.Fprintf(writer, "  %s%s%s%s%s%s%s\n",
-					ui.ColorGreen, flagPart, ui.ColorReset,
-					padding,
-					ui.ColorDim, descPart, ui.ColorReset)
+				fmt.Fprintf(writer, "  %s%s%s\n",
+					ui.Example(flagPart), padding, ui.Dim(descPart))
 			} else {
-				fmt.Fprintf(writer, "  %s%s%s\n", ui.ColorGreen, trimmed, ui.ColorReset)
+				fmt.Fprintf(writer, "  %s\n", ui.Example(trimmed))
 			}
 		} else {
 			// Continuation line (description continues)
 			indentSpaces := strings.Repeat(" ", maxFlagLen+4)
-			fmt.Fprintf(writer, "%s%s%s%s\n",
-				indentSpaces,
-				ui.ColorDim, trimmed, ui.ColorReset)
+			fmt.Fprintf(writer, "%s%s\n",
+				indentSpaces, ui.Dim(trimmed))
 		}
 	}
 }
@@ -0,0 +1,54 @@
+// internal/cli/sign.go
+package cli
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/law-makers/crawl/internal/attest"
+	"github.com/law-makers/crawl/internal/buildinfo"
+	"github.com/law-makers/crawl/internal/sink"
+	"github.com/law-makers/crawl/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var signKey string
+
+// addSignFlags registers --sign on a command
+func addSignFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&signKey, "sign", "", "Sign the output files with this PEM private key (Ed25519, ECDSA or RSA), writing <output>"+attest.Ext+": an in-toto attestation of each file's hash and each page's URL, fetch time, headers and content hash. Check it with 'crawl verify'")
+}
+
+// checkSign fails early when --sign can't be honoured: there is no output
+// file to sign or the key can't be used
+func checkSign(path string) error {
+	if signKey == "" {
+		return nil
+	}
+	if path == "" || sink.IsURL(path) {
+		return fmt.Errorf("--sign needs an --output file")
+	}
+	_, err := attest.LoadSigner(signKey)
+	return err
+}
+
+// signOutput writes the attestation for files, which hold pages, next to --output
+func signOutput(jobID string, files []string, pages []attest.Page) error {
+	if signKey == "" || len(files) == 0 {
+		return nil
+	}
+	signer, err := attest.LoadSigner(signKey)
+	if err != nil {
+		return err
+	}
+	path := output + attest.Ext
+	env, err := signer.Sign(filepath.Dir(path), files, pages, "crawl "+buildinfo.Get().Version, jobID)
+	if err != nil {
+		return err
+	}
+	if err := env.Write(path); err != nil {
+		return err
+	}
+	ui.Printf("%s\n", ui.Success(ui.Mark(ui.IconSuccess, ui.T("sign.saved", len(files), ui.Bold(terminalHyperlink(path, path))))))
+	return nil
+}
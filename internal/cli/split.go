@@ -0,0 +1,34 @@
+// internal/cli/split.go
+package cli
+
+import (
+	outpututil "github.com/law-makers/crawl/internal/utils/output"
+	"github.com/spf13/cobra"
+)
+
+var (
+	outputSplit     string
+	outputPartition string
+)
+
+// addSplitFlags registers the flags that rotate and partition file output
+// for commands that write many pages
+func addSplitFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&outputSplit, "output-split", "", "Rotate the output file at a size (100MB) or a number of pages (10000)")
+	cmd.Flags().StringVar(&outputPartition, "output-partition", "", "Write files into per-domain or per-day directories: domain or day")
+}
+
+// splitOptions builds the rotation options from the split flags
+func splitOptions() (outpututil.SplitOptions, error) {
+	opts, err := outpututil.ParseSplit(outputSplit)
+	if err != nil {
+		return opts, err
+	}
+	opts.Partition = outputPartition
+	return opts, nil
+}
+
+// splitting reports whether any split flag was given
+func splitting() bool {
+	return outputSplit != "" || outputPartition != ""
+}
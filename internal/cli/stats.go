@@ -0,0 +1,61 @@
+// internal/cli/stats.go
+package cli
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/law-makers/crawl/internal/stats"
+	"github.com/law-makers/crawl/internal/ui"
+	"github.com/law-makers/crawl/pkg/models"
+)
+
+// cacheCounters is implemented by caches that track hit/miss counts
+type cacheCounters interface {
+	Counters() (hits, misses uint64)
+}
+
+// pageWaited is how long a page was held back by rate limits and queues
+func pageWaited(page *models.PageData) time.Duration {
+	if page == nil || page.Timings == nil {
+		return 0
+	}
+	return time.Duration(page.Timings.Waited * float64(time.Millisecond))
+}
+
+// printStats prints the per-status, per-engine and politeness breakdown of a run
+func printStats(s stats.Summary) {
+	ui.Printf("\n%s\n", ui.Bold(ui.T("stats.title")))
+	ui.Printf("  %s %s\n", ui.Bold(ui.T("stats.requests")), ui.Value(ui.T("stats.requests_value", s.Requests, (time.Duration(s.DurationMs)*time.Millisecond).String())))
+
+	statuses := make([]string, 0, len(s.ByStatus))
+	for status := range s.ByStatus {
+		statuses = append(statuses, status)
+	}
+	sort.Strings(statuses)
+	parts := make([]string, 0, len(statuses))
+	for _, status := range statuses {
+		parts = append(parts, fmt.Sprintf("%s×%d", status, s.ByStatus[status]))
+	}
+	if len(parts) > 0 {
+		ui.Printf("  %s %s\n", ui.Bold(ui.T("stats.by_status")), ui.Value(strings.Join(parts, "  ")))
+	}
+
+	engines := make([]string, 0, len(s.Engines))
+	for name := range s.Engines {
+		engines = append(engines, name)
+	}
+	sort.Strings(engines)
+	for _, name := range engines {
+		e := s.Engines[name]
+		ui.Printf("  %s %s\n", ui.Bold(name+":"), ui.Value(ui.T("stats.engine_value", e.Requests, formatBytes(e.Bytes), e.TimeMs)))
+	}
+
+	ui.Printf("  %s %s\n", ui.Bold(ui.T("stats.retries")), ui.Value(fmt.Sprintf("%d", s.Retries)))
+	ui.Printf("  %s %s\n", ui.Bold(ui.T("stats.throttle")), ui.Value(ui.T("stats.throttle_value", s.ThrottleWaits, s.ThrottleWaitMs)))
+	if s.CacheHits+s.CacheMisses > 0 {
+		ui.Printf("  %s %s\n", ui.Bold(ui.T("stats.cache")), ui.Value(ui.T("stats.cache_value", s.CacheHits, s.CacheMisses)))
+	}
+}
@@ -0,0 +1,203 @@
+// internal/cli/status.go
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/law-makers/crawl/internal/app"
+	"github.com/law-makers/crawl/internal/engine"
+	"github.com/law-makers/crawl/internal/engine/status"
+	"github.com/law-makers/crawl/internal/engine/tdm"
+	proxypool "github.com/law-makers/crawl/internal/proxy"
+	"github.com/law-makers/crawl/internal/reqctx"
+	"github.com/law-makers/crawl/internal/retry"
+	"github.com/law-makers/crawl/internal/ui"
+	headersutil "github.com/law-makers/crawl/internal/utils/headers"
+	"github.com/law-makers/crawl/pkg/models"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+// defaultLadder is used by a bare --escalate
+const defaultLadder = "headers,proxy,spa,stealth"
+
+var (
+	okStatus       string
+	retrySuspected []string
+	retryProxies   []string
+	escalate       []string
+)
+
+// addStatusFlags registers the HTTP status allow-list flags
+func addStatusFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&okStatus, "ok-status", "", "Statuses that count as success, e.g. 200,203,301, 2xx or 200-299. Other statuses fail the page (429 and 5xx after retries), as do 2xx pages that read as \"not found\" (soft 404)")
+	cmd.Flags().IntVar(&retries, "retries", 2, "Retries for 429 and 5xx responses rejected by --ok-status")
+	cmd.Flags().StringSliceVar(&retrySuspected, "retry-suspected", nil, "Fetch pages that look like error or block pages again, trying each in order: spa (the browser), proxy (the next --retry-proxies entry)")
+	cmd.Flags().StringSliceVar(&retryProxies, "retry-proxies", nil, "Proxies used in turn by --retry-suspected proxy and --escalate")
+	cmd.Flags().StringSliceVar(&escalate, "escalate", nil, "When a response is blocked (403, Cloudflare challenge, CAPTCHA), retry up this ladder until one gets through: headers (browser-like headers), proxy (next --retry-proxies entry), spa (the browser), stealth (the browser, disguised). A bare --escalate uses "+defaultLadder+"; pick rungs with --escalate=headers,spa")
+	cmd.Flags().Lookup("escalate").NoOptDefVal = defaultLadder
+}
+
+// wrapScraper applies --prefer-print, block escalation, error-page
+// detection, the --ok-status allow-list and --fail-on-js-error to scraper,
+// innermost first
+func wrapScraper(appCtx *app.Application, scraper engine.Scraper, scraperMode models.ScraperMode) (engine.Scraper, error) {
+	// Innermost, so requests changed by escalation are checked too
+	scraper = appCtx.Guard(scraper)
+	scraper = withPrintPreference(scraper)
+	browser := browserRefetch(appCtx)
+	var pool *proxypool.ProxyPool
+	if len(retryProxies) > 0 {
+		pool = proxypool.NewProxyPool(retryProxies)
+	}
+
+	ladder, err := escalationLadder(browser, pool, scraperMode)
+	if err != nil {
+		return nil, err
+	}
+	if len(ladder) > 0 {
+		scraper = status.NewEscalator(scraper, ladder...)
+	}
+	if scraper, err = withDetector(scraper, browser, pool); err != nil {
+		return nil, err
+	}
+	if scraper, err = withStatusCheck(scraper); err != nil {
+		return nil, err
+	}
+	if scraper, err = withJSErrorCheck(scraper, scraperMode); err != nil {
+		return nil, err
+	}
+	scraper = withNoAI(appCtx, scraper)
+	// Outermost, so the retries and refetches of a URL share its request ID
+	return reqctx.Stamp(scraper, appCtx.Config.JobID), nil
+}
+
+// escalationLadder builds the rungs named by --escalate. proxy is skipped
+// without --retry-proxies, and spa when pages are already fetched with the browser.
+func escalationLadder(browser fetchFunc, pool *proxypool.ProxyPool, scraperMode models.ScraperMode) ([]status.Rung, error) {
+	var ladder []status.Rung
+	for _, name := range escalate {
+		name = strings.ToLower(strings.TrimSpace(name))
+		switch name {
+		case "headers":
+			ladder = append(ladder, status.Rung{Name: name, Apply: func(opts *models.RequestOptions) {
+				opts.Headers = headersutil.Browser(opts.Headers)
+			}})
+		case "proxy":
+			if pool == nil {
+				log.Debug().Msg("Skipping proxy escalation: no --retry-proxies")
+				continue
+			}
+			ladder = append(ladder, status.Rung{Name: name, Apply: func(opts *models.RequestOptions) {
+				opts.Proxy = pool.GetNext()
+			}})
+		case "spa":
+			if scraperMode == models.ModeSPA {
+				continue
+			}
+			ladder = append(ladder, status.Rung{Name: name, Fetch: browser})
+		case "stealth":
+			ladder = append(ladder, status.Rung{Name: name, Fetch: browser, Apply: func(opts *models.RequestOptions) {
+				opts.Stealth = true
+			}})
+		default:
+			return nil, fmt.Errorf("invalid --escalate rung %q (must be headers, proxy, spa or stealth)", name)
+		}
+	}
+	return ladder, nil
+}
+
+// fetchFunc fetches a page one particular way
+type fetchFunc func(opts models.RequestOptions) (*models.PageData, error)
+
+// withDetector wraps scraper so pages that look like error pages are
+// flagged in suspected_error and, with --retry-suspected, fetched again
+func withDetector(scraper engine.Scraper, browser fetchFunc, pool *proxypool.ProxyPool) (engine.Scraper, error) {
+	var refetches []status.Refetch
+	for _, way := range retrySuspected {
+		switch strings.ToLower(strings.TrimSpace(way)) {
+		case "spa":
+			refetches = append(refetches, status.Refetch{Name: "spa", Fetch: browser})
+		case "proxy":
+			if pool == nil {
+				return nil, fmt.Errorf("--retry-suspected proxy needs --retry-proxies")
+			}
+			refetches = append(refetches, status.Refetch{Name: "proxy", Fetch: proxyRefetch(scraper, pool)})
+		default:
+			return nil, fmt.Errorf("invalid --retry-suspected %q (must be spa or proxy)", way)
+		}
+	}
+	return status.NewDetector(scraper, refetches...), nil
+}
+
+// browserRefetch fetches with the dynamic scraper, starting the browser on
+// first use so runs that never need it don't pay for it
+func browserRefetch(appCtx *app.Application) fetchFunc {
+	var once sync.Once
+	var dynamic engine.Scraper
+	var startErr error
+	return func(opts models.RequestOptions) (*models.PageData, error) {
+		once.Do(func() {
+			dynamic, startErr = scraperForMode(appCtx, models.ModeSPA)
+			if startErr == nil {
+				dynamic = appCtx.Guard(dynamic)
+			}
+		})
+		if startErr != nil {
+			return nil, startErr
+		}
+		opts.Mode = models.ModeSPA
+		return dynamic.Fetch(opts)
+	}
+}
+
+// proxyRefetch fetches through the next healthy proxy in pool
+func proxyRefetch(scraper engine.Scraper, pool *proxypool.ProxyPool) fetchFunc {
+	return func(opts models.RequestOptions) (*models.PageData, error) {
+		opts.Proxy = pool.GetNext()
+		data, err := scraper.Fetch(opts)
+		if err != nil {
+			pool.MarkFailed(opts.Proxy)
+			return nil, err
+		}
+		return data, nil
+	}
+}
+
+// withStatusCheck wraps scraper with the --ok-status allow-list, or returns
+// it unchanged when no list is given
+func withStatusCheck(scraper engine.Scraper) (engine.Scraper, error) {
+	allow, err := status.ParseAllow(okStatus)
+	if err != nil {
+		return nil, err
+	}
+	if allow.IsZero() {
+		return scraper, nil
+	}
+	if retries < 0 {
+		return nil, fmt.Errorf("invalid --retries %d (must be 0 or more)", retries)
+	}
+	cfg := retry.DefaultConfig()
+	cfg.MaxAttempts = retries + 1
+	return status.New(scraper, allow, cfg), nil
+}
+
+// reportFailure prints a failed URL to stderr, marking soft 404s so they
+// stand out from fetch errors
+func reportFailure(url string, err error) {
+	var soft *status.SoftNotFoundError
+	if errors.As(err, &soft) {
+		fmt.Fprintf(os.Stderr, "%s %s: %s\n", ui.Warning(ui.Mark(ui.IconWarning, ui.T("status.soft404"))), url, soft.Reason)
+		return
+	}
+	var reserved *tdm.ReservedError
+	if errors.As(err, &reserved) {
+		fmt.Fprintf(os.Stderr, "%s %s: %s\n", ui.Warning(ui.Mark(ui.IconWarning, ui.T("noai.skipped"))), url, strings.Join(reserved.Signals, "; "))
+		return
+	}
+	fmt.Fprintf(os.Stderr, "%s: %v\n", ui.Error(ui.Mark(ui.IconFailure, url)), err)
+}
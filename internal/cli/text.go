@@ -0,0 +1,63 @@
+// internal/cli/text.go
+package cli
+
+import (
+	"strings"
+
+	outpututil "github.com/law-makers/crawl/internal/utils/output"
+	"github.com/law-makers/crawl/pkg/models"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+var (
+	textCollapse   bool
+	textParagraphs bool
+	textLinks      bool
+	textBullet     string
+	textTableSep   string
+)
+
+// addTextFlags registers the flags that control plain-text rendering
+func addTextFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&textCollapse, "text-collapse", false, "Text: collapse runs of whitespace into single spaces")
+	cmd.Flags().BoolVar(&textParagraphs, "text-paragraphs", false, "Text: keep a blank line between paragraphs, headings, lists and tables")
+	cmd.Flags().BoolVar(&textLinks, "text-links", false, "Text: follow link text with its URL in parentheses")
+	cmd.Flags().StringVar(&textBullet, "text-bullet", "", "Text: prefix list items with this (e.g. \"- \"); ordered lists are numbered")
+	cmd.Flags().StringVar(&textTableSep, "text-table-sep", "", "Text: put table rows on one line with cells joined by this (default \" | \")")
+}
+
+// textRendering reports whether any text flag was given; without them text
+// output is the extracted content unchanged
+func textRendering() bool {
+	return textCollapse || textParagraphs || textLinks || textBullet != "" || textTableSep != ""
+}
+
+// pageText returns the page's text, rendered from its HTML according to the
+// text flags when any are set
+func pageText(data *models.PageData) string {
+	if !textRendering() {
+		return data.Content
+	}
+	if strings.TrimSpace(data.HTML) == "" {
+		// --no-html: only the extracted text is left to tidy
+		if textCollapse {
+			return strings.Join(strings.Fields(data.Content), " ")
+		}
+		return data.Content
+	}
+
+	text, err := outpututil.RenderText(data.HTML, outpututil.TextOptions{
+		CollapseWhitespace: textCollapse,
+		Paragraphs:         textParagraphs,
+		LinkURLs:           textLinks,
+		Bullet:             textBullet,
+		TableSeparator:     strings.ReplaceAll(textTableSep, `\t`, "\t"),
+		BaseURL:            data.URL,
+	})
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to render text; using the extracted content")
+		return data.Content
+	}
+	return text
+}
@@ -0,0 +1,66 @@
+// internal/cli/verify.go
+package cli
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/law-makers/crawl/internal/attest"
+	"github.com/law-makers/crawl/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var verifyKey string
+
+// verifyCmd checks an attestation written by --sign
+var verifyCmd = &cobra.Command{
+	Use:   "verify <attestation>",
+	Short: "Check that signed output files are untampered",
+	Long: `Checks an attestation written by 'crawl get --sign' or 'crawl batch --sign':
+that it was signed by the private key matching --key, and that every output
+file it names still has the hash that was signed. The files are looked up
+relative to the attestation.
+
+The attestation is an in-toto statement in a DSSE envelope, so other in-toto
+tooling can check it too.`,
+	Example: `  # Sign a capture, then check it later with the public key
+  crawl get https://example.com/terms --output terms.json --sign key.pem
+  crawl verify terms.json` + attest.Ext + ` --key key.pub`,
+	Args: cobra.ExactArgs(1),
+	RunE: runVerify,
+}
+
+func init() {
+	rootCmd.AddCommand(verifyCmd)
+	verifyCmd.Flags().StringVar(&verifyKey, "key", "", "PEM public key the attestation must be signed with")
+	verifyCmd.MarkFlagRequired("key")
+}
+
+func runVerify(cmd *cobra.Command, args []string) error {
+	pub, err := attest.LoadPublicKey(verifyKey)
+	if err != nil {
+		return err
+	}
+	env, err := attest.ReadEnvelope(args[0])
+	if err != nil {
+		return err
+	}
+	cmd.SilenceUsage = true
+	st, err := attest.Verify(env, pub)
+	if err != nil {
+		return fmt.Errorf("%s: %w", args[0], err)
+	}
+	if err := attest.CheckSubjects(st, filepath.Dir(args[0])); err != nil {
+		return err
+	}
+
+	if jsonOutput {
+		return writeVersionJSON(st)
+	}
+	fmt.Println(ui.Success(ui.Mark(ui.IconSuccess, ui.T("verify.ok", len(st.Subject), len(st.Predicate.Pages)))))
+	for _, s := range st.Subject {
+		fmt.Printf("  %s  %s\n", ui.Value(s.Name), ui.Dim(s.Digest["sha256"]))
+	}
+	fmt.Printf("  %s\n", ui.Dim(ui.T("verify.signed", st.Predicate.SignedAt.Format("2006-01-02 15:04:05 MST"), st.Predicate.Tool)))
+	return nil
+}
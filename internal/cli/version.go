@@ -0,0 +1,223 @@
+// internal/cli/version.go
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/law-makers/crawl/internal/browser"
+	"github.com/law-makers/crawl/internal/buildinfo"
+	"github.com/law-makers/crawl/internal/engine/dynamic"
+	"github.com/law-makers/crawl/internal/paths"
+	"github.com/law-makers/crawl/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var versionDetailed bool
+
+// versionCmd prints build information, and with --detailed the environment
+// details asked for in bug reports
+var versionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "Print version, build and environment information",
+	Long: `Prints the crawl version. With --detailed it also reports the commit and
+build date, Go version, OS/architecture, the Chrome that SPA mode would use,
+and which optional features are available. Please include the --detailed
+output when reporting a bug.`,
+	Example: `  # Short version
+  crawl version
+
+  # Everything a bug report needs
+  crawl version --detailed
+
+  # Machine-readable
+  crawl version --detailed --json`,
+	Args: cobra.NoArgs,
+	RunE: runVersion,
+}
+
+func init() {
+	rootCmd.AddCommand(versionCmd)
+	versionCmd.Flags().BoolVar(&versionDetailed, "detailed", false, "Include build, Chrome and feature details")
+}
+
+// versionReport is the --detailed output
+type versionReport struct {
+	buildinfo.Info
+	Chrome      *chromeReport `json:"chrome,omitempty"`
+	Features    []feature     `json:"features,omitempty"`
+	ConfigFile  string        `json:"config_file,omitempty"`
+	ConfigDir   string        `json:"config_dir,omitempty"`
+	CacheDir    string        `json:"cache_dir,omitempty"`
+	BrowsersDir string        `json:"browsers_dir,omitempty"`
+}
+
+// chromeReport describes the browser SPA mode would use
+type chromeReport struct {
+	Path    string `json:"path,omitempty"`
+	Version string `json:"version,omitempty"`
+	Remote  string `json:"remote,omitempty"` // CDP endpoint used instead of a local browser
+}
+
+// feature is an optional capability and whether this install has it
+type feature struct {
+	Name    string `json:"name"`
+	Enabled bool   `json:"enabled"`
+	Detail  string `json:"detail,omitempty"`
+}
+
+func runVersion(cmd *cobra.Command, args []string) error {
+	info := buildinfo.Get()
+	if !versionDetailed {
+		if jsonOutput {
+			return writeVersionJSON(info)
+		}
+		fmt.Println(shortVersion(info))
+		return nil
+	}
+
+	report := versionReport{Info: info}
+	var chromePath, remote string
+	if appCtx := GetAppFromCmd(cmd); appCtx != nil {
+		chromePath = appCtx.Config.ChromePath
+		remote = appCtx.Config.BrowserRemoteURL
+		report.ConfigFile = appCtx.Config.File
+	}
+	report.Chrome = detectChrome(chromePath, remote)
+	report.Features = detectFeatures(report.Chrome)
+	report.ConfigDir, _ = paths.ConfigDir()
+	report.CacheDir, _ = paths.CacheDir()
+	report.BrowsersDir, _ = browser.Dir()
+
+	if jsonOutput {
+		return writeVersionJSON(report)
+	}
+	printVersionReport(report)
+	return nil
+}
+
+// shortVersion is the one-line form, e.g. "crawl 0.1.0 (3f2a9c1d0b7e, 2026-01-02T03:04:05Z)"
+func shortVersion(info buildinfo.Info) string {
+	var details []string
+	if c := info.ShortCommit(); c != "" {
+		if info.Modified {
+			c += "+dirty"
+		}
+		details = append(details, c)
+	}
+	if info.Date != "" {
+		details = append(details, info.Date)
+	}
+	if len(details) == 0 {
+		return "crawl " + info.Version
+	}
+	return fmt.Sprintf("crawl %s (%s)", info.Version, strings.Join(details, ", "))
+}
+
+// detectChrome finds the browser SPA mode would launch. A configured CDP
+// endpoint takes its place, so no local browser is probed then.
+func detectChrome(configured, remote string) *chromeReport {
+	if remote != "" {
+		return &chromeReport{Remote: remote}
+	}
+	path := configured
+	if path == "" {
+		path = dynamic.FindChrome()
+	}
+	if path == "" {
+		return &chromeReport{}
+	}
+	return &chromeReport{Path: path, Version: dynamic.GetChromeVersion(path)}
+}
+
+func chromeDetail(c *chromeReport) string {
+	switch {
+	case c.Remote != "":
+		return "remote " + c.Remote
+	case c.Path == "":
+		return "not found; run 'crawl browser install'"
+	case c.Version != "" && c.Version != "detected":
+		return c.Version
+	}
+	return c.Path
+}
+
+// detectFeatures reports the optional capabilities and whether they are usable
+func detectFeatures(chrome *chromeReport) []feature {
+	ffmpeg := feature{Name: "ffmpeg", Detail: "not found in PATH"}
+	if path, err := exec.LookPath("ffmpeg"); err == nil {
+		ffmpeg.Enabled, ffmpeg.Detail = true, path
+	}
+	return []feature{
+		{Name: "chrome", Enabled: chrome.Path != "" || chrome.Remote != "", Detail: chromeDetail(chrome)},
+		ffmpeg,
+		// Credentials are only read from flags, env and config files
+		{Name: "keyring", Enabled: false, Detail: "not included in this build"},
+	}
+}
+
+func writeVersionJSON(v interface{}) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+// printVersionReport prints the --detailed report as aligned label/value rows
+func printVersionReport(r versionReport) {
+	commit := r.Commit
+	if commit == "" {
+		commit = "unknown"
+	} else if r.Modified {
+		commit += " " + ui.T("version.modified")
+	}
+	date := r.Date
+	if date == "" {
+		date = "unknown"
+	}
+	chrome := chromeDetail(r.Chrome)
+	if r.Chrome.Path != "" {
+		chrome = r.Chrome.Path
+		if r.Chrome.Version != "" && r.Chrome.Version != "detected" {
+			chrome += " (" + r.Chrome.Version + ")"
+		}
+	}
+	configFile := r.ConfigFile
+	if configFile == "" {
+		configFile = ui.T("version.none")
+	}
+
+	rows := [][2]string{
+		{ui.T("version.version"), r.Version},
+		{ui.T("version.commit"), commit},
+		{ui.T("version.built"), date},
+		{ui.T("version.go"), r.GoVersion},
+		{ui.T("version.platform"), r.OS + "/" + r.Arch},
+		{ui.T("version.chrome"), chrome},
+		{ui.T("version.config_file"), configFile},
+		{ui.T("version.config_dir"), r.ConfigDir},
+		{ui.T("version.cache_dir"), r.CacheDir},
+		{ui.T("version.browsers_dir"), r.BrowsersDir},
+	}
+	width := 0
+	for _, row := range rows {
+		if n := len([]rune(row[0])); n > width {
+			width = n
+		}
+	}
+	for _, row := range rows {
+		pad := strings.Repeat(" ", width-len([]rune(row[0])))
+		fmt.Printf("%s%s  %s\n", ui.Bold(row[0]), pad, ui.Value(row[1]))
+	}
+
+	fmt.Printf("\n%s\n", ui.Bold(ui.T("version.features")))
+	for _, f := range r.Features {
+		state := ui.Success(fmt.Sprintf("%-3s", ui.T("version.enabled")))
+		if !f.Enabled {
+			state = ui.Dim(fmt.Sprintf("%-3s", ui.T("version.disabled")))
+		}
+		fmt.Printf("  %-8s %s  %s\n", f.Name, state, ui.Dim(f.Detail))
+	}
+}
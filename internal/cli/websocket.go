@@ -0,0 +1,49 @@
+// internal/cli/websocket.go
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	outpututil "github.com/law-makers/crawl/internal/utils/output"
+	"github.com/law-makers/crawl/pkg/models"
+	"github.com/spf13/cobra"
+)
+
+var (
+	captureWS     bool
+	wsMaxMessages int
+	wsMaxSize     string
+	wsListen      time.Duration
+)
+
+// addWSFlags registers --capture-ws and its limits on a command
+func addWSFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&captureWS, "capture-ws", false, "SPA mode: record the WebSocket messages the page sends and receives (in \"websocket\")")
+	cmd.Flags().IntVar(&wsMaxMessages, "ws-max-messages", 1000, "Most WebSocket messages to keep per page (0 for no limit)")
+	cmd.Flags().StringVar(&wsMaxSize, "ws-max-size", "64KB", "Cut each captured WebSocket message to this size (0 for no limit)")
+	cmd.Flags().DurationVar(&wsListen, "ws-listen", 2*time.Second, "Keep listening for WebSocket messages this long after the page is ready")
+}
+
+// wsCapture returns the capture limits for --capture-ws, or nil without it.
+// Only the browser sees WebSocket traffic, so it needs SPA mode.
+func wsCapture(scraperMode models.ScraperMode) (*models.WSCapture, error) {
+	if !captureWS {
+		return nil, nil
+	}
+	if scraperMode != models.ModeSPA {
+		return nil, fmt.Errorf("--capture-ws requires --mode=spa")
+	}
+	if wsMaxMessages < 0 || wsListen < 0 {
+		return nil, fmt.Errorf("--ws-max-messages and --ws-listen must be >= 0")
+	}
+	capture := &models.WSCapture{MaxMessages: wsMaxMessages, Listen: wsListen}
+	if wsMaxSize != "0" {
+		n, err := outpututil.ParseSize(wsMaxSize)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --ws-max-size: %w", err)
+		}
+		capture.MaxMessageSize = int(n)
+	}
+	return capture, nil
+}
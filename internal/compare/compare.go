@@ -0,0 +1,240 @@
+// internal/compare/compare.go
+//
+// Package compare fetches one URL under several request profiles (device
+// user agents, proxies in different regions, languages) and lines up what
+// each got back field by field, for A/B, geo and device testing.
+package compare
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	headersutil "github.com/law-makers/crawl/internal/utils/headers"
+	"github.com/law-makers/crawl/pkg/models"
+)
+
+// UserAgents are the devices a ua: variant can name
+var UserAgents = map[string]string{
+	"desktop": headersutil.BrowserUserAgent,
+	"mac":     "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/129.0.0.0 Safari/537.36",
+	"iphone":  "Mozilla/5.0 (iPhone; CPU iPhone OS 17_6 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.6 Mobile/15E148 Safari/604.1",
+	"ipad":    "Mozilla/5.0 (iPad; CPU OS 17_6 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.6 Mobile/15E148 Safari/604.1",
+	"android": "Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/129.0.0.0 Mobile Safari/537.36",
+}
+
+// Variant is one request profile to fetch the URL with
+type Variant struct {
+	Name    string            // as written in the spec, e.g. "ua:iphone+proxy:de"
+	Headers map[string]string // sent on top of the common headers
+	Proxy   string
+}
+
+// ParseVariants parses a comma-separated variant list. Each variant is one
+// or more of ua:<device>, proxy:<name or URL>, lang:<Accept-Language> and
+// header:<Name>=<value>, joined with "+". Proxy names are looked up in
+// proxies (the config file's proxies block).
+func ParseVariants(spec string, proxies map[string]string) ([]Variant, error) {
+	var variants []Variant
+	seen := map[string]bool{}
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		if seen[item] {
+			return nil, fmt.Errorf("variant %q is listed twice", item)
+		}
+		seen[item] = true
+
+		v := Variant{Name: item, Headers: map[string]string{}}
+		for _, part := range strings.Split(item, "+") {
+			kind, value, ok := strings.Cut(strings.TrimSpace(part), ":")
+			value = strings.TrimSpace(value)
+			if !ok || value == "" {
+				return nil, fmt.Errorf("invalid variant %q: expected kind:value, e.g. ua:iphone", part)
+			}
+			switch strings.ToLower(kind) {
+			case "ua":
+				ua, ok := UserAgents[strings.ToLower(value)]
+				if !ok {
+					return nil, fmt.Errorf("unknown device %q in variant %q (known: %s)", value, item, strings.Join(sortedKeys(UserAgents), ", "))
+				}
+				v.Headers["User-Agent"] = ua
+			case "proxy":
+				if strings.Contains(value, "://") {
+					v.Proxy = value
+				} else if p, ok := proxies[value]; ok {
+					v.Proxy = p
+				} else {
+					return nil, fmt.Errorf("unknown proxy %q in variant %q: name one from the config file's proxies block or give its URL", value, item)
+				}
+			case "lang":
+				v.Headers["Accept-Language"] = value
+			case "header":
+				name, hv, ok := strings.Cut(value, "=")
+				if !ok || strings.TrimSpace(name) == "" {
+					return nil, fmt.Errorf("invalid header in variant %q: expected header:Name=value", item)
+				}
+				v.Headers[strings.TrimSpace(name)] = strings.TrimSpace(hv)
+			default:
+				return nil, fmt.Errorf("unknown variant kind %q in %q (use ua, proxy, lang or header)", kind, item)
+			}
+		}
+		variants = append(variants, v)
+	}
+	if len(variants) < 2 {
+		return nil, fmt.Errorf("need at least two variants to compare")
+	}
+	return variants, nil
+}
+
+// Result is what one variant got back
+type Result struct {
+	Variant Variant
+	Page    *models.PageData
+	Err     error
+}
+
+// Field is one compared field with each variant's value, in variant order.
+// Differs is set when the variants that succeeded didn't all agree.
+type Field struct {
+	Name    string   `json:"field"`
+	Values  []string `json:"values"`
+	Differs bool     `json:"differs"`
+}
+
+// VariantSummary describes one variant's fetch in a Report
+type VariantSummary struct {
+	Name       string `json:"name"`
+	Proxy      string `json:"proxy,omitempty"`
+	StatusCode int    `json:"status_code,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// Report is the field-level comparison of the variants of one URL
+type Report struct {
+	URL      string           `json:"url"`
+	Variants []VariantSummary `json:"variants"`
+	Fields   []Field          `json:"fields"`
+}
+
+// Differing returns the fields whose values differ between variants
+func (r Report) Differing() []Field {
+	var out []Field
+	for _, f := range r.Fields {
+		if f.Differs {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// Compare builds the report for results, which are in variant order
+func Compare(url string, results []Result) Report {
+	report := Report{URL: url}
+	values := make([]map[string]string, len(results))
+	var names []string
+	known := map[string]bool{}
+	for i, res := range results {
+		s := VariantSummary{Name: res.Variant.Name, Proxy: res.Variant.Proxy}
+		if res.Err != nil || res.Page == nil {
+			if res.Err != nil {
+				s.Error = res.Err.Error()
+			}
+		} else {
+			s.StatusCode = res.Page.StatusCode
+			fields := pageFields(res.Page)
+			values[i] = map[string]string{}
+			for _, f := range fields {
+				values[i][f[0]] = f[1]
+				if !known[f[0]] {
+					known[f[0]] = true
+					names = append(names, f[0])
+				}
+			}
+		}
+		report.Variants = append(report.Variants, s)
+	}
+
+	for _, name := range names {
+		f := Field{Name: name, Values: make([]string, len(results))}
+		first, set := "", false
+		for i, vals := range values {
+			if vals == nil {
+				continue // failed variants don't count as a difference
+			}
+			f.Values[i] = vals[name]
+			if !set {
+				first, set = vals[name], true
+			} else if vals[name] != first {
+				f.Differs = true
+			}
+		}
+		report.Fields = append(report.Fields, f)
+	}
+	return report
+}
+
+// comparedHeaders are the response headers that tell variants apart
+var comparedHeaders = []string{"Content-Language", "Content-Type", "Vary", "Location"}
+
+// pageFields flattens a page into name/value pairs in a stable order:
+// the fetch outcome first, then content, extracted fields, metadata and
+// headers
+func pageFields(p *models.PageData) [][2]string {
+	fields := [][2]string{
+		{"status_code", strconv.Itoa(p.StatusCode)},
+		{"url", p.URL},
+		{"title", p.Title},
+		{"content", strings.Join(strings.Fields(p.Content), " ")},
+		{"links", strconv.Itoa(len(p.Links))},
+		{"images", strconv.Itoa(len(p.Images))},
+	}
+	if p.SuspectedError != "" {
+		fields = append(fields, [2]string{"suspected_error", p.SuspectedError})
+	}
+
+	// Structured rows: one field per column, rows joined in page order
+	var columns []string
+	seen := map[string]bool{}
+	for _, row := range p.Structured {
+		for _, col := range sortedKeys(row) {
+			if !seen[col] {
+				seen[col] = true
+				columns = append(columns, col)
+			}
+		}
+	}
+	for _, col := range columns {
+		vals := make([]string, len(p.Structured))
+		for i, row := range p.Structured {
+			vals[i] = row[col]
+		}
+		fields = append(fields, [2]string{"field." + col, strings.Join(vals, " | ")})
+	}
+
+	for _, key := range sortedKeys(p.Metadata) {
+		fields = append(fields, [2]string{"meta." + key, p.Metadata[key]})
+	}
+	headers := map[string]string{}
+	for k, v := range p.Headers {
+		headers[strings.ToLower(k)] = v
+	}
+	for _, name := range comparedHeaders {
+		if v, ok := headers[strings.ToLower(name)]; ok {
+			fields = append(fields, [2]string{"header." + name, v})
+		}
+	}
+	return fields
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
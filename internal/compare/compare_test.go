@@ -0,0 +1,92 @@
+package compare
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/law-makers/crawl/pkg/models"
+)
+
+func TestParseVariants(t *testing.T) {
+	proxies := map[string]string{"us": "http://us.example:8080"}
+	variants, err := ParseVariants("ua:iphone, proxy:us, ua:desktop+lang:de-DE, proxy:socks5://127.0.0.1:1080+header:X-Test=1", proxies)
+	if err != nil {
+		t.Fatalf("ParseVariants failed: %v", err)
+	}
+	if len(variants) != 4 {
+		t.Fatalf("Expected 4 variants, got %d", len(variants))
+	}
+	if variants[0].Name != "ua:iphone" || variants[0].Headers["User-Agent"] != UserAgents["iphone"] {
+		t.Errorf("Unexpected first variant: %+v", variants[0])
+	}
+	if variants[1].Proxy != "http://us.example:8080" {
+		t.Errorf("Expected the named proxy, got %q", variants[1].Proxy)
+	}
+	if variants[2].Headers["Accept-Language"] != "de-DE" || variants[2].Headers["User-Agent"] != UserAgents["desktop"] {
+		t.Errorf("Expected user agent and language together, got %+v", variants[2].Headers)
+	}
+	if variants[3].Proxy != "socks5://127.0.0.1:1080" || variants[3].Headers["X-Test"] != "1" {
+		t.Errorf("Expected a proxy URL and a header, got %+v", variants[3])
+	}
+}
+
+func TestParseVariants_Invalid(t *testing.T) {
+	for _, spec := range []string{
+		"ua:iphone",
+		"ua:iphone,ua:iphone",
+		"ua:toaster,ua:desktop",
+		"proxy:mars,ua:desktop",
+		"ua:iphone,color:blue",
+		"ua:iphone,header:novalue",
+		"ua:iphone,lang:",
+	} {
+		if _, err := ParseVariants(spec, nil); err == nil {
+			t.Errorf("Expected an error for %q", spec)
+		}
+	}
+}
+
+func TestCompare(t *testing.T) {
+	results := []Result{
+		{Variant: Variant{Name: "ua:iphone"}, Page: &models.PageData{
+			StatusCode: 200, Title: "Shop", Content: "Price 9.99",
+			Structured: []map[string]string{{"price": "9.99"}},
+			Headers:    map[string]string{"Content-Language": "en"},
+		}},
+		{Variant: Variant{Name: "ua:desktop"}, Page: &models.PageData{
+			StatusCode: 200, Title: "Shop", Content: "Price  12.99",
+			Structured: []map[string]string{{"price": "12.99"}},
+			Headers:    map[string]string{"content-language": "en"},
+		}},
+		{Variant: Variant{Name: "proxy:de"}, Err: errors.New("proxy refused")},
+	}
+	report := Compare("https://example.com", results)
+
+	if report.Variants[2].Error != "proxy refused" || report.Variants[0].StatusCode != 200 {
+		t.Errorf("Unexpected variant summaries: %+v", report.Variants)
+	}
+	differs := map[string]bool{}
+	for _, f := range report.Fields {
+		differs[f.Name] = f.Differs
+		if len(f.Values) != 3 {
+			t.Errorf("Expected a value per variant for %s, got %v", f.Name, f.Values)
+		}
+	}
+	for name, want := range map[string]bool{
+		"status_code":             false,
+		"title":                   false,
+		"content":                 true,
+		"field.price":             true,
+		"header.Content-Language": false,
+	} {
+		got, ok := differs[name]
+		if !ok {
+			t.Errorf("Expected field %s in the report", name)
+		} else if got != want {
+			t.Errorf("Expected %s differs=%v, got %v", name, want, got)
+		}
+	}
+	if n := len(report.Differing()); n != 2 {
+		t.Errorf("Expected 2 differing fields, got %d", n)
+	}
+}
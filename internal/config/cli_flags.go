@@ -9,10 +9,33 @@ func RegisterFlags(cmd *cobra.Command) {
 	}
 
 	cmd.PersistentFlags().BoolP("verbose", "v", false, "Enable debug logging")
-	cmd.PersistentFlags().BoolP("quiet", "q", false, "Suppress all output except errors")
+	cmd.PersistentFlags().BoolP("quiet", "q", false, "Suppress banners, progress bars and summaries; print only results and errors")
+	cmd.PersistentFlags().Bool("no-color", false, "Disable colored output (also set by the NO_COLOR environment variable)")
+	cmd.PersistentFlags().String("theme", "dark", "Output theme: dark, light or plain (no colors, ASCII symbols)")
+	cmd.PersistentFlags().String("lang", "", "Language for messages (default: $LANG, falling back to English)")
 	cmd.PersistentFlags().Bool("json", false, "Output in JSON format only")
+	cmd.PersistentFlags().BoolP("yes", "y", false, "Answer yes to every confirmation prompt")
+	cmd.PersistentFlags().Bool("non-interactive", false, "Never prompt or pause for input: confirmations are answered yes (for scripts and CI)")
 	cmd.PersistentFlags().String("proxy", "", "Set HTTP/SOCKS5 proxy (e.g., http://localhost:8080)")
 	cmd.PersistentFlags().String("timeout", "30s", "Set hard timeout for requests")
 	cmd.PersistentFlags().String("user-agent", "", "Custom user agent string")
-	cmd.PersistentFlags().String("config", "", "Path to configuration file (optional)")
+	cmd.PersistentFlags().String("from", "", "Contact for site operators (email or URL): added to the user agent, and sent as the From header if an email address")
+	cmd.PersistentFlags().String("config", "", "Path to configuration file (default: config.yaml in the user config directory, if present)")
+	cmd.PersistentFlags().Int("max-per-domain", DefaultMaxConcurrentPerDomain, "Maximum simultaneous requests per domain (0 for unlimited)")
+	cmd.PersistentFlags().String("memory-limit", "", "Pause new requests and free caches and idle browser tabs when memory use passes this, e.g. 2GB (off by default)")
+	cmd.PersistentFlags().StringArray("domain-concurrency", []string{}, "Per-domain concurrency override as host=N (repeatable)")
+	cmd.PersistentFlags().Int("max-idle-per-host", DefaultMaxIdleConnsPerHost, "Idle keep-alive connections kept open per host")
+	cmd.PersistentFlags().Duration("tls-handshake-timeout", DefaultTLSHandshakeTimeout, "Maximum time to wait for a TLS handshake")
+	cmd.PersistentFlags().Duration("expect-continue-timeout", DefaultExpectContinueTimeout, "Time to wait for a 100-continue response (0 sends the body immediately)")
+	cmd.PersistentFlags().Bool("no-keepalive", false, "Disable HTTP keep-alive (new connection per request)")
+	cmd.PersistentFlags().String("cdp", "", "Use an already-running browser at this DevTools endpoint (e.g. ws://localhost:9222) for SPA mode")
+	cmd.PersistentFlags().String("request-log", "", "Append a JSON line for every outbound request to this file")
+	cmd.PersistentFlags().String("log-file", "", "Write logs to this file instead of stderr, including info events")
+	cmd.PersistentFlags().String("log-format", "", "Log format: console, json or logfmt (default console, or json with --json)")
+	cmd.PersistentFlags().String("log-max-size", "10MB", "Rotate --log-file once it reaches this size (0 never rotates)")
+	cmd.PersistentFlags().Int("log-max-backups", DefaultLogMaxBackups, "Rotated log files to keep, as <file>.1, <file>.2, ...")
+	cmd.PersistentFlags().String("cache-policy", DefaultCachePolicy, "Caching: ttl keeps pages for cache_ttl, http caches static fetches as the site's Cache-Control, Expires and Vary headers allow")
+	cmd.PersistentFlags().String("record", "", "Record raw responses to this directory")
+	cmd.PersistentFlags().String("raw-output", "", "Save the exact, undecoded bytes of the page's HTTP response to this file and its status and headers to <file>.headers (crawl get with one URL, not SPA mode)")
+	cmd.PersistentFlags().String("replay", "", "Serve responses from a --record directory instead of the network")
 }
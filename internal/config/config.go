@@ -3,21 +3,59 @@ package config
 import (
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/law-makers/crawl/internal/paths"
+	outpututil "github.com/law-makers/crawl/internal/utils/output"
 	"github.com/spf13/cobra"
 )
 
 // Config holds application configuration values
 type Config struct {
-	// Logging
-	LogLevel string
-	JSONLog  bool
+	// Logging: LogFormat is console, json or logfmt (JSONLog, set by
+	// json_log or --json, picks json when it is empty). LogFile sends logs
+	// to a file, rotated past LogMaxSize bytes with LogMaxBackups kept.
+	// JobID is added to every event (CRAWL_JOB_ID, else random).
+	LogLevel      string
+	JSONLog       bool
+	LogFormat     string
+	LogFile       string
+	LogMaxSize    int64
+	LogMaxBackups int
+	JobID         string
+
+	// Output: Quiet drops banners, progress bars and summaries; NoColor
+	// prints without ANSI colors (also set by NO_COLOR)
+	Quiet   bool
+	NoColor bool
+
+	// UI theme (dark, light or plain) and message catalog language; an
+	// empty Language follows $LANG
+	Theme    string
+	Language string
+
+	// Never prompt: confirmations are answered yes and pauses are skipped
+	NonInteractive bool
 
 	// HTTP/Scraping
 	HTTPTimeout time.Duration
 	UserAgent   string
 	Proxy       string
+	// Email address or URL site operators can reach the person running the
+	// crawl at: added to the user agent, and sent as the From header if an
+	// email address, since From cannot carry a URL
+	Contact string
+	// Named proxies (name -> proxy URL) that commands like compare can
+	// refer to, e.g. a proxy per region
+	Proxies map[string]string
+
+	// HTTP transport tuning
+	MaxIdleConnsPerHost   int
+	TLSHandshakeTimeout   time.Duration
+	ExpectContinueTimeout time.Duration
+	DisableKeepAlives     bool
 
 	// Rate Limiting
 	StaticRateLimitRPS    float64
@@ -25,35 +63,110 @@ type Config struct {
 	DynamicRateLimitRPS   float64
 	DynamicRateLimitBurst int
 
-	// Browser Pool
-	BrowserPoolSize int
-	BrowserHeadless bool
-	ChromePath      string
+	// Concurrency: simultaneous requests per domain, with per-domain overrides
+	MaxConcurrentPerDomain int
+	DomainConcurrency      map[string]int
+
+	// Browser Pool: BrowserPoolSize is the most tabs open at once; the pool
+	// keeps BrowserPoolMin open and closes the rest after BrowserIdleTimeout
+	BrowserPoolSize    int
+	BrowserPoolMin     int
+	BrowserIdleTimeout time.Duration
+	BrowserHeadless    bool
+	ChromePath         string
+	// Download a managed chrome-headless-shell when no Chrome is found
+	BrowserAutoInstall bool
+	// CDP endpoint of an already-running browser to use instead of launching one
+	BrowserRemoteURL string
+	// Browser backend for SPA mode (default "chromedp")
+	BrowserDriver string
+	// Open DevTools in each browser tab (set by get --devtools)
+	BrowserDevTools bool
 
-	// Caching
+	// Caching: CachePolicy is "ttl" (pages kept for CacheTTL) or "http"
+	// (static fetches cached as the origin's Cache-Control, Expires and Vary
+	// allow; see internal/httpcache)
 	CacheTTL          time.Duration
 	CacheMaxSizeBytes int64
+	CacheMaxEntries   int // 0 = no limit on the number of entries
+	CachePolicy       string
+
+	// Per-host overrides from the config file's domains block
+	Domains map[string]DomainOverride
+
+	// Record/Replay
+	RecordDir string
+	ReplayDir string
+
+	// File for the undecoded body of the last HTTP response, with its
+	// headers next to it (see replay.RawDump)
+	RawOutput string
+
+	// Audit log of every outbound request (JSON lines)
+	RequestLog string
+
+	// Memory use (bytes) above which new requests pause and caches and idle
+	// browser tabs are released; 0 disables the guard
+	MemoryLimit int64
+
+	// System-wide policy file (see internal/policy). It is not settable
+	// from flags, the environment or the config file.
+	PolicyFile string
 
 	// Feature Flags
 	EnableBatch bool
+
+	// Config file that was applied, if any
+	File string
 }
 
 // Load builds a Config by combining defaults, an optional config file, environment variables, and CLI flags.
 // Caller should pass the root *cobra.Command so flags can be read.
 func Load(cmd *cobra.Command) (*Config, error) {
 	cfg := &Config{
-		LogLevel:              DefaultLogLevel,
-		JSONLog:               DefaultJSONLog,
-		HTTPTimeout:           DefaultHTTPTimeout,
-		UserAgent:             DefaultUserAgent,
-		StaticRateLimitRPS:    DefaultStaticRateLimitRPS,
-		StaticRateLimitBurst:  DefaultStaticRateLimitBurst,
-		DynamicRateLimitRPS:   DefaultDynamicRateLimitRPS,
-		DynamicRateLimitBurst: DefaultDynamicRateLimitBurst,
-		BrowserPoolSize:       DefaultBrowserPoolSize,
-		BrowserHeadless:       DefaultBrowserHeadless,
-		CacheTTL:              DefaultCacheTTL,
-		CacheMaxSizeBytes:     DefaultCacheMaxSizeBytes,
+		LogLevel:               DefaultLogLevel,
+		JSONLog:                DefaultJSONLog,
+		LogMaxSize:             DefaultLogMaxSize,
+		LogMaxBackups:          DefaultLogMaxBackups,
+		HTTPTimeout:            DefaultHTTPTimeout,
+		MaxIdleConnsPerHost:    DefaultMaxIdleConnsPerHost,
+		TLSHandshakeTimeout:    DefaultTLSHandshakeTimeout,
+		ExpectContinueTimeout:  DefaultExpectContinueTimeout,
+		UserAgent:              DefaultUserAgent,
+		StaticRateLimitRPS:     DefaultStaticRateLimitRPS,
+		StaticRateLimitBurst:   DefaultStaticRateLimitBurst,
+		DynamicRateLimitRPS:    DefaultDynamicRateLimitRPS,
+		DynamicRateLimitBurst:  DefaultDynamicRateLimitBurst,
+		MaxConcurrentPerDomain: DefaultMaxConcurrentPerDomain,
+		DomainConcurrency:      map[string]int{},
+		Proxies:                map[string]string{},
+		Domains:                map[string]DomainOverride{},
+		PolicyFile:             paths.PolicyFile(),
+		BrowserPoolSize:        DefaultBrowserPoolSize,
+		BrowserPoolMin:         DefaultBrowserPoolMin,
+		BrowserIdleTimeout:     DefaultBrowserIdleTimeout,
+		BrowserHeadless:        DefaultBrowserHeadless,
+		CacheTTL:               DefaultCacheTTL,
+		CacheMaxSizeBytes:      DefaultCacheMaxSizeBytes,
+		CachePolicy:            DefaultCachePolicy,
+	}
+
+	// Apply the config file: --config, CRAWL_CONFIG, or config.yaml in the
+	// user config directory
+	path := os.Getenv("CRAWL_CONFIG")
+	if cmd != nil {
+		if f := cmd.Flags().Lookup("config"); f != nil && f.Value.String() != "" {
+			path = f.Value.String()
+		}
+	}
+	if path == "" {
+		path = paths.DefaultConfigFile()
+	}
+	if path != "" {
+		if err := loadFile(path, cfg); err != nil {
+			return nil, err
+		}
+		cfg.File = path
 	}
 
 	// Override from environment variables (simple helpers)
@@ -63,9 +176,51 @@ func Load(cmd *cobra.Command) (*Config, error) {
 	if v := os.Getenv("CRAWL_PROXY"); v != "" {
 		cfg.Proxy = v
 	}
+	if v := os.Getenv("CRAWL_CONTACT"); v != "" {
+		cfg.Contact = v
+	}
+	if v := os.Getenv("CRAWL_CACHE_POLICY"); v != "" {
+		cfg.CachePolicy = v
+	}
 	if v := os.Getenv("CRAWL_CHROME_PATH"); v != "" {
 		cfg.ChromePath = v
 	}
+	if v := os.Getenv("CRAWL_BROWSER_DRIVER"); v != "" {
+		cfg.BrowserDriver = v
+	}
+	if v := os.Getenv("CRAWL_CDP_URL"); v != "" {
+		cfg.BrowserRemoteURL = v
+	}
+	if v, err := strconv.ParseBool(os.Getenv("CRAWL_BROWSER_AUTO_INSTALL")); err == nil {
+		cfg.BrowserAutoInstall = v
+	}
+	if v := os.Getenv("CRAWL_THEME"); v != "" {
+		cfg.Theme = v
+	}
+	if v := os.Getenv("CRAWL_LANG"); v != "" {
+		cfg.Language = v
+	}
+	if os.Getenv("NO_COLOR") != "" {
+		cfg.NoColor = true
+	}
+	if v, err := strconv.ParseBool(os.Getenv("CRAWL_NON_INTERACTIVE")); err == nil {
+		cfg.NonInteractive = v
+	}
+	cfg.MaxConcurrentPerDomain = int(envInt64("CRAWL_MAX_PER_DOMAIN", int64(cfg.MaxConcurrentPerDomain)))
+	if v := os.Getenv("CRAWL_LOG_FILE"); v != "" {
+		cfg.LogFile = v
+	}
+	if v := os.Getenv("CRAWL_LOG_FORMAT"); v != "" {
+		cfg.LogFormat = v
+	}
+	cfg.JobID = os.Getenv("CRAWL_JOB_ID")
+	if v := os.Getenv("CRAWL_MEMORY_LIMIT"); v != "" {
+		n, err := parseMemoryLimit(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CRAWL_MEMORY_LIMIT: %w", err)
+		}
+		cfg.MemoryLimit = n
+	}
 
 	// Read CLI flags if provided
 	if cmd != nil {
@@ -79,28 +234,156 @@ func Load(cmd *cobra.Command) (*Config, error) {
 				cfg.Proxy = s
 			}
 		}
-		if f := cmd.Flags().Lookup("timeout"); f != nil {
+		if f := cmd.Flags().Lookup("from"); f != nil && f.Changed {
+			cfg.Contact = f.Value.String()
+		}
+		if f := cmd.Flags().Lookup("timeout"); f != nil && f.Changed {
 			if s := f.Value.String(); s != "" {
 				if d, err := time.ParseDuration(s); err == nil {
 					cfg.HTTPTimeout = d
 				}
 			}
 		}
+		if f := cmd.Flags().Lookup("max-idle-per-host"); f != nil && f.Changed {
+			if n, err := strconv.Atoi(f.Value.String()); err == nil {
+				cfg.MaxIdleConnsPerHost = n
+			}
+		}
+		if f := cmd.Flags().Lookup("tls-handshake-timeout"); f != nil && f.Changed {
+			if d, err := time.ParseDuration(f.Value.String()); err == nil {
+				cfg.TLSHandshakeTimeout = d
+			}
+		}
+		if f := cmd.Flags().Lookup("expect-continue-timeout"); f != nil && f.Changed {
+			if d, err := time.ParseDuration(f.Value.String()); err == nil {
+				cfg.ExpectContinueTimeout = d
+			}
+		}
+		if f := cmd.Flags().Lookup("no-keepalive"); f != nil && f.Changed {
+			cfg.DisableKeepAlives = f.Value.String() == "true"
+		}
+		if f := cmd.Flags().Lookup("cdp"); f != nil && f.Changed {
+			cfg.BrowserRemoteURL = f.Value.String()
+		}
+		if f := cmd.Flags().Lookup("record"); f != nil {
+			cfg.RecordDir = f.Value.String()
+		}
+		if f := cmd.Flags().Lookup("replay"); f != nil {
+			cfg.ReplayDir = f.Value.String()
+		}
+		if f := cmd.Flags().Lookup("raw-output"); f != nil {
+			cfg.RawOutput = f.Value.String()
+		}
+		if f := cmd.Flags().Lookup("cache-policy"); f != nil && f.Changed {
+			cfg.CachePolicy = f.Value.String()
+		}
+		if f := cmd.Flags().Lookup("request-log"); f != nil {
+			cfg.RequestLog = f.Value.String()
+		}
+		if f := cmd.Flags().Lookup("log-file"); f != nil && f.Changed {
+			cfg.LogFile = f.Value.String()
+		}
+		if f := cmd.Flags().Lookup("log-format"); f != nil && f.Changed {
+			cfg.LogFormat = f.Value.String()
+		}
+		if f := cmd.Flags().Lookup("log-max-size"); f != nil && f.Changed {
+			n, err := parseLogMaxSize(f.Value.String())
+			if err != nil {
+				return nil, fmt.Errorf("invalid --log-max-size: %w", err)
+			}
+			cfg.LogMaxSize = n
+		}
+		if f := cmd.Flags().Lookup("log-max-backups"); f != nil && f.Changed {
+			if n, err := strconv.Atoi(f.Value.String()); err == nil {
+				cfg.LogMaxBackups = n
+			}
+		}
+		if f := cmd.Flags().Lookup("max-per-domain"); f != nil && f.Changed {
+			if n, err := strconv.Atoi(f.Value.String()); err == nil {
+				cfg.MaxConcurrentPerDomain = n
+			}
+		}
+		if f := cmd.Flags().Lookup("memory-limit"); f != nil && f.Changed {
+			n, err := parseMemoryLimit(f.Value.String())
+			if err != nil {
+				return nil, fmt.Errorf("invalid --memory-limit: %w", err)
+			}
+			cfg.MemoryLimit = n
+		}
+		if overrides, err := cmd.Flags().GetStringArray("domain-concurrency"); err == nil {
+			for _, entry := range overrides {
+				domain, n, err := parseDomainConcurrency(entry)
+				if err != nil {
+					return nil, err
+				}
+				cfg.DomainConcurrency[domain] = n
+			}
+		}
 		if f := cmd.Flags().Lookup("json"); f != nil {
 			if f.Value.String() == "true" {
 				cfg.JSONLog = true
 			}
 		}
+		if f := cmd.Flags().Lookup("quiet"); f != nil && f.Value.String() == "true" {
+			cfg.Quiet = true
+			cfg.LogLevel = "error"
+		}
+		if f := cmd.Flags().Lookup("no-color"); f != nil && f.Value.String() == "true" {
+			cfg.NoColor = true
+		}
+		if f := cmd.Flags().Lookup("theme"); f != nil && f.Changed {
+			cfg.Theme = f.Value.String()
+		}
+		if f := cmd.Flags().Lookup("lang"); f != nil && f.Changed {
+			cfg.Language = f.Value.String()
+		}
 		if f := cmd.Flags().Lookup("verbose"); f != nil {
 			if f.Value.String() == "true" {
 				cfg.LogLevel = "debug"
 			}
 		}
+		for _, name := range []string{"yes", "non-interactive"} {
+			if f := cmd.Flags().Lookup(name); f != nil && f.Value.String() == "true" {
+				cfg.NonInteractive = true
+			}
+		}
 	}
 
 	if err := validate(cfg); err != nil {
 		return nil, fmt.Errorf("invalid config: %w", err)
 	}
+	cfg.UserAgent = withContact(cfg.UserAgent, cfg.Contact)
 
 	return cfg, nil
 }
+
+// parseLogMaxSize parses a log rotation size such as 10MB; 0 never rotates
+func parseLogMaxSize(s string) (int64, error) {
+	if strings.TrimSpace(s) == "0" {
+		return 0, nil
+	}
+	return outpututil.ParseSize(s)
+}
+
+// parseMemoryLimit parses a memory limit such as 2GB; 0 or off disables it
+func parseMemoryLimit(s string) (int64, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "0", "off":
+		return 0, nil
+	}
+	return outpututil.ParseSize(s)
+}
+
+// parseDomainConcurrency parses a "host=N" per-domain concurrency override
+func parseDomainConcurrency(entry string) (string, int, error) {
+	domain, value, ok := strings.Cut(entry, "=")
+	domain = strings.ToLower(strings.TrimSpace(domain))
+	if !ok || domain == "" {
+		return "", 0, fmt.Errorf("invalid --domain-concurrency %q (expected host=N)", entry)
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || n < 0 {
+		return "", 0, fmt.Errorf("invalid --domain-concurrency %q (N must be a non-negative integer)", entry)
+	}
+	return domain, n, nil
+}
@@ -0,0 +1,44 @@
+package config
+
+import (
+	"fmt"
+	"net/mail"
+	"net/url"
+	"strings"
+)
+
+// FromAddress returns the email address in Contact, for the From header,
+// or "" when Contact is unset or a URL
+func (c *Config) FromAddress() string {
+	addr, err := mail.ParseAddress(strings.TrimPrefix(c.Contact, "mailto:"))
+	if err != nil {
+		return ""
+	}
+	return addr.Address
+}
+
+// validateContact checks that contact is an email address or http(s) URL
+func validateContact(contact string) error {
+	if contact == "" {
+		return nil
+	}
+	if _, err := mail.ParseAddress(strings.TrimPrefix(contact, "mailto:")); err == nil {
+		return nil
+	}
+	if u, err := url.Parse(contact); err == nil && u.Host != "" && (u.Scheme == "http" || u.Scheme == "https") {
+		return nil
+	}
+	return fmt.Errorf("--from must be an email address or http(s) URL, got %q", contact)
+}
+
+// withContact appends contact to a user agent in the usual "+contact"
+// form, e.g. "Crawl/1.0 (+mailto:ops@example.com)", unless it is already there
+func withContact(userAgent, contact string) string {
+	if contact == "" || strings.Contains(userAgent, contact) {
+		return userAgent
+	}
+	if !strings.Contains(contact, "://") && !strings.HasPrefix(contact, "mailto:") {
+		contact = "mailto:" + contact
+	}
+	return strings.TrimSpace(userAgent + " (+" + contact + ")")
+}
@@ -4,19 +4,28 @@ import "time"
 
 // Default constants for application configuration
 const (
-	DefaultLogLevel              = "info"
-	DefaultJSONLog               = false
-	DefaultUserAgent             = "Crawl/1.0 (https://github.com/law-makers/crawl)"
-	DefaultCacheTTL              = 5 * time.Minute
-	DefaultHTTPTimeout           = 30 * time.Second
-	DefaultStaticRateLimitRPS    = 5.0
-	DefaultStaticRateLimitBurst  = 10
-	DefaultDynamicRateLimitRPS   = 3.0
-	DefaultDynamicRateLimitBurst = 5
-	DefaultBrowserPoolSize       = 3
-	DefaultMaxBrowserPoolSize    = 10
-	DefaultBrowserHeadless       = true
-	DefaultCacheMaxSizeBytes     = 100 * 1024 * 1024 // 100MB
-	DefaultJSWaitTime            = 500 * time.Millisecond
-	DefaultPoolAcquireTTL        = 10 * time.Second
+	DefaultLogLevel               = "info"
+	DefaultJSONLog                = false
+	DefaultLogMaxSize             = 10 << 20
+	DefaultLogMaxBackups          = 3
+	DefaultUserAgent              = "Crawl/1.0 (https://github.com/law-makers/crawl)"
+	DefaultCacheTTL               = 5 * time.Minute
+	DefaultHTTPTimeout            = 30 * time.Second
+	DefaultStaticRateLimitRPS     = 5.0
+	DefaultStaticRateLimitBurst   = 10
+	DefaultDynamicRateLimitRPS    = 3.0
+	DefaultDynamicRateLimitBurst  = 5
+	DefaultMaxConcurrentPerDomain = 2
+	DefaultMaxIdleConnsPerHost    = 10
+	DefaultTLSHandshakeTimeout    = 10 * time.Second
+	DefaultExpectContinueTimeout  = 1 * time.Second
+	DefaultBrowserPoolSize        = 3
+	DefaultMaxBrowserPoolSize     = 10
+	DefaultBrowserPoolMin         = 1
+	DefaultBrowserIdleTimeout     = time.Minute
+	DefaultBrowserHeadless        = true
+	DefaultCacheMaxSizeBytes      = 100 * 1024 * 1024 // 100MB
+	DefaultCachePolicy            = "ttl"
+	DefaultJSWaitTime             = 500 * time.Millisecond
+	DefaultPoolAcquireTTL         = 10 * time.Second
 )
@@ -0,0 +1,49 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// DomainOverride holds per-host settings from the config file's domains
+// block. Zero values mean "use the global setting".
+type DomainOverride struct {
+	Mode          string            `yaml:"mode"`           // auto, static or spa
+	RPS           float64           `yaml:"rps"`            // requests per second
+	Burst         int               `yaml:"burst"`          // rate limit burst
+	MaxConcurrent int               `yaml:"max_concurrent"` // simultaneous requests
+	Headers       map[string]string `yaml:"headers"`        // added unless the request sets them
+	Proxy         string            `yaml:"proxy"`          // proxy URL for this host
+	WaitSeconds   int               `yaml:"wait_seconds"`   // wait after load before scraping
+}
+
+func (o DomainOverride) validate() error {
+	switch strings.ToLower(o.Mode) {
+	case "", "auto", "static", "spa":
+	default:
+		return fmt.Errorf("invalid mode %q (must be auto, static, or spa)", o.Mode)
+	}
+	if o.RPS < 0 || o.Burst < 0 || o.MaxConcurrent < 0 || o.WaitSeconds < 0 {
+		return fmt.Errorf("rps, burst, max_concurrent and wait_seconds must be >= 0")
+	}
+	return nil
+}
+
+// DomainFor returns the override for the URL's host, if any. Keys match the
+// host exactly (including a port, when the URL has one).
+func (c *Config) DomainFor(rawURL string) (DomainOverride, bool) {
+	if c == nil || len(c.Domains) == 0 {
+		return DomainOverride{}, false
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return DomainOverride{}, false
+	}
+	override, ok := c.Domains[normalizeHost(u.Host)]
+	return override, ok
+}
+
+func normalizeHost(host string) string {
+	return strings.ToLower(strings.TrimSpace(host))
+}
@@ -0,0 +1,200 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// fileConfig mirrors the YAML config file (see examples/crawl.yaml).
+// Pointer fields distinguish "unset" from zero values.
+type fileConfig struct {
+	LogLevel          *string                   `yaml:"log_level"`
+	JSONLog           *bool                     `yaml:"json_log"`
+	LogFormat         *string                   `yaml:"log_format"`
+	LogFile           *string                   `yaml:"log_file"`
+	LogMaxSize        *string                   `yaml:"log_max_size"`
+	LogMaxBackups     *int                      `yaml:"log_max_backups"`
+	Theme             *string                   `yaml:"theme"`
+	Language          *string                   `yaml:"language"`
+	HTTPTimeout       *string                   `yaml:"http_timeout"`
+	UserAgent         *string                   `yaml:"user_agent"`
+	Proxy             *string                   `yaml:"proxy"`
+	Contact           *string                   `yaml:"contact"`
+	CacheTTL          *string                   `yaml:"cache_ttl"`
+	CacheMaxSizeBytes *int64                    `yaml:"cache_max_size_bytes"`
+	CacheMaxEntries   *int                      `yaml:"cache_max_entries"`
+	CachePolicy       *string                   `yaml:"cache_policy"`
+	BrowserPoolSize   *int                      `yaml:"browser_pool_size"`
+	BrowserPoolMin    *int                      `yaml:"browser_pool_min"`
+	BrowserIdle       *string                   `yaml:"browser_idle_timeout"`
+	BrowserHeadless   *bool                     `yaml:"browser_headless"`
+	ChromePath        *string                   `yaml:"chrome_path"`
+	BrowserAutoInst   *bool                     `yaml:"browser_auto_install"`
+	BrowserRemoteURL  *string                   `yaml:"browser_remote_url"`
+	BrowserDriver     *string                   `yaml:"browser_driver"`
+	RateLimitRPS      *float64                  `yaml:"rate_limit_rps"`
+	RateLimitBurst    *int                      `yaml:"rate_limit_burst"`
+	MaxPerDomain      *int                      `yaml:"max_per_domain"`
+	MaxIdlePerHost    *int                      `yaml:"max_idle_conns_per_host"`
+	TLSHandshake      *string                   `yaml:"tls_handshake_timeout"`
+	ExpectContinue    *string                   `yaml:"expect_continue_timeout"`
+	DisableKeepAlives *bool                     `yaml:"disable_keepalives"`
+	MemoryLimit       *string                   `yaml:"memory_limit"`
+	Domains           map[string]DomainOverride `yaml:"domains"`
+	Proxies           map[string]string         `yaml:"proxies"`
+}
+
+// loadFile applies values from a YAML config file on top of cfg
+func loadFile(path string, cfg *Config) error {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var fc fileConfig
+	if err := yaml.Unmarshal(raw, &fc); err != nil {
+		return fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	if fc.LogLevel != nil {
+		cfg.LogLevel = *fc.LogLevel
+	}
+	if fc.JSONLog != nil {
+		cfg.JSONLog = *fc.JSONLog
+	}
+	if fc.LogFormat != nil {
+		cfg.LogFormat = *fc.LogFormat
+	}
+	if fc.LogFile != nil {
+		cfg.LogFile = *fc.LogFile
+	}
+	if fc.LogMaxSize != nil {
+		n, err := parseLogMaxSize(*fc.LogMaxSize)
+		if err != nil {
+			return fmt.Errorf("invalid log_max_size %q: %w", *fc.LogMaxSize, err)
+		}
+		cfg.LogMaxSize = n
+	}
+	if fc.LogMaxBackups != nil {
+		cfg.LogMaxBackups = *fc.LogMaxBackups
+	}
+	if fc.Theme != nil {
+		cfg.Theme = *fc.Theme
+	}
+	if fc.Language != nil {
+		cfg.Language = *fc.Language
+	}
+	if fc.HTTPTimeout != nil {
+		d, err := time.ParseDuration(*fc.HTTPTimeout)
+		if err != nil {
+			return fmt.Errorf("invalid http_timeout %q: %w", *fc.HTTPTimeout, err)
+		}
+		cfg.HTTPTimeout = d
+	}
+	if fc.UserAgent != nil {
+		cfg.UserAgent = *fc.UserAgent
+	}
+	if fc.Proxy != nil {
+		cfg.Proxy = *fc.Proxy
+	}
+	if fc.Contact != nil {
+		cfg.Contact = *fc.Contact
+	}
+	if fc.CacheTTL != nil {
+		d, err := time.ParseDuration(*fc.CacheTTL)
+		if err != nil {
+			return fmt.Errorf("invalid cache_ttl %q: %w", *fc.CacheTTL, err)
+		}
+		cfg.CacheTTL = d
+	}
+	if fc.CacheMaxSizeBytes != nil {
+		cfg.CacheMaxSizeBytes = *fc.CacheMaxSizeBytes
+	}
+	if fc.CacheMaxEntries != nil {
+		cfg.CacheMaxEntries = *fc.CacheMaxEntries
+	}
+	if fc.CachePolicy != nil {
+		cfg.CachePolicy = *fc.CachePolicy
+	}
+	if fc.BrowserPoolSize != nil {
+		cfg.BrowserPoolSize = *fc.BrowserPoolSize
+	}
+	if fc.BrowserPoolMin != nil {
+		cfg.BrowserPoolMin = *fc.BrowserPoolMin
+	}
+	if fc.BrowserIdle != nil {
+		d, err := time.ParseDuration(*fc.BrowserIdle)
+		if err != nil {
+			return fmt.Errorf("invalid browser_idle_timeout %q: %w", *fc.BrowserIdle, err)
+		}
+		cfg.BrowserIdleTimeout = d
+	}
+	if fc.BrowserHeadless != nil {
+		cfg.BrowserHeadless = *fc.BrowserHeadless
+	}
+	if fc.ChromePath != nil {
+		cfg.ChromePath = *fc.ChromePath
+	}
+	if fc.BrowserDriver != nil {
+		cfg.BrowserDriver = *fc.BrowserDriver
+	}
+	if fc.BrowserRemoteURL != nil {
+		cfg.BrowserRemoteURL = *fc.BrowserRemoteURL
+	}
+	if fc.BrowserAutoInst != nil {
+		cfg.BrowserAutoInstall = *fc.BrowserAutoInst
+	}
+	if fc.RateLimitRPS != nil {
+		cfg.StaticRateLimitRPS = *fc.RateLimitRPS
+	}
+	if fc.RateLimitBurst != nil {
+		cfg.StaticRateLimitBurst = *fc.RateLimitBurst
+	}
+	if fc.MaxPerDomain != nil {
+		cfg.MaxConcurrentPerDomain = *fc.MaxPerDomain
+	}
+	if fc.MaxIdlePerHost != nil {
+		cfg.MaxIdleConnsPerHost = *fc.MaxIdlePerHost
+	}
+	if fc.TLSHandshake != nil {
+		d, err := time.ParseDuration(*fc.TLSHandshake)
+		if err != nil {
+			return fmt.Errorf("invalid tls_handshake_timeout %q: %w", *fc.TLSHandshake, err)
+		}
+		cfg.TLSHandshakeTimeout = d
+	}
+	if fc.ExpectContinue != nil {
+		d, err := time.ParseDuration(*fc.ExpectContinue)
+		if err != nil {
+			return fmt.Errorf("invalid expect_continue_timeout %q: %w", *fc.ExpectContinue, err)
+		}
+		cfg.ExpectContinueTimeout = d
+	}
+	if fc.DisableKeepAlives != nil {
+		cfg.DisableKeepAlives = *fc.DisableKeepAlives
+	}
+	if fc.MemoryLimit != nil {
+		n, err := parseMemoryLimit(*fc.MemoryLimit)
+		if err != nil {
+			return fmt.Errorf("invalid memory_limit %q: %w", *fc.MemoryLimit, err)
+		}
+		cfg.MemoryLimit = n
+	}
+	for host, override := range fc.Domains {
+		if err := override.validate(); err != nil {
+			return fmt.Errorf("domains.%s: %w", host, err)
+		}
+		cfg.Domains[normalizeHost(host)] = override
+	}
+	for name, raw := range fc.Proxies {
+		if u, err := url.Parse(raw); err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("proxies.%s: invalid proxy URL %q", name, raw)
+		}
+		cfg.Proxies[name] = raw
+	}
+	return nil
+}
@@ -0,0 +1,181 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLoadFile_DomainsBlock(t *testing.T) {
+	cfg, err := Load(nil)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if err := loadFile(filepath.Join("examples", "crawl.yaml"), cfg); err != nil {
+		t.Fatalf("loadFile failed: %v", err)
+	}
+
+	if cfg.HTTPTimeout != 30*time.Second {
+		t.Errorf("Expected http_timeout 30s, got %v", cfg.HTTPTimeout)
+	}
+
+	shop, ok := cfg.DomainFor("https://Shop.Example.com/cart")
+	if !ok {
+		t.Fatal("Expected override for shop.example.com")
+	}
+	if shop.Mode != "spa" || shop.RPS != 1 || shop.WaitSeconds != 2 {
+		t.Errorf("Unexpected override: %+v", shop)
+	}
+	if shop.Headers["Accept-Language"] != "de-DE" {
+		t.Errorf("Expected header override, got %v", shop.Headers)
+	}
+
+	if _, ok := cfg.DomainFor("https://example.com/"); ok {
+		t.Error("Expected no override for example.com")
+	}
+}
+
+func TestLoadFile_InvalidMode(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "crawl.yaml")
+	os.WriteFile(path, []byte("domains:\n  example.com:\n    mode: turbo\n"), 0644)
+
+	cfg, _ := Load(nil)
+	if err := loadFile(path, cfg); err == nil {
+		t.Error("Expected error for invalid mode")
+	}
+}
+
+func TestLoad_RemoteBrowserURL(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "crawl.yaml")
+	os.WriteFile(path, []byte("browser_remote_url: ws://chrome:9222\n"), 0644)
+	t.Setenv("CRAWL_CONFIG", path)
+
+	cfg, err := Load(nil)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.BrowserRemoteURL != "ws://chrome:9222" {
+		t.Errorf("Expected remote URL from file, got %q", cfg.BrowserRemoteURL)
+	}
+
+	t.Setenv("CRAWL_CDP_URL", "http://localhost:9333")
+	if cfg, _ = Load(nil); cfg.BrowserRemoteURL != "http://localhost:9333" {
+		t.Errorf("Expected CRAWL_CDP_URL to override the file, got %q", cfg.BrowserRemoteURL)
+	}
+
+	t.Setenv("CRAWL_CDP_URL", "localhost:9222")
+	if _, err := Load(nil); err == nil {
+		t.Error("Expected error for an endpoint without a scheme")
+	}
+}
+
+func TestLoad_MemoryLimit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "crawl.yaml")
+	os.WriteFile(path, []byte("memory_limit: 1.5GB\n"), 0644)
+	t.Setenv("CRAWL_CONFIG", path)
+
+	cfg, err := Load(nil)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.MemoryLimit != 3<<29 {
+		t.Errorf("Expected a 1.5GB limit, got %d", cfg.MemoryLimit)
+	}
+
+	t.Setenv("CRAWL_MEMORY_LIMIT", "off")
+	if cfg, err := Load(nil); err != nil || cfg.MemoryLimit != 0 {
+		t.Errorf("Expected CRAWL_MEMORY_LIMIT=off to disable the guard, got %d, %v", cfg.MemoryLimit, err)
+	}
+	t.Setenv("CRAWL_MEMORY_LIMIT", "lots")
+	if _, err := Load(nil); err == nil {
+		t.Error("Expected an error for an invalid limit")
+	}
+}
+
+func TestLoad_BrowserPoolScaling(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "crawl.yaml")
+	os.WriteFile(path, []byte("browser_pool_size: 4\nbrowser_pool_min: 0\nbrowser_idle_timeout: 30s\n"), 0644)
+	t.Setenv("CRAWL_CONFIG", path)
+
+	cfg, err := Load(nil)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.BrowserPoolMin != 0 || cfg.BrowserIdleTimeout != 30*time.Second {
+		t.Errorf("Expected min 0 and a 30s idle timeout, got %d and %v", cfg.BrowserPoolMin, cfg.BrowserIdleTimeout)
+	}
+
+	os.WriteFile(path, []byte("browser_pool_size: 2\nbrowser_pool_min: 3\n"), 0644)
+	if _, err := Load(nil); err == nil {
+		t.Error("Expected an error for a minimum above the pool size")
+	}
+}
+
+func TestLoad_Contact(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "crawl.yaml")
+	os.WriteFile(path, []byte("contact: ops@example.com\n"), 0644)
+	t.Setenv("CRAWL_CONFIG", path)
+
+	cfg, err := Load(nil)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.FromAddress() != "ops@example.com" {
+		t.Errorf("Expected the From address from the file, got %q", cfg.FromAddress())
+	}
+	if cfg.UserAgent != DefaultUserAgent+" (+mailto:ops@example.com)" {
+		t.Errorf("Expected the contact in the user agent, got %q", cfg.UserAgent)
+	}
+
+	t.Setenv("CRAWL_CONTACT", "https://example.com/crawler")
+	if cfg, err = Load(nil); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.FromAddress() != "" || cfg.UserAgent != DefaultUserAgent+" (+https://example.com/crawler)" {
+		t.Errorf("Expected a URL contact only in the user agent, got From %q and %q", cfg.FromAddress(), cfg.UserAgent)
+	}
+
+	t.Setenv("CRAWL_CONTACT", "not a contact")
+	if _, err := Load(nil); err == nil {
+		t.Error("Expected an error for an invalid contact")
+	}
+}
+
+func TestLoad_CachePolicy(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "crawl.yaml")
+	os.WriteFile(path, []byte("cache_policy: http\n"), 0644)
+	t.Setenv("CRAWL_CONFIG", path)
+
+	cfg, err := Load(nil)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.CachePolicy != "http" {
+		t.Errorf("Expected cache policy http, got %q", cfg.CachePolicy)
+	}
+
+	t.Setenv("CRAWL_CACHE_POLICY", "forever")
+	if _, err := Load(nil); err == nil {
+		t.Error("Expected an error for an unknown cache policy")
+	}
+}
+
+func TestLoad_Proxies(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "crawl.yaml")
+	os.WriteFile(path, []byte("proxies:\n  us: http://us.example:8080\n"), 0644)
+	t.Setenv("CRAWL_CONFIG", path)
+
+	cfg, err := Load(nil)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.Proxies["us"] != "http://us.example:8080" {
+		t.Errorf("Expected the named proxy, got %v", cfg.Proxies)
+	}
+
+	os.WriteFile(path, []byte("proxies:\n  de: not-a-url\n"), 0644)
+	if _, err := Load(nil); err == nil {
+		t.Error("Expected an error for an invalid proxy URL")
+	}
+}
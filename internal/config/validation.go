@@ -1,6 +1,9 @@
 package config
 
-import "fmt"
+import (
+	"fmt"
+	"net/url"
+)
 
 func validate(c *Config) error {
 	if c.HTTPTimeout <= 0 {
@@ -9,8 +12,45 @@ func validate(c *Config) error {
 	if c.BrowserPoolSize <= 0 || c.BrowserPoolSize > DefaultMaxBrowserPoolSize {
 		return fmt.Errorf("browser pool size must be between 1 and %d", DefaultMaxBrowserPoolSize)
 	}
+	if c.BrowserPoolMin < 0 || c.BrowserPoolMin > c.BrowserPoolSize {
+		return fmt.Errorf("browser pool minimum must be between 0 and the pool size (%d)", c.BrowserPoolSize)
+	}
+	if c.BrowserIdleTimeout < 0 {
+		return fmt.Errorf("browser idle timeout must be >= 0")
+	}
 	if c.CacheMaxSizeBytes <= 0 {
 		return fmt.Errorf("cache max size must be > 0")
 	}
+	switch c.CachePolicy {
+	case "ttl", "http":
+	default:
+		return fmt.Errorf("cache policy must be ttl or http, got %q", c.CachePolicy)
+	}
+	if c.MaxIdleConnsPerHost < 0 || c.TLSHandshakeTimeout < 0 || c.ExpectContinueTimeout < 0 {
+		return fmt.Errorf("transport settings must be >= 0")
+	}
+	if c.MaxConcurrentPerDomain < 0 {
+		return fmt.Errorf("max concurrent requests per domain must be >= 0")
+	}
+	if c.BrowserRemoteURL != "" {
+		u, err := url.Parse(c.BrowserRemoteURL)
+		if err != nil || u.Host == "" || (u.Scheme != "ws" && u.Scheme != "wss" && u.Scheme != "http" && u.Scheme != "https") {
+			return fmt.Errorf("--cdp must be a ws://, wss:// or http:// DevTools endpoint, got %q", c.BrowserRemoteURL)
+		}
+	}
+	if err := validateContact(c.Contact); err != nil {
+		return err
+	}
+	switch c.LogFormat {
+	case "", "console", "json", "logfmt":
+	default:
+		return fmt.Errorf("log format must be console, json or logfmt, got %q", c.LogFormat)
+	}
+	if c.LogMaxSize < 0 || c.LogMaxBackups < 0 {
+		return fmt.Errorf("log rotation settings must be >= 0")
+	}
+	if c.RecordDir != "" && c.ReplayDir != "" {
+		return fmt.Errorf("--record and --replay cannot be used together")
+	}
 	return nil
 }
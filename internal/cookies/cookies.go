@@ -0,0 +1,121 @@
+// internal/cookies/cookies.go
+//
+// Package cookies turns the Set-Cookie headers seen while loading a page
+// into models.Cookie records, telling first-party cookies from third-party
+// ones by registrable domain (eTLD+1).
+package cookies
+
+import (
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/law-makers/crawl/pkg/models"
+	"golang.org/x/net/publicsuffix"
+)
+
+// Parse reads one Set-Cookie header sent in the response to responseURL.
+// pageURL is the page being loaded, for deciding whether the cookie is
+// third-party. ok is false for headers that aren't valid cookies.
+func Parse(header, responseURL, pageURL string) (cookie models.Cookie, ok bool) {
+	c, err := http.ParseSetCookie(header)
+	if err != nil {
+		return models.Cookie{}, false
+	}
+	cookie = models.Cookie{
+		Name:     c.Name,
+		Value:    c.Value,
+		Domain:   strings.TrimPrefix(strings.ToLower(c.Domain), "."),
+		Path:     c.Path,
+		Secure:   c.Secure,
+		HTTPOnly: c.HttpOnly,
+		SameSite: sameSite(c.SameSite),
+		Size:     len(c.Name) + len(c.Value),
+		SetBy:    responseURL,
+	}
+	if cookie.Domain == "" {
+		cookie.Domain = hostname(responseURL)
+	}
+	// Max-Age wins over Expires, as in browsers
+	switch {
+	case c.MaxAge > 0:
+		expires := time.Now().Add(time.Duration(c.MaxAge) * time.Second).UTC().Truncate(time.Second)
+		cookie.Expires = &expires
+	case c.MaxAge < 0:
+		expired := time.Unix(0, 0).UTC()
+		cookie.Expires = &expired
+	case !c.Expires.IsZero():
+		expires := c.Expires.UTC()
+		cookie.Expires = &expires
+	}
+	cookie.ThirdParty = site(cookie.Domain) != site(hostname(pageURL))
+	return cookie, true
+}
+
+// FromHeader parses every Set-Cookie header in h
+func FromHeader(h http.Header, responseURL, pageURL string) []models.Cookie {
+	var found []models.Cookie
+	for _, line := range h.Values("Set-Cookie") {
+		if c, ok := Parse(line, responseURL, pageURL); ok {
+			found = append(found, c)
+		}
+	}
+	return found
+}
+
+// Sort orders cookies first-party first, then by domain and name
+func Sort(cookies []models.Cookie) {
+	sort.SliceStable(cookies, func(i, j int) bool {
+		a, b := cookies[i], cookies[j]
+		if a.ThirdParty != b.ThirdParty {
+			return !a.ThirdParty
+		}
+		if a.Domain != b.Domain {
+			return a.Domain < b.Domain
+		}
+		return a.Name < b.Name
+	})
+}
+
+// ThirdParty counts the third-party cookies and the sites that set them
+func ThirdParty(cookies []models.Cookie) (count, sites int) {
+	seen := map[string]bool{}
+	for _, c := range cookies {
+		if c.ThirdParty {
+			count++
+			seen[site(c.Domain)] = true
+		}
+	}
+	return count, len(seen)
+}
+
+// site returns the registrable domain of host, or host itself for IP
+// addresses, localhost and the like
+func site(host string) string {
+	if s, err := publicsuffix.EffectiveTLDPlusOne(host); err == nil {
+		return s
+	}
+	return host
+}
+
+func hostname(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(u.Hostname())
+}
+
+func sameSite(s http.SameSite) string {
+	switch s {
+	case http.SameSiteStrictMode:
+		return "Strict"
+	case http.SameSiteLaxMode:
+		return "Lax"
+	case http.SameSiteNoneMode:
+		return "None"
+	}
+	return ""
+}
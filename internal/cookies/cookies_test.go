@@ -0,0 +1,50 @@
+package cookies
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	c, ok := Parse("sid=abc123; Path=/; Secure; HttpOnly; SameSite=Lax; Max-Age=3600", "https://www.example.com/login", "https://example.com/")
+	if !ok {
+		t.Fatal("Expected a valid cookie")
+	}
+	if c.Name != "sid" || c.Value != "abc123" || c.Domain != "www.example.com" || c.Path != "/" {
+		t.Errorf("Unexpected cookie: %+v", c)
+	}
+	if !c.Secure || !c.HTTPOnly || c.SameSite != "Lax" || c.Size != 9 || c.ThirdParty {
+		t.Errorf("Unexpected attributes: %+v", c)
+	}
+	if c.Expires == nil || time.Until(*c.Expires) < 59*time.Minute {
+		t.Errorf("Expected expiry in an hour, got %v", c.Expires)
+	}
+
+	c, _ = Parse("_ga=1; Domain=.tracker.net", "https://tracker.net/pixel", "https://example.com/")
+	if !c.ThirdParty || c.Domain != "tracker.net" || c.Expires != nil {
+		t.Errorf("Expected a third-party session cookie, got %+v", c)
+	}
+
+	if _, ok := Parse("not a cookie", "https://example.com/", "https://example.com/"); ok {
+		t.Error("Expected an invalid header to be rejected")
+	}
+}
+
+func TestFromHeaderAndThirdParty(t *testing.T) {
+	h := http.Header{}
+	h.Add("Set-Cookie", "a=1")
+	h.Add("Set-Cookie", "b=2; Domain=ads.example.org")
+	h.Add("Set-Cookie", "c=3; Domain=cdn.example.org")
+	found := FromHeader(h, "https://example.com/", "https://example.com/")
+	if len(found) != 3 {
+		t.Fatalf("Expected 3 cookies, got %d", len(found))
+	}
+	Sort(found)
+	if found[0].Name != "a" || found[0].ThirdParty {
+		t.Errorf("Expected first-party cookies first, got %+v", found[0])
+	}
+	if count, sites := ThirdParty(found); count != 2 || sites != 1 {
+		t.Errorf("ThirdParty = %d cookies from %d sites, want 2 from 1", count, sites)
+	}
+}
@@ -3,12 +3,15 @@ package downloader
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -23,9 +26,16 @@ type DownloadResult struct {
 	FilePath  string
 	Size      int64
 	Success   bool
+	Unchanged bool // Incremental mode: the remote file has not changed since the last run
+	Skipped   bool // Not attempted because the run budget was exhausted
 	Error     error
 	StartTime time.Time
 	Duration  time.Duration
+
+	StatusCode  int           // HTTP status of the last attempt (0 if no response)
+	ContentType string        // Content-Type of the downloaded file, as served
+	Attempts    int           // Number of attempts made, including retries
+	Waited      time.Duration // Time spent waiting on rate limits and host slots
 }
 
 // DownloadError provides detailed context about download failures
@@ -33,7 +43,8 @@ type DownloadError struct {
 	URL         string
 	StatusCode  int
 	Message     string
-	BodySnippet string // First 500 chars
+	BodySnippet string        // First 500 chars
+	Wait        time.Duration // Server-requested delay from Retry-After
 	Underlying  error
 }
 
@@ -51,6 +62,11 @@ func (e *DownloadError) GetStatusCode() int {
 	return e.StatusCode
 }
 
+// RetryAfter returns the delay requested by the server, if any
+func (e *DownloadError) RetryAfter() time.Duration {
+	return e.Wait
+}
+
 // DownloadOptions configures the download behavior
 type DownloadOptions struct {
 	OutputDir string
@@ -58,12 +74,19 @@ type DownloadOptions struct {
 	Filename  string
 	UserAgent string
 	Headers   map[string]string
+	State     *State // Incremental mode: skip files unchanged since the last run
+	FailFast  bool   // Stop starting downloads after the first failure
+
+	// PostProcess runs on each newly downloaded file, e.g. to convert
+	// images, and returns the file's path afterwards
+	PostProcess func(path string) (string, error)
 }
 
 // Downloader handles concurrent media downloads with streaming I/O
 type Downloader struct {
-	client    *http.Client
-	userAgent string
+	client      *http.Client
+	userAgent   string
+	retryConfig retry.Config
 }
 
 var bufferPool = sync.Pool{
@@ -102,21 +125,15 @@ func NewDownloader(timeout time.Duration, userAgent string) *Downloader {
 	}
 
 	return &Downloader{
-		client:    client,
-		userAgent: userAgent,
+		client:      client,
+		userAgent:   userAgent,
+		retryConfig: DefaultRetryConfig(),
 	}
 }
 
-// Download downloads a single file with streaming I/O
-func (d *Downloader) Download(ctx context.Context, fileURL string, opts DownloadOptions) *DownloadResult {
-	result := &DownloadResult{
-		URL:       fileURL,
-		StartTime: time.Now(),
-		Success:   false,
-	}
-
-	// Wrap download with retry logic
-	retryConfig := retry.Config{
+// DefaultRetryConfig returns the retry policy used for downloads
+func DefaultRetryConfig() retry.Config {
+	return retry.Config{
 		MaxAttempts:    3,
 		InitialBackoff: 1 * time.Second,
 		MaxBackoff:     10 * time.Second,
@@ -129,8 +146,27 @@ func (d *Downloader) Download(ctx context.Context, fileURL string, opts Download
 			http.StatusGatewayTimeout,
 		},
 	}
+}
 
-	err := retry.WithRetry(ctx, retryConfig, func() error {
+// SetRetryConfig replaces the retry policy used for downloads
+func (d *Downloader) SetRetryConfig(cfg retry.Config) {
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 1
+	}
+	d.retryConfig = cfg
+}
+
+// Download downloads a single file with streaming I/O
+func (d *Downloader) Download(ctx context.Context, fileURL string, opts DownloadOptions) *DownloadResult {
+	result := &DownloadResult{
+		URL:       fileURL,
+		StartTime: time.Now(),
+		Success:   false,
+	}
+
+	// Wrap download with retry logic
+	err := retry.WithRetry(ctx, d.retryConfig, func() error {
+		result.Attempts++
 		return d.downloadOnce(ctx, fileURL, opts, result)
 	})
 
@@ -138,11 +174,38 @@ func (d *Downloader) Download(ctx context.Context, fileURL string, opts Download
 		result.Error = err
 		result.Success = false
 	}
+	if result.Success && !result.Unchanged && opts.PostProcess != nil {
+		postProcess(fileURL, opts, result)
+	}
 
 	result.Duration = time.Since(result.StartTime)
 	return result
 }
 
+// postProcess runs opts.PostProcess on a finished download. A failure keeps
+// the file as downloaded rather than failing it.
+func postProcess(fileURL string, opts DownloadOptions, result *DownloadResult) {
+	path, err := opts.PostProcess(result.FilePath)
+	if err != nil {
+		log.Warn().Err(err).Str("file", result.FilePath).Msg("Post-processing failed; keeping the file as downloaded")
+	}
+	info, statErr := os.Stat(path)
+	if statErr != nil {
+		return
+	}
+	result.FilePath = path
+	result.Size = info.Size()
+
+	// Point the incremental state at the processed file
+	if opts.State != nil {
+		if entry, ok := opts.State.Get(fileURL); ok {
+			entry.File = path
+			entry.Size = result.Size
+			opts.State.Put(fileURL, entry)
+		}
+	}
+}
+
 // downloadOnce performs a single download attempt
 func (d *Downloader) downloadOnce(ctx context.Context, fileURL string, opts DownloadOptions, result *DownloadResult) error {
 	// Validate URL
@@ -167,9 +230,39 @@ func (d *Downloader) downloadOnce(ctx context.Context, fileURL string, opts Down
 	filePath := filepath.Join(opts.OutputDir, filename)
 	result.FilePath = filePath
 
+	// In incremental mode, revalidate a previously completed file instead of resuming it
+	var prev StateEntry
+	var havePrev bool
+	if opts.State != nil {
+		prev, havePrev = opts.State.Get(fileURL)
+		if havePrev {
+			if _, err := os.Stat(prev.File); err != nil {
+				havePrev = false
+			} else {
+				filePath = prev.File
+				result.FilePath = filePath
+			}
+		}
+	}
+
+	// A fresh download may have been saved under a corrected extension;
+	// resume that file, not one named after the URL
+	if opts.Filename == "" && !havePrev {
+		if _, err := os.Stat(filePath); os.IsNotExist(err) {
+			for _, name := range correctedNames(filename) {
+				if _, err := os.Stat(filepath.Join(opts.OutputDir, name)); err == nil {
+					filename = name
+					filePath = filepath.Join(opts.OutputDir, name)
+					result.FilePath = filePath
+					break
+				}
+			}
+		}
+	}
+
 	// Check for existing file to support resume
 	var startByte int64
-	if info, err := os.Stat(filePath); err == nil && !info.IsDir() {
+	if info, err := os.Stat(filePath); err == nil && !info.IsDir() && !havePrev {
 		startByte = info.Size()
 	}
 
@@ -190,6 +283,16 @@ func (d *Downloader) downloadOnce(ctx context.Context, fileURL string, opts Down
 		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", startByte))
 	}
 
+	// Add conditional headers from the previous run
+	if havePrev {
+		if prev.ETag != "" {
+			req.Header.Set("If-None-Match", prev.ETag)
+		}
+		if prev.LastModified != "" {
+			req.Header.Set("If-Modified-Since", prev.LastModified)
+		}
+	}
+
 	// Execute request
 	resp, err := d.client.Do(req)
 	if err != nil {
@@ -200,15 +303,38 @@ func (d *Downloader) downloadOnce(ctx context.Context, fileURL string, opts Down
 		}
 	}
 	defer resp.Body.Close()
+	result.StatusCode = resp.StatusCode
 
 	// Handle response status
 	var outFile *os.File
 	var appendMode bool
+	var tmpPath string
 
 	switch resp.StatusCode {
+	case http.StatusNotModified:
+		// Incremental mode: nothing changed since the last run
+		result.Size = prev.Size
+		result.Success = true
+		result.Unchanged = true
+		return nil
 	case http.StatusOK:
-		// Server doesn't support range or file didn't exist, overwrite
-		outFile, err = os.Create(filePath)
+		// Fix up the extension from the Content-Type for fresh downloads
+		if opts.Filename == "" && !havePrev {
+			filePath = filepath.Join(opts.OutputDir, correctExtension(filename, resp.Header.Get("Content-Type")))
+			result.FilePath = filePath
+		}
+		if havePrev {
+			// Keep the previous good file until the new body is complete, so a
+			// failed copy can't leave a truncated file behind the old validators
+			outFile, err = os.CreateTemp(filepath.Dir(filePath), "."+filepath.Base(filePath)+".*.part")
+			if err == nil {
+				tmpPath = outFile.Name()
+				err = outFile.Chmod(0644)
+			}
+		} else {
+			// Server doesn't support range or file didn't exist, overwrite
+			outFile, err = os.Create(filePath)
+		}
 		appendMode = false
 	case http.StatusPartialContent:
 		// Server supports range, append
@@ -218,6 +344,23 @@ func (d *Downloader) downloadOnce(ctx context.Context, fileURL string, opts Down
 		// File is likely already complete
 		result.Size = startByte
 		result.Success = true
+		if opts.State != nil {
+			// Record it, so the next incremental run revalidates it instead
+			// of asking for a range again
+			sum, err := hashFile(filePath)
+			if err != nil {
+				log.Warn().Err(err).Str("file", filePath).Msg("Failed to hash existing file; not recorded in the state")
+				return nil
+			}
+			opts.State.Put(fileURL, StateEntry{
+				File:         filePath,
+				ETag:         resp.Header.Get("ETag"),
+				LastModified: resp.Header.Get("Last-Modified"),
+				SHA256:       sum,
+				Size:         startByte,
+				FetchedAt:    time.Now(),
+			})
+		}
 		return nil
 	default:
 		// Read snippet of body for context
@@ -229,6 +372,7 @@ func (d *Downloader) downloadOnce(ctx context.Context, fileURL string, opts Down
 			StatusCode:  resp.StatusCode,
 			Message:     resp.Status,
 			BodySnippet: string(snippet[:n]),
+			Wait:        parseRetryAfter(resp.Header.Get("Retry-After")),
 		}
 	}
 
@@ -244,14 +388,38 @@ func (d *Downloader) downloadOnce(ctx context.Context, fileURL string, opts Down
 	// Stream to disk
 	buf := bufferPool.Get().(*[]byte)
 	defer bufferPool.Put(buf)
-	bytesWritten, err := io.CopyBuffer(outFile, resp.Body, *buf)
+	var dst io.Writer = outFile
+	hasher := sha256.New()
+	if opts.State != nil && !appendMode {
+		dst = io.MultiWriter(outFile, hasher)
+	}
+	result.ContentType = resp.Header.Get("Content-Type")
+	bytesWritten, err := io.CopyBuffer(dst, resp.Body, *buf)
 	if err != nil {
+		if tmpPath != "" {
+			outFile.Close()
+			os.Remove(tmpPath)
+		}
 		return &DownloadError{
 			URL:        fileURL,
 			Message:    "failed to write file",
 			Underlying: err,
 		}
 	}
+	if tmpPath != "" {
+		err = outFile.Close()
+		if err == nil {
+			err = os.Rename(tmpPath, filePath)
+		}
+		if err != nil {
+			os.Remove(tmpPath)
+			return &DownloadError{
+				URL:        fileURL,
+				Message:    "failed to replace file",
+				Underlying: err,
+			}
+		}
+	}
 
 	result.Size = bytesWritten
 	if appendMode {
@@ -259,6 +427,22 @@ func (d *Downloader) downloadOnce(ctx context.Context, fileURL string, opts Down
 	}
 	result.Success = true
 
+	// Remember validators and content hash for the next incremental run
+	if opts.State != nil {
+		entry := StateEntry{
+			File:         filePath,
+			ETag:         resp.Header.Get("ETag"),
+			LastModified: resp.Header.Get("Last-Modified"),
+			Size:         result.Size,
+			FetchedAt:    time.Now(),
+		}
+		if !appendMode {
+			entry.SHA256 = hex.EncodeToString(hasher.Sum(nil))
+			result.Unchanged = havePrev && entry.SHA256 == prev.SHA256
+		}
+		opts.State.Put(fileURL, entry)
+	}
+
 	log.Debug().
 		Str("url", fileURL).
 		Str("file", filePath).
@@ -268,6 +452,36 @@ func (d *Downloader) downloadOnce(ctx context.Context, fileURL string, opts Down
 	return nil
 }
 
+// hashFile returns the hex SHA-256 of the file at path
+func hashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+// parseRetryAfter parses a Retry-After header given in seconds or as an HTTP date
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(strings.TrimSpace(value)); err == nil && secs > 0 {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		if wait := time.Until(t); wait > 0 {
+			return wait
+		}
+	}
+	return 0
+}
+
 // sanitizeFilename prevents path traversal attacks
 func sanitizeFilename(input string, u *url.URL) string {
 	// Extract filename from URL
@@ -1,14 +1,23 @@
 package downloader
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"os"
+	"path/filepath"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
+
+	"github.com/law-makers/crawl/internal/budget"
 )
 
 func TestDownload_Success(t *testing.T) {
@@ -41,6 +50,322 @@ func TestDownload_Success(t *testing.T) {
 	}
 }
 
+func TestDownload_RetriesTransientStatus(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	dl := NewDownloader(10*time.Second, "Test/1.0")
+	cfg := DefaultRetryConfig()
+	cfg.InitialBackoff = time.Millisecond
+	dl.SetRetryConfig(cfg)
+
+	result := dl.Download(context.Background(), server.URL+"/retry.txt", DownloadOptions{OutputDir: t.TempDir()})
+	if !result.Success {
+		t.Fatalf("Download failed: %v", result.Error)
+	}
+	if got := atomic.LoadInt32(&attempts); got != 3 {
+		t.Errorf("Expected 3 attempts, got %d", got)
+	}
+}
+
+func TestDownload_DoesNotRetryNotFound(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	dl := NewDownloader(10*time.Second, "Test/1.0")
+	result := dl.Download(context.Background(), server.URL+"/missing.txt", DownloadOptions{OutputDir: t.TempDir()})
+	if result.Success {
+		t.Fatal("Expected download to fail")
+	}
+	if got := atomic.LoadInt32(&attempts); got != 1 {
+		t.Errorf("Expected 1 attempt, got %d", got)
+	}
+}
+
+func TestDownload_CorrectsExtensionFromContentType(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/webp")
+		w.Write([]byte("RIFF"))
+	}))
+	defer server.Close()
+
+	dl := NewDownloader(10*time.Second, "Test/1.0")
+	result := dl.Download(context.Background(), server.URL+"/photo.jpg", DownloadOptions{OutputDir: t.TempDir()})
+	if !result.Success {
+		t.Fatalf("Download failed: %v", result.Error)
+	}
+	if !strings.HasSuffix(result.FilePath, "photo.webp") {
+		t.Errorf("Expected photo.webp, got %s", result.FilePath)
+	}
+}
+
+func TestDownload_ResumesCorrectedExtension(t *testing.T) {
+	content := []byte("0123456789abcdefghij")
+	var ranges []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ranges = append(ranges, r.Header.Get("Range"))
+		w.Header().Set("Content-Type", "image/webp")
+		http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(content))
+	}))
+	defer server.Close()
+
+	// An interrupted first run left half the file under its corrected name
+	tempDir := t.TempDir()
+	partial := filepath.Join(tempDir, "photo.webp")
+	if err := os.WriteFile(partial, content[:10], 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	dl := NewDownloader(10*time.Second, "Test/1.0")
+	opts := DownloadOptions{OutputDir: tempDir}
+	second := dl.Download(context.Background(), server.URL+"/photo.jpg", opts)
+	if !second.Success {
+		t.Fatalf("Download failed: %v", second.Error)
+	}
+	if second.FilePath != partial || second.StatusCode != http.StatusPartialContent {
+		t.Errorf("Expected %s to be resumed, got %s with status %d", partial, second.FilePath, second.StatusCode)
+	}
+
+	// A run after it finds the file complete
+	third := dl.Download(context.Background(), server.URL+"/photo.jpg", opts)
+	if !third.Success || third.StatusCode != http.StatusRequestedRangeNotSatisfiable {
+		t.Errorf("Expected the complete file to be skipped, got success=%v status=%d err=%v", third.Success, third.StatusCode, third.Error)
+	}
+	if third.Size != int64(len(content)) {
+		t.Errorf("Expected size %d, got %d", len(content), third.Size)
+	}
+
+	data, err := os.ReadFile(partial)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, content) {
+		t.Errorf("Expected %q, got %q", content, data)
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, "photo.jpg")); !os.IsNotExist(err) {
+		t.Errorf("Expected no file under the uncorrected name, got %v", err)
+	}
+	if want := []string{"bytes=10-", "bytes=20-"}; strings.Join(ranges, ",") != strings.Join(want, ",") {
+		t.Errorf("Expected ranges %v, got %v", want, ranges)
+	}
+}
+
+func TestDownload_PostProcess(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("original"))
+	}))
+	defer server.Close()
+
+	dl := NewDownloader(10*time.Second, "Test/1.0")
+	result := dl.Download(context.Background(), server.URL+"/photo.png", DownloadOptions{
+		OutputDir: t.TempDir(),
+		PostProcess: func(path string) (string, error) {
+			out := strings.TrimSuffix(path, ".png") + ".webp"
+			if err := os.WriteFile(out, []byte("converted!"), 0644); err != nil {
+				return path, err
+			}
+			return out, os.Remove(path)
+		},
+	})
+
+	if !result.Success {
+		t.Fatalf("Download failed: %v", result.Error)
+	}
+	if !strings.HasSuffix(result.FilePath, ".webp") || result.Size != int64(len("converted!")) {
+		t.Errorf("Expected the result to describe the processed file, got %s (%d bytes)", result.FilePath, result.Size)
+	}
+}
+
+func TestCorrectExtension(t *testing.T) {
+	cases := []struct {
+		name, contentType, want string
+	}{
+		{"photo.jpg", "image/jpeg", "photo.jpg"},
+		{"photo.jpeg", "image/jpeg", "photo.jpeg"},
+		{"photo", "image/png", "photo.png"},
+		{"image.php", "image/gif", "image.gif"},
+		{"clip.bin", "video/mp4; codecs=avc1", "clip.mp4"},
+		{"file.dat", "application/octet-stream", "file.dat"},
+		{"page.jpg", "text/html; charset=utf-8", "page.jpg"},
+		{"noheader.jpg", "", "noheader.jpg"},
+	}
+	for _, c := range cases {
+		if got := correctExtension(c.name, c.contentType); got != c.want {
+			t.Errorf("correctExtension(%q, %q) = %q, want %q", c.name, c.contentType, got, c.want)
+		}
+	}
+}
+
+func TestDownload_IncrementalSkipsUnchanged(t *testing.T) {
+	var served int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		atomic.AddInt32(&served, 1)
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte("content"))
+	}))
+	defer server.Close()
+
+	tempDir := t.TempDir()
+	state, err := LoadState(tempDir + "/" + StateFileName)
+	if err != nil {
+		t.Fatalf("LoadState failed: %v", err)
+	}
+	dl := NewDownloader(10*time.Second, "Test/1.0")
+	opts := DownloadOptions{OutputDir: tempDir, State: state}
+
+	first := dl.Download(context.Background(), server.URL+"/file.txt", opts)
+	if !first.Success || first.Unchanged {
+		t.Fatalf("Expected fresh download, got success=%v unchanged=%v err=%v", first.Success, first.Unchanged, first.Error)
+	}
+
+	second := dl.Download(context.Background(), server.URL+"/file.txt", opts)
+	if !second.Success || !second.Unchanged {
+		t.Fatalf("Expected unchanged download, got success=%v unchanged=%v err=%v", second.Success, second.Unchanged, second.Error)
+	}
+	if got := atomic.LoadInt32(&served); got != 1 {
+		t.Errorf("Expected body to be served once, got %d", got)
+	}
+}
+
+func TestDownload_IncrementalRecordsCompleteFile(t *testing.T) {
+	content := []byte("content")
+	var conditional int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			atomic.AddInt32(&conditional, 1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		if r.Header.Get("Range") == fmt.Sprintf("bytes=%d-", len(content)) {
+			w.WriteHeader(http.StatusRequestedRangeNotSatisfiable)
+			return
+		}
+		w.Write(content)
+	}))
+	defer server.Close()
+
+	// A complete file from a run that kept no state
+	tempDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tempDir, "file.txt"), content, 0644); err != nil {
+		t.Fatal(err)
+	}
+	state, err := LoadState(tempDir + "/" + StateFileName)
+	if err != nil {
+		t.Fatalf("LoadState failed: %v", err)
+	}
+	dl := NewDownloader(10*time.Second, "Test/1.0")
+	opts := DownloadOptions{OutputDir: tempDir, State: state}
+
+	first := dl.Download(context.Background(), server.URL+"/file.txt", opts)
+	if !first.Success || first.StatusCode != http.StatusRequestedRangeNotSatisfiable {
+		t.Fatalf("Expected the complete file to be skipped, got success=%v status=%d err=%v", first.Success, first.StatusCode, first.Error)
+	}
+	entry, ok := state.Get(server.URL + "/file.txt")
+	if !ok {
+		t.Fatal("Expected the complete file to be recorded in the state")
+	}
+	sum := sha256.Sum256(content)
+	if entry.ETag != `"v1"` || entry.SHA256 != hex.EncodeToString(sum[:]) || entry.Size != int64(len(content)) {
+		t.Errorf("Unexpected state entry: %+v", entry)
+	}
+
+	second := dl.Download(context.Background(), server.URL+"/file.txt", opts)
+	if !second.Success || !second.Unchanged || atomic.LoadInt32(&conditional) != 1 {
+		t.Errorf("Expected a conditional request to find the file unchanged, got success=%v unchanged=%v err=%v", second.Success, second.Unchanged, second.Error)
+	}
+}
+
+func TestDownload_IncrementalKeepsFileOnFailedUpdate(t *testing.T) {
+	var updated int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(&updated) == 0 {
+			w.Header().Set("ETag", `"v1"`)
+			w.Write([]byte("content"))
+			return
+		}
+		// Promise more than is sent so the copy fails mid-body
+		w.Header().Set("ETag", `"v2"`)
+		w.Header().Set("Content-Length", "100")
+		w.Write([]byte("new"))
+	}))
+	defer server.Close()
+
+	tempDir := t.TempDir()
+	state, err := LoadState(tempDir + "/" + StateFileName)
+	if err != nil {
+		t.Fatalf("LoadState failed: %v", err)
+	}
+	dl := NewDownloader(10*time.Second, "Test/1.0")
+	cfg := DefaultRetryConfig()
+	cfg.InitialBackoff = time.Millisecond
+	dl.SetRetryConfig(cfg)
+	opts := DownloadOptions{OutputDir: tempDir, State: state}
+
+	first := dl.Download(context.Background(), server.URL+"/file.txt", opts)
+	if !first.Success {
+		t.Fatalf("First download failed: %v", first.Error)
+	}
+
+	atomic.StoreInt32(&updated, 1)
+	second := dl.Download(context.Background(), server.URL+"/file.txt", opts)
+	if second.Success {
+		t.Fatal("Expected the truncated update to fail")
+	}
+
+	data, err := os.ReadFile(first.FilePath)
+	if err != nil {
+		t.Fatalf("Failed to read file: %v", err)
+	}
+	if string(data) != "content" {
+		t.Errorf("Previous file was modified: got %q", string(data))
+	}
+	if entry, _ := state.Get(server.URL + "/file.txt"); entry.ETag != `"v1"` {
+		t.Errorf("Expected state to keep the old ETag, got %q", entry.ETag)
+	}
+	entries, _ := os.ReadDir(tempDir)
+	for _, e := range entries {
+		if strings.HasSuffix(e.Name(), ".part") {
+			t.Errorf("Temporary file left behind: %s", e.Name())
+		}
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	if d := parseRetryAfter("5"); d != 5*time.Second {
+		t.Errorf("Expected 5s, got %v", d)
+	}
+	if d := parseRetryAfter(""); d != 0 {
+		t.Errorf("Expected 0, got %v", d)
+	}
+	if d := parseRetryAfter("garbage"); d != 0 {
+		t.Errorf("Expected 0, got %v", d)
+	}
+	date := time.Now().Add(30 * time.Second).UTC().Format(http.TimeFormat)
+	if d := parseRetryAfter(date); d < 28*time.Second || d > 30*time.Second {
+		t.Errorf("Expected about 30s from an HTTP date, got %v", d)
+	}
+	past := time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat)
+	if d := parseRetryAfter(past); d != 0 {
+		t.Errorf("Expected 0 for a date in the past, got %v", d)
+	}
+}
+
 func TestSanitizeFilename_Security(t *testing.T) {
 	dangerous := []string{
 		"../../etc/passwd",
@@ -98,6 +423,61 @@ func TestWorkerPool_Concurrency(t *testing.T) {
 	}
 }
 
+func TestWorkerPool_RateLimitPerHost(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("data"))
+	}))
+	defer server.Close()
+
+	urls := []string{server.URL + "/1.txt", server.URL + "/2.txt", server.URL + "/3.txt"}
+
+	pool := NewWorkerPool(3, 10*time.Second, "Test/1.0")
+	pool.SetRateLimit(20, 1) // one request every 50ms
+
+	start := time.Now()
+	pool.DownloadBatch(context.Background(), urls, DownloadOptions{OutputDir: t.TempDir()})
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+		t.Errorf("Expected rate limiting to space out downloads, finished in %v", elapsed)
+	}
+
+	pool.SetRateLimit(0, 0)
+	if pool.rateLimiter != nil {
+		t.Error("Expected rate limiting to be disabled")
+	}
+}
+
+func TestWorkerPool_BudgetSkipsRemaining(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("data"))
+	}))
+	defer server.Close()
+
+	var urls []string
+	for i := 0; i < 5; i++ {
+		urls = append(urls, fmt.Sprintf("%s/%d.txt", server.URL, i))
+	}
+
+	pool := NewWorkerPool(1, 10*time.Second, "Test/1.0")
+	pool.SetBudget(budget.Budget{MaxRequests: 2})
+	results := pool.DownloadBatch(context.Background(), urls, DownloadOptions{OutputDir: t.TempDir()})
+
+	success, skipped := 0, 0
+	for _, r := range results {
+		switch {
+		case r.Success:
+			success++
+		case r.Skipped:
+			skipped++
+			if !errors.Is(r.Error, budget.ErrExceeded) {
+				t.Errorf("Expected budget error, got %v", r.Error)
+			}
+		}
+	}
+	if success != 2 || skipped != 3 {
+		t.Errorf("Expected 2 downloaded and 3 skipped, got %d and %d", success, skipped)
+	}
+}
+
 func TestWorkerPool_RecoversFromWorkerPanic(t *testing.T) {
 	// Create a pool with a nil downloader to force a nil pointer deref panic inside the worker
 	pool := &WorkerPool{
@@ -148,3 +528,37 @@ func TestHashString_Length(t *testing.T) {
 		}
 	}
 }
+
+// countingTransport counts round trips made through the shared transport
+type countingTransport struct {
+	calls int32
+}
+
+func (c *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	atomic.AddInt32(&c.calls, 1)
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestWorkerPool_SetClientSharesTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("data"))
+	}))
+	defer server.Close()
+
+	shared := &countingTransport{}
+	pool := NewWorkerPool(2, 10*time.Second, "Test/1.0")
+	pool.SetRateLimit(0, 0)
+	pool.SetClient(&http.Client{Transport: shared})
+
+	results := pool.DownloadBatch(context.Background(), []string{server.URL + "/1.txt", server.URL + "/2.txt"}, DownloadOptions{
+		OutputDir: t.TempDir(),
+	})
+	for _, result := range results {
+		if !result.Success {
+			t.Errorf("Download failed: %v", result.Error)
+		}
+	}
+	if got := atomic.LoadInt32(&shared.calls); got != 2 {
+		t.Errorf("Expected 2 requests through the shared transport, got %d", got)
+	}
+}
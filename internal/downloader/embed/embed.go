@@ -0,0 +1,216 @@
+// internal/downloader/embed/embed.go
+package embed
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Provider recognizes one kind of embedded player and resolves it into a
+// video. YouTube, Vimeo, JW Player and Video.js are built in; others
+// register themselves with Register.
+type Provider interface {
+	// Name returns the name the provider is registered under
+	Name() string
+
+	// Find returns the players of this kind on a page
+	Find(doc *goquery.Document, base *url.URL) []Embed
+
+	// Resolve turns an embed into stream URLs, or at least metadata
+	Resolve(ctx context.Context, client *http.Client, e Embed) (*Video, error)
+}
+
+// Embed is a player found on a page
+type Embed struct {
+	Provider string
+	URL      string // Player or iframe URL
+	ID       string // Provider's video ID, if known
+	Config   string // Inline player configuration, for self-hosted players
+}
+
+// Stream is a downloadable rendition of a video
+type Stream struct {
+	URL     string `json:"url"`
+	Type    string `json:"type,omitempty"` // MIME type, or "hls"/"dash" for manifests
+	Quality string `json:"quality,omitempty"`
+	Width   int    `json:"width,omitempty"`
+	Height  int    `json:"height,omitempty"`
+}
+
+// Video is what a provider knows about an embedded video
+type Video struct {
+	Provider  string   `json:"provider"`
+	ID        string   `json:"id,omitempty"`
+	EmbedURL  string   `json:"embed_url"`
+	PageURL   string   `json:"page_url,omitempty"` // Canonical watch page
+	Title     string   `json:"title,omitempty"`
+	Author    string   `json:"author,omitempty"`
+	Duration  int      `json:"duration,omitempty"` // Seconds
+	Thumbnail string   `json:"thumbnail,omitempty"`
+	Streams   []Stream `json:"streams,omitempty"`
+}
+
+// Best returns the stream to download: the largest progressive file, or a
+// manifest when there is nothing else. ok is false when the provider only
+// gave metadata.
+func (v *Video) Best() (Stream, bool) {
+	var best Stream
+	found := false
+	for _, s := range v.Streams {
+		if !found {
+			best, found = s, true
+			continue
+		}
+		if isManifest(best) != isManifest(s) {
+			if isManifest(best) {
+				best = s
+			}
+			continue
+		}
+		if s.Height > best.Height {
+			best = s
+		}
+	}
+	return best, found
+}
+
+func isManifest(s Stream) bool {
+	return s.Type == "hls" || s.Type == "dash"
+}
+
+var (
+	providersMu sync.RWMutex
+	providers   = map[string]Provider{}
+)
+
+// Register makes a provider available. It is intended to be called from
+// init functions; registering a name twice panics.
+func Register(p Provider) {
+	providersMu.Lock()
+	defer providersMu.Unlock()
+	name := strings.ToLower(p.Name())
+	if _, exists := providers[name]; exists {
+		panic("embed: provider registered twice: " + name)
+	}
+	providers[name] = p
+}
+
+// Providers returns the registered provider names, sorted
+func Providers() []string {
+	providersMu.RLock()
+	defer providersMu.RUnlock()
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Find returns the embedded players on a page that any provider recognizes,
+// without duplicates
+func Find(html, baseURL string) ([]Embed, error) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse HTML: %w", err)
+	}
+	base, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base URL: %w", err)
+	}
+
+	var embeds []Embed
+	seen := map[string]bool{}
+	for _, name := range Providers() {
+		providersMu.RLock()
+		p := providers[name]
+		providersMu.RUnlock()
+		for _, e := range p.Find(doc, base) {
+			e.Provider = name
+			key := name + "|" + e.ID + "|" + e.URL + "|" + e.Config
+			if !seen[key] {
+				seen[key] = true
+				embeds = append(embeds, e)
+			}
+		}
+	}
+	return embeds, nil
+}
+
+// Resolve resolves an embed with the provider that found it
+func Resolve(ctx context.Context, client *http.Client, e Embed) (*Video, error) {
+	providersMu.RLock()
+	p, ok := providers[strings.ToLower(e.Provider)]
+	providersMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown embed provider %q (available: %s)", e.Provider, strings.Join(Providers(), ", "))
+	}
+	return p.Resolve(ctx, client, e)
+}
+
+// iframeSources returns the resolved src of every iframe and embed element
+func iframeSources(doc *goquery.Document, base *url.URL) []*url.URL {
+	var srcs []*url.URL
+	doc.Find("iframe[src], iframe[data-src], embed[src]").Each(func(_ int, s *goquery.Selection) {
+		src, ok := s.Attr("src")
+		if !ok || src == "" || strings.HasPrefix(src, "about:") {
+			src, _ = s.Attr("data-src") // lazy-loaded iframes
+		}
+		if src == "" {
+			return
+		}
+		if u, err := base.Parse(strings.TrimSpace(src)); err == nil {
+			srcs = append(srcs, u)
+		}
+	})
+	return srcs
+}
+
+// getJSON fetches rawURL and decodes its JSON body into v
+func getJSON(ctx context.Context, client *http.Client, rawURL string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status %d from %s", resp.StatusCode, rawURL)
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 10<<20)).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// streamType guesses a stream's type from its URL
+func streamType(rawURL string) string {
+	path := strings.ToLower(rawURL)
+	if i := strings.IndexAny(path, "?#"); i >= 0 {
+		path = path[:i]
+	}
+	switch {
+	case strings.HasSuffix(path, ".m3u8"):
+		return "hls"
+	case strings.HasSuffix(path, ".mpd"):
+		return "dash"
+	case strings.HasSuffix(path, ".webm"):
+		return "video/webm"
+	case strings.HasSuffix(path, ".mp4"), strings.HasSuffix(path, ".m4v"):
+		return "video/mp4"
+	}
+	return ""
+}
@@ -0,0 +1,170 @@
+package embed
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+const testPage = `<html><head><script>
+jwplayer("player").setup({
+  file: "/media/trailer.m3u8",
+  image: "/media/poster.jpg",
+  title: "Trailer"
+});
+</script></head><body>
+<iframe src="https://www.youtube-nocookie.com/embed/dQw4w9WgXcQ?rel=0"></iframe>
+<iframe data-src="https://player.vimeo.com/video/76979871?h=8272103f6e"></iframe>
+<iframe src="https://cdn.jwplayer.com/players/abcd1234-efgh5678.html"></iframe>
+<iframe src="https://example.com/widget"></iframe>
+<video-js id="clip" poster="/clip.jpg" data-setup='{"sources":[{"src":"/clip/master.m3u8","type":"application/x-mpegURL"}]}'>
+  <source src="/clip/720.mp4" type="video/mp4">
+</video-js>
+</body></html>`
+
+func TestFind(t *testing.T) {
+	embeds, err := Find(testPage, "https://example.com/watch")
+	if err != nil {
+		t.Fatalf("Find failed: %v", err)
+	}
+
+	want := map[string]string{
+		"youtube":  "dQw4w9WgXcQ",
+		"vimeo":    "76979871",
+		"jwplayer": "abcd1234",
+		"videojs":  "clip",
+	}
+	counts := map[string]int{}
+	for _, e := range embeds {
+		counts[e.Provider]++
+		if id, ok := want[e.Provider]; ok && e.ID == id {
+			delete(want, e.Provider)
+		}
+	}
+	for provider, id := range want {
+		t.Errorf("Expected a %s embed with ID %q, got %+v", provider, id, embeds)
+	}
+	// The hosted iframe plus the inline setup()
+	if counts["jwplayer"] != 2 {
+		t.Errorf("Expected 2 jwplayer embeds, got %d", counts["jwplayer"])
+	}
+	if len(embeds) != 5 {
+		t.Errorf("Expected 5 embeds, got %d: %+v", len(embeds), embeds)
+	}
+}
+
+func TestResolve_Vimeo(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/video/76979871/config" || r.URL.Query().Get("h") != "8272103f6e" {
+			t.Errorf("Unexpected config request %s", r.URL)
+		}
+		w.Write([]byte(`{
+			"request": {"files": {
+				"progressive": [
+					{"url": "https://vod.example/360.mp4", "quality": "360p", "width": 640, "height": 360},
+					{"url": "https://vod.example/1080.mp4", "quality": "1080p", "width": 1920, "height": 1080}
+				],
+				"hls": {"default_cdn": "akfire", "cdns": {"akfire": {"url": "https://vod.example/master.m3u8"}}}
+			}},
+			"video": {"title": "The New Vimeo Player", "duration": 62, "owner": {"name": "Vimeo Staff"}, "thumbs": {"base": "https://i.vimeocdn.com/1"}}
+		}`))
+	}))
+	defer server.Close()
+	defer func(orig string) { vimeoConfigURL = orig }(vimeoConfigURL)
+	vimeoConfigURL = server.URL + "/video/%s/config"
+
+	v, err := Resolve(context.Background(), server.Client(), Embed{Provider: "vimeo", ID: "76979871", URL: "https://player.vimeo.com/video/76979871?h=8272103f6e"})
+	if err != nil {
+		t.Fatalf("Resolve failed: %v", err)
+	}
+	if v.Title != "The New Vimeo Player" || v.Author != "Vimeo Staff" || v.Duration != 62 {
+		t.Errorf("Unexpected metadata: %+v", v)
+	}
+	if len(v.Streams) != 3 {
+		t.Fatalf("Expected 3 streams, got %+v", v.Streams)
+	}
+	if best, ok := v.Best(); !ok || best.URL != "https://vod.example/1080.mp4" {
+		t.Errorf("Expected the 1080p file as the best stream, got %+v", best)
+	}
+}
+
+func TestResolve_JWPlayerFeed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"playlist": [{"mediaid": "abcd1234", "title": "Launch", "duration": 95.4,
+			"sources": [
+				{"file": "https://cdn.example/abcd1234.m3u8", "type": "application/vnd.apple.mpegurl"},
+				{"file": "https://cdn.example/abcd1234-720.mp4", "type": "video/mp4", "label": "720p", "height": 720}
+			]}]}`))
+	}))
+	defer server.Close()
+	defer func(orig string) { jwMediaURL = orig }(jwMediaURL)
+	jwMediaURL = server.URL + "/v2/media/"
+
+	v, err := Resolve(context.Background(), server.Client(), Embed{Provider: "jwplayer", ID: "abcd1234"})
+	if err != nil {
+		t.Fatalf("Resolve failed: %v", err)
+	}
+	if v.Title != "Launch" || v.Duration != 95 {
+		t.Errorf("Unexpected metadata: %+v", v)
+	}
+	if best, ok := v.Best(); !ok || best.Quality != "720p" {
+		t.Errorf("Expected the progressive file over the manifest, got %+v", best)
+	}
+}
+
+func TestResolve_InlinePlayers(t *testing.T) {
+	embeds, err := Find(testPage, "https://example.com/watch")
+	if err != nil {
+		t.Fatalf("Find failed: %v", err)
+	}
+	for _, e := range embeds {
+		if e.Config == "" {
+			continue
+		}
+		v, err := Resolve(context.Background(), http.DefaultClient, e)
+		if err != nil {
+			t.Fatalf("Resolve(%s) failed: %v", e.Provider, err)
+		}
+		best, ok := v.Best()
+		switch e.Provider {
+		case "jwplayer":
+			if !ok || best.URL != "https://example.com/media/trailer.m3u8" || best.Type != "hls" || v.Title != "Trailer" {
+				t.Errorf("Unexpected JW Player video: %+v", v)
+			}
+		case "videojs":
+			if !ok || best.URL != "https://example.com/clip/720.mp4" || v.Thumbnail != "https://example.com/clip.jpg" {
+				t.Errorf("Unexpected Video.js video: %+v", v)
+			}
+		}
+	}
+}
+
+func TestResolve_YouTubeMetadataOnly(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("url") != "https://www.youtube.com/watch?v=dQw4w9WgXcQ" {
+			t.Errorf("Unexpected oEmbed request %s", r.URL)
+		}
+		w.Write([]byte(`{"title": "Never Gonna Give You Up", "author_name": "Rick Astley"}`))
+	}))
+	defer server.Close()
+	defer func(orig string) { youtubeOEmbedURL = orig }(youtubeOEmbedURL)
+	youtubeOEmbedURL = server.URL
+
+	v, err := Resolve(context.Background(), server.Client(), Embed{Provider: "youtube", ID: "dQw4w9WgXcQ"})
+	if err != nil {
+		t.Fatalf("Resolve failed: %v", err)
+	}
+	if v.Title != "Never Gonna Give You Up" || v.PageURL != "https://www.youtube.com/watch?v=dQw4w9WgXcQ" {
+		t.Errorf("Unexpected metadata: %+v", v)
+	}
+	if _, ok := v.Best(); ok {
+		t.Error("Expected no downloadable stream for YouTube")
+	}
+}
+
+func TestResolve_UnknownProvider(t *testing.T) {
+	if _, err := Resolve(context.Background(), http.DefaultClient, Embed{Provider: "nope"}); err == nil {
+		t.Error("Expected an error for an unknown provider")
+	}
+}
@@ -0,0 +1,135 @@
+// internal/downloader/embed/jwplayer.go
+package embed
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// jwMediaURL is the JW Platform delivery API for a hosted video
+var jwMediaURL = "https://cdn.jwplayer.com/v2/media/"
+
+var (
+	jwPlayerPath = regexp.MustCompile(`^/players/([A-Za-z0-9]{8})(?:-[A-Za-z0-9]{8})?\.(?:html|js)$`)
+	jwSetup      = regexp.MustCompile(`(?s)jwplayer\([^)]*\)\s*\.setup\(\s*(\{.*?\})\s*\)`)
+	jwFile       = regexp.MustCompile(`["']?file["']?\s*:\s*["']([^"']+)["']`)
+	jwPlaylist   = regexp.MustCompile(`["']?playlist["']?\s*:\s*["']([^"']+)["']`)
+	jwTitle      = regexp.MustCompile(`["']?title["']?\s*:\s*["']([^"']+)["']`)
+	jwImage      = regexp.MustCompile(`["']?image["']?\s*:\s*["']([^"']+)["']`)
+)
+
+// jwplayer resolves hosted JW Player iframes and self-hosted
+// jwplayer().setup() configs
+type jwplayer struct{}
+
+func init() {
+	Register(jwplayer{})
+}
+
+func (jwplayer) Name() string { return "jwplayer" }
+
+func (jwplayer) Find(doc *goquery.Document, base *url.URL) []Embed {
+	var embeds []Embed
+	for _, u := range iframeSources(doc, base) {
+		host := strings.ToLower(u.Hostname())
+		if host != "cdn.jwplayer.com" && host != "content.jwplatform.com" {
+			continue
+		}
+		if m := jwPlayerPath.FindStringSubmatch(u.Path); m != nil {
+			embeds = append(embeds, Embed{URL: u.String(), ID: m[1]})
+		}
+	}
+	doc.Find("script").Each(func(_ int, s *goquery.Selection) {
+		for _, m := range jwSetup.FindAllStringSubmatch(s.Text(), -1) {
+			embeds = append(embeds, Embed{URL: base.String(), Config: m[1]})
+		}
+	})
+	return embeds
+}
+
+func (jwplayer) Resolve(ctx context.Context, client *http.Client, e Embed) (*Video, error) {
+	v := &Video{Provider: "jwplayer", ID: e.ID, EmbedURL: e.URL}
+	if e.Config == "" {
+		return v, resolveJWFeed(ctx, client, jwMediaURL+e.ID, v)
+	}
+
+	base, err := url.Parse(e.URL)
+	if err != nil {
+		return v, err
+	}
+	if m := jwTitle.FindStringSubmatch(e.Config); m != nil {
+		v.Title = m[1]
+	}
+	if m := jwImage.FindStringSubmatch(e.Config); m != nil {
+		v.Thumbnail = resolveAgainst(base, m[1])
+	}
+	for _, m := range jwFile.FindAllStringSubmatch(e.Config, -1) {
+		if src := resolveAgainst(base, m[1]); src != "" {
+			v.Streams = append(v.Streams, Stream{URL: src, Type: streamType(src)})
+		}
+	}
+	// A playlist can also be a feed URL instead of an inline list
+	if m := jwPlaylist.FindStringSubmatch(e.Config); m != nil {
+		if feed := resolveAgainst(base, m[1]); feed != "" {
+			return v, resolveJWFeed(ctx, client, feed, v)
+		}
+	}
+	return v, nil
+}
+
+// resolveJWFeed fills v from the first item of a JW Platform media feed
+func resolveJWFeed(ctx context.Context, client *http.Client, feedURL string, v *Video) error {
+	var feed struct {
+		Playlist []struct {
+			MediaID  string  `json:"mediaid"`
+			Title    string  `json:"title"`
+			Duration float64 `json:"duration"`
+			Image    string  `json:"image"`
+			Link     string  `json:"link"`
+			Sources  []struct {
+				File   string `json:"file"`
+				Type   string `json:"type"`
+				Label  string `json:"label"`
+				Width  int    `json:"width"`
+				Height int    `json:"height"`
+			} `json:"sources"`
+		} `json:"playlist"`
+	}
+	if err := getJSON(ctx, client, feedURL, &feed); err != nil {
+		return err
+	}
+	if len(feed.Playlist) == 0 {
+		return nil
+	}
+	item := feed.Playlist[0]
+	if v.ID == "" {
+		v.ID = item.MediaID
+	}
+	v.Title = item.Title
+	v.Duration = int(item.Duration)
+	v.Thumbnail = item.Image
+	v.PageURL = item.Link
+	for _, s := range item.Sources {
+		typ := s.Type
+		if typ == "application/vnd.apple.mpegurl" {
+			typ = "hls"
+		}
+		v.Streams = append(v.Streams, Stream{URL: s.File, Type: typ, Quality: s.Label, Width: s.Width, Height: s.Height})
+	}
+	return nil
+}
+
+// resolveAgainst resolves a possibly relative URL against base, returning ""
+// for anything that isn't http(s)
+func resolveAgainst(base *url.URL, ref string) string {
+	u, err := base.Parse(strings.TrimSpace(ref))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return ""
+	}
+	return u.String()
+}
@@ -0,0 +1,94 @@
+// internal/downloader/embed/videojs.go
+package embed
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// videoJSConfig is the part of a Video.js data-setup attribute that
+// describes the video
+type videoJSConfig struct {
+	Sources []videoJSSource `json:"sources"`
+	Poster  string          `json:"poster"`
+}
+
+type videoJSSource struct {
+	Src  string `json:"src"`
+	Type string `json:"type"`
+}
+
+// videojs resolves self-hosted Video.js players, whose sources are in a
+// data-setup attribute or <source> children that need no request
+type videojs struct{}
+
+func init() {
+	Register(videojs{})
+}
+
+func (videojs) Name() string { return "videojs" }
+
+func (videojs) Find(doc *goquery.Document, base *url.URL) []Embed {
+	var embeds []Embed
+	doc.Find("video-js, video.video-js, [data-setup]").Each(func(_ int, s *goquery.Selection) {
+		var config videoJSConfig
+		if setup, ok := s.Attr("data-setup"); ok && setup != "" {
+			_ = json.Unmarshal([]byte(setup), &config) // a broken config still has its <source> children
+		}
+		s.Find("source[src]").Each(func(_ int, src *goquery.Selection) {
+			config.Sources = append(config.Sources, videoJSSource{Src: src.AttrOr("src", ""), Type: src.AttrOr("type", "")})
+		})
+		if src := s.AttrOr("src", ""); src != "" {
+			config.Sources = append(config.Sources, videoJSSource{Src: src})
+		}
+		if poster := s.AttrOr("poster", ""); poster != "" && config.Poster == "" {
+			config.Poster = poster
+		}
+		if len(config.Sources) == 0 {
+			return
+		}
+		// Resolve now, while the page URL is at hand
+		for i := range config.Sources {
+			config.Sources[i].Src = resolveAgainst(base, config.Sources[i].Src)
+		}
+		if config.Poster != "" {
+			config.Poster = resolveAgainst(base, config.Poster)
+		}
+		raw, err := json.Marshal(config)
+		if err != nil {
+			return
+		}
+		embeds = append(embeds, Embed{URL: base.String(), ID: s.AttrOr("id", ""), Config: string(raw)})
+	})
+	return embeds
+}
+
+func (videojs) Resolve(_ context.Context, _ *http.Client, e Embed) (*Video, error) {
+	v := &Video{Provider: "videojs", ID: e.ID, EmbedURL: e.URL}
+	var config videoJSConfig
+	if err := json.Unmarshal([]byte(e.Config), &config); err != nil {
+		return v, fmt.Errorf("invalid Video.js config: %w", err)
+	}
+	v.Thumbnail = config.Poster
+	for _, s := range config.Sources {
+		if s.Src == "" {
+			continue
+		}
+		typ := s.Type
+		switch typ {
+		case "application/x-mpegURL", "application/vnd.apple.mpegurl":
+			typ = "hls"
+		case "application/dash+xml":
+			typ = "dash"
+		case "":
+			typ = streamType(s.Src)
+		}
+		v.Streams = append(v.Streams, Stream{URL: s.Src, Type: typ})
+	}
+	return v, nil
+}
@@ -0,0 +1,104 @@
+// internal/downloader/embed/vimeo.go
+package embed
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// vimeoConfigURL is the player config endpoint, which lists the streams
+var vimeoConfigURL = "https://player.vimeo.com/video/%s/config"
+
+var vimeoPath = regexp.MustCompile(`^/(?:video/)?(\d+)(?:/([0-9a-f]+))?/?$`)
+
+// vimeo resolves player.vimeo.com players through their config
+type vimeo struct{}
+
+func init() {
+	Register(vimeo{})
+}
+
+func (vimeo) Name() string { return "vimeo" }
+
+func (vimeo) Find(doc *goquery.Document, base *url.URL) []Embed {
+	var embeds []Embed
+	for _, u := range iframeSources(doc, base) {
+		host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+		if host != "player.vimeo.com" && host != "vimeo.com" {
+			continue
+		}
+		if m := vimeoPath.FindStringSubmatch(u.Path); m != nil {
+			embeds = append(embeds, Embed{URL: u.String(), ID: m[1]})
+		}
+	}
+	return embeds
+}
+
+func (vimeo) Resolve(ctx context.Context, client *http.Client, e Embed) (*Video, error) {
+	v := &Video{Provider: "vimeo", ID: e.ID, EmbedURL: e.URL, PageURL: "https://vimeo.com/" + e.ID}
+
+	configURL := fmt.Sprintf(vimeoConfigURL, e.ID)
+	// Unlisted videos need the hash from the embed URL
+	if u, err := url.Parse(e.URL); err == nil {
+		if h := u.Query().Get("h"); h != "" {
+			configURL += "?h=" + url.QueryEscape(h)
+		}
+	}
+
+	var config struct {
+		Request struct {
+			Files struct {
+				Progressive []struct {
+					URL     string `json:"url"`
+					Quality string `json:"quality"`
+					Width   int    `json:"width"`
+					Height  int    `json:"height"`
+					Mime    string `json:"mime"`
+				} `json:"progressive"`
+				HLS struct {
+					DefaultCDN string `json:"default_cdn"`
+					CDNs       map[string]struct {
+						URL string `json:"url"`
+					} `json:"cdns"`
+				} `json:"hls"`
+			} `json:"files"`
+		} `json:"request"`
+		Video struct {
+			Title    string            `json:"title"`
+			Duration int               `json:"duration"`
+			Thumbs   map[string]string `json:"thumbs"`
+			Owner    struct {
+				Name string `json:"name"`
+			} `json:"owner"`
+		} `json:"video"`
+	}
+	if err := getJSON(ctx, client, configURL, &config); err != nil {
+		return v, err
+	}
+
+	v.Title = config.Video.Title
+	v.Author = config.Video.Owner.Name
+	v.Duration = config.Video.Duration
+	v.Thumbnail = config.Video.Thumbs["base"]
+	if t, ok := config.Video.Thumbs["1280"]; ok {
+		v.Thumbnail = t
+	}
+	for _, f := range config.Request.Files.Progressive {
+		mime := f.Mime
+		if mime == "" {
+			mime = "video/mp4"
+		}
+		v.Streams = append(v.Streams, Stream{URL: f.URL, Type: mime, Quality: f.Quality, Width: f.Width, Height: f.Height})
+	}
+	hls := config.Request.Files.HLS
+	if cdn, ok := hls.CDNs[hls.DefaultCDN]; ok && cdn.URL != "" {
+		v.Streams = append(v.Streams, Stream{URL: cdn.URL, Type: "hls"})
+	}
+	return v, nil
+}
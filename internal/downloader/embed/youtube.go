@@ -0,0 +1,82 @@
+// internal/downloader/embed/youtube.go
+package embed
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// youtubeOEmbedURL is the oEmbed endpoint; YouTube streams are signed per
+// session, so only metadata is resolved
+var youtubeOEmbedURL = "https://www.youtube.com/oembed"
+
+var youtubeID = regexp.MustCompile(`^[A-Za-z0-9_-]{11}$`)
+
+// youtube resolves youtube.com, youtube-nocookie.com and youtu.be players
+type youtube struct{}
+
+func init() {
+	Register(youtube{})
+}
+
+func (youtube) Name() string { return "youtube" }
+
+func (youtube) Find(doc *goquery.Document, base *url.URL) []Embed {
+	var embeds []Embed
+	for _, u := range iframeSources(doc, base) {
+		if id := youtubeVideoID(u); id != "" {
+			embeds = append(embeds, Embed{URL: u.String(), ID: id})
+		}
+	}
+	return embeds
+}
+
+func (youtube) Resolve(ctx context.Context, client *http.Client, e Embed) (*Video, error) {
+	watch := "https://www.youtube.com/watch?v=" + e.ID
+	v := &Video{Provider: "youtube", ID: e.ID, EmbedURL: e.URL, PageURL: watch,
+		Thumbnail: "https://i.ytimg.com/vi/" + e.ID + "/hqdefault.jpg"}
+
+	var meta struct {
+		Title        string `json:"title"`
+		AuthorName   string `json:"author_name"`
+		ThumbnailURL string `json:"thumbnail_url"`
+	}
+	query := url.Values{"url": {watch}, "format": {"json"}}
+	if err := getJSON(ctx, client, youtubeOEmbedURL+"?"+query.Encode(), &meta); err != nil {
+		return v, err
+	}
+	v.Title = meta.Title
+	v.Author = meta.AuthorName
+	if meta.ThumbnailURL != "" {
+		v.Thumbnail = meta.ThumbnailURL
+	}
+	return v, nil
+}
+
+// youtubeVideoID returns the video ID in a YouTube player or watch URL
+func youtubeVideoID(u *url.URL) string {
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	host = strings.TrimPrefix(host, "m.")
+	var id string
+	switch host {
+	case "youtu.be":
+		id = strings.Trim(u.Path, "/")
+	case "youtube.com", "youtube-nocookie.com":
+		parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+		switch {
+		case len(parts) == 2 && (parts[0] == "embed" || parts[0] == "v" || parts[0] == "shorts"):
+			id = parts[1]
+		case parts[0] == "watch":
+			id = u.Query().Get("v")
+		}
+	}
+	if !youtubeID.MatchString(id) {
+		return ""
+	}
+	return id
+}
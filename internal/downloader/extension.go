@@ -0,0 +1,107 @@
+// internal/downloader/extension.go
+package downloader
+
+import (
+	"mime"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// preferredExtensions maps common media Content-Types to the extension we want
+// on disk. mime.ExtensionsByType is used as a fallback for anything else, but its
+// ordering is platform dependent (e.g. ".jfif" for image/jpeg).
+var preferredExtensions = map[string]string{
+	"image/jpeg":                    ".jpg",
+	"image/png":                     ".png",
+	"image/gif":                     ".gif",
+	"image/webp":                    ".webp",
+	"image/avif":                    ".avif",
+	"image/svg+xml":                 ".svg",
+	"image/bmp":                     ".bmp",
+	"image/x-icon":                  ".ico",
+	"image/vnd.microsoft.icon":      ".ico",
+	"image/tiff":                    ".tiff",
+	"video/mp4":                     ".mp4",
+	"video/webm":                    ".webm",
+	"video/quicktime":               ".mov",
+	"video/x-msvideo":               ".avi",
+	"video/x-matroska":              ".mkv",
+	"video/mp2t":                    ".ts",
+	"application/vnd.apple.mpegurl": ".m3u8",
+	"application/x-mpegurl":         ".m3u8",
+	"audio/mpeg":                    ".mp3",
+	"audio/mp4":                     ".m4a",
+	"audio/aac":                     ".aac",
+	"audio/ogg":                     ".ogg",
+	"audio/wav":                     ".wav",
+	"audio/x-wav":                   ".wav",
+	"audio/flac":                    ".flac",
+}
+
+// equivalentExtensions lists alternate spellings that are already correct for a type
+var equivalentExtensions = map[string][]string{
+	".jpg":  {".jpeg", ".jpe", ".jfif"},
+	".tiff": {".tif"},
+}
+
+// correctExtension returns filename with its extension adjusted to match the
+// response Content-Type. Generic or unparseable types leave the name unchanged.
+func correctExtension(filename, contentType string) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return filename
+	}
+	mediaType = strings.ToLower(mediaType)
+
+	// Only correct media payloads; octet-stream, HTML error pages, etc. tell us nothing
+	if !strings.HasPrefix(mediaType, "image/") &&
+		!strings.HasPrefix(mediaType, "video/") &&
+		!strings.HasPrefix(mediaType, "audio/") &&
+		preferredExtensions[mediaType] == "" {
+		return filename
+	}
+
+	want := preferredExtensions[mediaType]
+	if want == "" {
+		exts, err := mime.ExtensionsByType(mediaType)
+		if err != nil || len(exts) == 0 {
+			return filename
+		}
+		want = exts[0]
+	}
+
+	ext := filepath.Ext(filename)
+	lowerExt := strings.ToLower(ext)
+	if lowerExt == want {
+		return filename
+	}
+	for _, alt := range equivalentExtensions[want] {
+		if lowerExt == alt {
+			return filename
+		}
+	}
+
+	return strings.TrimSuffix(filename, ext) + want
+}
+
+// correctedNames lists the names correctExtension gives filename for the
+// common media types, so an earlier run's file can be found before the
+// response says which applies
+func correctedNames(filename string) []string {
+	types := make([]string, 0, len(preferredExtensions))
+	for t := range preferredExtensions {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+
+	var names []string
+	seen := map[string]bool{filename: true}
+	for _, t := range types {
+		if name := correctExtension(filename, t); !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	return names
+}
@@ -0,0 +1,172 @@
+// internal/downloader/imageproc/exif.go
+package imageproc
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// stripMetadata removes EXIF, XMP and comment metadata from an encoded
+// image without re-encoding it. changed is false when there was none.
+func stripMetadata(data []byte, format string) (out []byte, changed bool) {
+	switch format {
+	case "jpeg":
+		return stripJPEG(data)
+	case "png":
+		return stripPNG(data)
+	case "webp":
+		return stripWebP(data)
+	}
+	return data, false
+}
+
+// stripJPEG drops APP1 (EXIF, XMP), APP13 (IPTC) and COM segments. Color
+// data such as ICC profiles (APP2) and Adobe markers (APP14) is kept.
+func stripJPEG(data []byte) ([]byte, bool) {
+	if len(data) < 4 || data[0] != 0xff || data[1] != 0xd8 {
+		return data, false
+	}
+	out := append([]byte(nil), data[:2]...)
+	changed := false
+	for i := 2; i+4 <= len(data); {
+		if data[i] != 0xff {
+			return data, false // not a marker where one should be
+		}
+		marker := data[i+1]
+		if marker == 0xff { // fill byte
+			i++
+			continue
+		}
+		if marker == 0xda { // start of scan: the rest is image data
+			return append(out, data[i:]...), changed
+		}
+		if marker == 0x01 || (marker >= 0xd0 && marker <= 0xd8) { // no length
+			out = append(out, data[i:i+2]...)
+			i += 2
+			continue
+		}
+		end := i + 2 + int(binary.BigEndian.Uint16(data[i+2:]))
+		if end > len(data) {
+			return data, false
+		}
+		if marker == 0xe1 || marker == 0xed || marker == 0xfe {
+			changed = true
+		} else {
+			out = append(out, data[i:end]...)
+		}
+		i = end
+	}
+	return data, false
+}
+
+// jpegOrientation returns the EXIF orientation (1-8) of a JPEG, or 1
+func jpegOrientation(data []byte) int {
+	for i := 2; i+4 <= len(data) && data[i] == 0xff; {
+		marker := data[i+1]
+		if marker == 0xda {
+			break
+		}
+		end := i + 2 + int(binary.BigEndian.Uint16(data[i+2:]))
+		if end > len(data) {
+			break
+		}
+		if marker == 0xe1 && bytes.HasPrefix(data[i+4:end], []byte("Exif\x00\x00")) {
+			return tiffOrientation(data[i+10 : end])
+		}
+		i = end
+	}
+	return 1
+}
+
+// tiffOrientation reads the orientation tag from IFD0 of a TIFF header
+func tiffOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd+2 > len(tiff) {
+		return 1
+	}
+	entries := int(order.Uint16(tiff[ifd:]))
+	for e := 0; e < entries; e++ {
+		at := ifd + 2 + e*12
+		if at+12 > len(tiff) {
+			break
+		}
+		if order.Uint16(tiff[at:]) == 0x0112 {
+			if v := int(order.Uint16(tiff[at+8:])); v >= 1 && v <= 8 {
+				return v
+			}
+			break
+		}
+	}
+	return 1
+}
+
+// stripPNG drops eXIf and text chunks, which is where XMP lives
+func stripPNG(data []byte) ([]byte, bool) {
+	const sigLen = 8
+	if len(data) < sigLen {
+		return data, false
+	}
+	out := append([]byte(nil), data[:sigLen]...)
+	changed := false
+	for i := sigLen; i+12 <= len(data); {
+		end := i + 12 + int(binary.BigEndian.Uint32(data[i:]))
+		if end > len(data) || end < i {
+			return data, false
+		}
+		switch string(data[i+4 : i+8]) {
+		case "eXIf", "tEXt", "zTXt", "iTXt":
+			changed = true
+		default:
+			out = append(out, data[i:end]...)
+		}
+		i = end
+	}
+	return out, changed
+}
+
+// stripWebP drops the EXIF and XMP chunks of an extended WebP and clears
+// their flags in the VP8X header
+func stripWebP(data []byte) ([]byte, bool) {
+	if len(data) < 12 || string(data[:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return data, false
+	}
+	out := append([]byte(nil), data[:12]...)
+	changed := false
+	for i := 12; i+8 <= len(data); {
+		size := int(binary.LittleEndian.Uint32(data[i+4:]))
+		end := i + 8 + size + size&1
+		if end > len(data) || end < i {
+			return data, false
+		}
+		switch string(data[i : i+4]) {
+		case "EXIF", "XMP ":
+			changed = true
+		case "VP8X":
+			chunk := append([]byte(nil), data[i:end]...)
+			if len(chunk) > 8 {
+				chunk[8] &^= 0x08 | 0x04 // EXIF and XMP present
+			}
+			out = append(out, chunk...)
+		default:
+			out = append(out, data[i:end]...)
+		}
+		i = end
+	}
+	if !changed {
+		return data, false
+	}
+	binary.LittleEndian.PutUint32(out[4:], uint32(len(out)-8))
+	return out, true
+}
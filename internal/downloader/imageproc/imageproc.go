@@ -0,0 +1,246 @@
+// internal/downloader/imageproc/imageproc.go
+package imageproc
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp" // register the decoder with image.Decode
+)
+
+// Formats are the formats images can be converted to
+var Formats = []string{"jpeg", "png", "webp"}
+
+// DefaultMaxPixels is the largest image, in pixels, decoded for processing
+// unless Options.MaxPixels says otherwise. Decoding takes 4 bytes a pixel,
+// and orienting or scaling makes another copy, so 50 megapixels is already
+// a few hundred MB.
+const DefaultMaxPixels = 50_000_000
+
+// Options configures post-download image processing
+type Options struct {
+	Format    string // Convert to jpeg, png or webp; "" keeps the format
+	MaxWidth  int    // Scale wider images down to this width; 0 keeps the size
+	StripEXIF bool   // Remove EXIF, XMP and text metadata
+	Quality   int    // JPEG quality (1-100); 0 uses 90
+	MaxPixels int64  // Largest image decoded for re-encoding; 0 uses DefaultMaxPixels
+}
+
+// Enabled reports whether the options change anything
+func (o Options) Enabled() bool {
+	return o.Format != "" || o.MaxWidth > 0 || o.StripEXIF
+}
+
+// ParseFormat normalizes a --convert value
+func ParseFormat(s string) (string, error) {
+	switch strings.ToLower(strings.TrimPrefix(s, ".")) {
+	case "":
+		return "", nil
+	case "jpeg", "jpg":
+		return "jpeg", nil
+	case "png":
+		return "png", nil
+	case "webp":
+		return "webp", nil
+	}
+	return "", fmt.Errorf("unsupported image format %q (must be %s)", s, strings.Join(Formats, ", "))
+}
+
+// Process applies opts to the image at path and returns the path of the
+// result, which differs when the format changes. Files that aren't images,
+// and animated GIFs, are returned untouched. Re-encoding writes no
+// metadata, so EXIF orientation is applied to the pixels first. Images over
+// the pixel budget are not decoded: the header's dimensions are checked
+// first, so a small file claiming a huge size can't exhaust memory.
+func Process(path string, opts Options) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return path, fmt.Errorf("failed to read image: %w", err)
+	}
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return path, nil // not an image we can decode
+	}
+	if format == "gif" {
+		if g, err := gif.DecodeAll(bytes.NewReader(data)); err == nil && len(g.Image) > 1 {
+			return path, nil
+		}
+	}
+
+	target := opts.Format
+	if target == "" {
+		target = format
+		if target == "gif" {
+			target = "png" // the only lossless output for a resized GIF
+		}
+	}
+	orientation := 1
+	if format == "jpeg" {
+		orientation = jpegOrientation(data)
+	}
+	resize := opts.MaxWidth > 0 && cfg.Width > opts.MaxWidth
+	reencode := resize || target != format || (opts.StripEXIF && orientation != 1)
+
+	if !reencode {
+		if !opts.StripEXIF {
+			return path, nil
+		}
+		stripped, changed := stripMetadata(data, format)
+		if !changed {
+			return path, nil
+		}
+		return path, writeFile(path, stripped)
+	}
+
+	maxPixels := opts.MaxPixels
+	if maxPixels <= 0 {
+		maxPixels = DefaultMaxPixels
+	}
+	if pixels := int64(cfg.Width) * int64(cfg.Height); pixels > maxPixels {
+		return path, fmt.Errorf("image is %dx%d, over the %d-pixel limit for processing", cfg.Width, cfg.Height, maxPixels)
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return path, fmt.Errorf("failed to decode image: %w", err)
+	}
+	img = orient(img, orientation)
+	if b := img.Bounds(); opts.MaxWidth > 0 && b.Dx() > opts.MaxWidth {
+		height := b.Dy() * opts.MaxWidth / b.Dx()
+		if height < 1 {
+			height = 1
+		}
+		scaled := image.NewNRGBA(image.Rect(0, 0, opts.MaxWidth, height))
+		draw.CatmullRom.Scale(scaled, scaled.Bounds(), img, b, draw.Src, nil)
+		img = scaled
+	}
+
+	var buf bytes.Buffer
+	switch target {
+	case "jpeg":
+		quality := opts.Quality
+		if quality <= 0 || quality > 100 {
+			quality = 90
+		}
+		err = jpeg.Encode(&buf, flatten(img), &jpeg.Options{Quality: quality})
+	case "png":
+		err = png.Encode(&buf, img)
+	case "webp":
+		err = EncodeWebP(&buf, img)
+	default:
+		return path, fmt.Errorf("cannot encode %s images", target)
+	}
+	if err != nil {
+		return path, fmt.Errorf("failed to encode %s: %w", target, err)
+	}
+
+	out := withExtension(path, format, target)
+	if err := writeFile(out, buf.Bytes()); err != nil {
+		return path, err
+	}
+	if out != path {
+		if err := os.Remove(path); err != nil {
+			return out, fmt.Errorf("failed to remove original: %w", err)
+		}
+	}
+	return out, nil
+}
+
+// withExtension returns path with the extension for format, keeping the
+// original one when the format is unchanged
+func withExtension(path, from, to string) string {
+	if from == to {
+		return path
+	}
+	ext := map[string]string{"jpeg": ".jpg", "png": ".png", "webp": ".webp"}[to]
+	return strings.TrimSuffix(path, filepath.Ext(path)) + ext
+}
+
+// writeFile replaces path through a temporary file, so an interrupted run
+// never leaves a truncated image
+func writeFile(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".imageproc-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+	// CreateTemp makes the file private; match a normal download
+	if err := tmp.Chmod(0644); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write image: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write image: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to replace image: %w", err)
+	}
+	return nil
+}
+
+// flatten draws img onto white, since JPEG has no transparency
+func flatten(img image.Image) image.Image {
+	opaque, ok := img.(interface{ Opaque() bool })
+	if ok && opaque.Opaque() {
+		return img
+	}
+	b := img.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(dst, dst.Bounds(), img, b.Min, draw.Over)
+	return dst
+}
+
+// orient rotates and flips img so it displays upright without its EXIF
+// orientation tag (values 2-8; 1 is already upright)
+func orient(img image.Image, orientation int) image.Image {
+	if orientation < 2 || orientation > 8 {
+		return img
+	}
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	// Orientations 5-8 swap width and height
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+	dst := image.NewNRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2: // mirrored
+				dx, dy = w-1-x, y
+			case 3: // rotated 180°
+				dx, dy = w-1-x, h-1-y
+			case 4: // mirrored vertically
+				dx, dy = x, h-1-y
+			case 5: // mirrored along the top-left diagonal
+				dx, dy = y, x
+			case 6: // rotated 90° clockwise
+				dx, dy = h-1-y, x
+			case 7: // mirrored along the top-right diagonal
+				dx, dy = h-1-y, w-1-x
+			case 8: // rotated 90° counter-clockwise
+				dx, dy = y, w-1-x
+			}
+			dst.Set(dx, dy, img.At(b.Min.X+x, b.Min.Y+y))
+		}
+	}
+	return dst
+}
@@ -0,0 +1,196 @@
+package imageproc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/image/webp"
+)
+
+// withEXIF inserts an APP1 segment with the given orientation after SOI
+func withEXIF(t *testing.T, jpg []byte, orientation uint16) []byte {
+	t.Helper()
+	tiff := make([]byte, 8+2+12+4)
+	copy(tiff, "MM")
+	binary.BigEndian.PutUint16(tiff[2:], 42)
+	binary.BigEndian.PutUint32(tiff[4:], 8)
+	binary.BigEndian.PutUint16(tiff[8:], 1)
+	binary.BigEndian.PutUint16(tiff[10:], 0x0112) // Orientation
+	binary.BigEndian.PutUint16(tiff[12:], 3)      // SHORT
+	binary.BigEndian.PutUint32(tiff[14:], 1)
+	binary.BigEndian.PutUint16(tiff[18:], orientation)
+
+	payload := append([]byte("Exif\x00\x00"), tiff...)
+	segment := []byte{0xff, 0xe1, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:], uint16(len(payload)+2))
+	segment = append(segment, payload...)
+
+	out := append([]byte(nil), jpg[:2]...)
+	out = append(out, segment...)
+	return append(out, jpg[2:]...)
+}
+
+func testImage(w, h int) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.SetNRGBA(x, y, color.NRGBA{uint8(x * 4), uint8(y * 4), 90, 255})
+		}
+	}
+	return img
+}
+
+func TestProcess_StripEXIFAppliesOrientation(t *testing.T) {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, testImage(40, 20), nil); err != nil {
+		t.Fatal(err)
+	}
+	data := withEXIF(t, buf.Bytes(), 6)
+	if jpegOrientation(data) != 6 {
+		t.Fatalf("Expected orientation 6, got %d", jpegOrientation(data))
+	}
+
+	path := filepath.Join(t.TempDir(), "photo.jpg")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	out, err := Process(path, Options{StripEXIF: true})
+	if err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+	if out != path {
+		t.Errorf("Expected the path to stay %s, got %s", path, out)
+	}
+
+	result, _ := os.ReadFile(out)
+	if bytes.Contains(result, []byte("Exif\x00\x00")) {
+		t.Error("Expected the EXIF segment to be removed")
+	}
+	cfg, err := jpeg.DecodeConfig(bytes.NewReader(result))
+	if err != nil {
+		t.Fatalf("Output is not a JPEG: %v", err)
+	}
+	if cfg.Width != 20 || cfg.Height != 40 {
+		t.Errorf("Expected the rotation to be applied (20x40), got %dx%d", cfg.Width, cfg.Height)
+	}
+}
+
+func TestProcess_StripEXIFWithoutReencoding(t *testing.T) {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, testImage(8, 8), nil); err != nil {
+		t.Fatal(err)
+	}
+	data := withEXIF(t, buf.Bytes(), 1)
+	path := filepath.Join(t.TempDir(), "photo.jpg")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Process(path, Options{StripEXIF: true}); err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+	result, _ := os.ReadFile(path)
+	if !bytes.Equal(result, buf.Bytes()) {
+		t.Error("Expected only the EXIF segment to be removed, leaving the image data as encoded")
+	}
+}
+
+func TestProcess_ConvertAndResize(t *testing.T) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, testImage(64, 32)); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "image.png")
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := Process(path, Options{Format: "webp", MaxWidth: 16})
+	if err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+	if filepath.Ext(out) != ".webp" {
+		t.Errorf("Expected a .webp file, got %s", out)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("Expected the original to be removed")
+	}
+	f, err := os.Open(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	cfg, err := webp.DecodeConfig(f)
+	if err != nil {
+		t.Fatalf("Output is not a WebP: %v", err)
+	}
+	if cfg.Width != 16 || cfg.Height != 8 {
+		t.Errorf("Expected 16x8, got %dx%d", cfg.Width, cfg.Height)
+	}
+}
+
+func TestProcess_PixelBudget(t *testing.T) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, testImage(64, 32)); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "image.png")
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := Process(path, Options{Format: "jpeg", MaxPixels: 64*32 - 1})
+	if err == nil {
+		t.Fatal("Expected an error for an image over the pixel budget")
+	}
+	if out != path {
+		t.Errorf("Expected the original path back, got %s", out)
+	}
+	if data, _ := os.ReadFile(path); !bytes.Equal(data, buf.Bytes()) {
+		t.Error("Expected the image to be left as downloaded")
+	}
+}
+
+func TestProcess_LeavesOtherFilesAlone(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "clip.mp4")
+	if err := os.WriteFile(path, []byte("not an image"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	out, err := Process(path, Options{Format: "webp", MaxWidth: 100, StripEXIF: true})
+	if err != nil || out != path {
+		t.Errorf("Expected %s to be left alone, got %s, %v", path, out, err)
+	}
+}
+
+func TestStripPNG(t *testing.T) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, testImage(4, 4)); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	// Insert a tEXt chunk after IHDR (8 byte signature + 25 byte IHDR)
+	text := []byte{0, 0, 0, 5, 't', 'E', 'X', 't', 'h', 'e', 'l', 'l', 'o', 0, 0, 0, 0}
+	withText := append(append(append([]byte(nil), data[:33]...), text...), data[33:]...)
+
+	out, changed := stripPNG(withText)
+	if !changed || !bytes.Equal(out, data) {
+		t.Error("Expected the tEXt chunk to be removed")
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	for in, want := range map[string]string{"jpg": "jpeg", "JPEG": "jpeg", ".png": "png", "webp": "webp", "": ""} {
+		if got, err := ParseFormat(in); err != nil || got != want {
+			t.Errorf("ParseFormat(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := ParseFormat("avif"); err == nil {
+		t.Error("Expected an error for avif")
+	}
+}
@@ -0,0 +1,315 @@
+// internal/downloader/imageproc/webp.go
+package imageproc
+
+import (
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/draw"
+	"io"
+	"sort"
+)
+
+// The Go ecosystem has a WebP decoder but no pure-Go encoder, so this is a
+// minimal lossless (VP8L) one: no transforms and no backward references,
+// just one set of Huffman codes over the pixels. Output is larger than a
+// tuned encoder's but decodes everywhere WebP does.
+
+const (
+	vp8lSignature  = 0x2f
+	vp8lMaxSize    = 1 << 14
+	maxCodeLength  = 15
+	maxCLCodeLen   = 7
+	greenAlphabet  = 256 + 24 // literals plus backward-reference length prefixes
+	colorAlphabet  = 256
+	distanceSymbol = 40
+)
+
+// codeLengthOrder is the order code-length code lengths are written in
+var codeLengthOrder = [19]int{17, 18, 0, 1, 2, 3, 4, 5, 16, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}
+
+// EncodeWebP writes img as a lossless WebP
+func EncodeWebP(w io.Writer, img image.Image) error {
+	b := img.Bounds()
+	width, height := b.Dx(), b.Dy()
+	if width < 1 || height < 1 || width > vp8lMaxSize || height > vp8lMaxSize {
+		return fmt.Errorf("webp: %dx%d is outside the supported size (1-%d)", width, height, vp8lMaxSize)
+	}
+
+	pix, ok := img.(*image.NRGBA)
+	if !ok || pix.Rect.Min != (image.Point{}) {
+		pix = image.NewNRGBA(image.Rect(0, 0, width, height))
+		draw.Draw(pix, pix.Rect, img, b.Min, draw.Src)
+	}
+
+	var green, red, blue, alpha [colorAlphabet]uint32
+	alphaUsed := false
+	for y := 0; y < height; y++ {
+		row := pix.Pix[y*pix.Stride : y*pix.Stride+width*4]
+		for x := 0; x < len(row); x += 4 {
+			red[row[x]]++
+			green[row[x+1]]++
+			blue[row[x+2]]++
+			alpha[row[x+3]]++
+			if row[x+3] != 0xff {
+				alphaUsed = true
+			}
+		}
+	}
+
+	bw := &bitWriter{}
+	bw.buf = append(bw.buf, vp8lSignature)
+	bw.write(uint32(width-1), 14)
+	bw.write(uint32(height-1), 14)
+	bw.write(boolBit(alphaUsed), 1)
+	bw.write(0, 3) // version
+	bw.write(0, 1) // no transforms
+	bw.write(0, 1) // no color cache
+	bw.write(0, 1) // one set of prefix codes for the whole image
+
+	greenCounts := make([]uint32, greenAlphabet)
+	copy(greenCounts, green[:])
+	gCode := bw.writePrefixCode(greenCounts)
+	rCode := bw.writePrefixCode(red[:])
+	bCode := bw.writePrefixCode(blue[:])
+	aCode := bw.writePrefixCode(alpha[:])
+	bw.writePrefixCode(make([]uint32, distanceSymbol))
+
+	for y := 0; y < height; y++ {
+		row := pix.Pix[y*pix.Stride : y*pix.Stride+width*4]
+		for x := 0; x < len(row); x += 4 {
+			gCode.emit(bw, row[x+1])
+			rCode.emit(bw, row[x])
+			bCode.emit(bw, row[x+2])
+			aCode.emit(bw, row[x+3])
+		}
+	}
+	bw.flush()
+
+	data := bw.buf
+	pad := len(data) & 1
+	header := make([]byte, 20)
+	copy(header[0:], "RIFF")
+	binary.LittleEndian.PutUint32(header[4:], uint32(4+8+len(data)+pad))
+	copy(header[8:], "WEBPVP8L")
+	binary.LittleEndian.PutUint32(header[16:], uint32(len(data)))
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+	if pad == 1 {
+		_, err := w.Write([]byte{0})
+		return err
+	}
+	return nil
+}
+
+// bitWriter packs values least significant bit first, as VP8L reads them
+type bitWriter struct {
+	buf  []byte
+	acc  uint64
+	nacc uint
+}
+
+func (b *bitWriter) write(v uint32, n uint) {
+	b.acc |= uint64(v) << b.nacc
+	b.nacc += n
+	for b.nacc >= 8 {
+		b.buf = append(b.buf, byte(b.acc))
+		b.acc >>= 8
+		b.nacc -= 8
+	}
+}
+
+func (b *bitWriter) flush() {
+	if b.nacc > 0 {
+		b.buf = append(b.buf, byte(b.acc))
+		b.acc, b.nacc = 0, 0
+	}
+}
+
+// prefixCode is a Huffman code with bit-reversed codes, ready to write
+type prefixCode struct {
+	lengths []uint8
+	codes   []uint16
+}
+
+func (c prefixCode) emit(b *bitWriter, sym byte) {
+	b.write(uint32(c.codes[sym]), uint(c.lengths[sym]))
+}
+
+// writePrefixCode writes the code for an alphabet with the given symbol
+// counts and returns it. One or two small symbols use the compact "simple"
+// form; a lone symbol then takes no bits per pixel.
+func (b *bitWriter) writePrefixCode(counts []uint32) prefixCode {
+	var used []int
+	for sym, n := range counts {
+		if n > 0 {
+			used = append(used, sym)
+		}
+	}
+	if len(used) == 0 {
+		used = []int{0}
+	}
+
+	if len(used) <= 2 && used[len(used)-1] < 256 {
+		lengths := make([]uint8, len(counts))
+		b.write(1, 1) // simple code
+		b.write(uint32(len(used)-1), 1)
+		if used[0] < 2 {
+			b.write(0, 1)
+			b.write(uint32(used[0]), 1)
+		} else {
+			b.write(1, 1)
+			b.write(uint32(used[0]), 8)
+		}
+		if len(used) == 2 {
+			b.write(uint32(used[1]), 8)
+			lengths[used[0]], lengths[used[1]] = 1, 1
+		}
+		return prefixCode{lengths: lengths, codes: canonicalCodes(lengths)}
+	}
+
+	lengths := huffmanLengths(counts, maxCodeLength)
+
+	// The code lengths are themselves Huffman coded, one symbol per length
+	clCounts := make([]uint32, 19)
+	for _, l := range lengths {
+		clCounts[l]++
+	}
+	clUsed := 0
+	for _, n := range clCounts {
+		if n > 0 {
+			clUsed++
+		}
+	}
+	if clUsed == 1 {
+		// A code needs two symbols to be complete; add one that goes unused
+		if clCounts[0] == 0 {
+			clCounts[0] = 1
+		} else {
+			clCounts[1] = 1
+		}
+	}
+	clLengths := huffmanLengths(clCounts, maxCLCodeLen)
+	clCodes := canonicalCodes(clLengths)
+
+	b.write(0, 1)                              // normal code
+	b.write(uint32(len(codeLengthOrder)-4), 4) // all 19 code-length code lengths follow
+	for _, sym := range codeLengthOrder {
+		b.write(uint32(clLengths[sym]), 3)
+	}
+	b.write(0, 1) // a length for every symbol
+	for _, l := range lengths {
+		b.write(uint32(clCodes[l]), uint(clLengths[l]))
+	}
+	return prefixCode{lengths: lengths, codes: canonicalCodes(lengths)}
+}
+
+// huffmanLengths returns code lengths for counts no longer than limit. When
+// the optimal code is too deep, rare symbols are weighted up until it fits.
+func huffmanLengths(counts []uint32, limit int) []uint8 {
+	type node struct {
+		weight uint64
+		parent int
+	}
+	var leaves []int
+	for sym, n := range counts {
+		if n > 0 {
+			leaves = append(leaves, sym)
+		}
+	}
+	lengths := make([]uint8, len(counts))
+
+	for minWeight := uint64(1); ; minWeight *= 2 {
+		nodes := make([]node, 0, 2*len(leaves))
+		for _, sym := range leaves {
+			w := uint64(counts[sym])
+			if w < minWeight {
+				w = minWeight
+			}
+			nodes = append(nodes, node{weight: w, parent: -1})
+		}
+		order := make([]int, len(leaves))
+		for i := range order {
+			order[i] = i
+		}
+		sort.SliceStable(order, func(i, j int) bool { return nodes[order[i]].weight < nodes[order[j]].weight })
+
+		// Two-queue construction: sorted leaves, then internal nodes in the
+		// order made, whose weights never decrease
+		var internal []int
+		li, ii := 0, 0
+		pop := func() int {
+			if ii >= len(internal) || (li < len(order) && nodes[order[li]].weight <= nodes[internal[ii]].weight) {
+				li++
+				return order[li-1]
+			}
+			ii++
+			return internal[ii-1]
+		}
+		for remaining := len(leaves); remaining > 1; remaining-- {
+			a, c := pop(), pop()
+			nodes = append(nodes, node{weight: nodes[a].weight + nodes[c].weight, parent: -1})
+			nodes[a].parent = len(nodes) - 1
+			nodes[c].parent = len(nodes) - 1
+			internal = append(internal, len(nodes)-1)
+		}
+
+		maxDepth := 0
+		for i, sym := range leaves {
+			depth := 0
+			for n := i; nodes[n].parent >= 0; n = nodes[n].parent {
+				depth++
+			}
+			lengths[sym] = uint8(depth)
+			if depth > maxDepth {
+				maxDepth = depth
+			}
+		}
+		if maxDepth <= limit {
+			return lengths
+		}
+	}
+}
+
+// canonicalCodes assigns codes in order of length then symbol, as the
+// decoder does, and reverses them for least-significant-bit-first writing
+func canonicalCodes(lengths []uint8) []uint16 {
+	var count, next [maxCodeLength + 1]int
+	for _, l := range lengths {
+		if l > 0 {
+			count[l]++
+		}
+	}
+	code := 0
+	for bits := 1; bits <= maxCodeLength; bits++ {
+		code = (code + count[bits-1]) << 1
+		next[bits] = code
+	}
+
+	codes := make([]uint16, len(lengths))
+	for sym, l := range lengths {
+		if l == 0 {
+			continue
+		}
+		c := next[l]
+		next[l]++
+		var rev uint16
+		for i := uint8(0); i < l; i++ {
+			rev = rev<<1 | uint16(c&1)
+			c >>= 1
+		}
+		codes[sym] = rev
+	}
+	return codes
+}
+
+func boolBit(b bool) uint32 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,63 @@
+package imageproc
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"math/rand"
+	"testing"
+
+	"golang.org/x/image/webp"
+)
+
+func TestEncodeWebP_RoundTrip(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	noise := image.NewNRGBA(image.Rect(0, 0, 97, 61))
+	rng.Read(noise.Pix)
+
+	gradient := image.NewNRGBA(image.Rect(0, 0, 300, 40))
+	for y := 0; y < 40; y++ {
+		for x := 0; x < 300; x++ {
+			gradient.SetNRGBA(x, y, color.NRGBA{uint8(x), uint8(y * 6), 128, 255})
+		}
+	}
+
+	solid := image.NewNRGBA(image.Rect(0, 0, 5, 3))
+	for i := range solid.Pix {
+		solid.Pix[i] = 200
+	}
+
+	// Skewed counts force the length limit to kick in
+	skewed := image.NewNRGBA(image.Rect(0, 0, 4096, 8))
+	for i := 0; i < len(skewed.Pix); i += 4 {
+		v := uint8(0)
+		if i/4 < 40 {
+			v = uint8(i / 4)
+		} else if rng.Intn(1000) == 0 {
+			v = uint8(rng.Intn(256))
+		}
+		skewed.Pix[i], skewed.Pix[i+1], skewed.Pix[i+2], skewed.Pix[i+3] = v, v, v, 255
+	}
+
+	for name, img := range map[string]*image.NRGBA{"noise": noise, "gradient": gradient, "solid": solid, "skewed": skewed} {
+		var buf bytes.Buffer
+		if err := EncodeWebP(&buf, img); err != nil {
+			t.Fatalf("%s: EncodeWebP failed: %v", name, err)
+		}
+		decoded, err := webp.Decode(&buf)
+		if err != nil {
+			t.Fatalf("%s: decoding the output failed: %v", name, err)
+		}
+		if decoded.Bounds() != img.Bounds() {
+			t.Fatalf("%s: expected bounds %v, got %v", name, img.Bounds(), decoded.Bounds())
+		}
+		for y := 0; y < img.Rect.Dy(); y++ {
+			for x := 0; x < img.Rect.Dx(); x++ {
+				want := img.NRGBAAt(x, y)
+				if got := color.NRGBAModel.Convert(decoded.At(x, y)).(color.NRGBA); got != want {
+					t.Fatalf("%s: pixel (%d,%d) = %v, want %v", name, x, y, got, want)
+				}
+			}
+		}
+	}
+}
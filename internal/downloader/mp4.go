@@ -0,0 +1,120 @@
+// internal/downloader/mp4.go
+package downloader
+
+import (
+	"encoding/binary"
+	"io"
+)
+
+// maxMoovSize bounds how much of an MP4's movie header is read
+const maxMoovSize = 64 << 20
+
+// mp4Info is what the movie header of an MP4, MOV or M4A file says
+type mp4Info struct {
+	width, height int
+	duration      float64 // Seconds
+}
+
+// probeMP4 reads the duration and the first video track's size from the
+// moov box of an ISO base media file. ok is false for other files.
+func probeMP4(r io.ReaderAt, size int64) (info mp4Info, ok bool) {
+	for off := int64(0); off+8 <= size; {
+		var head [16]byte
+		if _, err := r.ReadAt(head[:8], off); err != nil {
+			return info, false
+		}
+		boxSize, hdr := int64(binary.BigEndian.Uint32(head[:4])), int64(8)
+		switch boxSize {
+		case 0:
+			boxSize = size - off
+		case 1:
+			if _, err := r.ReadAt(head[8:16], off+8); err != nil {
+				return info, false
+			}
+			boxSize, hdr = int64(binary.BigEndian.Uint64(head[8:16])), 16
+		}
+		if boxSize < hdr || off+boxSize > size {
+			return info, false
+		}
+		// Every ISO media file starts with an ftyp box
+		if off == 0 && string(head[4:8]) != "ftyp" {
+			return info, false
+		}
+		if string(head[4:8]) == "moov" {
+			if boxSize-hdr > maxMoovSize {
+				return info, false
+			}
+			moov := make([]byte, boxSize-hdr)
+			if _, err := r.ReadAt(moov, off+hdr); err != nil {
+				return info, false
+			}
+			return parseMoov(moov)
+		}
+		off += boxSize
+	}
+	return info, false
+}
+
+// parseMoov reads mvhd and the tkhd of each trak in a moov box's payload
+func parseMoov(moov []byte) (info mp4Info, ok bool) {
+	for _, box := range childBoxes(moov) {
+		switch box.kind {
+		case "mvhd":
+			// version(1) flags(3), then creation and modification times,
+			// timescale and duration: 32-bit in version 0, 64-bit in 1
+			b := box.data
+			if len(b) >= 20 && b[0] == 0 {
+				if scale := binary.BigEndian.Uint32(b[12:16]); scale > 0 {
+					info.duration = float64(binary.BigEndian.Uint32(b[16:20])) / float64(scale)
+				}
+			} else if len(b) >= 32 && b[0] == 1 {
+				if scale := binary.BigEndian.Uint32(b[20:24]); scale > 0 {
+					info.duration = float64(binary.BigEndian.Uint64(b[24:32])) / float64(scale)
+				}
+			}
+			ok = true
+		case "trak":
+			if info.width > 0 {
+				continue
+			}
+			for _, tk := range childBoxes(box.data) {
+				if tk.kind != "tkhd" || len(tk.data) == 0 {
+					continue
+				}
+				// Width and height are 16.16 fixed point after the matrix
+				at := 76
+				if tk.data[0] == 1 {
+					at = 88
+				}
+				if len(tk.data) >= at+8 {
+					info.width = int(binary.BigEndian.Uint32(tk.data[at:at+4]) >> 16)
+					info.height = int(binary.BigEndian.Uint32(tk.data[at+4:at+8]) >> 16)
+				}
+			}
+		}
+	}
+	return info, ok
+}
+
+// box is an ISO media box: its four-character type and payload
+type box struct {
+	kind string
+	data []byte
+}
+
+// childBoxes splits a box payload into the boxes it contains
+func childBoxes(b []byte) []box {
+	var boxes []box
+	for len(b) >= 8 {
+		size := int(binary.BigEndian.Uint32(b[:4]))
+		if size == 0 {
+			size = len(b)
+		}
+		if size < 8 || size > len(b) {
+			break
+		}
+		boxes = append(boxes, box{kind: string(b[4:8]), data: b[8:size]})
+		b = b[size:]
+	}
+	return boxes
+}
@@ -0,0 +1,165 @@
+// internal/downloader/pagination.go
+package downloader
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// nextLabels are link texts that lead to the next page of a gallery
+var nextLabels = map[string]bool{
+	"next": true, "next page": true, "next »": true, "next ›": true, "next →": true, "next >": true,
+	"older": true, "older posts": true, "load more": true, "more": true,
+	"»": true, "›": true, "→": true, ">": true,
+}
+
+// pageParams are query parameters galleries commonly number pages with
+var pageParams = []string{"page", "p", "pg", "paged"}
+
+// pagePath matches a page number in the path, e.g. /gallery/page/2/
+var pagePath = regexp.MustCompile(`(/page/)(\d+)(/|$)`)
+
+// NextPage finds the URL of the page after pageURL in a paginated gallery:
+// a rel="next" link, a link labelled "Next" (or », ›, →), or a link to the
+// same URL with its page number one higher. It returns "" on the last page.
+func NextPage(html, pageURL string) (string, error) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		return "", fmt.Errorf("failed to parse HTML: %w", err)
+	}
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid page URL: %w", err)
+	}
+
+	var links []string
+	doc.Find("a[href]").Each(func(_ int, s *goquery.Selection) {
+		href, _ := s.Attr("href")
+		if resolved := resolveLink(base, href); resolved != "" {
+			links = append(links, resolved)
+		}
+	})
+
+	// rel="next" is the explicit signal, on <link> in the head or on <a>
+	if href, ok := doc.Find(`link[rel~="next"], a[rel~="next"]`).First().Attr("href"); ok {
+		if next := resolveLink(base, href); next != "" && next != base.String() {
+			return next, nil
+		}
+	}
+
+	var next string
+	doc.Find("a[href]").EachWithBreak(func(_ int, s *goquery.Selection) bool {
+		label := strings.ToLower(strings.Join(strings.Fields(s.Text()), " "))
+		if label == "" {
+			label, _ = s.Attr("aria-label")
+			label = strings.ToLower(strings.TrimSpace(label))
+		}
+		if !nextLabels[label] {
+			return true
+		}
+		href, _ := s.Attr("href")
+		if resolved := resolveLink(base, href); resolved != "" && resolved != base.String() {
+			next = resolved
+			return false
+		}
+		return true
+	})
+	if next != "" {
+		return next, nil
+	}
+
+	// Fall back to a link to the next page number, if the page has one
+	for _, candidate := range numberedNext(base) {
+		for _, l := range links {
+			if l == candidate {
+				return candidate, nil
+			}
+		}
+	}
+	return "", nil
+}
+
+// numberedNext returns pageURL with its page number incremented, for each
+// way galleries number pages. A URL without a number is page 1.
+func numberedNext(base *url.URL) []string {
+	var candidates []string
+	query := base.Query()
+	for _, param := range pageParams {
+		n := 1
+		if v := query.Get(param); v != "" {
+			parsed, err := strconv.Atoi(v)
+			if err != nil {
+				continue
+			}
+			n = parsed
+		}
+		u := *base
+		q := u.Query()
+		q.Set(param, strconv.Itoa(n+1))
+		u.RawQuery = q.Encode()
+		candidates = append(candidates, u.String())
+	}
+
+	if m := pagePath.FindStringSubmatchIndex(base.Path); m != nil {
+		n, _ := strconv.Atoi(base.Path[m[4]:m[5]])
+		u := *base
+		u.Path = base.Path[:m[4]] + strconv.Itoa(n+1) + base.Path[m[5]:]
+		candidates = append(candidates, u.String())
+	} else {
+		u := *base
+		u.Path = strings.TrimSuffix(base.Path, "/") + "/page/2/"
+		candidates = append(candidates, u.String(), strings.TrimSuffix(u.String(), "/"))
+	}
+	return candidates
+}
+
+// resolveLink resolves href against base, dropping the fragment and
+// anything that isn't http(s)
+func resolveLink(base *url.URL, href string) string {
+	href = strings.TrimSpace(href)
+	if href == "" || strings.HasPrefix(href, "#") || strings.HasPrefix(strings.ToLower(href), "javascript:") {
+		return ""
+	}
+	u, err := base.Parse(href)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return ""
+	}
+	u.Fragment = ""
+	return u.String()
+}
+
+// Manifest collects the media URLs found across the pages of a gallery, in
+// the order first seen, so each page only adds files not already listed
+type Manifest struct {
+	seen map[string]bool
+	urls []string
+}
+
+// NewManifest creates an empty manifest
+func NewManifest() *Manifest {
+	return &Manifest{seen: make(map[string]bool)}
+}
+
+// Add records a page's media URLs and returns how many were new
+func (m *Manifest) Add(urls []string) int {
+	added := 0
+	for _, u := range urls {
+		if m.seen[u] {
+			continue
+		}
+		m.seen[u] = true
+		m.urls = append(m.urls, u)
+		added++
+	}
+	return added
+}
+
+// URLs returns every URL recorded, in the order first seen
+func (m *Manifest) URLs() []string {
+	return append([]string(nil), m.urls...)
+}
@@ -0,0 +1,80 @@
+package downloader
+
+import "testing"
+
+func TestNextPage(t *testing.T) {
+	tests := []struct {
+		name    string
+		html    string
+		pageURL string
+		want    string
+	}{
+		{
+			name:    "rel next link in head",
+			html:    `<html><head><link rel="next" href="/gallery?page=2"></head><body></body></html>`,
+			pageURL: "https://example.com/gallery",
+			want:    "https://example.com/gallery?page=2",
+		},
+		{
+			name:    "next label",
+			html:    `<body><a href="/about">About</a><a href="album/2">Next »</a></body>`,
+			pageURL: "https://example.com/photos/",
+			want:    "https://example.com/photos/album/2",
+		},
+		{
+			name:    "numbered query link",
+			html:    `<body><a href="?page=2">2</a><a href="?page=4">4</a><a href="?page=3">3</a></body>`,
+			pageURL: "https://example.com/gallery?page=2",
+			want:    "https://example.com/gallery?page=3",
+		},
+		{
+			name:    "numbered path link",
+			html:    `<body><a href="/blog/page/3/">3</a></body>`,
+			pageURL: "https://example.com/blog/page/2/",
+			want:    "https://example.com/blog/page/3/",
+		},
+		{
+			name:    "last page",
+			html:    `<body><a href="?page=1">1</a><a href="?page=2">2</a></body>`,
+			pageURL: "https://example.com/gallery?page=2",
+			want:    "",
+		},
+		{
+			name:    "next link to itself",
+			html:    `<body><a href="#top">Next</a><a href="/gallery?page=2">Next</a></body>`,
+			pageURL: "https://example.com/gallery?page=2",
+			want:    "",
+		},
+	}
+
+	for _, tt := range tests {
+		got, err := NextPage(tt.html, tt.pageURL)
+		if err != nil {
+			t.Fatalf("%s: NextPage failed: %v", tt.name, err)
+		}
+		if got != tt.want {
+			t.Errorf("%s: NextPage() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestManifest_Add(t *testing.T) {
+	m := NewManifest()
+	if added := m.Add([]string{"a.jpg", "b.jpg"}); added != 2 {
+		t.Errorf("Expected 2 new URLs, got %d", added)
+	}
+	if added := m.Add([]string{"b.jpg", "c.jpg", "a.jpg"}); added != 1 {
+		t.Errorf("Expected 1 new URL, got %d", added)
+	}
+
+	urls := m.URLs()
+	want := []string{"a.jpg", "b.jpg", "c.jpg"}
+	if len(urls) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, urls)
+	}
+	for i := range want {
+		if urls[i] != want[i] {
+			t.Errorf("Expected %v, got %v", want, urls)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+// internal/downloader/plan.go
+package downloader
+
+import (
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+)
+
+// PlannedFile is a media URL about to be downloaded, with what a HEAD
+// request said about it
+type PlannedFile struct {
+	URL         string
+	ContentType string
+	Size        int64 // -1 when unknown
+}
+
+// Group is the files sharing a type, extension or host
+type Group struct {
+	Key     string
+	Files   int
+	Bytes   int64 // Total of the known sizes
+	Unknown int   // Files whose size is unknown
+}
+
+// Plan summarizes a batch of media before it is downloaded
+type Plan struct {
+	Files       int
+	Bytes       int64 // Total of the known sizes
+	Unknown     int   // Files whose size is unknown
+	ByType      []Group
+	ByExtension []Group
+	ByHost      []Group
+}
+
+// NewPlan groups files by media type, extension and host, largest first
+func NewPlan(files []PlannedFile) Plan {
+	p := Plan{Files: len(files)}
+	byType := map[string]*Group{}
+	byExt := map[string]*Group{}
+	byHost := map[string]*Group{}
+
+	for _, f := range files {
+		if f.Size >= 0 {
+			p.Bytes += f.Size
+		} else {
+			p.Unknown++
+		}
+		mediaType := string(detectMediaType(f.URL, f.ContentType))
+		if mediaType == string(MediaTypeAll) {
+			mediaType = "other"
+		}
+		ext, host := "(none)", "(unknown)"
+		if u, err := url.Parse(f.URL); err == nil {
+			if e := strings.ToLower(path.Ext(u.Path)); e != "" {
+				ext = e
+			}
+			if u.Host != "" {
+				host = u.Host
+			}
+		}
+		addToGroup(byType, mediaType, f.Size)
+		addToGroup(byExt, ext, f.Size)
+		addToGroup(byHost, host, f.Size)
+	}
+
+	p.ByType = sortedGroups(byType)
+	p.ByExtension = sortedGroups(byExt)
+	p.ByHost = sortedGroups(byHost)
+	return p
+}
+
+// Estimate is the expected total size, counting each file of unknown size
+// as the average of the known ones
+func (p Plan) Estimate() int64 {
+	known := p.Files - p.Unknown
+	if known == 0 {
+		return 0
+	}
+	return p.Bytes + p.Bytes/int64(known)*int64(p.Unknown)
+}
+
+func addToGroup(groups map[string]*Group, key string, size int64) {
+	g := groups[key]
+	if g == nil {
+		g = &Group{Key: key}
+		groups[key] = g
+	}
+	g.Files++
+	if size >= 0 {
+		g.Bytes += size
+	} else {
+		g.Unknown++
+	}
+}
+
+// sortedGroups orders groups by size, then file count, then key
+func sortedGroups(groups map[string]*Group) []Group {
+	list := make([]Group, 0, len(groups))
+	for _, g := range groups {
+		list = append(list, *g)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Bytes != list[j].Bytes {
+			return list[i].Bytes > list[j].Bytes
+		}
+		if list[i].Files != list[j].Files {
+			return list[i].Files > list[j].Files
+		}
+		return list[i].Key < list[j].Key
+	})
+	return list
+}
@@ -0,0 +1,32 @@
+package downloader
+
+import "testing"
+
+func TestNewPlan(t *testing.T) {
+	p := NewPlan([]PlannedFile{
+		{URL: "https://cdn.example.com/a.mp4", ContentType: "video/mp4", Size: 3000},
+		{URL: "https://cdn.example.com/b.MP4", Size: -1},
+		{URL: "https://example.com/c.jpg", ContentType: "image/jpeg", Size: 100},
+		{URL: "https://example.com/download?id=4", ContentType: "image/png", Size: 200},
+	})
+
+	if p.Files != 4 || p.Bytes != 3300 || p.Unknown != 1 {
+		t.Errorf("Unexpected totals: %+v", p)
+	}
+	if got := p.Estimate(); got != 3300+1100 {
+		t.Errorf("Expected the unknown file to count as the average, got %d", got)
+	}
+
+	if len(p.ByType) != 2 || p.ByType[0].Key != "video" || p.ByType[0].Files != 2 || p.ByType[0].Unknown != 1 {
+		t.Errorf("Unexpected type breakdown: %+v", p.ByType)
+	}
+	if p.ByExtension[0].Key != ".mp4" || p.ByExtension[0].Files != 2 {
+		t.Errorf("Expected extensions to be compared case-insensitively, got %+v", p.ByExtension)
+	}
+	if len(p.ByExtension) != 3 || p.ByExtension[1].Key != "(none)" {
+		t.Errorf("Expected URLs without an extension grouped as (none), got %+v", p.ByExtension)
+	}
+	if len(p.ByHost) != 2 || p.ByHost[0].Key != "cdn.example.com" {
+		t.Errorf("Unexpected host breakdown: %+v", p.ByHost)
+	}
+}
@@ -4,11 +4,16 @@ package downloader
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"runtime/debug"
 	"sync"
 	"time"
 
+	"github.com/law-makers/crawl/internal/budget"
+	"github.com/law-makers/crawl/internal/failpolicy"
+	"github.com/law-makers/crawl/internal/memguard"
 	"github.com/law-makers/crawl/internal/ratelimit"
+	"github.com/law-makers/crawl/internal/retry"
 	"github.com/rs/zerolog/log"
 	"github.com/schollz/progressbar/v3"
 )
@@ -17,7 +22,12 @@ import (
 type WorkerPool struct {
 	downloader  *Downloader
 	concurrency int
-	rateLimiter *ratelimit.DomainLimiter
+	rateLimiter ratelimit.RateLimiter
+	hostSlots   *ratelimit.DomainConcurrency
+	budget      budget.Budget
+	memory      *memguard.Guard
+	hideBar     bool
+	plainBar    bool
 }
 
 // NewWorkerPool creates a new worker pool with specified concurrency
@@ -36,6 +46,64 @@ func NewWorkerPool(concurrency int, timeout time.Duration, userAgent string) *Wo
 	}
 }
 
+// SetRateLimit gives the pool its own per-host request rate for downloads.
+// A non-positive rate disables rate limiting entirely.
+func (wp *WorkerPool) SetRateLimit(requestsPerSecond float64, burst int) {
+	if requestsPerSecond <= 0 {
+		wp.rateLimiter = nil
+		return
+	}
+	wp.rateLimiter = ratelimit.NewDomainLimiter(requestsPerSecond, burst)
+}
+
+// SetRateLimiter makes downloads share rl, typically the application's
+// limiter so per-domain settings and policy ceilings apply to them too.
+// A nil value keeps the pool's own limiter.
+func (wp *WorkerPool) SetRateLimiter(rl ratelimit.RateLimiter) {
+	if rl != nil {
+		wp.rateLimiter = rl
+	}
+}
+
+// SetConcurrency caps simultaneous downloads per host, independent of the
+// number of workers. A nil value removes the cap.
+func (wp *WorkerPool) SetConcurrency(dc *ratelimit.DomainConcurrency) {
+	wp.hostSlots = dc
+}
+
+// SetBudget limits each batch to a maximum run time and number of downloads.
+// Downloads that cannot start within the budget are reported as Skipped;
+// downloads already in progress are allowed to finish.
+func (wp *WorkerPool) SetBudget(b budget.Budget) {
+	wp.budget = b
+}
+
+// SetMemoryGuard holds back new downloads while the guard reports memory
+// use over its limit
+func (wp *WorkerPool) SetMemoryGuard(g *memguard.Guard) {
+	wp.memory = g
+}
+
+// SetClient replaces the pool's HTTP client, typically with one sharing the
+// application transport so connections and proxy settings are reused
+func (wp *WorkerPool) SetClient(client *http.Client) {
+	if client != nil {
+		wp.downloader.client = client
+	}
+}
+
+// SetRetryConfig sets the retry policy applied to every download in the pool
+func (wp *WorkerPool) SetRetryConfig(cfg retry.Config) {
+	wp.downloader.SetRetryConfig(cfg)
+}
+
+// SetProgress controls the progress bar: show false hides it (for --quiet),
+// color false draws it without ANSI color codes (for NO_COLOR)
+func (wp *WorkerPool) SetProgress(show, color bool) {
+	wp.hideBar = !show
+	wp.plainBar = !color
+}
+
 // DownloadBatch downloads multiple files concurrently using the worker pool
 func (wp *WorkerPool) DownloadBatch(ctx context.Context, urls []string, opts DownloadOptions) []*DownloadResult {
 	if len(urls) == 0 {
@@ -43,6 +111,16 @@ func (wp *WorkerPool) DownloadBatch(ctx context.Context, urls []string, opts Dow
 	}
 
 	// Create progress bar
+	theme := progressbar.Theme{
+		Saucer:        "[green]=[reset]",
+		SaucerHead:    "[green]>[reset]",
+		SaucerPadding: " ",
+		BarStart:      "[",
+		BarEnd:        "]",
+	}
+	if wp.plainBar {
+		theme.Saucer, theme.SaucerHead = "=", ">"
+	}
 	bar := progressbar.NewOptions(len(urls),
 		progressbar.OptionSetDescription("Downloading"),
 		progressbar.OptionShowCount(),
@@ -51,14 +129,9 @@ func (wp *WorkerPool) DownloadBatch(ctx context.Context, urls []string, opts Dow
 		progressbar.OptionThrottle(100*time.Millisecond),
 		progressbar.OptionSetPredictTime(true),
 		progressbar.OptionSetRenderBlankState(true),
-		progressbar.OptionEnableColorCodes(true),
-		progressbar.OptionSetTheme(progressbar.Theme{
-			Saucer:        "[green]=[reset]",
-			SaucerHead:    "[green]>[reset]",
-			SaucerPadding: " ",
-			BarStart:      "[",
-			BarEnd:        "]",
-		}),
+		progressbar.OptionEnableColorCodes(!wp.plainBar),
+		progressbar.OptionSetTheme(theme),
+		progressbar.OptionSetVisibility(!wp.hideBar),
 	)
 
 	// Create channels for job distribution
@@ -67,9 +140,10 @@ func (wp *WorkerPool) DownloadBatch(ctx context.Context, urls []string, opts Dow
 
 	// Start workers
 	var wg sync.WaitGroup
+	tracker := budget.Start(wp.budget)
 	for w := 1; w <= wp.concurrency; w++ {
 		wg.Add(1)
-		go wp.worker(ctx, w, jobs, results, opts, &wg, bar)
+		go wp.worker(ctx, w, jobs, results, opts, tracker, &wg, bar)
 	}
 
 	// Send jobs to workers
@@ -99,7 +173,7 @@ func (wp *WorkerPool) DownloadBatch(ctx context.Context, urls []string, opts Dow
 }
 
 // worker processes download jobs from the jobs channel
-func (wp *WorkerPool) worker(ctx context.Context, id int, jobs <-chan string, results chan<- *DownloadResult, opts DownloadOptions, wg *sync.WaitGroup, bar *progressbar.ProgressBar) {
+func (wp *WorkerPool) worker(ctx context.Context, id int, jobs <-chan string, results chan<- *DownloadResult, opts DownloadOptions, tracker *budget.Tracker, wg *sync.WaitGroup, bar *progressbar.ProgressBar) {
 	defer wg.Done()
 
 	log.Debug().Int("worker_id", id).Msg("Worker started")
@@ -107,13 +181,17 @@ func (wp *WorkerPool) worker(ctx context.Context, id int, jobs <-chan string, re
 	for url := range jobs {
 		currentURL := url
 
-		// Check if context is cancelled
+		// Check if context is cancelled, and wait out memory pressure
 		select {
 		case <-ctx.Done():
 			log.Debug().Int("worker_id", id).Msg("Worker cancelled")
 			return
 		default:
 		}
+		if err := wp.memory.Wait(ctx); err != nil {
+			log.Debug().Int("worker_id", id).Msg("Worker cancelled")
+			return
+		}
 
 		// Process each job inside its own recover-protected function so a panic doesn't kill the worker
 		func() {
@@ -149,15 +227,49 @@ func (wp *WorkerPool) worker(ctx context.Context, id int, jobs <-chan string, re
 				Str("url", url).
 				Msg("Worker processing download")
 
+			// Skip the download once the run budget is used up
+			if err := tracker.Take(); err != nil {
+				if bar != nil {
+					bar.Add(1)
+				}
+				select {
+				case results <- &DownloadResult{URL: url, Skipped: true, Error: err}:
+				case <-ctx.Done():
+				}
+				return
+			}
+
 			// Apply rate limiting before download
+			waitStart := time.Now()
 			if wp.rateLimiter != nil {
 				if err := wp.rateLimiter.Wait(ctx, url); err != nil {
 					log.Warn().Err(err).Str("url", url).Msg("Rate limit error")
 				}
 			}
 
+			// Hold a per-host slot for the duration of the download
+			release, err := wp.hostSlots.Acquire(ctx, url)
+			if err != nil {
+				result := &DownloadResult{URL: url, Success: false, Error: err}
+				if bar != nil {
+					bar.Add(1)
+				}
+				select {
+				case results <- result:
+				case <-ctx.Done():
+				}
+				return
+			}
+
+			waited := time.Since(waitStart)
+
 			// Download the file
 			result := wp.downloader.Download(ctx, url, opts)
+			result.Waited = waited
+			release()
+			if opts.FailFast && !result.Success {
+				tracker.Stop(failpolicy.ErrAborted)
+			}
 
 			// Update progress bar
 			if bar != nil {
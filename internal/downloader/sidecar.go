@@ -0,0 +1,137 @@
+// internal/downloader/sidecar.go
+package downloader
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"image"
+	_ "image/gif" // decoders for image.DecodeConfig
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	_ "golang.org/x/image/webp"
+)
+
+// SidecarSuffix is added to a downloaded file's name to name its sidecar
+const SidecarSuffix = ".json"
+
+// MediaSource is where a media URL was found and what the page says about it
+type MediaSource struct {
+	Page    string // URL of the page the media is on
+	Alt     string // alt text of an image, aria-label of a player
+	Title   string // title attribute
+	Caption string // <figcaption> of the figure it is in
+}
+
+// Sidecar is the provenance of a downloaded file, written next to it as
+// JSON so it survives the file landing in a flat directory
+type Sidecar struct {
+	File         string    `json:"file"`           // Name of the file, in the same directory
+	URL          string    `json:"url"`            // Media URL
+	Page         string    `json:"page,omitempty"` // Page it was found on
+	Alt          string    `json:"alt,omitempty"`
+	Title        string    `json:"title,omitempty"`
+	Caption      string    `json:"caption,omitempty"`
+	ContentType  string    `json:"content_type,omitempty"`
+	Size         int64     `json:"size"`
+	Width        int       `json:"width,omitempty"` // Pixels, for images and MP4/MOV video
+	Height       int       `json:"height,omitempty"`
+	Duration     float64   `json:"duration_seconds,omitempty"` // For MP4, MOV and M4A files
+	SHA256       string    `json:"sha256"`
+	DownloadedAt time.Time `json:"downloaded_at"`
+}
+
+// DescribeMedia returns what the DOM of the page at pageURL says about each
+// media URL in it, keyed by the resolved URL. srcset candidates share their
+// element's description.
+func DescribeMedia(html, pageURL string) (map[string]MediaSource, error) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse HTML: %w", err)
+	}
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base URL: %w", err)
+	}
+
+	sources := map[string]MediaSource{}
+	add := func(href string, src MediaSource) {
+		if u := resolveURL(base, strings.TrimSpace(href)); u != "" {
+			if _, seen := sources[u]; !seen {
+				sources[u] = src
+			}
+		}
+	}
+	doc.Find("img, video, audio").Each(func(_ int, el *goquery.Selection) {
+		src := MediaSource{
+			Page:    pageURL,
+			Alt:     strings.TrimSpace(el.AttrOr("alt", el.AttrOr("aria-label", ""))),
+			Title:   strings.TrimSpace(el.AttrOr("title", "")),
+			Caption: strings.Join(strings.Fields(el.Closest("figure").Find("figcaption").First().Text()), " "),
+		}
+		add(el.AttrOr("src", ""), src)
+		for _, u := range parseSrcset(el.AttrOr("srcset", ""), base) {
+			add(u, src)
+		}
+		el.Find("source").Each(func(_ int, s *goquery.Selection) {
+			add(s.AttrOr("src", ""), src)
+		})
+	})
+	return sources, nil
+}
+
+// WriteSidecar writes the sidecar of a downloaded file next to it, reading
+// the file for its hash and dimensions, and returns the sidecar's path
+func WriteSidecar(result *DownloadResult, src MediaSource) (string, error) {
+	f, err := os.Open(result.FilePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open %s: %w", result.FilePath, err)
+	}
+	defer f.Close()
+
+	hasher := sha256.New()
+	size, err := io.Copy(hasher, f)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash %s: %w", result.FilePath, err)
+	}
+	sc := Sidecar{
+		File:         filepath.Base(result.FilePath),
+		URL:          result.URL,
+		Page:         src.Page,
+		Alt:          src.Alt,
+		Title:        src.Title,
+		Caption:      src.Caption,
+		ContentType:  result.ContentType,
+		Size:         size,
+		SHA256:       hex.EncodeToString(hasher.Sum(nil)),
+		DownloadedAt: result.StartTime,
+	}
+
+	if _, err := f.Seek(0, io.SeekStart); err == nil {
+		if cfg, format, err := image.DecodeConfig(f); err == nil {
+			// Post-processing may have converted the image
+			sc.Width, sc.Height, sc.ContentType = cfg.Width, cfg.Height, "image/"+format
+		} else if info, ok := probeMP4(f, size); ok {
+			sc.Width, sc.Height, sc.Duration = info.width, info.height, info.duration
+		}
+	}
+
+	data, err := json.MarshalIndent(sc, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode sidecar: %w", err)
+	}
+	path := result.FilePath + SidecarSuffix
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return "", fmt.Errorf("failed to write sidecar: %w", err)
+	}
+	return path, nil
+}
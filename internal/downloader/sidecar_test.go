@@ -0,0 +1,122 @@
+package downloader
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDescribeMedia(t *testing.T) {
+	html := `
+	<html>
+		<body>
+			<figure>
+				<img src="/a.jpg" srcset="/a-2x.jpg 2x" alt=" A cat " title="Cat">
+				<figcaption>The   cat,
+					asleep</figcaption>
+			</figure>
+			<video aria-label="Intro"><source src="intro.mp4"></video>
+			<img src="/a.jpg" alt="Duplicate">
+		</body>
+	</html>
+	`
+
+	sources, err := DescribeMedia(html, "https://example.com/pets/")
+	if err != nil {
+		t.Fatalf("DescribeMedia failed: %v", err)
+	}
+
+	cat := sources["https://example.com/a.jpg"]
+	if cat.Alt != "A cat" || cat.Title != "Cat" || cat.Caption != "The cat, asleep" {
+		t.Errorf("Unexpected description for a.jpg: %+v", cat)
+	}
+	if cat.Page != "https://example.com/pets/" {
+		t.Errorf("Expected page URL, got %q", cat.Page)
+	}
+	if sources["https://example.com/a-2x.jpg"] != cat {
+		t.Errorf("Expected srcset candidate to share the description, got %+v", sources["https://example.com/a-2x.jpg"])
+	}
+	if got := sources["https://example.com/pets/intro.mp4"].Alt; got != "Intro" {
+		t.Errorf("Expected aria-label for video source, got %q", got)
+	}
+}
+
+func TestWriteSidecar_Image(t *testing.T) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 3, 2))); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "dot.png")
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	result := &DownloadResult{URL: "https://example.com/dot.png", FilePath: path, ContentType: "application/octet-stream"}
+	sidecarPath, err := WriteSidecar(result, MediaSource{Page: "https://example.com/", Alt: "Dot"})
+	if err != nil {
+		t.Fatalf("WriteSidecar failed: %v", err)
+	}
+	if sidecarPath != path+SidecarSuffix {
+		t.Errorf("Expected sidecar at %s, got %s", path+SidecarSuffix, sidecarPath)
+	}
+
+	data, err := os.ReadFile(sidecarPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sc Sidecar
+	if err := json.Unmarshal(data, &sc); err != nil {
+		t.Fatalf("Invalid sidecar JSON: %v", err)
+	}
+	if sc.File != "dot.png" || sc.Alt != "Dot" || sc.Page != "https://example.com/" {
+		t.Errorf("Unexpected provenance: %+v", sc)
+	}
+	if sc.Width != 3 || sc.Height != 2 || sc.ContentType != "image/png" {
+		t.Errorf("Expected 3x2 image/png, got %dx%d %s", sc.Width, sc.Height, sc.ContentType)
+	}
+	if sc.Size != int64(buf.Len()) || len(sc.SHA256) != 64 {
+		t.Errorf("Unexpected size or hash: %d %q", sc.Size, sc.SHA256)
+	}
+}
+
+func TestProbeMP4(t *testing.T) {
+	mkbox := func(kind string, payload ...[]byte) []byte {
+		body := bytes.Join(payload, nil)
+		b := make([]byte, 8, 8+len(body))
+		binary.BigEndian.PutUint32(b, uint32(8+len(body)))
+		copy(b[4:], kind)
+		return append(b, body...)
+	}
+
+	// Version 0 mvhd: timescale 1000, duration 2500
+	mvhd := make([]byte, 100)
+	binary.BigEndian.PutUint32(mvhd[12:], 1000)
+	binary.BigEndian.PutUint32(mvhd[16:], 2500)
+	// Version 0 tkhd: 640x360 in 16.16 fixed point
+	tkhd := make([]byte, 84)
+	binary.BigEndian.PutUint32(tkhd[76:], 640<<16)
+	binary.BigEndian.PutUint32(tkhd[80:], 360<<16)
+
+	file := bytes.Join([][]byte{
+		mkbox("ftyp", []byte("isom\x00\x00\x02\x00")),
+		mkbox("mdat", make([]byte, 32)),
+		mkbox("moov", mkbox("mvhd", mvhd), mkbox("trak", mkbox("tkhd", tkhd))),
+	}, nil)
+
+	info, ok := probeMP4(bytes.NewReader(file), int64(len(file)))
+	if !ok {
+		t.Fatal("Expected MP4 to be recognized")
+	}
+	if info.duration != 2.5 || info.width != 640 || info.height != 360 {
+		t.Errorf("Expected 640x360 2.5s, got %+v", info)
+	}
+
+	if _, ok := probeMP4(bytes.NewReader([]byte("not a movie at all")), 18); ok {
+		t.Error("Expected non-MP4 data to be rejected")
+	}
+}
@@ -0,0 +1,81 @@
+// internal/downloader/state.go
+package downloader
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// StateFileName is the name of the incremental state file kept in the output directory
+const StateFileName = ".crawl-state.json"
+
+// StateEntry records what was last downloaded for a URL
+type StateEntry struct {
+	File         string    `json:"file"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"last_modified,omitempty"`
+	SHA256       string    `json:"sha256,omitempty"`
+	Size         int64     `json:"size"`
+	FetchedAt    time.Time `json:"fetched_at"`
+}
+
+// State tracks validators and content hashes of previous downloads so that
+// unchanged files can be skipped on later runs. It is safe for concurrent use.
+type State struct {
+	path    string
+	mu      sync.Mutex
+	entries map[string]StateEntry
+}
+
+// LoadState reads the state file at path. A missing file yields an empty state.
+func LoadState(path string) (*State, error) {
+	st := &State{
+		path:    path,
+		entries: make(map[string]StateEntry),
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return st, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &st.entries); err != nil {
+		return nil, err
+	}
+	return st, nil
+}
+
+// Get returns the recorded entry for url
+func (s *State) Get(url string) (StateEntry, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, ok := s.entries[url]
+	return entry, ok
+}
+
+// Put records the entry for url
+func (s *State) Put(url string, entry StateEntry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[url] = entry
+}
+
+// Save writes the state back to disk
+func (s *State) Save() error {
+	s.mu.Lock()
+	data, err := json.MarshalIndent(s.entries, "", "  ")
+	s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(s.path, data, 0644)
+}
@@ -0,0 +1,83 @@
+// internal/engine/batch/inflight.go
+package batch
+
+import (
+	"encoding/json"
+	"sync"
+
+	"github.com/law-makers/crawl/pkg/models"
+)
+
+// inflight deduplicates concurrent fetches of the same key: the first caller
+// runs the fetch and callers arriving while it is in progress share its result.
+// Once a fetch completes the key is forgotten, so later requests fetch again.
+type inflight struct {
+	mu    sync.Mutex
+	calls map[string]*inflightCall
+}
+
+type inflightCall struct {
+	done chan struct{}
+	data *models.PageData
+	err  error
+}
+
+// do runs fn once per key among concurrent callers. shared reports whether
+// the result came from another caller's fetch. Every caller, the one that
+// ran fn included, gets its own copy of the page, so checks it adds and
+// text it masks don't show up on the others while they are still copying.
+func (f *inflight) do(key string, fn func() (*models.PageData, error)) (data *models.PageData, shared bool, err error) {
+	f.mu.Lock()
+	if call, ok := f.calls[key]; ok {
+		f.mu.Unlock()
+		<-call.done
+		return clonePage(call.data), true, call.err
+	}
+	if f.calls == nil {
+		f.calls = make(map[string]*inflightCall)
+	}
+	call := &inflightCall{done: make(chan struct{})}
+	f.calls[key] = call
+	f.mu.Unlock()
+
+	defer func() {
+		f.mu.Lock()
+		delete(f.calls, key)
+		f.mu.Unlock()
+		close(call.done)
+	}()
+	call.data, call.err = fn()
+	return clonePage(call.data), false, call.err
+}
+
+// requestKey identifies what a request fetches: every option that can change
+// the page, but not the labels (attempt, IDs, trace) that only describe it
+func requestKey(r models.RequestOptions) string {
+	r.Attempt, r.JobID, r.RequestID, r.Trace = 0, "", "", nil
+	key, err := json.Marshal(r)
+	if err != nil {
+		return r.URL + "\x00" + err.Error()
+	}
+	return string(key)
+}
+
+// clonePage copies p deeply enough that callers can add assertion results
+// and mask text on their copy without touching the original
+func clonePage(p *models.PageData) *models.PageData {
+	if p == nil {
+		return nil
+	}
+	c := *p
+	c.Assertions = append([]models.AssertionResult(nil), p.Assertions...)
+	c.Data = append([]models.SelectionData(nil), p.Data...)
+	if p.Structured != nil {
+		c.Structured = make([]map[string]string, len(p.Structured))
+		for i, row := range p.Structured {
+			c.Structured[i] = make(map[string]string, len(row))
+			for k, v := range row {
+				c.Structured[i][k] = v
+			}
+		}
+	}
+	return &c
+}
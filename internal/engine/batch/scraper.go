@@ -5,7 +5,11 @@ import (
 	"context"
 	"sync"
 
+	"github.com/law-makers/crawl/internal/budget"
+	"github.com/law-makers/crawl/internal/failpolicy"
+	"github.com/law-makers/crawl/internal/memguard"
 	"github.com/law-makers/crawl/pkg/models"
+	"github.com/rs/zerolog/log"
 )
 
 // Scraper interface defines what a scraper must implement
@@ -17,6 +21,10 @@ type ScraperInterface interface {
 type Scraper struct {
 	scraper     ScraperInterface
 	concurrency int
+	budget      budget.Budget
+	failFast    bool
+	memory      *memguard.Guard
+	inflight    inflight
 }
 
 // New creates a new BatchScraper
@@ -31,6 +39,25 @@ func New(scraper ScraperInterface, concurrency int) *Scraper {
 	}
 }
 
+// SetBudget limits the run time and number of requests for each batch.
+// Requests that cannot start within the budget are returned with an error
+// wrapping budget.ErrExceeded; requests already in flight are not cancelled.
+func (s *Scraper) SetBudget(b budget.Budget) {
+	s.budget = b
+}
+
+// SetFailFast stops starting new requests after the first failure. Requests
+// that never start are returned with an error wrapping failpolicy.ErrAborted.
+func (s *Scraper) SetFailFast(failFast bool) {
+	s.failFast = failFast
+}
+
+// SetMemoryGuard holds back new requests while the guard reports memory
+// use over its limit. Requests in flight are not affected.
+func (s *Scraper) SetMemoryGuard(g *memguard.Guard) {
+	s.memory = g
+}
+
 // ScrapeBatch processes a list of requests concurrently
 // Requests are grouped by domain to leverage HTTP/2 multiplexing
 func (s *Scraper) ScrapeBatch(ctx context.Context, requests []models.RequestOptions) <-chan models.ScrapeResult {
@@ -40,6 +67,7 @@ func (s *Scraper) ScrapeBatch(ctx context.Context, requests []models.RequestOpti
 	domainGroups := GroupByDomain(requests)
 
 	var wg sync.WaitGroup
+	tracker := budget.Start(s.budget)
 
 	go func() {
 		// Process each domain group
@@ -56,18 +84,21 @@ func (s *Scraper) ScrapeBatch(ctx context.Context, requests []models.RequestOpti
 			sem := make(chan struct{}, s.concurrency)
 
 			for _, req := range groupRequests {
-				wg.Add(1)
+				if err := s.memory.Wait(ctx); err != nil {
+					break
+				}
 				sem <- struct{}{} // Acquire semaphore
+				if err := tracker.Take(); err != nil {
+					<-sem
+					results <- models.ScrapeResult{URL: req.URL, Error: err}
+					continue
+				}
+				wg.Add(1)
 
 				go func(r models.RequestOptions, d string) {
 					defer wg.Done()
 					defer func() { <-sem }() // Release semaphore
-
-					data, err := s.scraper.Fetch(r)
-					results <- models.ScrapeResult{
-						Data:  data,
-						Error: err,
-					}
+					results <- s.fetch(r, tracker)
 				}(req, domain)
 			}
 		}
@@ -78,3 +109,77 @@ func (s *Scraper) ScrapeBatch(ctx context.Context, requests []models.RequestOpti
 
 	return results
 }
+
+// ScrapeStream fetches requests as they arrive and emits each result as soon
+// as it completes, so callers can process URLs that are still being
+// produced. Results follow completion order. The returned channel is closed
+// once requests is closed and all fetches have finished, or ctx is done.
+func (s *Scraper) ScrapeStream(ctx context.Context, requests <-chan models.RequestOptions) <-chan models.ScrapeResult {
+	results := make(chan models.ScrapeResult, s.concurrency)
+	sem := make(chan struct{}, s.concurrency)
+
+	var wg sync.WaitGroup
+	tracker := budget.Start(s.budget)
+
+	go func() {
+		defer func() {
+			wg.Wait()
+			close(results)
+		}()
+
+		for {
+			var req models.RequestOptions
+			select {
+			case <-ctx.Done():
+				return
+			case r, ok := <-requests:
+				if !ok {
+					return
+				}
+				req = r
+			}
+
+			if err := s.memory.Wait(ctx); err != nil {
+				return
+			}
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			if err := tracker.Take(); err != nil {
+				<-sem
+				results <- models.ScrapeResult{URL: req.URL, Error: err}
+				continue
+			}
+			wg.Add(1)
+
+			go func(r models.RequestOptions) {
+				defer wg.Done()
+				defer func() { <-sem }()
+				results <- s.fetch(r, tracker)
+			}(req)
+		}
+	}()
+
+	return results
+}
+
+// fetch runs one request; identical requests running at the same time share
+// one fetch. In fail-fast mode a failure stops the tracker.
+func (s *Scraper) fetch(r models.RequestOptions, tracker *budget.Tracker) models.ScrapeResult {
+	data, shared, err := s.inflight.do(requestKey(r), func() (*models.PageData, error) {
+		return s.scraper.Fetch(r)
+	})
+	if shared {
+		log.Debug().Str("url", r.URL).Msg("Shared result of in-flight fetch")
+	}
+	if err != nil && s.failFast {
+		tracker.Stop(failpolicy.ErrAborted)
+	}
+	return models.ScrapeResult{
+		URL:   r.URL,
+		Data:  data,
+		Error: err,
+	}
+}
@@ -3,9 +3,12 @@ package batch
 import (
 	"context"
 	"errors"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/law-makers/crawl/internal/budget"
+	"github.com/law-makers/crawl/internal/failpolicy"
 	"github.com/law-makers/crawl/pkg/models"
 )
 
@@ -49,3 +52,178 @@ func TestBatchScraper(t *testing.T) {
 		t.Errorf("Expected 1 error, got %d", errors)
 	}
 }
+
+func TestBatchScraper_Budget(t *testing.T) {
+	batch := New(&mockScraper{}, 1)
+	batch.SetBudget(budget.Budget{MaxRequests: 2})
+
+	requests := []models.RequestOptions{{URL: "url1"}, {URL: "url2"}, {URL: "url3"}, {URL: "url4"}}
+
+	fetched, skipped := 0, 0
+	for res := range batch.ScrapeBatch(context.Background(), requests) {
+		if errors.Is(res.Error, budget.ErrExceeded) {
+			skipped++
+			if res.URL == "" {
+				t.Error("Expected skipped result to carry its URL")
+			}
+		} else if res.Error == nil {
+			fetched++
+		}
+	}
+	if fetched != 2 || skipped != 2 {
+		t.Errorf("Expected 2 fetched and 2 skipped, got %d and %d", fetched, skipped)
+	}
+}
+
+// countingScraper counts real fetches per URL
+type countingScraper struct {
+	mu    sync.Mutex
+	calls map[string]int
+}
+
+func (c *countingScraper) Fetch(opts models.RequestOptions) (*models.PageData, error) {
+	c.mu.Lock()
+	c.calls[opts.URL]++
+	c.mu.Unlock()
+	time.Sleep(50 * time.Millisecond)
+	return &models.PageData{URL: opts.URL}, nil
+}
+
+func TestBatchScraper_DeduplicatesConcurrentFetches(t *testing.T) {
+	scraper := &countingScraper{calls: map[string]int{}}
+	batch := New(scraper, 5)
+
+	requests := []models.RequestOptions{
+		{URL: "https://example.com/a"},
+		{URL: "https://example.com/a"},
+		{URL: "https://example.com/a"},
+		{URL: "https://example.com/a", Selector: ".price"},
+		{URL: "https://example.com/a", Headers: map[string]string{"Accept-Language": "de"}},
+		{URL: "https://example.com/b"},
+	}
+
+	count := 0
+	pages := map[*models.PageData]bool{}
+	for res := range batch.ScrapeBatch(context.Background(), requests) {
+		count++
+		if res.Error != nil || res.Data == nil || res.Data.URL != res.URL {
+			t.Errorf("Unexpected result for %s: %+v", res.URL, res)
+		}
+		if pages[res.Data] {
+			t.Errorf("Expected each result for %s to have its own page", res.URL)
+		}
+		pages[res.Data] = true
+	}
+
+	if count != len(requests) {
+		t.Errorf("Expected %d results, got %d", len(requests), count)
+	}
+	if n := scraper.calls["https://example.com/a"]; n != 3 {
+		t.Errorf("Expected 3 fetches of /a (one per selector and header set), got %d", n)
+	}
+	if n := scraper.calls["https://example.com/b"]; n != 1 {
+		t.Errorf("Expected 1 fetch of /b, got %d", n)
+	}
+}
+
+// TestInflight_CallersGetTheirOwnPage mutates each returned page the way the
+// CLI adds assertions and redacts text; run with -race to catch sharing
+func TestInflight_CallersGetTheirOwnPage(t *testing.T) {
+	var f inflight
+	release := make(chan struct{})
+	fetch := func() (*models.PageData, error) {
+		<-release
+		return &models.PageData{
+			URL:        "https://example.com/a",
+			Data:       []models.SelectionData{{Text: "call 555-0100"}},
+			Structured: []map[string]string{{"phone": "555-0100"}},
+		}, nil
+	}
+
+	const callers = 8
+	var wg sync.WaitGroup
+	pages := make([]*models.PageData, callers)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			page, _, err := f.do("a", fetch)
+			if err != nil {
+				t.Errorf("Unexpected error: %v", err)
+				return
+			}
+			page.Data[0].Text = "call [redacted]"
+			page.Structured[0]["phone"] = "[redacted]"
+			page.Assertions = append(page.Assertions, models.AssertionResult{Passed: true})
+			pages[i] = page
+		}(i)
+	}
+	time.Sleep(20 * time.Millisecond) // let the callers pile up on the fetch
+	close(release)
+	wg.Wait()
+
+	seen := map[*models.PageData]bool{}
+	for _, page := range pages {
+		if page == nil {
+			continue
+		}
+		if seen[page] {
+			t.Error("Expected every caller to get its own page")
+		}
+		seen[page] = true
+		if len(page.Assertions) != 1 {
+			t.Errorf("Expected 1 assertion on each page, got %d", len(page.Assertions))
+		}
+	}
+}
+
+func TestBatchScraper_StreamEmitsBeforeInputEnds(t *testing.T) {
+	batch := New(&mockScraper{}, 2)
+	requests := make(chan models.RequestOptions)
+	results := batch.ScrapeStream(context.Background(), requests)
+
+	// A result must arrive while the input is still open
+	requests <- models.RequestOptions{URL: "url1"}
+	select {
+	case res := <-results:
+		if res.URL != "url1" || res.Error != nil {
+			t.Errorf("Unexpected result: %+v", res)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected a result before the input was closed")
+	}
+
+	requests <- models.RequestOptions{URL: "url2"}
+	requests <- models.RequestOptions{URL: "error"}
+	close(requests)
+
+	count, failed := 0, 0
+	for res := range results {
+		count++
+		if res.Error != nil {
+			failed++
+		}
+	}
+	if count != 2 || failed != 1 {
+		t.Errorf("Expected 2 more results with 1 error, got %d and %d", count, failed)
+	}
+}
+
+func TestBatchScraper_FailFast(t *testing.T) {
+	batch := New(&mockScraper{}, 1)
+	batch.SetFailFast(true)
+
+	requests := []models.RequestOptions{{URL: "error"}, {URL: "error"}, {URL: "error"}}
+	failed, aborted := 0, 0
+	for res := range batch.ScrapeBatch(context.Background(), requests) {
+		switch {
+		case errors.Is(res.Error, failpolicy.ErrAborted):
+			aborted++
+		case res.Error != nil:
+			failed++
+		}
+	}
+	if failed != 1 || aborted != 2 {
+		t.Errorf("Expected 1 failure and 2 aborted requests, got %d and %d", failed, aborted)
+	}
+}
@@ -4,6 +4,7 @@ package dynamic
 import (
 	"context"
 	"fmt"
+	"runtime"
 	"sync"
 	"time"
 
@@ -14,28 +15,63 @@ import (
 
 // BrowserPool manages a pool of reusable Chrome browser contexts
 // This dramatically reduces startup overhead from ~1500ms to ~50ms per request
+//
+// The pool is elastic: it starts with MinSize contexts, opens more up to
+// Size while requests are waiting for one, and closes contexts that stay
+// idle for IdleTimeout until it is back at MinSize.
 type BrowserPool struct {
-	size        int
+	size        int // contexts open, idle or in use
+	min, max    int
+	idleTimeout time.Duration
 	contexts    chan *BrowserContext
 	allocCtx    context.Context
 	allocCancel context.CancelFunc
+	create      func() (*BrowserContext, error)
 	mu          sync.Mutex
 	closed      bool
+	done        chan struct{}
+
+	waiting    int
+	peak       int
+	scaleUps   int
+	scaleDowns int
 }
 
 // BrowserContext wraps a chromedp context with its cancel function
 type BrowserContext struct {
-	Ctx    context.Context
-	Cancel context.CancelFunc
+	Ctx       context.Context
+	Cancel    context.CancelFunc
+	idleSince time.Time
+}
+
+// PoolStats reports the pool's size and scaling activity
+type PoolStats struct {
+	Size       int `json:"size"`
+	Min        int `json:"min"`
+	Max        int `json:"max"`
+	Idle       int `json:"idle"`
+	Waiting    int `json:"waiting"`
+	Peak       int `json:"peak"`
+	ScaleUps   int `json:"scale_ups"`
+	ScaleDowns int `json:"scale_downs"`
 }
 
+// DefaultIdleTimeout is how long a context above MinSize may sit unused
+// before it is closed
+const DefaultIdleTimeout = time.Minute
+
 // BrowserPoolOptions configures the browser pool
 type BrowserPoolOptions struct {
-	Size      int
+	Size      int // Most contexts open at once
 	Headless  bool
 	UserAgent string
 	Proxy     string
+	RemoteURL string // CDP endpoint of a running browser (ws:// or http://host:9222); launch options are ignored
+	DevTools  bool   // Open DevTools in each tab (requires Headless false)
 	ExtraArgs []chromedp.ExecAllocatorOption
+
+	MinSize     int           // Contexts kept open when idle (default 1, at most Size)
+	IdleTimeout time.Duration // Idle time before a context above MinSize is closed (default 1m)
 }
 
 // NewBrowserPool creates a new pool of browser contexts
@@ -46,12 +82,101 @@ func NewBrowserPool(opts BrowserPoolOptions) (*BrowserPool, error) {
 	if opts.Size > 10 {
 		opts.Size = 10 // Max 10 contexts to avoid resource exhaustion
 	}
+	if opts.MinSize <= 0 {
+		opts.MinSize = 1
+	}
+	if opts.MinSize > opts.Size {
+		opts.MinSize = opts.Size
+	}
+	if opts.IdleTimeout <= 0 {
+		opts.IdleTimeout = DefaultIdleTimeout
+	}
 	if opts.UserAgent == "" {
 		opts.UserAgent = config.DefaultUserAgent
 	}
 
-	log.Debug().Int("size", opts.Size).Msg("Creating browser pool")
+	log.Debug().Int("min", opts.MinSize).Int("max", opts.Size).Msg("Creating browser pool")
+
+	// Attach to a running browser when a CDP endpoint is configured,
+	// otherwise launch our own
+	var allocCtx context.Context
+	var allocCancel context.CancelFunc
+	if opts.RemoteURL != "" {
+		allocCtx, allocCancel = chromedp.NewRemoteAllocator(context.Background(), opts.RemoteURL)
+		log.Debug().Str("url", opts.RemoteURL).Msg("Using remote browser")
+	} else {
+		sweepOnStart()
+		allocCtx, allocCancel = newExecAllocator(opts)
+	}
+
+	pool := newPool(opts.MinSize, opts.Size, opts.IdleTimeout, func() (*BrowserContext, error) {
+		return newBrowserContext(allocCtx)
+	})
+	pool.allocCtx, pool.allocCancel = allocCtx, allocCancel
+
+	// Pre-create the minimum; the rest open on demand
+	for i := 0; i < opts.MinSize; i++ {
+		bc, err := pool.create()
+		if err != nil {
+			pool.Close()
+			return nil, fmt.Errorf("failed to warm up browser context %d: %w", i, err)
+		}
+		pool.size++
+		bc.idleSince = time.Now()
+		pool.contexts <- bc
+
+		log.Debug().Int("context_id", i).Msg("Browser context initialized")
+	}
+	pool.peak = pool.size
+	go pool.reapIdle()
+
+	log.Info().Int("pool_size", opts.MinSize).Int("max", opts.Size).Msg("Browser pool ready")
+
+	return pool, nil
+}
 
+// newPool returns an empty pool that opens contexts with create
+func newPool(min, max int, idleTimeout time.Duration, create func() (*BrowserContext, error)) *BrowserPool {
+	return &BrowserPool{
+		min:         min,
+		max:         max,
+		idleTimeout: idleTimeout,
+		contexts:    make(chan *BrowserContext, max),
+		allocCancel: func() {},
+		create:      create,
+		done:        make(chan struct{}),
+	}
+}
+
+// newBrowserContext opens a tab and warms it up by loading a blank page
+func newBrowserContext(allocCtx context.Context) (*BrowserContext, error) {
+	browserCtx, browserCancel := chromedp.NewContext(allocCtx)
+	if err := chromedp.Run(browserCtx, chromedp.Navigate("about:blank")); err != nil {
+		browserCancel()
+		return nil, err
+	}
+	return &BrowserContext{Ctx: browserCtx, Cancel: browserCancel}, nil
+}
+
+// platformFlags returns the Chrome flags that depend on the OS. Running the
+// network service inside the browser process, like --single-process, makes
+// Chrome crash on start on Windows, and /dev/shm only exists on Linux.
+func platformFlags(goos string) map[string]interface{} {
+	flags := map[string]interface{}{}
+	switch goos {
+	case "windows":
+		flags["enable-features"] = "NetworkService"
+	case "linux":
+		flags["enable-features"] = "NetworkService,NetworkServiceInProcess"
+		flags["disable-dev-shm-usage"] = true
+	default:
+		flags["enable-features"] = "NetworkService,NetworkServiceInProcess"
+	}
+	return flags
+}
+
+// newExecAllocator launches a local Chrome with flags tuned for scraping
+func newExecAllocator(opts BrowserPoolOptions) (context.Context, context.CancelFunc) {
 	// Auto-detect Chrome path
 	chromePath := FindChrome()
 
@@ -61,7 +186,6 @@ func NewBrowserPool(opts BrowserPoolOptions) (*BrowserPool, error) {
 		chromedp.NoDefaultBrowserCheck,
 		chromedp.Flag("disable-gpu", true),
 		chromedp.Flag("no-sandbox", true),
-		chromedp.Flag("disable-dev-shm-usage", true),
 		chromedp.Flag("disable-extensions", true),
 		chromedp.Flag("disable-background-networking", true),
 		chromedp.Flag("disable-breakpad", true),
@@ -81,7 +205,6 @@ func NewBrowserPool(opts BrowserPoolOptions) (*BrowserPool, error) {
 		chromedp.UserAgent(opts.UserAgent),
 		// Point 10: Additional optimization flags
 		chromedp.Flag("disable-features", "site-per-process,TranslateUI,BlinkGenPropertyTrees"),
-		chromedp.Flag("enable-features", "NetworkService,NetworkServiceInProcess"),
 		chromedp.Flag("disable-blink-features", "AutomationControlled"),
 		chromedp.Flag("disable-infobars", true),
 		chromedp.Flag("window-size", "1920,1080"),
@@ -89,6 +212,10 @@ func NewBrowserPool(opts BrowserPoolOptions) (*BrowserPool, error) {
 		chromedp.Flag("media-cache-size", "0"),
 	}
 
+	for name, value := range platformFlags(runtime.GOOS) {
+		allocOpts = append(allocOpts, chromedp.Flag(name, value))
+	}
+
 	// Set Chrome path if found
 	if chromePath != "" {
 		allocOpts = append([]chromedp.ExecAllocatorOption{chromedp.ExecPath(chromePath)}, allocOpts...)
@@ -100,6 +227,9 @@ func NewBrowserPool(opts BrowserPoolOptions) (*BrowserPool, error) {
 	} else {
 		allocOpts = append(allocOpts, chromedp.Flag("headless", false))
 	}
+	if opts.DevTools {
+		allocOpts = append(allocOpts, chromedp.Flag("auto-open-devtools-for-tabs", true))
+	}
 
 	// Add proxy if specified
 	if opts.Proxy != "" {
@@ -109,73 +239,132 @@ func NewBrowserPool(opts BrowserPoolOptions) (*BrowserPool, error) {
 	// Add extra args
 	allocOpts = append(allocOpts, opts.ExtraArgs...)
 
-	// Create parent allocator context
-	allocCtx, allocCancel := chromedp.NewExecAllocator(context.Background(), allocOpts...)
+	// Create parent allocator context, with a profile tagged for cleanup
+	return supervisedAllocator(context.Background(), allocOpts...)
+}
 
-	pool := &BrowserPool{
-		size:        opts.Size,
-		contexts:    make(chan *BrowserContext, opts.Size),
-		allocCtx:    allocCtx,
-		allocCancel: allocCancel,
-		closed:      false,
+// Acquire gets a browser context from the pool. With none idle it opens a
+// new one if the pool is below its maximum, and otherwise blocks.
+func (bp *BrowserPool) Acquire(timeout time.Duration) (*BrowserContext, error) {
+	select {
+	case ctx := <-bp.contexts:
+		return bp.checkout(ctx)
+	default:
+	}
+	if ctx, ok, err := bp.grow(); ok {
+		return ctx, err
 	}
 
-	// Pre-create browser contexts
-	for i := 0; i < opts.Size; i++ {
-		browserCtx, browserCancel := chromedp.NewContext(allocCtx)
+	bp.mu.Lock()
+	bp.waiting++
+	bp.mu.Unlock()
+	defer func() {
+		bp.mu.Lock()
+		bp.waiting--
+		bp.mu.Unlock()
+	}()
 
-		// Warm up the context by loading a blank page
-		if err := chromedp.Run(browserCtx, chromedp.Navigate("about:blank")); err != nil {
-			browserCancel()
-			pool.Close()
-			return nil, fmt.Errorf("failed to warm up browser context %d: %w", i, err)
+	if timeout > 0 {
+		select {
+		case ctx := <-bp.contexts:
+			return bp.checkout(ctx)
+		case <-time.After(timeout):
+			return nil, fmt.Errorf("timeout waiting for available browser context")
 		}
+	}
+
+	// No timeout, block until available
+	return bp.checkout(<-bp.contexts)
+}
 
-		pool.contexts <- &BrowserContext{
-			Ctx:    browserCtx,
-			Cancel: browserCancel,
+// checkout hands out an idle context unless the pool was closed meanwhile.
+// ctx is nil when it was taken from the closed channel.
+func (bp *BrowserPool) checkout(ctx *BrowserContext) (*BrowserContext, error) {
+	bp.mu.Lock()
+	defer bp.mu.Unlock()
+	if bp.closed || ctx == nil {
+		if ctx != nil {
+			ctx.Cancel()
 		}
+		return nil, fmt.Errorf("browser pool is closed")
+	}
+	log.Debug().Msg("Browser context acquired from pool")
+	return ctx, nil
+}
 
-		log.Debug().Int("context_id", i).Msg("Browser context initialized")
+// grow opens a context when the pool is below its maximum. ok is false when
+// it is not, and the caller has to wait for a context to be released.
+func (bp *BrowserPool) grow() (ctx *BrowserContext, ok bool, err error) {
+	bp.mu.Lock()
+	if bp.closed || bp.size >= bp.max {
+		bp.mu.Unlock()
+		return nil, false, nil
 	}
+	// Claim the slot before the slow start-up so concurrent callers can't
+	// overshoot the maximum
+	bp.size++
+	bp.mu.Unlock()
 
-	log.Info().Int("pool_size", opts.Size).Msg("Browser pool ready")
+	ctx, err = bp.create()
 
-	return pool, nil
+	bp.mu.Lock()
+	defer bp.mu.Unlock()
+	if err != nil {
+		bp.size--
+		return nil, true, fmt.Errorf("failed to open browser context: %w", err)
+	}
+	if bp.closed {
+		bp.size--
+		ctx.Cancel()
+		return nil, true, fmt.Errorf("browser pool is closed")
+	}
+	bp.scaleUps++
+	if bp.size > bp.peak {
+		bp.peak = bp.size
+	}
+	log.Debug().Int("pool_size", bp.size).Int("max", bp.max).Msg("Browser pool scaled up")
+	return ctx, true, nil
 }
 
-// Acquire gets a browser context from the pool (blocks if none available)
-func (bp *BrowserPool) Acquire(timeout time.Duration) (*BrowserContext, error) {
-	if timeout > 0 {
+// reapIdle closes contexts idle for longer than the idle timeout, down to
+// the minimum, until the pool is closed
+func (bp *BrowserPool) reapIdle() {
+	ticker := time.NewTicker(bp.idleTimeout / 2)
+	defer ticker.Stop()
+	for {
 		select {
-		case ctx := <-bp.contexts:
-			// Check if pool was closed after we got the context
-			bp.mu.Lock()
-			defer bp.mu.Unlock()
-			if bp.closed {
-				// Pool closed, cancel context and return error
-				ctx.Cancel()
-				return nil, fmt.Errorf("browser pool is closed")
-			}
-			log.Debug().Msg("Browser context acquired from pool")
-			return ctx, nil
-		case <-time.After(timeout):
-			return nil, fmt.Errorf("timeout waiting for available browser context")
+		case <-bp.done:
+			return
+		case now := <-ticker.C:
+			bp.reap(now)
 		}
 	}
+}
 
-	// No timeout, block until available
-	ctx := <-bp.contexts
-	// Check if pool was closed after we got the context
+// reap closes the contexts that have been idle since before now minus the
+// idle timeout, keeping at least the minimum open
+func (bp *BrowserPool) reap(now time.Time) {
 	bp.mu.Lock()
 	defer bp.mu.Unlock()
 	if bp.closed {
-		// Pool closed, cancel context and return error
+		return
+	}
+	for n := len(bp.contexts); n > 0 && bp.size > bp.min; n-- {
+		var ctx *BrowserContext
+		select {
+		case ctx = <-bp.contexts:
+		default:
+			return
+		}
+		if now.Sub(ctx.idleSince) < bp.idleTimeout {
+			bp.contexts <- ctx // room is guaranteed: it just came out
+			continue
+		}
 		ctx.Cancel()
-		return nil, fmt.Errorf("browser pool is closed")
+		bp.size--
+		bp.scaleDowns++
+		log.Debug().Int("pool_size", bp.size).Int("min", bp.min).Msg("Browser pool scaled down")
 	}
-	log.Debug().Msg("Browser context acquired from pool")
-	return ctx, nil
 }
 
 // Release returns a browser context to the pool
@@ -200,6 +389,7 @@ func (bp *BrowserPool) Release(ctx *BrowserContext) {
 	)
 
 	// Return to pool
+	ctx.idleSince = time.Now()
 	select {
 	case bp.contexts <- ctx:
 		log.Debug().Msg("Browser context released to pool")
@@ -210,6 +400,14 @@ func (bp *BrowserPool) Release(ctx *BrowserContext) {
 	}
 }
 
+// Isolate opens a tab in a fresh incognito browser context (CDP
+// Target.createBrowserContext) within the pooled browser. Cookies,
+// localStorage and cache are private to the tab, and the context is
+// disposed when the returned cancel function is called.
+func (bc *BrowserContext) Isolate() (context.Context, context.CancelFunc) {
+	return chromedp.NewContext(bc.Ctx, chromedp.WithNewBrowserContext())
+}
+
 // Close shuts down all browser contexts and the allocator
 func (bp *BrowserPool) Close() error {
 	bp.mu.Lock()
@@ -219,8 +417,9 @@ func (bp *BrowserPool) Close() error {
 		return nil
 	}
 	bp.closed = true
+	close(bp.done)
 
-	log.Debug().Msg("Closing browser pool")
+	log.Debug().Int("peak", bp.peak).Int("scale_ups", bp.scaleUps).Int("scale_downs", bp.scaleDowns).Msg("Closing browser pool")
 
 	// Close the channel
 	close(bp.contexts)
@@ -238,11 +437,56 @@ func (bp *BrowserPool) Close() error {
 	return nil
 }
 
+// Shrink closes idle contexts until the pool holds min of them, to free
+// browser memory. Contexts in use are left alone. It returns how many were
+// closed.
+func (bp *BrowserPool) Shrink(min int) int {
+	if min < 1 {
+		min = 1
+	}
+	bp.mu.Lock()
+	defer bp.mu.Unlock()
+	if bp.closed {
+		return 0
+	}
+	closed := 0
+	for bp.size > min {
+		select {
+		case ctx := <-bp.contexts:
+			ctx.Cancel()
+			bp.size--
+			bp.scaleDowns++
+			closed++
+		default:
+			return closed
+		}
+	}
+	return closed
+}
+
 // Size returns the pool size
 func (bp *BrowserPool) Size() int {
+	bp.mu.Lock()
+	defer bp.mu.Unlock()
 	return bp.size
 }
 
+// Stats returns the pool's current size and scaling counters
+func (bp *BrowserPool) Stats() PoolStats {
+	bp.mu.Lock()
+	defer bp.mu.Unlock()
+	return PoolStats{
+		Size:       bp.size,
+		Min:        bp.min,
+		Max:        bp.max,
+		Idle:       len(bp.contexts),
+		Waiting:    bp.waiting,
+		Peak:       bp.peak,
+		ScaleUps:   bp.scaleUps,
+		ScaleDowns: bp.scaleDowns,
+	}
+}
+
 // Available returns the number of available contexts in the pool
 func (bp *BrowserPool) Available() int {
 	return len(bp.contexts)
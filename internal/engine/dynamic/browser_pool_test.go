@@ -0,0 +1,112 @@
+package dynamic
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPlatformFlags(t *testing.T) {
+	windows := platformFlags("windows")
+	if features, _ := windows["enable-features"].(string); strings.Contains(features, "InProcess") {
+		t.Errorf("Expected no in-process network service on Windows, got %q", features)
+	}
+	for name := range windows {
+		if name == "single-process" || name == "disable-dev-shm-usage" {
+			t.Errorf("Unexpected flag %q on Windows", name)
+		}
+	}
+
+	linux := platformFlags("linux")
+	if linux["disable-dev-shm-usage"] != true {
+		t.Error("Expected --disable-dev-shm-usage on Linux")
+	}
+	if features, _ := linux["enable-features"].(string); !strings.Contains(features, "NetworkServiceInProcess") {
+		t.Errorf("Expected the in-process network service on Linux, got %q", features)
+	}
+}
+
+func TestBrowserPoolShrink(t *testing.T) {
+	pool := &BrowserPool{size: 4, contexts: make(chan *BrowserContext, 4)}
+	cancelled := 0
+	for i := 0; i < 3; i++ {
+		pool.contexts <- &BrowserContext{Ctx: context.Background(), Cancel: func() { cancelled++ }}
+	}
+
+	// One context is in use and counts towards the minimum
+	if n := pool.Shrink(2); n != 2 || cancelled != 2 {
+		t.Errorf("Expected 2 contexts closed, got %d (cancelled %d)", n, cancelled)
+	}
+	if pool.Size() != 2 || pool.Available() != 1 {
+		t.Errorf("Expected size 2 with 1 idle, got %d and %d", pool.Size(), pool.Available())
+	}
+	if n := pool.Shrink(2); n != 0 {
+		t.Errorf("Expected no contexts closed at the minimum, got %d", n)
+	}
+	// Below that, only idle contexts can go
+	if n := pool.Shrink(0); n != 1 || pool.Size() != 1 {
+		t.Errorf("Expected the last idle context closed, got %d with size %d", n, pool.Size())
+	}
+}
+
+// fakePool returns a pool whose contexts are plain background contexts
+func fakePool(min, max int) (*BrowserPool, *int) {
+	cancelled := 0
+	pool := newPool(min, max, time.Minute, func() (*BrowserContext, error) {
+		return &BrowserContext{Ctx: context.Background(), Cancel: func() { cancelled++ }}, nil
+	})
+	return pool, &cancelled
+}
+
+func TestBrowserPoolScalesUp(t *testing.T) {
+	pool, _ := fakePool(1, 3)
+	defer pool.Close()
+
+	var held []*BrowserContext
+	for i := 0; i < 3; i++ {
+		ctx, err := pool.Acquire(time.Second)
+		if err != nil {
+			t.Fatalf("Acquire %d: %v", i, err)
+		}
+		held = append(held, ctx)
+	}
+	if s := pool.Stats(); s.Size != 3 || s.ScaleUps != 3 || s.Peak != 3 {
+		t.Errorf("Expected the pool to open 3 contexts, got %+v", s)
+	}
+
+	// At the maximum, callers wait for a release
+	if _, err := pool.Acquire(20 * time.Millisecond); err == nil {
+		t.Error("Expected a timeout at the maximum size")
+	}
+	go pool.Release(held[0])
+	if _, err := pool.Acquire(time.Second); err != nil {
+		t.Errorf("Expected the released context, got %v", err)
+	}
+	if s := pool.Stats(); s.Size != 3 {
+		t.Errorf("Expected no growth past the maximum, got %+v", s)
+	}
+}
+
+func TestBrowserPoolReapsIdle(t *testing.T) {
+	pool, cancelled := fakePool(1, 3)
+	defer pool.Close()
+
+	now := time.Now()
+	for i := 0; i < 3; i++ {
+		pool.size++
+		pool.contexts <- &BrowserContext{Ctx: context.Background(), Cancel: func() { *cancelled++ }, idleSince: now.Add(-time.Duration(i) * 30 * time.Second)}
+	}
+
+	// Only the context idle for a full minute goes
+	pool.reap(now)
+	if s := pool.Stats(); s.Size != 2 || s.ScaleDowns != 1 || *cancelled != 1 {
+		t.Errorf("Expected one context reaped, got %+v (cancelled %d)", s, *cancelled)
+	}
+
+	// Never below the minimum
+	pool.reap(now.Add(time.Hour))
+	if s := pool.Stats(); s.Size != 1 || s.Idle != 1 {
+		t.Errorf("Expected the pool back at its minimum, got %+v", s)
+	}
+}
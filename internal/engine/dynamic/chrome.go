@@ -2,11 +2,16 @@
 package dynamic
 
 import (
+	"bytes"
+	"context"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
 	"runtime"
+	"time"
 
+	"github.com/law-makers/crawl/internal/browser"
 	"github.com/rs/zerolog/log"
 )
 
@@ -100,10 +105,16 @@ func FindChrome() string {
 		return path
 	}
 
-	// 5. Give up - let chromedp try its default
+	// 5. Use a build installed with `crawl browser install`
+	if path := browser.Find(); path != "" {
+		log.Debug().Str("path", path).Msg("Chrome found in managed browsers")
+		return path
+	}
+
+	// 6. Give up - let chromedp try its default
 	log.Warn().
 		Str("os", runtime.GOOS).
-		Msg("Chrome not found, will use chromedp default (may fail)")
+		Msg("Chrome not found, will use chromedp default (may fail). Run 'crawl browser install' to download one")
 	return ""
 }
 
@@ -145,28 +156,41 @@ func findInPath() string {
 	return ""
 }
 
-// GetChromeVersion returns the version of Chrome if detectable
+// GetChromeVersion returns the version of Chrome if detectable, e.g.
+// "Google Chrome 131.0.6778.85"
 func GetChromeVersion(chromePath string) string {
 	if chromePath == "" {
 		return "unknown"
 	}
 
-	var args []string
-	switch runtime.GOOS {
-	case "windows":
-		// Windows Chrome doesn't support --version directly
+	if runtime.GOOS == "windows" {
+		// chrome.exe --version opens a window instead of printing; installs
+		// keep their files in a sibling directory named after the version
+		return windowsChromeVersion(chromePath)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	output, err := exec.CommandContext(ctx, chromePath, "--version").Output()
+	if err != nil || len(bytes.TrimSpace(output)) == 0 {
 		return "detected"
-	case "darwin":
-		args = []string{"--version"}
-	default:
-		args = []string{"--version"}
 	}
+	return string(bytes.TrimSpace(output))
+}
+
+// chromeVersionDir matches the versioned directory next to chrome.exe
+var chromeVersionDir = regexp.MustCompile(`^\d+\.\d+\.\d+\.\d+$`)
 
-	cmd := exec.Command(chromePath, args...)
-	output, err := cmd.Output()
+// windowsChromeVersion reads the version from the directory next to chrome.exe
+func windowsChromeVersion(chromePath string) string {
+	entries, err := os.ReadDir(filepath.Dir(chromePath))
 	if err != nil {
 		return "detected"
 	}
-
-	return string(output)
+	for _, e := range entries {
+		if e.IsDir() && chromeVersionDir.MatchString(e.Name()) {
+			return e.Name()
+		}
+	}
+	return "detected"
 }
@@ -0,0 +1,30 @@
+package dynamic
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWindowsChromeVersion(t *testing.T) {
+	dir := t.TempDir()
+	exe := filepath.Join(dir, "chrome.exe")
+	if err := os.WriteFile(exe, nil, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if got := windowsChromeVersion(exe); got != "detected" {
+		t.Errorf("Expected \"detected\" without a version directory, got %q", got)
+	}
+
+	os.Mkdir(filepath.Join(dir, "SetupMetrics"), 0755)
+	os.Mkdir(filepath.Join(dir, "131.0.6778.85"), 0755)
+	if got := windowsChromeVersion(exe); got != "131.0.6778.85" {
+		t.Errorf("Expected the version directory, got %q", got)
+	}
+}
+
+func TestGetChromeVersion_NoPath(t *testing.T) {
+	if got := GetChromeVersion(""); got != "unknown" {
+		t.Errorf("Expected \"unknown\", got %q", got)
+	}
+}
@@ -0,0 +1,517 @@
+// internal/engine/dynamic/chromedp_driver.go
+package dynamic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/chromedp"
+	"github.com/law-makers/crawl/internal/cookies"
+	"github.com/law-makers/crawl/internal/logging"
+	"github.com/law-makers/crawl/internal/trace"
+	"github.com/law-makers/crawl/pkg/models"
+)
+
+// DriverChromedp is the name of the built-in Chrome DevTools Protocol driver
+const DriverChromedp = "chromedp"
+
+func init() {
+	RegisterDriver(DriverChromedp, func(opts DriverOptions) (Driver, error) {
+		return &chromedpDriver{userAgent: opts.UserAgent, remoteURL: opts.RemoteURL, blockURL: opts.BlockURL}, nil
+	})
+}
+
+// chromedpDriver loads pages in Chrome over the DevTools Protocol, using a
+// pooled browser when one is available
+type chromedpDriver struct {
+	mu        sync.Mutex
+	pool      *BrowserPool
+	remoteURL string
+	userAgent string
+	debug     DebugOptions
+	blockURL  func(url string) error // Refuses browser requests, see DriverOptions
+}
+
+// DebugOptions make it possible to watch the browser while working out
+// why a page or selector misbehaves
+type DebugOptions struct {
+	Headful  bool          // Show the browser window when launching Chrome per request
+	DevTools bool          // Open DevTools in each tab (implies Headful)
+	SlowMo   time.Duration // Pause before each navigation, wait and extraction action
+
+	// Pause is called while the page is still open when the load fails or
+	// the selector never appears, so the user can inspect it. The browser
+	// closes once Pause returns.
+	Pause func(reason error)
+}
+
+// slow interleaves the SlowMo delay before each action
+func (c *chromedpDriver) slow(actions ...chromedp.Action) []chromedp.Action {
+	if c.debug.SlowMo <= 0 {
+		return actions
+	}
+	slowed := make([]chromedp.Action, 0, len(actions)*2)
+	for _, a := range actions {
+		slowed = append(slowed, chromedp.Sleep(c.debug.SlowMo), a)
+	}
+	return slowed
+}
+
+// pause hands the open page to the debug Pause hook, if any
+func (c *chromedpDriver) pause(reason error) {
+	if c.debug.Pause != nil {
+		c.debug.Pause(reason)
+	}
+}
+
+// Name returns the driver name
+func (c *chromedpDriver) Name() string {
+	return DriverChromedp
+}
+
+func (c *chromedpDriver) setPool(bp *BrowserPool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.pool = bp
+}
+
+func (c *chromedpDriver) getPool() *BrowserPool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.pool
+}
+
+// Load renders the page and extracts its data. Each phase (connect,
+// navigation, wait, extraction) runs under its own budget.
+func (c *chromedpDriver) Load(opts models.RequestOptions) (*models.PageData, error) {
+	start := time.Now()
+	logger := logging.ForRequest(opts, "dynamic")
+	timeout := opts.Timeout
+	if timeout == 0 {
+		timeout = 30 * time.Second
+	}
+	budgets := budgetsFor(opts)
+
+	var ctx context.Context
+	var abort context.CancelFunc // ends the request, used to enforce the connect budget
+	var tabWait time.Duration
+
+	// 1. Try to use browser pool (faster and more stable)
+	if pool := c.getPool(); pool != nil {
+		bCtx, err := pool.Acquire(timeout)
+		if err != nil {
+			return nil, fmt.Errorf("failed to acquire browser from pool: %w", err)
+		}
+		// Release back to pool when function exits
+		defer pool.Release(bCtx)
+		tabWait = time.Since(start)
+
+		// Run the request in its own incognito context so no cookies or
+		// storage carry over from earlier requests on this browser
+		ctx, abort = bCtx.Isolate()
+		defer abort()
+
+		logger.Debug().Dur("elapsed_ms", time.Since(start)).Msg("Acquired browser from pool")
+	} else if c.remoteURL != "" {
+		// 2. Fallback: attach to the remote browser and open a tab there
+		ctx, abort = chromedp.NewRemoteAllocator(context.Background(), c.remoteURL)
+		defer abort()
+
+		var cancel context.CancelFunc
+		ctx, cancel = chromedp.NewContext(ctx)
+		defer cancel()
+
+		logger.Debug().Str("cdp", c.remoteURL).Msg("Attached to remote browser (fallback)")
+	} else {
+		// 3. Fallback: Create new allocator and context (slower)
+		// We mirror the robust flags from browser_pool.go here to ensure stability on Windows
+
+		// Create base context; cancelling it shuts the browser down
+		ctx, abort = context.WithCancel(context.Background())
+		defer abort()
+
+		chromePath := FindChrome()
+		allocOpts := []chromedp.ExecAllocatorOption{
+			chromedp.NoFirstRun,
+			chromedp.NoDefaultBrowserCheck,
+			chromedp.Flag("disable-gpu", true),
+			chromedp.Flag("no-sandbox", true),
+			chromedp.Flag("disable-dev-shm-usage", true),
+			chromedp.Flag("disable-extensions", true),
+			chromedp.Flag("disable-background-networking", true),
+			chromedp.Flag("disable-breakpad", true),
+			chromedp.Flag("disable-client-side-phishing-detection", true),
+			chromedp.Flag("disable-default-apps", true),
+			chromedp.Flag("disable-hang-monitor", true),
+			chromedp.Flag("disable-ipc-flooding-protection", true),
+			chromedp.Flag("disable-prompt-on-repost", true),
+			chromedp.Flag("disable-renderer-backgrounding", true),
+			chromedp.Flag("disable-sync", true),
+			chromedp.Flag("disable-translate", true),
+			chromedp.Flag("force-color-profile", "srgb"),
+			chromedp.Flag("metrics-recording-only", true),
+			chromedp.Flag("mute-audio", true),
+			chromedp.Flag("safebrowsing-disable-auto-update", true),
+			// Robustness flags (critical for Windows stability)
+			chromedp.Flag("disable-features", "site-per-process,TranslateUI,BlinkGenPropertyTrees"),
+			chromedp.Flag("enable-features", "NetworkService,NetworkServiceInProcess"),
+			chromedp.Flag("disable-blink-features", "AutomationControlled"),
+			chromedp.Flag("disable-infobars", true),
+			chromedp.Flag("window-size", "1920,1080"),
+			chromedp.Flag("disk-cache-size", "0"),
+			chromedp.Flag("media-cache-size", "0"),
+			chromedp.UserAgent(c.userAgent),
+		}
+
+		// Set chrome path if found
+		if chromePath != "" {
+			allocOpts = append([]chromedp.ExecAllocatorOption{chromedp.ExecPath(chromePath)}, allocOpts...)
+		}
+
+		if c.debug.Headful || c.debug.DevTools {
+			allocOpts = append(allocOpts, chromedp.Flag("headless", false))
+		} else {
+			allocOpts = append(allocOpts, chromedp.Flag("headless", "new"))
+		}
+		if c.debug.DevTools {
+			allocOpts = append(allocOpts, chromedp.Flag("auto-open-devtools-for-tabs", true))
+		}
+
+		// Add proxy if specified
+		if opts.Proxy != "" {
+			allocOpts = append(allocOpts, chromedp.ProxyServer(opts.Proxy))
+		}
+
+		// Create allocator context
+		var allocCancel context.CancelFunc
+		ctx, allocCancel = supervisedAllocator(ctx, allocOpts...)
+		// We defer allocCancel in a way that it runs when the function returns
+		defer allocCancel()
+
+		// Create browser context
+		var cancel context.CancelFunc
+		ctx, cancel = chromedp.NewContext(ctx)
+		defer cancel()
+
+		logger.Debug().Dur("elapsed_ms", time.Since(start)).Msg("Created new browser context (fallback)")
+	}
+
+	// Build PageData
+	pageData := &models.PageData{
+		URL:       opts.URL,
+		FetchedAt: time.Now(),
+		Headers:   make(map[string]string),
+		Metadata:  make(map[string]string),
+		Links:     []string{},
+		Images:    []string{},
+		Scripts:   []string{},
+	}
+
+	// Variables to capture
+	var htmlContent string
+	var title string
+	var statusCode int64
+
+	navigateStart := time.Now()
+	logger.Debug().Msg("Starting chromedp.Run")
+
+	// Listen for network events to capture status code and headers
+	var mainFrame cdp.FrameID
+	var (
+		timingMu   sync.Mutex
+		timings    = &models.Timings{}
+		docRequest network.RequestID
+		received   *cdp.MonotonicTime
+		downloaded time.Time
+	)
+	// Cookies set by any response, subresources included, when asked for
+	var (
+		cookieMu    sync.Mutex
+		requestURLs = map[network.RequestID]string{}
+		setCookies  []models.Cookie
+	)
+	// WebSocket frames, when asked for
+	var ws *wsRecorder
+	if opts.CaptureWS != nil {
+		ws = newWSRecorder(*opts.CaptureWS)
+	}
+	console := &consoleRecorder{logger: logger}
+	var filter *requestFilter
+	if c.blockURL != nil {
+		filter = &requestFilter{check: c.blockURL, logger: logger}
+	}
+	chromedp.ListenTarget(ctx, func(ev interface{}) {
+		filter.handle(ctx, ev)
+		console.handle(ev)
+		if ws != nil {
+			ws.handle(ev)
+		}
+		switch ev := ev.(type) {
+		case *network.EventRequestWillBeSent:
+			if opts.CaptureCookies {
+				cookieMu.Lock()
+				requestURLs[ev.RequestID] = ev.Request.URL
+				cookieMu.Unlock()
+			}
+		case *network.EventResponseReceivedExtraInfo:
+			// Only the extra info carries Set-Cookie, one cookie per line
+			if !opts.CaptureCookies {
+				break
+			}
+			cookieMu.Lock()
+			setBy := requestURLs[ev.RequestID]
+			if setBy == "" {
+				setBy = opts.URL
+			}
+			for key, value := range ev.Headers {
+				if lines, ok := value.(string); ok && strings.EqualFold(key, "Set-Cookie") {
+					for _, line := range strings.Split(lines, "\n") {
+						if c, ok := cookies.Parse(line, setBy, opts.URL); ok {
+							setCookies = append(setCookies, c)
+						}
+					}
+				}
+			}
+			cookieMu.Unlock()
+		case *page.EventFrameNavigated:
+			if ev.Frame.ParentID == "" {
+				mainFrame = ev.Frame.ID
+				opts.Trace.Event("navigated", time.Now(), ev.Frame.URL)
+			}
+		case *page.EventLifecycleEvent:
+			if ev.FrameID == mainFrame {
+				opts.Trace.Event(ev.Name, time.Now(), "")
+			}
+		case *network.EventLoadingFinished:
+			timingMu.Lock()
+			if ev.RequestID == docRequest && received != nil && ev.Timestamp != nil {
+				timings.Download = trace.Millis(ev.Timestamp.Time().Sub(received.Time()))
+				downloaded = time.Now()
+			}
+			timingMu.Unlock()
+		case *network.EventResponseReceived:
+			resp := ev.Response
+			if resp.URL == opts.URL {
+				opts.Trace.Event("response", time.Now(), fmt.Sprintf("%d %s", resp.Status, resp.Protocol))
+				timingMu.Lock()
+				timings = resourceTimings(resp.Timing)
+				docRequest, received = ev.RequestID, ev.Timestamp
+				timingMu.Unlock()
+				statusCode = resp.Status
+				// Capture headers
+				for key, value := range resp.Headers {
+					if strValue, ok := value.(string); ok {
+						pageData.Headers[key] = strValue
+					}
+				}
+			}
+		}
+	})
+
+	// Prepare selector to wait for (if specified)
+	selector := opts.Selector
+	if selector == "" || selector == "body" {
+		selector = "body"
+	}
+
+	// Connect: start the browser (fallback) or open the tab (pooled)
+	connectActions := []chromedp.Action{network.Enable()}
+	if filter != nil {
+		connectActions = append(connectActions, filter.enable())
+	}
+	if opts.Trace != nil {
+		connectActions = append(connectActions, page.SetLifecycleEventsEnabled(true))
+	}
+	if opts.Stealth {
+		connectActions = append(connectActions, stealthActions()...)
+	}
+	phaseStart := time.Now()
+	if err := connect(ctx, abort, budgets.Connect, connectActions...); err != nil {
+		return nil, fmt.Errorf("chromedp execution failed: %w", err)
+	}
+	opts.Trace.Span(PhaseConnect, phaseStart, time.Now(), "")
+
+	// From here on a browser tab is open, so failures can be inspected
+	fail := func(err error) (*models.PageData, error) {
+		// A refused navigation only shows up as a network error
+		if denied := filter.deniedPage(); denied != nil {
+			err = denied
+		}
+		err = fmt.Errorf("chromedp execution failed: %w", err)
+		// Script errors are often why a page never rendered
+		msgs, _ := console.result()
+		for _, m := range msgs {
+			if m.Exception {
+				logger.Warn().Str("script", m.URL).Int("line", m.Line).Str("error", m.Text).Msg("Uncaught JavaScript error")
+			}
+		}
+		c.pause(err)
+		return nil, err
+	}
+
+	// Navigate and wait for the page to load
+	phaseStart = time.Now()
+	if err := runPhase(ctx, PhaseNavigation, budgets.Navigation, c.slow(chromedp.Navigate(opts.URL))...); err != nil {
+		return fail(err)
+	}
+	if denied := filter.deniedPage(); denied != nil {
+		return fail(denied)
+	}
+	opts.Trace.Span(PhaseNavigation, phaseStart, time.Now(), opts.URL)
+
+	// Wait a short initial period for JS to run, any user-specified wait
+	// (opts.WaitSeconds), and for the selector to appear. With a form to
+	// submit the selector is for the page after it, so only wait for the body.
+	selectorFound := true
+	waitFor := selector
+	if opts.Submit != nil {
+		waitFor = "body"
+	}
+	phaseStart = time.Now()
+	err := runPhase(ctx, PhaseWait, budgets.Wait, c.slow(
+		chromedp.ActionFunc(func(ctx context.Context) error {
+			if opts.WaitSeconds > 0 {
+				logger.Debug().Int("wait_seconds", opts.WaitSeconds).Msg("Waiting after navigation before scraping (dynamic)")
+			}
+			return sleepCtx(ctx, 300*time.Millisecond+time.Duration(opts.WaitSeconds)*time.Second)
+		}),
+		chromedp.WaitReady(waitFor, chromedp.ByQuery),
+	)...)
+	var timeoutErr *PhaseTimeoutError
+	if errors.As(err, &timeoutErr) && waitFor != "body" {
+		// Like the static engine, a missing selector yields empty content
+		logger.Warn().Str("selector", opts.Selector).Dur("wait", budgets.Wait).Msg("Selector not found before wait timed out")
+		selectorFound = false
+		c.pause(fmt.Errorf("selector %q not found: %w", opts.Selector, err))
+	} else if err != nil {
+		return fail(err)
+	}
+	// Fill and submit a form, then wait for the page it leads to
+	if opts.Submit != nil {
+		err := runPhase(ctx, PhaseWait, budgets.Wait, c.slow(
+			submitForm(opts.Submit),
+			chromedp.ActionFunc(func(ctx context.Context) error {
+				return sleepCtx(ctx, time.Second)
+			}),
+			chromedp.WaitReady(selector, chromedp.ByQuery),
+		)...)
+		if err != nil {
+			return fail(err)
+		}
+	}
+	rendered := time.Now()
+	opts.Trace.Span(PhaseWait, phaseStart, rendered, selector)
+
+	// Give sockets the page opened time to deliver data
+	if ws != nil && opts.CaptureWS.Listen > 0 {
+		if err := sleepCtx(ctx, opts.CaptureWS.Listen); err != nil {
+			return fail(err)
+		}
+		opts.Trace.Span("websocket", rendered, time.Now(), "listening for WebSocket frames")
+	}
+
+	// Extract the rendered page
+	phaseStart = time.Now()
+	extractCtx, extractCancel := context.WithTimeout(ctx, budgets.Extract)
+	defer extractCancel()
+	extract := []chromedp.Action{
+		chromedp.Title(&title),
+		chromedp.OuterHTML("html", &htmlContent, chromedp.ByQuery),
+	}
+	if opts.Frames != nil {
+		extract = append(extract, chromedp.ActionFunc(func(ctx context.Context) error {
+			frames, err := readFrames(ctx, opts.Frames)
+			if err != nil {
+				logger.Warn().Err(err).Msg("Failed to read iframes")
+				return nil
+			}
+			pageData.Frames = frames
+			return nil
+		}))
+	}
+	err = chromedp.Run(extractCtx, c.slow(extract...)...)
+
+	logger.Debug().Dur("elapsed_ms", time.Since(navigateStart)).Msg("chromedp.Run completed")
+
+	if err != nil {
+		return fail(phaseError(ctx, extractCtx, PhaseExtract, budgets.Extract, err))
+	}
+	opts.Trace.Span(PhaseExtract, phaseStart, time.Now(), "")
+
+	responseTime := time.Since(start).Milliseconds()
+
+	// Update page data
+	pageData.Title = title
+	pageData.HTML = htmlContent
+	pageData.StatusCode = int(statusCode)
+	pageData.ResponseTime = responseTime
+	cookieMu.Lock()
+	pageData.Cookies = setCookies
+	cookieMu.Unlock()
+	var droppedLogs int
+	if pageData.ConsoleLogs, droppedLogs = console.result(); droppedLogs > 0 {
+		logger.Debug().Int("dropped", droppedLogs).Msg("Console capture limit reached")
+	}
+	if ws != nil {
+		var dropped int
+		pageData.WebSocket, dropped = ws.result()
+		if dropped > 0 {
+			logger.Warn().Int("kept", len(pageData.WebSocket)).Int("dropped", dropped).Msg("WebSocket capture limit reached")
+		}
+	}
+
+	// Render covers the time from the document arriving to the selector being ready
+	timingMu.Lock()
+	if !downloaded.IsZero() && rendered.After(downloaded) {
+		timings.Render = trace.Millis(rendered.Sub(downloaded))
+	}
+	timings.Waited = trace.Millis(tabWait)
+	pageData.Timings = timings
+	timingMu.Unlock()
+
+	// Parse HTML to extract additional data
+	err = extractDataFromHTML(extractCtx, opts, pageData, selectorFound)
+	if err != nil {
+		logger.Warn().Err(err).Msg("Failed to extract additional data")
+	}
+
+	logger.Info().
+		Int("status", pageData.StatusCode).
+		Int64("response_time_ms", responseTime).
+		Int("links", len(pageData.Links)).
+		Int("images", len(pageData.Images)).
+		Msg("Fetch completed")
+
+	return pageData, nil
+}
+
+// resourceTimings converts Chrome's timing for the main document into
+// Timings. Chrome reports offsets from the request start, with -1 for phases
+// that did not happen, and counts the TLS handshake as part of connecting.
+func resourceTimings(rt *network.ResourceTiming) *models.Timings {
+	t := &models.Timings{}
+	if rt == nil {
+		return t
+	}
+	span := func(start, end float64) float64 {
+		if start < 0 || end < start {
+			return 0
+		}
+		return end - start
+	}
+	t.DNS = span(rt.DNSStart, rt.DNSEnd)
+	t.TLS = span(rt.SslStart, rt.SslEnd)
+	t.Connect = span(rt.ConnectStart, rt.ConnectEnd) - t.TLS
+	if t.Connect < 0 {
+		t.Connect = 0
+	}
+	t.TTFB = span(rt.SendEnd, rt.ReceiveHeadersEnd)
+	return t
+}
@@ -0,0 +1,119 @@
+// internal/engine/dynamic/console.go
+package dynamic
+
+import (
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chromedp/cdproto/runtime"
+	"github.com/law-makers/crawl/pkg/models"
+	"github.com/rs/zerolog"
+)
+
+// Limits on the console output kept per page, so a page logging in a loop
+// doesn't bloat the result
+const (
+	maxConsoleMessages = 200
+	maxConsoleText     = 2000
+)
+
+// consoleRecorder collects console messages and uncaught exceptions from
+// CDP runtime events. chromedp enables the runtime domain on every tab.
+type consoleRecorder struct {
+	logger zerolog.Logger
+
+	mu       sync.Mutex
+	messages []models.ConsoleMessage
+	dropped  int
+}
+
+// handle records ev if it is a console call or an uncaught exception
+func (r *consoleRecorder) handle(ev interface{}) {
+	switch ev := ev.(type) {
+	case *runtime.EventConsoleAPICalled:
+		args := make([]string, 0, len(ev.Args))
+		for _, arg := range ev.Args {
+			args = append(args, remoteText(arg))
+		}
+		msg := models.ConsoleMessage{Level: consoleLevel(ev.Type), Text: strings.Join(args, " "), Time: time.Now()}
+		if ev.StackTrace != nil && len(ev.StackTrace.CallFrames) > 0 {
+			frame := ev.StackTrace.CallFrames[0]
+			msg.URL, msg.Line = frame.URL, int(frame.LineNumber)+1
+		}
+		r.add(msg)
+	case *runtime.EventExceptionThrown:
+		d := ev.ExceptionDetails
+		if d == nil {
+			return
+		}
+		msg := models.ConsoleMessage{Level: "error", Text: d.Text, URL: d.URL, Line: int(d.LineNumber) + 1, Time: time.Now(), Exception: true}
+		if d.Exception != nil && d.Exception.Description != "" {
+			// The description is the message followed by the stack
+			msg.Text, _, _ = strings.Cut(d.Exception.Description, "\n")
+		}
+		if msg.URL == "" && d.StackTrace != nil && len(d.StackTrace.CallFrames) > 0 {
+			msg.URL = d.StackTrace.CallFrames[0].URL
+		}
+		r.add(msg)
+	}
+}
+
+func (r *consoleRecorder) add(msg models.ConsoleMessage) {
+	if len(msg.Text) > maxConsoleText {
+		msg.Text = msg.Text[:maxConsoleText] + "..."
+	}
+	r.logger.Debug().Str("level", msg.Level).Str("script", msg.URL).Int("line", msg.Line).Bool("exception", msg.Exception).Str("text", msg.Text).Msg("Console message")
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.messages) >= maxConsoleMessages {
+		r.dropped++
+		return
+	}
+	r.messages = append(r.messages, msg)
+}
+
+// result returns the recorded messages and how many were over the limit
+func (r *consoleRecorder) result() ([]models.ConsoleMessage, int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]models.ConsoleMessage(nil), r.messages...), r.dropped
+}
+
+// consoleLevel maps a console call type to a log level
+func consoleLevel(t runtime.APIType) string {
+	switch t {
+	case runtime.APITypeError, runtime.APITypeAssert:
+		return "error"
+	case runtime.APITypeWarning:
+		return "warning"
+	case runtime.APITypeInfo:
+		return "info"
+	case runtime.APITypeDebug:
+		return "debug"
+	}
+	return "log"
+}
+
+// remoteText renders a console argument the way DevTools prints it
+func remoteText(o *runtime.RemoteObject) string {
+	if o == nil {
+		return ""
+	}
+	if len(o.Value) > 0 {
+		var s string
+		if err := json.Unmarshal(o.Value, &s); err == nil {
+			return s
+		}
+		return string(o.Value)
+	}
+	if o.UnserializableValue != "" {
+		return string(o.UnserializableValue)
+	}
+	if o.Description != "" {
+		return o.Description
+	}
+	return string(o.Type)
+}
@@ -0,0 +1,44 @@
+package dynamic
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/chromedp/cdproto/runtime"
+	"github.com/rs/zerolog"
+)
+
+func TestConsoleRecorder(t *testing.T) {
+	r := &consoleRecorder{logger: zerolog.Nop()}
+	r.handle(&runtime.EventConsoleAPICalled{
+		Type: runtime.APITypeWarning,
+		Args: []*runtime.RemoteObject{
+			{Type: "string", Value: []byte(`"loaded \"app\""`)},
+			{Type: "number", Value: []byte(`42`)},
+			{Type: "number", UnserializableValue: "NaN"},
+			{Type: "object", Description: "Object"},
+		},
+		StackTrace: &runtime.StackTrace{CallFrames: []*runtime.CallFrame{{URL: "https://example.com/app.js", LineNumber: 9}}},
+	})
+	r.handle(&runtime.EventExceptionThrown{ExceptionDetails: &runtime.ExceptionDetails{
+		Text:       "Uncaught",
+		URL:        "https://example.com/app.js",
+		LineNumber: 0,
+		Exception:  &runtime.RemoteObject{Description: "TypeError: x is undefined\n    at app.js:1:5"},
+	}})
+	r.handle(&runtime.EventConsoleAPICalled{Type: runtime.APITypeLog, Args: []*runtime.RemoteObject{{Type: "string", Value: []byte(`"` + strings.Repeat("a", maxConsoleText+10) + `"`)}}})
+
+	msgs, dropped := r.result()
+	if len(msgs) != 3 || dropped != 0 {
+		t.Fatalf("Expected 3 messages, got %d (%d dropped)", len(msgs), dropped)
+	}
+	if m := msgs[0]; m.Level != "warning" || m.Text != `loaded "app" 42 NaN Object` || m.Line != 10 || m.Exception {
+		t.Errorf("Unexpected console message: %+v", m)
+	}
+	if m := msgs[1]; m.Level != "error" || m.Text != "TypeError: x is undefined" || m.Line != 1 || !m.Exception {
+		t.Errorf("Unexpected exception: %+v", m)
+	}
+	if m := msgs[2]; len(m.Text) != maxConsoleText+3 {
+		t.Errorf("Expected a long message to be cut, got %d bytes", len(m.Text))
+	}
+}
@@ -0,0 +1,83 @@
+// internal/engine/dynamic/driver.go
+package dynamic
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/law-makers/crawl/pkg/models"
+)
+
+// Driver is a browser backend that loads and renders a page. The Scraper
+// handles rate limiting, per-domain slots and request logging around it, so
+// a driver only has to drive its browser.
+//
+// chromedp (Chrome DevTools Protocol) is the built-in driver. Other engines,
+// such as a WebDriver BiDi or Firefox backend, register themselves with
+// RegisterDriver and are selected with the browser_driver config setting.
+type Driver interface {
+	// Name returns the name the driver is registered under
+	Name() string
+
+	// Load navigates to opts.URL and returns the rendered page. Drivers
+	// should honor the per-phase budgets in opts (ConnectTimeout etc.)
+	Load(opts models.RequestOptions) (*models.PageData, error)
+}
+
+// DriverOptions are the settings passed to a driver factory
+type DriverOptions struct {
+	UserAgent string
+	Proxy     string
+	Headless  bool
+	RemoteURL string // Endpoint of an already-running browser, if any
+
+	// BlockURL, when set, is asked about every request the browser makes,
+	// redirects and subresources included; drivers must fail the ones it
+	// returns an error for, and fail the page if its document is one
+	BlockURL func(url string) error
+}
+
+// DriverFactory creates a driver
+type DriverFactory func(opts DriverOptions) (Driver, error)
+
+var (
+	driversMu sync.RWMutex
+	drivers   = map[string]DriverFactory{}
+)
+
+// RegisterDriver makes a driver available by name. It is intended to be
+// called from init functions; registering a name twice panics.
+func RegisterDriver(name string, factory DriverFactory) {
+	driversMu.Lock()
+	defer driversMu.Unlock()
+	name = strings.ToLower(name)
+	if _, exists := drivers[name]; exists {
+		panic("dynamic: driver registered twice: " + name)
+	}
+	drivers[name] = factory
+}
+
+// Drivers returns the registered driver names, sorted
+func Drivers() []string {
+	driversMu.RLock()
+	defer driversMu.RUnlock()
+	names := make([]string, 0, len(drivers))
+	for name := range drivers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewDriver creates the named driver
+func NewDriver(name string, opts DriverOptions) (Driver, error) {
+	driversMu.RLock()
+	factory, ok := drivers[strings.ToLower(name)]
+	driversMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown browser driver %q (available: %s)", name, strings.Join(Drivers(), ", "))
+	}
+	return factory(opts)
+}
@@ -0,0 +1,119 @@
+package dynamic
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/chromedp"
+	"github.com/law-makers/crawl/pkg/models"
+)
+
+// fakeDriver returns canned pages and records the requests it served
+type fakeDriver struct {
+	urls []string
+	err  error
+}
+
+func (f *fakeDriver) Name() string { return "fake" }
+
+func (f *fakeDriver) Load(opts models.RequestOptions) (*models.PageData, error) {
+	f.urls = append(f.urls, opts.URL)
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &models.PageData{URL: opts.URL, StatusCode: 200, Title: "Fake", HTML: "<html></html>"}, nil
+}
+
+func TestDrivers_ChromedpRegistered(t *testing.T) {
+	found := false
+	for _, name := range Drivers() {
+		if name == DriverChromedp {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Expected %q in registered drivers, got %v", DriverChromedp, Drivers())
+	}
+
+	d, err := NewDriver("ChromeDP", DriverOptions{})
+	if err != nil || d.Name() != DriverChromedp {
+		t.Errorf("Expected case-insensitive lookup of chromedp, got %v, %v", d, err)
+	}
+}
+
+func TestNewDriver_Unknown(t *testing.T) {
+	_, err := NewDriver("netscape", DriverOptions{})
+	if err == nil || !strings.Contains(err.Error(), "available: chromedp") {
+		t.Errorf("Expected unknown driver error listing available drivers, got %v", err)
+	}
+}
+
+func TestScraper_UsesConfiguredDriver(t *testing.T) {
+	scraper := NewTestDynamicScraper()
+	drv := &fakeDriver{}
+	scraper.SetDriver(drv)
+
+	data, err := scraper.Fetch(models.RequestOptions{URL: "https://example.com", Timeout: 5 * time.Second, NoHTML: true})
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if len(drv.urls) != 1 || drv.urls[0] != "https://example.com" {
+		t.Errorf("Expected the driver to load the page, got %v", drv.urls)
+	}
+	if data.Title != "Fake" || data.HTML != "" {
+		t.Errorf("Expected driver result with HTML dropped for NoHTML, got %+v", data)
+	}
+
+	drv.err = errors.New("boom")
+	if _, err := scraper.Fetch(models.RequestOptions{URL: "https://example.com", Timeout: 5 * time.Second}); err == nil {
+		t.Error("Expected driver error to be returned")
+	}
+}
+
+func TestChromedpDriver_SlowMoInterleavesSleeps(t *testing.T) {
+	c := &chromedpDriver{}
+	actions := []chromedp.Action{chromedp.Navigate("about:blank"), chromedp.Title(new(string))}
+	if got := c.slow(actions...); len(got) != 2 {
+		t.Errorf("Expected actions unchanged without SlowMo, got %d", len(got))
+	}
+
+	c.debug.SlowMo = 250 * time.Millisecond
+	if got := c.slow(actions...); len(got) != 4 {
+		t.Errorf("Expected a sleep before each action, got %d actions", len(got))
+	}
+}
+
+func TestChromedpDriver_PauseCallsHook(t *testing.T) {
+	c := &chromedpDriver{}
+	c.pause(errors.New("no hook")) // must not panic
+
+	var reason error
+	c.debug.Pause = func(err error) { reason = err }
+	c.pause(errors.New("selector missing"))
+	if reason == nil || reason.Error() != "selector missing" {
+		t.Errorf("Expected pause hook to receive the failure, got %v", reason)
+	}
+}
+
+func TestResourceTimings(t *testing.T) {
+	got := resourceTimings(&network.ResourceTiming{
+		DNSStart: 0, DNSEnd: 5,
+		ConnectStart: 5, ConnectEnd: 40,
+		SslStart: 20, SslEnd: 40,
+		SendStart: 40, SendEnd: 41,
+		ReceiveHeadersEnd: 141,
+	})
+	want := &models.Timings{DNS: 5, Connect: 15, TLS: 20, TTFB: 100}
+	if *got != *want {
+		t.Errorf("Expected %+v, got %+v", want, got)
+	}
+
+	// Reused connections report -1 for the phases that were skipped
+	got = resourceTimings(&network.ResourceTiming{DNSStart: -1, DNSEnd: -1, ConnectStart: -1, ConnectEnd: -1, SslStart: -1, SslEnd: -1, SendEnd: 1, ReceiveHeadersEnd: 11})
+	if got.DNS != 0 || got.Connect != 0 || got.TLS != 0 || got.TTFB != 10 {
+		t.Errorf("Expected only ttfb for a reused connection, got %+v", got)
+	}
+}
@@ -7,25 +7,31 @@ import (
 
 	"github.com/chromedp/cdproto/cdp"
 	"github.com/chromedp/chromedp"
+	"github.com/law-makers/crawl/internal/engine/metadata"
 	"github.com/law-makers/crawl/pkg/models"
 	"github.com/rs/zerolog/log"
 )
 
-// extractDataFromHTML extracts links, images, scripts, and content from the page
-func extractDataFromHTML(ctx context.Context, opts models.RequestOptions, pageData *models.PageData) error {
+// extractDataFromHTML extracts links, images, scripts, and content from the page.
+// selectorFound is false when the selector never appeared, in which case the
+// content is left empty rather than waiting for it again.
+func extractDataFromHTML(ctx context.Context, opts models.RequestOptions, pageData *models.PageData, selectorFound bool) error {
 	// Extract content based on selector
 	selector := opts.Selector
 	if selector != "" && selector != "body" {
-		var content string
-		var html string
-		err := chromedp.Run(ctx,
-			chromedp.Text(selector, &content, chromedp.ByQuery),
-			chromedp.OuterHTML(selector, &html, chromedp.ByQuery),
-		)
-		if err == nil {
-			pageData.Content = strings.TrimSpace(content)
-		} else {
-			log.Warn().Str("selector", selector).Msg("Selector not found")
+		// A selector that never appeared was already reported while waiting
+		if selectorFound {
+			var content string
+			var html string
+			err := chromedp.Run(ctx,
+				chromedp.Text(selector, &content, chromedp.ByQuery),
+				chromedp.OuterHTML(selector, &html, chromedp.ByQuery),
+			)
+			if err == nil {
+				pageData.Content = strings.TrimSpace(content)
+			} else {
+				log.Warn().Str("selector", selector).Msg("Selector not found")
+			}
 		}
 	} else {
 		// Extract body text
@@ -69,6 +75,17 @@ func extractDataFromHTML(ctx context.Context, opts models.RequestOptions, pageDa
 		}
 	}
 
+	// Find a print version
+	var printLinks []*cdp.Node
+	var alternates []string
+	err = chromedp.Run(ctx, chromedp.Nodes(metadata.PrintAlternateSelector, &printLinks, chromedp.ByQueryAll, chromedp.AtLeast(0)))
+	if err == nil {
+		for _, node := range printLinks {
+			alternates = append(alternates, node.AttributeValue("href"))
+		}
+	}
+	pageData.PrintURL = metadata.PrintURL(pageData.URL, alternates, pageData.Links)
+
 	// Extract metadata
 	var metaTags []*cdp.Node
 	err = chromedp.Run(ctx, chromedp.Nodes("meta", &metaTags, chromedp.ByQueryAll))
@@ -0,0 +1,76 @@
+// internal/engine/dynamic/filter.go
+package dynamic
+
+import (
+	"context"
+	"sync"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/fetch"
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/chromedp"
+	"github.com/rs/zerolog"
+)
+
+// requestFilter fails every request the browser makes to a URL check
+// rejects. It intercepts with the Fetch domain, so redirects, subresources
+// and in-process iframes are held to the same rule as the page itself.
+type requestFilter struct {
+	check  func(url string) error
+	logger zerolog.Logger
+
+	mu     sync.Mutex
+	denied error // Why the main frame's document was refused, if it was
+}
+
+// enable starts pausing every request for handle to decide on
+func (f *requestFilter) enable() chromedp.Action {
+	return fetch.Enable().WithPatterns([]*fetch.RequestPattern{{URLPattern: "*", RequestStage: fetch.RequestStageRequest}})
+}
+
+// handle continues or fails a paused request. It is called from the
+// target's event listener, so the reply is sent from a goroutine.
+func (f *requestFilter) handle(ctx context.Context, ev interface{}) {
+	paused, ok := ev.(*fetch.EventRequestPaused)
+	if f == nil || !ok {
+		return
+	}
+	c := chromedp.FromContext(ctx)
+	if c == nil || c.Target == nil {
+		return
+	}
+
+	err := f.check(paused.Request.URL)
+	if err != nil {
+		f.logger.Debug().Err(err).Str("blocked", paused.Request.URL).Str("resource", string(paused.ResourceType)).Msg("Browser request denied")
+		// The main frame's document is the tab's target; anything else
+		// failing (an image, an iframe) leaves the page usable
+		if paused.ResourceType == network.ResourceTypeDocument && paused.FrameID == cdp.FrameID(c.Target.TargetID) {
+			f.mu.Lock()
+			if f.denied == nil {
+				f.denied = err
+			}
+			f.mu.Unlock()
+		}
+	}
+
+	go func() {
+		exec := cdp.WithExecutor(ctx, c.Target)
+		if err != nil {
+			_ = fetch.FailRequest(paused.RequestID, network.ErrorReasonBlockedByClient).Do(exec)
+		} else {
+			_ = fetch.ContinueRequest(paused.RequestID).Do(exec)
+		}
+	}()
+}
+
+// deniedPage returns why the page's document, or a redirect it followed,
+// was refused, or nil
+func (f *requestFilter) deniedPage() error {
+	if f == nil {
+		return nil
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.denied
+}
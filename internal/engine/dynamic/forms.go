@@ -0,0 +1,62 @@
+// internal/engine/dynamic/forms.go
+package dynamic
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/chromedp/chromedp"
+	"github.com/law-makers/crawl/pkg/models"
+)
+
+// submitFormJS fills a form the way a user would (firing input and change
+// events so scripts notice) and submits it through its submit handlers.
+// It returns an error message, or "" once the form is submitted.
+const submitFormJS = `(function(sub) {
+	const form = sub.selector ? document.querySelector(sub.selector) : document.forms[sub.index];
+	if (!form) return "form not found";
+	for (const [name, value] of Object.entries(sub.values || {})) {
+		let el = form.elements.namedItem(name);
+		if (!el) {
+			el = document.createElement("input");
+			el.type = "hidden";
+			el.name = name;
+			form.appendChild(el);
+		}
+		const els = el instanceof RadioNodeList ? Array.from(el) : [el];
+		for (const e of els) {
+			if (e.type === "radio" || e.type === "checkbox") {
+				e.checked = e.value === value || (value === "on" && e.type === "checkbox");
+			} else {
+				e.value = value;
+			}
+			e.dispatchEvent(new Event("input", {bubbles: true}));
+			e.dispatchEvent(new Event("change", {bubbles: true}));
+		}
+	}
+	if (form.requestSubmit) form.requestSubmit(); else form.submit();
+	return "";
+})(%s)`
+
+// submitForm fills and submits the form described by sub
+func submitForm(sub *models.FormSubmission) chromedp.Action {
+	return chromedp.ActionFunc(func(ctx context.Context) error {
+		arg, err := json.Marshal(map[string]interface{}{
+			"selector": sub.Selector,
+			"index":    sub.Index,
+			"values":   sub.Values,
+		})
+		if err != nil {
+			return err
+		}
+		var problem string
+		if err := chromedp.Evaluate(fmt.Sprintf(submitFormJS, arg), &problem).Do(ctx); err != nil {
+			return fmt.Errorf("failed to submit form: %w", err)
+		}
+		if problem != "" {
+			return fmt.Errorf("failed to submit form: %s", problem)
+		}
+		return nil
+	})
+}
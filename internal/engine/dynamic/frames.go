@@ -0,0 +1,83 @@
+// internal/engine/dynamic/frames.go
+package dynamic
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/cdproto/runtime"
+	"github.com/law-makers/crawl/internal/engine/metadata"
+	"github.com/law-makers/crawl/pkg/models"
+)
+
+// readFrames returns the loaded page's iframes, breadth first down to
+// metadata.MaxFrameDepth, with their documents in HTML for
+// metadata.ExtractFrames. Each document is read from an isolated world
+// created in its frame, which reaches cross-origin frames too: the browser
+// runs without site isolation, so they share the page's renderer.
+func readFrames(ctx context.Context, opts *models.FrameOptions) ([]models.Frame, error) {
+	tree, err := page.GetFrameTree().Do(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get frame tree: %w", err)
+	}
+	origin := tree.Frame.SecurityOrigin
+
+	var frames []models.Frame
+	queue := []*page.FrameTree{tree}
+	for depth := 1; depth <= metadata.MaxFrameDepth && len(queue) > 0; depth++ {
+		var next []*page.FrameTree
+		for _, parent := range queue {
+			for _, child := range parent.ChildFrames {
+				if opts.Max > 0 && len(frames) >= opts.Max {
+					return frames, nil
+				}
+				f := child.Frame
+				if f.URL == "" || f.URL == "about:blank" {
+					continue
+				}
+				frame := models.Frame{
+					URL:         f.URL,
+					Name:        f.Name,
+					Parent:      parent.Frame.URL,
+					Depth:       depth,
+					CrossOrigin: f.SecurityOrigin != origin,
+				}
+				if frame.CrossOrigin && !opts.CrossOrigin {
+					frame.Error = "cross-origin frame not read"
+				} else if frame.HTML, err = frameHTML(ctx, f.ID); err != nil {
+					frame.Error = err.Error()
+				}
+				frames = append(frames, frame)
+				next = append(next, child)
+			}
+		}
+		queue = next
+	}
+	return frames, nil
+}
+
+// frameHTML reads the outer HTML of a frame's document
+func frameHTML(ctx context.Context, id cdp.FrameID) (string, error) {
+	world, err := page.CreateIsolatedWorld(id).WithWorldName("crawl-frames").Do(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to enter frame: %w", err)
+	}
+	res, exc, err := runtime.Evaluate(`document.documentElement ? document.documentElement.outerHTML : ""`).
+		WithContextID(world).
+		WithReturnByValue(true).
+		Do(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to read frame: %w", err)
+	}
+	if exc != nil {
+		return "", fmt.Errorf("failed to read frame: %s", exc.Text)
+	}
+	var html string
+	if err := json.Unmarshal(res.Value, &html); err != nil {
+		return "", fmt.Errorf("failed to read frame: %w", err)
+	}
+	return html, nil
+}
@@ -0,0 +1,127 @@
+// internal/engine/dynamic/phases.go
+package dynamic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/chromedp/chromedp"
+	"github.com/law-makers/crawl/pkg/models"
+)
+
+// Default budgets for each phase of an SPA page load
+const (
+	DefaultConnectTimeout    = 15 * time.Second
+	DefaultNavigationTimeout = 30 * time.Second
+	DefaultWaitTimeout       = 10 * time.Second
+	DefaultExtractTimeout    = 10 * time.Second
+)
+
+// Phase names used in timeout errors
+const (
+	PhaseConnect    = "connect"
+	PhaseNavigation = "navigation"
+	PhaseWait       = "wait"
+	PhaseExtract    = "extraction"
+)
+
+// phaseBudgets is the time allowed for each phase of a page load. Each phase
+// gets its own budget, so a slow browser startup cannot eat into the time
+// reserved for the page to render.
+type phaseBudgets struct {
+	Connect    time.Duration
+	Navigation time.Duration
+	Wait       time.Duration
+	Extract    time.Duration
+}
+
+// budgetsFor resolves the phase budgets for a request. Unset budgets use the
+// defaults; the general Timeout, when set, bounds navigation, and the wait
+// budget always covers WaitSeconds.
+func budgetsFor(opts models.RequestOptions) phaseBudgets {
+	b := phaseBudgets{
+		Connect:    opts.ConnectTimeout,
+		Navigation: opts.NavigationTimeout,
+		Wait:       opts.WaitTimeout,
+		Extract:    opts.ExtractTimeout,
+	}
+	if b.Connect <= 0 {
+		b.Connect = DefaultConnectTimeout
+	}
+	if b.Navigation <= 0 {
+		b.Navigation = opts.Timeout
+	}
+	if b.Navigation <= 0 {
+		b.Navigation = DefaultNavigationTimeout
+	}
+	if b.Wait <= 0 {
+		b.Wait = DefaultWaitTimeout + time.Duration(opts.WaitSeconds)*time.Second
+	}
+	if b.Extract <= 0 {
+		b.Extract = DefaultExtractTimeout
+	}
+	return b
+}
+
+// PhaseTimeoutError reports which phase of a page load ran out of time
+type PhaseTimeoutError struct {
+	Phase  string
+	Budget time.Duration
+}
+
+func (e *PhaseTimeoutError) Error() string {
+	return fmt.Sprintf("%s timed out after %s", e.Phase, e.Budget)
+}
+
+// Unwrap lets callers match the error with context.DeadlineExceeded
+func (e *PhaseTimeoutError) Unwrap() error {
+	return context.DeadlineExceeded
+}
+
+// phaseError converts a deadline error from phaseCtx into a PhaseTimeoutError.
+// Errors caused by the parent context ending are returned unchanged.
+func phaseError(parent, phaseCtx context.Context, phase string, budget time.Duration, err error) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(phaseCtx.Err(), context.DeadlineExceeded) && parent.Err() == nil {
+		return &PhaseTimeoutError{Phase: phase, Budget: budget}
+	}
+	return err
+}
+
+// runPhase runs actions on an existing tab under the phase's own deadline
+func runPhase(ctx context.Context, phase string, budget time.Duration, actions ...chromedp.Action) error {
+	phaseCtx, cancel := context.WithTimeout(ctx, budget)
+	defer cancel()
+	return phaseError(ctx, phaseCtx, phase, budget, chromedp.Run(phaseCtx, actions...))
+}
+
+// connect opens the tab, starting the browser first when it is not pooled.
+// The first Run on a context must not carry a deadline, since cancelling it
+// stops the browser, so the budget is enforced by aborting the request.
+func connect(ctx context.Context, abort context.CancelFunc, budget time.Duration, actions ...chromedp.Action) error {
+	timer := time.AfterFunc(budget, abort)
+	err := chromedp.Run(ctx, actions...)
+	if !timer.Stop() {
+		return &PhaseTimeoutError{Phase: PhaseConnect, Budget: budget}
+	}
+	return err
+}
+
+// sleepCtx pauses for d or until ctx is done
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
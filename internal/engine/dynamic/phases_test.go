@@ -0,0 +1,86 @@
+package dynamic
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/law-makers/crawl/pkg/models"
+)
+
+func TestBudgetsFor_Defaults(t *testing.T) {
+	b := budgetsFor(models.RequestOptions{})
+	if b.Connect != DefaultConnectTimeout || b.Navigation != DefaultNavigationTimeout ||
+		b.Wait != DefaultWaitTimeout || b.Extract != DefaultExtractTimeout {
+		t.Errorf("Unexpected default budgets: %+v", b)
+	}
+}
+
+func TestBudgetsFor_Overrides(t *testing.T) {
+	b := budgetsFor(models.RequestOptions{
+		Timeout:        45 * time.Second,
+		ConnectTimeout: 5 * time.Second,
+		WaitSeconds:    3,
+	})
+	if b.Connect != 5*time.Second {
+		t.Errorf("Expected connect budget 5s, got %s", b.Connect)
+	}
+	if b.Navigation != 45*time.Second {
+		t.Errorf("Expected navigation to fall back to Timeout, got %s", b.Navigation)
+	}
+	if b.Wait != DefaultWaitTimeout+3*time.Second {
+		t.Errorf("Expected wait budget to cover WaitSeconds, got %s", b.Wait)
+	}
+
+	b = budgetsFor(models.RequestOptions{Timeout: 45 * time.Second, NavigationTimeout: 20 * time.Second, WaitTimeout: 2 * time.Second})
+	if b.Navigation != 20*time.Second || b.Wait != 2*time.Second {
+		t.Errorf("Expected explicit budgets to win, got %+v", b)
+	}
+}
+
+func TestPhaseError_NamesPhase(t *testing.T) {
+	parent := context.Background()
+	phaseCtx, cancel := context.WithTimeout(parent, time.Nanosecond)
+	defer cancel()
+	<-phaseCtx.Done()
+
+	err := phaseError(parent, phaseCtx, PhaseNavigation, 20*time.Second, phaseCtx.Err())
+	if err == nil || err.Error() != "navigation timed out after 20s" {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Error("Expected phase timeout to match context.DeadlineExceeded")
+	}
+}
+
+func TestPhaseError_ParentCancelled(t *testing.T) {
+	parent, cancelParent := context.WithCancel(context.Background())
+	phaseCtx, cancel := context.WithTimeout(parent, time.Nanosecond)
+	defer cancel()
+	<-phaseCtx.Done()
+	cancelParent()
+
+	original := errors.New("aborted")
+	if err := phaseError(parent, phaseCtx, PhaseWait, time.Second, original); err != original {
+		t.Errorf("Expected original error when the request itself ended, got %v", err)
+	}
+	if err := phaseError(parent, phaseCtx, PhaseWait, time.Second, nil); err != nil {
+		t.Errorf("Expected nil, got %v", err)
+	}
+}
+
+func TestSleepCtx_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err := sleepCtx(ctx, time.Minute)
+	if err == nil || !strings.Contains(err.Error(), "deadline") {
+		t.Errorf("Expected deadline error, got %v", err)
+	}
+	if time.Since(start) > time.Second {
+		t.Error("Expected sleep to stop when the context ended")
+	}
+}
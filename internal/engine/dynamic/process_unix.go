@@ -0,0 +1,51 @@
+// internal/engine/dynamic/process_unix.go
+//go:build !windows
+
+package dynamic
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// processAlive reports whether a process with this pid exists
+func processAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	err := syscall.Kill(pid, 0)
+	// EPERM means it exists but belongs to another user
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
+
+// listProcesses returns the running processes, from /proc where there is
+// one and from ps elsewhere
+func listProcesses() ([]process, error) {
+	if entries, err := os.ReadDir("/proc"); err == nil {
+		var procs []process
+		for _, e := range entries {
+			pid, err := strconv.Atoi(e.Name())
+			if err != nil {
+				continue
+			}
+			raw, err := os.ReadFile(filepath.Join("/proc", e.Name(), "cmdline"))
+			if err != nil || len(raw) == 0 {
+				continue
+			}
+			args := strings.Split(strings.TrimSuffix(string(raw), "\x00"), "\x00")
+			procs = append(procs, process{PID: pid, Args: args, Cmdline: strings.Join(args, " ")})
+		}
+		return procs, nil
+	}
+
+	out, err := exec.Command("ps", "-axww", "-o", "pid=,command=").Output()
+	if err != nil {
+		return nil, err
+	}
+	return parseProcessList(out), nil
+}
@@ -0,0 +1,41 @@
+// internal/engine/dynamic/process_windows.go
+//go:build windows
+
+package dynamic
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// stillActive is the exit code Windows reports for a running process
+const stillActive = 259
+
+// processAlive reports whether a process with this pid is still running
+func processAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	h, err := syscall.OpenProcess(syscall.PROCESS_QUERY_INFORMATION, false, uint32(pid))
+	if err != nil {
+		// Access denied still means the process exists
+		return err == syscall.ERROR_ACCESS_DENIED
+	}
+	defer syscall.CloseHandle(h)
+	var code uint32
+	if err := syscall.GetExitCodeProcess(h, &code); err != nil {
+		return true
+	}
+	return code == stillActive
+}
+
+// listProcesses returns the running processes with a command line, via CIM
+// (there is no /proc or ps, and wmic is deprecated)
+func listProcesses() ([]process, error) {
+	script := `Get-CimInstance Win32_Process | Where-Object CommandLine | ForEach-Object { "$($_.ProcessId) $($_.CommandLine)" }`
+	out, err := exec.Command("powershell", "-NoProfile", "-NonInteractive", "-Command", script).Output()
+	if err != nil {
+		return nil, err
+	}
+	return parseProcessList(out), nil
+}
@@ -4,23 +4,31 @@ package dynamic
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"strings"
 	"sync"
 	"time"
 
-	"github.com/chromedp/cdproto/network"
-	"github.com/chromedp/chromedp"
+	"github.com/PuerkitoBio/goquery"
 	"github.com/law-makers/crawl/internal/cache"
+	"github.com/law-makers/crawl/internal/engine/metadata"
+	"github.com/law-makers/crawl/internal/logging"
 	"github.com/law-makers/crawl/internal/ratelimit"
+	"github.com/law-makers/crawl/internal/reqlog"
+	"github.com/law-makers/crawl/internal/trace"
 	"github.com/law-makers/crawl/pkg/models"
-	"github.com/rs/zerolog/log"
 )
 
-// Scraper implements the Scraper interface using headless Chrome
-// It uses chromedp to render JavaScript and handle SPAs (React/Vue/Angular)
+// Scraper implements the Scraper interface using a headless browser
+// It renders JavaScript to handle SPAs (React/Vue/Angular); the browser
+// backend is a Driver, chromedp by default
 type Scraper struct {
 	cache       cache.Cache
 	limiter     ratelimit.RateLimiter
-	browserPool *BrowserPool
+	concurrency *ratelimit.DomainConcurrency
+	requestLog  *reqlog.Logger
+	chromedp    *chromedpDriver
+	driver      Driver
 	client      interface{} // Keep for compatibility
 	timeout     time.Duration
 	userAgent   string
@@ -29,20 +37,61 @@ type Scraper struct {
 
 // New creates a new DynamicScraper with dependency injection
 func New(c cache.Cache, lim ratelimit.RateLimiter, pool *BrowserPool, timeout time.Duration, ua string) *Scraper {
+	cd := &chromedpDriver{pool: pool, userAgent: ua}
 	return &Scraper{
-		cache:       c,
-		limiter:     lim,
-		browserPool: pool,
-		timeout:     timeout,
-		userAgent:   ua,
+		cache:     c,
+		limiter:   lim,
+		chromedp:  cd,
+		driver:    cd,
+		timeout:   timeout,
+		userAgent: ua,
 	}
 }
 
-// SetBrowserPool updates the browser pool used by the scraper (thread-safe)
+// SetBrowserPool updates the browser pool used by the chromedp driver (thread-safe)
 func (d *Scraper) SetBrowserPool(bp *BrowserPool) {
-	d.mu.Lock()
-	defer d.mu.Unlock()
-	d.browserPool = bp
+	d.chromedp.setPool(bp)
+}
+
+// SetRemoteURL makes the scraper attach to a running browser at the given
+// CDP endpoint instead of launching Chrome when no pool is available
+func (d *Scraper) SetRemoteURL(url string) {
+	d.chromedp.remoteURL = url
+}
+
+// SetDebug enables headful, slow-motion and pause-on-failure debugging for
+// the chromedp driver. Call it before the browser pool is created.
+func (d *Scraper) SetDebug(opts DebugOptions) {
+	d.chromedp.debug = opts
+}
+
+// SetDriver replaces the browser backend used to load pages. Rate limiting,
+// concurrency caps and request logging still apply.
+func (d *Scraper) SetDriver(drv Driver) {
+	if drv != nil {
+		d.driver = drv
+	}
+}
+
+// Driver returns the browser backend in use
+func (d *Scraper) Driver() Driver {
+	return d.driver
+}
+
+// SetConcurrency sets the per-domain cap on simultaneous page loads
+func (d *Scraper) SetConcurrency(dc *ratelimit.DomainConcurrency) {
+	d.concurrency = dc
+}
+
+// SetURLFilter makes the chromedp driver fail every browser request,
+// redirects and subresources included, for which check returns an error
+func (d *Scraper) SetURLFilter(check func(url string) error) {
+	d.chromedp.blockURL = check
+}
+
+// SetRequestLog sets the audit log that records each page load
+func (d *Scraper) SetRequestLog(l *reqlog.Logger) {
+	d.requestLog = l
 }
 
 // Name returns the name of this scraper
@@ -53,193 +102,98 @@ func (d *Scraper) Name() string {
 // Fetch retrieves and parses a page using headless Chrome
 func (d *Scraper) Fetch(opts models.RequestOptions) (*models.PageData, error) {
 	start := time.Now()
-
-	log.Debug().
-		Str("url", opts.URL).
-		Str("scraper", d.Name()).
-		Msg("Starting fetch")
-
-	// Set timeout - use a reasonable timeout
-	timeout := opts.Timeout
-	if timeout == 0 {
-		timeout = 30 * time.Second
-	}
-
-	var ctx context.Context
-	var cancel context.CancelFunc
-
-	// 1. Try to use browser pool (faster and more stable)
-	if d.browserPool != nil {
-		bCtx, err := d.browserPool.Acquire(timeout)
-		if err != nil {
-			return nil, fmt.Errorf("failed to acquire browser from pool: %w", err)
-		}
-		// Release back to pool when function exits
-		defer d.browserPool.Release(bCtx)
-
-		// Create timeout context for this specific request
-		ctx, cancel = context.WithTimeout(bCtx.Ctx, timeout)
-		defer cancel()
-
-		log.Debug().Dur("elapsed_ms", time.Since(start)).Msg("Acquired browser from pool")
-	} else {
-		// 2. Fallback: Create new allocator and context (slower)
-		// We mirror the robust flags from browser_pool.go here to ensure stability on Windows
-
-		// Create base context with timeout
-		var baseCancel context.CancelFunc
-		ctx, baseCancel = context.WithTimeout(context.Background(), timeout)
-		defer baseCancel()
-
-		chromePath := FindChrome()
-		allocOpts := []chromedp.ExecAllocatorOption{
-			chromedp.NoFirstRun,
-			chromedp.NoDefaultBrowserCheck,
-			chromedp.Flag("headless", "new"),
-			chromedp.Flag("disable-gpu", true),
-			chromedp.Flag("no-sandbox", true),
-			chromedp.Flag("disable-dev-shm-usage", true),
-			chromedp.Flag("disable-extensions", true),
-			chromedp.Flag("disable-background-networking", true),
-			chromedp.Flag("disable-breakpad", true),
-			chromedp.Flag("disable-client-side-phishing-detection", true),
-			chromedp.Flag("disable-default-apps", true),
-			chromedp.Flag("disable-hang-monitor", true),
-			chromedp.Flag("disable-ipc-flooding-protection", true),
-			chromedp.Flag("disable-prompt-on-repost", true),
-			chromedp.Flag("disable-renderer-backgrounding", true),
-			chromedp.Flag("disable-sync", true),
-			chromedp.Flag("disable-translate", true),
-			chromedp.Flag("force-color-profile", "srgb"),
-			chromedp.Flag("metrics-recording-only", true),
-			chromedp.Flag("mute-audio", true),
-			chromedp.Flag("safebrowsing-disable-auto-update", true),
-			// Robustness flags (critical for Windows stability)
-			chromedp.Flag("disable-features", "site-per-process,TranslateUI,BlinkGenPropertyTrees"),
-			chromedp.Flag("enable-features", "NetworkService,NetworkServiceInProcess"),
-			chromedp.Flag("disable-blink-features", "AutomationControlled"),
-			chromedp.Flag("disable-infobars", true),
-			chromedp.Flag("window-size", "1920,1080"),
-			chromedp.Flag("disk-cache-size", "0"),
-			chromedp.Flag("media-cache-size", "0"),
-			chromedp.UserAgent(d.userAgent),
+	data, err := d.fetch(opts)
+
+	// Browser traffic bypasses the Go HTTP stack, so log the page load here
+	if d.requestLog != nil {
+		entry := reqlog.Entry{
+			Time:       start,
+			URL:        opts.URL,
+			Engine:     "dynamic",
+			Method:     "GET",
+			DurationMs: time.Since(start).Milliseconds(),
+			Proxy:      opts.Proxy,
+			Cache:      reqlog.CacheMiss,
+			JobID:      opts.JobID,
+			RequestID:  opts.RequestID,
 		}
-
-		// Set chrome path if found
-		if chromePath != "" {
-			allocOpts = append([]chromedp.ExecAllocatorOption{chromedp.ExecPath(chromePath)}, allocOpts...)
+		if data != nil {
+			entry.Status = data.StatusCode
+			entry.Bytes = int64(len(data.HTML))
 		}
-
-		// Add proxy if specified
-		if opts.Proxy != "" {
-			allocOpts = append(allocOpts, chromedp.ProxyServer(opts.Proxy))
+		if err != nil {
+			entry.Error = err.Error()
 		}
+		d.requestLog.Log(entry)
+	}
+	return data, err
+}
 
-		// Create allocator context
-		var allocCancel context.CancelFunc
-		ctx, allocCancel = chromedp.NewExecAllocator(ctx, allocOpts...)
-		// We defer allocCancel in a way that it runs when the function returns
-		defer allocCancel()
-
-		// Create browser context
-		ctx, cancel = chromedp.NewContext(ctx)
-		defer cancel()
+func (d *Scraper) fetch(opts models.RequestOptions) (*models.PageData, error) {
+	logger := logging.ForRequest(opts, "dynamic")
+	logger.Debug().Msg("Starting fetch")
 
-		log.Debug().Dur("elapsed_ms", time.Since(start)).Msg("Created new browser context (fallback)")
+	if opts.Method != "" && opts.Method != http.MethodGet {
+		// The browser navigates with GET; forms are submitted with opts.Submit
+		return nil, fmt.Errorf("%s requests are not supported in SPA mode", opts.Method)
 	}
 
-	// Build PageData
-	pageData := &models.PageData{
-		URL:       opts.URL,
-		FetchedAt: time.Now(),
-		Headers:   make(map[string]string),
-		Metadata:  make(map[string]string),
-		Links:     []string{},
-		Images:    []string{},
-		Scripts:   []string{},
+	// Timeout bounds the queueing steps below; the driver bounds the page
+	// load itself per phase
+	timeout := opts.Timeout
+	if timeout == 0 {
+		timeout = 30 * time.Second
 	}
 
-	// Variables to capture
-	var htmlContent string
-	var title string
-	var statusCode int64
-
-	navigateStart := time.Now()
-	log.Debug().Msg("Starting chromedp.Run")
-
-	// Listen for network events to capture status code and headers
-	chromedp.ListenTarget(ctx, func(ev interface{}) {
-		switch ev := ev.(type) {
-		case *network.EventResponseReceived:
-			resp := ev.Response
-			if resp.URL == opts.URL {
-				statusCode = resp.Status
-				// Capture headers
-				for key, value := range resp.Headers {
-					if strValue, ok := value.(string); ok {
-						pageData.Headers[key] = strValue
-					}
-				}
-			}
+	// Respect the per-domain rate limit and wait for a free per-domain slot
+	// before taking a browser
+	queued := time.Now()
+	waitCtx, waitCancel := context.WithTimeout(context.Background(), timeout)
+	if d.limiter != nil {
+		if err := d.limiter.Wait(waitCtx, opts.URL); err != nil {
+			waitCancel()
+			return nil, fmt.Errorf("rate limit wait failed: %w", err)
 		}
-	})
-
-	// Prepare selector to wait for (if specified)
-	selector := opts.Selector
-	if selector == "" || selector == "body" {
-		selector = "body"
 	}
-
-	// Build task list
-	tasks := []chromedp.Action{network.Enable()}
-
-	// Execute navigation and content extraction
-	tasks = append(tasks,
-		chromedp.Navigate(opts.URL),
-		// Wait a short initial period and any user-specified wait (opts.WaitSeconds)
-		chromedp.ActionFunc(func(ctx context.Context) error {
-			// Small sleep to let initial JS execute
-			time.Sleep(300 * time.Millisecond)
-			if opts.WaitSeconds > 0 {
-				log.Debug().Int("wait_seconds", opts.WaitSeconds).Msg("Waiting after navigation before scraping (dynamic)")
-				time.Sleep(time.Duration(opts.WaitSeconds) * time.Second)
-			}
-			return nil
-		}),
-		chromedp.Title(&title),
-		chromedp.OuterHTML("html", &htmlContent, chromedp.ByQuery),
-	)
-
-	// Execute tasks with fast rendering - no blocking waits
-	err := chromedp.Run(ctx, tasks...)
-
-	log.Debug().Dur("elapsed_ms", time.Since(navigateStart)).Msg("chromedp.Run completed")
-
+	release, err := d.concurrency.Acquire(waitCtx, opts.URL)
+	waitCancel()
 	if err != nil {
-		return nil, fmt.Errorf("chromedp execution failed: %w", err)
+		return nil, fmt.Errorf("timed out waiting for a connection slot: %w", err)
 	}
+	defer release()
+	waited := time.Since(queued)
 
-	responseTime := time.Since(start).Milliseconds()
-
-	// Update page data
-	pageData.Title = title
-	pageData.HTML = htmlContent
-	pageData.StatusCode = int(statusCode)
-	pageData.ResponseTime = responseTime
-
-	// Parse HTML to extract additional data
-	err = extractDataFromHTML(ctx, opts, pageData)
+	data, err := d.driver.Load(opts)
 	if err != nil {
-		log.Warn().Err(err).Msg("Failed to extract additional data")
+		return nil, err
 	}
-
-	log.Info().
-		Str("url", opts.URL).
-		Int("status", pageData.StatusCode).
-		Int64("response_time_ms", responseTime).
-		Int("links", len(pageData.Links)).
-		Int("images", len(pageData.Images)).
-		Msg("Fetch completed")
-
-	return pageData, nil
+	if data.Timings == nil {
+		data.Timings = &models.Timings{}
+	}
+	data.Timings.Waited += trace.Millis(waited)
+	needsDOM := len(opts.Fields) > 0 || opts.AllMatches || len(opts.Assertions) > 0 || len(opts.SelectorCandidates) > 0 || len(data.Frames) > 0
+	var doc *goquery.Document
+	if needsDOM && data.HTML != "" {
+		// Candidates, fields, matches, assertions and frames are read from
+		// the rendered DOM snapshot
+		if doc, err = goquery.NewDocumentFromReader(strings.NewReader(data.HTML)); err == nil {
+			if sel := metadata.FirstMatch(doc, opts.SelectorCandidates); sel != "" {
+				logger.Debug().Str("selector", sel).Msg("Using selector candidate")
+				opts.Selector = sel
+				data.Content, data.HTML = metadata.ExtractContent(doc, sel)
+			}
+			data.Structured = metadata.ExtractFields(doc, opts.Selector, opts.Fields)
+			if opts.AllMatches {
+				data.Data = metadata.ExtractMatches(doc, opts.Selector, !opts.NoHTML)
+			}
+			data.Assertions = metadata.CheckAssertions(doc, opts.Assertions)
+		}
+	}
+	metadata.ExtractFrames(data, doc, opts)
+	if opts.NoHTML {
+		data.HTML = ""
+	}
+	metadata.Hash(data)
+	metadata.NoAI(data)
+	return data, nil
 }
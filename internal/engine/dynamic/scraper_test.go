@@ -2,12 +2,16 @@
 package dynamic
 
 import (
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/law-makers/crawl/internal/cache"
+	"github.com/law-makers/crawl/internal/policy"
 	"github.com/law-makers/crawl/internal/ratelimit"
 	"github.com/law-makers/crawl/pkg/models"
 )
@@ -173,3 +177,81 @@ func TestDynamicScraper_Fetch_InvalidURL(t *testing.T) {
 		t.Error("Expected error for invalid URL, got nil")
 	}
 }
+
+func TestDynamicScraper_Fetch_PooledContextsDoNotShareCookies(t *testing.T) {
+	if FindChrome() == "" {
+		t.Skip("Chrome not installed")
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/login" {
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "secret", Path: "/"})
+		}
+		w.Write([]byte(`<html><body><p id="cookie">` + r.Header.Get("Cookie") + `</p></body></html>`))
+	}))
+	defer server.Close()
+
+	// A single pooled context forces both requests onto the same browser tab
+	pool, err := NewBrowserPool(BrowserPoolOptions{Size: 1, Headless: true})
+	if err != nil {
+		t.Fatalf("Failed to create browser pool: %v", err)
+	}
+	defer pool.Close()
+
+	scraper := NewTestDynamicScraper()
+	scraper.SetBrowserPool(pool)
+
+	if _, err := scraper.Fetch(models.RequestOptions{URL: server.URL + "/login", Selector: "#cookie", Timeout: 10 * time.Second}); err != nil {
+		t.Fatalf("First fetch failed: %v", err)
+	}
+	pageData, err := scraper.Fetch(models.RequestOptions{URL: server.URL + "/profile", Selector: "#cookie", Timeout: 10 * time.Second})
+	if err != nil {
+		t.Fatalf("Second fetch failed: %v", err)
+	}
+	if strings.Contains(pageData.Content, "secret") {
+		t.Errorf("Cookie from the first request leaked into the second: %q", pageData.Content)
+	}
+}
+
+func TestDynamicScraper_Fetch_PolicyBlocksRedirectAndSubresources(t *testing.T) {
+	if FindChrome() == "" {
+		t.Skip("Chrome not installed")
+	}
+
+	// The server answers on 127.0.0.1 and localhost; the policy denies localhost
+	var deniedHits int32
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.Host, "localhost") {
+			atomic.AddInt32(&deniedHits, 1)
+		}
+		denied := strings.Replace(server.URL, "127.0.0.1", "localhost", 1)
+		switch r.URL.Path {
+		case "/redirect":
+			http.Redirect(w, r, denied+"/page", http.StatusFound)
+		default:
+			w.Write([]byte(`<html><body><p>allowed</p><img src="` + denied + `/pixel.png"><iframe src="` + denied + `/frame"></iframe></body></html>`))
+		}
+	}))
+	defer server.Close()
+
+	pol := &policy.Policy{Path: "test-policy.yaml", DenyDomains: []string{"localhost"}}
+	scraper := NewTestDynamicScraper()
+	scraper.SetURLFilter(pol.CheckURL)
+
+	_, err := scraper.Fetch(models.RequestOptions{URL: server.URL + "/redirect", Mode: models.ModeSPA, Timeout: 10 * time.Second})
+	if !errors.Is(err, policy.ErrDenied) {
+		t.Errorf("Expected a redirect to a denied domain to fail with ErrDenied, got %v", err)
+	}
+
+	pageData, err := scraper.Fetch(models.RequestOptions{URL: server.URL + "/page", Mode: models.ModeSPA, Timeout: 10 * time.Second})
+	if err != nil {
+		t.Fatalf("Expected a page with denied subresources to load, got %v", err)
+	}
+	if !strings.Contains(pageData.Content, "allowed") {
+		t.Errorf("Unexpected content: %q", pageData.Content)
+	}
+	if n := atomic.LoadInt32(&deniedHits); n != 0 {
+		t.Errorf("Expected no requests to the denied domain, got %d", n)
+	}
+}
@@ -0,0 +1,37 @@
+// internal/engine/dynamic/stealth.go
+package dynamic
+
+import (
+	"context"
+
+	"github.com/chromedp/cdproto/emulation"
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/chromedp"
+	headersutil "github.com/law-makers/crawl/internal/utils/headers"
+)
+
+// stealthScript runs before any page script and hides the properties bot
+// detectors most often check to spot headless or automated Chrome
+const stealthScript = `(() => {
+  Object.defineProperty(Navigator.prototype, 'webdriver', { get: () => undefined });
+  Object.defineProperty(Navigator.prototype, 'languages', { get: () => ['en-US', 'en'] });
+  Object.defineProperty(Navigator.prototype, 'plugins', { get: () => [1, 2, 3, 4, 5] });
+  if (!window.chrome) { window.chrome = { runtime: {} }; }
+  const query = window.navigator.permissions && window.navigator.permissions.query;
+  if (query) {
+    window.navigator.permissions.query = (p) =>
+      p && p.name === 'notifications' ? Promise.resolve({ state: Notification.permission }) : query(p);
+  }
+})();`
+
+// stealthActions prepare a tab to look like a regular desktop browser: the
+// script above, and a user agent without "HeadlessChrome"
+func stealthActions() []chromedp.Action {
+	return []chromedp.Action{
+		emulation.SetUserAgentOverride(headersutil.BrowserUserAgent).WithAcceptLanguage("en-US,en"),
+		chromedp.ActionFunc(func(ctx context.Context) error {
+			_, err := page.AddScriptToEvaluateOnNewDocument(stealthScript).Do(ctx)
+			return err
+		}),
+	}
+}
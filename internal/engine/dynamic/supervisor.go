@@ -0,0 +1,246 @@
+// internal/engine/dynamic/supervisor.go
+package dynamic
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/chromedp/chromedp"
+	"github.com/rs/zerolog/log"
+)
+
+// profilePrefix starts the name of every user-data-dir crawl creates. The
+// pid of the crawl process follows it, so a browser whose owner has exited
+// (e.g. after SIGKILL) can be recognised from its command line.
+const profilePrefix = "crawl-chrome-"
+
+// profileFlag matches the tagged --user-data-dir on a browser command line
+var profileFlag = regexp.MustCompile(`--user-data-dir="?(.*?` + profilePrefix + `(\d+)-[^\s"/\\]+)`)
+
+// profileArg matches the same flag as a single argument
+var profileArg = regexp.MustCompile(`^--user-data-dir=(.*` + profilePrefix + `(\d+)-[^/\\]+)$`)
+
+// Zombie is a browser process launched by a crawl process that is gone
+type Zombie struct {
+	PID     int    `json:"pid"`
+	Owner   int    `json:"owner_pid"`
+	Profile string `json:"profile"`
+}
+
+// SweepResult reports what a sweep cleaned up
+type SweepResult struct {
+	Killed   []Zombie `json:"killed"`
+	Profiles int      `json:"profiles_removed"`
+}
+
+// process is a running process and its command line. Args is set where
+// the arguments can be told apart (/proc), Cmdline otherwise.
+type process struct {
+	PID     int
+	Args    []string
+	Cmdline string
+}
+
+var (
+	ownedMu       sync.Mutex
+	ownedProfiles = map[string]bool{}
+)
+
+// supervisedAllocator launches Chrome with a user-data-dir tagged with this
+// process's pid. The returned cancel stops the browser and removes the dir.
+func supervisedAllocator(parent context.Context, allocOpts ...chromedp.ExecAllocatorOption) (context.Context, context.CancelFunc) {
+	dir, err := os.MkdirTemp("", fmt.Sprintf("%s%d-", profilePrefix, os.Getpid()))
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to create browser profile, using chromedp's default")
+		return chromedp.NewExecAllocator(parent, allocOpts...)
+	}
+	ownedMu.Lock()
+	ownedProfiles[dir] = true
+	ownedMu.Unlock()
+
+	allocCtx, cancel := chromedp.NewExecAllocator(parent, append(allocOpts, chromedp.UserDataDir(dir))...)
+	return allocCtx, func() {
+		cancel()
+		removeProfile(dir)
+	}
+}
+
+// removeProfile deletes a user-data-dir this process created
+func removeProfile(dir string) {
+	ownedMu.Lock()
+	delete(ownedProfiles, dir)
+	ownedMu.Unlock()
+	if err := os.RemoveAll(dir); err != nil {
+		log.Debug().Err(err).Str("dir", dir).Msg("Failed to remove browser profile")
+	}
+}
+
+// parseProfile returns the tagged user-data-dir on a browser command line
+// and the pid of the crawl process that created it. Only the browser process
+// itself is matched (its renderers exit with it), which also keeps shells
+// and editors that merely mention the dir safe.
+func parseProfile(p process) (string, int, bool) {
+	var m []string
+	if p.Args != nil {
+		debugging := false
+		for _, arg := range p.Args {
+			if strings.HasPrefix(arg, "--remote-debugging-port") {
+				debugging = true
+			} else if found := profileArg.FindStringSubmatch(arg); found != nil {
+				m = found
+			}
+		}
+		if !debugging {
+			return "", 0, false
+		}
+	} else if strings.Contains(p.Cmdline, "--remote-debugging-port") {
+		m = profileFlag.FindStringSubmatch(p.Cmdline)
+	}
+	if m == nil {
+		return "", 0, false
+	}
+	owner, err := strconv.Atoi(m[2])
+	if err != nil {
+		return "", 0, false
+	}
+	return m[1], owner, true
+}
+
+// FindZombies lists browser processes whose crawl process has exited
+func FindZombies() ([]Zombie, error) {
+	procs, err := listProcesses()
+	if err != nil {
+		return nil, err
+	}
+	return zombiesIn(procs, processAlive), nil
+}
+
+// zombiesIn picks the orphaned browsers out of procs
+func zombiesIn(procs []process, alive func(int) bool) []Zombie {
+	var zombies []Zombie
+	for _, p := range procs {
+		profile, owner, ok := parseProfile(p)
+		if !ok || owner == os.Getpid() || alive(owner) {
+			continue
+		}
+		zombies = append(zombies, Zombie{PID: p.PID, Owner: owner, Profile: profile})
+	}
+	return zombies
+}
+
+// Sweep kills orphaned browsers and removes the profiles they and any other
+// exited crawl processes left in the temp directory
+func Sweep() (SweepResult, error) {
+	var result SweepResult
+	zombies, err := FindZombies()
+	if err != nil {
+		return result, err
+	}
+	for _, z := range zombies {
+		if err := killProcess(z.PID); err != nil {
+			log.Debug().Err(err).Int("pid", z.PID).Msg("Failed to kill orphaned browser")
+			continue
+		}
+		result.Killed = append(result.Killed, z)
+	}
+	result.Profiles = removeStaleProfiles(os.TempDir(), processAlive)
+	return result, nil
+}
+
+// removeStaleProfiles deletes profile dirs in dir whose owner has exited
+func removeStaleProfiles(dir string, alive func(int) bool) int {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return 0
+	}
+	removed := 0
+	for _, e := range entries {
+		name := e.Name()
+		if !e.IsDir() || !strings.HasPrefix(name, profilePrefix) {
+			continue
+		}
+		pidPart, _, _ := strings.Cut(strings.TrimPrefix(name, profilePrefix), "-")
+		owner, err := strconv.Atoi(pidPart)
+		if err != nil || owner == os.Getpid() || alive(owner) {
+			continue
+		}
+		if os.RemoveAll(filepath.Join(dir, name)) == nil {
+			removed++
+		}
+	}
+	return removed
+}
+
+// sweepOnStart cleans up after earlier crashed runs before launching Chrome
+func sweepOnStart() {
+	result, err := Sweep()
+	if err != nil {
+		log.Debug().Err(err).Msg("Skipped the orphaned browser sweep")
+		return
+	}
+	if len(result.Killed) > 0 || result.Profiles > 0 {
+		log.Info().Int("killed", len(result.Killed)).Int("profiles", result.Profiles).Msg("Cleaned up browsers left by an earlier run")
+	}
+}
+
+// KillOwned stops every browser this process launched and removes their
+// profiles. It is for exit paths that skip deferred cleanup, such as
+// os.Exit from a signal handler.
+func KillOwned() {
+	ownedMu.Lock()
+	dirs := make([]string, 0, len(ownedProfiles))
+	for dir := range ownedProfiles {
+		dirs = append(dirs, dir)
+	}
+	ownedMu.Unlock()
+	if len(dirs) == 0 {
+		return
+	}
+
+	if procs, err := listProcesses(); err == nil {
+		for _, p := range procs {
+			if _, owner, ok := parseProfile(p); ok && owner == os.Getpid() {
+				killProcess(p.PID)
+			}
+		}
+	}
+	for _, dir := range dirs {
+		removeProfile(dir)
+	}
+}
+
+// parseProcessList reads "<pid> <command line>" lines
+func parseProcessList(out []byte) []process {
+	var procs []process
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		pidField, cmdline, ok := strings.Cut(strings.TrimSpace(scanner.Text()), " ")
+		if !ok {
+			continue
+		}
+		pid, err := strconv.Atoi(pidField)
+		if err != nil {
+			continue
+		}
+		procs = append(procs, process{PID: pid, Cmdline: strings.TrimSpace(cmdline)})
+	}
+	return procs
+}
+
+// killProcess terminates a process by pid
+func killProcess(pid int) error {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return err
+	}
+	return p.Kill()
+}
@@ -0,0 +1,77 @@
+package dynamic
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseProfile(t *testing.T) {
+	args := []string{"/usr/bin/chromium", "--remote-debugging-port=0", "--user-data-dir=/tmp/crawl-chrome-4242-123456", "about:blank"}
+	profile, owner, ok := parseProfile(process{Args: args})
+	if !ok || owner != 4242 || profile != "/tmp/crawl-chrome-4242-123456" {
+		t.Errorf("Expected the profile of crawl 4242, got %q %d %v", profile, owner, ok)
+	}
+
+	// A shell whose script mentions the flags is not a browser
+	shell := []string{"bash", "-c", "chromium --remote-debugging-port=0 --user-data-dir=/tmp/crawl-chrome-4242-1"}
+	if _, _, ok := parseProfile(process{Args: shell}); ok {
+		t.Error("Expected a shell command line not to match")
+	}
+	// Nor is a browser crawl didn't launch
+	if _, _, ok := parseProfile(process{Args: []string{"chrome", "--remote-debugging-port=9222", "--user-data-dir=/home/me/.config/chrome"}}); ok {
+		t.Error("Expected an untagged profile not to match")
+	}
+
+	// ps and Windows only give the whole line, possibly quoted
+	line := `"C:\Program Files\Google\Chrome\Application\chrome.exe" --remote-debugging-port=0 "--user-data-dir=C:\Users\A B\AppData\Local\Temp\crawl-chrome-77-99" about:blank`
+	profile, owner, ok = parseProfile(process{Cmdline: line})
+	if !ok || owner != 77 || profile != `C:\Users\A B\AppData\Local\Temp\crawl-chrome-77-99` {
+		t.Errorf("Expected the quoted Windows profile, got %q %d %v", profile, owner, ok)
+	}
+}
+
+func TestZombiesIn(t *testing.T) {
+	browser := func(owner string) []string {
+		return []string{"chrome", "--remote-debugging-port=0", "--user-data-dir=/tmp/crawl-chrome-" + owner + "-1"}
+	}
+	procs := []process{
+		{PID: 10, Args: browser("100")},
+		{PID: 11, Args: browser("200")},
+		{PID: 12, Args: []string{"sleep", "60"}},
+	}
+	alive := func(pid int) bool { return pid == 200 }
+
+	zombies := zombiesIn(procs, alive)
+	if len(zombies) != 1 || zombies[0].PID != 10 || zombies[0].Owner != 100 {
+		t.Errorf("Expected only the browser of exited crawl 100, got %+v", zombies)
+	}
+}
+
+func TestRemoveStaleProfiles(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"crawl-chrome-100-a", "crawl-chrome-200-b", "chromedp-runner-1", "crawl-chrome-x-c"} {
+		os.Mkdir(filepath.Join(dir, name), 0755)
+	}
+	alive := func(pid int) bool { return pid == 200 }
+
+	if n := removeStaleProfiles(dir, alive); n != 1 {
+		t.Errorf("Expected 1 profile removed, got %d", n)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "crawl-chrome-100-a")); !os.IsNotExist(err) {
+		t.Error("Expected the stale profile to be gone")
+	}
+	for _, name := range []string{"crawl-chrome-200-b", "chromedp-runner-1", "crawl-chrome-x-c"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("Expected %s to be kept", name)
+		}
+	}
+}
+
+func TestParseProcessList(t *testing.T) {
+	out := []byte("  1 /sbin/init\n 42 /usr/bin/chromium --headless=new\nbogus\n")
+	procs := parseProcessList(out)
+	if len(procs) != 2 || procs[1].PID != 42 || procs[1].Cmdline != "/usr/bin/chromium --headless=new" {
+		t.Errorf("Unexpected processes: %+v", procs)
+	}
+}
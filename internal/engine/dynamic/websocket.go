@@ -0,0 +1,86 @@
+// internal/engine/dynamic/websocket.go
+package dynamic
+
+import (
+	"encoding/base64"
+	"sync"
+	"time"
+
+	"github.com/chromedp/cdproto/network"
+	"github.com/law-makers/crawl/pkg/models"
+)
+
+// wsRecorder collects the WebSocket frames of a page load from CDP network
+// events, within the limits of a models.WSCapture
+type wsRecorder struct {
+	limits models.WSCapture
+
+	mu       sync.Mutex
+	urls     map[network.RequestID]string
+	messages []models.WSMessage
+	dropped  int
+}
+
+func newWSRecorder(limits models.WSCapture) *wsRecorder {
+	return &wsRecorder{limits: limits, urls: map[network.RequestID]string{}}
+}
+
+// handle records ev if it is a WebSocket event
+func (r *wsRecorder) handle(ev interface{}) {
+	switch ev := ev.(type) {
+	case *network.EventWebSocketCreated:
+		r.mu.Lock()
+		r.urls[ev.RequestID] = ev.URL
+		r.mu.Unlock()
+	case *network.EventWebSocketFrameSent:
+		r.add(ev.RequestID, "sent", ev.Response)
+	case *network.EventWebSocketFrameReceived:
+		r.add(ev.RequestID, "received", ev.Response)
+	}
+}
+
+func (r *wsRecorder) add(id network.RequestID, direction string, frame *network.WebSocketFrame) {
+	if frame == nil {
+		return
+	}
+	// Opcode 1 is text, 2 binary (base64 in PayloadData); the rest are
+	// control frames
+	if frame.Opcode != 1 && frame.Opcode != 2 {
+		return
+	}
+	msg := models.WSMessage{
+		Direction: direction,
+		Time:      time.Now(),
+		Binary:    frame.Opcode == 2,
+		Data:      frame.PayloadData,
+		Size:      len(frame.PayloadData),
+	}
+	if msg.Binary {
+		if raw, err := base64.StdEncoding.DecodeString(frame.PayloadData); err == nil {
+			msg.Size = len(raw)
+			if max := r.limits.MaxMessageSize; max > 0 && len(raw) > max {
+				msg.Data = base64.StdEncoding.EncodeToString(raw[:max])
+				msg.Truncated = true
+			}
+		}
+	} else if max := r.limits.MaxMessageSize; max > 0 && len(msg.Data) > max {
+		msg.Data = msg.Data[:max]
+		msg.Truncated = true
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.limits.MaxMessages > 0 && len(r.messages) >= r.limits.MaxMessages {
+		r.dropped++
+		return
+	}
+	msg.URL = r.urls[id]
+	r.messages = append(r.messages, msg)
+}
+
+// result returns the recorded frames and how many were over the limit
+func (r *wsRecorder) result() ([]models.WSMessage, int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]models.WSMessage(nil), r.messages...), r.dropped
+}
@@ -0,0 +1,33 @@
+package dynamic
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/chromedp/cdproto/network"
+	"github.com/law-makers/crawl/pkg/models"
+)
+
+func TestWSRecorder(t *testing.T) {
+	r := newWSRecorder(models.WSCapture{MaxMessages: 3, MaxMessageSize: 4})
+	r.handle(&network.EventWebSocketCreated{RequestID: "1", URL: "wss://example.com/live"})
+	r.handle(&network.EventWebSocketFrameSent{RequestID: "1", Response: &network.WebSocketFrame{Opcode: 1, PayloadData: "sub"}})
+	r.handle(&network.EventWebSocketFrameReceived{RequestID: "1", Response: &network.WebSocketFrame{Opcode: 9}}) // ping
+	r.handle(&network.EventWebSocketFrameReceived{RequestID: "1", Response: &network.WebSocketFrame{Opcode: 1, PayloadData: `{"price":1}`}})
+	r.handle(&network.EventWebSocketFrameReceived{RequestID: "1", Response: &network.WebSocketFrame{Opcode: 2, PayloadData: base64.StdEncoding.EncodeToString([]byte{1, 2, 3, 4, 5, 6})}})
+	r.handle(&network.EventWebSocketFrameReceived{RequestID: "1", Response: &network.WebSocketFrame{Opcode: 1, PayloadData: "late"}})
+
+	msgs, dropped := r.result()
+	if len(msgs) != 3 || dropped != 1 {
+		t.Fatalf("Expected 3 messages and 1 dropped, got %d and %d", len(msgs), dropped)
+	}
+	if m := msgs[0]; m.URL != "wss://example.com/live" || m.Direction != "sent" || m.Data != "sub" || m.Truncated {
+		t.Errorf("Unexpected sent message: %+v", m)
+	}
+	if m := msgs[1]; m.Direction != "received" || m.Data != `{"pr` || m.Size != 11 || !m.Truncated {
+		t.Errorf("Expected a truncated text message, got %+v", m)
+	}
+	if m := msgs[2]; !m.Binary || m.Size != 6 || m.Data != base64.StdEncoding.EncodeToString([]byte{1, 2, 3, 4}) || !m.Truncated {
+		t.Errorf("Expected a truncated binary message, got %+v", m)
+	}
+}
@@ -4,11 +4,13 @@ package hybrid
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/PuerkitoBio/goquery"
 	"github.com/dop251/goja"
 	"github.com/law-makers/crawl/internal/engine/dynamic"
 	"github.com/law-makers/crawl/internal/engine/static"
+	"github.com/law-makers/crawl/internal/trace"
 	"github.com/law-makers/crawl/pkg/models"
 	"github.com/rs/zerolog/log"
 )
@@ -43,8 +45,13 @@ func (s *Scraper) Fetch(opts models.RequestOptions) (*models.PageData, error) {
 	// 2. Execute JS if needed
 	// We only execute if we found scripts and the user didn't explicitly ask for static only
 	// (Though HybridScraper implies we want JS)
-	if len(data.Scripts) > 0 || strings.Contains(data.HTML, "<script") {
+	if len(data.Scripts) > 0 || doc.Find("script").Length() > 0 {
+		start := time.Now()
 		executeScripts(data, doc)
+		opts.Trace.Span("scripts", start, time.Now(), "inline JS")
+		if data.Timings != nil {
+			data.Timings.Render = trace.Millis(time.Since(start))
+		}
 	}
 
 	return data, nil
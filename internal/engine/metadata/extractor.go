@@ -2,6 +2,7 @@
 package metadata
 
 import (
+	"fmt"
 	"strings"
 
 	"github.com/PuerkitoBio/goquery"
@@ -49,10 +50,42 @@ func Extract(doc *goquery.Document, pageData *models.PageData) {
 			pageData.Scripts = append(pageData.Scripts, src)
 		}
 	})
+
+	// Find a print version
+	var alternates []string
+	doc.Find(PrintAlternateSelector).Each(func(i int, sel *goquery.Selection) {
+		alternates = append(alternates, sel.AttrOr("href", ""))
+	})
+	pageData.PrintURL = PrintURL(pageData.URL, alternates, pageData.Links)
+}
+
+// FirstMatch returns the first of candidates that matches an element with
+// text in doc, or "" when none does
+func FirstMatch(doc *goquery.Document, candidates []string) string {
+	if doc == nil {
+		return ""
+	}
+	for _, sel := range candidates {
+		if strings.TrimSpace(doc.Find(sel).Text()) != "" {
+			return sel
+		}
+	}
+	return ""
 }
 
 // ExtractContent extracts content based on selector or defaults to body
 func ExtractContent(doc *goquery.Document, selector string) (content string, html string) {
+	return extractContent(doc, selector, true)
+}
+
+// ExtractText is ExtractContent without serializing the HTML, which avoids
+// keeping a second full copy of large pages in memory
+func ExtractText(doc *goquery.Document, selector string) string {
+	content, _ := extractContent(doc, selector, false)
+	return content
+}
+
+func extractContent(doc *goquery.Document, selector string, withHTML bool) (content string, html string) {
 	if doc == nil {
 		return "", ""
 	}
@@ -62,13 +95,119 @@ func ExtractContent(doc *goquery.Document, selector string) (content string, htm
 		selection := doc.Find(selector)
 		if selection.Length() > 0 {
 			content = strings.TrimSpace(selection.Text())
-			html, _ = selection.Html()
+			if withHTML {
+				html, _ = selection.Html()
+			}
 			return content, html
 		}
 	}
 
 	// Default: extract body content
 	content = strings.TrimSpace(doc.Find("body").Text())
-	html, _ = doc.Find("html").Html()
+	if withHTML {
+		html, _ = doc.Find("html").Html()
+	}
 	return content, html
 }
+
+// ExtractFields returns one structured row per element matched by selector
+// (or a single row for the whole document when selector is empty or body),
+// with each field read from the first match of its selector inside it
+func ExtractFields(doc *goquery.Document, selector string, fields []models.Field) []map[string]string {
+	if doc == nil || len(fields) == 0 {
+		return nil
+	}
+
+	scopes := doc.Selection
+	if selector != "" && selector != "body" {
+		scopes = doc.Find(selector)
+	}
+
+	var rows []map[string]string
+	scopes.Each(func(i int, scope *goquery.Selection) {
+		row := make(map[string]string, len(fields))
+		for _, f := range fields {
+			sel, attr := f.Selector, ""
+			if at := strings.LastIndex(sel, "@"); at >= 0 {
+				sel, attr = sel[:at], sel[at+1:]
+			}
+
+			match := scope
+			if strings.TrimSpace(sel) != "" {
+				match = scope.Find(sel).First()
+			}
+			if attr != "" {
+				row[f.Name], _ = match.Attr(attr)
+			} else {
+				row[f.Name] = strings.TrimSpace(match.Text())
+			}
+		}
+		rows = append(rows, row)
+	})
+	return rows
+}
+
+// ExtractMatches returns one item per element matched by selector, with a
+// CSS path that selects it alone
+func ExtractMatches(doc *goquery.Document, selector string, withHTML bool) []models.SelectionData {
+	if doc == nil || selector == "" {
+		return nil
+	}
+
+	var items []models.SelectionData
+	doc.Find(selector).Each(func(i int, s *goquery.Selection) {
+		item := models.SelectionData{
+			Index:    i,
+			Selector: cssPath(s),
+			Text:     strings.TrimSpace(s.Text()),
+		}
+		if withHTML {
+			item.HTML, _ = goquery.OuterHtml(s)
+		}
+		items = append(items, item)
+	})
+	return items
+}
+
+// cssPath builds a selector for s from the nearest ancestor with an id (or
+// the html element), using :nth-child for each step below it
+func cssPath(s *goquery.Selection) string {
+	var parts []string
+	for n := s; n.Length() > 0 && goquery.NodeName(n) != "#document"; n = n.Parent() {
+		name := goquery.NodeName(n)
+		if id, ok := n.Attr("id"); ok && id != "" && !strings.ContainsAny(id, " \t\n\"'") {
+			parts = append(parts, "#"+id)
+			break
+		}
+		if name == "html" || name == "body" {
+			parts = append(parts, name)
+			if name == "html" {
+				break
+			}
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("%s:nth-child(%d)", name, n.PrevAll().Length()+1))
+	}
+
+	for i, j := 0, len(parts)-1; i < j; i, j = i+1, j-1 {
+		parts[i], parts[j] = parts[j], parts[i]
+	}
+	return strings.Join(parts, " > ")
+}
+
+// CheckAssertions counts the matches of each assertion's selector in doc
+func CheckAssertions(doc *goquery.Document, assertions []models.Assertion) []models.AssertionResult {
+	if doc == nil || len(assertions) == 0 {
+		return nil
+	}
+	results := make([]models.AssertionResult, len(assertions))
+	for i, a := range assertions {
+		count := doc.Find(a.Selector).Length()
+		results[i] = models.AssertionResult{
+			Assertion: a,
+			Count:     count,
+			Passed:    count >= a.Min && (a.Max < 0 || count <= a.Max),
+		}
+	}
+	return results
+}
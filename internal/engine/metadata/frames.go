@@ -0,0 +1,81 @@
+// internal/engine/metadata/frames.go
+package metadata
+
+import (
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/law-makers/crawl/pkg/models"
+)
+
+// MaxFrameDepth is how deep the engines follow iframes nested in iframes
+const MaxFrameDepth = 3
+
+// FrameField is the structured-row column naming the iframe a row came from
+const FrameField = "_frame"
+
+// ExtractFrames runs the request's selector, fields and --all-matches over
+// each of pageData.Frames, whose HTML the engine filled with the frame's
+// whole document, and merges the results into the page's. Frame HTML is
+// replaced by what the selector matched. When the selector matched nothing
+// in the page itself, the page's body-text fallback gives way to the frames'
+// content.
+func ExtractFrames(pageData *models.PageData, page *goquery.Document, opts models.RequestOptions) {
+	if pageData == nil || len(pageData.Frames) == 0 {
+		return
+	}
+	specific := opts.Selector != "" && opts.Selector != "body"
+	pageMatched := !specific || (page != nil && page.Find(opts.Selector).Length() > 0)
+
+	var contents []string
+	if pageMatched && pageData.Content != "" {
+		contents = append(contents, pageData.Content)
+	}
+	for i := range pageData.Frames {
+		f := &pageData.Frames[i]
+		full := f.HTML
+		f.HTML = ""
+		if f.Error != "" || full == "" {
+			continue
+		}
+		doc, err := goquery.NewDocumentFromReader(strings.NewReader(full))
+		if err != nil {
+			f.Error = err.Error()
+			continue
+		}
+		f.Title = strings.TrimSpace(doc.Find("title").First().Text())
+		if !specific || doc.Find(opts.Selector).Length() > 0 {
+			f.Content, f.HTML = extractContent(doc, opts.Selector, !opts.NoHTML)
+			if f.Content != "" {
+				contents = append(contents, f.Content)
+			}
+		}
+		for _, row := range ExtractFields(doc, opts.Selector, opts.Fields) {
+			if emptyRow(row) {
+				continue
+			}
+			row[FrameField] = f.URL
+			pageData.Structured = append(pageData.Structured, row)
+		}
+		if opts.AllMatches {
+			for _, item := range ExtractMatches(doc, opts.Selector, !opts.NoHTML) {
+				item.Frame = f.URL
+				pageData.Data = append(pageData.Data, item)
+			}
+		}
+	}
+	if len(contents) > 0 {
+		pageData.Content = strings.Join(contents, "\n\n")
+	}
+}
+
+// emptyRow reports whether every field of row is blank, as rows from a
+// frame without the fields' elements are
+func emptyRow(row map[string]string) bool {
+	for _, v := range row {
+		if v != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,30 @@
+// internal/engine/metadata/hash.go
+package metadata
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+
+	"github.com/law-makers/crawl/pkg/models"
+)
+
+// Hash sets the page's ContentHash and, when its HTML is retained, HTMLHash
+func Hash(pageData *models.PageData) {
+	pageData.ContentHash = ContentHash(pageData.Content)
+	pageData.HTMLHash = ""
+	if pageData.HTML != "" {
+		pageData.HTMLHash = sha256Hex(pageData.HTML)
+	}
+}
+
+// ContentHash is the SHA-256 of content with runs of whitespace collapsed
+// and the ends trimmed, so reflowed but otherwise identical text hashes the same
+func ContentHash(content string) string {
+	return sha256Hex(strings.Join(strings.Fields(content), " "))
+}
+
+func sha256Hex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
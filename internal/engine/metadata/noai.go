@@ -0,0 +1,57 @@
+// internal/engine/metadata/noai.go
+package metadata
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/law-makers/crawl/pkg/models"
+)
+
+// NoAI sets the page's NoAI to the text and data mining reservations in its
+// headers and meta tags: noai/noimageai in X-Robots-Tag or meta robots, and
+// the W3C TDMRep tdm-reservation header or meta tag
+func NoAI(pageData *models.PageData) {
+	pageData.NoAI = nil
+	for key, value := range pageData.Headers {
+		switch strings.ToLower(key) {
+		case "x-robots-tag":
+			for _, d := range noAIDirectives(value) {
+				pageData.NoAI = append(pageData.NoAI, "X-Robots-Tag: "+d)
+			}
+		case "tdm-reservation":
+			if strings.TrimSpace(value) == "1" {
+				pageData.NoAI = append(pageData.NoAI, "TDM-Reservation: 1")
+			}
+		}
+	}
+	for name, content := range pageData.Metadata {
+		switch strings.ToLower(name) {
+		case "robots":
+			for _, d := range noAIDirectives(content) {
+				pageData.NoAI = append(pageData.NoAI, "meta robots: "+d)
+			}
+		case "tdm-reservation":
+			if strings.TrimSpace(content) == "1" {
+				pageData.NoAI = append(pageData.NoAI, "meta tdm-reservation: 1")
+			}
+		}
+	}
+	sort.Strings(pageData.NoAI)
+}
+
+// noAIDirectives returns the noai and noimageai directives in a robots
+// value such as "noindex, noai" or "otherbot: noimageai"
+func noAIDirectives(value string) []string {
+	var found []string
+	for _, d := range strings.Split(value, ",") {
+		d = strings.ToLower(strings.TrimSpace(d))
+		if i := strings.LastIndex(d, ":"); i >= 0 {
+			d = strings.TrimSpace(d[i+1:])
+		}
+		if d == "noai" || d == "noimageai" {
+			found = append(found, d)
+		}
+	}
+	return found
+}
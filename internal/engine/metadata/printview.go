@@ -0,0 +1,97 @@
+// internal/engine/metadata/printview.go
+package metadata
+
+import (
+	"net/url"
+	"strings"
+)
+
+// PrintAlternateSelector finds the print versions a page declares in its head
+const PrintAlternateSelector = `link[rel~="alternate"][media~="print"][href]`
+
+// printParams are query parameters that ask for a print or reader view
+// (print=1, view=print, ...); a bare key such as ?print counts too
+var printParams = map[string][]string{
+	"print":     {"", "1", "true", "yes", "y"},
+	"printable": {"", "1", "true", "yes", "y"},
+	"view":      {"print", "printable", "reader"},
+	"mode":      {"print", "reader"},
+	"format":    {"print", "printable"},
+	"output":    {"print"},
+	"layout":    {"print"},
+	"reader":    {"1", "true"},
+}
+
+// printSegments are path segments that mark a print or reader view when
+// appended to, or put in front of, the page's own path
+var printSegments = []string{"print", "printable", "printer-friendly", "reader"}
+
+// PrintURL returns the print or reader version of the page at pageURL:
+// the first of alternates (the hrefs of PrintAlternateSelector), else the
+// first of links that is the page's own path with a print parameter or
+// segment added, e.g. ?print=1 or /print. Only same-host http(s) URLs
+// count. It returns "" when the page has none.
+func PrintURL(pageURL string, alternates, links []string) string {
+	page, err := url.Parse(pageURL)
+	if err != nil || page.Host == "" {
+		return ""
+	}
+	resolve := func(href string) *url.URL {
+		u, err := page.Parse(strings.TrimSpace(href))
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || !strings.EqualFold(u.Host, page.Host) {
+			return nil
+		}
+		u.Fragment = ""
+		if u.String() == page.String() {
+			return nil
+		}
+		return u
+	}
+
+	for _, href := range alternates {
+		if u := resolve(href); u != nil {
+			return u.String()
+		}
+	}
+	for _, href := range links {
+		if u := resolve(href); u != nil && isPrintView(u, page) {
+			return u.String()
+		}
+	}
+	return ""
+}
+
+// isPrintView reports whether u is page's URL with a print parameter added,
+// or its path with a print segment added
+func isPrintView(u, page *url.URL) bool {
+	pagePath := strings.TrimSuffix(page.Path, "/")
+	path := strings.TrimSuffix(u.Path, "/")
+	if path == pagePath {
+		query := u.Query()
+		found := false
+		for key, values := range query {
+			if len(values) == 1 && printParam(key, values[0]) {
+				delete(query, key)
+				found = true
+			}
+		}
+		// The rest of the query must be the page's, or it's another page
+		return found && query.Encode() == page.Query().Encode()
+	}
+	for _, seg := range printSegments {
+		if strings.EqualFold(path, pagePath+"/"+seg) || strings.EqualFold(path, "/"+seg+pagePath) {
+			return true
+		}
+	}
+	return false
+}
+
+// printParam reports whether key=value asks for a print or reader view
+func printParam(key, value string) bool {
+	for _, v := range printParams[strings.ToLower(key)] {
+		if strings.EqualFold(value, v) {
+			return true
+		}
+	}
+	return false
+}
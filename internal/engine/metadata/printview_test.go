@@ -0,0 +1,27 @@
+package metadata
+
+import "testing"
+
+func TestPrintURL(t *testing.T) {
+	const page = "https://example.com/news/story?id=7"
+	tests := []struct {
+		name       string
+		alternates []string
+		links      []string
+		want       string
+	}{
+		{"alternate", []string{"/news/story/print?id=7"}, nil, "https://example.com/news/story/print?id=7"},
+		{"print param", nil, []string{"/about", "story?id=7&print=1"}, "https://example.com/news/story?id=7&print=1"},
+		{"view param", nil, []string{"?id=7&view=reader"}, "https://example.com/news/story?id=7&view=reader"},
+		{"print segment", nil, []string{"/news/story/print?id=7"}, "https://example.com/news/story/print?id=7"},
+		{"other page", nil, []string{"story?id=8&print=1", "/news/other/print"}, ""},
+		{"other host", []string{"https://cdn.example.net/story.pdf"}, []string{"https://other.com/news/story?id=7&print=1"}, ""},
+		{"script", nil, []string{"javascript:window.print()"}, ""},
+		{"not print", nil, []string{"story?id=7&print=0", "story?id=7#print"}, ""},
+	}
+	for _, tt := range tests {
+		if got := PrintURL(page, tt.alternates, tt.links); got != tt.want {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.want, got)
+		}
+	}
+}
@@ -2,11 +2,16 @@ package engine
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/law-makers/crawl/internal/cache"
 	"github.com/law-makers/crawl/internal/engine/batch"
+	"github.com/law-makers/crawl/internal/engine/static"
 	"github.com/law-makers/crawl/pkg/models"
 )
 
@@ -153,3 +158,40 @@ func BenchmarkMemoryAllocation(b *testing.B) {
 		_ = data.Content // Use the data to prevent optimization
 	}
 }
+
+// largePageHTML builds a ~200KB article-style page for retention benchmarks
+func largePageHTML() string {
+	var sb strings.Builder
+	sb.WriteString(`<!DOCTYPE html><html><head><title>Large Page</title></head><body><main>`)
+	for i := 0; i < 1000; i++ {
+		fmt.Fprintf(&sb, `<article class="item"><h2>Item %d</h2><p>Paragraph with <a href="/item/%d">a link</a> and some descriptive text content.</p><img src="/img/%d.jpg"></article>`, i, i, i)
+	}
+	sb.WriteString(`</main></body></html>`)
+	return sb.String()
+}
+
+// BenchmarkStaticScraperLargePage measures a large page with and without HTML retention
+func BenchmarkStaticScraperLargePage(b *testing.B) {
+	page := largePageHTML()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(page))
+	}))
+	defer ts.Close()
+
+	scraper := static.New(cache.NewMemoryCache(1024*1024), nil, &http.Client{}, 30*time.Second, "Bench/1.0")
+
+	for _, tc := range []struct {
+		name   string
+		noHTML bool
+	}{{"RetainHTML", false}, {"NoHTML", true}} {
+		b.Run(tc.name, func(b *testing.B) {
+			opts := models.RequestOptions{URL: ts.URL, Selector: "body", NoHTML: tc.noHTML}
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := scraper.Fetch(opts); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,229 @@
+// internal/engine/prefetch/prefetch.go
+//
+// Package prefetch warms the page cache with the links of each fetched
+// page, in the background, so that fetching one of them next is served
+// from memory.
+package prefetch
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/law-makers/crawl/internal/cache"
+	"github.com/law-makers/crawl/internal/engine"
+	"github.com/law-makers/crawl/pkg/models"
+	"github.com/rs/zerolog/log"
+)
+
+// ScopeSameDomain follows links to the page's own host, with or without "www."
+const ScopeSameDomain = "same-domain"
+
+// DefaultLimit is the number of links warmed per page when the spec omits it
+const DefaultLimit = 10
+
+// DefaultConcurrency is the number of links fetched at once
+const DefaultConcurrency = 4
+
+// Spec says which links of a page to prefetch
+type Spec struct {
+	Scope string
+	Limit int
+}
+
+// ParseSpec parses "same-domain" or "same-domain:<n>"
+func ParseSpec(s string) (Spec, error) {
+	scope, n, hasLimit := strings.Cut(strings.TrimSpace(s), ":")
+	spec := Spec{Scope: strings.ToLower(scope), Limit: DefaultLimit}
+	if spec.Scope != ScopeSameDomain {
+		return Spec{}, fmt.Errorf("invalid prefetch scope %q (must be %s)", scope, ScopeSameDomain)
+	}
+	if hasLimit {
+		limit, err := strconv.Atoi(n)
+		if err != nil || limit <= 0 {
+			return Spec{}, fmt.Errorf("invalid prefetch limit %q (must be a positive number)", n)
+		}
+		spec.Limit = limit
+	}
+	return spec, nil
+}
+
+// Links returns the first spec.Limit links of page within the spec's scope,
+// in page order, without fragments, duplicates or the page itself
+func Links(page *models.PageData, spec Spec) []string {
+	base, err := url.Parse(page.URL)
+	if err != nil {
+		return nil
+	}
+	seen := map[string]bool{stripFragment(base): true}
+	var links []string
+	for _, raw := range page.Links {
+		if len(links) >= spec.Limit {
+			break
+		}
+		u, err := base.Parse(raw)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || !sameDomain(base, u) {
+			continue
+		}
+		link := stripFragment(u)
+		if seen[link] {
+			continue
+		}
+		seen[link] = true
+		links = append(links, link)
+	}
+	return links
+}
+
+func stripFragment(u *url.URL) string {
+	c := *u
+	c.Fragment = ""
+	c.RawFragment = ""
+	return c.String()
+}
+
+func sameDomain(a, b *url.URL) bool {
+	host := func(u *url.URL) string { return strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.") }
+	return host(a) == host(b)
+}
+
+// Prefetcher wraps a scraper. Pages it fetches have their links warmed in
+// the cache, and requests for a warmed page are answered from the cache.
+// Prefetched pages are fetched with the same options as the page linking
+// to them, through the wrapped scraper and so its rate limits.
+type Prefetcher struct {
+	next  engine.Scraper
+	cache cache.Cache
+	spec  Spec
+	ttl   time.Duration
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	sem    chan struct{}
+	wg     sync.WaitGroup
+
+	mu      sync.Mutex
+	pending map[string]chan struct{}
+	warmed  int
+	served  int
+}
+
+// New wraps next, keeping prefetched pages in c for ttl
+func New(next engine.Scraper, c cache.Cache, spec Spec, ttl time.Duration) *Prefetcher {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Prefetcher{
+		next:    next,
+		cache:   c,
+		spec:    spec,
+		ttl:     ttl,
+		ctx:     ctx,
+		cancel:  cancel,
+		sem:     make(chan struct{}, DefaultConcurrency),
+		pending: map[string]chan struct{}{},
+	}
+}
+
+// Name returns the name of the wrapped scraper
+func (p *Prefetcher) Name() string {
+	return p.next.Name()
+}
+
+// Fetch serves a warmed page from the cache, waiting for its prefetch if
+// one is running, and otherwise fetches it and starts warming its links
+func (p *Prefetcher) Fetch(opts models.RequestOptions) (*models.PageData, error) {
+	key := cache.CacheKeyFromURL(opts.URL, opts.Selector)
+	p.mu.Lock()
+	done := p.pending[key]
+	p.mu.Unlock()
+	if done != nil {
+		<-done
+	}
+	if data, ok := p.cache.Get(key); ok {
+		p.mu.Lock()
+		p.served++
+		p.mu.Unlock()
+		log.Debug().Str("url", opts.URL).Msg("Served prefetched page")
+		return data, nil
+	}
+
+	data, err := p.next.Fetch(opts)
+	if err == nil && data != nil {
+		p.warm(data, opts)
+	}
+	return data, err
+}
+
+// warm starts background fetches of the page's links that are not cached
+// or already being fetched. Prefetched pages don't warm their own links.
+func (p *Prefetcher) warm(page *models.PageData, opts models.RequestOptions) {
+	for _, link := range Links(page, p.spec) {
+		key := cache.CacheKeyFromURL(link, opts.Selector)
+		if _, ok := p.cache.Get(key); ok {
+			continue
+		}
+		p.mu.Lock()
+		if p.pending[key] != nil || p.ctx.Err() != nil {
+			p.mu.Unlock()
+			continue
+		}
+		done := make(chan struct{})
+		p.pending[key] = done
+		p.wg.Add(1)
+		p.mu.Unlock()
+
+		o := opts
+		o.URL = link
+		o.Trace = nil
+		o.DumpRaw = false
+		go p.prefetch(key, o, done)
+	}
+}
+
+func (p *Prefetcher) prefetch(key string, opts models.RequestOptions, done chan struct{}) {
+	defer p.wg.Done()
+	defer func() {
+		p.mu.Lock()
+		delete(p.pending, key)
+		p.mu.Unlock()
+		close(done)
+	}()
+
+	select {
+	case p.sem <- struct{}{}:
+		defer func() { <-p.sem }()
+	case <-p.ctx.Done():
+		return
+	}
+
+	data, err := p.next.Fetch(opts)
+	if err != nil || data == nil {
+		log.Debug().Err(err).Str("url", opts.URL).Msg("Prefetch failed")
+		return
+	}
+	if err := p.cache.Set(key, data, p.ttl); err != nil {
+		log.Debug().Err(err).Str("url", opts.URL).Msg("Failed to cache prefetched page")
+		return
+	}
+	p.mu.Lock()
+	p.warmed++
+	p.mu.Unlock()
+	log.Debug().Str("url", opts.URL).Msg("Prefetched page")
+}
+
+// Wait blocks until the prefetches started so far have finished
+func (p *Prefetcher) Wait() {
+	p.wg.Wait()
+}
+
+// Close drops prefetches that have not started. Those already fetching
+// finish in the background.
+func (p *Prefetcher) Close() {
+	p.cancel()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	log.Debug().Int("warmed", p.warmed).Int("served", p.served).Msg("Prefetcher closed")
+}
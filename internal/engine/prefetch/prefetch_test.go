@@ -0,0 +1,107 @@
+package prefetch
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/law-makers/crawl/internal/cache"
+	"github.com/law-makers/crawl/pkg/models"
+)
+
+// site answers every URL with a page linking to the same links
+type site struct {
+	mu    sync.Mutex
+	calls map[string]int
+	links []string
+}
+
+func (s *site) Name() string { return "site" }
+func (s *site) Fetch(opts models.RequestOptions) (*models.PageData, error) {
+	s.mu.Lock()
+	s.calls[opts.URL]++
+	s.mu.Unlock()
+	return &models.PageData{URL: opts.URL, Title: opts.URL, Links: s.links}, nil
+}
+
+func (s *site) count(url string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls[url]
+}
+
+func TestParseSpec(t *testing.T) {
+	if spec, err := ParseSpec("same-domain:3"); err != nil || spec.Limit != 3 || spec.Scope != ScopeSameDomain {
+		t.Errorf("Expected same-domain with 3 links, got %+v, %v", spec, err)
+	}
+	if spec, err := ParseSpec("same-domain"); err != nil || spec.Limit != DefaultLimit {
+		t.Errorf("Expected the default limit, got %+v, %v", spec, err)
+	}
+	for _, bad := range []string{"everywhere:3", "same-domain:0", "same-domain:x"} {
+		if _, err := ParseSpec(bad); err == nil {
+			t.Errorf("Expected an error for %q", bad)
+		}
+	}
+}
+
+func TestLinks(t *testing.T) {
+	page := &models.PageData{
+		URL: "https://example.com/a",
+		Links: []string{
+			"/a#top", // the page itself
+			"https://www.example.com/b",
+			"/c#section",
+			"/c",
+			"https://other.com/d",
+			"mailto:me@example.com",
+			"/e",
+			"/f",
+		},
+	}
+	got := Links(page, Spec{Scope: ScopeSameDomain, Limit: 3})
+	want := []string{"https://www.example.com/b", "https://example.com/c", "https://example.com/e"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Links() = %v, want %v", got, want)
+	}
+}
+
+func TestPrefetcherServesWarmedLinks(t *testing.T) {
+	s := &site{calls: map[string]int{}, links: []string{"/b", "/c", "/d"}}
+	c := cache.NewMemoryCache(0)
+	defer c.Close()
+	p := New(s, c, Spec{Scope: ScopeSameDomain, Limit: 2}, time.Minute)
+	defer p.Close()
+
+	if _, err := p.Fetch(models.RequestOptions{URL: "https://example.com/a"}); err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	p.Wait()
+	if s.count("https://example.com/b") != 1 || s.count("https://example.com/c") != 1 || s.count("https://example.com/d") != 0 {
+		t.Errorf("Expected the first 2 links prefetched, got %v", s.calls)
+	}
+
+	// The warmed page comes from the cache, and doesn't warm its own links
+	data, err := p.Fetch(models.RequestOptions{URL: "https://example.com/b"})
+	if err != nil || data.Title != "https://example.com/b" {
+		t.Fatalf("Expected the prefetched page, got %+v, %v", data, err)
+	}
+	p.Wait()
+	if s.count("https://example.com/b") != 1 || s.count("https://example.com/d") != 0 {
+		t.Errorf("Expected no further fetches, got %v", s.calls)
+	}
+}
+
+func TestPrefetcherClose(t *testing.T) {
+	s := &site{calls: map[string]int{}, links: []string{"/b"}}
+	c := cache.NewMemoryCache(0)
+	defer c.Close()
+	p := New(s, c, Spec{Scope: ScopeSameDomain, Limit: 1}, time.Minute)
+	p.Close()
+
+	p.Fetch(models.RequestOptions{URL: "https://example.com/a"})
+	p.Wait()
+	if n := s.count("https://example.com/b"); n != 0 {
+		t.Errorf("Expected no prefetch after Close, got %d", n)
+	}
+}
@@ -0,0 +1,128 @@
+// internal/engine/static/frames.go
+package static
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/law-makers/crawl/internal/engine/metadata"
+	"github.com/law-makers/crawl/pkg/models"
+)
+
+// maxFrameBytes caps how much of each iframe document is read
+const maxFrameBytes = 10 << 20
+
+// frameDoc is a fetched document whose iframes are still to be read
+type frameDoc struct {
+	doc         *goquery.Document
+	url         *url.URL
+	depth       int
+	crossOrigin bool
+}
+
+// fetchFrames fetches the iframes of the page in doc, breadth first down to
+// metadata.MaxFrameDepth, and returns them with their documents in HTML for
+// metadata.ExtractFrames. Frames are fetched under the page's connection
+// slot, one at a time.
+func (s *Scraper) fetchFrames(ctx context.Context, client *http.Client, doc *goquery.Document, pageURL *url.URL, opts models.RequestOptions) []models.Frame {
+	var frames []models.Frame
+	queue := []frameDoc{{doc: doc, url: pageURL}}
+	for len(queue) > 0 {
+		parent := queue[0]
+		queue = queue[1:]
+		base := baseURL(parent.doc, parent.url)
+		parent.doc.Find("iframe, frame").EachWithBreak(func(_ int, el *goquery.Selection) bool {
+			if opts.Frames.Max > 0 && len(frames) >= opts.Frames.Max {
+				return false
+			}
+			frame := models.Frame{
+				Name:   firstAttr(el, "name", "id"),
+				Parent: parent.url.String(),
+				Depth:  parent.depth + 1,
+			}
+			frameURL := parent.url
+			if srcdoc, ok := el.Attr("srcdoc"); ok {
+				// Inline frames share their parent's origin
+				frame.URL, frame.HTML, frame.CrossOrigin = "about:srcdoc", srcdoc, parent.crossOrigin
+			} else {
+				src := strings.TrimSpace(el.AttrOr("src", ""))
+				u, err := base.Parse(src)
+				if src == "" || err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+					return true
+				}
+				frame.URL = u.String()
+				frame.CrossOrigin = u.Scheme != pageURL.Scheme || u.Host != pageURL.Host
+				if frame.CrossOrigin && !opts.Frames.CrossOrigin {
+					frame.Error = "cross-origin frame not read"
+				} else if frame.HTML, frameURL, err = s.fetchFrame(ctx, client, u, parent.url.String(), opts); err != nil {
+					frame.Error = err.Error()
+				}
+			}
+			frames = append(frames, frame)
+
+			if frame.HTML != "" && frame.Depth < metadata.MaxFrameDepth {
+				if fd, err := goquery.NewDocumentFromReader(strings.NewReader(frame.HTML)); err == nil {
+					queue = append(queue, frameDoc{doc: fd, url: frameURL, depth: frame.Depth, crossOrigin: frame.CrossOrigin})
+				}
+			}
+			return true
+		})
+	}
+	return frames
+}
+
+// fetchFrame GETs an iframe document, returning its HTML and final URL
+func (s *Scraper) fetchFrame(ctx context.Context, client *http.Client, u *url.URL, referer string, opts models.RequestOptions) (string, *url.URL, error) {
+	if s.limiter != nil {
+		if err := s.limiter.Wait(ctx, u.String()); err != nil {
+			return "", nil, fmt.Errorf("rate limit wait failed: %w", err)
+		}
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	s.setHeaders(req, opts)
+	req.Header.Del("Content-Type")
+	req.Header.Set("Referer", referer)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to fetch frame: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		return "", nil, fmt.Errorf("frame returned HTTP %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxFrameBytes))
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to read frame: %w", err)
+	}
+	return string(body), resp.Request.URL, nil
+}
+
+// baseURL returns the URL relative links in doc resolve against: its
+// <base href> if it has one, else docURL
+func baseURL(doc *goquery.Document, docURL *url.URL) *url.URL {
+	if href, ok := doc.Find("base[href]").First().Attr("href"); ok {
+		if u, err := docURL.Parse(strings.TrimSpace(href)); err == nil {
+			return u
+		}
+	}
+	return docURL
+}
+
+// firstAttr returns the first non-empty of el's attributes names
+func firstAttr(el *goquery.Selection, names ...string) string {
+	for _, name := range names {
+		if v := strings.TrimSpace(el.AttrOr(name, "")); v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -2,30 +2,49 @@
 package static
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"net/http/httptrace"
+	"net/url"
 	"time"
 
 	"github.com/PuerkitoBio/goquery"
 	"github.com/law-makers/crawl/internal/cache"
+	"github.com/law-makers/crawl/internal/cookies"
 	"github.com/law-makers/crawl/internal/engine/metadata"
+	"github.com/law-makers/crawl/internal/logging"
+	"github.com/law-makers/crawl/internal/proxy"
 	"github.com/law-makers/crawl/internal/ratelimit"
+	"github.com/law-makers/crawl/internal/replay"
+	"github.com/law-makers/crawl/internal/reqctx"
+	"github.com/law-makers/crawl/internal/trace"
 	"github.com/law-makers/crawl/pkg/models"
-	"github.com/rs/zerolog/log"
 )
 
 // Scraper implements the Scraper interface for static HTML pages
 // It uses raw HTTP requests and goquery for parsing - extremely fast
 type Scraper struct {
-	cache     cache.Cache
-	limiter   ratelimit.RateLimiter
-	client    *http.Client
-	timeout   time.Duration
-	userAgent string
+	cache       cache.Cache
+	limiter     ratelimit.RateLimiter
+	concurrency *ratelimit.DomainConcurrency
+	client      *http.Client
+	timeout     time.Duration
+	userAgent   string
 }
 
-// New creates a new StaticScraper with dependency injection
+// defaultUserAgent is sent when the scraper is created without one
+const defaultUserAgent = "Crawl/1.0 (https://github.com/law-makers/crawl)"
+
+// New creates a new StaticScraper with dependency injection. ua is the
+// User-Agent sent unless a request sets its own.
 func New(c cache.Cache, lim ratelimit.RateLimiter, client *http.Client, timeout time.Duration, ua string) *Scraper {
+	if ua == "" {
+		ua = defaultUserAgent
+	}
 	return &Scraper{
 		cache:     c,
 		limiter:   lim,
@@ -35,6 +54,11 @@ func New(c cache.Cache, lim ratelimit.RateLimiter, client *http.Client, timeout
 	}
 }
 
+// SetConcurrency sets the per-domain cap on simultaneous requests
+func (s *Scraper) SetConcurrency(dc *ratelimit.DomainConcurrency) {
+	s.concurrency = dc
+}
+
 // Name returns the name of this scraper
 func (s *Scraper) Name() string {
 	return "StaticScraper"
@@ -54,42 +78,105 @@ func (s *Scraper) Fetch(opts models.RequestOptions) (*models.PageData, error) {
 func (s *Scraper) fetch(opts models.RequestOptions) (*models.PageData, *goquery.Document, error) {
 	start := time.Now()
 
-	log.Debug().
-		Str("url", opts.URL).
-		Str("scraper", s.Name()).
-		Msg("Starting fetch")
+	logger := logging.ForRequest(opts, "static")
+	logger.Debug().Msg("Starting fetch")
+
+	// Bound the whole request, including reading the body, with a per-request
+	// context rather than mutating the shared client's Timeout
+	ctx, cancel := context.WithTimeout(context.Background(), s.requestTimeout(opts))
+	defer cancel()
+	ctx = reqctx.WithIDs(ctx, opts.JobID, opts.RequestID)
+	// Phase timings are always collected; the caller's trace, if any, sees the same hooks
+	timings := models.NewTrace()
+	ctx = httptrace.WithClientTrace(ctx, trace.ClientTrace(timings))
+	if opts.Trace != nil {
+		ctx = httptrace.WithClientTrace(ctx, trace.ClientTrace(opts.Trace))
+	}
 
 	// Create request
-	req, err := http.NewRequest("GET", opts.URL, nil)
+	method := opts.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+	var body io.Reader
+	if opts.Body != nil {
+		body = bytes.NewReader(opts.Body)
+	}
+	// A per-request proxy is picked up by the base transport's Proxy func
+	// (see proxy.Func), however many wrappers sit above it
+	if opts.Proxy != "" {
+		proxyURL, err := url.Parse(opts.Proxy)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid proxy URL %q: %w", opts.Proxy, err)
+		}
+		ctx = proxy.WithURL(ctx, proxyURL)
+	}
+	// Only the page's own response goes to --raw-output; iframes below
+	// are fetched with the unmarked ctx
+	reqCtx := ctx
+	if opts.DumpRaw {
+		reqCtx = replay.WithRawDump(ctx)
+	}
+	req, err := http.NewRequestWithContext(reqCtx, method, opts.URL, body)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	// Set default headers
-	req.Header.Set("User-Agent", "Crawl/1.0 (https://github.com/law-makers/crawl)")
-	req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
-	req.Header.Set("Accept-Language", "en-US,en;q=0.9")
+	s.setHeaders(req, opts)
 
-	// Add custom headers
-	for key, value := range opts.Headers {
-		req.Header.Set(key, value)
+	// Respect the per-domain rate limit, then wait for a free per-domain
+	// slot; the slot is held until the response is parsed
+	queued := time.Now()
+	if s.limiter != nil {
+		if err := s.limiter.Wait(ctx, opts.URL); err != nil {
+			return nil, nil, fmt.Errorf("rate limit wait failed: %w", err)
+		}
 	}
-
-	// Set timeout if specified
-	if opts.Timeout > 0 {
-		s.client.Timeout = opts.Timeout
+	release, err := s.concurrency.Acquire(ctx, opts.URL)
+	if err != nil {
+		return nil, nil, fmt.Errorf("timed out waiting for a connection slot: %w", err)
+	}
+	defer release()
+	dequeued := time.Now()
+	timings.Span("queue", queued, dequeued, "")
+	if dequeued.Sub(queued) > time.Millisecond {
+		opts.Trace.Span("queue", queued, dequeued, "rate limit and connection slot")
 	}
 
-	// Make request
-	resp, err := s.client.Do(req)
+	// Make request. The context deadline replaces the client-wide timeout so
+	// RequestOptions.Timeout can be longer than the default.
+	client := *s.clientFor(opts.Proxy)
+	client.Timeout = 0
+	var setCookies []models.Cookie
+	if opts.CaptureCookies {
+		// Redirect responses set cookies too; only CheckRedirect sees them
+		checkRedirect := client.CheckRedirect
+		client.CheckRedirect = func(next *http.Request, via []*http.Request) error {
+			if r := next.Response; r != nil {
+				setCookies = append(setCookies, cookies.FromHeader(r.Header, r.Request.URL.String(), opts.URL)...)
+			}
+			if checkRedirect != nil {
+				return checkRedirect(next, via)
+			}
+			if len(via) >= 10 {
+				return errors.New("stopped after 10 redirects")
+			}
+			return nil
+		}
+	}
+	resp, err := client.Do(req)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to fetch URL: %w", err)
 	}
 	defer resp.Body.Close()
+	if opts.CaptureCookies {
+		setCookies = append(setCookies, cookies.FromHeader(resp.Header, resp.Request.URL.String(), opts.URL)...)
+	}
+	bodyStart := time.Now()
 
 	// If caller requested a wait after load, sleep briefly after receiving response
 	if opts.WaitSeconds > 0 {
-		log.Debug().Int("wait_seconds", opts.WaitSeconds).Msg("Waiting after response before parsing (static)")
+		logger.Debug().Int("wait_seconds", opts.WaitSeconds).Msg("Waiting after response before parsing (static)")
 		time.Sleep(time.Duration(opts.WaitSeconds) * time.Second)
 	}
 
@@ -98,6 +185,9 @@ func (s *Scraper) fetch(opts models.RequestOptions) (*models.PageData, *goquery.
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to parse HTML: %w", err)
 	}
+	bodyEnd := time.Now()
+	timings.Span("body", bodyStart, bodyEnd, "")
+	opts.Trace.Span("body", bodyStart, bodyEnd, "read and parse")
 
 	responseTime := time.Since(start).Milliseconds()
 
@@ -107,8 +197,10 @@ func (s *Scraper) fetch(opts models.RequestOptions) (*models.PageData, *goquery.
 		StatusCode:   resp.StatusCode,
 		FetchedAt:    time.Now(),
 		ResponseTime: responseTime,
+		Timings:      trace.Timings(timings.Spans()),
 		Headers:      make(map[string]string),
 		Metadata:     make(map[string]string),
+		Cookies:      setCookies,
 	}
 
 	// Extract headers
@@ -118,20 +210,39 @@ func (s *Scraper) fetch(opts models.RequestOptions) (*models.PageData, *goquery.
 		}
 	}
 
-	// Extract content based on selector
-	pageData.Content, pageData.HTML = metadata.ExtractContent(doc, opts.Selector)
+	// Extract content based on selector, or the first candidate on the page
+	if sel := metadata.FirstMatch(doc, opts.SelectorCandidates); sel != "" {
+		logger.Debug().Str("selector", sel).Msg("Using selector candidate")
+		opts.Selector = sel
+	}
+	if opts.NoHTML {
+		pageData.Content = metadata.ExtractText(doc, opts.Selector)
+	} else {
+		pageData.Content, pageData.HTML = metadata.ExtractContent(doc, opts.Selector)
+	}
 
 	if opts.Selector != "" && opts.Selector != "body" && pageData.Content == "" {
-		log.Warn().
+		logger.Warn().
 			Str("selector", opts.Selector).
 			Msg("Selector not found in document")
 	}
 
+	pageData.Structured = metadata.ExtractFields(doc, opts.Selector, opts.Fields)
+	if opts.AllMatches {
+		pageData.Data = metadata.ExtractMatches(doc, opts.Selector, !opts.NoHTML)
+	}
+	pageData.Assertions = metadata.CheckAssertions(doc, opts.Assertions)
+	if opts.Frames != nil {
+		pageData.Frames = s.fetchFrames(ctx, &client, doc, resp.Request.URL, opts)
+		metadata.ExtractFrames(pageData, doc, opts)
+	}
+
 	// Extract metadata, links, images, scripts
 	metadata.Extract(doc, pageData)
+	metadata.Hash(pageData)
+	metadata.NoAI(pageData)
 
-	log.Debug().
-		Str("url", opts.URL).
+	logger.Debug().
 		Int("status", resp.StatusCode).
 		Int64("response_time_ms", responseTime).
 		Int("links", len(pageData.Links)).
@@ -140,3 +251,45 @@ func (s *Scraper) fetch(opts models.RequestOptions) (*models.PageData, *goquery.
 
 	return pageData, doc, nil
 }
+
+// setHeaders sets the default request headers, the configured user agent
+// among them, then the caller's
+func (s *Scraper) setHeaders(req *http.Request, opts models.RequestOptions) {
+	req.Header.Set("User-Agent", s.userAgent)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
+	req.Header.Set("Accept-Language", "en-US,en;q=0.9")
+	for key, value := range opts.Headers {
+		req.Header.Set(key, value)
+	}
+}
+
+// defaultTransport stands in for http.DefaultTransport when the client has
+// no transport of its own, so per-request proxies still apply
+var defaultTransport = func() *http.Transport {
+	tr := http.DefaultTransport.(*http.Transport).Clone()
+	tr.Proxy = proxy.Func(http.ProxyFromEnvironment)
+	return tr
+}()
+
+// clientFor returns the shared client. A client without a transport gets
+// one that honours per-request proxies when proxyURL is set; any other
+// transport must build its Proxy with proxy.Func, as the application's does.
+func (s *Scraper) clientFor(proxyURL string) *http.Client {
+	if proxyURL == "" || s.client.Transport != nil {
+		return s.client
+	}
+	client := *s.client
+	client.Transport = defaultTransport
+	return &client
+}
+
+// requestTimeout returns the per-request timeout, falling back to the scraper default
+func (s *Scraper) requestTimeout(opts models.RequestOptions) time.Duration {
+	if opts.Timeout > 0 {
+		return opts.Timeout
+	}
+	if s.timeout > 0 {
+		return s.timeout
+	}
+	return 30 * time.Second
+}
@@ -2,12 +2,15 @@
 package static
 
 import (
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/law-makers/crawl/internal/cache"
+	"github.com/law-makers/crawl/internal/proxy"
 	"github.com/law-makers/crawl/internal/ratelimit"
 	"github.com/law-makers/crawl/pkg/models"
 )
@@ -185,3 +188,498 @@ func TestStaticScraper_Fetch_CustomHeaders(t *testing.T) {
 		t.Errorf("Expected status code 200, got %d", pageData.StatusCode)
 	}
 }
+
+func TestStaticScraper_Fetch_PerRequestTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+		w.Write([]byte("<html><body>slow</body></html>"))
+	}))
+	defer server.Close()
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	scraper := New(cache.NewMemoryCache(1024*1024), nil, client, 30*time.Second, "TestScraper/1.0")
+
+	// Concurrent requests with different timeouts must not interfere
+	errs := make(chan error, 2)
+	go func() {
+		_, err := scraper.Fetch(models.RequestOptions{URL: server.URL, Timeout: 20 * time.Millisecond})
+		errs <- err
+	}()
+	go func() {
+		_, err := scraper.Fetch(models.RequestOptions{URL: server.URL, Timeout: 5 * time.Second})
+		errs <- err
+	}()
+
+	failed := 0
+	for i := 0; i < 2; i++ {
+		if err := <-errs; err != nil {
+			failed++
+		}
+	}
+	if failed != 1 {
+		t.Errorf("Expected exactly the short-timeout request to fail, got %d failures", failed)
+	}
+	if client.Timeout != 30*time.Second {
+		t.Errorf("Shared client timeout was modified: %v", client.Timeout)
+	}
+}
+
+func TestStaticScraper_Fetch_CookieIsolation(t *testing.T) {
+	// The server echoes the request cookie and tries to set its own
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "server", Value: "leaked"})
+		w.Write([]byte("<html><body><p id=\"c\">" + r.Header.Get("Cookie") + "</p></body></html>"))
+	}))
+	defer server.Close()
+
+	scraper := New(cache.NewMemoryCache(1024*1024), nil, &http.Client{Timeout: 30 * time.Second}, 30*time.Second, "TestScraper/1.0")
+
+	const n = 20
+	type result struct {
+		want, got string
+	}
+	results := make(chan result, n)
+	for i := 0; i < n; i++ {
+		go func(i int) {
+			cookie := fmt.Sprintf("session=user%d", i)
+			data, err := scraper.Fetch(models.RequestOptions{
+				URL:      server.URL,
+				Selector: "#c",
+				Headers:  map[string]string{"Cookie": cookie},
+				Timeout:  5 * time.Second,
+			})
+			if err != nil {
+				results <- result{want: cookie, got: err.Error()}
+				return
+			}
+			results <- result{want: cookie, got: data.Content}
+		}(i)
+	}
+
+	for i := 0; i < n; i++ {
+		r := <-results
+		if r.got != r.want {
+			t.Errorf("Cookie leaked between requests: sent %q, server saw %q", r.want, r.got)
+		}
+	}
+}
+
+func TestStaticScraper_Fetch_NoHTML(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<html><head><title>Big</title></head><body><div class="price-tag">$99.99</div><a href="/next">Next</a></body></html>`))
+	}))
+	defer server.Close()
+
+	scraper := NewTestStaticScraper()
+	pageData, err := scraper.Fetch(models.RequestOptions{
+		URL:      server.URL,
+		Selector: ".price-tag",
+		Timeout:  5 * time.Second,
+		NoHTML:   true,
+	})
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+
+	if pageData.HTML != "" {
+		t.Errorf("Expected no HTML to be retained, got %q", pageData.HTML)
+	}
+	if pageData.Content != "$99.99" {
+		t.Errorf("Expected content '$99.99', got '%s'", pageData.Content)
+	}
+	if pageData.Title != "Big" || len(pageData.Links) != 1 {
+		t.Errorf("Expected title and links to still be extracted, got %q and %v", pageData.Title, pageData.Links)
+	}
+}
+
+func TestStaticScraper_Fetch_Fields(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<html><body>
+<div class="item"><span class="name">Tea</span><span class="price">$3</span><a href="/tea">more</a></div>
+<div class="item"><span class="name">Cake</span><span class="price">$5</span><a href="/cake">more</a></div>
+</body></html>`))
+	}))
+	defer server.Close()
+
+	scraper := NewTestStaticScraper()
+	pageData, err := scraper.Fetch(models.RequestOptions{
+		URL:      server.URL,
+		Selector: ".item",
+		Fields: []models.Field{
+			{Name: "price", Selector: ".price"},
+			{Name: "name", Selector: ".name"},
+			{Name: "link", Selector: "a@href"},
+		},
+		Timeout: 5 * time.Second,
+	})
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+
+	if len(pageData.Structured) != 2 {
+		t.Fatalf("Expected 2 structured rows, got %v", pageData.Structured)
+	}
+	row := pageData.Structured[1]
+	if row["name"] != "Cake" || row["price"] != "$5" || row["link"] != "/cake" {
+		t.Errorf("Unexpected row: %v", row)
+	}
+}
+
+func TestStaticScraper_Fetch_AllMatches(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<html><body><ul id="menu"><li>Home</li><li class="x">About</li></ul><p class="x">Note</p></body></html>`))
+	}))
+	defer server.Close()
+
+	scraper := NewTestStaticScraper()
+	pageData, err := scraper.Fetch(models.RequestOptions{
+		URL:        server.URL,
+		Selector:   ".x",
+		AllMatches: true,
+		Timeout:    5 * time.Second,
+	})
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+
+	if len(pageData.Data) != 2 {
+		t.Fatalf("Expected 2 matches, got %+v", pageData.Data)
+	}
+	first, second := pageData.Data[0], pageData.Data[1]
+	if first.Text != "About" || first.Selector != "#menu > li:nth-child(2)" || first.HTML != `<li class="x">About</li>` {
+		t.Errorf("Unexpected first match: %+v", first)
+	}
+	if second.Index != 1 || second.Selector != "html > body > p:nth-child(2)" {
+		t.Errorf("Unexpected second match: %+v", second)
+	}
+}
+
+func TestStaticScraper_Fetch_Assertions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<html><body><div class="result">a</div><div class="result">b</div></body></html>`))
+	}))
+	defer server.Close()
+
+	scraper := NewTestStaticScraper()
+	pageData, err := scraper.Fetch(models.RequestOptions{
+		URL: server.URL,
+		Assertions: []models.Assertion{
+			{Selector: ".result", Min: 1, Max: -1},
+			{Selector: ".captcha", Min: 0, Max: 0},
+			{Selector: ".result", Min: 3, Max: -1},
+		},
+		Timeout: 5 * time.Second,
+	})
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+
+	if len(pageData.Assertions) != 3 {
+		t.Fatalf("Expected 3 assertion results, got %+v", pageData.Assertions)
+	}
+	for i, want := range []bool{true, true, false} {
+		if got := pageData.Assertions[i]; got.Passed != want {
+			t.Errorf("Assertion %d: expected passed=%v, got %+v", i, want, got)
+		}
+	}
+	if pageData.Assertions[2].Count != 2 {
+		t.Errorf("Expected a count of 2, got %d", pageData.Assertions[2].Count)
+	}
+}
+
+func TestStaticScraper_Fetch_SelectorCandidates(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<html><body><nav>Menu</nav><article></article><main><p>Docs</p></main></body></html>`))
+	}))
+	defer server.Close()
+
+	scraper := NewTestStaticScraper()
+	pageData, err := scraper.Fetch(models.RequestOptions{
+		URL:      server.URL,
+		Selector: "body",
+		// article is on the page but empty, so main is used
+		SelectorCandidates: []string{".missing", "article", "main"},
+		Timeout:            5 * time.Second,
+	})
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if pageData.Content != "Docs" {
+		t.Errorf("Expected the first candidate with text, got %q", pageData.Content)
+	}
+}
+
+func TestStaticScraper_Fetch_Timings(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(10 * time.Millisecond)
+		w.Write([]byte(`<html><body>ok</body></html>`))
+	}))
+	defer server.Close()
+
+	scraper := NewTestStaticScraper()
+	pageData, err := scraper.Fetch(models.RequestOptions{URL: server.URL, Timeout: 5 * time.Second})
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+
+	if pageData.Timings == nil {
+		t.Fatal("Expected timings to be populated")
+	}
+	if pageData.Timings.TTFB < 10 {
+		t.Errorf("Expected ttfb to include the server delay, got %.2fms", pageData.Timings.TTFB)
+	}
+	if pageData.Timings.Connect <= 0 {
+		t.Errorf("Expected a connect time for a new connection, got %.2fms", pageData.Timings.Connect)
+	}
+	if pageData.Timings.TLS != 0 {
+		t.Errorf("Expected no TLS time over plain HTTP, got %.2fms", pageData.Timings.TLS)
+	}
+}
+
+func TestStaticScraper_Fetch_WaitedOnRateLimit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<html><body>ok</body></html>`))
+	}))
+	defer server.Close()
+
+	// One request every 100ms: the second waits for the first's slot
+	scraper := New(nil, ratelimit.NewDomainLimiter(10, 1), &http.Client{}, 5*time.Second, "TestScraper/1.0")
+	first, err := scraper.Fetch(models.RequestOptions{URL: server.URL})
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	second, err := scraper.Fetch(models.RequestOptions{URL: server.URL})
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+
+	if first.Timings.Waited > 50 {
+		t.Errorf("Expected the first request not to wait, got %.2fms", first.Timings.Waited)
+	}
+	if second.Timings.Waited < 50 {
+		t.Errorf("Expected the second request to wait on the rate limit, got %.2fms", second.Timings.Waited)
+	}
+}
+
+func TestStaticScraper_Fetch_Hashes(t *testing.T) {
+	bodies := map[string]string{
+		"/a": `<html><body><p>Price: $10</p></body></html>`,
+		"/b": `<html><body><p>Price:
+			$10</p></body></html>`,
+		"/c": `<html><body><p>Price: $12</p></body></html>`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(bodies[r.URL.Path]))
+	}))
+	defer server.Close()
+
+	scraper := NewTestStaticScraper()
+	pages := map[string]*models.PageData{}
+	for path := range bodies {
+		page, err := scraper.Fetch(models.RequestOptions{URL: server.URL + path, Selector: "p", Timeout: 5 * time.Second})
+		if err != nil {
+			t.Fatalf("Fetch failed: %v", err)
+		}
+		pages[path] = page
+	}
+
+	if len(pages["/a"].ContentHash) != 64 || len(pages["/a"].HTMLHash) != 64 {
+		t.Fatalf("Expected SHA-256 hex hashes, got %q and %q", pages["/a"].ContentHash, pages["/a"].HTMLHash)
+	}
+	if pages["/a"].ContentHash != pages["/b"].ContentHash {
+		t.Error("Expected content differing only in whitespace to hash the same")
+	}
+	if pages["/a"].HTMLHash == pages["/b"].HTMLHash {
+		t.Error("Expected different HTML to hash differently")
+	}
+	if pages["/a"].ContentHash == pages["/c"].ContentHash {
+		t.Error("Expected changed content to hash differently")
+	}
+
+	noHTML, err := scraper.Fetch(models.RequestOptions{URL: server.URL + "/a", Selector: "p", Timeout: 5 * time.Second, NoHTML: true})
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if noHTML.HTMLHash != "" || noHTML.ContentHash != pages["/a"].ContentHash {
+		t.Errorf("Expected only the content hash without HTML, got %q and %q", noHTML.ContentHash, noHTML.HTMLHash)
+	}
+}
+
+func TestStaticScraper_Fetch_NoAI(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/reserved" {
+			w.Header().Set("X-Robots-Tag", "noindex, otherbot: noai")
+			w.Write([]byte(`<html><head><meta name="robots" content="noimageai"><meta name="tdm-reservation" content="1"></head><body>Hi</body></html>`))
+			return
+		}
+		w.Write([]byte(`<html><head><meta name="robots" content="noindex"></head><body>Hi</body></html>`))
+	}))
+	defer server.Close()
+
+	scraper := NewTestStaticScraper()
+	page, err := scraper.Fetch(models.RequestOptions{URL: server.URL + "/reserved", Timeout: 5 * time.Second})
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	want := []string{"X-Robots-Tag: noai", "meta robots: noimageai", "meta tdm-reservation: 1"}
+	if strings.Join(page.NoAI, "|") != strings.Join(want, "|") {
+		t.Errorf("NoAI = %q, want %q", page.NoAI, want)
+	}
+
+	page, err = scraper.Fetch(models.RequestOptions{URL: server.URL + "/open", Timeout: 5 * time.Second})
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if len(page.NoAI) != 0 {
+		t.Errorf("Expected no reservations, got %q", page.NoAI)
+	}
+}
+
+func TestStaticScraper_Fetch_CaptureCookies(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/login" {
+			http.SetCookie(w, &http.Cookie{Name: "redirected", Value: "1"})
+			http.Redirect(w, r, "/home", http.StatusFound)
+			return
+		}
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "abc", HttpOnly: true})
+		w.Write([]byte(`<html><body>Home</body></html>`))
+	}))
+	defer server.Close()
+
+	scraper := NewTestStaticScraper()
+	page, err := scraper.Fetch(models.RequestOptions{URL: server.URL + "/login", Timeout: 5 * time.Second, CaptureCookies: true})
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if len(page.Cookies) != 2 || page.Cookies[0].Name != "redirected" || page.Cookies[1].Name != "session" {
+		t.Fatalf("Expected the redirect's and the page's cookies, got %+v", page.Cookies)
+	}
+	if page.Cookies[0].SetBy != server.URL+"/login" || !page.Cookies[1].HTTPOnly {
+		t.Errorf("Unexpected cookies: %+v", page.Cookies)
+	}
+
+	page, _ = scraper.Fetch(models.RequestOptions{URL: server.URL + "/home", Timeout: 5 * time.Second})
+	if len(page.Cookies) != 0 {
+		t.Errorf("Expected no cookies without CaptureCookies, got %+v", page.Cookies)
+	}
+}
+
+func TestStaticScraper_Fetch_Post(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "POST only", http.StatusMethodNotAllowed)
+			return
+		}
+		r.ParseForm()
+		w.Write([]byte("<html><body>Results for " + r.PostForm.Get("q") + "</body></html>"))
+	}))
+	defer server.Close()
+
+	scraper := NewTestStaticScraper()
+	page, err := scraper.Fetch(models.RequestOptions{
+		URL:     server.URL,
+		Method:  http.MethodPost,
+		Body:    []byte("q=crawl"),
+		Headers: map[string]string{"Content-Type": "application/x-www-form-urlencoded"},
+		Timeout: 5 * time.Second,
+	})
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if page.StatusCode != 200 || !strings.Contains(page.Content, "Results for crawl") {
+		t.Errorf("Expected the POST results, got %d %q", page.StatusCode, page.Content)
+	}
+}
+
+func TestStaticScraper_Fetch_Frames(t *testing.T) {
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<html><body><p class="notice">Elsewhere</p></body></html>`))
+	}))
+	defer other.Close()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/doc":
+			w.Write([]byte(`<html><head><title>Doc</title></head><body><p class="notice">Closed Friday</p><iframe srcdoc="<p class='notice'>Nested</p>"></iframe></body></html>`))
+		default:
+			w.Write([]byte(`<html><body><h1>Portal</h1><iframe name="docs" src="/doc"></iframe><iframe src="` + other.URL + `"></iframe></body></html>`))
+		}
+	}))
+	defer server.Close()
+
+	scraper := NewTestStaticScraper()
+	opts := models.RequestOptions{
+		URL:        server.URL,
+		Selector:   ".notice",
+		Fields:     []models.Field{{Name: "text", Selector: ""}},
+		AllMatches: true,
+		Timeout:    5 * time.Second,
+		Frames:     &models.FrameOptions{},
+	}
+	page, err := scraper.Fetch(opts)
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if len(page.Frames) != 3 {
+		t.Fatalf("Expected 3 frames, got %+v", page.Frames)
+	}
+	if f := page.Frames[0]; f.Name != "docs" || f.Title != "Doc" || f.Content != "Closed Friday" || f.Depth != 1 {
+		t.Errorf("Unexpected frame: %+v", f)
+	}
+	if f := page.Frames[1]; !f.CrossOrigin || f.Error == "" {
+		t.Errorf("Expected the cross-origin frame to be left unread, got %+v", f)
+	}
+	if f := page.Frames[2]; f.URL != "about:srcdoc" || f.Depth != 2 || f.Parent != server.URL+"/doc" {
+		t.Errorf("Unexpected nested frame: %+v", f)
+	}
+	if page.Content != "Closed Friday\n\nNested" {
+		t.Errorf("Expected the frames' content in place of the page's, got %q", page.Content)
+	}
+	if len(page.Data) != 2 || page.Data[0].Frame != server.URL+"/doc" || page.Data[1].Frame != "about:srcdoc" {
+		t.Errorf("Expected matches tagged with their frame, got %+v", page.Data)
+	}
+	if len(page.Structured) != 2 || page.Structured[0]["_frame"] != server.URL+"/doc" {
+		t.Errorf("Expected rows tagged with their frame, got %+v", page.Structured)
+	}
+
+	opts.Frames.CrossOrigin = true
+	page, _ = scraper.Fetch(opts)
+	if f := page.Frames[1]; f.Error != "" || f.Content != "Elsewhere" {
+		t.Errorf("Expected the cross-origin frame to be read, got %+v", f)
+	}
+}
+
+// wrapTransport stands in for the recorder, request log and other wrappers
+// the application stacks above the base transport
+type wrapTransport struct{ next http.RoundTripper }
+
+func (w wrapTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return w.next.RoundTrip(req)
+}
+
+func TestStaticScraper_ProxyThroughWrappedTransport(t *testing.T) {
+	var proxied string
+	proxyServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = r.URL.String()
+		w.Write([]byte(`<html><body><h1>via proxy</h1></body></html>`))
+	}))
+	defer proxyServer.Close()
+
+	client := &http.Client{Transport: wrapTransport{&http.Transport{Proxy: proxy.Func(nil)}}}
+	scraper := New(nil, nil, client, 5*time.Second, "TestScraper/1.0")
+
+	data, err := scraper.Fetch(models.RequestOptions{
+		URL:      "http://site.invalid/page",
+		Selector: "h1",
+		Proxy:    proxyServer.URL,
+	})
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if proxied != "http://site.invalid/page" {
+		t.Errorf("Expected the request to go through the proxy, got %q", proxied)
+	}
+	if !strings.Contains(data.Content, "via proxy") {
+		t.Errorf("Expected the proxied page, got %q", data.Content)
+	}
+}
@@ -0,0 +1,126 @@
+// internal/engine/status/detect.go
+package status
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/law-makers/crawl/internal/engine"
+	"github.com/law-makers/crawl/internal/logging"
+	"github.com/law-makers/crawl/pkg/models"
+)
+
+// minBodyText is the least text a whole page is expected to have; less
+// usually means an error stub or a page that failed to render
+const minBodyText = 20
+
+// blockMarkers are phrases from the block pages of common WAFs and bot
+// filters, matched case-insensitively against the title and start of the content
+var blockMarkers = []string{
+	"attention required! | cloudflare",
+	"just a moment...",
+	"checking your browser before accessing",
+	"enable javascript and cookies to continue",
+	"access denied",
+	"request unsuccessful. incapsula incident",
+	"the requested url was rejected",
+	"pardon our interruption",
+	"are you a robot",
+	"verify you are a human",
+	"please complete the security check",
+	"unusual traffic from your computer network",
+	"captcha",
+}
+
+// Detect returns why a page served with a 2xx status looks like an error
+// or block page, or "" when it looks fine. selector is the one the page was
+// fetched with; the tiny-body check only applies to whole pages.
+func Detect(data *models.PageData, selector string) string {
+	if data == nil || data.StatusCode < 200 || data.StatusCode > 299 {
+		return ""
+	}
+	if reason, ok := SoftNotFound(data); ok {
+		return "soft 404: " + reason
+	}
+	if marker := BlockMarker(data); marker != "" {
+		return fmt.Sprintf("block page: contains %q", marker)
+	}
+	if selector == "" || selector == "body" {
+		text := strings.TrimSpace(data.Content)
+		if len(text) < minBodyText && len(data.Links) == 0 && len(data.Images) == 0 {
+			return fmt.Sprintf("nearly empty page (%d characters of text)", len(text))
+		}
+	}
+	return ""
+}
+
+// BlockMarker returns the first block-page phrase in the page's title or the
+// start of its content, or ""
+func BlockMarker(data *models.PageData) string {
+	head := data.Content
+	if len(head) > 1000 {
+		head = head[:1000]
+	}
+	text := strings.ToLower(data.Title + "\n" + head)
+	for _, m := range blockMarkers {
+		if strings.Contains(text, m) {
+			return m
+		}
+	}
+	return ""
+}
+
+// Refetch fetches a suspected page again another way, e.g. with the
+// browser or through another proxy
+type Refetch struct {
+	Name  string
+	Fetch func(opts models.RequestOptions) (*models.PageData, error)
+}
+
+// Detector wraps another scraper, setting PageData.SuspectedError on pages
+// that look like error pages and trying its refetches, in order, until one
+// returns a page that doesn't
+type Detector struct {
+	next      engine.Scraper
+	refetches []Refetch
+}
+
+// NewDetector wraps next with error-page detection
+func NewDetector(next engine.Scraper, refetches ...Refetch) *Detector {
+	return &Detector{next: next, refetches: refetches}
+}
+
+// Name returns the name of the wrapped scraper
+func (d *Detector) Name() string {
+	return d.next.Name()
+}
+
+// Fetch retrieves the page and flags it if it looks like an error page.
+// A flagged page is still returned when no refetch does better.
+func (d *Detector) Fetch(opts models.RequestOptions) (*models.PageData, error) {
+	data, err := d.next.Fetch(opts)
+	if err != nil {
+		return nil, err
+	}
+	data.SuspectedError = Detect(data, opts.Selector)
+	if data.SuspectedError == "" {
+		return data, nil
+	}
+
+	logger := logging.ForRequest(opts, "")
+	for _, r := range d.refetches {
+		logger.Debug().Str("reason", data.SuspectedError).Str("via", r.Name).Msg("Suspected error page, fetching again")
+		again, err := r.Fetch(opts)
+		if err != nil || again == nil {
+			logger.Debug().Err(err).Str("via", r.Name).Msg("Refetch failed")
+			continue
+		}
+		again.SuspectedError = Detect(again, opts.Selector)
+		if again.SuspectedError == "" {
+			logger.Info().Str("via", r.Name).Msg("Suspected error page fetched successfully on retry")
+			return again, nil
+		}
+	}
+	logger.Warn().Str("reason", data.SuspectedError).Msg("Page looks like an error page")
+	return data, nil
+}
@@ -0,0 +1,71 @@
+package status
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/law-makers/crawl/pkg/models"
+)
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     models.PageData
+		selector string
+		want     string // substring of the reason, or "" for none
+	}{
+		{"normal page", models.PageData{StatusCode: 200, Title: "Shop", Content: "Welcome to the shop, browse our products"}, "body", ""},
+		{"title 404", models.PageData{StatusCode: 200, Title: "404", Content: "Welcome to the shop, browse our products"}, "body", "soft 404"},
+		{"cloudflare", models.PageData{StatusCode: 200, Title: "Just a moment...", Content: "Checking your browser before accessing example.com"}, "body", "block page"},
+		{"tiny body", models.PageData{StatusCode: 200, Content: "ok"}, "body", "nearly empty"},
+		{"tiny selection", models.PageData{StatusCode: 200, Content: "$5"}, ".price", ""},
+		{"error status", models.PageData{StatusCode: 503, Title: "Access denied"}, "body", ""},
+	}
+	for _, tt := range tests {
+		got := Detect(&tt.data, tt.selector)
+		if tt.want == "" && got != "" {
+			t.Errorf("%s: expected no suspicion, got %q", tt.name, got)
+		}
+		if tt.want != "" && !strings.Contains(got, tt.want) {
+			t.Errorf("%s: expected a reason containing %q, got %q", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestDetector_Refetch(t *testing.T) {
+	blocked := &sequenceScraper{pages: []*models.PageData{{StatusCode: 200, Title: "Attention Required! | Cloudflare"}}}
+	var tried []string
+	d := NewDetector(blocked,
+		Refetch{Name: "broken", Fetch: func(models.RequestOptions) (*models.PageData, error) {
+			tried = append(tried, "broken")
+			return nil, errors.New("no browser")
+		}},
+		Refetch{Name: "spa", Fetch: func(models.RequestOptions) (*models.PageData, error) {
+			tried = append(tried, "spa")
+			return &models.PageData{StatusCode: 200, Title: "Shop", Content: "Welcome to the shop, browse our products"}, nil
+		}},
+	)
+
+	data, err := d.Fetch(models.RequestOptions{URL: "u", Selector: "body"})
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if data.Title != "Shop" || data.SuspectedError != "" {
+		t.Errorf("Expected the refetched page, got %+v", data)
+	}
+	if strings.Join(tried, ",") != "broken,spa" {
+		t.Errorf("Expected refetches in order, got %v", tried)
+	}
+}
+
+func TestDetector_KeepsFlaggedPage(t *testing.T) {
+	blocked := &sequenceScraper{pages: []*models.PageData{{StatusCode: 200, Title: "Access Denied"}}}
+	data, err := NewDetector(blocked).Fetch(models.RequestOptions{URL: "u"})
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if !strings.Contains(data.SuspectedError, "access denied") {
+		t.Errorf("Expected the page to be flagged, got %q", data.SuspectedError)
+	}
+}
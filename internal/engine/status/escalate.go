@@ -0,0 +1,98 @@
+// internal/engine/status/escalate.go
+package status
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/law-makers/crawl/internal/engine"
+	"github.com/law-makers/crawl/internal/logging"
+	"github.com/law-makers/crawl/pkg/models"
+)
+
+// Blocked returns why a response looks like a bot filter turned it away,
+// or "" when it doesn't: a 403, a Cloudflare challenge, or a page with
+// block or CAPTCHA text
+func Blocked(data *models.PageData) string {
+	if data == nil {
+		return ""
+	}
+	for k, v := range data.Headers {
+		if strings.EqualFold(k, "cf-mitigated") && strings.EqualFold(v, "challenge") {
+			return "cloudflare challenge"
+		}
+	}
+	if marker := BlockMarker(data); marker != "" {
+		return fmt.Sprintf("block page: contains %q", marker)
+	}
+	if data.StatusCode == http.StatusForbidden {
+		return "status 403"
+	}
+	return ""
+}
+
+// Rung is one step of an escalation ladder. Its changes carry over to
+// the rungs after it, so e.g. the browser is used with the proxy before it.
+type Rung struct {
+	Name  string
+	Apply func(opts *models.RequestOptions)                          // Changes the request; nil leaves it
+	Fetch func(opts models.RequestOptions) (*models.PageData, error) // Replaces the fetcher; nil keeps the current one
+}
+
+// Escalator wraps another scraper, climbing its ladder when a response is
+// blocked until a rung gets through. The page from that rung is returned
+// with PageData.Escalation set to the rung's name.
+type Escalator struct {
+	next   engine.Scraper
+	ladder []Rung
+}
+
+// NewEscalator wraps next with the escalation ladder
+func NewEscalator(next engine.Scraper, ladder ...Rung) *Escalator {
+	return &Escalator{next: next, ladder: ladder}
+}
+
+// Name returns the name of the wrapped scraper
+func (e *Escalator) Name() string {
+	return e.next.Name()
+}
+
+// Fetch retrieves the page, escalating if it is blocked. When every rung is
+// blocked too, the original response is returned.
+func (e *Escalator) Fetch(opts models.RequestOptions) (*models.PageData, error) {
+	data, err := e.next.Fetch(opts)
+	if err != nil {
+		return nil, err
+	}
+	reason := Blocked(data)
+	if reason == "" || len(e.ladder) == 0 {
+		return data, nil
+	}
+
+	logger := logging.ForRequest(opts, "")
+	fetch := e.next.Fetch
+	for _, rung := range e.ladder {
+		if rung.Apply != nil {
+			rung.Apply(&opts)
+		}
+		if rung.Fetch != nil {
+			fetch = rung.Fetch
+		}
+		logger.Debug().Str("reason", reason).Str("rung", rung.Name).Msg("Blocked, escalating")
+		again, err := fetch(opts)
+		if err != nil || again == nil {
+			logger.Debug().Err(err).Str("rung", rung.Name).Msg("Escalation rung failed")
+			continue
+		}
+		if next := Blocked(again); next != "" {
+			reason = next
+			continue
+		}
+		again.Escalation = rung.Name
+		logger.Info().Str("rung", rung.Name).Msg("Got past block by escalating")
+		return again, nil
+	}
+	logger.Warn().Str("reason", reason).Msg("Still blocked after every escalation rung")
+	return data, nil
+}
@@ -0,0 +1,83 @@
+package status
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/law-makers/crawl/pkg/models"
+)
+
+// headerGate returns 403 unless the request carries a Sec-Fetch-Mode header
+type headerGate struct{ calls []models.RequestOptions }
+
+func (g *headerGate) Name() string { return "gate" }
+func (g *headerGate) Fetch(opts models.RequestOptions) (*models.PageData, error) {
+	g.calls = append(g.calls, opts)
+	if opts.Headers["Sec-Fetch-Mode"] == "" {
+		return &models.PageData{URL: opts.URL, StatusCode: http.StatusForbidden}, nil
+	}
+	return &models.PageData{URL: opts.URL, StatusCode: http.StatusOK, Title: "Shop"}, nil
+}
+
+func TestBlocked(t *testing.T) {
+	tests := []struct {
+		data    models.PageData
+		blocked bool
+	}{
+		{models.PageData{StatusCode: 200, Title: "Shop"}, false},
+		{models.PageData{StatusCode: 403}, true},
+		{models.PageData{StatusCode: 503, Headers: map[string]string{"Cf-Mitigated": "challenge"}}, true},
+		{models.PageData{StatusCode: 200, Content: "Please complete the CAPTCHA below"}, true},
+		{models.PageData{StatusCode: 404}, false},
+	}
+	for _, tt := range tests {
+		if got := Blocked(&tt.data) != ""; got != tt.blocked {
+			t.Errorf("Blocked(%+v) = %v, want %v", tt.data, got, tt.blocked)
+		}
+	}
+}
+
+func TestEscalator_ClimbsUntilThrough(t *testing.T) {
+	gate := &headerGate{}
+	browserUsed := false
+	e := NewEscalator(gate,
+		Rung{Name: "proxy", Apply: func(opts *models.RequestOptions) { opts.Proxy = "http://proxy:8080" }},
+		Rung{Name: "headers", Apply: func(opts *models.RequestOptions) {
+			opts.Headers = map[string]string{"Sec-Fetch-Mode": "navigate"}
+		}},
+		Rung{Name: "spa", Fetch: func(models.RequestOptions) (*models.PageData, error) {
+			browserUsed = true
+			return nil, nil
+		}},
+	)
+
+	data, err := e.Fetch(models.RequestOptions{URL: "u"})
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if data.StatusCode != 200 || data.Escalation != "headers" {
+		t.Errorf("Expected the headers rung to get through, got status %d via %q", data.StatusCode, data.Escalation)
+	}
+	if browserUsed {
+		t.Error("Expected the ladder to stop at the first rung that got through")
+	}
+	// Changes carry over: the headers rung still used the proxy
+	if last := gate.calls[len(gate.calls)-1]; last.Proxy != "http://proxy:8080" {
+		t.Errorf("Expected earlier rungs' changes to carry over, got %+v", last)
+	}
+}
+
+func TestEscalator_ReturnsOriginalWhenStillBlocked(t *testing.T) {
+	gate := &headerGate{}
+	data, err := NewEscalator(gate, Rung{Name: "proxy", Apply: func(opts *models.RequestOptions) { opts.Proxy = "http://proxy:8080" }}).
+		Fetch(models.RequestOptions{URL: "u"})
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if data.StatusCode != http.StatusForbidden || data.Escalation != "" {
+		t.Errorf("Expected the original 403, got %+v", data)
+	}
+	if len(gate.calls) != 2 {
+		t.Errorf("Expected 2 fetches, got %d", len(gate.calls))
+	}
+}
@@ -0,0 +1,235 @@
+// internal/engine/status/status.go
+package status
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/law-makers/crawl/internal/engine"
+	"github.com/law-makers/crawl/internal/retry"
+	"github.com/law-makers/crawl/pkg/models"
+)
+
+// Allow is a set of accepted HTTP status codes. The zero value accepts every status.
+type Allow struct {
+	ranges [][2]int
+	spec   string
+}
+
+// ParseAllow parses an --ok-status list such as "200,203,301", "2xx" or
+// "200-299,304"
+func ParseAllow(s string) (Allow, error) {
+	a := Allow{spec: strings.TrimSpace(s)}
+	for _, part := range strings.Split(s, ",") {
+		part = strings.ToLower(strings.TrimSpace(part))
+		if part == "" {
+			continue
+		}
+		lo, hi, err := parseRange(part)
+		if err != nil {
+			return Allow{}, fmt.Errorf("invalid --ok-status %q: %w", s, err)
+		}
+		a.ranges = append(a.ranges, [2]int{lo, hi})
+	}
+	return a, nil
+}
+
+func parseRange(part string) (int, int, error) {
+	if len(part) == 3 && strings.HasSuffix(part, "xx") {
+		class, err := strconv.Atoi(part[:1])
+		if err != nil || class < 1 || class > 5 {
+			return 0, 0, fmt.Errorf("unknown status class %q", part)
+		}
+		return class * 100, class*100 + 99, nil
+	}
+	if lo, hi, ok := strings.Cut(part, "-"); ok {
+		from, err1 := parseCode(lo)
+		to, err2 := parseCode(hi)
+		if err1 != nil || err2 != nil || from > to {
+			return 0, 0, fmt.Errorf("invalid status range %q", part)
+		}
+		return from, to, nil
+	}
+	code, err := parseCode(part)
+	return code, code, err
+}
+
+func parseCode(s string) (int, error) {
+	code, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil || code < 100 || code > 599 {
+		return 0, fmt.Errorf("invalid status code %q", s)
+	}
+	return code, nil
+}
+
+// IsZero reports whether a accepts every status
+func (a Allow) IsZero() bool {
+	return len(a.ranges) == 0
+}
+
+// Allows reports whether code is accepted
+func (a Allow) Allows(code int) bool {
+	if a.IsZero() {
+		return true
+	}
+	for _, r := range a.ranges {
+		if code >= r[0] && code <= r[1] {
+			return true
+		}
+	}
+	return false
+}
+
+// String returns the list as given to ParseAllow
+func (a Allow) String() string {
+	return a.spec
+}
+
+// StatusError is returned for a page whose status is not allowed. It
+// implements retry.StatusCoder, so 429 and 5xx responses are retried.
+type StatusError struct {
+	URL        string
+	StatusCode int
+	Allowed    Allow
+}
+
+// Error implements the error interface
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("status %d %s (allowed: %s)", e.StatusCode, http.StatusText(e.StatusCode), e.Allowed)
+}
+
+// GetStatusCode implements retry.StatusCoder
+func (e *StatusError) GetStatusCode() int {
+	return e.StatusCode
+}
+
+// SoftNotFoundError is returned for a page served with an allowed status
+// whose content says it was not found. It is never retried.
+type SoftNotFoundError struct {
+	URL        string
+	StatusCode int
+	Reason     string
+}
+
+// Error implements the error interface
+func (e *SoftNotFoundError) Error() string {
+	return fmt.Sprintf("soft 404: status %d but %s", e.StatusCode, e.Reason)
+}
+
+// GetStatusCode implements retry.StatusCoder
+func (e *SoftNotFoundError) GetStatusCode() int {
+	return e.StatusCode
+}
+
+// notFoundText matches the wording of typical "not found" pages
+var notFoundText = regexp.MustCompile(`(?i)\b(404|not found|(does not|doesn't|could not|couldn't|cannot|can't) be found|no longer exists?)\b`)
+
+// notFoundFiller matches the words that pad a "not found" title, e.g.
+// "Oops! Error 404 - Page Not Found"
+var notFoundFiller = regexp.MustCompile(`(?i)\b(oops|sorry|error|http|page|file|document|resource|url|requested|the|this|that|we|it|you|your|were|was|is|are|looking|for)\b`)
+
+// titleSeparators split a title into the page's own part and the site name
+var titleSeparators = regexp.MustCompile(`\s+[|\-–—:·]\s+|\s*\|\s*`)
+
+// maxNotFoundBody is the most text a page can have and still be taken as a
+// bare "not found" message; longer pages may just be about a 404
+const maxNotFoundBody = 300
+
+// SoftNotFound reports whether a successful page looks like a "not found"
+// page, and why. Mentioning 404 is not enough: some part of the title must
+// be little more than the "not found" phrase, or the whole page must be a
+// short text containing it.
+func SoftNotFound(data *models.PageData) (string, bool) {
+	if data == nil || data.StatusCode < 200 || data.StatusCode > 299 {
+		return "", false
+	}
+	for _, part := range titleSeparators.Split(data.Title, -1) {
+		m := notFoundText.FindString(part)
+		if m == "" {
+			continue
+		}
+		rest := notFoundFiller.ReplaceAllString(notFoundText.ReplaceAllString(part, ""), "")
+		if strings.IndexFunc(rest, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }) < 0 {
+			return fmt.Sprintf("title %q says %q", data.Title, m), true
+		}
+	}
+	body := strings.TrimSpace(data.Content)
+	if len(body) <= maxNotFoundBody {
+		if m := notFoundText.FindString(body); m != "" {
+			return fmt.Sprintf("%d-character page says %q", len(body), m), true
+		}
+	}
+	return "", false
+}
+
+// fetchError marks an error from the wrapped scraper so it is returned as
+// is rather than retried; only status failures are retried here
+type fetchError struct{ err error }
+
+func (e fetchError) Error() string   { return e.err.Error() }
+func (e fetchError) Unwrap() error   { return e.err }
+func (e fetchError) Temporary() bool { return false }
+
+// Scraper wraps another scraper, failing pages whose status is not allowed
+// (retrying 429 and 5xx responses) and pages that are soft 404s
+type Scraper struct {
+	next  engine.Scraper
+	allow Allow
+	retry retry.Config
+}
+
+// New wraps next with the allow-list, retrying as cfg says
+func New(next engine.Scraper, allow Allow, cfg retry.Config) *Scraper {
+	return &Scraper{next: next, allow: allow, retry: cfg}
+}
+
+// Name returns the name of the wrapped scraper
+func (s *Scraper) Name() string {
+	return s.next.Name()
+}
+
+// Fetch retrieves the page and checks its status
+func (s *Scraper) Fetch(opts models.RequestOptions) (*models.PageData, error) {
+	var data *models.PageData
+	tries := 0
+	err := retry.WithRetry(context.Background(), s.retry, func() error {
+		if tries++; tries > 1 {
+			opts.Attempt = tries
+		}
+		page, err := s.next.Fetch(opts)
+		if err != nil {
+			return fetchError{err}
+		}
+		if err := s.Check(page); err != nil {
+			return err
+		}
+		data = page
+		return nil
+	})
+	if fe, ok := err.(fetchError); ok {
+		return nil, fe.err
+	}
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// Check returns a *StatusError or *SoftNotFoundError for a page that fails
+// the allow-list, or nil
+func (s *Scraper) Check(data *models.PageData) error {
+	// A zero status means the engine didn't see the response (e.g. some
+	// browser navigations); only the content can be judged
+	if data.StatusCode != 0 && !s.allow.Allows(data.StatusCode) {
+		return &StatusError{URL: data.URL, StatusCode: data.StatusCode, Allowed: s.allow}
+	}
+	if reason, ok := SoftNotFound(data); ok {
+		return &SoftNotFoundError{URL: data.URL, StatusCode: data.StatusCode, Reason: reason}
+	}
+	return nil
+}
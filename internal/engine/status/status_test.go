@@ -0,0 +1,147 @@
+package status
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/law-makers/crawl/internal/retry"
+	"github.com/law-makers/crawl/pkg/models"
+)
+
+// sequenceScraper returns one page per call from pages, repeating the last
+type sequenceScraper struct {
+	pages []*models.PageData
+	calls int
+}
+
+func (s *sequenceScraper) Name() string { return "sequence" }
+func (s *sequenceScraper) Fetch(opts models.RequestOptions) (*models.PageData, error) {
+	i := s.calls
+	if i >= len(s.pages) {
+		i = len(s.pages) - 1
+	}
+	s.calls++
+	if s.pages[i] == nil {
+		return nil, errors.New("connection refused")
+	}
+	return s.pages[i], nil
+}
+
+func testRetryConfig() retry.Config {
+	cfg := retry.DefaultConfig()
+	cfg.InitialBackoff = time.Millisecond
+	cfg.MaxBackoff = time.Millisecond
+	return cfg
+}
+
+func TestParseAllow(t *testing.T) {
+	tests := []struct {
+		spec    string
+		code    int
+		allowed bool
+	}{
+		{"200,203,301", 203, true},
+		{"200,203,301", 302, false},
+		{"2xx", 204, true},
+		{"2xx", 301, false},
+		{"200-299, 304", 304, true},
+		{"200-299, 304", 404, false},
+		{"", 500, true},
+	}
+	for _, tt := range tests {
+		a, err := ParseAllow(tt.spec)
+		if err != nil {
+			t.Fatalf("ParseAllow(%q) failed: %v", tt.spec, err)
+		}
+		if got := a.Allows(tt.code); got != tt.allowed {
+			t.Errorf("ParseAllow(%q).Allows(%d) = %v, want %v", tt.spec, tt.code, got, tt.allowed)
+		}
+	}
+
+	for _, spec := range []string{"abc", "99", "600", "6xx", "300-200"} {
+		if _, err := ParseAllow(spec); err == nil {
+			t.Errorf("Expected error for %q", spec)
+		}
+	}
+}
+
+func TestScraper_RetriesServerErrors(t *testing.T) {
+	next := &sequenceScraper{pages: []*models.PageData{
+		{URL: "u", StatusCode: 503},
+		{URL: "u", StatusCode: 200, Title: "Products"},
+	}}
+	allow, _ := ParseAllow("200")
+	data, err := New(next, allow, testRetryConfig()).Fetch(models.RequestOptions{URL: "u"})
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if data.StatusCode != 200 || next.calls != 2 {
+		t.Errorf("Expected a 200 after one retry, got %d after %d calls", data.StatusCode, next.calls)
+	}
+}
+
+func TestScraper_RejectsWithoutRetry(t *testing.T) {
+	next := &sequenceScraper{pages: []*models.PageData{{URL: "u", StatusCode: 404}}}
+	allow, _ := ParseAllow("200")
+	_, err := New(next, allow, testRetryConfig()).Fetch(models.RequestOptions{URL: "u"})
+
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != 404 {
+		t.Fatalf("Expected a StatusError for 404, got %v", err)
+	}
+	if next.calls != 1 {
+		t.Errorf("Expected 404 not to be retried, got %d calls", next.calls)
+	}
+}
+
+func TestScraper_SoftNotFound(t *testing.T) {
+	next := &sequenceScraper{pages: []*models.PageData{{URL: "u", StatusCode: 200, Title: "Page Not Found | Shop"}}}
+	allow, _ := ParseAllow("2xx")
+	_, err := New(next, allow, testRetryConfig()).Fetch(models.RequestOptions{URL: "u"})
+
+	var soft *SoftNotFoundError
+	if !errors.As(err, &soft) {
+		t.Fatalf("Expected a SoftNotFoundError, got %v", err)
+	}
+	if next.calls != 1 {
+		t.Errorf("Expected soft 404 not to be retried, got %d calls", next.calls)
+	}
+}
+
+func TestSoftNotFound(t *testing.T) {
+	article := "A 404 status means the server could not find the requested page. " + strings.Repeat("Servers send it for missing pages and for pages they want to hide. ", 10)
+	tests := []struct {
+		title   string
+		content string
+		want    bool
+	}{
+		{"Page Not Found | Shop", "Browse our products", true},
+		{"404", "Welcome to the shop, browse our products", true},
+		{"Oops! Error 404 - Page Not Found", article, true},
+		{"Shop", "Sorry, the page you were looking for could not be found.", true},
+		{"HTTP 404 explained", article, false},
+		{"Model 404 specs", "The Model 404 has a 2.4 GHz radio. " + article, false},
+		{"Sorry, this product no longer exists | Shop", article, false},
+		{"Shop", article, false},
+	}
+	for _, tt := range tests {
+		data := &models.PageData{StatusCode: 200, Title: tt.title, Content: tt.content}
+		if reason, got := SoftNotFound(data); got != tt.want {
+			t.Errorf("SoftNotFound(%q) = %v (%s), want %v", tt.title, got, reason, tt.want)
+		}
+	}
+}
+
+func TestScraper_FetchErrorNotRetried(t *testing.T) {
+	next := &sequenceScraper{pages: []*models.PageData{nil}}
+	allow, _ := ParseAllow("200")
+	_, err := New(next, allow, testRetryConfig()).Fetch(models.RequestOptions{URL: "u"})
+	if err == nil || err.Error() != "connection refused" {
+		t.Fatalf("Expected the fetch error unchanged, got %v", err)
+	}
+	if next.calls != 1 {
+		t.Errorf("Expected 1 call, got %d", next.calls)
+	}
+}
@@ -0,0 +1,171 @@
+// internal/engine/tdm/robots.go
+package tdm
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+)
+
+// AIAgents are the user agents of AI crawlers and training opt-out tokens
+// whose robots.txt groups are read as reservations
+var AIAgents = []string{
+	"GPTBot", "ChatGPT-User", "OAI-SearchBot", "CCBot", "Google-Extended",
+	"anthropic-ai", "ClaudeBot", "Claude-Web", "PerplexityBot", "Bytespider",
+	"Applebot-Extended", "cohere-ai", "Meta-ExternalAgent", "Diffbot", "Omgilibot",
+}
+
+// Robots holds the parts of a robots.txt that reserve content from AI use
+type Robots struct {
+	groups []group
+	usage  []usage
+}
+
+type group struct {
+	agents []string // lowercased
+	rules  []rule
+}
+
+type rule struct {
+	allow bool
+	path  string
+}
+
+// usage is a Content-Usage line (the IETF AI preferences vocabulary),
+// optionally limited to a path prefix
+type usage struct {
+	path  string
+	prefs string
+}
+
+// ParseRobots parses a robots.txt body. Lines it doesn't know are ignored.
+func ParseRobots(body string) *Robots {
+	r := &Robots{}
+	var cur *group
+	inAgents := false
+	scanner := bufio.NewScanner(strings.NewReader(body))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key, value = strings.ToLower(strings.TrimSpace(key)), strings.TrimSpace(value)
+		switch key {
+		case "user-agent":
+			if !inAgents {
+				r.groups = append(r.groups, group{})
+				cur = &r.groups[len(r.groups)-1]
+			}
+			cur.agents = append(cur.agents, strings.ToLower(value))
+			inAgents = true
+		case "allow", "disallow":
+			inAgents = false
+			if cur != nil && value != "" {
+				cur.rules = append(cur.rules, rule{allow: key == "allow", path: value})
+			}
+		case "content-usage":
+			inAgents = false
+			u := usage{prefs: value}
+			if strings.HasPrefix(value, "/") {
+				u.path, u.prefs, _ = strings.Cut(value, " ")
+			}
+			r.usage = append(r.usage, u)
+		default:
+			inAgents = false
+		}
+	}
+	return r
+}
+
+// Reservations returns how the robots.txt reserves path from AI use: AI
+// crawlers disallowed from it, and Content-Usage preferences refusing AI
+func (r *Robots) Reservations(path string) []string {
+	if path == "" {
+		path = "/"
+	}
+	var found []string
+	for _, agent := range AIAgents {
+		if g := r.group(agent); g != nil && !g.allowed(path) {
+			found = append(found, fmt.Sprintf("robots.txt: %s disallowed", agent))
+		}
+	}
+	for _, u := range r.usage {
+		if u.path != "" && !strings.HasPrefix(path, u.path) {
+			continue
+		}
+		if refusesAI(u.prefs) {
+			found = append(found, "robots.txt: Content-Usage "+strings.TrimSpace(u.prefs))
+		}
+	}
+	return found
+}
+
+// group returns the group naming agent itself; the * group is not an AI
+// reservation
+func (r *Robots) group(agent string) *group {
+	agent = strings.ToLower(agent)
+	for i := range r.groups {
+		for _, a := range r.groups[i].agents {
+			if a == agent {
+				return &r.groups[i]
+			}
+		}
+	}
+	return nil
+}
+
+// allowed applies the longest matching rule, allow winning ties
+func (g *group) allowed(path string) bool {
+	best, allow := -1, true
+	for _, rl := range g.rules {
+		if !matchPath(rl.path, path) {
+			continue
+		}
+		if n := len(rl.path); n > best || (n == best && rl.allow) {
+			best, allow = n, rl.allow
+		}
+	}
+	return allow
+}
+
+// matchPath matches a robots.txt path pattern, with * and a trailing $
+func matchPath(pattern, path string) bool {
+	exact := strings.HasSuffix(pattern, "$")
+	pattern = strings.TrimSuffix(pattern, "$")
+	parts := strings.Split(pattern, "*")
+	if !strings.HasPrefix(path, parts[0]) {
+		return false
+	}
+	rest := path[len(parts[0]):]
+	for _, part := range parts[1:] {
+		i := strings.Index(rest, part)
+		if i < 0 {
+			return false
+		}
+		rest = rest[i+len(part):]
+	}
+	if exact {
+		return rest == "" || (len(parts) > 1 && strings.HasSuffix(path, parts[len(parts)-1]))
+	}
+	return true
+}
+
+// refusesAI reports whether Content-Usage preferences such as "train-ai=n"
+// or "ai=n, search=y" refuse any AI use
+func refusesAI(prefs string) bool {
+	for _, p := range strings.FieldsFunc(prefs, func(r rune) bool { return r == ',' || r == ' ' || r == ';' }) {
+		key, value, ok := strings.Cut(p, "=")
+		if !ok {
+			continue
+		}
+		key, value = strings.ToLower(strings.TrimSpace(key)), strings.ToLower(strings.TrimSpace(value))
+		if (key == "ai" || strings.HasSuffix(key, "-ai")) && value == "n" {
+			return true
+		}
+	}
+	return false
+}
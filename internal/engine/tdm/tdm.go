@@ -0,0 +1,130 @@
+// internal/engine/tdm/tdm.go
+//
+// Package tdm finds text and data mining (TDM) reservations: the signals
+// sites use to say their content is not to be used for AI. Headers and meta
+// tags are read by the engines (see metadata.NoAI); this package adds the
+// site's robots.txt and can refuse pages that carry any reservation.
+package tdm
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/law-makers/crawl/internal/engine"
+	"github.com/law-makers/crawl/pkg/models"
+	"github.com/rs/zerolog/log"
+)
+
+// maxRobotsSize bounds how much of a robots.txt is read
+const maxRobotsSize = 512 << 10
+
+// ReservedError is returned for pages skipped because they reserve text
+// and data mining
+type ReservedError struct {
+	URL     string
+	Signals []string
+}
+
+// Error implements the error interface
+func (e *ReservedError) Error() string {
+	return fmt.Sprintf("reserves text and data mining (%s)", strings.Join(e.Signals, "; "))
+}
+
+// Checker wraps a scraper, adding each site's robots.txt reservations to
+// the pages it fetches and, when respecting them, skipping reserved pages
+type Checker struct {
+	next    engine.Scraper
+	client  *http.Client
+	respect bool
+
+	mu    sync.Mutex
+	sites map[string]*site
+}
+
+// site is one origin's robots.txt, fetched once
+type site struct {
+	once   sync.Once
+	robots *Robots
+}
+
+// NewChecker wraps next, fetching robots.txt with client. With respect set,
+// pages with any reservation fail with a *ReservedError; those reserved by
+// robots.txt are not fetched at all.
+func NewChecker(next engine.Scraper, client *http.Client, respect bool) *Checker {
+	return &Checker{next: next, client: client, respect: respect, sites: map[string]*site{}}
+}
+
+// Name returns the name of the wrapped scraper
+func (c *Checker) Name() string {
+	return c.next.Name()
+}
+
+// Fetch checks robots.txt, fetches the page and merges the reservations
+func (c *Checker) Fetch(opts models.RequestOptions) (*models.PageData, error) {
+	fromRobots := c.robotsReservations(opts.URL)
+	if c.respect && len(fromRobots) > 0 {
+		return nil, &ReservedError{URL: opts.URL, Signals: fromRobots}
+	}
+
+	data, err := c.next.Fetch(opts)
+	if err != nil {
+		return nil, err
+	}
+	if len(fromRobots) > 0 {
+		data.NoAI = append(data.NoAI, fromRobots...)
+		sort.Strings(data.NoAI)
+	}
+	if c.respect && len(data.NoAI) > 0 {
+		return nil, &ReservedError{URL: opts.URL, Signals: data.NoAI}
+	}
+	return data, nil
+}
+
+// robotsReservations returns the reservations rawURL's robots.txt makes
+// for it. A robots.txt that can't be fetched makes none.
+func (c *Checker) robotsReservations(rawURL string) []string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return nil
+	}
+	origin := u.Scheme + "://" + u.Host
+
+	c.mu.Lock()
+	s, ok := c.sites[origin]
+	if !ok {
+		s = &site{}
+		c.sites[origin] = s
+	}
+	c.mu.Unlock()
+
+	s.once.Do(func() {
+		s.robots = c.fetchRobots(origin)
+	})
+	if s.robots == nil {
+		return nil
+	}
+	return s.robots.Reservations(u.EscapedPath())
+}
+
+func (c *Checker) fetchRobots(origin string) *Robots {
+	resp, err := c.client.Get(origin + "/robots.txt")
+	if err != nil {
+		log.Debug().Err(err).Str("site", origin).Msg("Could not fetch robots.txt")
+		return nil
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxRobotsSize))
+	if err != nil {
+		log.Debug().Err(err).Str("site", origin).Msg("Could not read robots.txt")
+		return nil
+	}
+	return ParseRobots(string(body))
+}
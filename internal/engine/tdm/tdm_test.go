@@ -0,0 +1,102 @@
+package tdm
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/law-makers/crawl/pkg/models"
+)
+
+const testRobots = `
+User-agent: *
+Disallow: /private
+
+# AI crawlers
+User-agent: GPTBot
+User-agent: CCBot
+Disallow: /
+Allow: /public/
+
+User-agent: ClaudeBot
+Disallow: /*.pdf$
+
+Content-Usage: /articles/ train-ai=n
+`
+
+func TestRobots_Reservations(t *testing.T) {
+	r := ParseRobots(testRobots)
+	tests := []struct {
+		path string
+		want []string
+	}{
+		{"/", []string{"robots.txt: GPTBot disallowed", "robots.txt: CCBot disallowed"}},
+		{"/public/page", nil},
+		{"/public/doc.pdf", []string{"robots.txt: ClaudeBot disallowed"}},
+		{"/articles/1", []string{"robots.txt: GPTBot disallowed", "robots.txt: CCBot disallowed", "robots.txt: Content-Usage train-ai=n"}},
+	}
+	for _, tt := range tests {
+		if got := r.Reservations(tt.path); strings.Join(got, "|") != strings.Join(tt.want, "|") {
+			t.Errorf("Reservations(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+
+	// Blocking every crawler is not an AI reservation
+	if got := ParseRobots("User-agent: *\nDisallow: /\n").Reservations("/"); len(got) != 0 {
+		t.Errorf("Expected no reservations from the * group, got %q", got)
+	}
+}
+
+type fakeScraper struct {
+	fetched []string
+	noAI    []string
+}
+
+func (f *fakeScraper) Name() string { return "fake" }
+
+func (f *fakeScraper) Fetch(opts models.RequestOptions) (*models.PageData, error) {
+	f.fetched = append(f.fetched, opts.URL)
+	return &models.PageData{URL: opts.URL, StatusCode: 200, NoAI: append([]string(nil), f.noAI...)}, nil
+}
+
+func TestChecker(t *testing.T) {
+	robotsRequests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			robotsRequests++
+			w.Write([]byte("User-agent: GPTBot\nDisallow: /news/\n"))
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+
+	next := &fakeScraper{}
+	checker := NewChecker(next, server.Client(), false)
+	page, err := checker.Fetch(models.RequestOptions{URL: server.URL + "/news/1"})
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if len(page.NoAI) != 1 || page.NoAI[0] != "robots.txt: GPTBot disallowed" {
+		t.Errorf("Expected the robots.txt reservation recorded, got %q", page.NoAI)
+	}
+
+	respecting := NewChecker(next, server.Client(), true)
+	_, err = respecting.Fetch(models.RequestOptions{URL: server.URL + "/news/2"})
+	var reserved *ReservedError
+	if !errors.As(err, &reserved) || len(next.fetched) != 1 {
+		t.Errorf("Expected the page skipped without fetching, got %v after %d fetches", err, len(next.fetched))
+	}
+	if _, err := respecting.Fetch(models.RequestOptions{URL: server.URL + "/about"}); err != nil {
+		t.Errorf("Expected an unreserved page fetched, got %v", err)
+	}
+	next.noAI = []string{"X-Robots-Tag: noai"}
+	if _, err := respecting.Fetch(models.RequestOptions{URL: server.URL + "/contact"}); !errors.As(err, &reserved) {
+		t.Errorf("Expected a page reserved by its headers skipped, got %v", err)
+	}
+	if robotsRequests != 2 {
+		t.Errorf("Expected robots.txt fetched once per checker, got %d requests", robotsRequests)
+	}
+}
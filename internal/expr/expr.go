@@ -0,0 +1,125 @@
+// internal/expr/expr.go
+package expr
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/cel-go/cel"
+	"github.com/law-makers/crawl/pkg/models"
+)
+
+// Expressions are written in CEL (https://cel.dev) and see a fetched page
+// through these variables:
+//
+//	url, title, content, html, suspected_error  string
+//	status, response_time_ms                    int
+//	headers, metadata                           map(string, string)
+//	links, images, scripts                      list(string)
+//	items                                       list(map(string, string)), the --field rows
+//
+// e.g. `status == 200 && url.matches("/product/") && size(items) >= 10`.
+var env = mustEnv()
+
+func mustEnv() *cel.Env {
+	strList := cel.ListType(cel.StringType)
+	strMap := cel.MapType(cel.StringType, cel.StringType)
+	e, err := cel.NewEnv(
+		cel.Variable("url", cel.StringType),
+		cel.Variable("title", cel.StringType),
+		cel.Variable("content", cel.StringType),
+		cel.Variable("html", cel.StringType),
+		cel.Variable("suspected_error", cel.StringType),
+		cel.Variable("status", cel.IntType),
+		cel.Variable("response_time_ms", cel.IntType),
+		cel.Variable("headers", strMap),
+		cel.Variable("metadata", strMap),
+		cel.Variable("links", strList),
+		cel.Variable("images", strList),
+		cel.Variable("scripts", strList),
+		cel.Variable("items", cel.ListType(strMap)),
+	)
+	if err != nil {
+		panic(err)
+	}
+	return e
+}
+
+// Program is a compiled expression, safe for concurrent use
+type Program struct {
+	Source string
+	prg    cel.Program
+}
+
+// Compile parses and type-checks a boolean expression, so mistakes are
+// reported before anything is fetched
+func Compile(src string) (*Program, error) {
+	src = strings.TrimSpace(src)
+	if src == "" {
+		return nil, fmt.Errorf("empty expression")
+	}
+	ast, iss := env.Compile(src)
+	if iss.Err() != nil {
+		return nil, fmt.Errorf("invalid expression %q: %w", src, iss.Err())
+	}
+	if ast.OutputType() != cel.BoolType {
+		return nil, fmt.Errorf("expression %q must be true or false, not %s", src, ast.OutputType())
+	}
+	prg, err := env.Program(ast)
+	if err != nil {
+		return nil, fmt.Errorf("invalid expression %q: %w", src, err)
+	}
+	return &Program{Source: src, prg: prg}, nil
+}
+
+// Eval evaluates the expression against a page. Errors come from the data,
+// such as a missing map key; test with `"x-cache" in headers` first.
+func (p *Program) Eval(page *models.PageData) (bool, error) {
+	out, _, err := p.prg.Eval(vars(page))
+	if err != nil {
+		return false, err
+	}
+	v, ok := out.Value().(bool)
+	if !ok {
+		return false, fmt.Errorf("expression returned %v, not a boolean", out.Value())
+	}
+	return v, nil
+}
+
+// vars binds the page to the expression variables. Nil maps and slices
+// become empty ones so size() and "in" work on every page.
+func vars(page *models.PageData) map[string]interface{} {
+	items := page.Structured
+	if items == nil {
+		items = []map[string]string{}
+	}
+	return map[string]interface{}{
+		"url":              page.URL,
+		"title":            page.Title,
+		"content":          page.Content,
+		"html":             page.HTML,
+		"suspected_error":  page.SuspectedError,
+		"status":           int64(page.StatusCode),
+		"response_time_ms": page.ResponseTime,
+		"headers":          orEmpty(page.Headers),
+		"metadata":         orEmpty(page.Metadata),
+		"links":            listOrEmpty(page.Links),
+		"images":           listOrEmpty(page.Images),
+		"scripts":          listOrEmpty(page.Scripts),
+		"items":            items,
+	}
+}
+
+func orEmpty(m map[string]string) map[string]string {
+	if m == nil {
+		return map[string]string{}
+	}
+	return m
+}
+
+func listOrEmpty(l []string) []string {
+	if l == nil {
+		return []string{}
+	}
+	return l
+}
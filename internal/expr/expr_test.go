@@ -0,0 +1,68 @@
+package expr
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/law-makers/crawl/pkg/models"
+)
+
+func TestEval(t *testing.T) {
+	page := &models.PageData{
+		URL:        "https://shop.example.com/product/42",
+		StatusCode: 200,
+		Title:      "Blue Widget",
+		Headers:    map[string]string{"Content-Type": "text/html"},
+		Structured: []map[string]string{{"price": "9.99"}, {"price": "12.50"}},
+	}
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{`status == 200`, true},
+		{`url.matches("/product/[0-9]+$") && title.contains("Widget")`, true},
+		{`size(items) >= 3`, false},
+		{`items.all(i, double(i.price) < 20.0)`, true},
+		{`"X-Cache" in headers && headers["X-Cache"] == "HIT"`, false},
+		{`size(links) == 0 && metadata == {}`, true},
+	}
+	for _, tt := range tests {
+		p, err := Compile(tt.expr)
+		if err != nil {
+			t.Errorf("Compile(%q): %v", tt.expr, err)
+			continue
+		}
+		got, err := p.Eval(page)
+		if err != nil {
+			t.Errorf("Eval(%q): %v", tt.expr, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Eval(%q) = %v, expected %v", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	for expr, want := range map[string]string{
+		``:                "empty",
+		`status ==`:       "invalid expression",
+		`depth < 3`:       "undeclared reference",
+		`title + "!"`:     "must be true or false",
+		`status == "200"`: "no matching overload",
+	} {
+		if _, err := Compile(expr); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Compile(%q): expected an error containing %q, got %v", expr, want, err)
+		}
+	}
+}
+
+func TestEvalMissingKey(t *testing.T) {
+	p, err := Compile(`headers["X-Cache"] == "HIT"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.Eval(&models.PageData{}); err == nil {
+		t.Error("Expected an error for a missing header")
+	}
+}
@@ -0,0 +1,106 @@
+// internal/failpolicy/failpolicy.go
+package failpolicy
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DefaultExitCode is used when a run fails because of failed requests, so
+// scripts can tell it apart from usage and configuration errors (exit 1)
+const DefaultExitCode = 2
+
+// ErrAborted is reported for work skipped after the first failure in
+// fail-fast mode. Test with errors.Is.
+var ErrAborted = errors.New("aborted after first failure (--fail-fast)")
+
+// Policy decides whether failed requests fail the whole run. The zero value
+// fails on any failure with exit code 1.
+type Policy struct {
+	limit    float64 // failures allowed: a count, or a percentage when percent is set
+	percent  bool
+	FailFast bool // Stop starting new requests after the first failure
+	ExitCode int  // Process exit code when the policy fails the run
+}
+
+// Parse builds a Policy from the command-line flags. threshold is either a
+// percentage of requests ("5%") or a number of failures ("3") tolerated
+// before the run fails; empty or "0" tolerates none.
+func Parse(threshold string, failFast bool, exitCode int) (Policy, error) {
+	p := Policy{FailFast: failFast, ExitCode: exitCode}
+	if exitCode < 1 || exitCode > 125 {
+		return p, fmt.Errorf("invalid --fail-exit-code %d (must be 1-125)", exitCode)
+	}
+
+	s := strings.TrimSpace(threshold)
+	if s == "" {
+		return p, nil
+	}
+	if strings.HasSuffix(s, "%") {
+		pct, err := strconv.ParseFloat(strings.TrimSuffix(s, "%"), 64)
+		if err != nil || pct < 0 || pct > 100 {
+			return p, fmt.Errorf("invalid --fail-threshold %q (use a percentage such as 5%% or a count such as 3)", threshold)
+		}
+		p.limit, p.percent = pct, true
+		return p, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 {
+		return p, fmt.Errorf("invalid --fail-threshold %q (use a percentage such as 5%% or a count such as 3)", threshold)
+	}
+	p.limit = float64(n)
+	return p, nil
+}
+
+// Exceeded reports whether failed out of total requests is more than the policy allows
+func (p Policy) Exceeded(failed, total int) bool {
+	if failed <= 0 {
+		return false
+	}
+	if p.percent {
+		if total <= 0 {
+			return true
+		}
+		return float64(failed)*100/float64(total) > p.limit
+	}
+	return float64(failed) > p.limit
+}
+
+// Check returns an *ExitError when the run should fail, or nil when its
+// failures are within the threshold. aborted marks a fail-fast stop; in
+// fail-fast mode any failure fails the run.
+func (p Policy) Check(failed, total int, aborted bool) error {
+	aborted = aborted || (p.FailFast && failed > 0)
+	if !aborted && !p.Exceeded(failed, total) {
+		return nil
+	}
+	code := p.ExitCode
+	if code == 0 {
+		code = 1
+	}
+	return &ExitError{Code: code, Failed: failed, Total: total, Aborted: aborted}
+}
+
+// ExitError is returned by commands whose run failed under the policy.
+// main exits with Code.
+type ExitError struct {
+	Code    int
+	Failed  int
+	Total   int
+	Aborted bool
+}
+
+// ExitCode returns the process exit code for the failed run
+func (e *ExitError) ExitCode() int {
+	return e.Code
+}
+
+// Error implements the error interface
+func (e *ExitError) Error() string {
+	if e.Aborted {
+		return fmt.Sprintf("stopped after %d failure(s) (--fail-fast); %d request(s) attempted", e.Failed, e.Total)
+	}
+	return fmt.Sprintf("%d of %d request(s) failed", e.Failed, e.Total)
+}
@@ -0,0 +1,60 @@
+package failpolicy
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParse_PercentAndCount(t *testing.T) {
+	tests := []struct {
+		threshold     string
+		failed, total int
+		exceeded      bool
+	}{
+		{"", 0, 10, false},
+		{"", 1, 10, true},
+		{"0", 1, 100, true},
+		{"5%", 5, 100, false},
+		{"5%", 6, 100, true},
+		{"3", 3, 10, false},
+		{"3", 4, 1000, true},
+		{"100%", 10, 10, false},
+	}
+	for _, tt := range tests {
+		p, err := Parse(tt.threshold, false, DefaultExitCode)
+		if err != nil {
+			t.Fatalf("Parse(%q) failed: %v", tt.threshold, err)
+		}
+		if got := p.Exceeded(tt.failed, tt.total); got != tt.exceeded {
+			t.Errorf("Parse(%q).Exceeded(%d, %d) = %v, want %v", tt.threshold, tt.failed, tt.total, got, tt.exceeded)
+		}
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	for _, threshold := range []string{"abc", "-1", "150%", "x%"} {
+		if _, err := Parse(threshold, false, DefaultExitCode); err == nil {
+			t.Errorf("Expected error for threshold %q", threshold)
+		}
+	}
+	if _, err := Parse("", false, 0); err == nil {
+		t.Error("Expected error for exit code 0")
+	}
+}
+
+func TestCheck_ExitCode(t *testing.T) {
+	p, _ := Parse("10%", false, 4)
+	if err := p.Check(1, 20, false); err != nil {
+		t.Errorf("Expected failures within threshold to pass, got %v", err)
+	}
+
+	var exitErr *ExitError
+	if err := p.Check(5, 20, false); !errors.As(err, &exitErr) || exitErr.Code != 4 {
+		t.Errorf("Expected ExitError with code 4, got %v", err)
+	}
+
+	// Fail-fast stops fail the run even within the threshold
+	if err := p.Check(1, 20, true); !errors.As(err, &exitErr) || !exitErr.Aborted {
+		t.Errorf("Expected aborted ExitError, got %v", err)
+	}
+}
@@ -0,0 +1,237 @@
+// internal/forms/forms.go
+//
+// Package forms finds the forms on a page and encodes a filled-in form the
+// way a browser submits it, for search-driven sites whose content is only
+// reachable through a form.
+package forms
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/law-makers/crawl/pkg/models"
+)
+
+// csrfName matches field names used for anti-CSRF tokens by common frameworks
+var csrfName = regexp.MustCompile(`(?i)csrf|xsrf|authenticity_token|requestverificationtoken|^_token$|^__requestdigest$|nonce`)
+
+// Parse returns the forms in html. Relative actions are resolved against
+// pageURL; a form without one submits to the page itself.
+func Parse(html, pageURL string) ([]models.Form, error) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse HTML: %w", err)
+	}
+	base, _ := url.Parse(pageURL)
+	if href, ok := doc.Find("base[href]").First().Attr("href"); ok && base != nil {
+		if u, err := base.Parse(href); err == nil {
+			base = u
+		}
+	}
+
+	var found []models.Form
+	doc.Find("form").Each(func(i int, s *goquery.Selection) {
+		form := models.Form{
+			Index:   i,
+			Name:    attr(s, "name"),
+			ID:      attr(s, "id"),
+			Method:  strings.ToUpper(attr(s, "method")),
+			Enctype: attr(s, "enctype"),
+			Action:  pageURL,
+		}
+		if form.Method != "POST" {
+			form.Method = "GET"
+		}
+		if action := attr(s, "action"); action != "" && base != nil {
+			if u, err := base.Parse(action); err == nil {
+				form.Action = u.String()
+			}
+		}
+
+		index := map[string]int{}
+		s.Find("input, select, textarea, button").Each(func(_ int, c *goquery.Selection) {
+			name := attr(c, "name")
+			if name == "" || c.Is("[disabled]") {
+				return
+			}
+			field := models.FormField{Name: name, Required: c.Is("[required]")}
+			switch goquery.NodeName(c) {
+			case "select":
+				field.Type = "select"
+				c.Find("option").Each(func(_ int, o *goquery.Selection) {
+					v := optionValue(o)
+					field.Options = append(field.Options, v)
+					// The selected option, else the first as browsers do
+					if o.Is("[selected]") || (len(field.Options) == 1 && !c.Is("[multiple]")) {
+						field.Value = v
+					}
+				})
+			case "textarea":
+				field.Type = "textarea"
+				field.Value = c.Text()
+			case "button":
+				// Buttons are only submitted when clicked; list them as options
+				field.Type = "button"
+				if t := strings.ToLower(attr(c, "type")); t != "" && t != "submit" {
+					return
+				}
+				field.Options = []string{attr(c, "value")}
+			default:
+				field.Type = strings.ToLower(attr(c, "type"))
+				if field.Type == "" {
+					field.Type = "text"
+				}
+				switch field.Type {
+				case "image", "reset", "file":
+					return
+				case "submit":
+					field.Options = []string{attr(c, "value")}
+				case "radio", "checkbox":
+					v, ok := c.Attr("value")
+					if !ok {
+						v = "on"
+					}
+					if i, ok := index[name]; ok && form.Fields[i].Type == field.Type {
+						form.Fields[i].Options = append(form.Fields[i].Options, v)
+						if c.Is("[checked]") && form.Fields[i].Value == "" {
+							form.Fields[i].Value = v
+						}
+						return
+					}
+					field.Options = []string{v}
+					if c.Is("[checked]") {
+						field.Value = v
+					}
+				default:
+					field.Value = attr(c, "value")
+				}
+			}
+			if field.Type == "hidden" && csrfName.MatchString(name) {
+				form.CSRF = append(form.CSRF, name)
+			}
+			if _, ok := index[name]; !ok {
+				index[name] = len(form.Fields)
+			}
+			form.Fields = append(form.Fields, field)
+		})
+		found = append(found, form)
+	})
+	return found, nil
+}
+
+// Find picks a form by name, id or index. With an empty ref the page must
+// have exactly one form.
+func Find(forms []models.Form, ref string) (*models.Form, error) {
+	if len(forms) == 0 {
+		return nil, fmt.Errorf("the page has no forms")
+	}
+	if ref == "" {
+		if len(forms) == 1 {
+			return &forms[0], nil
+		}
+		return nil, fmt.Errorf("the page has %d forms; choose one with --form (name, id or index)", len(forms))
+	}
+	for i := range forms {
+		if forms[i].Name == ref || forms[i].ID == ref {
+			return &forms[i], nil
+		}
+	}
+	if n, err := strconv.Atoi(ref); err == nil && n >= 0 && n < len(forms) {
+		return &forms[n], nil
+	}
+	return nil, fmt.Errorf("no form named %q (the page has %d forms)", ref, len(forms))
+}
+
+// Values returns what the form submits with values filled in: each field's
+// current value, replaced by values for the fields named there. Submit
+// buttons are left out unless named in values. Names the form doesn't have
+// are added, since scripts often add fields before submitting.
+func Values(form *models.Form, values map[string]string) url.Values {
+	out := url.Values{}
+	for _, f := range form.Fields {
+		if _, ok := out[f.Name]; ok {
+			continue // repeated names (e.g. checkbox groups) submit once here
+		}
+		if v, ok := values[f.Name]; ok {
+			out.Set(f.Name, v)
+			continue
+		}
+		switch f.Type {
+		case "submit", "button":
+			continue
+		case "radio", "checkbox":
+			if f.Value == "" {
+				continue
+			}
+		}
+		out.Set(f.Name, f.Value)
+	}
+	for name, v := range values {
+		if _, ok := out[name]; !ok {
+			out.Set(name, v)
+		}
+	}
+	return out
+}
+
+// Request returns the method, URL and body a browser would send to submit
+// the form with vals, and the Content-Type of the body (empty for GET)
+func Request(form *models.Form, vals url.Values) (method, target string, body []byte, contentType string, err error) {
+	if form.Method == "GET" {
+		u, err := url.Parse(form.Action)
+		if err != nil {
+			return "", "", nil, "", fmt.Errorf("invalid form action %q: %w", form.Action, err)
+		}
+		u.RawQuery = vals.Encode()
+		u.Fragment = ""
+		return "GET", u.String(), nil, "", nil
+	}
+	if strings.EqualFold(form.Enctype, "multipart/form-data") {
+		return "", "", nil, "", fmt.Errorf("multipart forms (file uploads) are not supported; try --mode spa")
+	}
+	return "POST", form.Action, []byte(vals.Encode()), "application/x-www-form-urlencoded", nil
+}
+
+// Submission describes filling and submitting the form in a browser
+func Submission(form *models.Form, values map[string]string) *models.FormSubmission {
+	sub := &models.FormSubmission{Index: form.Index, Values: values}
+	switch {
+	case form.ID != "":
+		sub.Selector = fmt.Sprintf("form[id=%q]", form.ID)
+	case form.Name != "":
+		sub.Selector = fmt.Sprintf("form[name=%q]", form.Name)
+	}
+	return sub
+}
+
+// ParseValues parses name=value pairs separated by commas
+func ParseValues(s string) (map[string]string, error) {
+	values := map[string]string{}
+	for _, pair := range strings.Split(s, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("invalid form value %q: expected name=value", pair)
+		}
+		values[strings.TrimSpace(name)] = strings.TrimSpace(value)
+	}
+	return values, nil
+}
+
+func attr(s *goquery.Selection, name string) string {
+	v, _ := s.Attr(name)
+	return strings.TrimSpace(v)
+}
+
+func optionValue(o *goquery.Selection) string {
+	if v, ok := o.Attr("value"); ok {
+		return v
+	}
+	return strings.TrimSpace(o.Text())
+}
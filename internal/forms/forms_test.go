@@ -0,0 +1,114 @@
+package forms
+
+import (
+	"net/url"
+	"testing"
+)
+
+const page = `<html><head><base href="/app/"></head><body>
+<form id="search" action="results" method="post">
+  <input type="hidden" name="authenticity_token" value="tok">
+  <input name="q" required>
+  <select name="cat"><option value="all">All</option><option value="books" selected>Books</option></select>
+  <input type="radio" name="sort" value="new"><input type="radio" name="sort" value="old" checked>
+  <input type="checkbox" name="exact">
+  <input type="text" name="off" disabled value="x">
+  <textarea name="note">hi</textarea>
+  <input type="submit" name="go" value="Search">
+</form>
+<form name="nl"><input type="email" name="email"></form>
+</body></html>`
+
+func TestParse(t *testing.T) {
+	found, err := Parse(page, "https://example.com/index.html")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(found) != 2 {
+		t.Fatalf("Expected 2 forms, got %d", len(found))
+	}
+	f := found[0]
+	if f.ID != "search" || f.Method != "POST" || f.Action != "https://example.com/app/results" {
+		t.Errorf("Unexpected form: %+v", f)
+	}
+	if len(f.CSRF) != 1 || f.CSRF[0] != "authenticity_token" {
+		t.Errorf("Expected the CSRF token to be flagged, got %v", f.CSRF)
+	}
+	fields := map[string]string{}
+	for _, field := range f.Fields {
+		fields[field.Name] = field.Type + "=" + field.Value
+	}
+	want := map[string]string{
+		"authenticity_token": "hidden=tok",
+		"q":                  "text=",
+		"cat":                "select=books",
+		"sort":               "radio=old",
+		"exact":              "checkbox=",
+		"note":               "textarea=hi",
+		"go":                 "submit=",
+	}
+	for name, w := range want {
+		if fields[name] != w {
+			t.Errorf("Field %s: expected %q, got %q", name, w, fields[name])
+		}
+	}
+	if _, ok := fields["off"]; ok {
+		t.Error("Expected disabled fields to be left out")
+	}
+	if nl := found[1]; nl.Method != "GET" || nl.Action != "https://example.com/index.html" {
+		t.Errorf("Expected a GET form submitting to the page, got %+v", nl)
+	}
+}
+
+func TestFind(t *testing.T) {
+	found, _ := Parse(page, "https://example.com/")
+	if _, err := Find(found, ""); err == nil {
+		t.Error("Expected an error choosing between two forms")
+	}
+	for _, ref := range []string{"search", "nl", "1"} {
+		if _, err := Find(found, ref); err != nil {
+			t.Errorf("Find(%q) failed: %v", ref, err)
+		}
+	}
+	if _, err := Find(found, "missing"); err == nil {
+		t.Error("Expected an error for an unknown form")
+	}
+}
+
+func TestValuesAndRequest(t *testing.T) {
+	found, _ := Parse(page, "https://example.com/")
+	form := &found[0]
+	vals := Values(form, map[string]string{"q": "crawl", "extra": "1"})
+	want := url.Values{
+		"authenticity_token": {"tok"},
+		"q":                  {"crawl"},
+		"cat":                {"books"},
+		"sort":               {"old"},
+		"note":               {"hi"},
+		"extra":              {"1"},
+	}
+	if vals.Encode() != want.Encode() {
+		t.Errorf("Expected %s, got %s", want.Encode(), vals.Encode())
+	}
+
+	method, target, body, contentType, err := Request(form, vals)
+	if err != nil || method != "POST" || target != "https://example.com/app/results" || contentType != "application/x-www-form-urlencoded" || string(body) != vals.Encode() {
+		t.Errorf("Unexpected POST request: %s %s %q %q %v", method, target, body, contentType, err)
+	}
+
+	nl := &found[1]
+	method, target, body, _, err = Request(nl, Values(nl, map[string]string{"email": "a@b.c"}))
+	if err != nil || method != "GET" || target != "https://example.com/?email=a%40b.c" || body != nil {
+		t.Errorf("Unexpected GET request: %s %s %q %v", method, target, body, err)
+	}
+}
+
+func TestParseValues(t *testing.T) {
+	values, err := ParseValues("q=climate bill, cat=books,")
+	if err != nil || values["q"] != "climate bill" || values["cat"] != "books" {
+		t.Errorf("Unexpected values %v (%v)", values, err)
+	}
+	if _, err := ParseValues("novalue"); err == nil {
+		t.Error("Expected an error without =")
+	}
+}
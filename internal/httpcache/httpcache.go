@@ -0,0 +1,435 @@
+// internal/httpcache/httpcache.go
+//
+// Package httpcache is an in-memory HTTP cache that follows the origin's
+// caching headers (RFC 9111, formerly RFC 7234) instead of a fixed TTL:
+// Cache-Control and Expires decide how long a response is fresh, Vary
+// decides which request headers select a stored variant, and stale
+// responses with a validator are revalidated with a conditional request.
+//
+// It behaves as a private cache, since crawl serves one user: responses
+// marked private are stored, s-maxage is ignored.
+package httpcache
+
+import (
+	"bytes"
+	"container/list"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/law-makers/crawl/internal/proxy"
+)
+
+// XFromCache is set on responses served from the cache, to Hit when the
+// stored response was fresh or Revalidated when the origin answered a
+// conditional request with 304 Not Modified
+const XFromCache = "X-From-Cache"
+
+// Values of the XFromCache header
+const (
+	Hit         = "hit"
+	Revalidated = "revalidated"
+)
+
+// heuristicStatus are the statuses that may be cached without explicit
+// freshness, using the Last-Modified heuristic (RFC 9111 section 4.2.2)
+var heuristicStatus = map[int]bool{
+	200: true, 203: true, 204: true, 300: true, 301: true, 308: true,
+	404: true, 405: true, 410: true, 414: true, 501: true,
+}
+
+// maxHeuristic caps the freshness guessed from Last-Modified
+const maxHeuristic = 24 * time.Hour
+
+// DefaultMaxEntryBytes caps a single stored response. Larger responses are
+// streamed through without being buffered.
+const DefaultMaxEntryBytes = 8 << 20
+
+// Transport is an http.RoundTripper that answers from the cache when it can
+type Transport struct {
+	next     http.RoundTripper
+	maxBytes int64
+	maxEntry int64
+	now      func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*list.Element // key: URL plus variant
+	vary    map[string][]string      // URL -> header names its responses vary on
+	lru     *list.List
+	size    int64
+}
+
+// entry is a stored response
+type entry struct {
+	key          string
+	status       int
+	proto        string
+	header       http.Header
+	body         []byte
+	requestTime  time.Time
+	responseTime time.Time
+}
+
+// size approximates the memory an entry holds
+func (e *entry) size() int64 {
+	n := int64(len(e.body))
+	for name, values := range e.header {
+		for _, v := range values {
+			n += int64(len(name) + len(v))
+		}
+	}
+	return n
+}
+
+// New wraps next in a cache holding up to maxBytes of responses (0 for no
+// limit). No single response larger than DefaultMaxEntryBytes, or maxBytes
+// if that is smaller, is stored.
+func New(next http.RoundTripper, maxBytes int64) *Transport {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	maxEntry := int64(DefaultMaxEntryBytes)
+	if maxBytes > 0 && maxBytes < maxEntry {
+		maxEntry = maxBytes
+	}
+	return &Transport{
+		next:     next,
+		maxBytes: maxBytes,
+		maxEntry: maxEntry,
+		now:      time.Now,
+		entries:  map[string]*list.Element{},
+		vary:     map[string][]string{},
+		lru:      list.New(),
+	}
+}
+
+// RoundTrip serves req from the cache when a fresh response is stored,
+// revalidates a stale one, or fetches and stores the response
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet || req.Header.Get("Range") != "" {
+		return t.next.RoundTrip(req)
+	}
+	reqCC := parseCacheControl(req.Header.Get("Cache-Control"))
+	if _, ok := reqCC["no-store"]; ok {
+		return t.next.RoundTrip(req)
+	}
+
+	key := t.key(req)
+	cached, stored := t.lookup(key, req)
+	if stored != nil {
+		age := t.age(cached, stored)
+		_, reqNoCache := reqCC["no-cache"]
+		if !reqNoCache && !mustRevalidate(cached) && withinRequestLimits(reqCC, age, freshness(cached)) {
+			cached.Header.Set("Age", strconv.Itoa(int(age.Seconds())))
+			cached.Header.Set(XFromCache, Hit)
+			return cached, nil
+		}
+		if etag, lastMod := cached.Header.Get("ETag"), cached.Header.Get("Last-Modified"); etag != "" || lastMod != "" {
+			cond := req.Clone(req.Context())
+			if etag != "" {
+				cond.Header.Set("If-None-Match", etag)
+			}
+			if lastMod != "" {
+				cond.Header.Set("If-Modified-Since", lastMod)
+			}
+			req = cond
+		} else {
+			cached = nil
+		}
+	}
+
+	requestTime := t.now()
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	responseTime := t.now()
+
+	if resp.StatusCode == http.StatusNotModified && cached != nil {
+		// Refresh the stored headers and serve the stored body
+		resp.Body.Close()
+		for name, values := range resp.Header {
+			if !strings.EqualFold(name, "Content-Length") {
+				cached.Header[name] = values
+			}
+		}
+		body, _ := io.ReadAll(cached.Body)
+		t.store(req, cached, body, requestTime, responseTime)
+		cached.Body = io.NopCloser(bytes.NewReader(body))
+		cached.Header.Set(XFromCache, Revalidated)
+		return cached, nil
+	}
+	if !storable(resp) || resp.ContentLength > t.maxEntry {
+		return resp, nil
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, t.maxEntry+1))
+	if err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	if int64(len(body)) > t.maxEntry {
+		// Too large to store: hand back what was read followed by the rest
+		resp.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(body), resp.Body), resp.Body}
+		return resp, nil
+	}
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	t.store(req, resp, body, requestTime, responseTime)
+	return resp, nil
+}
+
+// Clear drops every stored response
+func (t *Transport) Clear() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.entries = map[string]*list.Element{}
+	t.vary = map[string][]string{}
+	t.lru.Init()
+	t.size = 0
+}
+
+// key returns the cache key for req: its URL plus the values of the request
+// headers earlier responses for the URL varied on
+func (t *Transport) key(req *http.Request) string {
+	url := resource(req)
+	t.mu.Lock()
+	names := t.vary[url]
+	t.mu.Unlock()
+	return variantKey(url, names, req.Header)
+}
+
+// resource identifies what req asks for: its URL, and the proxy it goes
+// through, since a site may answer differently by location
+func resource(req *http.Request) string {
+	if p := proxy.FromContext(req.Context()); p != nil {
+		return req.URL.String() + "\x00proxy=" + p.String()
+	}
+	return req.URL.String()
+}
+
+func variantKey(url string, names []string, h http.Header) string {
+	var b strings.Builder
+	b.WriteString(url)
+	for _, name := range names {
+		b.WriteString("\x00")
+		b.WriteString(strings.ToLower(name))
+		b.WriteString("=")
+		b.WriteString(strings.Join(h.Values(name), ","))
+	}
+	return b.String()
+}
+
+// lookup returns the stored response for key, rebuilt for req, and its entry
+func (t *Transport) lookup(key string, req *http.Request) (*http.Response, *entry) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	el, ok := t.entries[key]
+	if !ok {
+		return nil, nil
+	}
+	t.lru.MoveToFront(el)
+	e := el.Value.(*entry)
+	return e.response(req), e
+}
+
+// response rebuilds the stored response as the answer to req
+func (e *entry) response(req *http.Request) *http.Response {
+	proto := e.proto
+	if proto == "" {
+		proto = "HTTP/1.1"
+	}
+	major, minor, _ := http.ParseHTTPVersion(proto)
+	return &http.Response{
+		Status:        strconv.Itoa(e.status) + " " + http.StatusText(e.status),
+		StatusCode:    e.status,
+		Proto:         proto,
+		ProtoMajor:    major,
+		ProtoMinor:    minor,
+		Header:        e.header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(e.body)),
+		ContentLength: int64(len(e.body)),
+		Request:       req,
+	}
+}
+
+// store saves resp with its body, evicting the least recently used
+// responses over the limit
+func (t *Transport) store(req *http.Request, resp *http.Response, body []byte, requestTime, responseTime time.Time) {
+	header := resp.Header.Clone()
+	header.Del(XFromCache)
+	e := &entry{
+		status:       resp.StatusCode,
+		proto:        resp.Proto,
+		header:       header,
+		body:         body,
+		requestTime:  requestTime,
+		responseTime: responseTime,
+	}
+
+	url := resource(req)
+	names := varyNames(resp.Header)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.vary[url] = names
+	e.key = variantKey(url, names, req.Header)
+	if el, ok := t.entries[e.key]; ok {
+		t.remove(el)
+	}
+	if t.maxBytes > 0 && e.size() > t.maxBytes {
+		return
+	}
+	t.entries[e.key] = t.lru.PushFront(e)
+	t.size += e.size()
+	for t.maxBytes > 0 && t.size > t.maxBytes {
+		t.remove(t.lru.Back())
+	}
+}
+
+func (t *Transport) remove(el *list.Element) {
+	e := el.Value.(*entry)
+	t.lru.Remove(el)
+	delete(t.entries, e.key)
+	t.size -= e.size()
+}
+
+// age is the stored response's current age (RFC 9111 section 4.2.3)
+func (t *Transport) age(resp *http.Response, e *entry) time.Duration {
+	apparent := time.Duration(0)
+	if date, err := http.ParseTime(resp.Header.Get("Date")); err == nil && e.responseTime.After(date) {
+		apparent = e.responseTime.Sub(date)
+	}
+	ageValue := time.Duration(0)
+	if s, err := strconv.Atoi(resp.Header.Get("Age")); err == nil && s > 0 {
+		ageValue = time.Duration(s) * time.Second
+	}
+	corrected := ageValue + e.responseTime.Sub(e.requestTime)
+	if apparent > corrected {
+		corrected = apparent
+	}
+	return corrected + t.now().Sub(e.responseTime)
+}
+
+// freshness is how long a response stays fresh after it was generated
+func freshness(resp *http.Response) time.Duration {
+	cc := parseCacheControl(resp.Header.Get("Cache-Control"))
+	if v, ok := cc["max-age"]; ok {
+		if s, err := strconv.Atoi(v); err == nil {
+			return time.Duration(s) * time.Second
+		}
+		return 0
+	}
+	date, dateErr := http.ParseTime(resp.Header.Get("Date"))
+	if h := resp.Header.Get("Expires"); h != "" {
+		expires, err := http.ParseTime(h)
+		if err != nil || dateErr != nil {
+			return 0 // invalid Expires means already expired
+		}
+		return expires.Sub(date)
+	}
+	if lastMod, err := http.ParseTime(resp.Header.Get("Last-Modified")); err == nil && dateErr == nil && heuristicStatus[resp.StatusCode] {
+		if guess := date.Sub(lastMod) / 10; guess > 0 {
+			return min(guess, maxHeuristic)
+		}
+	}
+	return 0
+}
+
+// mustRevalidate reports whether the response may only be used after
+// checking with the origin
+func mustRevalidate(resp *http.Response) bool {
+	_, ok := parseCacheControl(resp.Header.Get("Cache-Control"))["no-cache"]
+	return ok || resp.Header.Get("Pragma") == "no-cache" && resp.Header.Get("Cache-Control") == ""
+}
+
+// withinRequestLimits applies freshness and the request's max-age,
+// min-fresh and max-stale directives
+func withinRequestLimits(reqCC map[string]string, age, fresh time.Duration) bool {
+	seconds := func(name string) (time.Duration, bool) {
+		v, ok := reqCC[name]
+		if !ok {
+			return 0, false
+		}
+		s, err := strconv.Atoi(v)
+		if err != nil {
+			return 0, true
+		}
+		return time.Duration(s) * time.Second, true
+	}
+	if maxAge, ok := seconds("max-age"); ok && age > maxAge {
+		return false
+	}
+	if minFresh, ok := seconds("min-fresh"); ok {
+		age += minFresh
+	}
+	if v, ok := reqCC["max-stale"]; ok {
+		if v == "" {
+			return true // any staleness is acceptable
+		}
+		maxStale, _ := seconds("max-stale")
+		fresh += maxStale
+	}
+	return age < fresh
+}
+
+// storable reports whether a response may be stored
+func storable(resp *http.Response) bool {
+	cc := parseCacheControl(resp.Header.Get("Cache-Control"))
+	if _, ok := cc["no-store"]; ok {
+		return false
+	}
+	for _, name := range varyNames(resp.Header) {
+		if name == "*" {
+			return false
+		}
+	}
+	if _, ok := cc["max-age"]; ok {
+		return true
+	}
+	if _, ok := cc["no-cache"]; ok {
+		return true
+	}
+	if resp.Header.Get("Expires") != "" {
+		return true
+	}
+	if _, ok := cc["public"]; ok {
+		return true
+	}
+	// Heuristic freshness, or a validator to revalidate with
+	return heuristicStatus[resp.StatusCode] && (resp.Header.Get("Last-Modified") != "" || resp.Header.Get("ETag") != "")
+}
+
+// varyNames lists the header names in Vary, canonicalised, in the order
+// they appear
+func varyNames(h http.Header) []string {
+	var names []string
+	for _, v := range h.Values("Vary") {
+		for _, name := range strings.Split(v, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				names = append(names, http.CanonicalHeaderKey(name))
+			}
+		}
+	}
+	return names
+}
+
+// parseCacheControl splits a Cache-Control header into lowercased
+// directives and their (unquoted) values
+func parseCacheControl(h string) map[string]string {
+	cc := map[string]string{}
+	for _, part := range strings.Split(h, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, value, _ := strings.Cut(part, "=")
+		cc[strings.ToLower(strings.TrimSpace(name))] = strings.Trim(strings.TrimSpace(value), `"`)
+	}
+	return cc
+}
@@ -0,0 +1,246 @@
+package httpcache
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fetch GETs url through tr with the given request headers, returning the
+// body and the XFromCache state
+func fetch(t *testing.T, tr http.RoundTripper, url string, header ...string) (string, string) {
+	t.Helper()
+	req, _ := http.NewRequest(http.MethodGet, url, nil)
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+	resp, err := tr.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("RoundTrip failed: %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	return string(body), resp.Header.Get(XFromCache)
+}
+
+func TestTransport_MaxAge(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.Header().Set("Cache-Control", "max-age=60")
+		io.WriteString(w, "hello")
+	}))
+	defer srv.Close()
+
+	now := time.Now()
+	tr := New(nil, 0)
+	tr.now = func() time.Time { return now }
+
+	if body, state := fetch(t, tr, srv.URL); body != "hello" || state != "" {
+		t.Errorf("Expected a network response, got %q (%q)", body, state)
+	}
+	if body, state := fetch(t, tr, srv.URL); body != "hello" || state != Hit {
+		t.Errorf("Expected a cache hit, got %q (%q)", body, state)
+	}
+	if hits.Load() != 1 {
+		t.Errorf("Expected 1 request to the origin, got %d", hits.Load())
+	}
+
+	now = now.Add(2 * time.Minute)
+	if _, state := fetch(t, tr, srv.URL); state != "" {
+		t.Errorf("Expected a stale response to be refetched, got %q", state)
+	}
+	if hits.Load() != 2 {
+		t.Errorf("Expected 2 requests to the origin, got %d", hits.Load())
+	}
+}
+
+func TestTransport_NoStore(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.Header().Set("Cache-Control", "no-store, max-age=60")
+		io.WriteString(w, "secret")
+	}))
+	defer srv.Close()
+
+	tr := New(nil, 0)
+	fetch(t, tr, srv.URL)
+	fetch(t, tr, srv.URL)
+	if hits.Load() != 2 {
+		t.Errorf("Expected no-store responses not to be cached, got %d requests", hits.Load())
+	}
+}
+
+func TestTransport_Revalidate(t *testing.T) {
+	var hits, notModified atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("ETag", `"v1"`)
+		io.WriteString(w, "body")
+	}))
+	defer srv.Close()
+
+	tr := New(nil, 0)
+	fetch(t, tr, srv.URL)
+	body, state := fetch(t, tr, srv.URL)
+	if body != "body" || state != Revalidated {
+		t.Errorf("Expected the stored body after revalidation, got %q (%q)", body, state)
+	}
+	if hits.Load() != 2 || notModified.Load() != 1 {
+		t.Errorf("Expected one conditional request, got %d requests and %d 304s", hits.Load(), notModified.Load())
+	}
+}
+
+func TestTransport_Expires(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		now := time.Now().UTC()
+		w.Header().Set("Date", now.Format(http.TimeFormat))
+		w.Header().Set("Expires", now.Add(time.Hour).Format(http.TimeFormat))
+		io.WriteString(w, "page")
+	}))
+	defer srv.Close()
+
+	tr := New(nil, 0)
+	fetch(t, tr, srv.URL)
+	if _, state := fetch(t, tr, srv.URL); state != Hit {
+		t.Errorf("Expected Expires to make the response fresh, got %q", state)
+	}
+	if hits.Load() != 1 {
+		t.Errorf("Expected 1 request to the origin, got %d", hits.Load())
+	}
+}
+
+func TestTransport_Vary(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.Header().Set("Cache-Control", "max-age=60")
+		w.Header().Set("Vary", "Accept-Language")
+		io.WriteString(w, r.Header.Get("Accept-Language"))
+	}))
+	defer srv.Close()
+
+	tr := New(nil, 0)
+	fetch(t, tr, srv.URL, "Accept-Language", "en")
+	fetch(t, tr, srv.URL, "Accept-Language", "fr")
+	if body, state := fetch(t, tr, srv.URL, "Accept-Language", "en"); body != "en" || state != Hit {
+		t.Errorf("Expected the cached en variant, got %q (%q)", body, state)
+	}
+	if body, state := fetch(t, tr, srv.URL, "Accept-Language", "fr"); body != "fr" || state != Hit {
+		t.Errorf("Expected the cached fr variant, got %q (%q)", body, state)
+	}
+	if hits.Load() != 2 {
+		t.Errorf("Expected one request per variant, got %d", hits.Load())
+	}
+}
+
+func TestTransport_RequestNoCache(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.Header().Set("Cache-Control", "max-age=60")
+		io.WriteString(w, "x")
+	}))
+	defer srv.Close()
+
+	tr := New(nil, 0)
+	fetch(t, tr, srv.URL)
+	if _, state := fetch(t, tr, srv.URL, "Cache-Control", "max-age=0"); state == Hit {
+		t.Error("Expected max-age=0 in the request to bypass the stored response")
+	}
+	if hits.Load() != 2 {
+		t.Errorf("Expected 2 requests to the origin, got %d", hits.Load())
+	}
+}
+
+func TestTransport_Evicts(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "max-age=60")
+		w.Write(make([]byte, 1000))
+	}))
+	defer srv.Close()
+
+	tr := New(nil, 1500)
+	fetch(t, tr, srv.URL+"/a")
+	fetch(t, tr, srv.URL+"/b")
+	if _, state := fetch(t, tr, srv.URL+"/a"); state == Hit {
+		t.Error("Expected /a to be evicted over the size limit")
+	}
+	if _, state := fetch(t, tr, srv.URL+"/a"); state != Hit {
+		t.Error("Expected /a to be cached again")
+	}
+}
+
+func TestTransport_SkipsOversized(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.Header().Set("Cache-Control", "max-age=60")
+		if r.URL.Path == "/chunked" {
+			// Flushing first leaves the length unknown until the body is read
+			w.(http.Flusher).Flush()
+		}
+		io.WriteString(w, strings.Repeat("x", 3000))
+	}))
+	defer srv.Close()
+
+	tr := New(nil, 1000)
+	for _, path := range []string{"/sized", "/chunked"} {
+		for i := 0; i < 2; i++ {
+			body, state := fetch(t, tr, srv.URL+path)
+			if len(body) != 3000 {
+				t.Errorf("%s: expected the full body, got %d bytes", path, len(body))
+			}
+			if state != "" {
+				t.Errorf("%s: expected a response over the limit not to be cached, got %q", path, state)
+			}
+		}
+	}
+	if hits.Load() != 4 {
+		t.Errorf("Expected 4 requests to the origin, got %d", hits.Load())
+	}
+	if tr.size != 0 {
+		t.Errorf("Expected nothing stored, got %d bytes", tr.size)
+	}
+}
+
+func TestFreshness(t *testing.T) {
+	date := time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		header http.Header
+		want   time.Duration
+	}{
+		{"max-age", http.Header{"Cache-Control": {"public, max-age=300"}}, 5 * time.Minute},
+		{"max-age wins over Expires", http.Header{
+			"Cache-Control": {"max-age=10"},
+			"Date":          {date.Format(http.TimeFormat)},
+			"Expires":       {date.Add(time.Hour).Format(http.TimeFormat)},
+		}, 10 * time.Second},
+		{"invalid Expires", http.Header{"Date": {date.Format(http.TimeFormat)}, "Expires": {"0"}}, 0},
+		{"heuristic", http.Header{
+			"Date":          {date.Format(http.TimeFormat)},
+			"Last-Modified": {date.Add(-10 * time.Hour).Format(http.TimeFormat)},
+		}, time.Hour},
+		{"none", http.Header{}, 0},
+	}
+	for _, tt := range tests {
+		resp := &http.Response{StatusCode: http.StatusOK, Header: tt.header}
+		if got := freshness(resp); got != tt.want {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, got)
+		}
+	}
+}
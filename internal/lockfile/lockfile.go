@@ -0,0 +1,170 @@
+// internal/lockfile/lockfile.go
+package lockfile
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// DirName is the lock file created inside a locked output directory
+const DirName = ".crawl.lock"
+
+// StaleAfter is how long a lock may go without a heartbeat before it is
+// considered abandoned, e.g. by a run on another machine that crashed
+const StaleAfter = 5 * time.Minute
+
+// heartbeat is how often a held lock's modification time is refreshed
+var heartbeat = time.Minute
+
+// Owner describes the run holding a lock
+type Owner struct {
+	PID  int    `json:"pid"`
+	Host string `json:"host"`
+	// Command names the program and subcommand only; arguments are left
+	// out, as flag values may hold credentials
+	Command string    `json:"command"`
+	Started time.Time `json:"started"`
+}
+
+// HeldError is returned when another live run holds the lock
+type HeldError struct {
+	Target string
+	Owner  Owner
+}
+
+func (e *HeldError) Error() string {
+	age := time.Since(e.Owner.Started).Round(time.Second)
+	return fmt.Sprintf("another crawl run is using %s (pid %d on %s, started %s ago: %s); wait for it to finish or pass --force",
+		e.Target, e.Owner.PID, e.Owner.Host, age, e.Owner.Command)
+}
+
+// Lock is an advisory lock on an output target. It only keeps out other
+// crawl runs that check it; it does not stop anything else writing.
+type Lock struct {
+	path string
+	stop chan struct{}
+	once sync.Once
+}
+
+// PathFor returns the lock file for a target: a file inside it for a
+// directory, or a sibling "<file>.lock" for a file
+func PathFor(target string, isDir bool) string {
+	if isDir {
+		return filepath.Join(target, DirName)
+	}
+	return target + ".lock"
+}
+
+// Acquire locks target for this run. command names the run in the lock,
+// e.g. "crawl get", and defaults to the program name. A lock left by a run
+// that has exited, or that has not been refreshed within StaleAfter, is
+// taken over; so is any lock when force is set. Otherwise it returns a
+// *HeldError.
+func Acquire(target, command string, isDir, force bool) (*Lock, error) {
+	if isDir {
+		if err := os.MkdirAll(target, 0755); err != nil {
+			return nil, fmt.Errorf("failed to create output directory: %w", err)
+		}
+	}
+	path := PathFor(target, isDir)
+	owner := currentOwner(command)
+	data, err := json.Marshal(owner)
+	if err != nil {
+		return nil, err
+	}
+
+	for attempt := 0; attempt < 2; attempt++ {
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if err == nil {
+			_, werr := f.Write(data)
+			if cerr := f.Close(); werr == nil {
+				werr = cerr
+			}
+			if werr != nil {
+				os.Remove(path)
+				return nil, fmt.Errorf("failed to write lock file: %w", werr)
+			}
+			l := &Lock{path: path, stop: make(chan struct{})}
+			go l.refresh()
+			return l, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, fmt.Errorf("failed to create lock file: %w", err)
+		}
+
+		held, stale := inspect(path)
+		if !stale && !force {
+			return nil, &HeldError{Target: target, Owner: held}
+		}
+		// Take over: remove it and race any other run for a fresh create
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("failed to remove stale lock file: %w", err)
+		}
+	}
+	held, _ := inspect(path)
+	return nil, &HeldError{Target: target, Owner: held}
+}
+
+// inspect reads a lock file and reports whether it is stale
+func inspect(path string) (Owner, bool) {
+	var owner Owner
+	info, err := os.Stat(path)
+	if err != nil {
+		return owner, true // already gone
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || json.Unmarshal(data, &owner) != nil {
+		// Unreadable, or caught mid-write: stale only once it stops changing
+		return owner, time.Since(info.ModTime()) > StaleAfter
+	}
+	if host, _ := os.Hostname(); owner.Host == host && !processAlive(owner.PID) {
+		return owner, true
+	}
+	return owner, time.Since(info.ModTime()) > StaleAfter
+}
+
+// refresh touches the lock file until it is released, so other machines
+// sharing the target can tell a live run from a crashed one
+func (l *Lock) refresh() {
+	ticker := time.NewTicker(heartbeat)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-ticker.C:
+			now := time.Now()
+			_ = os.Chtimes(l.path, now, now)
+		}
+	}
+}
+
+// Release removes the lock. It is safe to call more than once.
+func (l *Lock) Release() error {
+	var err error
+	l.once.Do(func() {
+		close(l.stop)
+		if rerr := os.Remove(l.path); rerr != nil && !errors.Is(rerr, os.ErrNotExist) {
+			err = fmt.Errorf("failed to remove lock file: %w", rerr)
+		}
+	})
+	return err
+}
+
+func currentOwner(command string) Owner {
+	if command == "" {
+		command = filepath.Base(os.Args[0])
+	}
+	host, _ := os.Hostname()
+	return Owner{
+		PID:     os.Getpid(),
+		Host:    host,
+		Command: command,
+		Started: time.Now().UTC(),
+	}
+}
@@ -0,0 +1,19 @@
+// internal/lockfile/process_unix.go
+//go:build !windows
+
+package lockfile
+
+import (
+	"errors"
+	"syscall"
+)
+
+// processAlive reports whether a process with this pid exists
+func processAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	err := syscall.Kill(pid, 0)
+	// EPERM means it exists but belongs to another user
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
@@ -0,0 +1,91 @@
+// internal/logging/logfmt.go
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/rs/zerolog"
+)
+
+// logfmtWriter turns zerolog's JSON events into logfmt lines
+type logfmtWriter struct {
+	mu  sync.Mutex
+	out io.Writer
+}
+
+// NewLogfmtWriter returns a writer for zerolog that prints each event as
+// key=value pairs: time, level and message first, then the other fields
+// sorted by name
+func NewLogfmtWriter(out io.Writer) io.Writer {
+	return &logfmtWriter{out: out}
+}
+
+// leading are the fields printed first, in this order
+var leading = []string{zerolog.TimestampFieldName, zerolog.LevelFieldName, zerolog.MessageFieldName}
+
+func (w *logfmtWriter) Write(p []byte) (int, error) {
+	var event map[string]interface{}
+	d := json.NewDecoder(bytes.NewReader(p))
+	d.UseNumber()
+	if err := d.Decode(&event); err != nil {
+		return 0, fmt.Errorf("cannot decode event: %w", err)
+	}
+
+	var buf bytes.Buffer
+	for _, key := range leading {
+		if v, ok := event[key]; ok {
+			writePair(&buf, key, v)
+			delete(event, key)
+		}
+	}
+	keys := make([]string, 0, len(event))
+	for key := range event {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		writePair(&buf, key, event[key])
+	}
+	buf.WriteByte('\n')
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, err := w.out.Write(buf.Bytes()); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func writePair(buf *bytes.Buffer, key string, v interface{}) {
+	if buf.Len() > 0 {
+		buf.WriteByte(' ')
+	}
+	buf.WriteString(key)
+	buf.WriteByte('=')
+
+	var s string
+	switch v := v.(type) {
+	case string:
+		s = v
+	case json.Number:
+		buf.WriteString(v.String())
+		return
+	case nil:
+		return
+	default:
+		// Nested objects and arrays stay JSON
+		raw, _ := json.Marshal(v)
+		s = string(raw)
+	}
+	if s == "" || strings.ContainsAny(s, " =\"\t\n\\") {
+		s = strconv.Quote(s)
+	}
+	buf.WriteString(s)
+}
@@ -0,0 +1,140 @@
+// internal/logging/logging.go
+//
+// Package logging configures the global zerolog logger from the config:
+// level, format (console, json or logfmt) and destination (stderr or a
+// rotating file). It also names the fields that describe a request, so
+// every package logs them the same way.
+package logging
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/law-makers/crawl/pkg/models"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// Log formats
+const (
+	FormatConsole = "console" // human-friendly, colored on a terminal
+	FormatJSON    = "json"    // one JSON object per line
+	FormatLogfmt  = "logfmt"  // key=value pairs, one event per line
+)
+
+// Field names shared by every package, so the events about one request or
+// one run can be filtered on them
+const (
+	FieldURL     = "url"
+	FieldDomain  = "domain"
+	FieldEngine  = "engine"  // static or dynamic, as in the request log
+	FieldAttempt = "attempt" // try number once a request is retried
+	FieldJobID   = "job_id"  // identifies the run; set CRAWL_JOB_ID to choose it
+
+	FieldRequestID = "request_id" // identifies one page request and its retries
+)
+
+// Options configures the global logger
+type Options struct {
+	Level      string // debug, info, warn or error
+	Format     string // console, json or logfmt
+	File       string // Log to this file instead of stderr
+	MaxSize    int64  // Rotate the file once it would grow past this many bytes (0 never)
+	MaxBackups int    // Rotated files to keep, as File.1, File.2, ...
+	NoColor    bool
+	JobID      string // Added to every event; empty leaves it out
+}
+
+// ValidFormat reports whether format is a known log format ("" is console)
+func ValidFormat(format string) bool {
+	switch format {
+	case "", FormatConsole, FormatJSON, FormatLogfmt:
+		return true
+	}
+	return false
+}
+
+// Setup points the global logger at the configured output. The returned
+// function closes the log file, if any.
+func Setup(opts Options) (func() error, error) {
+	var out io.Writer = os.Stderr
+	closeFn := func() error { return nil }
+	if opts.File != "" {
+		f, err := OpenRotating(opts.File, opts.MaxSize, opts.MaxBackups)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open log file: %w", err)
+		}
+		out, closeFn = f, f.Close
+	}
+
+	zerolog.SetGlobalLevel(level(opts.Level, opts.File != ""))
+
+	var w io.Writer
+	switch opts.Format {
+	case FormatJSON:
+		w = out
+	case FormatLogfmt:
+		w = NewLogfmtWriter(out)
+	case "", FormatConsole:
+		w = zerolog.ConsoleWriter{Out: out, NoColor: opts.NoColor || opts.File != ""}
+	default:
+		closeFn()
+		return nil, fmt.Errorf("invalid log format %q (must be console, json or logfmt)", opts.Format)
+	}
+
+	ctx := zerolog.New(w).With().Timestamp()
+	if opts.JobID != "" {
+		ctx = ctx.Str(FieldJobID, opts.JobID)
+	}
+	log.Logger = ctx.Logger()
+	return closeFn, nil
+}
+
+// level maps a configured level to zerolog's. On the terminal info events
+// are hidden unless asked for with -v, as they would interleave with
+// progress output; a log file gets them.
+func level(name string, toFile bool) zerolog.Level {
+	switch strings.ToLower(name) {
+	case "debug":
+		return zerolog.DebugLevel
+	case "warn":
+		return zerolog.WarnLevel
+	case "error":
+		return zerolog.ErrorLevel
+	}
+	if toFile {
+		return zerolog.InfoLevel
+	}
+	return zerolog.ErrorLevel
+}
+
+// NewJobID returns a random run identifier
+func NewJobID() string {
+	b := make([]byte, 6)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// ForRequest returns a logger carrying the url, domain, request ID and
+// engine of a request, and its attempt once it is being retried
+func ForRequest(opts models.RequestOptions, engine string) zerolog.Logger {
+	ctx := log.With().Str(FieldURL, opts.URL)
+	if u, err := url.Parse(opts.URL); err == nil && u.Host != "" {
+		ctx = ctx.Str(FieldDomain, u.Hostname())
+	}
+	if opts.RequestID != "" {
+		ctx = ctx.Str(FieldRequestID, opts.RequestID)
+	}
+	if engine != "" {
+		ctx = ctx.Str(FieldEngine, engine)
+	}
+	if opts.Attempt > 0 {
+		ctx = ctx.Int(FieldAttempt, opts.Attempt)
+	}
+	return ctx.Logger()
+}
@@ -0,0 +1,100 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/law-makers/crawl/pkg/models"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+func TestLogfmtWriter(t *testing.T) {
+	var buf bytes.Buffer
+	logger := zerolog.New(NewLogfmtWriter(&buf))
+	logger.Info().Str("url", "https://example.com/a b").Int("attempt", 2).Str("engine", "static").Msg("Fetched page")
+
+	want := `level=info message="Fetched page" attempt=2 engine=static url="https://example.com/a b"` + "\n"
+	if buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+}
+
+func TestRotatingFile_Rotates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "crawl.log")
+	f, err := OpenRotating(path, 10, 2)
+	if err != nil {
+		t.Fatalf("OpenRotating failed: %v", err)
+	}
+	for _, line := range []string{"first\n", "second\n", "third\n", "fourth\n"} {
+		if _, err := f.Write([]byte(line)); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+	f.Close()
+
+	for name, want := range map[string]string{
+		path:        "fourth\n",
+		path + ".1": "third\n",
+		path + ".2": "second\n",
+	} {
+		got, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatalf("ReadFile(%s) failed: %v", name, err)
+		}
+		if string(got) != want {
+			t.Errorf("%s = %q, want %q", filepath.Base(name), got, want)
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("expected only 2 backups to be kept")
+	}
+}
+
+func TestSetup_FileWithJobID(t *testing.T) {
+	defer func(l zerolog.Logger, lvl zerolog.Level) {
+		log.Logger = l
+		zerolog.SetGlobalLevel(lvl)
+	}(log.Logger, zerolog.GlobalLevel())
+
+	path := filepath.Join(t.TempDir(), "crawl.log")
+	closeLog, err := Setup(Options{Format: FormatJSON, File: path, JobID: "job-1"})
+	if err != nil {
+		t.Fatalf("Setup failed: %v", err)
+	}
+	logger := ForRequest(models.RequestOptions{URL: "https://www.example.com/page", Attempt: 2, RequestID: "req-1"}, "static")
+	logger.Info().Msg("Fetched page")
+	closeLog()
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	var event map[string]interface{}
+	if err := json.Unmarshal(raw, &event); err != nil {
+		t.Fatalf("log line is not JSON: %q", raw)
+	}
+	want := map[string]interface{}{
+		FieldJobID:     "job-1",
+		FieldURL:       "https://www.example.com/page",
+		FieldDomain:    "www.example.com",
+		FieldEngine:    "static",
+		FieldAttempt:   float64(2),
+		FieldRequestID: "req-1",
+	}
+	for k, v := range want {
+		if event[k] != v {
+			t.Errorf("%s = %v, want %v", k, event[k], v)
+		}
+	}
+}
+
+func TestSetup_InvalidFormat(t *testing.T) {
+	if _, err := Setup(Options{Format: "xml"}); err == nil || !strings.Contains(err.Error(), "invalid log format") {
+		t.Errorf("expected invalid format error, got %v", err)
+	}
+}
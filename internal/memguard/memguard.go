@@ -0,0 +1,184 @@
+// internal/memguard/memguard.go
+package memguard
+
+import (
+	"context"
+	"runtime"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// DefaultInterval is how often memory use is sampled
+const DefaultInterval = time.Second
+
+// DefaultMaxPause bounds one pause. Memory that doesn't come back, such as
+// a limit set below what the process needs at rest, then slows a run down
+// instead of stalling it.
+const DefaultMaxPause = 30 * time.Second
+
+// resumeRatio is the share of the limit memory must fall back under before
+// paused work resumes, so a run doesn't flap at the threshold
+const resumeRatio = 0.9
+
+// Guard watches the process's memory use and applies backpressure when it
+// crosses a limit: Wait blocks new work, and the relief functions are run
+// to hand memory back (flush caches, close idle browser tabs). Work resumes
+// once use falls below 90% of the limit, or after DefaultMaxPause. A nil
+// Guard never blocks.
+type Guard struct {
+	limit    uint64
+	interval time.Duration
+	maxPause time.Duration
+	usage    func() uint64
+	now      func() time.Time
+
+	mu       sync.Mutex
+	relief   []func()
+	over     bool
+	resume   chan struct{} // closed when a pause ends
+	since    time.Time     // start of the current pause
+	pauses   int
+	peak     uint64
+	lastSeen uint64
+}
+
+// New returns a Guard for a limit in bytes, measured as the process's
+// resident memory where the OS reports it and Go's own memory elsewhere
+func New(limit uint64) *Guard {
+	return &Guard{limit: limit, interval: DefaultInterval, maxPause: DefaultMaxPause, usage: Usage, now: time.Now}
+}
+
+// OnPressure registers fn to run each time use crosses the limit
+func (g *Guard) OnPressure(fn func()) {
+	if g == nil {
+		return
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.relief = append(g.relief, fn)
+}
+
+// Start takes a first sample, so work is held back from the start when the
+// process is already over the limit, and keeps sampling in the background
+// until ctx is done. It also sets the Go runtime's soft memory limit, so the
+// garbage collector works harder near the limit.
+func (g *Guard) Start(ctx context.Context) {
+	if g == nil {
+		return
+	}
+	debug.SetMemoryLimit(int64(g.limit))
+	g.check()
+	go func() {
+		ticker := time.NewTicker(g.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				g.release()
+				return
+			case <-ticker.C:
+				g.check()
+			}
+		}
+	}()
+}
+
+// check samples memory use once and starts or ends a pause
+func (g *Guard) check() {
+	used := g.usage()
+
+	g.mu.Lock()
+	g.lastSeen = used
+	if used > g.peak {
+		g.peak = used
+	}
+	switch {
+	case !g.over && used > g.limit:
+		g.over = true
+		g.pauses++
+		g.resume = make(chan struct{})
+		g.since = g.now()
+		relief := append([]func(){}, g.relief...)
+		g.mu.Unlock()
+
+		log.Warn().Uint64("used_bytes", used).Uint64("limit_bytes", g.limit).Msg("Memory limit reached; pausing new requests")
+		for _, fn := range relief {
+			fn()
+		}
+		runtime.GC()
+		debug.FreeOSMemory()
+		return
+	case g.over && float64(used) < float64(g.limit)*resumeRatio:
+		g.mu.Unlock()
+		log.Info().Uint64("used_bytes", used).Msg("Memory back under the limit; resuming")
+		g.release()
+		return
+	case g.over && g.now().Sub(g.since) >= g.maxPause:
+		g.mu.Unlock()
+		log.Warn().Uint64("used_bytes", used).Dur("paused", g.maxPause).Msg("Memory still over the limit; resuming anyway")
+		g.release()
+		return
+	}
+	g.mu.Unlock()
+}
+
+// release ends a pause, if one is in progress
+func (g *Guard) release() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.over {
+		g.over = false
+		close(g.resume)
+	}
+}
+
+// Wait blocks while memory use is over the limit. It returns ctx's error if
+// ctx ends first.
+func (g *Guard) Wait(ctx context.Context) error {
+	if g == nil {
+		return nil
+	}
+	g.mu.Lock()
+	over, resume := g.over, g.resume
+	g.mu.Unlock()
+	if !over {
+		return nil
+	}
+	select {
+	case <-resume:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Paused reports whether new work is being held back
+func (g *Guard) Paused() bool {
+	if g == nil {
+		return false
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.over
+}
+
+// Stats describes the guard's activity for reports
+type Stats struct {
+	Limit  uint64 `json:"limit_bytes"`
+	Used   uint64 `json:"used_bytes"`
+	Peak   uint64 `json:"peak_bytes"`
+	Pauses int    `json:"pauses"`
+}
+
+// Stats returns the latest sample, the peak and the number of pauses
+func (g *Guard) Stats() Stats {
+	if g == nil {
+		return Stats{}
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return Stats{Limit: g.limit, Used: g.lastSeen, Peak: g.peak, Pauses: g.pauses}
+}
@@ -0,0 +1,105 @@
+package memguard
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestGuardPausesAndResumes(t *testing.T) {
+	used := uint64(50)
+	g := New(100)
+	g.usage = func() uint64 { return used }
+	relieved := 0
+	g.OnPressure(func() { relieved++ })
+
+	g.check()
+	if g.Paused() {
+		t.Fatal("Expected no pause under the limit")
+	}
+
+	used = 150
+	g.check()
+	g.check()
+	if !g.Paused() || relieved != 1 {
+		t.Fatalf("Expected one pause with relief, got paused=%v relieved=%d", g.Paused(), relieved)
+	}
+
+	done := make(chan error)
+	go func() { done <- g.Wait(context.Background()) }()
+	select {
+	case <-done:
+		t.Fatal("Expected Wait to block while over the limit")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	// Between the resume threshold and the limit: still paused
+	used = 95
+	g.check()
+	if !g.Paused() {
+		t.Error("Expected the pause to hold above 90% of the limit")
+	}
+
+	used = 80
+	g.check()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Wait: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected Wait to return once memory dropped")
+	}
+
+	if s := g.Stats(); s.Pauses != 1 || s.Peak != 150 || s.Used != 80 {
+		t.Errorf("Unexpected stats %+v", s)
+	}
+}
+
+func TestGuardMaxPause(t *testing.T) {
+	now := time.Now()
+	g := New(100)
+	g.usage = func() uint64 { return 200 }
+	g.now = func() time.Time { return now }
+	relieved := 0
+	g.OnPressure(func() { relieved++ })
+
+	g.check()
+	now = now.Add(DefaultMaxPause)
+	g.check()
+	if g.Paused() {
+		t.Fatal("Expected the pause to end after DefaultMaxPause")
+	}
+
+	// Still over: the next sample pauses again and retries the relief
+	g.check()
+	if !g.Paused() || relieved != 2 || g.Stats().Pauses != 2 {
+		t.Errorf("Expected a second pause, got paused=%v relieved=%d", g.Paused(), relieved)
+	}
+}
+
+func TestWaitCancelled(t *testing.T) {
+	g := New(100)
+	g.usage = func() uint64 { return 200 }
+	g.check()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := g.Wait(ctx); err != context.Canceled {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
+
+func TestNilGuard(t *testing.T) {
+	var g *Guard
+	g.OnPressure(func() {})
+	if err := g.Wait(context.Background()); err != nil || g.Paused() {
+		t.Error("Expected a nil Guard to never block")
+	}
+}
+
+func TestUsage(t *testing.T) {
+	if Usage() == 0 {
+		t.Error("Expected a non-zero memory use")
+	}
+}
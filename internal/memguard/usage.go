@@ -0,0 +1,37 @@
+// internal/memguard/usage.go
+package memguard
+
+import (
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// Usage returns the process's resident set size from /proc on Linux, and
+// the memory Go has obtained from the OS, less what it returned, elsewhere
+func Usage() uint64 {
+	if rss, ok := procRSS(); ok {
+		return rss
+	}
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	return m.Sys - m.HeapReleased
+}
+
+// procRSS reads the resident page count from /proc/self/statm
+func procRSS() (uint64, bool) {
+	raw, err := os.ReadFile("/proc/self/statm")
+	if err != nil {
+		return 0, false
+	}
+	fields := strings.Fields(string(raw))
+	if len(fields) < 2 {
+		return 0, false
+	}
+	pages, err := strconv.ParseUint(fields[1], 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return pages * uint64(os.Getpagesize()), true
+}
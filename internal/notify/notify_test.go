@@ -0,0 +1,87 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRender_DefaultTemplate(t *testing.T) {
+	msg, err := Render("", Event{Kind: KindCompleted, Command: "media", Target: "https://example.com", Total: 10, Success: 9, Failed: 1})
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if !strings.Contains(msg, "9/10 succeeded, 1 failed") {
+		t.Errorf("Unexpected message: %q", msg)
+	}
+}
+
+func TestNewDispatcher_RejectsBadInput(t *testing.T) {
+	if _, err := NewDispatcher(nil, "{{.Nope", 0); err == nil {
+		t.Error("Expected error for invalid template")
+	}
+	if _, err := NewDispatcher([]string{"ftp://example.com"}, "", 0); err == nil {
+		t.Error("Expected error for unsupported scheme")
+	}
+	if _, err := NewDispatcher([]string{"smtp://mail.example.com"}, "", 0); err == nil {
+		t.Error("Expected error for smtp without recipients")
+	}
+}
+
+func TestDispatcher_FailureThreshold(t *testing.T) {
+	var got map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&got)
+	}))
+	defer server.Close()
+
+	d, err := NewDispatcher([]string{server.URL}, "{{.Kind}} {{.Failed}}/{{.Total}}", 25)
+	if err != nil {
+		t.Fatalf("NewDispatcher failed: %v", err)
+	}
+
+	if err := d.Finish(context.Background(), Event{Command: "media", Total: 4, Failed: 1}); err != nil {
+		t.Fatalf("Finish failed: %v", err)
+	}
+	if got["kind"] != string(KindFailureThreshold) || got["message"] != "failure_threshold 1/4" {
+		t.Errorf("Expected threshold breach, got %v", got)
+	}
+
+	if err := d.Finish(context.Background(), Event{Command: "media", Total: 10, Failed: 1}); err != nil {
+		t.Fatalf("Finish failed: %v", err)
+	}
+	if got["kind"] != string(KindCompleted) {
+		t.Errorf("Expected completed below threshold, got %v", got["kind"])
+	}
+}
+
+func TestWebhookNotifier_SlackPayload(t *testing.T) {
+	var got map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&got)
+	}))
+	defer server.Close()
+
+	n := newWebhookNotifier("slack", server.URL, slackPayload)
+	if err := n.Notify(context.Background(), Event{}, "hello"); err != nil {
+		t.Fatalf("Notify failed: %v", err)
+	}
+	if got["text"] != "hello" {
+		t.Errorf("Expected slack text payload, got %v", got)
+	}
+}
+
+func TestWebhookNotifier_ErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "invalid_token", http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	n := newWebhookNotifier("discord", server.URL, discordPayload)
+	if err := n.Notify(context.Background(), Event{}, "hello"); err == nil {
+		t.Error("Expected error for 403 response")
+	}
+}
@@ -0,0 +1,78 @@
+// internal/notify/webhook.go
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// payloadFunc builds the JSON body expected by a webhook flavour
+type payloadFunc func(event Event, message string) interface{}
+
+func slackPayload(_ Event, message string) interface{} {
+	return map[string]string{"text": message}
+}
+
+func discordPayload(_ Event, message string) interface{} {
+	return map[string]string{"content": message}
+}
+
+// genericPayload sends the full event alongside the rendered message
+func genericPayload(event Event, message string) interface{} {
+	return struct {
+		Event
+		Message string `json:"message"`
+	}{event, message}
+}
+
+// webhookNotifier POSTs a JSON payload to an HTTP endpoint
+type webhookNotifier struct {
+	name     string
+	endpoint string
+	payload  payloadFunc
+	client   *http.Client
+}
+
+func newWebhookNotifier(name, endpoint string, payload payloadFunc) *webhookNotifier {
+	return &webhookNotifier{
+		name:     name,
+		endpoint: endpoint,
+		payload:  payload,
+		client:   &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+// Name returns the notifier flavour
+func (w *webhookNotifier) Name() string {
+	return w.name
+}
+
+// Notify posts the message
+func (w *webhookNotifier) Notify(ctx context.Context, event Event, message string) error {
+	body, err := json.Marshal(w.payload(event, message))
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+// internal/paths/paths.go
+//
+// Package paths locates crawl's per-user directories using the platform's
+// conventions: ~/.config and ~/.cache on Linux, ~/Library on macOS, and
+// %AppData% and %LocalAppData% on Windows.
+package paths
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// appName is the directory created under the platform's config and cache dirs
+const appName = "crawl"
+
+// ConfigFileName is the config file looked for in ConfigDir
+const ConfigFileName = "config.yaml"
+
+// ConfigDir returns the directory for crawl's settings. CRAWL_HOME
+// overrides it.
+func ConfigDir() (string, error) {
+	if dir := os.Getenv("CRAWL_HOME"); dir != "" {
+		return dir, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("cannot locate config directory: %w", err)
+	}
+	return filepath.Join(dir, appName), nil
+}
+
+// CacheDir returns the directory for data crawl can download again, such
+// as browser builds. CRAWL_HOME overrides it.
+func CacheDir() (string, error) {
+	if dir := os.Getenv("CRAWL_HOME"); dir != "" {
+		return dir, nil
+	}
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("cannot locate cache directory: %w", err)
+	}
+	return filepath.Join(dir, appName), nil
+}
+
+// LegacyDir returns ~/.crawl, used by earlier versions on every platform
+func LegacyDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("cannot locate home directory: %w", err)
+	}
+	return filepath.Join(home, ".crawl"), nil
+}
+
+// DefaultConfigFile returns the config file in ConfigDir, or "" if there
+// isn't one
+func DefaultConfigFile() string {
+	dir, err := ConfigDir()
+	if err != nil {
+		return ""
+	}
+	path := filepath.Join(dir, ConfigFileName)
+	if _, err := os.Stat(path); err != nil {
+		return ""
+	}
+	return path
+}
+
+// PolicyFile returns the system-wide policy file administrators use to
+// restrict crawl: /etc/crawl/policy.yaml, or %ProgramData%\crawl\policy.yaml
+// on Windows. Unlike the config file, CRAWL_HOME doesn't move it.
+func PolicyFile() string {
+	if runtime.GOOS == "windows" {
+		dir := os.Getenv("ProgramData")
+		if dir == "" {
+			dir = `C:\ProgramData`
+		}
+		return filepath.Join(dir, appName, "policy.yaml")
+	}
+	return filepath.Join("/etc", appName, "policy.yaml")
+}
@@ -0,0 +1,53 @@
+package paths
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestConfigDir_CrawlHome(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("CRAWL_HOME", home)
+
+	for name, dir := range map[string]func() (string, error){"ConfigDir": ConfigDir, "CacheDir": CacheDir} {
+		got, err := dir()
+		if err != nil {
+			t.Fatalf("%s failed: %v", name, err)
+		}
+		if got != home {
+			t.Errorf("%s() = %q, want CRAWL_HOME %q", name, got, home)
+		}
+	}
+}
+
+func TestConfigDir_PlatformDefault(t *testing.T) {
+	t.Setenv("CRAWL_HOME", "")
+	base, err := os.UserConfigDir()
+	if err != nil {
+		t.Skipf("no user config directory: %v", err)
+	}
+	got, err := ConfigDir()
+	if err != nil {
+		t.Fatalf("ConfigDir failed: %v", err)
+	}
+	if got != filepath.Join(base, "crawl") {
+		t.Errorf("ConfigDir() = %q, want it under %q", got, base)
+	}
+}
+
+func TestDefaultConfigFile(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("CRAWL_HOME", home)
+	if got := DefaultConfigFile(); got != "" {
+		t.Errorf("Expected no config file, got %q", got)
+	}
+
+	path := filepath.Join(home, ConfigFileName)
+	if err := os.WriteFile(path, []byte("log_level: debug\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := DefaultConfigFile(); got != path {
+		t.Errorf("DefaultConfigFile() = %q, want %q", got, path)
+	}
+}
@@ -0,0 +1,30 @@
+//go:build windows
+
+package paths
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDirs_Windows(t *testing.T) {
+	t.Setenv("CRAWL_HOME", "")
+	config, err := ConfigDir()
+	if err != nil {
+		t.Fatalf("ConfigDir failed: %v", err)
+	}
+	if want := filepath.Join(os.Getenv("AppData"), "crawl"); !strings.EqualFold(config, want) {
+		t.Errorf("ConfigDir() = %q, want %q", config, want)
+	}
+
+	// Browser builds are large; they belong in the local, non-roaming profile
+	cache, err := CacheDir()
+	if err != nil {
+		t.Fatalf("CacheDir failed: %v", err)
+	}
+	if want := filepath.Join(os.Getenv("LocalAppData"), "crawl"); !strings.EqualFold(cache, want) {
+		t.Errorf("CacheDir() = %q, want %q", cache, want)
+	}
+}
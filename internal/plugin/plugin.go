@@ -0,0 +1,225 @@
+// internal/plugin/plugin.go
+//
+// Package plugin runs crawl plugins: executables named crawl-<name> found on
+// PATH, in the style of kubectl and git. A plugin is used in two ways.
+//
+// As a command, "crawl <name> args..." runs crawl-<name> args... with the
+// terminal attached, when <name> is not a built-in command. The plugin gets
+// the environment described by Env.
+//
+// As an extractor, "crawl get URL --plugin <name>" runs
+// "crawl-<name> extract [--plugin-arg values...]" and exchanges one JSON
+// document each way (protocol "crawl.plugin/v1"):
+//
+//	stdin:  {"protocol": "crawl.plugin/v1", "kind": "extract",
+//	         "args": [...], "page": <PageData, including "html">}
+//	stdout: {"page": <PageData>}   the transformed page; --output formats,
+//	                               sinks and assertions then use it
+//	    or  {"output": <any JSON>} printed or saved as-is, in place of the page
+//	    or  {"error": "message"}   the extraction failed
+//
+// A plugin that exits non-zero fails the extraction; its stderr is passed
+// through for diagnostics.
+//
+// An extractor can also be a WebAssembly module ("--plugin prices.wasm"),
+// built for WASI preview 1 (GOOS=wasip1, wasm32-wasi). It speaks the same
+// JSON contract over stdin and stdout but runs sandboxed: see LoadWASM.
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/law-makers/crawl/pkg/models"
+)
+
+// Prefix starts the name of every plugin executable
+const Prefix = "crawl-"
+
+// Protocol identifies the version of the JSON contract
+const Protocol = "crawl.plugin/v1"
+
+// Plugin is a crawl-<name> executable
+type Plugin struct {
+	Name string `json:"name"`
+	Path string `json:"path"`
+	// Paths of plugins with the same name later in PATH, which are ignored
+	Shadowed []string `json:"shadowed,omitempty"`
+}
+
+// Request is what an extractor plugin reads from stdin
+type Request struct {
+	Protocol string           `json:"protocol"`
+	Kind     string           `json:"kind"`
+	Args     []string         `json:"args"`
+	Page     *models.PageData `json:"page"`
+}
+
+// Response is what an extractor plugin writes to stdout. Exactly one of the
+// fields is expected.
+type Response struct {
+	Page   *models.PageData `json:"page,omitempty"`
+	Output json.RawMessage  `json:"output,omitempty"`
+	Error  string           `json:"error,omitempty"`
+}
+
+// Extractor transforms a fetched page, either a crawl-<name> executable or
+// a sandboxed WASM module
+type Extractor interface {
+	Extract(ctx context.Context, page *models.PageData, args []string) (*Response, error)
+}
+
+// Open returns the extractor for --plugin: a WASM module when ref names a
+// .wasm file, otherwise the crawl-<ref> plugin on PATH. opts only apply
+// to WASM modules.
+func Open(ctx context.Context, ref string, opts WASMOptions) (Extractor, error) {
+	if strings.HasSuffix(strings.ToLower(ref), ".wasm") {
+		return LoadWASM(ctx, ref, opts)
+	}
+	return Find(ref)
+}
+
+// ErrNotFound is returned by Find when no plugin has the name
+var ErrNotFound = errors.New("plugin not found")
+
+// Discover lists the plugins on PATH, sorted by name. When two directories
+// hold the same plugin the first one in PATH wins, as the shell would pick.
+func Discover() []Plugin {
+	return discover(filepath.SplitList(os.Getenv("PATH")))
+}
+
+func discover(dirs []string) []Plugin {
+	byName := map[string]*Plugin{}
+	var names []string
+	for _, dir := range dirs {
+		if dir == "" {
+			continue
+		}
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			name, ok := pluginName(e.Name())
+			if !ok || e.IsDir() {
+				continue
+			}
+			path := filepath.Join(dir, e.Name())
+			if !executable(path) {
+				continue
+			}
+			if p, seen := byName[name]; seen {
+				p.Shadowed = append(p.Shadowed, path)
+				continue
+			}
+			byName[name] = &Plugin{Name: name, Path: path}
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	plugins := make([]Plugin, 0, len(names))
+	for _, name := range names {
+		plugins = append(plugins, *byName[name])
+	}
+	return plugins
+}
+
+// pluginName returns the plugin name for an executable's file name
+func pluginName(file string) (string, bool) {
+	if runtime.GOOS == "windows" {
+		ext := strings.ToLower(filepath.Ext(file))
+		if ext != ".exe" && ext != ".bat" && ext != ".cmd" {
+			return "", false
+		}
+		file = strings.TrimSuffix(file, filepath.Ext(file))
+	}
+	name := strings.TrimPrefix(file, Prefix)
+	if name == file || name == "" {
+		return "", false
+	}
+	return name, true
+}
+
+func executable(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		return false
+	}
+	return runtime.GOOS == "windows" || info.Mode()&0111 != 0
+}
+
+// Find looks a plugin up by name
+func Find(name string) (Plugin, error) {
+	for _, p := range Discover() {
+		if p.Name == name {
+			return p, nil
+		}
+	}
+	return Plugin{}, fmt.Errorf("%w: no %s%s executable on PATH", ErrNotFound, Prefix, name)
+}
+
+// Env returns the environment plugins run with: crawl's own plus
+// CRAWL_PLUGIN_PROTOCOL and CRAWL_BIN, the crawl executable, so a plugin
+// can call back into crawl
+func Env() []string {
+	env := append(os.Environ(), "CRAWL_PLUGIN_PROTOCOL="+Protocol)
+	if self, err := os.Executable(); err == nil {
+		env = append(env, "CRAWL_BIN="+self)
+	}
+	return env
+}
+
+// Command prepares p to run as a crawl subcommand with the terminal attached
+func (p Plugin) Command(ctx context.Context, args []string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, p.Path, args...)
+	cmd.Env = Env()
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	return cmd
+}
+
+// Extract sends page to the plugin and returns its response
+func (p Plugin) Extract(ctx context.Context, page *models.PageData, args []string) (*Response, error) {
+	if args == nil {
+		args = []string{}
+	}
+	req, err := json.Marshal(Request{Protocol: Protocol, Kind: "extract", Args: args, Page: page})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode plugin request: %w", err)
+	}
+
+	cmd := exec.CommandContext(ctx, p.Path, append([]string{"extract"}, args...)...)
+	cmd.Env = Env()
+	cmd.Stdin = bytes.NewReader(req)
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("plugin %s failed: %w", p.Name, err)
+	}
+
+	return decodeResponse(p.Name, stdout.Bytes())
+}
+
+// decodeResponse parses an extractor's stdout
+func decodeResponse(name string, data []byte) (*Response, error) {
+	var resp Response
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, fmt.Errorf("plugin %s returned invalid JSON: %w", name, err)
+	}
+	switch {
+	case resp.Error != "":
+		return nil, fmt.Errorf("plugin %s: %s", name, resp.Error)
+	case resp.Page == nil && len(resp.Output) == 0:
+		return nil, fmt.Errorf("plugin %s returned neither a page nor output", name)
+	}
+	return &resp, nil
+}
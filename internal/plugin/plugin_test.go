@@ -0,0 +1,92 @@
+package plugin
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/law-makers/crawl/pkg/models"
+)
+
+// writeScript creates an executable shell script
+func writeScript(t *testing.T, dir, name, body string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+body+"\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestDiscover(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses shell scripts")
+	}
+	first, second := t.TempDir(), t.TempDir()
+	prices := writeScript(t, first, "crawl-prices", "true")
+	shadowed := writeScript(t, second, "crawl-prices", "true")
+	writeScript(t, second, "crawl-archive", "true")
+	os.WriteFile(filepath.Join(first, "crawl-notes"), []byte("not executable"), 0644)
+	writeScript(t, first, "other-tool", "true")
+
+	plugins := discover([]string{first, "", second, filepath.Join(first, "missing")})
+	if len(plugins) != 2 {
+		t.Fatalf("Expected 2 plugins, got %+v", plugins)
+	}
+	if plugins[0].Name != "archive" || plugins[1].Name != "prices" {
+		t.Errorf("Expected plugins sorted by name, got %+v", plugins)
+	}
+	if plugins[1].Path != prices || len(plugins[1].Shadowed) != 1 || plugins[1].Shadowed[0] != shadowed {
+		t.Errorf("Expected the first plugin in PATH to win, got %+v", plugins[1])
+	}
+}
+
+func TestExtract(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses shell scripts")
+	}
+	dir := t.TempDir()
+	tests := []struct {
+		name    string
+		script  string
+		wantErr string
+		check   func(*Response) bool
+	}{
+		{
+			name: "page",
+			// Only answer when the args and the page's HTML arrive
+			script: `read req; case "$req" in *'"args":["--currency","EUR"]'*'"html":'*) echo '{"page":{"url":"https://example.com","title":"ok"}}';; *) echo '{"error":"bad request"}';; esac`,
+			check:  func(r *Response) bool { return r.Page != nil && r.Page.Title == "ok" },
+		},
+		{
+			name:   "output",
+			script: `cat >/dev/null; echo '{"output":[1,2,3]}'`,
+			check:  func(r *Response) bool { return string(r.Output) == "[1,2,3]" },
+		},
+		{name: "error", script: `cat >/dev/null; echo '{"error":"no prices"}'`, wantErr: "no prices"},
+		{name: "empty", script: `cat >/dev/null; echo '{}'`, wantErr: "neither"},
+		{name: "invalid", script: `cat >/dev/null; echo nope`, wantErr: "invalid JSON"},
+		{name: "exit", script: `cat >/dev/null; exit 3`, wantErr: "exit status 3"},
+	}
+	page := &models.PageData{URL: "https://example.com", HTML: "<p>x</p>"}
+	for _, tt := range tests {
+		p := Plugin{Name: tt.name, Path: writeScript(t, dir, "crawl-"+tt.name, tt.script)}
+		resp, err := p.Extract(context.Background(), page, []string{"--currency", "EUR"})
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("%s: expected an error containing %q, got %v", tt.name, tt.wantErr, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+			continue
+		}
+		if !tt.check(resp) {
+			t.Errorf("%s: unexpected response %+v", tt.name, resp)
+		}
+	}
+}
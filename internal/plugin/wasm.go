@@ -0,0 +1,120 @@
+// internal/plugin/wasm.go
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/law-makers/crawl/pkg/models"
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+	"github.com/tetratelabs/wazero/sys"
+)
+
+// DefaultWASMTimeout bounds one page's run of a WASM extractor
+const DefaultWASMTimeout = 10 * time.Second
+
+// DefaultWASMMemoryMB caps a WASM extractor's linear memory
+const DefaultWASMMemoryMB = 256
+
+// WASMOptions limits a WASM extractor. Zero values use the defaults.
+type WASMOptions struct {
+	Timeout  time.Duration
+	MemoryMB uint32
+}
+
+// WASM is an extractor compiled from a WebAssembly module. Each page runs
+// in a fresh instance with no filesystem, network, host environment or real
+// clock: the module sees only the request on stdin, its arguments and
+// CRAWL_PLUGIN_PROTOCOL. Runs are cut off at the timeout and memory is
+// capped, so a shared transform can't reach or exhaust the host.
+type WASM struct {
+	Name     string
+	runtime  wazero.Runtime
+	compiled wazero.CompiledModule
+	timeout  time.Duration
+}
+
+// LoadWASM compiles the module at path
+func LoadWASM(ctx context.Context, path string, opts WASMOptions) (*WASM, error) {
+	code, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read WASM module: %w", err)
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultWASMTimeout
+	}
+	if opts.MemoryMB == 0 {
+		opts.MemoryMB = DefaultWASMMemoryMB
+	}
+
+	// 16 pages of 64 KiB make a MiB
+	cfg := wazero.NewRuntimeConfig().
+		WithMemoryLimitPages(opts.MemoryMB * 16).
+		WithCloseOnContextDone(true)
+	r := wazero.NewRuntimeWithConfig(ctx, cfg)
+	if _, err := wasi_snapshot_preview1.Instantiate(ctx, r); err != nil {
+		r.Close(ctx)
+		return nil, fmt.Errorf("failed to set up WASI: %w", err)
+	}
+	compiled, err := r.CompileModule(ctx, code)
+	if err != nil {
+		r.Close(ctx)
+		return nil, fmt.Errorf("failed to compile WASM module %s: %w", path, err)
+	}
+
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	return &WASM{Name: name, runtime: r, compiled: compiled, timeout: opts.Timeout}, nil
+}
+
+// Extract runs the module's _start with the request on stdin and decodes
+// the response from stdout
+func (w *WASM) Extract(ctx context.Context, page *models.PageData, args []string) (*Response, error) {
+	if args == nil {
+		args = []string{}
+	}
+	req, err := json.Marshal(Request{Protocol: Protocol, Kind: "extract", Args: args, Page: page})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode plugin request: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, w.timeout)
+	defer cancel()
+	var stdout bytes.Buffer
+	cfg := wazero.NewModuleConfig().
+		WithName(""). // anonymous, so instances for several pages don't collide
+		WithArgs(append([]string{w.Name, "extract"}, args...)...).
+		WithEnv("CRAWL_PLUGIN_PROTOCOL", Protocol).
+		WithStdin(bytes.NewReader(req)).
+		WithStdout(&stdout).
+		WithStderr(os.Stderr)
+
+	mod, err := w.runtime.InstantiateModule(ctx, w.compiled, cfg)
+	if mod != nil {
+		mod.Close(ctx)
+	}
+	if err != nil {
+		var exit *sys.ExitError
+		switch {
+		case errors.As(err, &exit) && exit.ExitCode() == 0:
+			// proc_exit(0), as Go and Rust programs end
+		case errors.As(err, &exit) && exit.ExitCode() == sys.ExitCodeDeadlineExceeded:
+			return nil, fmt.Errorf("plugin %s timed out after %s", w.Name, w.timeout)
+		default:
+			return nil, fmt.Errorf("plugin %s failed: %w", w.Name, err)
+		}
+	}
+	return decodeResponse(w.Name, stdout.Bytes())
+}
+
+// Close releases the compiled module and its runtime
+func (w *WASM) Close(ctx context.Context) error {
+	return w.runtime.Close(ctx)
+}
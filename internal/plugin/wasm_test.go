@@ -0,0 +1,177 @@
+package plugin
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/law-makers/crawl/pkg/models"
+)
+
+// uleb encodes n as unsigned LEB128
+func uleb(n uint32) []byte {
+	var b []byte
+	for {
+		c := byte(n & 0x7f)
+		n >>= 7
+		if n != 0 {
+			c |= 0x80
+		}
+		b = append(b, c)
+		if n == 0 {
+			return b
+		}
+	}
+}
+
+// i32 emits i32.const n for small non-negative n
+func i32(n uint32) []byte {
+	var b []byte
+	for {
+		c := byte(n & 0x7f)
+		n >>= 7
+		if n != 0 || c&0x40 != 0 {
+			b = append(b, c|0x80)
+			continue
+		}
+		b = append(b, c)
+		return append([]byte{0x41}, b...)
+	}
+}
+
+// section frames a module section with its id and length
+func section(id byte, parts ...[]byte) []byte {
+	var body []byte
+	for _, p := range parts {
+		body = append(body, p...)
+	}
+	return append(append([]byte{id}, uleb(uint32(len(body)))...), body...)
+}
+
+// name encodes a length-prefixed string
+func name(s string) []byte {
+	return append(uleb(uint32(len(s))), s...)
+}
+
+// module assembles a WASI module whose _start runs code. Functions 0 and 1
+// are fd_read and fd_write; "{"output":" is stored at address 1024.
+func module(code ...[]byte) []byte {
+	var body []byte
+	for _, c := range code {
+		body = append(body, c...)
+	}
+	body = append([]byte{0x00}, append(body, 0x0b)...) // no locals ... end
+	fd := []byte{0x60, 0x04, 0x7f, 0x7f, 0x7f, 0x7f, 0x01, 0x7f}
+	prefix := `{"output":`
+
+	m := []byte{0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00}
+	m = append(m, section(1, uleb(2), fd, []byte{0x60, 0x00, 0x00})...)
+	m = append(m, section(2, uleb(2),
+		name("wasi_snapshot_preview1"), name("fd_read"), []byte{0x00, 0x00},
+		name("wasi_snapshot_preview1"), name("fd_write"), []byte{0x00, 0x00})...)
+	m = append(m, section(3, uleb(1), uleb(1))...)
+	m = append(m, section(5, uleb(1), []byte{0x00, 0x01})...)
+	m = append(m, section(7, uleb(2), name("_start"), []byte{0x00, 0x02}, name("memory"), []byte{0x02, 0x00})...)
+	m = append(m, section(10, uleb(1), uleb(uint32(len(body))), body)...)
+	m = append(m, section(11, uleb(1), []byte{0x00}, i32(1024), []byte{0x0b}, name(prefix))...)
+	return m
+}
+
+var (
+	store  = []byte{0x36, 0x02, 0x00}
+	load   = []byte{0x28, 0x02, 0x00}
+	store8 = []byte{0x3a, 0x00, 0x00}
+	add    = []byte{0x6a}
+	drop   = []byte{0x1a}
+)
+
+func call(fn byte) []byte { return []byte{0x10, fn} }
+
+// echoModule answers {"output": <request>} so tests can see what it was sent
+func echoModule() []byte {
+	return module(
+		// iovec at 0: read stdin to 1034, after the prefix
+		i32(0), i32(1034), store,
+		i32(4), i32(60000), store,
+		i32(0), i32(0), i32(1), i32(8), call(0), drop,
+		// close the object after the nread bytes at 8
+		i32(8), load, i32(1034), add, i32(125), store8,
+		// iovec at 16: write prefix, request and "}" to stdout
+		i32(16), i32(1024), store,
+		i32(20), i32(8), load, i32(11), add, store,
+		i32(1), i32(16), i32(1), i32(24), call(1), drop,
+	)
+}
+
+// writeModule saves a module to a temporary .wasm file
+func writeModule(t *testing.T, code []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "echo.wasm")
+	if err := os.WriteFile(path, code, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestWASMExtract(t *testing.T) {
+	ctx := context.Background()
+	ex, err := Open(ctx, writeModule(t, echoModule()), WASMOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := ex.(*WASM)
+	defer w.Close(ctx)
+
+	// A fresh instance per page: the second run must not see the first
+	for _, title := range []string{"First", "Second"} {
+		resp, err := w.Extract(ctx, &models.PageData{URL: "https://example.com", Title: title}, []string{"--mode=fast"})
+		if err != nil {
+			t.Fatalf("Extract: %v", err)
+		}
+		out := string(resp.Output)
+		for _, want := range []string{`"protocol":"crawl.plugin/v1"`, `"kind":"extract"`, `"args":["--mode=fast"]`, `"title":"` + title + `"`} {
+			if !strings.Contains(out, want) {
+				t.Errorf("Expected %s in the request, got %s", want, out)
+			}
+		}
+	}
+}
+
+func TestWASMTimeout(t *testing.T) {
+	ctx := context.Background()
+	spin := module([]byte{0x03, 0x40, 0x0c, 0x00, 0x0b}) // loop br 0 end
+	w, err := LoadWASM(ctx, writeModule(t, spin), WASMOptions{Timeout: 100 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close(ctx)
+
+	_, err = w.Extract(ctx, &models.PageData{URL: "https://example.com"}, nil)
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("Expected a timeout, got %v", err)
+	}
+}
+
+func TestWASMMemoryLimit(t *testing.T) {
+	ctx := context.Background()
+	grow := module(i32(32), []byte{0x40, 0x00}, []byte{0x41, 0x7f, 0x46, 0x04, 0x40, 0x00, 0x0b}) // memory.grow 32; if -1 unreachable
+	w, err := LoadWASM(ctx, writeModule(t, grow), WASMOptions{MemoryMB: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close(ctx)
+
+	_, err = w.Extract(ctx, &models.PageData{URL: "https://example.com"}, nil)
+	if err == nil || !strings.Contains(err.Error(), "unreachable") {
+		t.Errorf("Expected growing past the memory limit to fail, got %v", err)
+	}
+}
+
+func TestLoadWASMInvalid(t *testing.T) {
+	if _, err := LoadWASM(context.Background(), writeModule(t, []byte("not wasm")), WASMOptions{}); err == nil {
+		t.Error("Expected an error for an invalid module")
+	}
+}
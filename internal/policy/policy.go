@@ -0,0 +1,188 @@
+// internal/policy/policy.go
+//
+// Package policy reads the system-wide policy file administrators use to
+// keep crawl within an organisation's rules: domains it must not fetch,
+// politeness limits no flag or config can raise, and features that are
+// turned off. Users can't override it; a missing file means no policy.
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/law-makers/crawl/pkg/models"
+	"gopkg.in/yaml.v3"
+)
+
+// Features a policy can turn off
+const (
+	FeatureStealth       = "stealth"        // disguising the browser (--escalate stealth)
+	FeatureProxy         = "proxy"          // proxies (--proxy, --retry-proxies, CRAWL_PROXY, domain and compare proxies)
+	FeaturePlugins       = "plugins"        // extractor plugins (--plugin)
+	FeatureEscalate      = "escalate"       // getting past blocks (--escalate, --retry-suspected)
+	FeatureHeadful       = "headful"        // visible browsers (--headful, --devtools, --slowmo)
+	FeatureRemoteBrowser = "remote-browser" // browsers elsewhere (--cdp, browser_remote_url)
+)
+
+// features lists every feature that can be disabled
+var features = []string{FeatureStealth, FeatureProxy, FeaturePlugins, FeatureEscalate, FeatureHeadful, FeatureRemoteBrowser}
+
+// ErrDenied is returned for requests to a domain the policy forbids
+var ErrDenied = errors.New("denied by policy")
+
+// Policy is the parsed policy file. Its methods treat a nil Policy as one
+// that allows everything.
+type Policy struct {
+	Path string `yaml:"-"`
+
+	DenyDomains  []string `yaml:"deny_domains"`  // Never fetched; subdomains included
+	AllowDomains []string `yaml:"allow_domains"` // When set, only these (and their subdomains) are fetched
+
+	MaxRPS       float64 `yaml:"max_rps"`        // Highest request rate per domain
+	MaxBurst     int     `yaml:"max_burst"`      // Highest burst per domain
+	MaxPerDomain int     `yaml:"max_per_domain"` // Most requests in flight per domain
+
+	DisabledFeatures []string `yaml:"disabled_features"`
+}
+
+// Load reads the policy at path. A missing file is no policy (nil).
+func Load(path string) (*Policy, error) {
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policy: %w", err)
+	}
+	p := &Policy{Path: path}
+	if err := yaml.Unmarshal(raw, p); err != nil {
+		return nil, fmt.Errorf("failed to parse policy %s: %w", path, err)
+	}
+	if err := p.validate(); err != nil {
+		return nil, fmt.Errorf("invalid policy %s: %w", path, err)
+	}
+	return p, nil
+}
+
+func (p *Policy) validate() error {
+	if p.MaxRPS < 0 || p.MaxBurst < 0 || p.MaxPerDomain < 0 {
+		return fmt.Errorf("max_rps, max_burst and max_per_domain must not be negative")
+	}
+	for i, f := range p.DisabledFeatures {
+		f = strings.ToLower(strings.TrimSpace(f))
+		known := false
+		for _, k := range features {
+			known = known || f == k
+		}
+		if !known {
+			return fmt.Errorf("unknown feature %q in disabled_features (known: %s)", f, strings.Join(features, ", "))
+		}
+		p.DisabledFeatures[i] = f
+	}
+	for _, list := range [][]string{p.DenyDomains, p.AllowDomains} {
+		for i, d := range list {
+			list[i] = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(d)), "*.")
+		}
+	}
+	sort.Strings(p.DisabledFeatures)
+	return nil
+}
+
+// Disabled reports whether the policy turns feature off
+func (p *Policy) Disabled(feature string) bool {
+	if p == nil {
+		return false
+	}
+	for _, f := range p.DisabledFeatures {
+		if f == feature {
+			return true
+		}
+	}
+	return false
+}
+
+// Forbid returns an error naming the policy if feature is disabled
+func (p *Policy) Forbid(feature, what string) error {
+	if !p.Disabled(feature) {
+		return nil
+	}
+	return fmt.Errorf("%s is not allowed: %s is disabled by the policy in %s", what, feature, p.Path)
+}
+
+// CheckURL returns an error wrapping ErrDenied if rawURL's host is denied,
+// or not allowed when the policy has an allow list
+func (p *Policy) CheckURL(rawURL string) error {
+	if p == nil {
+		return nil
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil
+	}
+	host := strings.ToLower(u.Hostname())
+	if host == "" {
+		return nil
+	}
+	if matchAny(host, p.DenyDomains) {
+		return fmt.Errorf("%s: %w (%s)", host, ErrDenied, p.Path)
+	}
+	if len(p.AllowDomains) > 0 && !matchAny(host, p.AllowDomains) {
+		return fmt.Errorf("%s: %w, not in allow_domains (%s)", host, ErrDenied, p.Path)
+	}
+	return nil
+}
+
+// RestrictsDomains reports whether the policy denies or limits domains
+func (p *Policy) RestrictsDomains() bool {
+	return p != nil && (len(p.DenyDomains) > 0 || len(p.AllowDomains) > 0)
+}
+
+// CheckRequest checks a page request's URL and the features it uses
+func (p *Policy) CheckRequest(opts models.RequestOptions) error {
+	if err := p.CheckURL(opts.URL); err != nil {
+		return err
+	}
+	if opts.Stealth {
+		if err := p.Forbid(FeatureStealth, "stealth mode"); err != nil {
+			return err
+		}
+	}
+	if opts.Proxy != "" {
+		return p.Forbid(FeatureProxy, "a proxy")
+	}
+	return nil
+}
+
+func matchAny(host string, domains []string) bool {
+	for _, d := range domains {
+		if host == d || strings.HasSuffix(host, "."+d) {
+			return true
+		}
+	}
+	return false
+}
+
+// Transport wraps next so requests, including redirects, to denied
+// domains fail without leaving the machine
+func (p *Policy) Transport(next http.RoundTripper) http.RoundTripper {
+	if !p.RestrictsDomains() {
+		return next
+	}
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		if err := p.CheckURL(req.URL.String()); err != nil {
+			return nil, err
+		}
+		return next.RoundTrip(req)
+	})
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
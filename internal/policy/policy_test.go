@@ -0,0 +1,131 @@
+package policy
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/law-makers/crawl/pkg/models"
+)
+
+func writePolicy(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoad_Missing(t *testing.T) {
+	p, err := Load(filepath.Join(t.TempDir(), "policy.yaml"))
+	if err != nil || p != nil {
+		t.Fatalf("Expected no policy and no error, got %v, %v", p, err)
+	}
+	// A nil policy allows everything
+	if err := p.CheckURL("https://example.com"); err != nil || p.Disabled(FeatureProxy) {
+		t.Errorf("Expected a nil policy to allow everything, got %v", err)
+	}
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	for _, content := range []string{
+		"disabled_features: [teleport]",
+		"max_rps: -1",
+		"deny_domains: {",
+	} {
+		if _, err := Load(writePolicy(t, content)); err == nil {
+			t.Errorf("Load(%q) succeeded, want error", content)
+		}
+	}
+}
+
+func TestPolicy_CheckURL(t *testing.T) {
+	p, err := Load(writePolicy(t, "deny_domains: ['*.Blocked.com']\n"))
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	tests := []struct {
+		url    string
+		denied bool
+	}{
+		{"https://blocked.com/a", true},
+		{"https://www.blocked.com:8443/a", true},
+		{"https://notblocked.com/", false},
+		{"https://example.com/", false},
+	}
+	for _, tt := range tests {
+		err := p.CheckURL(tt.url)
+		if denied := errors.Is(err, ErrDenied); denied != tt.denied {
+			t.Errorf("CheckURL(%q) = %v, want denied %v", tt.url, err, tt.denied)
+		}
+	}
+
+	p, _ = Load(writePolicy(t, "allow_domains: [example.com]\n"))
+	if err := p.CheckURL("https://docs.example.com/"); err != nil {
+		t.Errorf("Expected allowed subdomain, got %v", err)
+	}
+	if err := p.CheckURL("https://other.org/"); !errors.Is(err, ErrDenied) {
+		t.Errorf("Expected a domain outside allow_domains denied, got %v", err)
+	}
+}
+
+func TestPolicy_CheckRequest(t *testing.T) {
+	path := writePolicy(t, "disabled_features: [Stealth, proxy]\n")
+	p, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if err := p.CheckRequest(models.RequestOptions{URL: "https://example.com"}); err != nil {
+		t.Errorf("Expected a plain request allowed, got %v", err)
+	}
+	err = p.CheckRequest(models.RequestOptions{URL: "https://example.com", Stealth: true})
+	if err == nil || !strings.Contains(err.Error(), path) {
+		t.Errorf("Expected stealth refused naming the policy, got %v", err)
+	}
+	if err := p.CheckRequest(models.RequestOptions{URL: "https://example.com", Proxy: "http://p:8080"}); err == nil {
+		t.Error("Expected a proxied request refused")
+	}
+	if p.Disabled(FeaturePlugins) {
+		t.Error("Expected plugins to stay enabled")
+	}
+}
+
+func TestPolicy_Transport(t *testing.T) {
+	p, _ := Load(writePolicy(t, "deny_domains: [blocked.com]\n"))
+	called := false
+	next := roundTripperFunc(func(*http.Request) (*http.Response, error) {
+		called = true
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+	})
+	rt := p.Transport(next)
+
+	req, _ := http.NewRequest(http.MethodGet, "https://sub.blocked.com/", nil)
+	if _, err := rt.RoundTrip(req); !errors.Is(err, ErrDenied) || called {
+		t.Errorf("Expected the request refused before sending, got %v (sent %v)", err, called)
+	}
+	req, _ = http.NewRequest(http.MethodGet, "https://example.com/", nil)
+	if _, err := rt.RoundTrip(req); err != nil || !called {
+		t.Errorf("Expected the request sent, got %v", err)
+	}
+}
+
+func TestPolicy_RestrictsDomains(t *testing.T) {
+	var none *Policy
+	if none.RestrictsDomains() {
+		t.Error("Expected no policy to restrict nothing")
+	}
+	features, _ := Load(writePolicy(t, "disabled_features: [stealth]\n"))
+	if features.RestrictsDomains() {
+		t.Error("Expected a features-only policy to leave domains alone")
+	}
+	for _, content := range []string{"deny_domains: [blocked.com]\n", "allow_domains: [example.com]\n"} {
+		p, _ := Load(writePolicy(t, content))
+		if !p.RestrictsDomains() {
+			t.Errorf("Expected %q to restrict domains", content)
+		}
+	}
+}
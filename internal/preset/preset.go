@@ -0,0 +1,108 @@
+// internal/preset/preset.go
+package preset
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/law-makers/crawl/pkg/models"
+)
+
+// Preset bundles get defaults for a common kind of site, so a first run
+// gives useful output without working out selectors and rate limits
+type Preset struct {
+	Name        string
+	Description string
+
+	// Content selectors to try in order; the first found on the page is
+	// used. With Fields, each element it matches becomes one row.
+	Selectors []string
+	Fields    []models.Field
+
+	// Politeness: pages fetched at once, and the per-domain request rate
+	Concurrency  int
+	MaxPerDomain int
+	RPS          float64
+	Burst        int
+}
+
+var presets = map[string]Preset{
+	"docs": {
+		Name:        "docs",
+		Description: "Documentation pages: the article text without navigation, sidebars and footers",
+		Selectors:   []string{"main article", "[role=main]", "article", "main", ".markdown-body", ".rst-content", ".content", "#content"},
+		Fields: []models.Field{
+			{Name: "heading", Selector: "h1"},
+			{Name: "updated", Selector: "time@datetime"},
+		},
+		Concurrency:  4,
+		MaxPerDomain: 4,
+		RPS:          2,
+		Burst:        4,
+	},
+	"ecommerce": {
+		Name:        "ecommerce",
+		Description: "Product listings and product pages: one row per product with name, price and link",
+		Selectors:   []string{"[itemtype*='schema.org/Product']", ".product-card", ".product-item", "li.product", ".product", "[data-product-id]"},
+		Fields: []models.Field{
+			{Name: "name", Selector: "[itemprop=name], .product-title, .product-name, h2, h3"},
+			{Name: "price", Selector: "[itemprop=price], .price, .product-price, [data-price]"},
+			{Name: "currency", Selector: "[itemprop=priceCurrency]@content"},
+			{Name: "link", Selector: "a@href"},
+			{Name: "image", Selector: "img@src"},
+		},
+		// Shops rate-limit and block aggressively
+		Concurrency:  2,
+		MaxPerDomain: 1,
+		RPS:          0.5,
+		Burst:        1,
+	},
+	"news": {
+		Name:        "news",
+		Description: "News articles: headline, byline, publication date and body text",
+		Selectors:   []string{"article [itemprop=articleBody]", "[itemprop=articleBody]", "article", ".article-body", ".story-body", "main"},
+		Fields: []models.Field{
+			{Name: "headline", Selector: "h1, [itemprop=headline]"},
+			{Name: "author", Selector: "[rel=author], [itemprop=author], .byline, .author"},
+			{Name: "published", Selector: "time@datetime"},
+		},
+		Concurrency:  3,
+		MaxPerDomain: 2,
+		RPS:          1,
+		Burst:        2,
+	},
+	"forum": {
+		Name:        "forum",
+		Description: "Forum threads: one row per post with author, date and text",
+		Selectors:   []string{"[itemtype*='schema.org/Comment']", "article.post", ".post", ".message", ".comment", "[id^=post-]"},
+		Fields: []models.Field{
+			{Name: "author", Selector: "[itemprop=author], .author, .username, .poster"},
+			{Name: "date", Selector: "time@datetime"},
+			{Name: "text", Selector: "[itemprop=text], .post-body, .message-body, .post-content, .content"},
+		},
+		Concurrency:  2,
+		MaxPerDomain: 2,
+		RPS:          1,
+		Burst:        2,
+	},
+}
+
+// Get returns the preset called name
+func Get(name string) (Preset, error) {
+	p, ok := presets[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		return Preset{}, fmt.Errorf("unknown preset %q (available: %s)", name, strings.Join(Names(), ", "))
+	}
+	return p, nil
+}
+
+// Names lists the presets in alphabetical order
+func Names() []string {
+	names := make([]string, 0, len(presets))
+	for name := range presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,37 @@
+package preset
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/cascadia"
+)
+
+func TestGet(t *testing.T) {
+	p, err := Get(" News ")
+	if err != nil || p.Name != "news" {
+		t.Fatalf("Expected the news preset, got %+v, %v", p, err)
+	}
+	if _, err := Get("blog"); err == nil || !strings.Contains(err.Error(), "docs, ecommerce, forum, news") {
+		t.Errorf("Expected an error listing the presets, got %v", err)
+	}
+}
+
+// Every selector must parse, or a preset would fail on every page
+func TestSelectorsParse(t *testing.T) {
+	for _, name := range Names() {
+		p, _ := Get(name)
+		if len(p.Selectors) == 0 || p.Concurrency < 1 || p.RPS <= 0 {
+			t.Errorf("%s: incomplete preset %+v", name, p)
+		}
+		sels := append([]string{}, p.Selectors...)
+		for _, f := range p.Fields {
+			sels = append(sels, strings.Split(f.Selector, "@")[0])
+		}
+		for _, sel := range sels {
+			if _, err := cascadia.ParseGroup(sel); err != nil {
+				t.Errorf("%s: invalid selector %q: %v", name, sel, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,167 @@
+// internal/probe/probe.go
+package probe
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/law-makers/crawl/internal/ratelimit"
+	"github.com/rs/zerolog/log"
+)
+
+// Result describes a URL without downloading its body
+type Result struct {
+	URL          string `json:"url"`
+	FinalURL     string `json:"final_url,omitempty"` // After redirects
+	Method       string `json:"method"`              // HEAD, or GET when the server rejected HEAD
+	Status       int    `json:"status,omitempty"`
+	ContentType  string `json:"content_type,omitempty"`
+	Size         int64  `json:"size"` // -1 when the server did not say
+	LastModified string `json:"last_modified,omitempty"`
+	ETag         string `json:"etag,omitempty"`
+	CacheControl string `json:"cache_control,omitempty"`
+	Expires      string `json:"expires,omitempty"`
+	DurationMs   int64  `json:"duration_ms"`
+	Error        string `json:"error,omitempty"`
+}
+
+// Prober issues HEAD requests, falling back to GET for servers that don't
+// support HEAD. It shares the rate and per-domain concurrency limits used by
+// the scrapers.
+type Prober struct {
+	client      *http.Client
+	limiter     ratelimit.RateLimiter
+	concurrency *ratelimit.DomainConcurrency
+	headers     map[string]string
+	userAgent   string
+	headOnly    bool
+}
+
+// New creates a Prober. The limiter and concurrency may be nil.
+func New(client *http.Client, lim ratelimit.RateLimiter, dc *ratelimit.DomainConcurrency) *Prober {
+	return &Prober{client: client, limiter: lim, concurrency: dc}
+}
+
+// SetHeaders sets extra headers sent with every request
+func (p *Prober) SetHeaders(headers map[string]string) {
+	p.headers = headers
+}
+
+// SetUserAgent sets the User-Agent sent with every request, e.g. one that
+// carries the --from contact. Headers set with SetHeaders take precedence.
+func (p *Prober) SetUserAgent(ua string) {
+	p.userAgent = ua
+}
+
+// SetHeadOnly stops the GET fallback for servers that reject HEAD, whose
+// sizes are then unknown. Use it when bodies may be large, e.g. for media.
+func (p *Prober) SetHeadOnly(headOnly bool) {
+	p.headOnly = headOnly
+}
+
+// Probe reports on a single URL. Failures are returned in Result.Error so a
+// bad URL doesn't stop a list from being triaged.
+func (p *Prober) Probe(ctx context.Context, url string) Result {
+	start := time.Now()
+	res := Result{URL: url, Method: http.MethodHead, Size: -1}
+
+	if p.limiter != nil {
+		if err := p.limiter.Wait(ctx, url); err != nil {
+			res.Error = fmt.Sprintf("rate limit wait failed: %v", err)
+			return res
+		}
+	}
+	release, err := p.concurrency.Acquire(ctx, url)
+	if err != nil {
+		res.Error = fmt.Sprintf("timed out waiting for a connection slot: %v", err)
+		return res
+	}
+	defer release()
+
+	resp, err := p.do(ctx, http.MethodHead, url)
+	if err == nil && !p.headOnly && (resp.StatusCode == http.StatusMethodNotAllowed || resp.StatusCode == http.StatusNotImplemented) {
+		resp.Body.Close()
+		log.Debug().Str("url", url).Int("status", resp.StatusCode).Msg("HEAD not supported, retrying with GET")
+		res.Method = http.MethodGet
+		resp, err = p.do(ctx, http.MethodGet, url)
+	}
+	if err != nil {
+		res.Error = err.Error()
+		res.DurationMs = time.Since(start).Milliseconds()
+		return res
+	}
+	defer resp.Body.Close()
+
+	res.Status = resp.StatusCode
+	res.FinalURL = resp.Request.URL.String()
+	res.ContentType = resp.Header.Get("Content-Type")
+	res.LastModified = resp.Header.Get("Last-Modified")
+	res.ETag = resp.Header.Get("ETag")
+	res.CacheControl = resp.Header.Get("Cache-Control")
+	res.Expires = resp.Header.Get("Expires")
+	res.Size = resp.ContentLength
+
+	// For GET, drain the body so the size is known even without Content-Length
+	if res.Method == http.MethodGet {
+		n, err := io.Copy(io.Discard, resp.Body)
+		if err != nil {
+			res.Error = fmt.Sprintf("failed to read body: %v", err)
+		} else if res.Size < 0 {
+			res.Size = n
+		}
+	}
+
+	res.DurationMs = time.Since(start).Milliseconds()
+	return res
+}
+
+// ProbeAll probes urls with up to workers requests in flight and returns the
+// results in the same order as urls
+func (p *Prober) ProbeAll(ctx context.Context, urls []string, workers int) []Result {
+	if workers < 1 {
+		workers = 1
+	}
+	results := make([]Result, len(urls))
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = p.Probe(ctx, urls[i])
+			}
+		}()
+	}
+	for i := range urls {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return results
+}
+
+func (p *Prober) do(ctx context.Context, method, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	ua := p.userAgent
+	if ua == "" {
+		ua = "Crawl/1.0 (https://github.com/law-makers/crawl)"
+	}
+	req.Header.Set("User-Agent", ua)
+	for key, value := range p.headers {
+		req.Header.Set(key, value)
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s failed: %w", method, err)
+	}
+	return resp, nil
+}
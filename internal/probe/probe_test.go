@@ -0,0 +1,98 @@
+package probe
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestProbe_Head(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodHead {
+			t.Errorf("Expected HEAD, got %s", r.Method)
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("Content-Length", "1234")
+		w.Header().Set("Cache-Control", "max-age=60")
+		w.Header().Set("Last-Modified", "Mon, 02 Jan 2006 15:04:05 GMT")
+	}))
+	defer server.Close()
+
+	r := New(http.DefaultClient, nil, nil).Probe(context.Background(), server.URL)
+	if r.Error != "" {
+		t.Fatalf("Probe failed: %s", r.Error)
+	}
+	if r.Status != 200 || r.Method != "HEAD" || r.Size != 1234 {
+		t.Errorf("Unexpected result: %+v", r)
+	}
+	if r.CacheControl != "max-age=60" || r.LastModified == "" || r.ContentType != "text/html; charset=utf-8" {
+		t.Errorf("Expected headers to be reported, got %+v", r)
+	}
+}
+
+func TestProbe_FallsBackToGet(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		// Chunked, so the size is only known by reading the body
+		w.(http.Flusher).Flush()
+		w.Write([]byte("hello world"))
+	}))
+	defer server.Close()
+
+	r := New(http.DefaultClient, nil, nil).Probe(context.Background(), server.URL)
+	if r.Method != "GET" || r.Status != 200 {
+		t.Errorf("Expected GET fallback with 200, got %+v", r)
+	}
+	if r.Size != 11 {
+		t.Errorf("Expected size 11 from the discarded body, got %d", r.Size)
+	}
+}
+
+func TestProbe_HeadOnly(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodHead {
+			t.Errorf("Expected no GET fallback, got %s", r.Method)
+		}
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}))
+	defer server.Close()
+
+	p := New(http.DefaultClient, nil, nil)
+	p.SetHeadOnly(true)
+	r := p.Probe(context.Background(), server.URL)
+	if r.Method != "HEAD" || r.Status != http.StatusMethodNotAllowed {
+		t.Errorf("Expected the HEAD result, got %+v", r)
+	}
+}
+
+func TestProbeAll_FollowsRedirectsAndKeepsOrder(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/old", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/new", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/new", func(w http.ResponseWriter, r *http.Request) {})
+	mux.HandleFunc("/gone", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusGone)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	urls := []string{server.URL + "/old", server.URL + "/gone", server.URL + "/new"}
+	results := New(http.DefaultClient, nil, nil).ProbeAll(context.Background(), urls, 3)
+
+	for i, r := range results {
+		if r.URL != urls[i] {
+			t.Errorf("Expected result %d for %s, got %s", i, urls[i], r.URL)
+		}
+	}
+	if results[0].FinalURL != server.URL+"/new" {
+		t.Errorf("Expected final URL after redirect, got %s", results[0].FinalURL)
+	}
+	if results[1].Status != http.StatusGone {
+		t.Errorf("Expected 410, got %d", results[1].Status)
+	}
+}
@@ -0,0 +1,37 @@
+package proxy
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+type contextKey struct{}
+
+// WithURL returns ctx asking for requests made with it to go through
+// proxyURL. Only transports whose Proxy is built with Func honour it.
+func WithURL(ctx context.Context, proxyURL *url.URL) context.Context {
+	return context.WithValue(ctx, contextKey{}, proxyURL)
+}
+
+// FromContext returns the proxy requested with WithURL, or nil
+func FromContext(ctx context.Context) *url.URL {
+	u, _ := ctx.Value(contextKey{}).(*url.URL)
+	return u
+}
+
+// Func returns an http.Transport Proxy function that uses the proxy in
+// the request's context, or fallback (which may be nil) when there is none.
+// Choosing the proxy at the bottom of the transport stack means wrappers
+// above it, such as the recorder or the request log, don't need to know.
+func Func(fallback func(*http.Request) (*url.URL, error)) func(*http.Request) (*url.URL, error) {
+	return func(req *http.Request) (*url.URL, error) {
+		if u := FromContext(req.Context()); u != nil {
+			return u, nil
+		}
+		if fallback == nil {
+			return nil, nil
+		}
+		return fallback(req)
+	}
+}
@@ -0,0 +1,102 @@
+// internal/ratelimit/concurrency.go
+package ratelimit
+
+import (
+	"context"
+	"sync"
+)
+
+// DomainConcurrency caps the number of simultaneous in-flight requests per
+// domain. It complements DomainLimiter, which only controls request rate:
+// a slow server can otherwise accumulate many open connections even at a
+// low RPS.
+type DomainConcurrency struct {
+	slots     map[string]chan struct{}
+	overrides map[string]int
+	mu        sync.Mutex
+	perHost   int
+	ceiling   int // No domain gets more slots than this; 0 for no ceiling
+}
+
+// NewDomainConcurrency creates a limiter allowing maxPerHost concurrent
+// requests per domain. A non-positive value disables the default cap;
+// per-domain overrides still apply.
+func NewDomainConcurrency(maxPerHost int) *DomainConcurrency {
+	return &DomainConcurrency{
+		slots:     make(map[string]chan struct{}),
+		overrides: make(map[string]int),
+		perHost:   maxPerHost,
+	}
+}
+
+// SetMax overrides the concurrency cap for a single domain. It must be
+// called before requests to that domain start.
+func (dc *DomainConcurrency) SetMax(domain string, max int) {
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+	dc.overrides[domain] = max
+	delete(dc.slots, domain)
+}
+
+// SetCeiling caps every domain, including unlimited ones and overrides, at
+// max slots. A non-positive max removes the ceiling. Like SetMax, it must
+// be called before requests start.
+func (dc *DomainConcurrency) SetCeiling(max int) {
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+	dc.ceiling = max
+	dc.slots = make(map[string]chan struct{})
+}
+
+// Acquire blocks until a slot for the URL's domain is free and returns a
+// function that releases it. A nil receiver, an unparsable URL or an
+// unlimited domain return immediately.
+func (dc *DomainConcurrency) Acquire(ctx context.Context, urlStr string) (func(), error) {
+	if dc == nil {
+		return func() {}, nil
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	slots := dc.getSlots(extractDomain(urlStr))
+	if slots == nil {
+		return func() {}, nil
+	}
+
+	select {
+	case slots <- struct{}{}:
+		return func() { <-slots }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// getSlots returns or creates the semaphore for the given domain
+func (dc *DomainConcurrency) getSlots(domain string) chan struct{} {
+	if domain == "" {
+		return nil
+	}
+
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+
+	if slots, exists := dc.slots[domain]; exists {
+		return slots
+	}
+
+	max := dc.perHost
+	if override, ok := dc.overrides[domain]; ok {
+		max = override
+	}
+	if dc.ceiling > 0 && (max <= 0 || max > dc.ceiling) {
+		max = dc.ceiling
+	}
+	if max <= 0 {
+		return nil
+	}
+
+	slots := make(chan struct{}, max)
+	dc.slots[domain] = slots
+	return slots
+}
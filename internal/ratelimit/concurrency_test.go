@@ -0,0 +1,102 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDomainConcurrency_CapsPerDomain(t *testing.T) {
+	dc := NewDomainConcurrency(2)
+
+	var current, peak int32
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			release, err := dc.Acquire(context.Background(), "https://example.com/page")
+			if err != nil {
+				t.Errorf("Acquire failed: %v", err)
+				return
+			}
+			defer release()
+
+			n := atomic.AddInt32(&current, 1)
+			for {
+				p := atomic.LoadInt32(&peak)
+				if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			atomic.AddInt32(&current, -1)
+		}()
+	}
+	wg.Wait()
+
+	if peak > 2 {
+		t.Errorf("Expected at most 2 concurrent requests, saw %d", peak)
+	}
+}
+
+func TestDomainConcurrency_OverrideAndTimeout(t *testing.T) {
+	dc := NewDomainConcurrency(2)
+	dc.SetMax("slow.example.com", 1)
+
+	release, err := dc.Acquire(context.Background(), "https://slow.example.com/a")
+	if err != nil {
+		t.Fatalf("Acquire failed: %v", err)
+	}
+	defer release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := dc.Acquire(ctx, "https://slow.example.com/b"); err == nil {
+		t.Error("Expected second acquire to time out with override of 1")
+	}
+
+	// Other domains are unaffected
+	other, err := dc.Acquire(context.Background(), "https://fast.example.com/")
+	if err != nil {
+		t.Fatalf("Acquire for other domain failed: %v", err)
+	}
+	other()
+}
+
+func TestDomainConcurrency_Unlimited(t *testing.T) {
+	dc := NewDomainConcurrency(0)
+	for i := 0; i < 100; i++ {
+		if _, err := dc.Acquire(context.Background(), "https://example.com/"); err != nil {
+			t.Fatalf("Acquire failed: %v", err)
+		}
+	}
+
+	var nilDC *DomainConcurrency
+	release, err := nilDC.Acquire(context.Background(), "https://example.com/")
+	if err != nil {
+		t.Fatalf("nil Acquire failed: %v", err)
+	}
+	release()
+}
+
+func TestDomainConcurrency_Ceiling(t *testing.T) {
+	dc := NewDomainConcurrency(0)
+	dc.SetMax("big.example.com", 10)
+	dc.SetCeiling(1)
+
+	for _, u := range []string{"https://example.com/", "https://big.example.com/"} {
+		release, err := dc.Acquire(context.Background(), u)
+		if err != nil {
+			t.Fatalf("Acquire failed: %v", err)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		if _, err := dc.Acquire(ctx, u); err == nil {
+			t.Errorf("Expected the ceiling of 1 to hold for %s", u)
+		}
+		cancel()
+		release()
+	}
+}
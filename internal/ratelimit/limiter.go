@@ -32,8 +32,13 @@ type RateLimiter interface {
 type DomainLimiter struct {
 	limiters map[string]*rate.Limiter
 	mu       sync.RWMutex
-	perHost  rate.Limit // Requests per second per host
-	burst    int        // Burst capacity
+	perHost  rate.Limit      // Requests per second per host
+	burst    int             // Burst capacity
+	explicit map[string]bool // Domains given their own limit with SetLimit
+
+	// Ceilings no limit may exceed, e.g. from an organisation policy; zero for none
+	maxRate  rate.Limit
+	maxBurst int
 }
 
 // NewDomainLimiter creates a new rate limiter with the specified per-host rate
@@ -47,6 +52,7 @@ func NewDomainLimiter(requestsPerSecond float64, burst int) *DomainLimiter {
 
 	return &DomainLimiter{
 		limiters: make(map[string]*rate.Limiter),
+		explicit: make(map[string]bool),
 		perHost:  rate.Limit(requestsPerSecond),
 		burst:    burst,
 	}
@@ -121,12 +127,69 @@ func (dl *DomainLimiter) SetLimit(domain string, requestsPerSecond float64, burs
 	dl.mu.Lock()
 	defer dl.mu.Unlock()
 
+	limit, burst := dl.clamp(rate.Limit(requestsPerSecond), burst)
+	dl.explicit[domain] = true
 	if limiter, exists := dl.limiters[domain]; exists {
-		limiter.SetLimit(rate.Limit(requestsPerSecond))
+		limiter.SetLimit(limit)
 		limiter.SetBurst(burst)
 	} else {
-		dl.limiters[domain] = rate.NewLimiter(rate.Limit(requestsPerSecond), burst)
+		dl.limiters[domain] = rate.NewLimiter(limit, burst)
+	}
+}
+
+// Default returns the limit applied to domains without their own
+func (dl *DomainLimiter) Default() (requestsPerSecond float64, burst int) {
+	dl.mu.RLock()
+	defer dl.mu.RUnlock()
+	return float64(dl.perHost), dl.burst
+}
+
+// SetDefault replaces the limit of every domain without one set by SetLimit.
+// A non-positive rate removes the limit, though never past the ceiling.
+func (dl *DomainLimiter) SetDefault(requestsPerSecond float64, burst int) {
+	dl.mu.Lock()
+	defer dl.mu.Unlock()
+
+	limit := rate.Limit(requestsPerSecond)
+	if requestsPerSecond <= 0 {
+		limit = rate.Inf
+	}
+	if burst <= 0 {
+		burst = dl.burst
+	}
+	dl.perHost, dl.burst = dl.clamp(limit, burst)
+	for domain, limiter := range dl.limiters {
+		if !dl.explicit[domain] {
+			limiter.SetLimit(dl.perHost)
+			limiter.SetBurst(dl.burst)
+		}
+	}
+}
+
+// SetCeiling caps the default and every per-domain limit, current and
+// later, at requestsPerSecond and burst. Zero leaves that part uncapped.
+func (dl *DomainLimiter) SetCeiling(requestsPerSecond float64, burst int) {
+	dl.mu.Lock()
+	defer dl.mu.Unlock()
+
+	dl.maxRate, dl.maxBurst = rate.Limit(requestsPerSecond), burst
+	dl.perHost, dl.burst = dl.clamp(dl.perHost, dl.burst)
+	for _, limiter := range dl.limiters {
+		limit, burst := dl.clamp(limiter.Limit(), limiter.Burst())
+		limiter.SetLimit(limit)
+		limiter.SetBurst(burst)
+	}
+}
+
+// clamp lowers a limit to the ceilings
+func (dl *DomainLimiter) clamp(limit rate.Limit, burst int) (rate.Limit, int) {
+	if dl.maxRate > 0 && limit > dl.maxRate {
+		limit = dl.maxRate
+	}
+	if dl.maxBurst > 0 && burst > dl.maxBurst {
+		burst = dl.maxBurst
 	}
+	return limit, burst
 }
 
 // extractDomain extracts the domain from a URL string
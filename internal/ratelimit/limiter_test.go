@@ -0,0 +1,27 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+
+	"golang.org/x/time/rate"
+)
+
+func TestDomainLimiter_SetDefaultKeepsExplicitAndCeiling(t *testing.T) {
+	dl := NewDomainLimiter(5, 10)
+	dl.SetLimit("slow.example", 1, 1)
+	dl.SetCeiling(20, 4)
+	dl.Wait(context.Background(), "https://other.example/") // creates a limiter at the old default
+
+	dl.SetDefault(0, 50)
+
+	if rps, burst := dl.Default(); rps != 20 || burst != 4 {
+		t.Errorf("Expected the default to stop at the ceiling, got %v/%d", rps, burst)
+	}
+	if got := dl.getLimiter("other.example").Limit(); got != 20 {
+		t.Errorf("Expected existing default limiters to follow, got %v", got)
+	}
+	if got := dl.getLimiter("slow.example").Limit(); got != rate.Limit(1) {
+		t.Errorf("Expected the explicit domain limit to be kept, got %v", got)
+	}
+}
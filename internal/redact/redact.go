@@ -0,0 +1,152 @@
+// internal/redact/redact.go
+//
+// Package redact masks personal data in pages before they are written or
+// sent anywhere: email addresses, phone numbers, national-ID-like numbers
+// and payment card numbers.
+package redact
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/law-makers/crawl/internal/engine/metadata"
+	"github.com/law-makers/crawl/pkg/models"
+)
+
+// Kinds of personal data that can be masked
+const (
+	KindEmail = "email"
+	KindPhone = "phone"
+	KindID    = "id"   // national-ID-like numbers: US SSN, UK National Insurance
+	KindCard  = "card" // payment card numbers that pass the Luhn check
+)
+
+// PII selects every kind
+const PII = "pii"
+
+// kinds lists every kind, in the order they are masked. Emails go first
+// as they may contain digits, cards before phones as both are digit runs.
+var kinds = []string{KindEmail, KindCard, KindID, KindPhone}
+
+var (
+	emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+	cardPattern  = regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`)
+	idPattern    = regexp.MustCompile(`\b\d{3}-\d{2}-\d{4}\b|\b[A-CEGHJ-PR-TW-Z]{2} ?\d{2} ?\d{2} ?\d{2} ?[A-D]\b`)
+	phonePattern = regexp.MustCompile(`(?:\+\d|\(\d|\b\d)[\d\s().-]{5,}\d\b`)
+	datePattern  = regexp.MustCompile(`^\d{4}[-/.]\d{1,2}[-/.]\d{1,2}$|^\d{1,2}[-/.]\d{1,2}[-/.]\d{4}$`)
+)
+
+// Redactor masks the kinds of personal data it was created with
+type Redactor struct {
+	kinds map[string]bool
+}
+
+// Parse builds a Redactor from a comma-separated list of kinds, where
+// "pii" stands for all of them
+func Parse(spec string) (*Redactor, error) {
+	r := &Redactor{kinds: map[string]bool{}}
+	for _, k := range strings.Split(spec, ",") {
+		k = strings.ToLower(strings.TrimSpace(k))
+		switch k {
+		case "":
+		case PII:
+			for _, kind := range kinds {
+				r.kinds[kind] = true
+			}
+		case KindEmail, KindPhone, KindID, KindCard:
+			r.kinds[k] = true
+		default:
+			return nil, fmt.Errorf("invalid redaction %q (must be pii, email, phone, id or card)", k)
+		}
+	}
+	if len(r.kinds) == 0 {
+		return nil, fmt.Errorf("no redactions given")
+	}
+	return r, nil
+}
+
+// String masks personal data in s, replacing each match with e.g. "[email]"
+func (r *Redactor) String(s string) string {
+	if r.kinds[KindEmail] {
+		s = emailPattern.ReplaceAllString(s, "["+KindEmail+"]")
+	}
+	if r.kinds[KindCard] {
+		s = cardPattern.ReplaceAllStringFunc(s, func(m string) string {
+			if luhn(m) {
+				return "[" + KindCard + "]"
+			}
+			return m
+		})
+	}
+	if r.kinds[KindID] {
+		s = idPattern.ReplaceAllString(s, "["+KindID+"]")
+	}
+	if r.kinds[KindPhone] {
+		s = phonePattern.ReplaceAllStringFunc(s, func(m string) string {
+			if isPhone(m) {
+				return "[" + KindPhone + "]"
+			}
+			return m
+		})
+	}
+	return s
+}
+
+// Page masks the text a page carries: title, content, HTML, structured
+// fields and matched elements. Its hashes are recomputed so they don't
+// describe the unredacted text.
+func (r *Redactor) Page(p *models.PageData) {
+	p.Title = r.String(p.Title)
+	p.Content = r.String(p.Content)
+	p.HTML = r.String(p.HTML)
+	for _, row := range p.Structured {
+		for k, v := range row {
+			row[k] = r.String(v)
+		}
+	}
+	for i := range p.Data {
+		p.Data[i].Text = r.String(p.Data[i].Text)
+		p.Data[i].HTML = r.String(p.Data[i].HTML)
+	}
+	if p.ContentHash != "" {
+		metadata.Hash(p)
+	}
+}
+
+// isPhone tells phone numbers from other digit runs the pattern matches,
+// such as dates and long plain numbers
+func isPhone(m string) bool {
+	digits := 0
+	for _, c := range m {
+		if c >= '0' && c <= '9' {
+			digits++
+		}
+	}
+	if digits < 7 || digits > 15 || datePattern.MatchString(m) {
+		return false
+	}
+	// A bare run of digits needs a country code to read as a phone number
+	return strings.HasPrefix(m, "+") || strings.ContainsAny(m, " ().-")
+}
+
+// luhn reports whether the digits of s pass the Luhn checksum
+func luhn(s string) bool {
+	sum, double := 0, false
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
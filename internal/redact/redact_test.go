@@ -0,0 +1,66 @@
+package redact
+
+import (
+	"testing"
+
+	"github.com/law-makers/crawl/pkg/models"
+)
+
+func TestRedactor_String(t *testing.T) {
+	r, err := Parse("pii")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	tests := []struct {
+		in, want string
+	}{
+		{"Write to jane.doe+news@example.co.uk today", "Write to [email] today"},
+		{"Call +44 20 7946 0958 or (555) 123-4567", "Call [phone] or [phone]"},
+		{"SSN 123-45-6789, NI AB 12 34 56 C", "SSN [id], NI [id]"},
+		{"Card 4111 1111 1111 1111 expires soon", "Card [card] expires soon"},
+		{"Order 4111 1111 1111 1112 shipped", "Order 4111 1111 1111 1112 shipped"},
+		{"Published 2024-01-15, 1,299 views, SKU 12345", "Published 2024-01-15, 1,299 views, SKU 12345"},
+		{"Invoice 1234567890", "Invoice 1234567890"},
+	}
+	for _, tt := range tests {
+		if got := r.String(tt.in); got != tt.want {
+			t.Errorf("String(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	r, err := Parse("email")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if got := r.String("a@example.com +1 555 123 4567"); got != "[email] +1 555 123 4567" {
+		t.Errorf("Expected only emails masked, got %q", got)
+	}
+	for _, bad := range []string{"", "names"} {
+		if _, err := Parse(bad); err == nil {
+			t.Errorf("Parse(%q) succeeded, want error", bad)
+		}
+	}
+}
+
+func TestRedactor_Page(t *testing.T) {
+	r, _ := Parse("pii")
+	page := &models.PageData{
+		Content:     "Contact bob@example.com",
+		HTML:        `<a href="mailto:bob@example.com">bob@example.com</a>`,
+		Structured:  []map[string]string{{"email": "bob@example.com", "name": "Bob"}},
+		Data:        []models.SelectionData{{Text: "bob@example.com"}},
+		ContentHash: "stale",
+	}
+	r.Page(page)
+	if page.Content != "Contact [email]" || page.Structured[0]["email"] != "[email]" || page.Structured[0]["name"] != "Bob" {
+		t.Errorf("Unexpected page: %+v", page)
+	}
+	if page.HTML != `<a href="mailto:[email]">[email]</a>` || page.Data[0].Text != "[email]" {
+		t.Errorf("Expected HTML and matches masked, got %q and %q", page.HTML, page.Data[0].Text)
+	}
+	if page.ContentHash == "stale" {
+		t.Error("Expected the content hash to be recomputed")
+	}
+}
@@ -0,0 +1,126 @@
+// internal/replay/raw.go
+package replay
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+)
+
+// HeadersExt is appended to a raw dump's path for the file holding the
+// status line and headers
+const HeadersExt = ".headers"
+
+// RawDump is an http.RoundTripper that saves the exact bytes of a response
+// body, before decompression, to Path, and the response's status line and
+// headers to Path+HeadersExt. Only requests whose context was marked with
+// WithRawDump are saved; the rest pass through untouched. A later response
+// (after a redirect, say) replaces an earlier one, so the files describe
+// the last hop.
+//
+// It must wrap the *http.Transport directly: it asks for gzip itself so the
+// transport hands over the compressed body, then decompresses it the way
+// the transport would have for the layers above.
+type RawDump struct {
+	Path string
+	Next http.RoundTripper
+
+	mu sync.Mutex // one response's body and headers written at a time
+}
+
+type rawDumpKey struct{}
+
+// WithRawDump marks requests made with ctx as ones a RawDump saves, e.g.
+// the fetch of the page itself but not its iframes or robots.txt
+func WithRawDump(ctx context.Context) context.Context {
+	return context.WithValue(ctx, rawDumpKey{}, true)
+}
+
+// NewRawDump creates a RawDump wrapping next (http.DefaultTransport if nil)
+func NewRawDump(path string, next http.RoundTripper) *RawDump {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &RawDump{Path: path, Next: next}
+}
+
+// RoundTrip performs the request, saves the raw response and returns it decoded
+func (d *RawDump) RoundTrip(req *http.Request) (*http.Response, error) {
+	if dump, _ := req.Context().Value(rawDumpKey{}).(bool); !dump {
+		return d.Next.RoundTrip(req)
+	}
+
+	// With Accept-Encoding set by the caller the transport leaves the body alone
+	askedGzip := false
+	if req.Header.Get("Accept-Encoding") == "" && req.Method != http.MethodHead {
+		req = req.Clone(req.Context())
+		req.Header.Set("Accept-Encoding", "gzip")
+		askedGzip = true
+	}
+
+	resp, err := d.Next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response for --raw-output: %w", err)
+	}
+	if err := d.save(resp, raw); err != nil {
+		// Best effort, like recording; never fail the real request
+		log.Warn().Err(err).Str("file", d.Path).Msg("Failed to save raw response")
+	}
+
+	body := raw
+	if askedGzip && strings.EqualFold(resp.Header.Get("Content-Encoding"), "gzip") {
+		zr, err := gzip.NewReader(bytes.NewReader(raw))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress response: %w", err)
+		}
+		if body, err = io.ReadAll(zr); err != nil {
+			return nil, fmt.Errorf("failed to decompress response: %w", err)
+		}
+		resp.Header.Del("Content-Encoding")
+		resp.Header.Del("Content-Length")
+		resp.ContentLength = -1
+		resp.Uncompressed = true
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	return resp, nil
+}
+
+func (d *RawDump) save(resp *http.Response, raw []byte) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if dir := filepath.Dir(d.Path); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+	if err := os.WriteFile(d.Path, raw, 0644); err != nil {
+		return err
+	}
+
+	var head bytes.Buffer
+	fmt.Fprintf(&head, "%s %s\r\n", resp.Proto, resp.Status)
+	if err := resp.Header.Write(&head); err != nil {
+		return err
+	}
+	if err := os.WriteFile(d.Path+HeadersExt, head.Bytes(), 0644); err != nil {
+		return err
+	}
+
+	log.Debug().Str("file", d.Path).Int("bytes", len(raw)).Int("status", resp.StatusCode).Msg("Saved raw response")
+	return nil
+}
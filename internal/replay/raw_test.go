@@ -0,0 +1,65 @@
+package replay
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRawDump(t *testing.T) {
+	var compressed bytes.Buffer
+	zw := gzip.NewWriter(&compressed)
+	zw.Write([]byte("<html>caf\xe9</html>"))
+	zw.Close()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Accept-Encoding") != "gzip" {
+			t.Errorf("Expected gzip to be asked for, got %q", r.Header.Get("Accept-Encoding"))
+		}
+		w.Header().Set("Content-Type", "text/html; charset=iso-8859-1")
+		w.Header().Set("Content-Encoding", "gzip")
+		w.WriteHeader(http.StatusForbidden)
+		w.Write(compressed.Bytes())
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "response.bin")
+	client := &http.Client{Transport: NewRawDump(path, nil)}
+	// Unmarked requests are not saved
+	if resp, err := client.Get(server.URL); err == nil {
+		resp.Body.Close()
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("Expected an unmarked request not to be saved, got %v", err)
+	}
+
+	req, _ := http.NewRequestWithContext(WithRawDump(context.Background()), http.MethodGet, server.URL, nil)
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if string(body) != "<html>caf\xe9</html>" || resp.Header.Get("Content-Encoding") != "" {
+		t.Errorf("Expected the body decoded for the caller, got %q (%q)", body, resp.Header.Get("Content-Encoding"))
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil || !bytes.Equal(raw, compressed.Bytes()) {
+		t.Errorf("Expected the compressed bytes saved, got %d bytes (%v)", len(raw), err)
+	}
+	head, err := os.ReadFile(path + HeadersExt)
+	if err != nil {
+		t.Fatalf("Expected a headers file: %v", err)
+	}
+	if !strings.HasPrefix(string(head), "HTTP/1.1 403 Forbidden\r\n") || !strings.Contains(string(head), "Content-Encoding: gzip") {
+		t.Errorf("Unexpected headers file:\n%s", head)
+	}
+}
@@ -0,0 +1,146 @@
+// internal/replay/replay.go
+package replay
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/rs/zerolog/log"
+)
+
+// ErrNotRecorded is returned in replay mode when no recording exists for a request
+var ErrNotRecorded = errors.New("no recorded response")
+
+// entryMeta is stored next to each recorded body
+type entryMeta struct {
+	Method     string              `json:"method"`
+	URL        string              `json:"url"`
+	StatusCode int                 `json:"status_code"`
+	Header     map[string][]string `json:"header,omitempty"`
+}
+
+// Key returns the file name stem used to store a recording for method and URL
+func Key(method, url string) string {
+	sum := sha256.Sum256([]byte(method + " " + url))
+	return hex.EncodeToString(sum[:])[:16]
+}
+
+// Recorder is an http.RoundTripper that saves every response body to Dir
+// before handing it back to the caller
+type Recorder struct {
+	Dir  string
+	Next http.RoundTripper
+}
+
+// NewRecorder creates a Recorder wrapping next (http.DefaultTransport if nil)
+func NewRecorder(dir string, next http.RoundTripper) *Recorder {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &Recorder{Dir: dir, Next: next}
+}
+
+// RoundTrip performs the request and records the response
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := r.Next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response for recording: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	meta := entryMeta{
+		Method:     req.Method,
+		URL:        req.URL.String(),
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+	}
+	if err := r.save(meta, body); err != nil {
+		// Recording is best effort; never fail the real request
+		log.Warn().Err(err).Str("url", meta.URL).Msg("Failed to record response")
+	}
+
+	return resp, nil
+}
+
+func (r *Recorder) save(meta entryMeta, body []byte) error {
+	if err := os.MkdirAll(r.Dir, 0755); err != nil {
+		return err
+	}
+
+	key := Key(meta.Method, meta.URL)
+	metaJSON, err := json.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(r.Dir, key+".body"), body, 0644); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(r.Dir, key+".json"), metaJSON, 0644); err != nil {
+		return err
+	}
+
+	log.Debug().Str("url", meta.URL).Str("key", key).Msg("Recorded response")
+	return nil
+}
+
+// Replayer is an http.RoundTripper that serves responses previously saved
+// by a Recorder and never touches the network
+type Replayer struct {
+	Dir string
+}
+
+// NewReplayer creates a Replayer reading from dir
+func NewReplayer(dir string) *Replayer {
+	return &Replayer{Dir: dir}
+}
+
+// RoundTrip returns the recorded response for the request
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	key := Key(req.Method, req.URL.String())
+
+	metaJSON, err := os.ReadFile(filepath.Join(r.Dir, key+".json"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("%w for %s %s", ErrNotRecorded, req.Method, req.URL)
+		}
+		return nil, err
+	}
+
+	var meta entryMeta
+	if err := json.Unmarshal(metaJSON, &meta); err != nil {
+		return nil, fmt.Errorf("corrupt recording %s: %w", key, err)
+	}
+
+	body, err := os.ReadFile(filepath.Join(r.Dir, key+".body"))
+	if err != nil {
+		return nil, fmt.Errorf("missing recorded body %s: %w", key, err)
+	}
+
+	log.Debug().Str("url", meta.URL).Str("key", key).Msg("Replaying recorded response")
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", meta.StatusCode, http.StatusText(meta.StatusCode)),
+		StatusCode:    meta.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header(meta.Header),
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
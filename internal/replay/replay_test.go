@@ -0,0 +1,57 @@
+package replay
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRecordThenReplay(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte("<html><title>Recorded</title></html>"))
+	}))
+
+	dir := t.TempDir()
+	recClient := &http.Client{Transport: NewRecorder(dir, nil)}
+
+	resp, err := recClient.Get(server.URL + "/page")
+	if err != nil {
+		t.Fatalf("Record request failed: %v", err)
+	}
+	io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	// Replay must not need the network
+	server.Close()
+
+	playClient := &http.Client{Transport: NewReplayer(dir)}
+	resp, err = playClient.Get(server.URL + "/page")
+	if err != nil {
+		t.Fatalf("Replay request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if string(body) != "<html><title>Recorded</title></html>" {
+		t.Errorf("Unexpected replayed body: %q", string(body))
+	}
+	if resp.StatusCode != http.StatusCreated {
+		t.Errorf("Expected status 201, got %d", resp.StatusCode)
+	}
+	if resp.Header.Get("Content-Type") != "text/html" {
+		t.Errorf("Expected Content-Type text/html, got %q", resp.Header.Get("Content-Type"))
+	}
+}
+
+func TestReplay_NotRecorded(t *testing.T) {
+	playClient := &http.Client{Transport: NewReplayer(t.TempDir())}
+
+	_, err := playClient.Get("http://example.com/missing")
+	if !errors.Is(err, ErrNotRecorded) {
+		t.Errorf("Expected ErrNotRecorded, got %v", err)
+	}
+}
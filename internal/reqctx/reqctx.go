@@ -1,3 +1,8 @@
+// internal/reqctx/reqctx.go
+//
+// Package reqctx identifies the run (job ID) and each page request
+// (request ID), so logs, request log entries, PageData and notifications
+// can be correlated with the invocation that produced them.
 package reqctx
 
 import (
@@ -6,6 +11,8 @@ import (
 	"encoding/hex"
 	"fmt"
 	"time"
+
+	"github.com/law-makers/crawl/pkg/models"
 )
 
 type key int
@@ -13,6 +20,7 @@ type key int
 const requestKey key = 0
 
 type RequestContext struct {
+	JobID     string
 	RequestID string
 	StartTime time.Time
 }
@@ -24,8 +32,23 @@ func WithRequestContext(ctx context.Context) context.Context {
 	})
 }
 
+// WithIDs returns ctx carrying the given job and request IDs
+func WithIDs(ctx context.Context, jobID, requestID string) context.Context {
+	return context.WithValue(ctx, requestKey, &RequestContext{
+		JobID:     jobID,
+		RequestID: requestID,
+		StartTime: time.Now(),
+	})
+}
+
+// From returns the request context in ctx, if there is one
+func From(ctx context.Context) (*RequestContext, bool) {
+	rc, ok := ctx.Value(requestKey).(*RequestContext)
+	return rc, ok
+}
+
 func GetRequestContext(ctx context.Context) *RequestContext {
-	if rc, ok := ctx.Value(requestKey).(*RequestContext); ok {
+	if rc, ok := From(ctx); ok {
 		return rc
 	}
 	return &RequestContext{
@@ -34,12 +57,58 @@ func GetRequestContext(ctx context.Context) *RequestContext {
 	}
 }
 
+// NewID returns a random identifier for a job or request
+func NewID() string {
+	return generateID()
+}
+
 func generateID() string {
 	b := make([]byte, 8)
 	rand.Read(b)
 	return hex.EncodeToString(b)
 }
 
+// Fetcher is the part of engine.Scraper a Stamper needs; engine itself
+// can't be imported here as the request log, which reads the IDs, is below it
+type Fetcher interface {
+	Fetch(opts models.RequestOptions) (*models.PageData, error)
+	Name() string
+}
+
+// Stamper wraps a scraper, giving each request a request ID (unless the
+// caller set one) and the job ID, and copying both onto the page. It goes
+// outside retries and refetches so all the tries of a URL share its ID.
+type Stamper struct {
+	next  Fetcher
+	jobID string
+}
+
+// Stamp wraps next so its requests and pages carry jobID and a request ID
+func Stamp(next Fetcher, jobID string) *Stamper {
+	return &Stamper{next: next, jobID: jobID}
+}
+
+// Name returns the name of the wrapped scraper
+func (s *Stamper) Name() string {
+	return s.next.Name()
+}
+
+// Fetch assigns the IDs and fetches the page
+func (s *Stamper) Fetch(opts models.RequestOptions) (*models.PageData, error) {
+	if opts.JobID == "" {
+		opts.JobID = s.jobID
+	}
+	if opts.RequestID == "" {
+		opts.RequestID = generateID()
+	}
+	data, err := s.next.Fetch(opts)
+	if data != nil {
+		data.JobID = opts.JobID
+		data.RequestID = opts.RequestID
+	}
+	return data, err
+}
+
 // RequestError wraps an error with request context
 type RequestError struct {
 	RequestID string
@@ -0,0 +1,51 @@
+package reqctx
+
+import (
+	"context"
+	"testing"
+
+	"github.com/law-makers/crawl/pkg/models"
+)
+
+type recordingScraper struct {
+	seen []models.RequestOptions
+}
+
+func (r *recordingScraper) Name() string { return "recording" }
+
+func (r *recordingScraper) Fetch(opts models.RequestOptions) (*models.PageData, error) {
+	r.seen = append(r.seen, opts)
+	return &models.PageData{URL: opts.URL}, nil
+}
+
+func TestStamp_AssignsIDs(t *testing.T) {
+	next := &recordingScraper{}
+	s := Stamp(next, "job-1")
+
+	first, _ := s.Fetch(models.RequestOptions{URL: "https://example.com/a"})
+	second, _ := s.Fetch(models.RequestOptions{URL: "https://example.com/b"})
+	if first.JobID != "job-1" || second.JobID != "job-1" {
+		t.Errorf("Expected job-1 on both pages, got %q and %q", first.JobID, second.JobID)
+	}
+	if first.RequestID == "" || first.RequestID == second.RequestID {
+		t.Errorf("Expected distinct request IDs, got %q and %q", first.RequestID, second.RequestID)
+	}
+	if next.seen[0].RequestID != first.RequestID || next.seen[0].JobID != "job-1" {
+		t.Errorf("Expected the IDs to be passed down, got %+v", next.seen[0])
+	}
+
+	kept, _ := s.Fetch(models.RequestOptions{URL: "https://example.com/c", RequestID: "given"})
+	if kept.RequestID != "given" {
+		t.Errorf("Expected the caller's request ID to be kept, got %q", kept.RequestID)
+	}
+}
+
+func TestFrom(t *testing.T) {
+	if _, ok := From(context.Background()); ok {
+		t.Error("Expected no request context in a bare context")
+	}
+	rc, ok := From(WithIDs(context.Background(), "job-1", "req-1"))
+	if !ok || rc.JobID != "job-1" || rc.RequestID != "req-1" {
+		t.Errorf("Unexpected request context: %+v", rc)
+	}
+}
@@ -0,0 +1,179 @@
+// internal/reqlog/reqlog.go
+package reqlog
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/law-makers/crawl/internal/httpcache"
+	"github.com/law-makers/crawl/internal/proxy"
+	"github.com/law-makers/crawl/internal/reqctx"
+)
+
+// Cache states recorded for each request
+const (
+	CacheMiss        = "miss"        // fetched from the network
+	CacheHit         = "hit"         // served from crawl's cache
+	CacheRevalidated = "revalidated" // conditional request answered 304 Not Modified
+	CacheReplay      = "replay"      // served from a --replay recording
+)
+
+// Entry is one line of the request log
+type Entry struct {
+	Time       time.Time `json:"time"`
+	URL        string    `json:"url"`
+	Engine     string    `json:"engine"`
+	Method     string    `json:"method"`
+	Status     int       `json:"status,omitempty"`
+	Bytes      int64     `json:"bytes"`
+	DurationMs int64     `json:"duration_ms"`
+	Proxy      string    `json:"proxy,omitempty"`
+	Cache      string    `json:"cache"`
+	Error      string    `json:"error,omitempty"`
+	JobID      string    `json:"job_id,omitempty"`
+	RequestID  string    `json:"request_id,omitempty"` // Page request this is part of, as in PageData
+}
+
+// Logger appends entries as JSON lines. It is safe for concurrent use; a nil
+// Logger discards entries.
+type Logger struct {
+	mu   sync.Mutex
+	file *os.File
+	enc  *json.Encoder
+}
+
+// Open creates (or appends to) the request log at path
+func Open(path string) (*Logger, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open request log: %w", err)
+	}
+	return &Logger{file: f, enc: json.NewEncoder(f)}, nil
+}
+
+// Log writes one entry
+func (l *Logger) Log(e Entry) {
+	if l == nil {
+		return
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.enc.Encode(e)
+}
+
+// Close closes the underlying file
+func (l *Logger) Close() error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.file.Close()
+}
+
+// Transport is an http.RoundTripper that logs every request made through it.
+// The entry is written when the response body is closed, so byte counts and
+// durations cover the full transfer.
+type Transport struct {
+	Next   http.RoundTripper
+	Log    *Logger
+	Engine string
+	Cache  string // cache state to report for network responses (default CacheMiss)
+}
+
+// Wrap returns next wrapped in a logging Transport, or next unchanged if l is nil
+func Wrap(next http.RoundTripper, l *Logger, engine string) http.RoundTripper {
+	if l == nil {
+		return next
+	}
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &Transport{Next: next, Log: l, Engine: engine}
+}
+
+// RoundTrip performs the request and arranges for it to be logged
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	entry := Entry{
+		Time:   start,
+		URL:    req.URL.String(),
+		Engine: t.Engine,
+		Method: req.Method,
+		Proxy:  proxyFor(t.Next, req),
+		Cache:  t.Cache,
+	}
+	if entry.Cache == "" {
+		entry.Cache = CacheMiss
+	}
+	if rc, ok := reqctx.From(req.Context()); ok {
+		entry.JobID, entry.RequestID = rc.JobID, rc.RequestID
+	}
+
+	resp, err := t.Next.RoundTrip(req)
+	if err != nil {
+		entry.DurationMs = time.Since(start).Milliseconds()
+		entry.Error = err.Error()
+		t.Log.Log(entry)
+		return nil, err
+	}
+
+	entry.Status = resp.StatusCode
+	if resp.StatusCode == http.StatusNotModified {
+		entry.Cache = CacheRevalidated
+	}
+	if state := resp.Header.Get(httpcache.XFromCache); state != "" {
+		entry.Cache = state // answered by --cache-policy http
+	}
+	resp.Body = &countingBody{ReadCloser: resp.Body, onClose: func(n int64) {
+		entry.Bytes = n
+		entry.DurationMs = time.Since(start).Milliseconds()
+		t.Log.Log(entry)
+	}}
+	return resp, nil
+}
+
+// proxyFor reports the proxy the next transport will use for req, if any
+func proxyFor(next http.RoundTripper, req *http.Request) string {
+	if u := proxy.FromContext(req.Context()); u != nil {
+		return u.Redacted()
+	}
+	tr, ok := next.(*http.Transport)
+	if !ok || tr.Proxy == nil {
+		return ""
+	}
+	u, err := tr.Proxy(req)
+	if err != nil || u == nil {
+		return ""
+	}
+	return u.Redacted()
+}
+
+// countingBody counts bytes read and reports the total once on Close
+type countingBody struct {
+	io.ReadCloser
+	n       int64
+	once    sync.Once
+	onClose func(int64)
+}
+
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	atomic.AddInt64(&b.n, int64(n))
+	return n, err
+}
+
+func (b *countingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(func() { b.onClose(atomic.LoadInt64(&b.n)) })
+	return err
+}
@@ -0,0 +1,89 @@
+package reqlog
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/law-makers/crawl/internal/reqctx"
+)
+
+func readEntries(t *testing.T, path string) []Entry {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("open log: %v", err)
+	}
+	defer f.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			t.Fatalf("invalid log line %q: %v", scanner.Text(), err)
+		}
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+func TestTransport_LogsRequests(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/cached" {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Write([]byte("hello world"))
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "requests.jsonl")
+	l, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+
+	client := &http.Client{Transport: Wrap(http.DefaultTransport, l, "static")}
+	for _, p := range []string{"/page", "/cached"} {
+		req, _ := http.NewRequestWithContext(reqctx.WithIDs(context.Background(), "job-1", "req"+p), "GET", server.URL+p, nil)
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("GET failed: %v", err)
+		}
+		io.ReadAll(resp.Body)
+		resp.Body.Close()
+	}
+	// Connection failures are logged too
+	client.Get("http://127.0.0.1:1/unreachable")
+	l.Close()
+
+	entries := readEntries(t, path)
+	if len(entries) != 3 {
+		t.Fatalf("Expected 3 entries, got %d", len(entries))
+	}
+	if e := entries[0]; e.Status != 200 || e.Bytes != 11 || e.Engine != "static" || e.Method != "GET" || e.Cache != CacheMiss {
+		t.Errorf("Unexpected entry: %+v", e)
+	}
+	if entries[0].JobID != "job-1" || entries[0].RequestID != "req/page" {
+		t.Errorf("Expected the IDs from the request context, got %+v", entries[0])
+	}
+	if entries[1].Cache != CacheRevalidated {
+		t.Errorf("Expected 304 to be logged as revalidated, got %q", entries[1].Cache)
+	}
+	if entries[2].Error == "" || entries[2].Status != 0 {
+		t.Errorf("Expected connection error entry, got %+v", entries[2])
+	}
+}
+
+func TestWrap_NilLogger(t *testing.T) {
+	if Wrap(http.DefaultTransport, nil, "static") != http.DefaultTransport {
+		t.Error("Expected transport to be returned unchanged without a logger")
+	}
+}
@@ -3,6 +3,7 @@ package retry
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math"
 	"net/http"
@@ -73,6 +74,16 @@ func WithRetry(ctx context.Context, cfg Config, fn func() error) error {
 		if attempt < cfg.MaxAttempts-1 {
 			backoff := calculateBackoff(attempt, cfg)
 
+			// Honor a server-provided delay (e.g. Retry-After) when it is longer
+			if ra, ok := err.(RetryAfterer); ok {
+				if wait := ra.RetryAfter(); wait > backoff {
+					backoff = wait
+				}
+				if backoff > cfg.MaxBackoff {
+					backoff = cfg.MaxBackoff
+				}
+			}
+
 			log.Debug().
 				Int("attempt", attempt+1).
 				Int("max_attempts", cfg.MaxAttempts).
@@ -117,8 +128,15 @@ func shouldRetry(err error, cfg Config) bool {
 		return false
 	}
 
-	// Check for errors implementing StatusCoder (like HTTPError or DownloadError)
-	if sc, ok := err.(StatusCoder); ok {
+	// Cancellation is a caller decision, never retry it
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	// Check for errors implementing StatusCoder (like HTTPError or DownloadError).
+	// A zero status means no response was received, so fall through to the
+	// network error checks below.
+	if sc, ok := err.(StatusCoder); ok && sc.GetStatusCode() > 0 {
 		statusCode := sc.GetStatusCode()
 		for _, code := range cfg.RetryableStatusCodes {
 			if statusCode == code {
@@ -183,6 +201,11 @@ type StatusCoder interface {
 	GetStatusCode() int
 }
 
+// RetryAfterer is an interface for errors that carry a server-requested delay
+type RetryAfterer interface {
+	RetryAfter() time.Duration
+}
+
 func (e HTTPError) Error() string {
 	if e.Message != "" {
 		return fmt.Sprintf("HTTP %d: %s - %s", e.StatusCode, e.Status, e.Message)
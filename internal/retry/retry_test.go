@@ -0,0 +1,135 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+)
+
+// statusErr is an error with a status code and an optional server delay
+type statusErr struct {
+	status int
+	wait   time.Duration
+}
+
+func (e statusErr) Error() string             { return fmt.Sprintf("status %d", e.status) }
+func (e statusErr) GetStatusCode() int        { return e.status }
+func (e statusErr) RetryAfter() time.Duration { return e.wait }
+
+// timeoutErr is a network timeout reported with no status, like a
+// DownloadError for a request that never got a response
+type timeoutErr struct{}
+
+func (timeoutErr) Error() string      { return "timeout" }
+func (timeoutErr) Timeout() bool      { return true }
+func (timeoutErr) GetStatusCode() int { return 0 }
+
+type temporaryErr bool
+
+func (e temporaryErr) Error() string   { return "temporary" }
+func (e temporaryErr) Temporary() bool { return bool(e) }
+
+func TestShouldRetry(t *testing.T) {
+	cfg := DefaultConfig()
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"canceled", context.Canceled, false},
+		{"wrapped canceled", fmt.Errorf("fetch: %w", context.Canceled), false},
+		{"deadline exceeded", context.DeadlineExceeded, true},
+		{"429", statusErr{status: 429}, true},
+		{"503", statusErr{status: 503}, true},
+		{"404", statusErr{status: 404}, false},
+		{"403", statusErr{status: 403}, false},
+		{"no response", statusErr{status: 0}, true},
+		{"no response, timeout", timeoutErr{}, true},
+		{"legacy HTTPError 502", NewHTTPError(502, "Bad Gateway", ""), true},
+		{"legacy HTTPError 400", NewHTTPError(400, "Bad Request", ""), false},
+		{"net timeout", &net.DNSError{Err: "timeout", IsTimeout: true}, true},
+		{"temporary", temporaryErr(true), true},
+		{"not temporary", temporaryErr(false), false},
+		{"unknown", errors.New("connection reset"), true},
+	}
+	for _, tt := range tests {
+		if got := shouldRetry(tt.err, cfg); got != tt.want {
+			t.Errorf("%s: shouldRetry(%v) = %v, want %v", tt.name, tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestCalculateBackoff(t *testing.T) {
+	cfg := Config{InitialBackoff: time.Second, MaxBackoff: 5 * time.Second, Multiplier: 2}
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{0, time.Second},
+		{1, 2 * time.Second},
+		{2, 4 * time.Second},
+		{3, 5 * time.Second},
+	}
+	for _, tt := range tests {
+		if got := calculateBackoff(tt.attempt, cfg); got != tt.want {
+			t.Errorf("calculateBackoff(%d) = %v, want %v", tt.attempt, got, tt.want)
+		}
+	}
+}
+
+func TestWithRetry_StopsOnNonRetryable(t *testing.T) {
+	calls := 0
+	err := WithRetry(context.Background(), Config{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond, Multiplier: 1, RetryableStatusCodes: []int{503}}, func() error {
+		calls++
+		return statusErr{status: 404}
+	})
+	if err == nil || calls != 1 {
+		t.Errorf("Expected one call and an error, got %d calls, err %v", calls, err)
+	}
+}
+
+func TestWithRetry_HonorsRetryAfter(t *testing.T) {
+	cfg := Config{MaxAttempts: 2, InitialBackoff: time.Millisecond, MaxBackoff: 100 * time.Millisecond, Multiplier: 1, RetryableStatusCodes: []int{429}}
+	tests := []struct {
+		name     string
+		wait     time.Duration
+		min, max time.Duration
+	}{
+		{"longer than backoff", 50 * time.Millisecond, 50 * time.Millisecond, time.Second},
+		{"capped at MaxBackoff", time.Hour, 100 * time.Millisecond, time.Second},
+	}
+	for _, tt := range tests {
+		calls := 0
+		start := time.Now()
+		err := WithRetry(context.Background(), cfg, func() error {
+			calls++
+			if calls == 1 {
+				return statusErr{status: 429, wait: tt.wait}
+			}
+			return nil
+		})
+		elapsed := time.Since(start)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+		}
+		if elapsed < tt.min || elapsed > tt.max {
+			t.Errorf("%s: waited %v, want between %v and %v", tt.name, elapsed, tt.min, tt.max)
+		}
+	}
+}
+
+func TestWithRetry_CanceledDuringBackoff(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cfg := Config{MaxAttempts: 3, InitialBackoff: time.Hour, MaxBackoff: time.Hour, Multiplier: 1, RetryableStatusCodes: []int{503}}
+	err := WithRetry(ctx, cfg, func() error {
+		cancel()
+		return statusErr{status: 503}
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
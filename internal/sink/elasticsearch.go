@@ -0,0 +1,248 @@
+// internal/sink/elasticsearch.go
+package sink
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	outpututil "github.com/law-makers/crawl/internal/utils/output"
+	"github.com/law-makers/crawl/pkg/models"
+	"github.com/rs/zerolog/log"
+)
+
+// esBulkSize is the number of buffered documents that triggers a bulk request
+const esBulkSize = 100
+
+// esIndexMapping is applied when the index does not exist yet. Content and
+// title are analyzed for full-text search; URLs, headers and metadata values
+// are keywords so they can be filtered and aggregated on exactly.
+var esIndexMapping = map[string]interface{}{
+	"mappings": map[string]interface{}{
+		"dynamic_templates": []interface{}{
+			map[string]interface{}{
+				"string_maps": map[string]interface{}{
+					"path_match":         []string{"headers.*", "metadata.*"},
+					"match_mapping_type": "string",
+					"mapping":            map[string]interface{}{"type": "keyword", "ignore_above": 1024},
+				},
+			},
+		},
+		"properties": map[string]interface{}{
+			"url":         map[string]interface{}{"type": "keyword"},
+			"status_code": map[string]interface{}{"type": "integer"},
+			"title": map[string]interface{}{
+				"type":   "text",
+				"fields": map[string]interface{}{"raw": map[string]interface{}{"type": "keyword", "ignore_above": 512}},
+			},
+			"content":          map[string]interface{}{"type": "text"},
+			"links":            map[string]interface{}{"type": "keyword", "ignore_above": 2048},
+			"images":           map[string]interface{}{"type": "keyword", "ignore_above": 2048},
+			"scripts":          map[string]interface{}{"type": "keyword", "ignore_above": 2048},
+			"headers":          map[string]interface{}{"type": "object"},
+			"metadata":         map[string]interface{}{"type": "object"},
+			"fetched_at":       map[string]interface{}{"type": "date"},
+			"response_time_ms": map[string]interface{}{"type": "long"},
+		},
+	},
+}
+
+// esSink bulk-indexes pages into an Elasticsearch or OpenSearch index. Each
+// document is keyed by a hash of its URL, so re-crawling a page updates the
+// existing document instead of adding a duplicate.
+type esSink struct {
+	client   *http.Client
+	endpoint string
+	index    string
+	username string
+	password string
+
+	mu      sync.Mutex
+	pending bytes.Buffer
+	count   int
+}
+
+func newESSink(ctx context.Context, u *url.URL) (*esSink, error) {
+	index := strings.Trim(u.Path, "/")
+	if index == "" || strings.Contains(index, "/") {
+		return nil, fmt.Errorf("elasticsearch sink requires an index, e.g. es://localhost:9200/crawl-pages")
+	}
+
+	scheme := "http"
+	if strings.HasSuffix(u.Scheme, "+https") || u.Query().Get("tls") == "true" {
+		scheme = "https"
+	}
+	host := u.Host
+	if u.Port() == "" {
+		host = u.Hostname() + ":9200"
+	}
+
+	s := &esSink{
+		client:   &http.Client{Timeout: 30 * time.Second},
+		endpoint: scheme + "://" + host,
+		index:    index,
+	}
+	if u.User != nil {
+		s.username = u.User.Username()
+		s.password, _ = u.User.Password()
+	}
+
+	if err := s.ensureIndex(ctx); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// ensureIndex creates the index with esIndexMapping unless it already exists
+func (s *esSink) ensureIndex(ctx context.Context) error {
+	resp, err := s.do(ctx, http.MethodHead, "/"+s.index, "", nil)
+	if err != nil {
+		return fmt.Errorf("failed to connect to elasticsearch: %w", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+	if resp.StatusCode != http.StatusNotFound {
+		return fmt.Errorf("elasticsearch returned %s for index %s", resp.Status, s.index)
+	}
+
+	body, _ := json.Marshal(esIndexMapping)
+	resp, err = s.do(ctx, http.MethodPut, "/"+s.index, "application/json", body)
+	if err != nil {
+		return fmt.Errorf("failed to create index %s: %w", s.index, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		// Another writer may have created it between HEAD and PUT
+		if resp.StatusCode == http.StatusBadRequest && bytes.Contains(msg, []byte("resource_already_exists_exception")) {
+			return nil
+		}
+		return fmt.Errorf("failed to create index %s: %s: %s", s.index, resp.Status, strings.TrimSpace(string(msg)))
+	}
+	log.Debug().Str("index", s.index).Msg("Created elasticsearch index")
+	return nil
+}
+
+// Name returns a short description of the sink
+func (s *esSink) Name() string {
+	return "elasticsearch:" + s.index
+}
+
+// Write buffers the page and sends a bulk request once esBulkSize documents are pending
+func (s *esSink) Write(ctx context.Context, data *models.PageData) error {
+	doc, err := outpututil.MarshalJSON(data)
+	if err != nil {
+		return err
+	}
+	sum := sha256.Sum256([]byte(data.URL))
+	action, _ := json.Marshal(map[string]interface{}{
+		"index": map[string]string{"_index": s.index, "_id": hex.EncodeToString(sum[:])},
+	})
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pending.Write(action)
+	s.pending.WriteByte('\n')
+	s.pending.Write(bytes.TrimSpace(doc))
+	s.pending.WriteByte('\n')
+	s.count++
+
+	if s.count >= esBulkSize {
+		return s.flush(ctx)
+	}
+	return nil
+}
+
+// Close sends any buffered documents
+func (s *esSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.flush(context.Background())
+}
+
+// esBulkResponse is the subset of the _bulk response needed to detect item failures
+type esBulkResponse struct {
+	Errors bool `json:"errors"`
+	Items  []map[string]struct {
+		Status int `json:"status"`
+		Error  struct {
+			Type   string `json:"type"`
+			Reason string `json:"reason"`
+		} `json:"error"`
+	} `json:"items"`
+}
+
+// flush sends the pending documents to the _bulk endpoint; callers hold s.mu
+func (s *esSink) flush(ctx context.Context) error {
+	if s.count == 0 {
+		return nil
+	}
+	body := s.pending.Bytes()
+	count := s.count
+	defer func() {
+		s.pending.Reset()
+		s.count = 0
+	}()
+
+	resp, err := s.do(ctx, http.MethodPost, "/_bulk", "application/x-ndjson", body)
+	if err != nil {
+		return fmt.Errorf("bulk request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("bulk request failed: %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+
+	var result esBulkResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("failed to decode bulk response: %w", err)
+	}
+	if result.Errors {
+		failed := 0
+		var first string
+		for _, item := range result.Items {
+			for _, r := range item {
+				if r.Status >= 300 {
+					if failed == 0 {
+						first = r.Error.Type + ": " + r.Error.Reason
+					}
+					failed++
+				}
+			}
+		}
+		return fmt.Errorf("%d of %d documents failed to index (first error: %s)", failed, count, first)
+	}
+
+	log.Debug().Str("index", s.index).Int("documents", count).Msg("Bulk indexed documents")
+	return nil
+}
+
+func (s *esSink) do(ctx context.Context, method, path, contentType string, body []byte) (*http.Response, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, s.endpoint+path, reader)
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if s.username != "" {
+		req.SetBasicAuth(s.username, s.password)
+	}
+	return s.client.Do(req)
+}
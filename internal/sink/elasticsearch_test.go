@@ -0,0 +1,95 @@
+package sink
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/law-makers/crawl/pkg/models"
+)
+
+func TestESSink_CreatesIndexAndBulkIndexes(t *testing.T) {
+	var created bool
+	var bulk []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodHead && r.URL.Path == "/pages":
+			w.WriteHeader(http.StatusNotFound)
+		case r.Method == http.MethodPut && r.URL.Path == "/pages":
+			body, _ := io.ReadAll(r.Body)
+			if !bytes.Contains(body, []byte(`"content":{"type":"text"}`)) {
+				t.Errorf("Expected content mapping, got %s", body)
+			}
+			created = true
+			w.Write([]byte(`{"acknowledged":true}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/_bulk":
+			if ct := r.Header.Get("Content-Type"); ct != "application/x-ndjson" {
+				t.Errorf("Expected ndjson content type, got %q", ct)
+			}
+			scanner := bufio.NewScanner(r.Body)
+			for scanner.Scan() {
+				bulk = append(bulk, scanner.Text())
+			}
+			w.Write([]byte(`{"errors":false,"items":[]}`))
+		default:
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	addr := strings.TrimPrefix(server.URL, "http://")
+	s, err := Open(context.Background(), "es://"+addr+"/pages")
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if !created {
+		t.Error("Expected index to be created")
+	}
+
+	for _, u := range []string{"https://example.com/a", "https://example.com/b"} {
+		if err := s.Write(context.Background(), &models.PageData{URL: u, Title: "Example"}); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+	if len(bulk) != 0 {
+		t.Error("Expected documents to be buffered until Close")
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	if len(bulk) != 4 {
+		t.Fatalf("Expected 4 bulk lines (2 actions, 2 documents), got %d", len(bulk))
+	}
+	if !strings.Contains(bulk[0], `"_index":"pages"`) || !strings.Contains(bulk[0], `"_id":`) {
+		t.Errorf("Unexpected action line: %s", bulk[0])
+	}
+	if !strings.Contains(bulk[1], `"url":"https://example.com/a"`) {
+		t.Errorf("Unexpected document line: %s", bulk[1])
+	}
+}
+
+func TestESSink_ReportsItemErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/_bulk" {
+			w.Write([]byte(`{"errors":true,"items":[{"index":{"status":400,"error":{"type":"mapper_parsing_exception","reason":"bad"}}}]}`))
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	s, err := Open(context.Background(), "es://"+strings.TrimPrefix(server.URL, "http://")+"/pages")
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	s.Write(context.Background(), &models.PageData{URL: "https://example.com"})
+	if err := s.Close(); err == nil || !strings.Contains(err.Error(), "mapper_parsing_exception") {
+		t.Errorf("Expected item error, got %v", err)
+	}
+}
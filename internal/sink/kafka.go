@@ -0,0 +1,87 @@
+// internal/sink/kafka.go
+package sink
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	outpututil "github.com/law-makers/crawl/internal/utils/output"
+	"github.com/law-makers/crawl/pkg/models"
+	"github.com/segmentio/kafka-go"
+)
+
+// kafkaSink produces each page as a JSON message keyed by its URL (or the
+// URL's hash with ?key=sha256), so pages from the same URL always land on
+// the same partition.
+type kafkaSink struct {
+	writer  *kafka.Writer
+	topic   string
+	keyMode string
+}
+
+func newKafkaSink(u *url.URL) (*kafkaSink, error) {
+	topic := strings.Trim(u.Path, "/")
+	if topic == "" {
+		return nil, fmt.Errorf("kafka sink requires a topic, e.g. kafka://localhost:9092/crawl-pages")
+	}
+	keyMode, err := messageKeyMode(u)
+	if err != nil {
+		return nil, err
+	}
+
+	brokers := strings.Split(u.Host, ",")
+	for i, broker := range brokers {
+		if !strings.Contains(broker, ":") {
+			brokers[i] = broker + ":9092"
+		}
+	}
+
+	writer := &kafka.Writer{
+		Addr:                   kafka.TCP(brokers...),
+		Topic:                  topic,
+		Balancer:               &kafka.Hash{},
+		RequiredAcks:           kafka.RequireOne,
+		AllowAutoTopicCreation: true,
+		BatchTimeout:           50 * time.Millisecond,
+	}
+
+	return &kafkaSink{writer: writer, topic: topic, keyMode: keyMode}, nil
+}
+
+// Name returns a short description of the sink
+func (s *kafkaSink) Name() string {
+	return "kafka:" + s.topic
+}
+
+// Write produces the page as JSON
+func (s *kafkaSink) Write(ctx context.Context, data *models.PageData) error {
+	msg, err := kafkaMessage(data, s.keyMode)
+	if err != nil {
+		return err
+	}
+	return s.writer.WriteMessages(ctx, msg)
+}
+
+// kafkaMessage builds the message carrying data
+func kafkaMessage(data *models.PageData, keyMode string) (kafka.Message, error) {
+	payload, err := outpututil.MarshalJSON(data)
+	if err != nil {
+		return kafka.Message{}, err
+	}
+	msg := kafka.Message{
+		Key:   []byte(messageKey(data, keyMode)),
+		Value: payload,
+	}
+	for _, h := range messageHeaders(data) {
+		msg.Headers = append(msg.Headers, kafka.Header{Key: h.key, Value: []byte(h.value)})
+	}
+	return msg, nil
+}
+
+// Close flushes pending messages and closes broker connections
+func (s *kafkaSink) Close() error {
+	return s.writer.Close()
+}
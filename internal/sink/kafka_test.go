@@ -0,0 +1,86 @@
+package sink
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/law-makers/crawl/pkg/models"
+)
+
+func TestKafkaMessage(t *testing.T) {
+	page := &models.PageData{
+		URL:        "https://example.com",
+		Title:      "Example",
+		StatusCode: 404,
+		Headers:    map[string]string{"Content-Type": "text/html; charset=utf-8"},
+		JobID:      "job-1",
+		RequestID:  "req-7",
+	}
+
+	tests := []struct {
+		mode, key string
+	}{
+		{KeyURL, "https://example.com"},
+		{KeySHA256, "100680ad546ce6a577f42f52df33b4cfdca756859e664b8d7de329b150d09ce9"},
+	}
+	for _, tt := range tests {
+		msg, err := kafkaMessage(page, tt.mode)
+		if err != nil {
+			t.Fatalf("kafkaMessage failed: %v", err)
+		}
+		if string(msg.Key) != tt.key {
+			t.Errorf("%s: key = %q, want %q", tt.mode, msg.Key, tt.key)
+		}
+		if !strings.Contains(string(msg.Value), `"title":"Example"`) {
+			t.Errorf("%s: expected JSON payload, got %s", tt.mode, msg.Value)
+		}
+	}
+
+	msg, _ := kafkaMessage(page, KeyURL)
+	headers := map[string]string{}
+	for _, h := range msg.Headers {
+		headers[h.Key] = string(h.Value)
+	}
+	want := map[string]string{
+		"Content-Type":       "application/json",
+		"Crawl-Url":          "https://example.com",
+		"Crawl-Status":       "404",
+		"Crawl-Content-Type": "text/html; charset=utf-8",
+		"Crawl-Job-Id":       "job-1",
+		"Crawl-Request-Id":   "req-7",
+	}
+	for k, v := range want {
+		if headers[k] != v {
+			t.Errorf("Header %s = %q, want %q", k, headers[k], v)
+		}
+	}
+
+	// Empty values are left out rather than sent blank
+	msg, _ = kafkaMessage(&models.PageData{URL: "https://example.com"}, KeyURL)
+	for _, h := range msg.Headers {
+		if h.Key == "Crawl-Job-Id" || h.Key == "Crawl-Content-Type" {
+			t.Errorf("Expected no %s header for a page without one", h.Key)
+		}
+	}
+}
+
+func TestNewKafkaSink(t *testing.T) {
+	s, err := Open(context.Background(), "kafka://broker1,broker2:9093/crawl-pages?key=sha256")
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer s.Close()
+
+	ks := s.(*kafkaSink)
+	if ks.topic != "crawl-pages" || ks.keyMode != KeySHA256 {
+		t.Errorf("Unexpected topic or key mode: %q %q", ks.topic, ks.keyMode)
+	}
+	if got := ks.writer.Addr.String(); got != "broker1:9092,broker2:9093" {
+		t.Errorf("Expected default port on bare brokers, got %q", got)
+	}
+
+	if _, err := Open(context.Background(), "kafka://broker1/"); err == nil {
+		t.Error("Expected error for a missing topic")
+	}
+}
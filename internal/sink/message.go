@@ -0,0 +1,80 @@
+// internal/sink/message.go
+package sink
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/law-makers/crawl/pkg/models"
+)
+
+// Message keys for the ?key= option of message bus sinks
+const (
+	KeyURL    = "url"    // The page URL as is
+	KeySHA256 = "sha256" // Hex SHA-256 of the page URL, a fixed-length key
+)
+
+// messageKeyMode reads the ?key= option of a sink URL, defaulting to KeyURL
+func messageKeyMode(u *url.URL) (string, error) {
+	switch mode := u.Query().Get("key"); mode {
+	case "", KeyURL:
+		return KeyURL, nil
+	case KeySHA256:
+		return KeySHA256, nil
+	default:
+		return "", fmt.Errorf("unsupported message key %q (use %s or %s)", mode, KeyURL, KeySHA256)
+	}
+}
+
+// messageKey returns the key of the message carrying data
+func messageKey(data *models.PageData, mode string) string {
+	if mode == KeySHA256 {
+		sum := sha256.Sum256([]byte(data.URL))
+		return hex.EncodeToString(sum[:])
+	}
+	return data.URL
+}
+
+// header is a message header; headers keep their order on the wire
+type header struct {
+	key, value string
+}
+
+// messageHeaders returns the headers sent with the message carrying data,
+// so consumers can route or filter without decoding the payload. Empty
+// values are left out.
+func messageHeaders(data *models.PageData) []header {
+	candidates := []header{
+		{"Content-Type", "application/json"},
+		{"Crawl-Url", data.URL},
+		{"Crawl-Status", strconv.Itoa(data.StatusCode)},
+		{"Crawl-Content-Type", headerValue(data.Headers, "Content-Type")},
+		{"Crawl-Job-Id", data.JobID},
+		{"Crawl-Request-Id", data.RequestID},
+	}
+	headers := candidates[:0]
+	for _, h := range candidates {
+		if h.value != "" {
+			headers = append(headers, h)
+		}
+	}
+	return headers
+}
+
+// headerValue looks up name in response headers, which the browser
+// reports in lower case
+func headerValue(headers map[string]string, name string) string {
+	if v, ok := headers[name]; ok {
+		return v
+	}
+	for k, v := range headers {
+		if strings.EqualFold(k, name) {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,189 @@
+// internal/sink/nats.go
+package sink
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	outpututil "github.com/law-makers/crawl/internal/utils/output"
+	"github.com/law-makers/crawl/pkg/models"
+	"github.com/rs/zerolog/log"
+)
+
+// natsSink publishes each page as a JSON message to a NATS subject, with
+// the page's key (?key=url or sha256) and details in headers when the
+// server supports them. It speaks the NATS text protocol directly; only
+// plain TCP is supported.
+type natsSink struct {
+	conn    net.Conn
+	reader  *bufio.Reader
+	writer  *bufio.Writer
+	subject string
+	keyMode string
+	headers bool // Server accepts HPUB
+	mu      sync.Mutex
+}
+
+// natsInfo is the part of the server INFO payload the sink uses
+type natsInfo struct {
+	Headers bool `json:"headers"`
+}
+
+// natsConnect is the CONNECT payload sent after the server INFO line
+type natsConnect struct {
+	Verbose  bool   `json:"verbose"`
+	Pedantic bool   `json:"pedantic"`
+	Name     string `json:"name"`
+	Lang     string `json:"lang"`
+	Version  string `json:"version"`
+	User     string `json:"user,omitempty"`
+	Pass     string `json:"pass,omitempty"`
+	Headers  bool   `json:"headers"`
+}
+
+func newNATSSink(ctx context.Context, u *url.URL) (*natsSink, error) {
+	subject := strings.Trim(u.Path, "/")
+	subject = strings.ReplaceAll(subject, "/", ".")
+	if subject == "" {
+		return nil, fmt.Errorf("nats sink requires a subject, e.g. nats://localhost:4222/crawl.pages")
+	}
+	keyMode, err := messageKeyMode(u)
+	if err != nil {
+		return nil, err
+	}
+
+	host := u.Host
+	if u.Port() == "" {
+		host = net.JoinHostPort(u.Hostname(), "4222")
+	}
+
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+	conn, err := dialer.DialContext(ctx, "tcp", host)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to nats: %w", err)
+	}
+
+	s := &natsSink{
+		conn:    conn,
+		reader:  bufio.NewReader(conn),
+		writer:  bufio.NewWriter(conn),
+		subject: subject,
+		keyMode: keyMode,
+	}
+
+	// Server greets with INFO {...}
+	conn.SetDeadline(time.Now().Add(10 * time.Second))
+	line, err := s.reader.ReadString('\n')
+	if err != nil || !strings.HasPrefix(line, "INFO ") {
+		conn.Close()
+		return nil, fmt.Errorf("unexpected nats greeting: %q", strings.TrimSpace(line))
+	}
+	var info natsInfo
+	if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "INFO ")), &info); err == nil {
+		s.headers = info.Headers
+	}
+	if !s.headers {
+		log.Debug().Str("host", host).Msg("NATS server does not support headers, publishing without them")
+	}
+
+	connect := natsConnect{Name: "crawl", Lang: "go", Version: "1.0", Headers: s.headers}
+	if u.User != nil {
+		connect.User = u.User.Username()
+		connect.Pass, _ = u.User.Password()
+	}
+	payload, _ := json.Marshal(connect)
+	fmt.Fprintf(s.writer, "CONNECT %s\r\n", payload)
+	if err := s.ping(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	conn.SetDeadline(time.Time{})
+
+	log.Debug().Str("host", host).Str("subject", subject).Msg("Connected to NATS")
+	return s, nil
+}
+
+// Name returns a short description of the sink
+func (s *natsSink) Name() string {
+	return "nats:" + s.subject
+}
+
+// Write publishes the page as JSON
+func (s *natsSink) Write(ctx context.Context, data *models.PageData) error {
+	payload, err := outpututil.MarshalJSON(data)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.headers {
+		hdr := natsHeaderBlock(data, s.keyMode)
+		fmt.Fprintf(s.writer, "HPUB %s %d %d\r\n", s.subject, len(hdr), len(hdr)+len(payload))
+		s.writer.WriteString(hdr)
+	} else {
+		fmt.Fprintf(s.writer, "PUB %s %d\r\n", s.subject, len(payload))
+	}
+	s.writer.Write(payload)
+	s.writer.WriteString("\r\n")
+	return s.writer.Flush()
+}
+
+// natsHeaderBlock encodes the headers of the message carrying data, with
+// its key as Crawl-Key since NATS messages have no key of their own
+func natsHeaderBlock(data *models.PageData, keyMode string) string {
+	var b strings.Builder
+	b.WriteString("NATS/1.0\r\n")
+	headers := append(messageHeaders(data), header{"Crawl-Key", messageKey(data, keyMode)})
+	for _, h := range headers {
+		// A line break in a value would end the header early
+		value := strings.NewReplacer("\r", " ", "\n", " ").Replace(h.value)
+		fmt.Fprintf(&b, "%s: %s\r\n", h.key, value)
+	}
+	b.WriteString("\r\n")
+	return b.String()
+}
+
+// Close flushes outstanding messages (PING/PONG round trip) and disconnects
+func (s *natsSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.conn.SetDeadline(time.Now().Add(10 * time.Second))
+	err := s.ping()
+	s.conn.Close()
+	return err
+}
+
+// ping sends PING and waits for PONG, surfacing any -ERR from the server
+func (s *natsSink) ping() error {
+	s.writer.WriteString("PING\r\n")
+	if err := s.writer.Flush(); err != nil {
+		return err
+	}
+
+	for {
+		line, err := s.reader.ReadString('\n')
+		if err != nil {
+			return fmt.Errorf("nats connection error: %w", err)
+		}
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "PONG":
+			return nil
+		case line == "PING":
+			s.writer.WriteString("PONG\r\n")
+			s.writer.Flush()
+		case strings.HasPrefix(line, "-ERR"):
+			return fmt.Errorf("nats error: %s", strings.TrimSpace(strings.TrimPrefix(line, "-ERR")))
+		}
+	}
+}
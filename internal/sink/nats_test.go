@@ -0,0 +1,155 @@
+package sink
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/law-makers/crawl/pkg/models"
+)
+
+// fakeNATSServer accepts one connection, answers PINGs and records PUB and
+// HPUB messages as "<command line>|<headers and payload>"
+func fakeNATSServer(t *testing.T, info string) (string, <-chan string) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	published := make(chan string, 10)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		conn.Write([]byte("INFO " + info + "\r\n"))
+		r := bufio.NewReader(conn)
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				close(published)
+				return
+			}
+			switch {
+			case strings.HasPrefix(line, "PING"):
+				conn.Write([]byte("PONG\r\n"))
+			case strings.HasPrefix(line, "PUB "), strings.HasPrefix(line, "HPUB "):
+				fields := strings.Fields(line)
+				size, _ := strconv.Atoi(fields[len(fields)-1])
+				msg := make([]byte, size+2)
+				if _, err := io.ReadFull(r, msg); err != nil {
+					close(published)
+					return
+				}
+				published <- strings.TrimSpace(line) + "|" + strings.TrimSpace(string(msg))
+			}
+		}
+	}()
+
+	return ln.Addr().String(), published
+}
+
+func TestNATSSink_Publish(t *testing.T) {
+	addr, published := fakeNATSServer(t, `{"server_id":"test"}`)
+
+	s, err := Open(context.Background(), "nats://"+addr+"/crawl/pages")
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+
+	if err := s.Write(context.Background(), &models.PageData{URL: "https://example.com", Title: "Example"}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	msg := <-published
+	if !strings.HasPrefix(msg, "PUB crawl.pages ") {
+		t.Errorf("Unexpected PUB line: %q", msg)
+	}
+	if !strings.Contains(msg, `"title":"Example"`) {
+		t.Errorf("Expected JSON payload with title, got %q", msg)
+	}
+}
+
+func TestNATSSink_PublishWithHeaders(t *testing.T) {
+	addr, published := fakeNATSServer(t, `{"server_id":"test","headers":true}`)
+
+	s, err := Open(context.Background(), "nats://"+addr+"/crawl.pages?key=sha256")
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	page := &models.PageData{
+		URL:        "https://example.com",
+		StatusCode: 200,
+		Headers:    map[string]string{"content-type": "text/html"},
+		JobID:      "job-1",
+	}
+	if err := s.Write(context.Background(), page); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	msg := <-published
+	line, body, _ := strings.Cut(msg, "|")
+	hdr, payload, ok := strings.Cut(body, "\r\n\r\n")
+	if !ok {
+		t.Fatalf("Expected a header block, got %q", body)
+	}
+	fields := strings.Fields(line)
+	if len(fields) != 4 || fields[0] != "HPUB" || fields[2] != strconv.Itoa(len(hdr)+4) {
+		t.Errorf("Unexpected HPUB line %q for %d header bytes", line, len(hdr)+4)
+	}
+	for _, want := range []string{
+		"NATS/1.0",
+		"Crawl-Status: 200",
+		"Crawl-Content-Type: text/html",
+		"Crawl-Job-Id: job-1",
+		"Crawl-Key: 100680ad546ce6a577f42f52df33b4cfdca756859e664b8d7de329b150d09ce9",
+	} {
+		if !strings.Contains(hdr, want+"\r\n") && !strings.HasSuffix(hdr, want) {
+			t.Errorf("Expected header %q in %q", want, hdr)
+		}
+	}
+	if !strings.Contains(payload, `"url":"https://example.com"`) {
+		t.Errorf("Expected JSON payload, got %q", payload)
+	}
+}
+
+func TestOpen_InvalidKey(t *testing.T) {
+	if _, err := Open(context.Background(), "kafka://localhost/pages?key=title"); err == nil {
+		t.Error("Expected error for an unsupported key")
+	}
+}
+
+func TestOpen_UnsupportedScheme(t *testing.T) {
+	if _, err := Open(context.Background(), "ftp://example.com/x"); err == nil {
+		t.Error("Expected error for unsupported scheme")
+	}
+}
+
+func TestIsMessageBus(t *testing.T) {
+	tests := map[string]bool{
+		"nats://localhost:4222/pages":         true,
+		"kafka://localhost:9092/pages?key=id": true,
+		"postgres://user@localhost/crawl":     false,
+		"es://localhost:9200/pages":           false,
+		"pages.jsonl":                         false,
+		"":                                    false,
+	}
+	for rawURL, want := range tests {
+		if got := IsMessageBus(rawURL); got != want {
+			t.Errorf("IsMessageBus(%q) = %v, want %v", rawURL, got, want)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+// internal/stats/stats.go
+package stats
+
+import (
+	"encoding/json"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Request describes the outcome of one outbound request
+type Request struct {
+	Status   int           // HTTP status (0 if no response was received)
+	Bytes    int64         // Response body bytes
+	Duration time.Duration // Time spent on the request itself
+	Retries  int           // Attempts beyond the first
+	Waited   time.Duration // Time spent in rate limiting / host slot queues
+	Err      error
+	Skipped  bool // Not attempted (e.g. run budget exhausted)
+}
+
+// EngineStats aggregates requests made by one engine
+type EngineStats struct {
+	Requests int   `json:"requests"`
+	Failed   int   `json:"failed"`
+	Bytes    int64 `json:"bytes"`
+	TimeMs   int64 `json:"time_ms"`
+}
+
+// Summary is the aggregated view of a run, suitable for printing or JSON export
+type Summary struct {
+	Command        string                  `json:"command"`
+	Target         string                  `json:"target,omitempty"`
+	StartedAt      time.Time               `json:"started_at"`
+	DurationMs     int64                   `json:"duration_ms"`
+	Requests       int                     `json:"requests"`
+	Succeeded      int                     `json:"succeeded"`
+	Failed         int                     `json:"failed"`
+	Skipped        int                     `json:"skipped"`
+	Bytes          int64                   `json:"bytes"`
+	ByStatus       map[string]int          `json:"by_status"`
+	Engines        map[string]*EngineStats `json:"engines"`
+	CacheHits      uint64                  `json:"cache_hits"`
+	CacheMisses    uint64                  `json:"cache_misses"`
+	Retries        int                     `json:"retries"`
+	ThrottleWaits  int                     `json:"throttle_waits"`
+	ThrottleWaitMs int64                   `json:"throttle_wait_ms"`
+}
+
+// throttleThreshold is the minimum queue time counted as a throttle wait
+const throttleThreshold = time.Millisecond
+
+// Collector accumulates request outcomes for a run. It is safe for concurrent use.
+type Collector struct {
+	mu      sync.Mutex
+	summary Summary
+}
+
+// New starts collecting statistics for a command run
+func New(command, target string) *Collector {
+	return &Collector{summary: Summary{
+		Command:   command,
+		Target:    target,
+		StartedAt: time.Now(),
+		ByStatus:  make(map[string]int),
+		Engines:   make(map[string]*EngineStats),
+	}}
+}
+
+// Record adds one request made by the named engine
+func (c *Collector) Record(engine string, r Request) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	s := &c.summary
+	if r.Skipped {
+		s.Skipped++
+		return
+	}
+
+	s.Requests++
+	if r.Err != nil {
+		s.Failed++
+	} else {
+		s.Succeeded++
+	}
+	s.Bytes += r.Bytes
+	s.Retries += r.Retries
+	if r.Waited >= throttleThreshold {
+		s.ThrottleWaits++
+		s.ThrottleWaitMs += r.Waited.Milliseconds()
+	}
+
+	status := "error"
+	if r.Status > 0 {
+		status = strconv.Itoa(r.Status)
+	}
+	s.ByStatus[status]++
+
+	e, ok := s.Engines[engine]
+	if !ok {
+		e = &EngineStats{}
+		s.Engines[engine] = e
+	}
+	e.Requests++
+	if r.Err != nil {
+		e.Failed++
+	}
+	e.Bytes += r.Bytes
+	e.TimeMs += r.Duration.Milliseconds()
+}
+
+// SetCache records cache hit/miss counters
+func (c *Collector) SetCache(hits, misses uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.summary.CacheHits = hits
+	c.summary.CacheMisses = misses
+}
+
+// Summary returns a snapshot of the statistics with the elapsed run time
+func (c *Collector) Summary() Summary {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	s := c.summary
+	s.DurationMs = time.Since(s.StartedAt).Milliseconds()
+	s.ByStatus = make(map[string]int, len(c.summary.ByStatus))
+	for k, v := range c.summary.ByStatus {
+		s.ByStatus[k] = v
+	}
+	s.Engines = make(map[string]*EngineStats, len(c.summary.Engines))
+	for k, v := range c.summary.Engines {
+		copied := *v
+		s.Engines[k] = &copied
+	}
+	return s
+}
+
+// WriteJSON writes the summary as indented JSON to path
+func (c *Collector) WriteJSON(path string) error {
+	content, err := json.MarshalIndent(c.Summary(), "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(content, '\n'), 0644)
+}
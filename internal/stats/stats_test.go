@@ -0,0 +1,52 @@
+package stats
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCollector_Aggregates(t *testing.T) {
+	c := New("media", "https://example.com")
+	c.Record("StaticScraper", Request{Status: 200, Bytes: 1000, Duration: 50 * time.Millisecond})
+	c.Record("downloader", Request{Status: 200, Bytes: 500, Retries: 2, Waited: 20 * time.Millisecond})
+	c.Record("downloader", Request{Status: 404, Err: errors.New("not found")})
+	c.Record("downloader", Request{Err: errors.New("connection refused")})
+	c.Record("downloader", Request{Skipped: true})
+
+	s := c.Summary()
+	if s.Requests != 4 || s.Succeeded != 2 || s.Failed != 2 || s.Skipped != 1 {
+		t.Errorf("Unexpected counts: %+v", s)
+	}
+	if s.ByStatus["200"] != 2 || s.ByStatus["404"] != 1 || s.ByStatus["error"] != 1 {
+		t.Errorf("Unexpected status breakdown: %v", s.ByStatus)
+	}
+	if s.Bytes != 1500 || s.Retries != 2 || s.ThrottleWaits != 1 {
+		t.Errorf("Unexpected totals: bytes=%d retries=%d waits=%d", s.Bytes, s.Retries, s.ThrottleWaits)
+	}
+	if e := s.Engines["downloader"]; e == nil || e.Requests != 3 || e.Failed != 2 {
+		t.Errorf("Unexpected engine stats: %+v", e)
+	}
+}
+
+func TestCollector_WriteJSON(t *testing.T) {
+	c := New("media", "")
+	c.Record("downloader", Request{Status: 200, Bytes: 10})
+
+	path := filepath.Join(t.TempDir(), "stats.json")
+	if err := c.WriteJSON(path); err != nil {
+		t.Fatalf("WriteJSON failed: %v", err)
+	}
+
+	raw, _ := os.ReadFile(path)
+	var s Summary
+	if err := json.Unmarshal(raw, &s); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+	if s.Command != "media" || s.ByStatus["200"] != 1 {
+		t.Errorf("Unexpected summary: %+v", s)
+	}
+}
@@ -0,0 +1,173 @@
+// internal/trace/trace.go
+package trace
+
+import (
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net/http/httptrace"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/law-makers/crawl/pkg/models"
+)
+
+// barWidth is the number of columns used for the waterfall bars
+const barWidth = 40
+
+// ClientTrace returns httptrace hooks that record DNS, connect, TLS and
+// time-to-first-byte spans on t. Redirects produce one set of spans per hop.
+func ClientTrace(t *models.Trace) *httptrace.ClientTrace {
+	var (
+		mu                               sync.Mutex
+		dnsStart, connectStart, tlsStart time.Time
+		wroteRequest                     time.Time
+	)
+	set := func(v *time.Time) {
+		mu.Lock()
+		*v = time.Now()
+		mu.Unlock()
+	}
+	get := func(v *time.Time) time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		return *v
+	}
+
+	return &httptrace.ClientTrace{
+		GetConn: func(hostPort string) {
+			t.Event("request", time.Now(), hostPort)
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			if info.Reused {
+				t.Event("reused conn", time.Now(), fmt.Sprintf("idle %s", info.IdleTime.Round(time.Millisecond)))
+			}
+		},
+		DNSStart: func(httptrace.DNSStartInfo) { set(&dnsStart) },
+		DNSDone: func(info httptrace.DNSDoneInfo) {
+			detail := fmt.Sprintf("%d addrs", len(info.Addrs))
+			if info.Err != nil {
+				detail = info.Err.Error()
+			}
+			t.Span("dns", get(&dnsStart), time.Now(), detail)
+		},
+		ConnectStart: func(network, addr string) { set(&connectStart) },
+		ConnectDone: func(network, addr string, err error) {
+			detail := addr
+			if err != nil {
+				detail = err.Error()
+			}
+			t.Span("connect", get(&connectStart), time.Now(), detail)
+		},
+		TLSHandshakeStart: func() { set(&tlsStart) },
+		TLSHandshakeDone: func(state tls.ConnectionState, err error) {
+			detail := tls.VersionName(state.Version)
+			if err != nil {
+				detail = err.Error()
+			}
+			t.Span("tls", get(&tlsStart), time.Now(), detail)
+		},
+		WroteRequest: func(httptrace.WroteRequestInfo) { set(&wroteRequest) },
+		GotFirstResponseByte: func() {
+			t.Span("ttfb", get(&wroteRequest), time.Now(), "")
+		},
+	}
+}
+
+// Render prints t as a waterfall, one line per span in start order. Point
+// events are drawn as a single marker.
+func Render(w io.Writer, title string, t *models.Trace) {
+	spans := t.Spans()
+	sort.SliceStable(spans, func(i, j int) bool { return spans[i].Start < spans[j].Start })
+
+	var total time.Duration
+	nameWidth := 0
+	for _, s := range spans {
+		if s.End > total {
+			total = s.End
+		}
+		if len(s.Name) > nameWidth {
+			nameWidth = len(s.Name)
+		}
+	}
+
+	fmt.Fprintf(w, "Trace: %s\n", title)
+	if len(spans) == 0 {
+		fmt.Fprintln(w, "  (no events recorded)")
+		return
+	}
+
+	for _, s := range spans {
+		from, to := column(s.Start, total), column(s.End, total)
+		var bar string
+		if s.End == s.Start {
+			bar = strings.Repeat(" ", from) + "|" + strings.Repeat(" ", barWidth-from-1)
+		} else {
+			if to == from {
+				to = from + 1
+			}
+			bar = strings.Repeat(" ", from) + strings.Repeat("█", to-from) + strings.Repeat(" ", barWidth-to)
+		}
+
+		timing := fmt.Sprintf("%8s", "@"+ms(s.Start))
+		if s.End != s.Start {
+			timing = fmt.Sprintf("%8s", ms(s.End-s.Start))
+		}
+		line := fmt.Sprintf("  %-*s [%s] %s", nameWidth, s.Name, bar, timing)
+		if s.Detail != "" {
+			line += "  " + s.Detail
+		}
+		fmt.Fprintln(w, line)
+	}
+	fmt.Fprintf(w, "  %-*s  %s\n", nameWidth, "total", ms(total))
+}
+
+// column maps an offset onto the bar, leaving room for a one-column bar at the end
+func column(d, total time.Duration) int {
+	if total <= 0 {
+		return 0
+	}
+	c := int(int64(d) * int64(barWidth-1) / int64(total))
+	if c < 0 {
+		return 0
+	}
+	if c > barWidth-1 {
+		return barWidth - 1
+	}
+	return c
+}
+
+func ms(d time.Duration) string {
+	return fmt.Sprintf("%.1fms", Millis(d))
+}
+
+// Timings sums the network spans recorded by ClientTrace, plus the engine's
+// "body" span, into per-phase totals. Redirect hops are added together.
+func Timings(spans []models.TraceSpan) *models.Timings {
+	t := &models.Timings{}
+	for _, s := range spans {
+		d := Millis(s.End - s.Start)
+		switch s.Name {
+		case "dns":
+			t.DNS += d
+		case "connect":
+			t.Connect += d
+		case "tls":
+			t.TLS += d
+		case "ttfb":
+			t.TTFB += d
+		case "body":
+			t.Download += d
+		case "queue":
+			t.Waited += d
+		}
+	}
+	return t
+}
+
+// Millis converts d to fractional milliseconds
+func Millis(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
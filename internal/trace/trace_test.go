@@ -0,0 +1,96 @@
+package trace
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httptrace"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/law-makers/crawl/pkg/models"
+)
+
+func TestClientTrace_RecordsConnectAndTTFB(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(5 * time.Millisecond)
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	tr := models.NewTrace()
+	ctx := httptrace.WithClientTrace(context.Background(), ClientTrace(tr))
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	got := map[string]models.TraceSpan{}
+	for _, s := range tr.Spans() {
+		got[s.Name] = s
+	}
+	if _, ok := got["connect"]; !ok {
+		t.Error("Expected a connect span")
+	}
+	ttfb, ok := got["ttfb"]
+	if !ok {
+		t.Fatal("Expected a ttfb span")
+	}
+	if ttfb.End-ttfb.Start < 5*time.Millisecond {
+		t.Errorf("Expected ttfb to include the server delay, got %s", ttfb.End-ttfb.Start)
+	}
+}
+
+func TestRender_Waterfall(t *testing.T) {
+	tr := models.NewTrace()
+	start := time.Now()
+	tr.Span("connect", start, start.Add(10*time.Millisecond), "127.0.0.1:80")
+	tr.Span("ttfb", start.Add(10*time.Millisecond), start.Add(40*time.Millisecond), "")
+	tr.Event("load", start.Add(40*time.Millisecond), "")
+
+	var buf bytes.Buffer
+	Render(&buf, "https://example.com", tr)
+	out := buf.String()
+
+	for _, want := range []string{"Trace: https://example.com", "connect", "ttfb", "load", "127.0.0.1:80", "total"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, out)
+		}
+	}
+	if !strings.Contains(out, "█") {
+		t.Errorf("Expected bars in output, got:\n%s", out)
+	}
+}
+
+func TestTrace_NilIsNoop(t *testing.T) {
+	var tr *models.Trace
+	tr.Span("connect", time.Now(), time.Now(), "")
+	if tr.Spans() != nil {
+		t.Error("Expected nil trace to record nothing")
+	}
+}
+
+func TestTimings_SumsPhases(t *testing.T) {
+	spans := []models.TraceSpan{
+		{Name: "dns", Start: 0, End: 2 * time.Millisecond},
+		{Name: "connect", Start: 2 * time.Millisecond, End: 5 * time.Millisecond},
+		{Name: "ttfb", Start: 5 * time.Millisecond, End: 15 * time.Millisecond},
+		// A redirect hop on a reused connection
+		{Name: "ttfb", Start: 15 * time.Millisecond, End: 20 * time.Millisecond},
+		{Name: "body", Start: 20 * time.Millisecond, End: 24 * time.Millisecond},
+		{Name: "request", Start: 0, End: 0},
+		{Name: "queue", Start: 0, End: 7 * time.Millisecond},
+	}
+
+	got := Timings(spans)
+	want := models.Timings{DNS: 2, Connect: 3, TTFB: 15, Download: 4, Waited: 7}
+	if *got != want {
+		t.Errorf("Expected %+v, got %+v", want, *got)
+	}
+}
@@ -0,0 +1,219 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Catalog maps message keys to fmt format strings in one language
+type Catalog map[string]string
+
+var catalogs = map[string]Catalog{}
+
+// RegisterCatalog adds or extends the catalog for a language (e.g. "de" or
+// "pt-br"), typically from an init function in a fork. Keys it does not
+// define fall back to English.
+func RegisterCatalog(lang string, c Catalog) {
+	lang = normalizeLang(lang)
+	existing := catalogs[lang]
+	if existing == nil {
+		existing = Catalog{}
+		catalogs[lang] = existing
+	}
+	for key, msg := range c {
+		existing[key] = msg
+	}
+}
+
+// Languages lists the languages with a registered catalog
+func Languages() []string {
+	langs := make([]string, 0, len(catalogs))
+	for lang := range catalogs {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+	return langs
+}
+
+// lookupCatalog finds the catalog for a language, trying the base language
+// ("pt" for "pt-br") when there is no exact match
+func lookupCatalog(lang string) (Catalog, bool) {
+	lang = normalizeLang(lang)
+	if c, ok := catalogs[lang]; ok {
+		return c, true
+	}
+	if base, _, ok := strings.Cut(lang, "-"); ok {
+		c, found := catalogs[base]
+		return c, found
+	}
+	return nil, false
+}
+
+// normalizeLang turns locale names like "de_DE.UTF-8" into "de-de"
+func normalizeLang(lang string) string {
+	lang, _, _ = strings.Cut(lang, ".")
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(lang), "_", "-"))
+}
+
+// translate formats the message for key from c, falling back to English and
+// then to the key itself
+func translate(c Catalog, key string, args ...interface{}) string {
+	msg, ok := c[key]
+	if !ok {
+		if msg, ok = English[key]; !ok {
+			msg = key
+		}
+	}
+	if len(args) == 0 {
+		return msg
+	}
+	return fmt.Sprintf(msg, args...)
+}
+
+// English is the built-in catalog and the fallback for every other language
+var English = Catalog{
+	// Help and usage
+	"help.usage":        "Usage",
+	"help.examples":     "Examples",
+	"help.commands":     "Commands",
+	"help.flags":        "Flags",
+	"help.global_flags": "Global Flags",
+	"help.footer":       "Use \"%s %s %s\" for more information about a command.",
+	"usage.footer":      "Use \"%s %s\" for more information.",
+
+	// get
+	"get.saved_to":  "Saved to",
+	"get.preview":   "Content Preview:",
+	"get.save_hint": "Use --output=<file> to save to a specific format (available: %s)",
+	"get.paused":    "Paused:",
+	"get.inspect":   "Inspect the browser window, then press Enter to continue...",
+
+	// Page metadata summary
+	"page.url":             "URL",
+	"page.status":          "Status",
+	"page.title":           "Title",
+	"page.response_time":   "Response Time",
+	"page.links":           "Links",
+	"page.images":          "Images",
+	"page.scripts":         "Scripts",
+	"page.suspected_error": "Suspected Error",
+	"page.waited":          "(%dms held by rate limits)",
+
+	// Archives
+	"archive.written": "Archived to",
+
+	// Batch fetches
+	"batch.stopped_early": "Stopped early:",
+	"batch.not_fetched":   "%d URL(s) not fetched after the first failure",
+	"batch.over_budget":   "%v; %d URL(s) not fetched:",
+	"batch.saved_pages":   "Saved %d pages to %s",
+	"batch.saved_files":   "Saved %d pages to %d file(s)",
+
+	// media
+	"media.none_found":    "No media files found.",
+	"media.tip_spa":       "TIP: Try using --mode=spa for JavaScript-heavy sites",
+	"media.found":         "Found",
+	"media.found_list":    "%d media file(s):",
+	"media.found_count":   "%d media file(s).",
+	"media.starting":      "Starting download with",
+	"media.workers":       "%d workers...",
+	"media.results":       "Download Results:",
+	"media.size":          "Size:",
+	"media.duration":      "Duration:",
+	"media.error":         "Error:",
+	"media.stopped_early": "Stopped early:",
+	"media.not_fetched":   "%v; %d file(s) not downloaded",
+	"media.page":          "Page %d:",
+	"media.page_failed":   "Page %d failed:",
+	"media.page_new":      "%d new",
+	"media.page_counts":   "%d new, %d already seen",
+	"media.no_stream":     "No downloadable stream for",
+
+	// Download summary
+	"summary.title":      "Summary:",
+	"summary.total":      "Total:",
+	"summary.files":      "%d files",
+	"summary.success":    "Success:",
+	"summary.failed":     "Failed:",
+	"summary.unchanged":  "Unchanged:",
+	"summary.skipped":    "Skipped:",
+	"summary.total_size": "Total Size:",
+	"summary.avg_time":   "Average Time:",
+	"summary.output_dir": "Output Directory:",
+
+	// Download plan
+	"plan.title":    "Download plan:",
+	"plan.estimate": "Estimated total:",
+	"plan.unknown":  "unknown",
+	"plan.unsized":  "(%d of %d file(s) did not report a size)",
+	"plan.confirm":  "Download %d file(s), about %s?",
+
+	// Request statistics
+	"stats.title":          "Statistics:",
+	"stats.requests":       "Requests:",
+	"stats.requests_value": "%d in %s",
+	"stats.by_status":      "By Status:",
+	"stats.engine_value":   "%d requests, %s, %dms total",
+	"stats.retries":        "Retries:",
+	"stats.throttle":       "Throttle Waits:",
+	"stats.throttle_value": "%d (%dms)",
+	"stats.cache":          "Cache:",
+	"stats.cache_value":    "%d hits, %d misses",
+
+	// Per-URL failures
+	"assert.failed":  "Assertion failed",
+	"status.soft404": "Soft 404",
+	"noai.skipped":   "Reserves TDM",
+	"batch.skipped":  "skipped",
+
+	// version --detailed
+	"version.version":      "Version:",
+	"version.commit":       "Commit:",
+	"version.modified":     "(modified)",
+	"version.built":        "Built:",
+	"version.go":           "Go:",
+	"version.platform":     "OS/Arch:",
+	"version.chrome":       "Chrome:",
+	"version.config_file":  "Config file:",
+	"version.config_dir":   "Config dir:",
+	"version.cache_dir":    "Cache dir:",
+	"version.browsers_dir": "Browsers dir:",
+	"version.none":         "none",
+	"version.features":     "Features:",
+	"version.enabled":      "yes",
+	"version.disabled":     "no",
+
+	// plugin list
+	"plugin.none":     "No plugins found. Plugins are executables named %s<name> on PATH.",
+	"plugin.shadowed": "shadowed:",
+
+	// Other commands
+	"record.saved":      "Recorded",
+	"record.detail":     "(%s, status %d)",
+	"head.summary":      "%d URLs checked, %d failed",
+	"browser.install":   "Installing chrome-headless-shell",
+	"browser.installed": "Installed",
+	"browser.checksum":  "No known checksum for this release; archive SHA-256 is %s",
+	"browser.none":      "No managed browsers installed. Run 'crawl browser install'.",
+	"browser.removed":   "Removed",
+	"doctor.clean":      "No orphaned browser processes",
+	"doctor.zombies":    "%d orphaned browser processes",
+	"doctor.zombie":     "pid %d (from crawl pid %d, exited)  %s",
+	"doctor.hint":       "Run 'crawl doctor --kill-zombies' to stop them",
+	"doctor.killed":     "Stopped %d orphaned browser processes",
+	"doctor.profiles":   "Removed %d stale browser profiles",
+	"sign.saved":        "Signed %d file(s): %s",
+	"verify.ok":         "Signature valid; %d file(s) unchanged, %d page(s) attested",
+	"verify.signed":     "Signed %s by %s",
+	"cookies.none":      "No cookies set",
+	"cookies.summary":   "%d cookies, %d third-party from %d sites",
+	"compare.same":      "All %d variants returned the same fields",
+	"compare.summary":   "%d of %d fields differ across %d variants",
+	"forms.none":        "No forms on the page",
+	"forms.csrf":        "CSRF token",
+}
+
+func init() {
+	RegisterCatalog("en", English)
+}
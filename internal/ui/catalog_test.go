@@ -0,0 +1,34 @@
+package ui
+
+import "testing"
+
+func TestRenderer_T(t *testing.T) {
+	r := NewRenderer(Dark, Catalog{"media.found": "Gefunden"})
+	if got := r.T("media.found"); got != "Gefunden" {
+		t.Errorf("Expected the catalog's message, got %q", got)
+	}
+	if got := r.T("media.workers", 4); got != "4 workers..." {
+		t.Errorf("Expected the English fallback, got %q", got)
+	}
+	if got := r.T("no.such.key"); got != "no.such.key" {
+		t.Errorf("Expected the key for an unknown message, got %q", got)
+	}
+}
+
+func TestSetLanguage(t *testing.T) {
+	defer std.SetCatalog(English)
+	RegisterCatalog("de", Catalog{"summary.title": "Zusammenfassung:"})
+
+	if err := SetLanguage("de_DE.UTF-8"); err != nil {
+		t.Fatalf("Expected the base language to match: %v", err)
+	}
+	if got := T("summary.title"); got != "Zusammenfassung:" {
+		t.Errorf("Expected the German message, got %q", got)
+	}
+	if got := T("summary.total"); got != "Total:" {
+		t.Errorf("Expected missing keys to fall back to English, got %q", got)
+	}
+	if err := SetLanguage("xx"); err == nil {
+		t.Error("Expected an error for a language without a catalog")
+	}
+}
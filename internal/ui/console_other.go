@@ -0,0 +1,9 @@
+//go:build !windows
+
+package ui
+
+// EnableVirtualTerminal reports whether the terminal handles ANSI escape
+// codes; outside Windows they always are
+func EnableVirtualTerminal() bool {
+	return true
+}
@@ -0,0 +1,31 @@
+//go:build windows
+
+package ui
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// EnableVirtualTerminal turns on ANSI escape code handling for the console
+// behind stdout and stderr. It returns false when a console can't process
+// them (conhost before Windows 10), in which case colors should be disabled.
+// Output redirected to a file or pipe is left alone.
+func EnableVirtualTerminal() bool {
+	ok := true
+	for _, f := range []*os.File{os.Stdout, os.Stderr} {
+		h := windows.Handle(f.Fd())
+		var mode uint32
+		if err := windows.GetConsoleMode(h, &mode); err != nil {
+			continue // not a console
+		}
+		if mode&windows.ENABLE_VIRTUAL_TERMINAL_PROCESSING != 0 {
+			continue
+		}
+		if err := windows.SetConsoleMode(h, mode|windows.ENABLE_VIRTUAL_TERMINAL_PROCESSING); err != nil {
+			ok = false
+		}
+	}
+	return ok
+}
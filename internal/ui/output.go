@@ -0,0 +1,40 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// quiet is set by --quiet; status output is dropped while it is true
+var quiet bool
+
+// SetQuiet turns status output on or off. Warnings, errors and the data a
+// command was asked to print are not status output and are unaffected.
+func SetQuiet(q bool) {
+	quiet = q
+}
+
+// Quiet reports whether status output is suppressed
+func Quiet() bool {
+	return quiet
+}
+
+// Status returns the writer for banners, progress notes and summaries:
+// stdout, or io.Discard in quiet mode
+func Status() io.Writer {
+	if quiet {
+		return io.Discard
+	}
+	return os.Stdout
+}
+
+// Printf prints status output, like fmt.Printf unless quiet
+func Printf(format string, args ...interface{}) {
+	fmt.Fprintf(Status(), format, args...)
+}
+
+// Println prints status output, like fmt.Println unless quiet
+func Println(args ...interface{}) {
+	fmt.Fprintln(Status(), args...)
+}
@@ -0,0 +1,18 @@
+package ui
+
+import (
+	"io"
+	"os"
+	"testing"
+)
+
+func TestSetQuiet(t *testing.T) {
+	defer SetQuiet(false)
+	if Status() != io.Writer(os.Stdout) {
+		t.Error("Expected status output on stdout by default")
+	}
+	SetQuiet(true)
+	if !Quiet() || Status() != io.Discard {
+		t.Error("Expected status output to be discarded in quiet mode")
+	}
+}
@@ -0,0 +1,160 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// reset ends every styled span
+const reset = "\033[0m"
+
+// Renderer turns message keys, styles and icons into terminal text using a
+// theme and a message catalog. Commands use the package-level functions,
+// which share one Renderer configured at startup.
+type Renderer struct {
+	mu      sync.RWMutex
+	theme   Theme
+	catalog Catalog
+	colors  bool
+}
+
+// NewRenderer returns a renderer for a theme and catalog. A nil catalog
+// means English.
+func NewRenderer(theme Theme, catalog Catalog) *Renderer {
+	if catalog == nil {
+		catalog = English
+	}
+	return &Renderer{theme: theme, catalog: catalog, colors: true}
+}
+
+// SetTheme switches the theme
+func (r *Renderer) SetTheme(t Theme) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.theme = t
+}
+
+// Theme returns the current theme
+func (r *Renderer) Theme() Theme {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.theme
+}
+
+// SetCatalog switches the message catalog
+func (r *Renderer) SetCatalog(c Catalog) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.catalog = c
+}
+
+// DisableColors drops the theme's escape codes but keeps its icons
+func (r *Renderer) DisableColors() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.colors = false
+}
+
+// ColorsEnabled reports whether the renderer emits escape codes
+func (r *Renderer) ColorsEnabled() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.colors && len(r.theme.Styles) > 0
+}
+
+// Style wraps text in the theme's codes for s. Spans styled inside text
+// keep their look, and the outer style resumes after each of them.
+func (r *Renderer) Style(s Style, text string) string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	code := r.theme.Styles[s]
+	if !r.colors || code == "" {
+		return text
+	}
+	text = strings.TrimSuffix(text, reset)
+	return code + strings.ReplaceAll(text, reset, reset+code) + reset
+}
+
+// Icon returns the theme's symbol for i, or "" if it has none
+func (r *Renderer) Icon(i Icon) string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.theme.Icons[i]
+}
+
+// Mark prefixes text with the icon i when the theme has one
+func (r *Renderer) Mark(i Icon, text string) string {
+	if icon := r.Icon(i); icon != "" {
+		return icon + " " + text
+	}
+	return text
+}
+
+// T returns the message for key, formatted with args like fmt.Sprintf
+func (r *Renderer) T(key string, args ...interface{}) string {
+	r.mu.RLock()
+	c := r.catalog
+	r.mu.RUnlock()
+	return translate(c, key, args...)
+}
+
+// std is the renderer behind the package-level functions
+var std = NewRenderer(Dark, English)
+
+// Default returns the shared renderer
+func Default() *Renderer {
+	return std
+}
+
+// SetTheme switches the shared renderer's theme
+func SetTheme(t Theme) {
+	std.SetTheme(t)
+}
+
+// SetLanguage switches the shared renderer to the catalog for lang
+func SetLanguage(lang string) error {
+	c, ok := lookupCatalog(lang)
+	if !ok {
+		return fmt.Errorf("no message catalog for language %q (available: %s)", lang, strings.Join(Languages(), ", "))
+	}
+	std.SetCatalog(c)
+	return nil
+}
+
+// DisableColors makes all output plain, for NO_COLOR and for consoles that
+// print escape codes literally
+func DisableColors() {
+	std.DisableColors()
+}
+
+// ColorsEnabled reports whether output is colored
+func ColorsEnabled() bool {
+	return std.ColorsEnabled()
+}
+
+// T returns a message from the current catalog
+func T(key string, args ...interface{}) string {
+	return std.T(key, args...)
+}
+
+// Mark prefixes text with an icon from the current theme
+func Mark(i Icon, text string) string {
+	return std.Mark(i, text)
+}
+
+// Paint styles text with the current theme
+func Paint(s Style, text string) string {
+	return std.Style(s, text)
+}
+
+func Bold(s string) string        { return std.Style(StyleBold, s) }
+func Dim(s string) string         { return std.Style(StyleDim, s) }
+func Accent(s string) string      { return std.Style(StyleAccent, s) }
+func Value(s string) string       { return std.Style(StyleValue, s) }
+func Success(s string) string     { return std.Style(StyleSuccess, s) }
+func Warning(s string) string     { return std.Style(StyleWarning, s) }
+func Error(s string) string       { return std.Style(StyleError, s) }
+func Info(s string) string        { return std.Style(StyleInfo, s) }
+func Placeholder(s string) string { return std.Style(StylePlaceholder, s) }
+func Example(s string) string     { return std.Style(StyleExample, s) }
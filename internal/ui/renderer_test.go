@@ -0,0 +1,55 @@
+package ui
+
+import "testing"
+
+func TestRenderer_Style(t *testing.T) {
+	r := NewRenderer(Dark, nil)
+	if got := r.Style(StyleSuccess, "ok"); got != "\033[32mok\033[0m" {
+		t.Errorf("Expected green output, got %q", got)
+	}
+	// The outer style resumes after a nested span
+	nested := r.Style(StyleDim, "a "+r.Style(StyleAccent, "b")+" c")
+	want := "\033[2ma \033[36mb\033[0m\033[2m c\033[0m"
+	if nested != want {
+		t.Errorf("Expected %q, got %q", want, nested)
+	}
+
+	r.DisableColors()
+	if r.ColorsEnabled() {
+		t.Error("Expected ColorsEnabled to report false")
+	}
+	for s := StyleBold; s <= StyleExample; s++ {
+		if got := r.Style(s, "x"); got != "x" {
+			t.Errorf("Expected plain output for style %d, got %q", s, got)
+		}
+	}
+	if r.Icon(IconSuccess) != "✓" {
+		t.Error("Expected DisableColors to keep the theme's icons")
+	}
+}
+
+func TestRenderer_PlainTheme(t *testing.T) {
+	r := NewRenderer(Plain, nil)
+	if r.ColorsEnabled() {
+		t.Error("Expected the plain theme to report no colors")
+	}
+	if got := r.Style(StyleError, "x"); got != "x" {
+		t.Errorf("Expected plain output, got %q", got)
+	}
+	if got := r.Mark(IconSuccess, "Saved"); got != "OK Saved" {
+		t.Errorf("Expected an ASCII icon, got %q", got)
+	}
+	if got := r.Mark(IconTip, "TIP"); got != "TIP" {
+		t.Errorf("Expected no icon and no leading space, got %q", got)
+	}
+}
+
+func TestThemeByName(t *testing.T) {
+	theme, err := ThemeByName(" Light ")
+	if err != nil || theme.Name != "light" {
+		t.Errorf("Expected the light theme, got %q, %v", theme.Name, err)
+	}
+	if _, err := ThemeByName("neon"); err == nil {
+		t.Error("Expected an error for an unknown theme")
+	}
+}
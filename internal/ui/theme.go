@@ -0,0 +1,129 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Style is a role a piece of output plays; a Theme decides how it looks
+type Style int
+
+const (
+	StyleBold        Style = iota
+	StyleDim               // secondary text: descriptions, hints
+	StyleAccent            // command names and usage lines
+	StyleValue             // values printed next to a label
+	StyleSuccess           // completed work
+	StyleWarning           // problems that did not stop the command
+	StyleError             // failures
+	StyleInfo              // progress notes and tips
+	StylePlaceholder       // <command> and other arguments in usage lines
+	StyleExample           // example commands and flag names
+)
+
+// Icon is a symbol printed in front of a status line
+type Icon int
+
+const (
+	IconSuccess Icon = iota // a step that finished
+	IconFailure             // one item that failed
+	IconWarning             // something worth a look
+	IconNone                // nothing was found
+	IconTip                 // a suggestion
+)
+
+// Theme maps styles to ANSI escape sequences and icons to symbols. A
+// style or icon missing from the maps is printed plain.
+type Theme struct {
+	Name   string
+	Styles map[Style]string
+	Icons  map[Icon]string
+}
+
+// emoji are the icons shared by the colored themes
+var emoji = map[Icon]string{
+	IconSuccess: "✓",
+	IconFailure: "✗",
+	IconWarning: "⚠",
+	IconNone:    "❌",
+	IconTip:     "💡",
+}
+
+// Dark suits terminals with a dark background and is the default
+var Dark = Theme{
+	Name: "dark",
+	Styles: map[Style]string{
+		StyleBold:        "\033[1m",
+		StyleDim:         "\033[2m",
+		StyleAccent:      "\033[36m",
+		StyleValue:       "\033[97m",
+		StyleSuccess:     "\033[32m",
+		StyleWarning:     "\033[33m",
+		StyleError:       "\033[31m",
+		StyleInfo:        "\033[2m\033[33m",
+		StylePlaceholder: "\033[33m",
+		StyleExample:     "\033[32m",
+	},
+	Icons: emoji,
+}
+
+// Light avoids bright white and yellow, which vanish on a light background
+var Light = Theme{
+	Name: "light",
+	Styles: map[Style]string{
+		StyleBold:        "\033[1m",
+		StyleDim:         "\033[2m",
+		StyleAccent:      "\033[34m",
+		StyleValue:       "\033[30m",
+		StyleSuccess:     "\033[32m",
+		StyleWarning:     "\033[35m",
+		StyleError:       "\033[31m",
+		StyleInfo:        "\033[34m",
+		StylePlaceholder: "\033[35m",
+		StyleExample:     "\033[32m",
+	},
+	Icons: emoji,
+}
+
+// Plain prints no escape codes and only ASCII, for logs and screen readers
+var Plain = Theme{
+	Name: "plain",
+	Icons: map[Icon]string{
+		IconSuccess: "OK",
+		IconFailure: "FAIL",
+		IconWarning: "WARNING:",
+	},
+}
+
+var themes = map[string]Theme{}
+
+// RegisterTheme makes a theme selectable by name with --theme
+func RegisterTheme(t Theme) {
+	themes[strings.ToLower(t.Name)] = t
+}
+
+// ThemeByName returns a registered theme
+func ThemeByName(name string) (Theme, error) {
+	t, ok := themes[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		return Theme{}, fmt.Errorf("unknown theme %q (available: %s)", name, strings.Join(ThemeNames(), ", "))
+	}
+	return t, nil
+}
+
+// ThemeNames lists the registered themes
+func ThemeNames() []string {
+	names := make([]string, 0, len(themes))
+	for name := range themes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func init() {
+	RegisterTheme(Dark)
+	RegisterTheme(Light)
+	RegisterTheme(Plain)
+}
@@ -0,0 +1,244 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ManifestName is the name of the manifest written at the root of every archive
+const ManifestName = "index.json"
+
+// Entry is a file to pack and where it came from
+type Entry struct {
+	Path        string // File on disk
+	URL         string // Source URL, if the file holds a single resource
+	ContentType string // Detected from the extension when empty
+}
+
+// ManifestEntry describes one file in the archive
+type ManifestEntry struct {
+	File        string `json:"file"` // Name in the archive, slash-separated
+	URL         string `json:"url,omitempty"`
+	ContentType string `json:"content_type,omitempty"`
+	Size        int64  `json:"size"`
+	SHA256      string `json:"sha256"`
+}
+
+// Manifest is the content of index.json
+type Manifest struct {
+	Created time.Time       `json:"created"`
+	Files   []ManifestEntry `json:"files"`
+}
+
+// Supported reports whether path has an archive extension this package can write
+func Supported(path string) bool {
+	lower := strings.ToLower(path)
+	return strings.HasSuffix(lower, ".zip") || strings.HasSuffix(lower, ".tar.gz") || strings.HasSuffix(lower, ".tgz")
+}
+
+// Create packs entries into an archive at archivePath, followed by an
+// index.json manifest listing each file with its source URL, content type and
+// hash. The format is chosen from the extension (.zip, .tar.gz or .tgz).
+// Entry names are made relative to baseDir. A partly written archive is
+// removed on error.
+func Create(archivePath, baseDir string, entries []Entry) (err error) {
+	if !Supported(archivePath) {
+		return fmt.Errorf("unsupported archive format: %s (use .zip, .tar.gz or .tgz)", archivePath)
+	}
+
+	out, err := os.Create(archivePath)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			out.Close()
+			os.Remove(archivePath)
+		}
+	}()
+
+	if strings.HasSuffix(strings.ToLower(archivePath), ".zip") {
+		err = writeZip(out, baseDir, entries)
+	} else {
+		err = writeTarGz(out, baseDir, entries)
+	}
+	if err != nil {
+		return err
+	}
+	return out.Close()
+}
+
+func writeZip(w io.Writer, baseDir string, entries []Entry) error {
+	zw := zip.NewWriter(w)
+	manifest := newManifest()
+
+	for _, e := range entries {
+		name, info, err := manifest.add(baseDir, e)
+		if err != nil {
+			return err
+		}
+
+		header, err := zip.FileInfoHeader(info)
+		if err != nil {
+			return err
+		}
+		header.Name = name
+		header.Method = zip.Deflate
+
+		entry, err := zw.CreateHeader(header)
+		if err != nil {
+			return err
+		}
+		if err := manifest.copy(entry, e.Path); err != nil {
+			return err
+		}
+	}
+
+	data, err := manifest.encode()
+	if err != nil {
+		return err
+	}
+	entry, err := zw.CreateHeader(&zip.FileHeader{Name: ManifestName, Method: zip.Deflate, Modified: manifest.Created})
+	if err != nil {
+		return err
+	}
+	if _, err := entry.Write(data); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+func writeTarGz(w io.Writer, baseDir string, entries []Entry) error {
+	gw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gw)
+	manifest := newManifest()
+
+	for _, e := range entries {
+		name, info, err := manifest.add(baseDir, e)
+		if err != nil {
+			return err
+		}
+
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		header.Name = name
+
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if err := manifest.copy(tw, e.Path); err != nil {
+			return err
+		}
+	}
+
+	data, err := manifest.encode()
+	if err != nil {
+		return err
+	}
+	header := &tar.Header{Name: ManifestName, Mode: 0644, Size: int64(len(data)), ModTime: manifest.Created, Typeflag: tar.TypeReg}
+	if err := tw.WriteHeader(header); err != nil {
+		return err
+	}
+	if _, err := tw.Write(data); err != nil {
+		return err
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gw.Close()
+}
+
+// entryInfo returns the slash-separated archive name and file info for file
+func entryInfo(baseDir, file string) (string, os.FileInfo, error) {
+	info, err := os.Stat(file)
+	if err != nil {
+		return "", nil, err
+	}
+	if info.IsDir() {
+		return "", nil, fmt.Errorf("cannot archive directory entry: %s", file)
+	}
+
+	name := filepath.Base(file)
+	if rel, err := relPath(baseDir, file); err == nil && !strings.HasPrefix(rel, "..") {
+		name = rel
+	}
+	return filepath.ToSlash(name), info, nil
+}
+
+// relPath is filepath.Rel for paths that may mix relative and absolute forms
+func relPath(baseDir, file string) (string, error) {
+	absBase, err := filepath.Abs(baseDir)
+	if err != nil {
+		return "", err
+	}
+	absFile, err := filepath.Abs(file)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Rel(absBase, absFile)
+}
+
+// manifestBuilder collects the manifest while entries are written
+type manifestBuilder struct {
+	Manifest
+	names map[string]bool
+}
+
+func newManifest() *manifestBuilder {
+	return &manifestBuilder{
+		Manifest: Manifest{Created: time.Now().UTC().Truncate(time.Second), Files: []ManifestEntry{}},
+		names:    map[string]bool{ManifestName: true},
+	}
+}
+
+// add records e in the manifest and returns its archive name and file info.
+// Two files may not share a name, nor take the manifest's.
+func (m *manifestBuilder) add(baseDir string, e Entry) (string, os.FileInfo, error) {
+	name, info, err := entryInfo(baseDir, e.Path)
+	if err != nil {
+		return "", nil, err
+	}
+	if m.names[name] {
+		return "", nil, fmt.Errorf("duplicate archive entry %s: %s", name, e.Path)
+	}
+	m.names[name] = true
+
+	contentType := e.ContentType
+	if contentType == "" {
+		contentType = mime.TypeByExtension(filepath.Ext(e.Path))
+	}
+	m.Files = append(m.Files, ManifestEntry{File: name, URL: e.URL, ContentType: contentType, Size: info.Size()})
+	return name, info, nil
+}
+
+// copy writes the file at path to dst and records its hash on the last entry
+func (m *manifestBuilder) copy(dst io.Writer, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(dst, h), f); err != nil {
+		return err
+	}
+	m.Files[len(m.Files)-1].SHA256 = hex.EncodeToString(h.Sum(nil))
+	return nil
+}
+
+func (m *manifestBuilder) encode() ([]byte, error) {
+	return json.MarshalIndent(m.Manifest, "", "  ")
+}
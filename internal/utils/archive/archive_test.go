@@ -0,0 +1,240 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeFiles creates files under dir and returns them as entries
+func writeFiles(t *testing.T, dir string, files map[string]string) []Entry {
+	t.Helper()
+	var entries []Entry
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		entries = append(entries, Entry{Path: path, URL: "https://example.com/" + name})
+	}
+	return entries
+}
+
+// readZip returns the entries of a zip archive by name
+func readZip(t *testing.T, path string) map[string]string {
+	t.Helper()
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		t.Fatalf("Failed to open zip: %v", err)
+	}
+	defer zr.Close()
+
+	entries := map[string]string{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("Failed to open %s: %v", f.Name, err)
+		}
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", f.Name, err)
+		}
+		entries[f.Name] = string(data)
+	}
+	return entries
+}
+
+// readTarGz returns the entries of a gzipped tar archive by name
+func readTarGz(t *testing.T, path string) map[string]string {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gr, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatalf("Failed to open gzip stream: %v", err)
+	}
+	tr := tar.NewReader(gr)
+
+	entries := map[string]string{}
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Failed to read tar: %v", err)
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", header.Name, err)
+		}
+		entries[header.Name] = string(data)
+	}
+	return entries
+}
+
+func TestCreate_RoundTrip(t *testing.T) {
+	base := t.TempDir()
+	files := writeFiles(t, base, map[string]string{
+		"a.jpg":          "image a",
+		"videos/b.mp4":   "video b",
+		"videos/b.json":  `{"url":"b"}`,
+		"deep/er/c.webp": "image c",
+	})
+	// A file outside baseDir is stored by its base name, never as ../
+	files = append(files, writeFiles(t, t.TempDir(), map[string]string{"outside.png": "outside"})...)
+
+	want := map[string]string{
+		"a.jpg":          "image a",
+		"videos/b.mp4":   "video b",
+		"videos/b.json":  `{"url":"b"}`,
+		"deep/er/c.webp": "image c",
+		"outside.png":    "outside",
+	}
+
+	tests := []struct {
+		name string
+		read func(*testing.T, string) map[string]string
+	}{
+		{"media.zip", readZip},
+		{"media.tar.gz", readTarGz},
+		{"media.TGZ", readTarGz},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(t.TempDir(), tt.name)
+		if err := Create(path, base, files); err != nil {
+			t.Fatalf("%s: Create failed: %v", tt.name, err)
+		}
+
+		got := tt.read(t, path)
+		checkManifest(t, tt.name, got, want)
+		delete(got, ManifestName)
+		if len(got) != len(want) {
+			t.Errorf("%s: expected %d entries, got %d: %v", tt.name, len(want), len(got), got)
+		}
+		for name, content := range want {
+			if got[name] != content {
+				t.Errorf("%s: entry %s = %q, want %q", tt.name, name, got[name], content)
+			}
+		}
+		for name := range got {
+			if strings.HasPrefix(name, "/") || strings.HasPrefix(name, "../") || strings.Contains(name, "\\") {
+				t.Errorf("%s: unsafe entry name %q", tt.name, name)
+			}
+		}
+	}
+}
+
+// checkManifest verifies that index.json lists every file with its URL,
+// content type, size and hash
+func checkManifest(t *testing.T, name string, got, want map[string]string) {
+	t.Helper()
+	data, ok := got[ManifestName]
+	if !ok {
+		t.Fatalf("%s: no %s in archive", name, ManifestName)
+	}
+	var manifest Manifest
+	if err := json.Unmarshal([]byte(data), &manifest); err != nil {
+		t.Fatalf("%s: invalid manifest: %v", name, err)
+	}
+	if len(manifest.Files) != len(want) {
+		t.Errorf("%s: manifest lists %d files, want %d", name, len(manifest.Files), len(want))
+	}
+	for _, f := range manifest.Files {
+		content, ok := want[f.File]
+		if !ok {
+			t.Errorf("%s: manifest lists unexpected file %s", name, f.File)
+			continue
+		}
+		sum := sha256.Sum256([]byte(content))
+		if f.SHA256 != hex.EncodeToString(sum[:]) || f.Size != int64(len(content)) {
+			t.Errorf("%s: %s recorded as %d bytes, sha256 %s", name, f.File, f.Size, f.SHA256)
+		}
+		if !strings.HasPrefix(f.URL, "https://example.com/") {
+			t.Errorf("%s: %s has URL %q", name, f.File, f.URL)
+		}
+		if f.File == "a.jpg" && f.ContentType != "image/jpeg" {
+			t.Errorf("%s: a.jpg has content type %q, want image/jpeg", name, f.ContentType)
+		}
+	}
+}
+
+func TestCreate_Errors(t *testing.T) {
+	base := t.TempDir()
+	files := writeFiles(t, base, map[string]string{"a.txt": "a"})
+
+	if err := Create(filepath.Join(base, "out.rar"), base, files); err == nil {
+		t.Error("Expected an error for an unsupported format")
+	}
+	if err := Create(filepath.Join(base, "dir.zip"), base, []Entry{{Path: base}}); err == nil {
+		t.Error("Expected an error for a directory entry")
+	}
+	if err := Create(filepath.Join(base, "missing.tar.gz"), base, []Entry{{Path: filepath.Join(base, "missing.txt")}}); err == nil {
+		t.Error("Expected an error for a missing file")
+	}
+	if err := Create(filepath.Join(base, "twice.zip"), base, append(files, files...)); err == nil {
+		t.Error("Expected an error for a duplicate entry")
+	}
+	manifest := writeFiles(t, base, map[string]string{ManifestName: "{}"})
+	if err := Create(filepath.Join(base, "clash.zip"), base, manifest); err == nil {
+		t.Error("Expected an error for a file named like the manifest")
+	}
+
+	// A failed archive is not left behind
+	for _, name := range []string{"dir.zip", "missing.tar.gz", "twice.zip", "clash.zip"} {
+		if _, err := os.Stat(filepath.Join(base, name)); !os.IsNotExist(err) {
+			t.Errorf("Expected %s to be removed after the error", name)
+		}
+	}
+}
+
+func TestSupported(t *testing.T) {
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"out.zip", true},
+		{"out.ZIP", true},
+		{"out.tar.gz", true},
+		{"out.tgz", true},
+		{"out.tar", false},
+		{"out.gz", false},
+		{"zip", false},
+	}
+	for _, tt := range tests {
+		if got := Supported(tt.path); got != tt.want {
+			t.Errorf("Supported(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestCreate_RelativePaths(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	writeFiles(t, ".", map[string]string{"post.md": "![](assets/p.png)", "assets/p.png": "png"})
+
+	path := filepath.Join(dir, "post.zip")
+	entries := []Entry{{Path: "post.md"}, {Path: filepath.Join("assets", "p.png")}}
+	if err := Create(path, dir, entries); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	got := readZip(t, path)
+	if got["assets/p.png"] != "png" || got["post.md"] == "" {
+		t.Errorf("Expected relative paths kept under the base directory, got %v", got)
+	}
+}
@@ -0,0 +1,30 @@
+package headers
+
+import "net/http"
+
+// BrowserUserAgent is a current desktop Chrome on Windows, the most common browser
+const BrowserUserAgent = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/129.0.0.0 Safari/537.36"
+
+// Browser returns the headers desktop Chrome sends for a top-level
+// navigation, with custom overriding any of them. Accept-Encoding is left
+// to the HTTP client, which only decompresses responses it asked for itself.
+func Browser(custom map[string]string) map[string]string {
+	m := map[string]string{
+		"User-Agent":                BrowserUserAgent,
+		"Accept":                    "text/html,application/xhtml+xml,application/xml;q=0.9,image/avif,image/webp,image/apng,*/*;q=0.8,application/signed-exchange;v=b3;q=0.7",
+		"Accept-Language":           "en-US,en;q=0.9",
+		"Cache-Control":             "max-age=0",
+		"Sec-Ch-Ua":                 `"Google Chrome";v="129", "Not=A?Brand";v="8", "Chromium";v="129"`,
+		"Sec-Ch-Ua-Mobile":          "?0",
+		"Sec-Ch-Ua-Platform":        `"Windows"`,
+		"Sec-Fetch-Dest":            "document",
+		"Sec-Fetch-Mode":            "navigate",
+		"Sec-Fetch-Site":            "none",
+		"Sec-Fetch-User":            "?1",
+		"Upgrade-Insecure-Requests": "1",
+	}
+	for k, v := range custom {
+		m[http.CanonicalHeaderKey(k)] = v
+	}
+	return m
+}
@@ -13,3 +13,16 @@ func TestParseHeaders(t *testing.T) {
 		t.Fatalf("unexpected parse result: %#v", out)
 	}
 }
+
+func TestBrowser_CustomOverrides(t *testing.T) {
+	h := Browser(map[string]string{"user-agent": "Bot", "X-Token": "abc"})
+	if h["User-Agent"] != "Bot" || h["X-Token"] != "abc" {
+		t.Errorf("Expected custom headers to win, got %#v", h)
+	}
+	if h["Sec-Fetch-Mode"] != "navigate" {
+		t.Errorf("Expected browser headers to be kept, got %#v", h)
+	}
+	if _, ok := h["Accept-Encoding"]; ok {
+		t.Error("Expected Accept-Encoding to be left to the HTTP client")
+	}
+}
@@ -2,34 +2,91 @@ package output
 
 import (
 	"encoding/csv"
+	"fmt"
+	"io"
 	"os"
 	"sort"
+	"strconv"
+	"unicode/utf8"
 
 	"github.com/law-makers/crawl/pkg/models"
 )
 
-// SaveCSV writes page data to a CSV file. Returns an error on failure.
-func SaveCSV(data *models.PageData, filepath string) error {
+// utf8BOM marks a file as UTF-8 for Excel
+const utf8BOM = "\uFEFF"
+
+// CSVDialect controls how CSV files are written. The zero value writes
+// comma-separated RFC 4180 files with a header row and LF line endings.
+type CSVDialect struct {
+	Comma    rune // Field delimiter (default ',')
+	NoHeader bool // Leave out the header row
+	BOM      bool // Start each file with a UTF-8 byte order mark
+	CRLF     bool // End lines with \r\n
+}
+
+// csvDialect is used by SaveCSV and RotatingWriter
+var csvDialect CSVDialect
+
+// SetCSVDialect sets the dialect of every CSV file written by this package
+func SetCSVDialect(d CSVDialect) {
+	csvDialect = d
+}
+
+// ParseDelimiter parses a --csv-delimiter value: a single character, or
+// "tab" / "\t" for tab-separated output
+func ParseDelimiter(s string) (rune, error) {
+	switch s {
+	case "":
+		return ',', nil
+	case "tab", `\t`:
+		return '\t', nil
+	}
+	r, size := utf8.DecodeRuneInString(s)
+	if size != len(s) || r == '"' || r == '\r' || r == '\n' || r == utf8.RuneError {
+		return 0, fmt.Errorf("invalid CSV delimiter %q (must be a single character other than a quote or newline)", s)
+	}
+	return r, nil
+}
+
+// newCSVWriter returns a csv.Writer for w configured with the dialect
+func newCSVWriter(w io.Writer) *csv.Writer {
+	writer := csv.NewWriter(w)
+	if csvDialect.Comma != 0 {
+		writer.Comma = csvDialect.Comma
+	}
+	writer.UseCRLF = csvDialect.CRLF
+	return writer
+}
+
+// SaveCSV writes page data to a CSV file. Structured rows use columns in
+// the given order, or their keys sorted when columns is empty. Returns an
+// error on failure.
+func SaveCSV(data *models.PageData, filepath string, columns []string) error {
 	file, err := os.Create(filepath)
 	if err != nil {
 		return err
 	}
 	defer file.Close()
 
-	writer := csv.NewWriter(file)
+	if csvDialect.BOM {
+		if _, err := io.WriteString(file, utf8BOM); err != nil {
+			return err
+		}
+	}
+	writer := newCSVWriter(file)
 	defer writer.Flush()
 
-	// If we have structured data (from --fields), use that
-	if len(data.Structured) > 0 {
-		// Get headers from the first item
-		var headers []string
-		firstItem := data.Structured[0]
-		for k := range firstItem {
-			headers = append(headers, k)
+	writeHeader := func(header []string) error {
+		if csvDialect.NoHeader {
+			return nil
 		}
-		sort.Strings(headers)
+		return writer.Write(header)
+	}
 
-		if err := writer.Write(headers); err != nil {
+	// If we have structured data (from --fields), use that
+	if len(data.Structured) > 0 {
+		headers := structuredColumns(data.Structured, columns)
+		if err := writeHeader(headers); err != nil {
 			return err
 		}
 
@@ -43,18 +100,27 @@ func SaveCSV(data *models.PageData, filepath string) error {
 			}
 		}
 	} else if len(data.Data) > 0 {
-		// If we have list data but no fields, just dump Text and HTML
-		if err := writer.Write([]string{"Text", "HTML"}); err != nil {
+		// If we have list data (--all-matches) but no fields, one row per match
+		if err := writeHeader([]string{"Index", "Selector", "Text", "HTML"}); err != nil {
 			return err
 		}
 		for _, item := range data.Data {
-			if err := writer.Write([]string{item.Text, item.HTML}); err != nil {
+			if err := writer.Write([]string{strconv.Itoa(item.Index), item.Selector, item.Text, item.HTML}); err != nil {
 				return err
 			}
 		}
+	} else if projection.only != nil || projection.omit != nil {
+		// Page columns chosen with --only/--omit
+		cols := projectedColumns(csvColumns)
+		if err := writeHeader(cols); err != nil {
+			return err
+		}
+		if err := writer.Write(pageRow(data, cols)); err != nil {
+			return err
+		}
 	} else {
 		// Fallback for single page content
-		if err := writer.Write([]string{"Content", "HTML"}); err != nil {
+		if err := writeHeader([]string{"Content", "HTML"}); err != nil {
 			return err
 		}
 		if err := writer.Write([]string{data.Content, data.HTML}); err != nil {
@@ -64,3 +130,17 @@ func SaveCSV(data *models.PageData, filepath string) error {
 
 	return nil
 }
+
+// structuredColumns returns columns, or when it is empty the keys of the
+// first row sorted so that output is stable
+func structuredColumns(rows []map[string]string, columns []string) []string {
+	if len(columns) > 0 {
+		return columns
+	}
+	var headers []string
+	for k := range rows[0] {
+		headers = append(headers, k)
+	}
+	sort.Strings(headers)
+	return headers
+}
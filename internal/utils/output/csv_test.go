@@ -0,0 +1,86 @@
+package output
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/law-makers/crawl/pkg/models"
+)
+
+func TestSaveCSV_ColumnOrder(t *testing.T) {
+	data := &models.PageData{Structured: []map[string]string{
+		{"name": "Tea", "price": "$3", "link": "/tea"},
+		{"name": "Cake", "price": "$5", "link": "/cake"},
+	}}
+	path := filepath.Join(t.TempDir(), "items.csv")
+
+	if err := SaveCSV(data, path, []string{"price", "name", "link"}); err != nil {
+		t.Fatal(err)
+	}
+	b, _ := os.ReadFile(path)
+	if want := "price,name,link\n$3,Tea,/tea\n$5,Cake,/cake\n"; string(b) != want {
+		t.Errorf("unexpected CSV:\n%s", b)
+	}
+
+	// Without columns the keys are sorted
+	if err := SaveCSV(data, path, nil); err != nil {
+		t.Fatal(err)
+	}
+	b, _ = os.ReadFile(path)
+	if !strings.HasPrefix(string(b), "link,name,price\n") {
+		t.Errorf("unexpected header:\n%s", b)
+	}
+}
+
+func TestSaveMarkdown_StructuredTable(t *testing.T) {
+	data := &models.PageData{Structured: []map[string]string{{"name": "A|B", "price": "$3"}}}
+	path := filepath.Join(t.TempDir(), "items.md")
+
+	if err := SaveMarkdown(data, path, MarkdownOptions{Columns: []string{"price", "name"}}); err != nil {
+		t.Fatal(err)
+	}
+	b, _ := os.ReadFile(path)
+	want := "| price | name |\n| --- | --- |\n| $3 | A\\|B |\n"
+	if string(b) != want {
+		t.Errorf("unexpected table:\n%s", b)
+	}
+}
+
+func TestSaveCSV_Dialect(t *testing.T) {
+	SetCSVDialect(CSVDialect{Comma: ';', BOM: true, CRLF: true})
+	defer SetCSVDialect(CSVDialect{})
+
+	data := &models.PageData{Structured: []map[string]string{{"name": "Tea", "price": "3,50"}}}
+	path := filepath.Join(t.TempDir(), "items.csv")
+	if err := SaveCSV(data, path, []string{"name", "price"}); err != nil {
+		t.Fatal(err)
+	}
+	b, _ := os.ReadFile(path)
+	if want := "\uFEFFname;price\r\nTea;3,50\r\n"; string(b) != want {
+		t.Errorf("unexpected CSV: %q", b)
+	}
+
+	SetCSVDialect(CSVDialect{NoHeader: true})
+	if err := SaveCSV(data, path, []string{"name", "price"}); err != nil {
+		t.Fatal(err)
+	}
+	b, _ = os.ReadFile(path)
+	if string(b) != "Tea,\"3,50\"\n" {
+		t.Errorf("unexpected CSV without header: %q", b)
+	}
+}
+
+func TestParseDelimiter(t *testing.T) {
+	for in, want := range map[string]rune{"": ',', ";": ';', "tab": '\t', `\t`: '\t', "|": '|'} {
+		if got, err := ParseDelimiter(in); err != nil || got != want {
+			t.Errorf("ParseDelimiter(%q) = %q, %v", in, got, err)
+		}
+	}
+	for _, bad := range []string{`"`, ";;", "\n"} {
+		if _, err := ParseDelimiter(bad); err == nil {
+			t.Errorf("ParseDelimiter(%q) should fail", bad)
+		}
+	}
+}
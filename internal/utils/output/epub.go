@@ -0,0 +1,378 @@
+package output
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha1"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	urlutil "github.com/law-makers/crawl/internal/utils/url"
+	"github.com/law-makers/crawl/pkg/models"
+	"golang.org/x/net/html"
+)
+
+// EPUBOptions controls SaveEPUB
+type EPUBOptions struct {
+	Title  string // Book title (default: the first page's title)
+	Author string
+
+	// FetchImage downloads an image for embedding. Images it fails on, or
+	// all images when it is nil, are replaced by their alt text, since
+	// readers don't load remote images.
+	FetchImage func(url string) (data []byte, mediaType string, err error)
+}
+
+// epubChapter is one page rendered as an XHTML document
+type epubChapter struct {
+	file  string
+	title string
+	body  string
+}
+
+// epubImage is an image embedded in the book
+type epubImage struct {
+	id        string
+	file      string
+	mediaType string
+	data      []byte
+}
+
+// imageExtensions maps the image types EPUB readers must support to file extensions
+var imageExtensions = map[string]string{
+	"image/jpeg":    ".jpg",
+	"image/png":     ".png",
+	"image/gif":     ".gif",
+	"image/svg+xml": ".svg",
+	"image/webp":    ".webp",
+}
+
+// SaveEPUB writes pages to an EPUB 3 book with one chapter per page, the
+// page images embedded and a generated table of contents
+func SaveEPUB(pages []*models.PageData, filepath string, opts EPUBOptions) error {
+	f, err := os.Create(filepath)
+	if err != nil {
+		return err
+	}
+	if err := WriteEPUB(f, pages, opts); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// WriteEPUB writes the book described by SaveEPUB to w
+func WriteEPUB(w io.Writer, pages []*models.PageData, opts EPUBOptions) error {
+	if len(pages) == 0 {
+		return fmt.Errorf("no pages to write")
+	}
+
+	title := opts.Title
+	if title == "" {
+		title = chapterTitle(pages[0])
+	}
+
+	images := make(map[string]*epubImage)
+	var imageOrder []*epubImage
+	embed := func(src string) (string, bool) {
+		if img, ok := images[src]; ok {
+			return img.file, img.data != nil
+		}
+		img := &epubImage{}
+		images[src] = img
+		if opts.FetchImage == nil {
+			return "", false
+		}
+		data, mediaType, err := opts.FetchImage(src)
+		mediaType, _, _ = strings.Cut(mediaType, ";")
+		mediaType = strings.TrimSpace(mediaType)
+		if _, ok := imageExtensions[mediaType]; !ok {
+			mediaType = imageMediaType(src)
+		}
+		ext, ok := imageExtensions[mediaType]
+		if err != nil || !ok || len(data) == 0 {
+			return "", false
+		}
+		img.id = fmt.Sprintf("img%d", len(imageOrder)+1)
+		img.file = "images/" + img.id + ext
+		img.mediaType = mediaType
+		img.data = data
+		imageOrder = append(imageOrder, img)
+		return img.file, true
+	}
+
+	chapters := make([]epubChapter, len(pages))
+	for i, p := range pages {
+		body, err := chapterBody(p, embed)
+		if err != nil {
+			return fmt.Errorf("failed to convert %s: %w", p.URL, err)
+		}
+		chapters[i] = epubChapter{
+			file:  fmt.Sprintf("chapter%03d.xhtml", i+1),
+			title: chapterTitle(p),
+			body:  body,
+		}
+	}
+
+	zw := zip.NewWriter(w)
+	// The mimetype must come first and be stored uncompressed
+	mw, err := zw.CreateHeader(&zip.FileHeader{Name: "mimetype", Method: zip.Store})
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(mw, "application/epub+zip"); err != nil {
+		return err
+	}
+
+	id := bookID(pages)
+	files := []struct {
+		name string
+		body []byte
+	}{
+		{"META-INF/container.xml", []byte(epubContainer)},
+		{"OEBPS/content.opf", []byte(epubPackage(id, title, opts.Author, chapters, imageOrder))},
+		{"OEBPS/nav.xhtml", []byte(epubNav(title, chapters))},
+		{"OEBPS/toc.ncx", []byte(epubNCX(id, title, chapters))},
+	}
+	for _, c := range chapters {
+		files = append(files, struct {
+			name string
+			body []byte
+		}{"OEBPS/" + c.file, []byte(xhtmlDocument(c.title, c.body))})
+	}
+	for _, img := range imageOrder {
+		files = append(files, struct {
+			name string
+			body []byte
+		}{"OEBPS/" + img.file, img.data})
+	}
+
+	for _, file := range files {
+		fw, err := zw.Create(file.name)
+		if err != nil {
+			return err
+		}
+		if _, err := fw.Write(file.body); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+func chapterTitle(p *models.PageData) string {
+	if t := strings.TrimSpace(p.Title); t != "" {
+		return t
+	}
+	return p.URL
+}
+
+// chapterBody converts a page's HTML (or, without HTML, its text) into an
+// XHTML fragment, resolving links and embedding images through embed
+func chapterBody(p *models.PageData, embed func(src string) (string, bool)) (string, error) {
+	var b strings.Builder
+	if strings.TrimSpace(p.HTML) == "" {
+		fmt.Fprintf(&b, "<h1>%s</h1>", xmlEscape(chapterTitle(p)))
+		for _, para := range strings.Split(p.Content, "\n\n") {
+			if para = strings.TrimSpace(para); para != "" {
+				fmt.Fprintf(&b, "<p>%s</p>", xmlEscape(para))
+			}
+		}
+		return b.String(), nil
+	}
+
+	cleaned, err := CleanHTML(p.HTML)
+	if err != nil {
+		return "", err
+	}
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(cleaned))
+	if err != nil {
+		return "", err
+	}
+	body := doc.Find("body")
+
+	body.Find("a[href]").Each(func(i int, s *goquery.Selection) {
+		href, _ := s.Attr("href")
+		s.SetAttr("href", urlutil.ResolveURL(p.URL, href))
+	})
+	body.Find("img").Each(func(i int, s *goquery.Selection) {
+		src, _ := s.Attr("src")
+		alt, _ := s.Attr("alt")
+		if src != "" {
+			if file, ok := embed(urlutil.ResolveURL(p.URL, src)); ok {
+				s.SetAttr("src", file)
+				s.SetAttr("alt", alt) // required in XHTML
+				return
+			}
+		}
+		s.ReplaceWithHtml(html.EscapeString(alt))
+	})
+
+	if body.Find("h1").Length() == 0 {
+		fmt.Fprintf(&b, "<h1>%s</h1>", xmlEscape(chapterTitle(p)))
+	}
+	for _, n := range body.Nodes {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			renderXHTML(&b, c)
+		}
+	}
+	return b.String(), nil
+}
+
+// voidElements have no content and are written self-closed
+var voidElements = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true, "hr": true,
+	"img": true, "input": true, "link": true, "meta": true, "source": true, "track": true, "wbr": true,
+}
+
+// renderXHTML writes n as well-formed XML, which EPUB requires and
+// html.Render does not produce
+func renderXHTML(b *strings.Builder, n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		b.WriteString(xmlEscape(n.Data))
+	case html.ElementNode:
+		if !validXMLName(n.Data) {
+			// Keep the content of elements XML can't name, such as <o:p>
+			for c := n.FirstChild; c != nil; c = c.NextSibling {
+				renderXHTML(b, c)
+			}
+			return
+		}
+		b.WriteString("<" + n.Data)
+		for _, a := range n.Attr {
+			if a.Namespace != "" || !validXMLName(a.Key) {
+				continue
+			}
+			fmt.Fprintf(b, ` %s="%s"`, a.Key, xmlEscape(a.Val))
+		}
+		if voidElements[n.Data] {
+			b.WriteString("/>")
+			return
+		}
+		b.WriteString(">")
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			renderXHTML(b, c)
+		}
+		b.WriteString("</" + n.Data + ">")
+	}
+}
+
+func validXMLName(name string) bool {
+	if name == "" || strings.ContainsAny(name[:1], "-.0123456789") {
+		return false
+	}
+	for _, r := range name {
+		if !(r == '-' || r == '_' || r == '.' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
+func xmlEscape(s string) string {
+	var buf bytes.Buffer
+	xml.EscapeText(&buf, []byte(s))
+	return buf.String()
+}
+
+// bookID derives a stable urn:uuid from the page URLs, so rebuilding the
+// same crawl updates the book in a reader's library instead of duplicating it
+func bookID(pages []*models.PageData) string {
+	h := sha1.New()
+	for _, p := range pages {
+		io.WriteString(h, p.URL+"\n")
+	}
+	sum := h.Sum(nil)
+	sum[6] = sum[6]&0x0f | 0x50 // version 5
+	sum[8] = sum[8]&0x3f | 0x80
+	return fmt.Sprintf("urn:uuid:%x-%x-%x-%x-%x", sum[0:4], sum[4:6], sum[6:8], sum[8:10], sum[10:16])
+}
+
+func xhtmlDocument(title, body string) string {
+	return xml.Header + `<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops">
+<head><meta charset="utf-8"/><title>` + xmlEscape(title) + `</title></head>
+<body>` + body + `</body>
+</html>
+`
+}
+
+const epubContainer = xml.Header + `<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+<rootfiles><rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/></rootfiles>
+</container>
+`
+
+func epubPackage(id, title, author string, chapters []epubChapter, images []*epubImage) string {
+	var b strings.Builder
+	b.WriteString(xml.Header)
+	b.WriteString(`<package xmlns="http://www.idpf.org/2007/opf" version="3.0" unique-identifier="bookid">` + "\n")
+	b.WriteString(`<metadata xmlns:dc="http://purl.org/dc/elements/1.1/">` + "\n")
+	fmt.Fprintf(&b, "<dc:identifier id=\"bookid\">%s</dc:identifier>\n", id)
+	fmt.Fprintf(&b, "<dc:title>%s</dc:title>\n", xmlEscape(title))
+	if author != "" {
+		fmt.Fprintf(&b, "<dc:creator>%s</dc:creator>\n", xmlEscape(author))
+	}
+	b.WriteString("<dc:language>en</dc:language>\n")
+	fmt.Fprintf(&b, "<meta property=\"dcterms:modified\">%s</meta>\n", time.Now().UTC().Format("2006-01-02T15:04:05Z"))
+	b.WriteString("</metadata>\n<manifest>\n")
+	b.WriteString(`<item id="nav" href="nav.xhtml" media-type="application/xhtml+xml" properties="nav"/>` + "\n")
+	b.WriteString(`<item id="ncx" href="toc.ncx" media-type="application/x-dtbncx+xml"/>` + "\n")
+	for i, c := range chapters {
+		fmt.Fprintf(&b, "<item id=\"ch%d\" href=\"%s\" media-type=\"application/xhtml+xml\"/>\n", i+1, c.file)
+	}
+	for _, img := range images {
+		fmt.Fprintf(&b, "<item id=\"%s\" href=\"%s\" media-type=\"%s\"/>\n", img.id, img.file, img.mediaType)
+	}
+	b.WriteString("</manifest>\n<spine toc=\"ncx\">\n")
+	for i := range chapters {
+		fmt.Fprintf(&b, "<itemref idref=\"ch%d\"/>\n", i+1)
+	}
+	b.WriteString("</spine>\n</package>\n")
+	return b.String()
+}
+
+func epubNav(title string, chapters []epubChapter) string {
+	var b strings.Builder
+	b.WriteString(`<nav epub:type="toc" id="toc"><h1>Contents</h1><ol>`)
+	for _, c := range chapters {
+		fmt.Fprintf(&b, `<li><a href="%s">%s</a></li>`, c.file, xmlEscape(c.title))
+	}
+	b.WriteString(`</ol></nav>`)
+	return xhtmlDocument(title, b.String())
+}
+
+// epubNCX is the EPUB 2 table of contents, for older readers
+func epubNCX(id, title string, chapters []epubChapter) string {
+	var b strings.Builder
+	b.WriteString(xml.Header)
+	b.WriteString(`<ncx xmlns="http://www.daisy.org/z3986/2005/ncx/" version="2005-1">` + "\n")
+	fmt.Fprintf(&b, "<head><meta name=\"dtb:uid\" content=\"%s\"/></head>\n", id)
+	fmt.Fprintf(&b, "<docTitle><text>%s</text></docTitle>\n<navMap>\n", xmlEscape(title))
+	for i, c := range chapters {
+		fmt.Fprintf(&b, "<navPoint id=\"np%d\" playOrder=\"%d\"><navLabel><text>%s</text></navLabel><content src=\"%s\"/></navPoint>\n", i+1, i+1, xmlEscape(c.title), c.file)
+	}
+	b.WriteString("</navMap>\n</ncx>\n")
+	return b.String()
+}
+
+// imageMediaType guesses an image's type from its URL when the server sent none
+func imageMediaType(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	ext := strings.ToLower(path.Ext(u.Path))
+	for mediaType, e := range imageExtensions {
+		if e == ext || (ext == ".jpeg" && e == ".jpg") {
+			return mediaType
+		}
+	}
+	return ""
+}
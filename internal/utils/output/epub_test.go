@@ -0,0 +1,83 @@
+package output
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/law-makers/crawl/pkg/models"
+)
+
+func TestWriteEPUB(t *testing.T) {
+	pages := []*models.PageData{
+		{URL: "https://example.com/docs/a", Title: "Intro", HTML: `<body><p>See <a href="b">next</a><br>line<img src="/logo.png" alt="Logo"><img src="gone.png" alt="Missing"></p></body>`},
+		{URL: "https://example.com/docs/b", Title: "Usage & Tips", Content: "First.\n\nSecond."},
+	}
+	fetched := 0
+	opts := EPUBOptions{
+		Title: "Docs",
+		FetchImage: func(url string) ([]byte, string, error) {
+			fetched++
+			if url == "https://example.com/logo.png" {
+				return []byte("\x89PNG"), "image/png", nil
+			}
+			return nil, "", errors.New("404")
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteEPUB(&buf, pages, opts); err != nil {
+		t.Fatal(err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if first := zr.File[0]; first.Name != "mimetype" || first.Method != zip.Store {
+		t.Errorf("mimetype must be the first, uncompressed entry; got %s (method %d)", first.Name, first.Method)
+	}
+
+	files := make(map[string]string)
+	for _, f := range zr.File {
+		rc, _ := f.Open()
+		b, _ := io.ReadAll(rc)
+		rc.Close()
+		files[f.Name] = string(b)
+
+		if strings.HasSuffix(f.Name, ".xhtml") || strings.HasSuffix(f.Name, ".opf") || strings.HasSuffix(f.Name, ".ncx") {
+			dec := xml.NewDecoder(bytes.NewReader(b))
+			for {
+				if _, err := dec.Token(); err == io.EOF {
+					break
+				} else if err != nil {
+					t.Errorf("%s is not well-formed XML: %v", f.Name, err)
+					break
+				}
+			}
+		}
+	}
+
+	ch1 := files["OEBPS/chapter001.xhtml"]
+	for _, want := range []string{`href="https://example.com/docs/b"`, "<br/>", `<img src="images/img1.png" alt="Logo"/>`, "Missing"} {
+		if !strings.Contains(ch1, want) {
+			t.Errorf("chapter 1 missing %q:\n%s", want, ch1)
+		}
+	}
+	if files["OEBPS/images/img1.png"] != "\x89PNG" {
+		t.Error("image was not embedded")
+	}
+	if ch2 := files["OEBPS/chapter002.xhtml"]; !strings.Contains(ch2, "<p>Second.</p>") {
+		t.Errorf("text-only page should become paragraphs:\n%s", ch2)
+	}
+	if nav := files["OEBPS/nav.xhtml"]; !strings.Contains(nav, "Usage &amp; Tips") {
+		t.Errorf("table of contents missing chapter:\n%s", nav)
+	}
+	if fetched != 2 {
+		t.Errorf("expected each image to be fetched once, got %d fetches", fetched)
+	}
+}
@@ -3,19 +3,46 @@ package output
 import (
 	"encoding/json"
 	"os"
+	"reflect"
 
 	urlutil "github.com/law-makers/crawl/internal/utils/url"
 	"github.com/law-makers/crawl/pkg/models"
 )
 
-// SaveJSON writes a compacted JSON export of the PageData (HTML removed) to filepath.
-func SaveJSON(data *models.PageData, filepath string) error {
+// ExportData returns a copy of the PageData prepared for export: HTML removed
+// (unless requested with --only), fields excluded by the projection cleared,
+// and relative links resolved against the page URL.
+func ExportData(data *models.PageData) models.PageData {
 	// Create a copy to avoid modifying the original data
 	exportData := *data
-	exportData.HTML = "" // Remove HTML from JSON export
+	if !projection.keepHTML() {
+		exportData.HTML = "" // Remove HTML from JSON export
+	}
 	urlutil.ResolveRelativeLinks(&exportData)
 
-	content, err := json.MarshalIndent(exportData, "", "  ")
+	v := reflect.ValueOf(&exportData).Elem()
+	for i, name := range pageFields {
+		if !projection.Includes(name) {
+			v.Field(i).Set(reflect.Zero(v.Field(i).Type()))
+		}
+	}
+	return exportData
+}
+
+// ExportJSON returns the JSON form of ExportData, which also leaves out
+// fields the projection excludes even when they have no omitempty tag.
+func ExportJSON(data *models.PageData) json.Marshaler {
+	return exportObject{data: ExportData(data), p: projection}
+}
+
+// MarshalJSON returns a single-line JSON export of the PageData (HTML removed).
+func MarshalJSON(data *models.PageData) ([]byte, error) {
+	return json.Marshal(ExportJSON(data))
+}
+
+// SaveJSON writes a compacted JSON export of the PageData (HTML removed) to filepath.
+func SaveJSON(data *models.PageData, filepath string) error {
+	content, err := json.MarshalIndent(ExportJSON(data), "", "  ")
 	if err != nil {
 		return err
 	}
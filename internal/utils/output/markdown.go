@@ -3,16 +3,74 @@ package output
 import (
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	md "github.com/JohannesKaufmann/html-to-markdown"
 	"github.com/JohannesKaufmann/html-to-markdown/plugin"
 	"github.com/PuerkitoBio/goquery"
 	urlutil "github.com/law-makers/crawl/internal/utils/url"
 	"github.com/law-makers/crawl/pkg/models"
+	"gopkg.in/yaml.v3"
 )
 
-// SaveMarkdown converts HTML to Markdown and writes it to filepath
-func SaveMarkdown(data *models.PageData, filepath string) error {
+// MarkdownOptions controls SaveMarkdown
+type MarkdownOptions struct {
+	Columns           []string          // Structured table column order (sorted keys when empty)
+	Frontmatter       bool              // Start with YAML frontmatter: url, title, date and tags
+	Tags              []string          // Frontmatter tags
+	NormalizeHeadings bool              // Shift headings so the highest level on the page becomes #
+	Images            map[string]string // Absolute image URL to the local path written in its place
+}
+
+// frontmatter is written in field order, so it stays stable between runs
+type frontmatter struct {
+	URL   string   `yaml:"url"`
+	Title string   `yaml:"title,omitempty"`
+	Date  string   `yaml:"date"`
+	Tags  []string `yaml:"tags,omitempty"`
+}
+
+// SaveMarkdown converts HTML to Markdown and writes it to filepath. Pages
+// with structured data or selector matches are written as a table instead.
+func SaveMarkdown(data *models.PageData, filepath string, opts MarkdownOptions) error {
+	var body string
+	if len(data.Structured) > 0 {
+		body = markdownTable(data.Structured, opts.Columns)
+	} else if len(data.Data) > 0 {
+		rows := make([]map[string]string, len(data.Data))
+		for i, item := range data.Data {
+			rows[i] = map[string]string{"#": strconv.Itoa(item.Index), "Text": item.Text, "Selector": item.Selector}
+		}
+		body = markdownTable(rows, []string{"#", "Text", "Selector"})
+	} else {
+		var err error
+		if body, err = pageMarkdown(data, opts); err != nil {
+			return err
+		}
+	}
+
+	if opts.Frontmatter {
+		fetched := data.FetchedAt
+		if fetched.IsZero() {
+			fetched = time.Now()
+		}
+		head, err := yaml.Marshal(frontmatter{
+			URL:   data.URL,
+			Title: strings.TrimSpace(data.Title),
+			Date:  fetched.UTC().Format(time.RFC3339),
+			Tags:  opts.Tags,
+		})
+		if err != nil {
+			return err
+		}
+		body = "---\n" + string(head) + "---\n\n" + body
+	}
+	return os.WriteFile(filepath, []byte(body), 0644)
+}
+
+func pageMarkdown(data *models.PageData, opts MarkdownOptions) (string, error) {
 	converter := md.NewConverter("", true, nil)
 	converter.Use(plugin.GitHubFlavored())
 
@@ -36,14 +94,79 @@ func SaveMarkdown(data *models.PageData, filepath string) error {
 		},
 	})
 
+	// Point images at their downloaded copies, or resolve them like links
+	converter.AddRules(md.Rule{
+		Filter: []string{"img"},
+		Replacement: func(content string, selec *goquery.Selection, opt *md.Options) *string {
+			src, exists := selec.Attr("src")
+			if !exists || src == "" {
+				return nil
+			}
+
+			target := urlutil.ResolveURL(data.URL, src)
+			if local, ok := opts.Images[target]; ok {
+				target = local
+			}
+			alt, _ := selec.Attr("alt")
+			str := fmt.Sprintf("![%s](%s)", alt, target)
+			return &str
+		},
+	})
+
 	cleaned, err := CleanHTML(data.HTML)
 	if err != nil {
-		return err
+		return "", err
+	}
+	if opts.NormalizeHeadings {
+		if cleaned, err = normalizeHeadings(cleaned); err != nil {
+			return "", err
+		}
 	}
 
-	mdStr, err := converter.ConvertString(cleaned)
+	return converter.ConvertString(cleaned)
+}
+
+// normalizeHeadings renumbers headings so the highest level used becomes
+// h1, keeping their relative depth. Pages often start at h2 or h3 under a
+// site banner, which reads as a document missing its title.
+func normalizeHeadings(htmlContent string) (string, error) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(htmlContent))
 	if err != nil {
-		return err
+		return "", err
+	}
+
+	headings := doc.Find("h1, h2, h3, h4, h5, h6")
+	top := 7
+	headings.Each(func(i int, s *goquery.Selection) {
+		if level := int(s.Nodes[0].Data[1] - '0'); level < top {
+			top = level
+		}
+	})
+	if top == 7 || top == 1 {
+		return htmlContent, nil
+	}
+
+	headings.Each(func(i int, s *goquery.Selection) {
+		level := int(s.Nodes[0].Data[1]-'0') - (top - 1)
+		s.Nodes[0].Data = fmt.Sprintf("h%d", level)
+	})
+	return doc.Html()
+}
+
+// markdownTable renders structured rows as a GitHub-flavored Markdown table
+func markdownTable(rows []map[string]string, columns []string) string {
+	headers := structuredColumns(rows, columns)
+	cell := strings.NewReplacer("|", "\\|", "\r\n", "<br>", "\n", "<br>")
+
+	var b strings.Builder
+	b.WriteString("| " + strings.Join(headers, " | ") + " |\n")
+	b.WriteString(strings.Repeat("| --- ", len(headers)) + "|\n")
+	for _, row := range rows {
+		cells := make([]string, len(headers))
+		for i, h := range headers {
+			cells[i] = cell.Replace(row[h])
+		}
+		b.WriteString("| " + strings.Join(cells, " | ") + " |\n")
 	}
-	return os.WriteFile(filepath, []byte(mdStr), 0644)
+	return b.String()
 }
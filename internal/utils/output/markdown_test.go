@@ -0,0 +1,45 @@
+package output
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/law-makers/crawl/pkg/models"
+)
+
+func TestSaveMarkdown_FrontmatterAndImages(t *testing.T) {
+	data := &models.PageData{
+		URL:       "https://example.com/blog/post",
+		Title:     "A Post",
+		FetchedAt: time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC),
+		HTML:      `<article><h3>Intro</h3><img src="/img/cat.png" alt="cat"><img src="dog.png" alt="dog"><h4>More</h4></article>`,
+	}
+	path := filepath.Join(t.TempDir(), "post.md")
+
+	err := SaveMarkdown(data, path, MarkdownOptions{
+		Frontmatter:       true,
+		Tags:              []string{"reading"},
+		NormalizeHeadings: true,
+		Images:            map[string]string{"https://example.com/img/cat.png": "assets/cat.png"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := os.ReadFile(path)
+	out := string(b)
+
+	if !strings.HasPrefix(out, "---\nurl: https://example.com/blog/post\ntitle: A Post\ndate: \"2024-05-01T08:00:00Z\"\ntags:\n    - reading\n---\n\n") {
+		t.Errorf("unexpected frontmatter:\n%s", out)
+	}
+	for _, want := range []string{"# Intro", "## More", "![cat](assets/cat.png)", "![dog](https://example.com/blog/dog.png)"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "### Intro") {
+		t.Errorf("headings were not normalized:\n%s", out)
+	}
+}
@@ -0,0 +1,157 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/law-makers/crawl/pkg/models"
+)
+
+// Projection selects which PageData fields the exporters write, by JSON
+// field name. The zero value keeps every field except html.
+type Projection struct {
+	only map[string]bool
+	omit map[string]bool
+}
+
+// projection is used by ExportData, MarshalJSON, SaveJSON and the CSV writers
+var projection Projection
+
+// SetProjection sets the fields written by every exporter in this package
+func SetProjection(p Projection) {
+	projection = p
+}
+
+// pageFields lists the JSON names of PageData's fields in declaration order
+var pageFields = func() []string {
+	t := reflect.TypeOf(models.PageData{})
+	names := make([]string, t.NumField())
+	for i := range names {
+		names[i] = jsonName(t.Field(i))
+	}
+	return names
+}()
+
+// ParseProjection builds a Projection from comma-separated --only and
+// --omit lists, rejecting unknown field names
+func ParseProjection(only, omit string) (Projection, error) {
+	var p Projection
+	var err error
+	if p.only, err = parseFieldList("--only", only); err != nil {
+		return p, err
+	}
+	if p.omit, err = parseFieldList("--omit", omit); err != nil {
+		return p, err
+	}
+	if p.only != nil && p.omit != nil {
+		return p, fmt.Errorf("--only and --omit cannot be combined")
+	}
+	return p, nil
+}
+
+func parseFieldList(flag, list string) (map[string]bool, error) {
+	if strings.TrimSpace(list) == "" {
+		return nil, nil
+	}
+	fields := make(map[string]bool)
+	for _, f := range strings.Split(list, ",") {
+		f = strings.ToLower(strings.TrimSpace(f))
+		if f == "" {
+			continue
+		}
+		if !isPageField(f) {
+			known := append([]string(nil), pageFields...)
+			sort.Strings(known)
+			return nil, fmt.Errorf("unknown field %q in %s (fields: %s)", f, flag, strings.Join(known, ", "))
+		}
+		fields[f] = true
+	}
+	return fields, nil
+}
+
+func isPageField(name string) bool {
+	for _, f := range pageFields {
+		if f == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Includes reports whether field is written. html is the exception for JSON
+// exports, which keep it only when it is named in --only (see keepHTML).
+func (p Projection) Includes(field string) bool {
+	if p.only != nil {
+		return p.only[field]
+	}
+	return !p.omit[field]
+}
+
+// keepHTML reports whether JSON exports keep the page HTML, which they drop
+// unless asked for explicitly since it is usually most of the payload
+func (p Projection) keepHTML() bool {
+	return p.only["html"]
+}
+
+// exportObject is a projected PageData that marshals its fields in
+// declaration order, leaving out those the projection excludes
+type exportObject struct {
+	data models.PageData
+	p    Projection
+}
+
+// MarshalJSON implements json.Marshaler
+func (o exportObject) MarshalJSON() ([]byte, error) {
+	v := reflect.ValueOf(o.data)
+	t := v.Type()
+
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := jsonName(field)
+		if !o.p.Includes(name) || (name == "html" && !o.p.keepHTML()) {
+			continue
+		}
+		if strings.Contains(field.Tag.Get("json"), ",omitempty") && isEmpty(v.Field(i)) {
+			continue
+		}
+
+		value, err := json.Marshal(v.Field(i).Interface())
+		if err != nil {
+			return nil, err
+		}
+		if buf.Len() > 1 {
+			buf.WriteByte(',')
+		}
+		key, _ := json.Marshal(name)
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+func jsonName(f reflect.StructField) string {
+	name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+	if name == "" {
+		return strings.ToLower(f.Name)
+	}
+	return name
+}
+
+// isEmpty matches encoding/json's definition of an empty value for omitempty
+func isEmpty(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		return v.Len() == 0
+	case reflect.Ptr, reflect.Interface:
+		return v.IsNil()
+	}
+	return v.IsZero()
+}
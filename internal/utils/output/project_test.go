@@ -0,0 +1,63 @@
+package output
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/law-makers/crawl/pkg/models"
+)
+
+func projectedJSON(t *testing.T, only, omit string, data *models.PageData) string {
+	t.Helper()
+	p, err := ParseProjection(only, omit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	SetProjection(p)
+	defer SetProjection(Projection{})
+	b, err := MarshalJSON(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func TestProjection_Only(t *testing.T) {
+	data := &models.PageData{URL: "https://example.com/", StatusCode: 200, Title: "Hi", HTML: "<p>hi</p>", Headers: map[string]string{"A": "b"}}
+
+	got := projectedJSON(t, "title, url", "", data)
+	if got != `{"url":"https://example.com/","title":"Hi"}` {
+		t.Errorf("unexpected projection: %s", got)
+	}
+
+	got = projectedJSON(t, "url,html", "", data)
+	if got != `{"url":"https://example.com/","html":"\u003cp\u003ehi\u003c/p\u003e"}` {
+		t.Errorf("html should be kept when listed in --only: %s", got)
+	}
+}
+
+func TestProjection_Omit(t *testing.T) {
+	data := &models.PageData{URL: "https://example.com/", StatusCode: 200, HTML: "<p>hi</p>", Headers: map[string]string{"A": "b"}}
+
+	got := projectedJSON(t, "", "headers,status_code,fetched_at", data)
+	if strings.Contains(got, "headers") || strings.Contains(got, "status_code") || strings.Contains(got, "fetched_at") {
+		t.Errorf("omitted fields present: %s", got)
+	}
+	if strings.Contains(got, "html") || !strings.Contains(got, "response_time_ms") {
+		t.Errorf("unexpected default fields: %s", got)
+	}
+
+	// The default matches the historical export: everything except html
+	if got := projectedJSON(t, "", "", data); strings.Contains(got, "html") || !strings.Contains(got, `"headers"`) {
+		t.Errorf("unexpected default export: %s", got)
+	}
+}
+
+func TestParseProjection_Errors(t *testing.T) {
+	if _, err := ParseProjection("url,bogus", ""); err == nil || !strings.Contains(err.Error(), "bogus") {
+		t.Errorf("expected unknown field error, got %v", err)
+	}
+	if _, err := ParseProjection("url", "html"); err == nil {
+		t.Error("expected --only and --omit together to be rejected")
+	}
+}
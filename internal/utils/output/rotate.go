@@ -0,0 +1,348 @@
+package output
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/law-makers/crawl/pkg/models"
+)
+
+// Partitions for SplitOptions.Partition
+const (
+	PartitionDomain = "domain" // One directory per host
+	PartitionDay    = "day"    // One directory per UTC fetch date
+)
+
+// maxOpenParts bounds the files kept open at once when partitioning; older
+// parts are closed and reopened for appending when written to again
+const maxOpenParts = 64
+
+// csvColumns are written for each page when streaming pages to CSV, less
+// any the projection excludes
+var csvColumns = []string{"url", "status_code", "title", "content", "html", "fetched_at", "response_time_ms"}
+
+// SplitOptions controls how RotatingWriter divides its output
+type SplitOptions struct {
+	MaxBytes   int64  // Start a new file before one would exceed this size (0 for no limit)
+	MaxRecords int    // Start a new file once one holds this many pages (0 for no limit)
+	Partition  string // "", PartitionDomain or PartitionDay
+}
+
+// ParseSplit parses an --output-split value: a size such as 100MB, 512KB or
+// 1GB, or a plain number of records per file
+func ParseSplit(s string) (SplitOptions, error) {
+	var opts SplitOptions
+	s = strings.TrimSpace(strings.ToUpper(s))
+	if s == "" {
+		return opts, nil
+	}
+
+	if n, ok, err := parseSize(s); ok {
+		if err != nil {
+			return opts, fmt.Errorf("invalid output split %q (use a size such as 100MB or a record count)", s)
+		}
+		opts.MaxBytes = n
+		return opts, nil
+	}
+
+	n, err := strconv.Atoi(s)
+	if err != nil || n <= 0 {
+		return opts, fmt.Errorf("invalid output split %q (use a size such as 100MB or a record count)", s)
+	}
+	opts.MaxRecords = n
+	return opts, nil
+}
+
+// ParseSize parses a positive size with a unit: 512KB, 100MB, 1.5GB or 10B
+func ParseSize(s string) (int64, error) {
+	n, ok, err := parseSize(strings.TrimSpace(strings.ToUpper(s)))
+	if !ok || err != nil {
+		return 0, fmt.Errorf("invalid size %q (use e.g. 500MB or 2GB)", s)
+	}
+	return n, nil
+}
+
+// parseSize parses an upper-case size; ok is false when s has no unit
+func parseSize(s string) (n int64, ok bool, err error) {
+	units := []struct {
+		suffix string
+		mult   float64
+	}{{"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10}, {"B", 1}}
+	for _, u := range units {
+		if strings.HasSuffix(s, u.suffix) {
+			f, err := strconv.ParseFloat(strings.TrimSpace(strings.TrimSuffix(s, u.suffix)), 64)
+			if err != nil || f <= 0 {
+				return 0, true, fmt.Errorf("invalid size %q", s)
+			}
+			return int64(f * u.mult), true, nil
+		}
+	}
+	return 0, false, nil
+}
+
+// RotatingWriter streams pages to JSON Lines or CSV files (chosen by the
+// extension of the base path), starting a new numbered file when a size or
+// record limit is reached and optionally partitioning files into
+// per-domain or per-day directories. It is not safe for concurrent use.
+type RotatingWriter struct {
+	base  string
+	csv   bool
+	cols  []string
+	opts  SplitOptions
+	parts map[string]*part
+	order []string // every file written, in creation order
+	clock int
+}
+
+type part struct {
+	key     string
+	seq     int
+	path    string
+	file    *os.File
+	bytes   int64
+	records int
+	lastUse int
+}
+
+// NewRotatingWriter creates a writer for files named after base, e.g.
+// pages.jsonl becomes pages-00001.jsonl, pages-00002.jsonl, ... when split.
+func NewRotatingWriter(base string, opts SplitOptions) (*RotatingWriter, error) {
+	if opts.Partition != "" && opts.Partition != PartitionDomain && opts.Partition != PartitionDay {
+		return nil, fmt.Errorf("invalid partition %q (must be domain or day)", opts.Partition)
+	}
+	return &RotatingWriter{
+		base:  base,
+		csv:   strings.EqualFold(filepath.Ext(base), ".csv"),
+		cols:  projectedColumns(csvColumns),
+		opts:  opts,
+		parts: make(map[string]*part),
+	}, nil
+}
+
+// Write appends one page, rotating to a new file first if it would break a limit
+func (w *RotatingWriter) Write(data *models.PageData) error {
+	record, err := w.encode(data)
+	if err != nil {
+		return err
+	}
+
+	key := w.partitionKey(data)
+	p := w.parts[key]
+	if p == nil {
+		p = &part{key: key, seq: 1}
+		w.parts[key] = p
+	} else if w.full(p, len(record)) {
+		if err := p.close(); err != nil {
+			return err
+		}
+		*p = part{key: key, seq: p.seq + 1}
+	}
+
+	if err := w.open(p); err != nil {
+		return err
+	}
+	if _, err := p.file.Write(record); err != nil {
+		return fmt.Errorf("failed to write %s: %w", p.path, err)
+	}
+	p.bytes += int64(len(record))
+	p.records++
+	return nil
+}
+
+// Files returns the paths written so far, in the order they were created
+func (w *RotatingWriter) Files() []string {
+	return append([]string(nil), w.order...)
+}
+
+// Close closes every open file
+func (w *RotatingWriter) Close() error {
+	var first error
+	for _, p := range w.parts {
+		if err := p.close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// full reports whether adding a record of size n to p would break a limit.
+// A file always receives at least one record, however large.
+func (w *RotatingWriter) full(p *part, n int) bool {
+	if p.records == 0 {
+		return false
+	}
+	if w.opts.MaxRecords > 0 && p.records >= w.opts.MaxRecords {
+		return true
+	}
+	return w.opts.MaxBytes > 0 && p.bytes+int64(n) > w.opts.MaxBytes
+}
+
+// open makes sure p's file is open, creating it (with a CSV header) on first
+// use and closing the least recently used part if too many are open
+func (w *RotatingWriter) open(p *part) error {
+	w.clock++
+	p.lastUse = w.clock
+	if p.file != nil {
+		return nil
+	}
+
+	open := 0
+	var oldest *part
+	for _, other := range w.parts {
+		if other.file != nil {
+			open++
+			if oldest == nil || other.lastUse < oldest.lastUse {
+				oldest = other
+			}
+		}
+	}
+	if open >= maxOpenParts && oldest != nil {
+		if err := oldest.close(); err != nil {
+			return err
+		}
+	}
+
+	if p.path != "" {
+		// Reopen a part closed to free a file descriptor
+		f, err := os.OpenFile(p.path, os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return fmt.Errorf("failed to reopen %s: %w", p.path, err)
+		}
+		p.file = f
+		return nil
+	}
+
+	p.path = w.path(p.key, p.seq)
+	if err := os.MkdirAll(filepath.Dir(p.path), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(p.path), err)
+	}
+	f, err := os.Create(p.path)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", p.path, err)
+	}
+	p.file = f
+	w.order = append(w.order, p.path)
+
+	if w.csv {
+		var header []byte
+		if csvDialect.BOM {
+			header = append(header, utf8BOM...)
+		}
+		if !csvDialect.NoHeader {
+			row, _ := csvRecord(w.cols)
+			header = append(header, row...)
+		}
+		if _, err := f.Write(header); err != nil {
+			return fmt.Errorf("failed to write %s: %w", p.path, err)
+		}
+		p.bytes += int64(len(header))
+	}
+	return nil
+}
+
+// path builds the file name for a partition and sequence number
+func (w *RotatingWriter) path(key string, seq int) string {
+	dir, name := filepath.Split(w.base)
+	if key != "" {
+		dir = filepath.Join(dir, key)
+	}
+	if w.opts.MaxBytes > 0 || w.opts.MaxRecords > 0 {
+		ext := filepath.Ext(name)
+		name = fmt.Sprintf("%s-%05d%s", strings.TrimSuffix(name, ext), seq, ext)
+	}
+	return filepath.Join(dir, name)
+}
+
+func (w *RotatingWriter) partitionKey(data *models.PageData) string {
+	switch w.opts.Partition {
+	case PartitionDomain:
+		u, err := url.Parse(data.URL)
+		if err != nil || u.Host == "" {
+			return "unknown"
+		}
+		// Keep the port, but as a character that is valid in every file system
+		return strings.ReplaceAll(strings.ToLower(u.Host), ":", "_")
+	case PartitionDay:
+		at := data.FetchedAt
+		if at.IsZero() {
+			at = time.Now()
+		}
+		return at.UTC().Format("2006-01-02")
+	}
+	return ""
+}
+
+func (w *RotatingWriter) encode(data *models.PageData) ([]byte, error) {
+	if w.csv {
+		return csvRecord(pageRow(data, w.cols))
+	}
+	line, err := MarshalJSON(data)
+	if err != nil {
+		return nil, err
+	}
+	return append(line, '\n'), nil
+}
+
+// projectedColumns filters page columns through the projection; html is
+// only kept when asked for, as in JSON exports
+func projectedColumns(columns []string) []string {
+	var cols []string
+	for _, c := range columns {
+		if projection.Includes(c) && (c != "html" || projection.keepHTML()) {
+			cols = append(cols, c)
+		}
+	}
+	return cols
+}
+
+// pageRow returns the named page columns as CSV fields
+func pageRow(data *models.PageData, columns []string) []string {
+	row := make([]string, len(columns))
+	for i, c := range columns {
+		switch c {
+		case "url":
+			row[i] = data.URL
+		case "status_code":
+			row[i] = strconv.Itoa(data.StatusCode)
+		case "title":
+			row[i] = data.Title
+		case "content":
+			row[i] = data.Content
+		case "html":
+			row[i] = data.HTML
+		case "fetched_at":
+			row[i] = data.FetchedAt.UTC().Format(time.RFC3339)
+		case "response_time_ms":
+			row[i] = strconv.FormatInt(data.ResponseTime, 10)
+		}
+	}
+	return row
+}
+
+func csvRecord(fields []string) ([]byte, error) {
+	var buf bytes.Buffer
+	cw := newCSVWriter(&buf)
+	if err := cw.Write(fields); err != nil {
+		return nil, err
+	}
+	cw.Flush()
+	return buf.Bytes(), cw.Error()
+}
+
+func (p *part) close() error {
+	if p.file == nil {
+		return nil
+	}
+	err := p.file.Close()
+	p.file = nil
+	if err != nil {
+		return fmt.Errorf("failed to close %s: %w", p.path, err)
+	}
+	return nil
+}
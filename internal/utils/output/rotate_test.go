@@ -0,0 +1,119 @@
+package output
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/law-makers/crawl/pkg/models"
+)
+
+func TestParseSplit(t *testing.T) {
+	cases := map[string]SplitOptions{
+		"100MB": {MaxBytes: 100 << 20},
+		"1.5kb": {MaxBytes: 1536},
+		"500":   {MaxRecords: 500},
+		"":      {},
+	}
+	for in, want := range cases {
+		got, err := ParseSplit(in)
+		if err != nil || got != want {
+			t.Errorf("ParseSplit(%q) = %+v, %v; want %+v", in, got, err, want)
+		}
+	}
+	for _, bad := range []string{"MB", "-1", "10 pages", "0"} {
+		if _, err := ParseSplit(bad); err == nil {
+			t.Errorf("ParseSplit(%q) should fail", bad)
+		}
+	}
+}
+
+func TestParseSize(t *testing.T) {
+	if n, err := ParseSize("2GB"); err != nil || n != 2<<30 {
+		t.Errorf("ParseSize(2GB) = %d, %v", n, err)
+	}
+	for _, bad := range []string{"", "500", "GB", "-1MB"} {
+		if _, err := ParseSize(bad); err == nil {
+			t.Errorf("ParseSize(%q) should fail", bad)
+		}
+	}
+}
+
+func TestRotatingWriter_SplitsByRecords(t *testing.T) {
+	dir := t.TempDir()
+	w, err := NewRotatingWriter(filepath.Join(dir, "pages.jsonl"), SplitOptions{MaxRecords: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 5; i++ {
+		if err := w.Write(&models.PageData{URL: "https://example.com/", StatusCode: 200}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	files := w.Files()
+	if len(files) != 3 {
+		t.Fatalf("expected 3 files, got %v", files)
+	}
+	if filepath.Base(files[0]) != "pages-00001.jsonl" || filepath.Base(files[2]) != "pages-00003.jsonl" {
+		t.Errorf("unexpected file names: %v", files)
+	}
+	for i, want := range []int{2, 2, 1} {
+		b, _ := os.ReadFile(files[i])
+		if n := strings.Count(string(b), "\n"); n != want {
+			t.Errorf("%s: expected %d lines, got %d", files[i], want, n)
+		}
+	}
+}
+
+func TestRotatingWriter_SplitsBySizeWithCSVHeader(t *testing.T) {
+	dir := t.TempDir()
+	w, _ := NewRotatingWriter(filepath.Join(dir, "pages.csv"), SplitOptions{MaxBytes: 120})
+	for i := 0; i < 4; i++ {
+		w.Write(&models.PageData{URL: "https://example.com/page", StatusCode: 200, Content: strings.Repeat("x", 30)})
+	}
+	w.Close()
+
+	files := w.Files()
+	if len(files) < 2 {
+		t.Fatalf("expected the output to rotate, got %v", files)
+	}
+	for _, f := range files {
+		b, _ := os.ReadFile(f)
+		if !strings.HasPrefix(string(b), "url,status_code,") {
+			t.Errorf("%s: missing CSV header: %q", f, b)
+		}
+	}
+}
+
+func TestRotatingWriter_Partitions(t *testing.T) {
+	dir := t.TempDir()
+	w, _ := NewRotatingWriter(filepath.Join(dir, "pages.jsonl"), SplitOptions{Partition: PartitionDomain})
+	w.Write(&models.PageData{URL: "https://a.example/1"})
+	w.Write(&models.PageData{URL: "http://localhost:8080/"})
+	w.Write(&models.PageData{URL: "https://a.example/2"})
+	w.Close()
+
+	for _, f := range []string{"a.example/pages.jsonl", "localhost_8080/pages.jsonl"} {
+		if _, err := os.Stat(filepath.Join(dir, f)); err != nil {
+			t.Errorf("expected %s: %v", f, err)
+		}
+	}
+
+	day := t.TempDir()
+	w, _ = NewRotatingWriter(filepath.Join(day, "pages.jsonl"), SplitOptions{Partition: PartitionDay, MaxRecords: 10})
+	w.Write(&models.PageData{URL: "https://a.example/", FetchedAt: time.Date(2024, 3, 9, 23, 0, 0, 0, time.UTC)})
+	w.Close()
+	if _, err := os.Stat(filepath.Join(day, "2024-03-09", "pages-00001.jsonl")); err != nil {
+		t.Errorf("expected day partition: %v", err)
+	}
+
+	if _, err := NewRotatingWriter("pages.jsonl", SplitOptions{Partition: "week"}); err == nil {
+		t.Error("expected an unknown partition to be rejected")
+	}
+}
@@ -0,0 +1,254 @@
+package output
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	urlutil "github.com/law-makers/crawl/internal/utils/url"
+	"golang.org/x/net/html"
+)
+
+// TextOptions controls how RenderText turns HTML into plain text
+type TextOptions struct {
+	CollapseWhitespace bool   // Turn runs of spaces, tabs and newlines in text into one space (except in <pre>)
+	Paragraphs         bool   // Separate paragraphs, headings, lists and tables with a blank line
+	LinkURLs           bool   // Follow link text with its URL: "docs (https://...)"
+	Bullet             string // Prefix for list items, e.g. "- " or "• "; ordered lists are numbered. Empty leaves items unmarked.
+	TableSeparator     string // Put each table row on one line, cells joined by this (default " | ")
+	BaseURL            string // Resolves relative link URLs
+}
+
+// blockElements start on a new line
+var blockElements = map[string]bool{
+	"address": true, "article": true, "aside": true, "dd": true, "div": true, "dl": true, "dt": true,
+	"figcaption": true, "figure": true, "footer": true, "form": true, "header": true, "hr": true,
+	"li": true, "main": true, "nav": true, "section": true, "tr": true,
+}
+
+// paragraphElements are set off by a blank line when Paragraphs is set
+var paragraphElements = map[string]bool{
+	"blockquote": true, "h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	"ol": true, "p": true, "pre": true, "table": true, "ul": true,
+}
+
+// skippedElements have no readable text
+var skippedElements = map[string]bool{
+	"head": true, "noscript": true, "script": true, "style": true, "svg": true, "template": true,
+}
+
+var (
+	spaceRun     = regexp.MustCompile(`[ \t\r\n\f]+`)
+	blankLineRun = regexp.MustCompile(`\n{3,}`)
+)
+
+// textRenderer accumulates text, tracking line ends so breaks don't stack
+type textRenderer struct {
+	opts     TextOptions
+	b        strings.Builder
+	pre      int  // depth of <pre> elements
+	started  bool // some non-space text has been written
+	newlines int  // newlines at the end of the output
+}
+
+// RenderText converts an HTML fragment or document into readable plain text
+func RenderText(htmlContent string, opts TextOptions) (string, error) {
+	doc, err := html.Parse(strings.NewReader(htmlContent))
+	if err != nil {
+		return "", err
+	}
+	if opts.TableSeparator == "" {
+		opts.TableSeparator = " | "
+	}
+
+	r := &textRenderer{opts: opts}
+	r.node(doc)
+
+	lines := strings.Split(r.b.String(), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t")
+	}
+	text := blankLineRun.ReplaceAllString(strings.Join(lines, "\n"), "\n\n")
+	return strings.TrimSpace(text) + "\n", nil
+}
+
+func (r *textRenderer) node(n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		r.text(n.Data)
+		return
+	case html.ElementNode:
+	default:
+		r.children(n)
+		return
+	}
+
+	if skippedElements[n.Data] {
+		return
+	}
+	switch n.Data {
+	case "br":
+		r.write("\n")
+		return
+	case "ul", "ol":
+		r.block(n.Data)
+		r.list(n)
+		r.block(n.Data)
+		return
+	case "table":
+		r.block(n.Data)
+		r.table(n)
+		r.block(n.Data)
+		return
+	case "a":
+		r.children(n)
+		r.linkURL(n)
+		return
+	case "pre":
+		r.block(n.Data)
+		r.pre++
+		r.children(n)
+		r.pre--
+		r.block(n.Data)
+		return
+	}
+
+	r.block(n.Data)
+	r.children(n)
+	r.block(n.Data)
+}
+
+func (r *textRenderer) children(n *html.Node) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		r.node(c)
+	}
+}
+
+func (r *textRenderer) text(s string) {
+	if r.pre > 0 || !r.opts.CollapseWhitespace {
+		r.write(s)
+		return
+	}
+	s = spaceRun.ReplaceAllString(s, " ")
+	if r.newlines > 0 || !r.started {
+		s = strings.TrimLeft(s, " ")
+	}
+	r.write(s)
+}
+
+// write appends s and updates the line-end tracking
+func (r *textRenderer) write(s string) {
+	if s == "" {
+		return
+	}
+	r.b.WriteString(s)
+	if strings.TrimSpace(s) != "" {
+		r.started = true
+	}
+	if trimmed := strings.TrimRight(s, "\n"); trimmed == "" {
+		r.newlines += len(s)
+	} else {
+		r.newlines = len(s) - len(trimmed)
+	}
+}
+
+// block ends the current line before and after block elements, with a blank
+// line around paragraph elements when Paragraphs is set
+func (r *textRenderer) block(tag string) {
+	switch {
+	case paragraphElements[tag] && r.opts.Paragraphs:
+		r.breaks(2)
+	case paragraphElements[tag] || blockElements[tag]:
+		r.breaks(1)
+	}
+}
+
+// breaks makes sure the output ends in at least n newlines (none at the start)
+func (r *textRenderer) breaks(n int) {
+	if !r.started {
+		return
+	}
+	for r.newlines < n {
+		r.write("\n")
+	}
+}
+
+func (r *textRenderer) list(n *html.Node) {
+	num := 0
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type != html.ElementNode || c.Data != "li" {
+			r.node(c)
+			continue
+		}
+		num++
+		r.breaks(1)
+		if r.opts.Bullet != "" {
+			if n.Data == "ol" {
+				r.write(strconv.Itoa(num) + ". ")
+			} else {
+				r.write(r.opts.Bullet)
+			}
+		}
+		r.children(c)
+		r.breaks(1)
+	}
+}
+
+// table writes one line per row with cells joined by TableSeparator
+func (r *textRenderer) table(n *html.Node) {
+	var rows []*html.Node
+	var collect func(*html.Node)
+	collect = func(n *html.Node) {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type != html.ElementNode {
+				continue
+			}
+			switch c.Data {
+			case "tr":
+				rows = append(rows, c)
+			case "thead", "tbody", "tfoot":
+				collect(c)
+			case "caption":
+				r.children(c)
+				r.breaks(1)
+			}
+		}
+	}
+	collect(n)
+
+	for _, row := range rows {
+		var cells []string
+		for c := row.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type != html.ElementNode || (c.Data != "td" && c.Data != "th") {
+				continue
+			}
+			cell := &textRenderer{opts: r.opts}
+			cell.opts.CollapseWhitespace = true
+			cell.children(c)
+			cells = append(cells, strings.TrimSpace(spaceRun.ReplaceAllString(cell.b.String(), " ")))
+		}
+		r.breaks(1)
+		r.write(strings.Join(cells, r.opts.TableSeparator))
+		r.breaks(1)
+	}
+}
+
+func (r *textRenderer) linkURL(n *html.Node) {
+	if !r.opts.LinkURLs {
+		return
+	}
+	for _, a := range n.Attr {
+		if a.Key != "href" {
+			continue
+		}
+		href := strings.TrimSpace(a.Val)
+		if href == "" || strings.HasPrefix(href, "#") || strings.HasPrefix(strings.ToLower(href), "javascript:") {
+			return
+		}
+		if r.opts.BaseURL != "" {
+			href = urlutil.ResolveURL(r.opts.BaseURL, href)
+		}
+		r.write(" (" + href + ")")
+		return
+	}
+}
@@ -0,0 +1,36 @@
+package output
+
+import "testing"
+
+func TestRenderText(t *testing.T) {
+	in := `<html><head><title>x</title><style>p{}</style></head><body>
+<h1>Title</h1>
+<p>Some    text
+   wrapped <a href="/docs">docs</a> and <a href="#top">top</a>.</p>
+<ul><li>one</li><li>two</li></ul>
+<ol><li>first</li><li>second</li></ol>
+<table><tr><th>Name</th><th>Price</th></tr><tr><td>Tea</td><td> $3 </td></tr></table>
+<pre>  keep
+    this</pre>
+</body></html>`
+
+	got, err := RenderText(in, TextOptions{
+		CollapseWhitespace: true,
+		Paragraphs:         true,
+		LinkURLs:           true,
+		Bullet:             "- ",
+		BaseURL:            "https://example.com/a/",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "Title\n\nSome text wrapped docs (https://example.com/docs) and top.\n\n- one\n- two\n\n1. first\n2. second\n\nName | Price\nTea | $3\n\n  keep\n    this\n"
+	if got != want {
+		t.Errorf("unexpected text:\n%q\nwant:\n%q", got, want)
+	}
+
+	got, _ = RenderText(`<p>a</p><p>b</p><table><tr><td>x</td><td>y</td></tr></table>`, TextOptions{CollapseWhitespace: true, TableSeparator: "\t"})
+	if want := "a\nb\nx\ty\n"; got != want {
+		t.Errorf("unexpected compact text: %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,283 @@
+package output
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/law-makers/crawl/pkg/models"
+)
+
+// maxCellChars is Excel's limit on the text in one cell
+const maxCellChars = 32767
+
+// Cell styles defined in xlsxStyles
+const (
+	styleDefault = 0
+	styleHeader  = 1
+	styleDate    = 2
+)
+
+// sheet is a worksheet of rows whose first row is the header
+type sheet struct {
+	name string
+	rows [][]any
+}
+
+// SaveXLSX writes pages to an Excel workbook: a Summary sheet with one row
+// per page, then Data (structured rows, in the given column order), Links,
+// Images and Metadata sheets for whichever of those the pages have.
+func SaveXLSX(pages []*models.PageData, filepath string, columns []string) error {
+	f, err := os.Create(filepath)
+	if err != nil {
+		return err
+	}
+	if err := WriteXLSX(f, pages, columns); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// WriteXLSX writes the workbook described by SaveXLSX to w
+func WriteXLSX(w io.Writer, pages []*models.PageData, columns []string) error {
+	exports := make([]models.PageData, len(pages))
+	for i, p := range pages {
+		exports[i] = ExportData(p)
+	}
+
+	sheets := []sheet{summarySheet(exports)}
+	for _, s := range []sheet{dataSheet(exports, columns), linkSheet("Links", exports, linksOf), linkSheet("Images", exports, imagesOf), metadataSheet(exports)} {
+		if len(s.rows) > 1 {
+			sheets = append(sheets, s)
+		}
+	}
+
+	zw := zip.NewWriter(w)
+	files := []struct {
+		name string
+		body string
+	}{
+		{"[Content_Types].xml", xlsxContentTypes(len(sheets))},
+		{"_rels/.rels", xlsxRootRels},
+		{"xl/workbook.xml", xlsxWorkbook(sheets)},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels(len(sheets))},
+		{"xl/styles.xml", xlsxStyles},
+	}
+	for i, s := range sheets {
+		files = append(files, struct {
+			name string
+			body string
+		}{fmt.Sprintf("xl/worksheets/sheet%d.xml", i+1), xlsxSheet(s)})
+	}
+
+	for _, file := range files {
+		fw, err := zw.Create(file.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(fw, file.body); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+func summarySheet(pages []models.PageData) sheet {
+	s := sheet{name: "Summary", rows: [][]any{{"URL", "Status", "Title", "Fetched At", "Response Time (ms)", "Rows", "Links", "Images", "Scripts"}}}
+	for _, p := range pages {
+		var fetched any = ""
+		if !p.FetchedAt.IsZero() {
+			fetched = p.FetchedAt
+		}
+		s.rows = append(s.rows, []any{p.URL, p.StatusCode, p.Title, fetched, p.ResponseTime, len(p.Structured), len(p.Links), len(p.Images), len(p.Scripts)})
+	}
+	return s
+}
+
+func dataSheet(pages []models.PageData, columns []string) sheet {
+	s := sheet{name: "Data"}
+	for _, p := range pages {
+		if len(p.Structured) == 0 {
+			continue
+		}
+		if s.rows == nil {
+			header := []any{"Page"}
+			columns = structuredColumns(p.Structured, columns)
+			for _, c := range columns {
+				header = append(header, c)
+			}
+			s.rows = append(s.rows, header)
+		}
+		for _, item := range p.Structured {
+			row := []any{p.URL}
+			for _, c := range columns {
+				row = append(row, typedValue(item[c]))
+			}
+			s.rows = append(s.rows, row)
+		}
+	}
+	return s
+}
+
+func linksOf(p models.PageData) []string  { return p.Links }
+func imagesOf(p models.PageData) []string { return p.Images }
+
+func linkSheet(name string, pages []models.PageData, list func(models.PageData) []string) sheet {
+	s := sheet{name: name, rows: [][]any{{"Page", "URL"}}}
+	for _, p := range pages {
+		for _, u := range list(p) {
+			s.rows = append(s.rows, []any{p.URL, u})
+		}
+	}
+	return s
+}
+
+func metadataSheet(pages []models.PageData) sheet {
+	s := sheet{name: "Metadata", rows: [][]any{{"Page", "Name", "Value"}}}
+	for _, p := range pages {
+		names := make([]string, 0, len(p.Metadata))
+		for k := range p.Metadata {
+			names = append(names, k)
+		}
+		sort.Strings(names)
+		for _, k := range names {
+			s.rows = append(s.rows, []any{p.URL, k, p.Metadata[k]})
+		}
+	}
+	return s
+}
+
+// typedValue turns extracted text that is plainly a number into one, so
+// Excel can sum and sort it. Values with leading zeros (IDs, postcodes) stay text.
+func typedValue(s string) any {
+	t := strings.TrimSpace(s)
+	if t == "" || (len(t) > 1 && t[0] == '0' && t[1] != '.') {
+		return s
+	}
+	if f, err := strconv.ParseFloat(t, 64); err == nil {
+		return f
+	}
+	return s
+}
+
+func xlsxSheet(s sheet) string {
+	var b strings.Builder
+	b.WriteString(xml.Header)
+	b.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`)
+	// Keep the header row visible while scrolling
+	b.WriteString(`<sheetViews><sheetView workbookViewId="0"><pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/></sheetView></sheetViews>`)
+	b.WriteString(`<sheetData>`)
+	for r, row := range s.rows {
+		fmt.Fprintf(&b, `<row r="%d">`, r+1)
+		for c, v := range row {
+			ref := columnName(c) + strconv.Itoa(r+1)
+			style := styleDefault
+			if r == 0 {
+				style = styleHeader
+			}
+			writeCell(&b, ref, v, style)
+		}
+		b.WriteString(`</row>`)
+	}
+	b.WriteString(`</sheetData></worksheet>`)
+	return b.String()
+}
+
+func writeCell(b *strings.Builder, ref string, v any, style int) {
+	switch v := v.(type) {
+	case int:
+		fmt.Fprintf(b, `<c r="%s" s="%d"><v>%d</v></c>`, ref, style, v)
+	case int64:
+		fmt.Fprintf(b, `<c r="%s" s="%d"><v>%d</v></c>`, ref, style, v)
+	case float64:
+		fmt.Fprintf(b, `<c r="%s" s="%d"><v>%s</v></c>`, ref, style, strconv.FormatFloat(v, 'f', -1, 64))
+	case time.Time:
+		fmt.Fprintf(b, `<c r="%s" s="%d"><v>%s</v></c>`, ref, styleDate, strconv.FormatFloat(excelDate(v), 'f', -1, 64))
+	default:
+		text := fmt.Sprint(v)
+		if len(text) > maxCellChars {
+			text = text[:maxCellChars]
+		}
+		var esc bytes.Buffer
+		xml.EscapeText(&esc, []byte(text))
+		fmt.Fprintf(b, `<c r="%s" s="%d" t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`, ref, style, esc.String())
+	}
+}
+
+// excelDate converts t to an Excel serial date (days since 1899-12-30, UTC)
+func excelDate(t time.Time) float64 {
+	epoch := time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+	return t.UTC().Sub(epoch).Hours() / 24
+}
+
+// columnName returns the letters for a zero-based column index: A, B, ..., Z, AA, ...
+func columnName(i int) string {
+	name := ""
+	for i++; i > 0; i = (i - 1) / 26 {
+		name = string(rune('A'+(i-1)%26)) + name
+	}
+	return name
+}
+
+func xlsxContentTypes(sheets int) string {
+	var b strings.Builder
+	b.WriteString(xml.Header)
+	b.WriteString(`<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">`)
+	b.WriteString(`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>`)
+	b.WriteString(`<Default Extension="xml" ContentType="application/xml"/>`)
+	b.WriteString(`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>`)
+	b.WriteString(`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>`)
+	for i := 1; i <= sheets; i++ {
+		fmt.Fprintf(&b, `<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, i)
+	}
+	b.WriteString(`</Types>`)
+	return b.String()
+}
+
+const xlsxRootRels = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+	`</Relationships>`
+
+func xlsxWorkbook(sheets []sheet) string {
+	var b strings.Builder
+	b.WriteString(xml.Header)
+	b.WriteString(`<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets>`)
+	for i, s := range sheets {
+		fmt.Fprintf(&b, `<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, s.name, i+1, i+1)
+	}
+	b.WriteString(`</sheets></workbook>`)
+	return b.String()
+}
+
+func xlsxWorkbookRels(sheets int) string {
+	var b strings.Builder
+	b.WriteString(xml.Header)
+	b.WriteString(`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`)
+	for i := 1; i <= sheets; i++ {
+		fmt.Fprintf(&b, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`, i, i)
+	}
+	fmt.Fprintf(&b, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>`, sheets+1)
+	b.WriteString(`</Relationships>`)
+	return b.String()
+}
+
+// xlsxStyles defines styleDefault, styleHeader (bold) and styleDate
+// (built-in format 22, "m/d/yy h:mm")
+const xlsxStyles = xml.Header + `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+	`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+	`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+	`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+	`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+	`<cellXfs count="3">` +
+	`<xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+	`<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/>` +
+	`<xf numFmtId="22" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`</cellXfs></styleSheet>`
@@ -0,0 +1,80 @@
+package output
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/law-makers/crawl/pkg/models"
+)
+
+func readZip(t *testing.T, b []byte) map[string]string {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := make(map[string]string)
+	for _, f := range zr.File {
+		rc, _ := f.Open()
+		body, _ := io.ReadAll(rc)
+		rc.Close()
+		files[f.Name] = string(body)
+	}
+	return files
+}
+
+func TestWriteXLSX_Sheets(t *testing.T) {
+	pages := []*models.PageData{{
+		URL:        "https://example.com/",
+		StatusCode: 200,
+		Title:      "Shop & Co",
+		FetchedAt:  time.Date(2024, 1, 2, 12, 0, 0, 0, time.UTC),
+		Structured: []map[string]string{{"name": "Tea", "price": "3.5", "sku": "007"}},
+		Links:      []string{"/about"},
+	}}
+
+	var buf bytes.Buffer
+	if err := WriteXLSX(&buf, pages, []string{"name", "price", "sku"}); err != nil {
+		t.Fatal(err)
+	}
+	files := readZip(t, buf.Bytes())
+
+	workbook := files["xl/workbook.xml"]
+	for _, name := range []string{`name="Summary"`, `name="Data"`, `name="Links"`} {
+		if !strings.Contains(workbook, name) {
+			t.Errorf("workbook missing sheet %s: %s", name, workbook)
+		}
+	}
+	if strings.Contains(workbook, `name="Images"`) {
+		t.Error("empty Images sheet should be left out")
+	}
+
+	summary := files["xl/worksheets/sheet1.xml"]
+	if !strings.Contains(summary, "Shop &amp; Co") || !strings.Contains(summary, `s="2"><v>45293.5</v>`) {
+		t.Errorf("unexpected summary sheet: %s", summary)
+	}
+
+	data := files["xl/worksheets/sheet2.xml"]
+	if !strings.Contains(data, `<c r="C2" s="0"><v>3.5</v></c>`) {
+		t.Errorf("price should be a number cell: %s", data)
+	}
+	if !strings.Contains(data, `<c r="D2" s="0" t="inlineStr"><is><t xml:space="preserve">007</t>`) {
+		t.Errorf("sku with a leading zero should stay text: %s", data)
+	}
+
+	if links := files["xl/worksheets/sheet3.xml"]; !strings.Contains(links, "https://example.com/about") {
+		t.Errorf("links should be resolved: %s", links)
+	}
+}
+
+func TestColumnName(t *testing.T) {
+	for i, want := range map[int]string{0: "A", 25: "Z", 26: "AA", 701: "ZZ", 702: "AAA"} {
+		if got := columnName(i); got != want {
+			t.Errorf("columnName(%d) = %s, want %s", i, got, want)
+		}
+	}
+}
@@ -1,11 +1,18 @@
 package models
 
-import "time"
+import (
+	"fmt"
+	"sync"
+	"time"
+)
 
 // SelectionData represents a single item extracted from a list
 type SelectionData struct {
-	Text string `json:"text"`
-	HTML string `json:"html"`
+	Index    int    `json:"index"`    // Position among the selector's matches, from 0
+	Selector string `json:"selector"` // CSS path that selects just this element
+	Text     string `json:"text"`
+	HTML     string `json:"html,omitempty"`  // Outer HTML (empty with NoHTML)
+	Frame    string `json:"frame,omitempty"` // URL of the iframe it is in, if not the page itself
 }
 
 // PageData represents the scraped data from a web page.
@@ -27,10 +34,204 @@ type PageData struct {
 	Scripts      []string            `json:"scripts,omitempty"`    // All script URLs found on the page
 	FetchedAt    time.Time           `json:"fetched_at"`           // Timestamp when the page was fetched
 	ResponseTime int64               `json:"response_time_ms"`     // Time taken to fetch and parse (milliseconds)
+	Timings      *Timings            `json:"timings,omitempty"`    // ResponseTime broken down by phase
+	Assertions   []AssertionResult   `json:"assertions,omitempty"` // Outcome of RequestOptions.Assertions
+
+	SuspectedError string `json:"suspected_error,omitempty"` // Why a 2xx page looks like an error or block page
+	Escalation     string `json:"escalation,omitempty"`      // Escalation rung that got past a block, e.g. "spa"
+
+	// Print or reader version the page links to (rel=alternate media=print,
+	// ?print=1 and the like), and the URL the content was read from when it
+	// isn't URL, e.g. that version with --prefer-print
+	PrintURL    string `json:"print_url,omitempty"`
+	FetchedFrom string `json:"fetched_from,omitempty"`
+
+	// Hashes for spotting changed pages without keeping their bodies:
+	// SHA-256 of Content with whitespace collapsed, and of HTML when retained
+	ContentHash string `json:"content_hash,omitempty"`
+	HTMLHash    string `json:"html_hash,omitempty"`
+
+	// Text and data mining reservations the page carries, e.g.
+	// "X-Robots-Tag: noai" or "robots.txt: GPTBot disallowed"
+	NoAI []string `json:"noai,omitempty"`
+
+	Cookies []Cookie `json:"cookies,omitempty"` // Cookies set while loading the page (RequestOptions.CaptureCookies)
+
+	// WebSocket frames sent and received while the page loaded, in SPA
+	// mode with RequestOptions.CaptureWS
+	WebSocket []WSMessage `json:"websocket,omitempty"`
+
+	// Console messages and uncaught exceptions while the page rendered, in
+	// SPA mode
+	ConsoleLogs []ConsoleMessage `json:"console_logs,omitempty"`
+
+	// The page's iframes and what was extracted from each, with
+	// RequestOptions.Frames. Their content, rows and matches are merged
+	// into the page's, tagged with the frame's URL.
+	Frames []Frame `json:"frames,omitempty"`
+
+	JobID     string `json:"job_id,omitempty"`     // Run that fetched the page (CRAWL_JOB_ID, else random)
+	RequestID string `json:"request_id,omitempty"` // Request that fetched the page, as in the logs
+}
+
+// Cookie is a cookie set by a response while loading a page
+type Cookie struct {
+	Name       string     `json:"name"`
+	Value      string     `json:"value"`
+	Domain     string     `json:"domain"` // The setting host when the cookie names none
+	Path       string     `json:"path,omitempty"`
+	Expires    *time.Time `json:"expires,omitempty"` // Unset for session cookies
+	Secure     bool       `json:"secure"`
+	HTTPOnly   bool       `json:"http_only"`
+	SameSite   string     `json:"same_site,omitempty"` // Strict, Lax or None, as sent
+	Size       int        `json:"size"`                // Name plus value, as browsers count it
+	ThirdParty bool       `json:"third_party"`         // Domain is not the page's site
+	SetBy      string     `json:"set_by"`              // URL of the response that set it
+}
+
+// ConsoleMessage is a browser console message or uncaught JavaScript
+// exception seen while rendering a page
+type ConsoleMessage struct {
+	Level     string    `json:"level"` // log, info, warning, error or debug
+	Text      string    `json:"text"`
+	URL       string    `json:"url,omitempty"` // Script the message came from, when known
+	Line      int       `json:"line,omitempty"`
+	Time      time.Time `json:"time"`
+	Exception bool      `json:"exception,omitempty"` // An uncaught exception rather than a console call
+}
+
+// Frame is an iframe of a page
+type Frame struct {
+	URL         string `json:"url"`               // about:srcdoc for inline frames
+	Name        string `json:"name,omitempty"`    // The iframe's name or id
+	Parent      string `json:"parent"`            // URL of the document embedding it
+	Depth       int    `json:"depth"`             // 1 for the page's own iframes, 2 for iframes inside those...
+	CrossOrigin bool   `json:"cross_origin"`      // Not the page's origin
+	Title       string `json:"title,omitempty"`   // The frame document's <title>
+	Content     string `json:"content,omitempty"` // Text the selector matched in the frame
+	HTML        string `json:"html,omitempty"`    // HTML the selector matched (empty with NoHTML)
+	Error       string `json:"error,omitempty"`   // Why the frame wasn't read
+}
+
+// FrameOptions asks for a page's iframes to be read along with it
+type FrameOptions struct {
+	CrossOrigin bool // Also read iframes from other origins
+	Max         int  // Most iframes to read per page (0 for no limit)
+}
+
+// WSMessage is one WebSocket frame captured while loading a page
+type WSMessage struct {
+	URL       string    `json:"url"`       // The socket's URL
+	Direction string    `json:"direction"` // "sent" or "received"
+	Time      time.Time `json:"time"`
+	Binary    bool      `json:"binary,omitempty"` // Data is base64-encoded
+	Data      string    `json:"data"`
+	Size      int       `json:"size"`                // Payload bytes before truncation
+	Truncated bool      `json:"truncated,omitempty"` // Data was cut to WSCapture.MaxMessageSize
+}
+
+// WSCapture limits the WebSocket frames recorded in SPA mode
+type WSCapture struct {
+	MaxMessages    int           // Frames to keep; later ones are dropped (0 for no limit)
+	MaxMessageSize int           // Bytes of each payload to keep (0 for no limit)
+	Listen         time.Duration // How long to keep listening once the page is ready
+}
+
+// Form is an HTML form found on a page
+type Form struct {
+	Index   int         `json:"index"` // Position among the page's forms, from 0
+	Name    string      `json:"name,omitempty"`
+	ID      string      `json:"id,omitempty"`
+	Action  string      `json:"action"` // Absolute URL the form submits to
+	Method  string      `json:"method"` // GET or POST
+	Enctype string      `json:"enctype,omitempty"`
+	Fields  []FormField `json:"fields"`
+	CSRF    []string    `json:"csrf,omitempty"` // Hidden fields that look like anti-CSRF tokens
+}
+
+// FormField is one named control of a form. Radio buttons sharing a name
+// are one field, with their values as Options.
+type FormField struct {
+	Name     string   `json:"name"`
+	Type     string   `json:"type"`            // input type, or select, textarea or button
+	Value    string   `json:"value,omitempty"` // What the form submits as is
+	Required bool     `json:"required,omitempty"`
+	Options  []string `json:"options,omitempty"` // Values a select or radio group can take
+}
+
+// FormSubmission fills and submits a form after the page loads, in SPA
+// mode. The static engine posts the encoded form instead (Method, Body).
+type FormSubmission struct {
+	Selector string            // CSS selector for the form; empty to use Index
+	Index    int               // Position among the page's forms
+	Values   map[string]string // Field name -> value to type or select
+}
+
+// Assertion checks how many elements on the page match a selector, that an
+// expression over the page holds, or that a response header has a value,
+// for using crawl as a content monitor
+type Assertion struct {
+	Selector string `json:"selector,omitempty"`
+	Min      int    `json:"min"`              // Fewest matches allowed
+	Max      int    `json:"max"`              // Most matches allowed, or -1 for no limit
+	Expr     string `json:"expr,omitempty"`   // CEL expression that must be true, instead of a selector
+	Header   string `json:"header,omitempty"` // Response header that must be present, instead of a selector
+	Value    string `json:"value,omitempty"`  // Text the Header's value must contain (case-insensitive)
+}
+
+// AssertionResult is an Assertion evaluated against a fetched page
+type AssertionResult struct {
+	Assertion
+	Count  int    `json:"count"`
+	Passed bool   `json:"passed"`
+	Error  string `json:"error,omitempty"` // Why an expression couldn't be evaluated
+	Got    string `json:"got,omitempty"`   // Header's value, for header assertions
+}
+
+// String describes the result, e.g. `".captcha" found 1 time(s), expected none`
+// or `"status == 200" is false`
+func (r AssertionResult) String() string {
+	if r.Expr != "" {
+		if r.Error != "" {
+			return fmt.Sprintf("%q could not be evaluated: %s", r.Expr, r.Error)
+		}
+		return fmt.Sprintf("%q is false", r.Expr)
+	}
+	if r.Header != "" {
+		if r.Count == 0 {
+			return fmt.Sprintf("header %s missing", r.Header)
+		}
+		return fmt.Sprintf("header %s is %q, expected it to contain %q", r.Header, r.Got, r.Value)
+	}
+	var want string
+	switch {
+	case r.Max == 0:
+		want = "none"
+	case r.Max < 0 && r.Min <= 1:
+		want = "at least one"
+	case r.Max < 0:
+		want = fmt.Sprintf("at least %d", r.Min)
+	default:
+		want = fmt.Sprintf("%d to %d", r.Min, r.Max)
+	}
+	return fmt.Sprintf("%q found %d time(s), expected %s", r.Selector, r.Count, want)
+}
+
+// Timings breaks a fetch down by phase, in milliseconds. Phases that did not
+// happen, such as TLS over plain HTTP or DNS on a reused connection, are zero.
+type Timings struct {
+	DNS      float64 `json:"dns_ms"`
+	Connect  float64 `json:"connect_ms"`
+	TLS      float64 `json:"tls_ms"`
+	TTFB     float64 `json:"ttfb_ms"`     // Request sent until the first response byte
+	Download float64 `json:"download_ms"` // Reading (and for static mode, parsing) the body
+	Render   float64 `json:"render_ms"`   // Script execution (auto mode) or rendering until the selector is ready (SPA mode)
+	Waited   float64 `json:"waited_ms"`   // Held back by crawl itself: rate limits, per-domain slots and, in SPA mode, a free browser tab
 }
 
 // ScrapeResult represents the result of a scraping operation
 type ScrapeResult struct {
+	URL   string // The requested URL, set even when Data is nil
 	Data  *PageData
 	Error error
 }
@@ -44,14 +245,130 @@ const (
 	ModeSPA    ScraperMode = "spa"
 )
 
+// Field maps a column of structured output to a CSS selector, evaluated
+// within each element matched by the request selector. A selector ending in
+// @attr takes that attribute instead of the text (e.g. "a@href").
+type Field struct {
+	Name     string
+	Selector string
+}
+
+// FieldNames returns the names of fields in declaration order
+func FieldNames(fields []Field) []string {
+	names := make([]string, len(fields))
+	for i, f := range fields {
+		names[i] = f.Name
+	}
+	return names
+}
+
 // RequestOptions contains options for making scraping requests
 type RequestOptions struct {
 	URL         string
 	Mode        ScraperMode
 	Selector    string
-	Fields      map[string]string
+	Fields      []Field // Structured fields to extract from each selected element, in column order
 	Headers     map[string]string
 	Timeout     time.Duration
 	Proxy       string
-	WaitSeconds int // Number of seconds to wait after browser opens before scraping
+	WaitSeconds int         // Number of seconds to wait after browser opens before scraping
+	NoHTML      bool        // Skip retaining raw HTML in PageData (lower memory for large pages)
+	AllMatches  bool        // Fill PageData.Data with one item per element the selector matches
+	Assertions  []Assertion // Checks to run on the page; results go to PageData.Assertions
+	Stealth     bool        // SPA mode: hide the usual signs of an automated browser
+
+	// Selectors to try in order instead of Selector; the first one found on
+	// the page is used, and Selector when none is
+	SelectorCandidates []string
+
+	// Attempt numbers the tries of a retried request (2 is the first
+	// retry) and is 0 on the first try. It only labels log events.
+	Attempt int
+
+	// JobID and RequestID label the request in logs, the request log and
+	// PageData. They are filled in by reqctx.Stamp.
+	JobID     string
+	RequestID string
+
+	// Per-phase budgets for SPA mode; zero uses the engine defaults.
+	// Each phase has its own deadline, so a slow browser start doesn't
+	// shorten the time left for the page to load and render.
+	ConnectTimeout    time.Duration // Starting the browser and opening a tab
+	NavigationTimeout time.Duration // Loading the page (defaults to Timeout when set)
+	WaitTimeout       time.Duration // Waiting for the selector and WaitSeconds
+	ExtractTimeout    time.Duration // Reading title, HTML and content from the page
+
+	// Trace, when set, records the timing of each stage of the fetch
+	Trace *Trace
+
+	// CaptureCookies records the cookies every response set, redirects and
+	// (in SPA mode) subresources included, in PageData.Cookies
+	CaptureCookies bool
+
+	// Method and Body make the static engine send something other than a
+	// bare GET, e.g. a form POST; Content-Type goes in Headers
+	Method string
+	Body   []byte
+
+	// Submit, in SPA mode, fills and submits a form on the loaded page and
+	// returns the page it leads to
+	Submit *FormSubmission
+
+	// CaptureWS, in SPA mode, records WebSocket frames in PageData.WebSocket
+	CaptureWS *WSCapture
+
+	// Frames, when set, extracts from the page's iframes too (PageData.Frames)
+	Frames *FrameOptions
+
+	// DumpRaw has the static engine save this fetch's undecoded response
+	// to the --raw-output file. Fetches derived from the page (iframes,
+	// print versions, prefetched links) leave it unset.
+	DumpRaw bool
+}
+
+// TraceSpan is one stage of a traced fetch. Offsets are relative to the
+// start of the trace; point events have End equal to Start.
+type TraceSpan struct {
+	Name   string
+	Start  time.Duration
+	End    time.Duration
+	Detail string
+}
+
+// Trace collects spans from the engines during a fetch. It is safe for
+// concurrent use, and a nil Trace ignores everything recorded on it.
+type Trace struct {
+	mu    sync.Mutex
+	start time.Time
+	spans []TraceSpan
+}
+
+// NewTrace starts a trace at the current time
+func NewTrace() *Trace {
+	return &Trace{start: time.Now()}
+}
+
+// Span records a stage that ran from start to end
+func (t *Trace) Span(name string, start, end time.Time, detail string) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.spans = append(t.spans, TraceSpan{Name: name, Start: start.Sub(t.start), End: end.Sub(t.start), Detail: detail})
+}
+
+// Event records something that happened at a single point in time
+func (t *Trace) Event(name string, at time.Time, detail string) {
+	t.Span(name, at, at, detail)
+}
+
+// Spans returns a copy of the recorded spans in the order they were added
+func (t *Trace) Spans() []TraceSpan {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]TraceSpan(nil), t.spans...)
 }